@@ -0,0 +1,81 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package archive chooses the program that archives WAL from PostgreSQL and
+// restores from those archives: pgBackRest, or WAL-G when the spec selects it.
+package archive
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/walg"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// Name returns the name of the archive selected for inCluster, either
+// "pgbackrest" or "walg".
+func Name(inCluster *v1beta1.PostgresCluster) string {
+	if walg.Enabled(inCluster) {
+		return "walg"
+	}
+	return "pgbackrest"
+}
+
+// PostgreSQL populates outParameters with the archive_command and
+// restore_command of the archive selected for inCluster.
+func PostgreSQL(
+	inCluster *v1beta1.PostgresCluster,
+	outParameters *postgres.Parameters,
+) {
+	if walg.Enabled(inCluster) {
+		walg.PostgreSQL(inCluster, outParameters)
+	} else {
+		pgbackrest.PostgreSQL(inCluster, outParameters)
+	}
+}
+
+// InstancePod populates outInstancePod with anything the archive selected for
+// inCluster needs in PostgreSQL instances beyond pgBackRest configuration.
+func InstancePod(
+	inCluster *v1beta1.PostgresCluster,
+	outInstancePod *corev1.PodSpec,
+) {
+	if walg.Enabled(inCluster) {
+		walg.InstancePod(inCluster, outInstancePod)
+	}
+}
+
+// ReplicaCreateCommand returns the command that can initialize the PostgreSQL
+// data directory on an instance from the archive selected for inCluster. It
+// returns nil when that archive has no backup from which to do so.
+func ReplicaCreateCommand(
+	inCluster *v1beta1.PostgresCluster, inInstance *v1beta1.PostgresInstanceSetSpec,
+) []string {
+	if walg.Enabled(inCluster) {
+		return walg.ReplicaCreateCommand()
+	}
+	return pgbackrest.ReplicaCreateCommand(inCluster, inInstance)
+}
+
+// ReplicaCreateKeepsData returns whether the replica create command of the
+// archive selected for inCluster can reuse files already in the PostgreSQL data
+// directory. pgBackRest restores with "--delta" while WAL-G fetches a backup
+// only into an empty directory.
+// - https://github.com/wal-g/wal-g/blob/master/docs/PostgreSQL.md#backup-fetch
+func ReplicaCreateKeepsData(inCluster *v1beta1.PostgresCluster) bool {
+	return !walg.Enabled(inCluster)
+}
@@ -0,0 +1,89 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package archive
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestPGBackRest(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.PostgresVersion = 14
+	instance := new(v1beta1.PostgresInstanceSetSpec)
+
+	assert.Equal(t, Name(cluster), "pgbackrest")
+	assert.Assert(t, ReplicaCreateKeepsData(cluster))
+
+	parameters := new(postgres.Parameters)
+	PostgreSQL(cluster, parameters)
+	assert.Assert(t, strings.Contains(
+		parameters.Mandatory.AsMap()["archive_command"], "pgbackrest"))
+	assert.Assert(t, strings.Contains(
+		parameters.Mandatory.AsMap()["restore_command"], "pgbackrest"))
+
+	pod := corev1.PodSpec{Containers: []corev1.Container{{Name: naming.ContainerDatabase}}}
+	InstancePod(cluster, &pod)
+	assert.Assert(t, len(pod.Containers[0].Env) == 0)
+
+	// There is no backup from which to create a replica.
+	assert.Assert(t, ReplicaCreateCommand(cluster, instance) == nil)
+
+	cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+		Repos: []v1beta1.RepoStatus{{Name: "repo1", ReplicaCreateBackupComplete: true}},
+	}
+	command := ReplicaCreateCommand(cluster, instance)
+	assert.Assert(t, len(command) > 0)
+	assert.Equal(t, command[0], "pgbackrest")
+	assert.Assert(t, strings.Contains(strings.Join(command, " "), "--delta"))
+}
+
+func TestWALG(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.PostgresVersion = 14
+	cluster.Spec.Backups.WALG = &v1beta1.WALGArchive{
+		Env: []corev1.EnvVar{{Name: "WALG_S3_PREFIX", Value: "s3://bucket"}},
+	}
+	instance := new(v1beta1.PostgresInstanceSetSpec)
+
+	assert.Equal(t, Name(cluster), "walg")
+	assert.Assert(t, !ReplicaCreateKeepsData(cluster))
+
+	parameters := new(postgres.Parameters)
+	PostgreSQL(cluster, parameters)
+	assert.Equal(t, parameters.Mandatory.AsMap()["archive_command"], `wal-g wal-push "%p"`)
+	assert.Equal(t, parameters.Mandatory.AsMap()["restore_command"], `wal-g wal-fetch "%f" "%p"`)
+
+	pod := corev1.PodSpec{Containers: []corev1.Container{
+		{Name: naming.ContainerDatabase}, {Name: "other"},
+	}}
+	InstancePod(cluster, &pod)
+	assert.DeepEqual(t, pod.Containers[0].Env, []corev1.EnvVar{
+		{Name: "WALG_S3_PREFIX", Value: "s3://bucket"},
+	})
+	assert.Assert(t, len(pod.Containers[1].Env) == 0)
+
+	assert.DeepEqual(t, ReplicaCreateCommand(cluster, instance), []string{
+		"bash", "-ceu", "--", `exec wal-g backup-fetch "${PGDATA?}" LATEST`,
+	})
+}
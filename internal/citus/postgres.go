@@ -0,0 +1,81 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package citus
+
+import (
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// Enabled returns whether or not cluster is a Citus cluster.
+func Enabled(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.Citus != nil
+}
+
+// CommonName returns the common name of the certificate that Citus nodes of
+// cluster present to one another. Every PostgresCluster trusts the same root
+// certificate authority, so this name is unique to cluster.
+func CommonName(cluster *v1beta1.PostgresCluster) string {
+	return "citus-" + string(cluster.UID)
+}
+
+// PostgreSQLHBAs provides the Postgres HBA rules that allow Citus nodes to
+// connect to one another.
+func PostgreSQLHBAs(inCluster *v1beta1.PostgresCluster, outHBAs *postgres.HBAs) {
+	if Enabled(inCluster) {
+		// The coordinator connects to workers as the "postgres" superuser when
+		// it distributes tables and registers nodes. That connection must use
+		// TLS and present the certificate of this cluster. The user name map
+		// keeps certificates of other clusters from logging in as "postgres".
+		// - https://docs.citusdata.com/en/stable/admin_guide/cluster_management.html#increasing-worker-security
+		// - https://www.postgresql.org/docs/current/auth-cert.html
+		outHBAs.Mandatory = append(outHBAs.Mandatory,
+			*postgres.NewHBA().TLS().User("postgres").Method("cert").
+				Options(map[string]string{"map": "citus"}))
+		outHBAs.Idents = append(outHBAs.Idents,
+			`citus "`+CommonName(inCluster)+`" postgres`)
+	}
+}
+
+// PostgreSQLParameters provides additional required configuration parameters
+// that Postgres needs to support Citus.
+func PostgreSQLParameters(inCluster *v1beta1.PostgresCluster, outParameters *postgres.Parameters) {
+	if Enabled(inCluster) {
+		// Citus must be the first of shared_preload_libraries.
+		// - https://docs.citusdata.com/en/stable/installation/multi_node_debian.html
+		libraries := []string{"citus"}
+
+		defined, found := outParameters.Mandatory.Get("shared_preload_libraries")
+		if found {
+			libraries = append(libraries, defined)
+		}
+
+		outParameters.Mandatory.Add("shared_preload_libraries", strings.Join(libraries, ","))
+
+		// Connect to other nodes using the certificate mounted by the instance
+		// Pod. Citus honors only some libpq parameters here.
+		// - https://docs.citusdata.com/en/stable/develop/api_guc.html#citus-node-conninfo-text
+		outParameters.Mandatory.Add("citus.node_conninfo", strings.Join([]string{
+			"sslmode=verify-ca",
+			"sslrootcert=" + naming.CertMountPath + "/ca.crt",
+			"sslcert=" + naming.CitusTmp + "/" + naming.CitusCert,
+			"sslkey=" + naming.CitusTmp + "/" + naming.CitusPrivateKey,
+		}, " "))
+	}
+}
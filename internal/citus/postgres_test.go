@@ -0,0 +1,58 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package citus
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestPostgreSQLHBAs(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	hbas := postgres.HBAs{}
+
+	PostgreSQLHBAs(cluster, &hbas)
+	assert.Equal(t, len(hbas.Mandatory), 0)
+	assert.Equal(t, len(hbas.Idents), 0)
+
+	cluster.UID = "some-uid"
+	cluster.Spec.Citus = new(v1beta1.CitusSpec)
+	PostgreSQLHBAs(cluster, &hbas)
+	assert.Equal(t, len(hbas.Mandatory), 1)
+	assert.Equal(t, hbas.Mandatory[0].String(), `hostssl all "postgres" all cert  map="citus"`)
+	assert.DeepEqual(t, hbas.Idents, []string{`citus "citus-some-uid" postgres`})
+}
+
+func TestPostgreSQLParameters(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	parameters := postgres.Parameters{Mandatory: postgres.NewParameterSet()}
+
+	PostgreSQLParameters(cluster, &parameters)
+	assert.DeepEqual(t, parameters.Mandatory.AsMap(), map[string]string{})
+
+	cluster.Spec.Citus = new(v1beta1.CitusSpec)
+	parameters.Mandatory.Add("shared_preload_libraries", "other")
+	PostgreSQLParameters(cluster, &parameters)
+	assert.DeepEqual(t, parameters.Mandatory.AsMap(), map[string]string{
+		"citus.node_conninfo": "sslmode=verify-ca sslrootcert=/pgconf/tls/ca.crt" +
+			" sslcert=/tmp/citus/tls.crt sslkey=/tmp/citus/tls.key",
+		"shared_preload_libraries": "citus,other",
+	})
+}
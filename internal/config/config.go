@@ -67,6 +67,29 @@ func PGBouncerContainerImage(cluster *v1beta1.PostgresCluster) string {
 	return defaultFromEnv(image, "RELATED_IMAGE_PGBOUNCER")
 }
 
+// HAProxyContainerImage returns the container image to use for HAProxy.
+func HAProxyContainerImage(cluster *v1beta1.PostgresCluster) string {
+	var image string
+	if cluster.Spec.Proxy != nil &&
+		cluster.Spec.Proxy.HAProxy != nil {
+		image = cluster.Spec.Proxy.HAProxy.Image
+	}
+
+	return defaultFromEnv(image, "RELATED_IMAGE_HAPROXY")
+}
+
+// LogShipperContainerImage returns the container image to use for the log
+// shipper.
+func LogShipperContainerImage(cluster *v1beta1.PostgresCluster) string {
+	var image string
+	if cluster.Spec.Monitoring != nil &&
+		cluster.Spec.Monitoring.LogShipping != nil {
+		image = cluster.Spec.Monitoring.LogShipping.Image
+	}
+
+	return defaultFromEnv(image, "RELATED_IMAGE_FLUENT_BIT")
+}
+
 // PGExporterContainerImage returns the container image to use for the
 // PostgreSQL Exporter.
 func PGExporterContainerImage(cluster *v1beta1.PostgresCluster) string {
@@ -80,6 +103,17 @@ func PGExporterContainerImage(cluster *v1beta1.PostgresCluster) string {
 	return defaultFromEnv(image, "RELATED_IMAGE_PGEXPORTER")
 }
 
+// PGUpgradeContainerImage returns the container image to use for major
+// upgrades of PostgreSQL.
+func PGUpgradeContainerImage(cluster *v1beta1.PostgresCluster) string {
+	var image string
+	if cluster.Spec.Upgrade != nil {
+		image = cluster.Spec.Upgrade.Image
+	}
+
+	return defaultFromEnv(image, "RELATED_IMAGE_PGUPGRADE")
+}
+
 // PostgresContainerImage returns the container image to use for PostgreSQL.
 func PostgresContainerImage(cluster *v1beta1.PostgresCluster) string {
 	image := cluster.Spec.Image
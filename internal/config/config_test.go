@@ -103,6 +103,42 @@ func TestPGBouncerContainerImage(t *testing.T) {
 	assert.Equal(t, PGBouncerContainerImage(cluster), "spec-image")
 }
 
+func TestHAProxyContainerImage(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+
+	unsetEnv(t, "RELATED_IMAGE_HAPROXY")
+	assert.Equal(t, HAProxyContainerImage(cluster), "")
+
+	setEnv(t, "RELATED_IMAGE_HAPROXY", "")
+	assert.Equal(t, HAProxyContainerImage(cluster), "")
+
+	setEnv(t, "RELATED_IMAGE_HAPROXY", "env-var-haproxy")
+	assert.Equal(t, HAProxyContainerImage(cluster), "env-var-haproxy")
+
+	assert.NilError(t, yaml.Unmarshal([]byte(`{
+		proxy: { haProxy: { image: spec-image } },
+	}`), &cluster.Spec))
+	assert.Equal(t, HAProxyContainerImage(cluster), "spec-image")
+}
+
+func TestLogShipperContainerImage(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+
+	unsetEnv(t, "RELATED_IMAGE_FLUENT_BIT")
+	assert.Equal(t, LogShipperContainerImage(cluster), "")
+
+	setEnv(t, "RELATED_IMAGE_FLUENT_BIT", "")
+	assert.Equal(t, LogShipperContainerImage(cluster), "")
+
+	setEnv(t, "RELATED_IMAGE_FLUENT_BIT", "env-var-fluent-bit")
+	assert.Equal(t, LogShipperContainerImage(cluster), "env-var-fluent-bit")
+
+	assert.NilError(t, yaml.Unmarshal([]byte(`{
+		monitoring: { logShipping: { image: spec-image } },
+	}`), &cluster.Spec))
+	assert.Equal(t, LogShipperContainerImage(cluster), "spec-image")
+}
+
 func TestPGExporterContainerImage(t *testing.T) {
 	cluster := &v1beta1.PostgresCluster{}
 
@@ -121,6 +157,24 @@ func TestPGExporterContainerImage(t *testing.T) {
 	assert.Equal(t, PGExporterContainerImage(cluster), "spec-image")
 }
 
+func TestPGUpgradeContainerImage(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+
+	unsetEnv(t, "RELATED_IMAGE_PGUPGRADE")
+	assert.Equal(t, PGUpgradeContainerImage(cluster), "")
+
+	setEnv(t, "RELATED_IMAGE_PGUPGRADE", "")
+	assert.Equal(t, PGUpgradeContainerImage(cluster), "")
+
+	setEnv(t, "RELATED_IMAGE_PGUPGRADE", "env-var-pgupgrade")
+	assert.Equal(t, PGUpgradeContainerImage(cluster), "env-var-pgupgrade")
+
+	assert.NilError(t, yaml.Unmarshal([]byte(`{
+		upgrade: { fromPostgresVersion: 13, image: spec-image },
+	}`), &cluster.Spec))
+	assert.Equal(t, PGUpgradeContainerImage(cluster), "spec-image")
+}
+
 func TestPostgresContainerImage(t *testing.T) {
 	cluster := &v1beta1.PostgresCluster{}
 	cluster.Spec.PostgresVersion = 12
@@ -0,0 +1,200 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/citus"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/patroni"
+	"github.com/crunchydata/postgres-operator/internal/pki"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// citusGroups returns the distinct Citus groups of cluster in ascending order.
+func citusGroups(cluster *v1beta1.PostgresCluster) []int32 {
+	groups := sets.NewInt32()
+	if citus.Enabled(cluster) {
+		for i := range cluster.Spec.InstanceSets {
+			if group := cluster.Spec.InstanceSets[i].CitusGroup; group != nil {
+				groups.Insert(*group)
+			}
+		}
+	}
+	return groups.List()
+}
+
+// validateCitusGroups returns an error when cluster is a Citus cluster without
+// a coordinator or with instance sets outside of any group.
+func validateCitusGroups(cluster *v1beta1.PostgresCluster) error {
+	if !citus.Enabled(cluster) {
+		return nil
+	}
+
+	path := field.NewPath("spec", "instances")
+	coordinator := false
+	for i := range cluster.Spec.InstanceSets {
+		group := cluster.Spec.InstanceSets[i].CitusGroup
+		if group == nil {
+			return field.Required(path.Index(i).Child("citusGroup"),
+				"Every instance set of a Citus cluster must have a group")
+		}
+		coordinator = coordinator || *group == 0
+	}
+	if !coordinator {
+		return field.Invalid(path, cluster.Name,
+			"A Citus cluster requires an instance set in group 0, the coordinator")
+	}
+	return nil
+}
+
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={create,delete,patch}
+
+// reconcileCitusSecret writes the Secret containing the client certificate
+// that Citus nodes use to connect to one another. It deletes the Secret when
+// cluster is not a Citus cluster.
+func (r *Reconciler) reconcileCitusSecret(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	root *pki.RootCertificateAuthority,
+) error {
+	existing := &corev1.Secret{ObjectMeta: naming.CitusClientCertSecret(cluster)}
+	err := errors.WithStack(
+		r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing))
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+
+	if !citus.Enabled(cluster) {
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, existing))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	// Citus connects as the "postgres" superuser. PostgreSQL certificate
+	// authentication maps the common name of this cluster to that user.
+	// - https://www.postgresql.org/docs/current/auth-cert.html
+	leaf := &pki.LeafCertificate{}
+	commonName := citus.CommonName(cluster)
+	dnsNames := []string{commonName}
+
+	// Unmarshal and validate the stored leaf. These first errors can
+	// be ignored because they result in an invalid leaf which is then
+	// correctly regenerated.
+	_ = leaf.Certificate.UnmarshalText(existing.Data[naming.CitusCert])
+	_ = leaf.PrivateKey.UnmarshalText(existing.Data[naming.CitusPrivateKey])
+
+	leaf, err = root.RegenerateLeafWhenNecessary(leaf, commonName, dnsNames)
+	err = errors.WithStack(err)
+
+	intent := &corev1.Secret{ObjectMeta: naming.CitusClientCertSecret(cluster)}
+	intent.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	intent.Data = make(map[string][]byte)
+
+	intent.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+	intent.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster:            cluster.Name,
+			naming.LabelClusterCertificate: "citus-client-tls",
+		})
+
+	if err == nil {
+		err = errors.WithStack(r.setControllerReference(cluster, intent))
+	}
+	if err == nil {
+		intent.Data[naming.CitusCert], err = leaf.Certificate.MarshalText()
+		err = errors.WithStack(err)
+	}
+	if err == nil {
+		intent.Data[naming.CitusPrivateKey], err = leaf.PrivateKey.MarshalText()
+		err = errors.WithStack(err)
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, intent))
+	}
+	return err
+}
+
+// +kubebuilder:rbac:groups="",resources="services",verbs={create,patch}
+
+// reconcileCitusServices writes a Service for the leader and configuration
+// Endpoints that Patroni creates for every Citus worker group. Those of the
+// coordinator are handled like any other cluster.
+func (r *Reconciler) reconcileCitusServices(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	if patroni.UseConfigMaps(cluster) {
+		// Kubernetes does not remove ConfigMaps, so there is nothing to protect.
+		return nil
+	}
+
+	var err error
+	for _, group := range citusGroups(cluster) {
+		if group == 0 {
+			continue
+		}
+		for _, meta := range []metav1.ObjectMeta{
+			naming.PatroniCitusGroupLeaderEndpoints(cluster, group),
+			naming.PatroniCitusGroupConfiguration(cluster, group),
+		} {
+			var service *corev1.Service
+			if err == nil {
+				service, err = r.generateCitusGroupService(cluster, meta)
+			}
+			if err == nil {
+				err = errors.WithStack(r.apply(ctx, service))
+			}
+		}
+	}
+	return err
+}
+
+// generateCitusGroupService returns a Service that keeps Kubernetes from
+// removing Endpoints that Patroni creates for a Citus group. See
+// [Reconciler.reconcilePatroniDistributedConfiguration].
+func (r *Reconciler) generateCitusGroupService(
+	cluster *v1beta1.PostgresCluster, meta metav1.ObjectMeta,
+) (*corev1.Service, error) {
+	service := &corev1.Service{ObjectMeta: meta}
+	service.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Service"))
+
+	service.Annotations = naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil())
+	service.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelPatroni: naming.PatroniScope(cluster),
+		})
+
+	// Allocate no IP address (headless) and let Patroni manage the Endpoints.
+	// - https://docs.k8s.io/concepts/services-networking/service/#headless-services
+	service.Spec.ClusterIP = corev1.ClusterIPNone
+	service.Spec.Selector = nil
+
+	err := errors.WithStack(r.setControllerReference(cluster, service))
+	return service, err
+}
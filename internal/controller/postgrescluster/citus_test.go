@@ -0,0 +1,56 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestCitusGroups(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+		{Name: "a", CitusGroup: initialize.Int32(2)},
+		{Name: "b", CitusGroup: initialize.Int32(0)},
+		{Name: "c", CitusGroup: initialize.Int32(2)},
+		{Name: "d", CitusGroup: initialize.Int32(1)},
+	}
+
+	assert.Assert(t, len(citusGroups(cluster)) == 0, "expected none when disabled")
+
+	cluster.Spec.Citus = new(v1beta1.CitusSpec)
+	assert.DeepEqual(t, citusGroups(cluster), []int32{0, 1, 2})
+}
+
+func TestValidateCitusGroups(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "a"}}
+	assert.NilError(t, validateCitusGroups(cluster))
+
+	cluster.Spec.Citus = new(v1beta1.CitusSpec)
+	assert.ErrorContains(t, validateCitusGroups(cluster), "spec.instances[0].citusGroup")
+
+	cluster.Spec.InstanceSets[0].CitusGroup = initialize.Int32(1)
+	assert.ErrorContains(t, validateCitusGroups(cluster), "group 0")
+
+	cluster.Spec.InstanceSets = append(cluster.Spec.InstanceSets,
+		v1beta1.PostgresInstanceSetSpec{Name: "b", CitusGroup: initialize.Int32(0)})
+	assert.NilError(t, validateCitusGroups(cluster))
+}
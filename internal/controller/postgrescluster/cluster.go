@@ -17,6 +17,7 @@ package postgrescluster
 
 import (
 	"context"
+	"fmt"
 	"io"
 
 	"github.com/pkg/errors"
@@ -55,6 +56,9 @@ func (r *Reconciler) reconcileClusterConfigMap(
 		err = patroni.ClusterConfigMap(ctx, cluster, pgHBAs, pgParameters,
 			clusterConfigMap)
 	}
+	if err == nil {
+		postgres.ClusterConfigMap(cluster, clusterConfigMap)
+	}
 	if err == nil {
 		err = errors.WithStack(r.apply(ctx, clusterConfigMap))
 	}
@@ -202,7 +206,17 @@ func (r *Reconciler) generateClusterReplicaService(
 	service.Annotations = naming.Merge(
 		cluster.Spec.Metadata.GetAnnotationsOrNil())
 	service.Labels = naming.Merge(
-		cluster.Spec.Metadata.GetLabelsOrNil(),
+		cluster.Spec.Metadata.GetLabelsOrNil())
+
+	if spec := cluster.Spec.ReplicaService; spec != nil {
+		service.Annotations = naming.Merge(service.Annotations,
+			spec.Metadata.GetAnnotationsOrNil())
+		service.Labels = naming.Merge(service.Labels,
+			spec.Metadata.GetLabelsOrNil())
+	}
+
+	// add our labels last so they aren't overwritten
+	service.Labels = naming.Merge(service.Labels,
 		map[string]string{
 			naming.LabelCluster: cluster.Name,
 			naming.LabelRole:    naming.RoleReplica,
@@ -211,7 +225,6 @@ func (r *Reconciler) generateClusterReplicaService(
 	// Allocate an IP address and let Kubernetes manage the Endpoints by
 	// selecting Pods with the Patroni replica role.
 	// - https://docs.k8s.io/concepts/services-networking/service/#defining-a-service
-	service.Spec.Type = corev1.ServiceTypeClusterIP
 	service.Spec.Selector = map[string]string{
 		naming.LabelCluster: cluster.Name,
 		naming.LabelRole:    naming.RolePatroniReplica,
@@ -220,12 +233,34 @@ func (r *Reconciler) generateClusterReplicaService(
 	// The TargetPort must be the name (not the number) of the PostgreSQL
 	// ContainerPort. This name allows the port number to differ between Pods,
 	// which can happen during a rolling update.
-	service.Spec.Ports = []corev1.ServicePort{{
+	servicePort := corev1.ServicePort{
 		Name:       naming.PortPostgreSQL,
 		Port:       *cluster.Spec.Port,
 		Protocol:   corev1.ProtocolTCP,
 		TargetPort: intstr.FromString(naming.PortPostgreSQL),
-	}}
+	}
+
+	if spec := cluster.Spec.ReplicaService; spec == nil {
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+	} else {
+		service.Spec.Type = corev1.ServiceType(spec.Type)
+		service.Spec.IPFamilies = spec.IPFamilies
+		service.Spec.IPFamilyPolicy = spec.IPFamilyPolicy
+		if spec.NodePort != nil {
+			if service.Spec.Type == corev1.ServiceTypeClusterIP {
+				// The NodePort can only be set when the Service type is NodePort or
+				// LoadBalancer. Log an Event and return an error like the other
+				// Services with a ServiceSpec.
+				r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "MisconfiguredClusterIP",
+					"NodePort cannot be set with type ClusterIP on Service %q", service.Name)
+				return nil, fmt.Errorf("NodePort cannot be set with type ClusterIP on Service %q", service.Name)
+			}
+			servicePort.NodePort = *spec.NodePort
+		}
+		setServiceExternalTraffic(spec, service)
+		setServiceRouting(spec, service)
+	}
+	service.Spec.Ports = []corev1.ServicePort{servicePort}
 
 	err := errors.WithStack(r.setControllerReference(cluster, service))
 
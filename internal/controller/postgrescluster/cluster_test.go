@@ -791,4 +791,47 @@ postgres-operator.crunchydata.com/cluster: pg2
 postgres-operator.crunchydata.com/role: replica
 		`))
 	})
+
+	t.Run("ReplicaServiceSpec", func(t *testing.T) {
+		affinity := corev1.ServiceAffinityClientIP
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.ReplicaService = &v1beta1.ServiceSpec{
+			Metadata: &v1beta1.Metadata{
+				Annotations: map[string]string{"some": "note"},
+				Labels: map[string]string{
+					"postgres-operator.crunchydata.com/role": "wrong",
+				},
+			},
+			NodePort:           initialize.Int32(32000),
+			SessionAffinity:    &affinity,
+			TopologyAwareHints: true,
+			Type:               "NodePort",
+		}
+
+		service, err := reconciler.generateClusterReplicaService(cluster)
+		assert.NilError(t, err)
+
+		assert.Assert(t, marshalMatches(service.ObjectMeta.Annotations, `
+service.kubernetes.io/topology-aware-hints: auto
+some: note
+		`))
+		assert.Assert(t, marshalMatches(service.ObjectMeta.Labels, `
+postgres-operator.crunchydata.com/cluster: pg2
+postgres-operator.crunchydata.com/role: replica
+		`))
+		assert.Assert(t, marshalMatches(service.Spec, `
+ports:
+- name: postgres
+  nodePort: 32000
+  port: 9876
+  protocol: TCP
+  targetPort: postgres
+selector:
+  postgres-operator.crunchydata.com/cluster: pg2
+  postgres-operator.crunchydata.com/role: replica
+sessionAffinity: ClientIP
+type: NodePort
+		`))
+	})
 }
@@ -0,0 +1,136 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/patroni"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// instanceSetsNotReady returns the names of instance sets in cluster that have
+// fewer ready Pods than specified.
+func instanceSetsNotReady(cluster *v1beta1.PostgresCluster) []string {
+	ready := make(map[string]int32, len(cluster.Status.InstanceSets))
+	for _, status := range cluster.Status.InstanceSets {
+		ready[status.Name] = status.ReadyReplicas
+	}
+
+	var names []string
+	for _, set := range cluster.Spec.InstanceSets {
+		if set.Replicas != nil && ready[set.Name] < *set.Replicas {
+			names = append(names, set.Name)
+		}
+	}
+	return names
+}
+
+// setClusterConditions summarizes the health of cluster in its PrimaryAvailable
+// and ClusterReady conditions. It is called at the end of every reconcile,
+// after instances, instance set statuses, and any migration have been observed.
+func setClusterConditions(cluster *v1beta1.PostgresCluster, instances *observedInstances) {
+	standby := cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled
+	shutdown := cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown
+
+	// Find the leader elected by Patroni. In a standby cluster that is the
+	// standby leader, which follows another cluster and does not accept writes.
+	var leader *Instance
+	for _, instance := range instances.forCluster {
+		if terminating, known := instance.IsTerminating(); terminating || !known {
+			continue
+		}
+		if primary, _ := instance.IsPrimary(); primary ||
+			(len(instance.Pods) == 1 && patroni.PodIsStandbyLeader(instance.Pods[0])) {
+			leader = instance
+			break
+		}
+	}
+
+	leaderReady := false
+	if leader != nil {
+		leaderReady, _ = leader.IsReady()
+	}
+
+	primary := metav1.Condition{
+		Type:               v1beta1.PrimaryAvailable,
+		ObservedGeneration: cluster.GetGeneration(),
+		Status:             metav1.ConditionFalse,
+	}
+
+	switch {
+	case shutdown:
+		primary.Reason = "Shutdown"
+		primary.Message = "The cluster is shut down."
+	case leader == nil:
+		primary.Reason = "NoPrimary"
+		primary.Message = "Patroni has not elected a leader."
+	case !leaderReady:
+		primary.Reason = "PrimaryNotReady"
+		primary.Message = fmt.Sprintf("Primary %s is not ready for connections.", leader.Name)
+	case standby:
+		primary.Reason = "Standby"
+		primary.Message = "The cluster is a standby and does not accept writes."
+	default:
+		if writable, _ := leader.IsWritable(); writable {
+			primary.Status = metav1.ConditionTrue
+			primary.Reason = "PrimaryAcceptingWrites"
+			primary.Message = fmt.Sprintf("Primary %s is accepting writes.", leader.Name)
+		} else {
+			primary.Reason = "PrimaryReadOnly"
+			primary.Message = fmt.Sprintf("Primary %s is not accepting writes.", leader.Name)
+		}
+	}
+
+	ready := metav1.Condition{
+		Type:               v1beta1.ClusterReady,
+		ObservedGeneration: cluster.GetGeneration(),
+		Status:             metav1.ConditionFalse,
+	}
+
+	notReady := instanceSetsNotReady(cluster)
+
+	switch {
+	case shutdown:
+		ready.Reason = "Shutdown"
+		ready.Message = "The cluster is shut down."
+	case len(notReady) > 0:
+		ready.Reason = "InstancesNotReady"
+		ready.Message = "Fewer replicas than specified are ready in instance sets: " +
+			strings.Join(notReady, ", ")
+	case standby && !leaderReady:
+		ready.Reason = "PrimaryUnavailable"
+		ready.Message = "The standby leader is not ready."
+	case !standby && primary.Status != metav1.ConditionTrue:
+		ready.Reason = "PrimaryUnavailable"
+		ready.Message = primary.Message
+	case meta.IsStatusConditionFalse(cluster.Status.Conditions, v1beta1.MigrationSucceeded):
+		ready.Reason = "MigrationNotSucceeded"
+		ready.Message = meta.FindStatusCondition(
+			cluster.Status.Conditions, v1beta1.MigrationSucceeded).Message
+	default:
+		ready.Status = metav1.ConditionTrue
+		ready.Reason = "AllInstancesReady"
+		ready.Message = "All instance sets are ready and the primary is available."
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, primary)
+	meta.SetStatusCondition(&cluster.Status.Conditions, ready)
+}
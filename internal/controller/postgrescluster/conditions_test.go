@@ -0,0 +1,173 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestSetClusterConditions(t *testing.T) {
+	instance := func(name, role string, ready bool) *Instance {
+		pod := new(corev1.Pod)
+		pod.Name = name + "-0"
+		pod.Annotations = map[string]string{"status": `{"role":"` + role + `"}`}
+		if role == "master" {
+			pod.Labels = map[string]string{naming.LabelRole: naming.RolePatroniLeader}
+		}
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type: corev1.PodReady, Status: status,
+		}}
+		return &Instance{Name: name, Pods: []*corev1.Pod{pod}}
+	}
+
+	newCluster := func() *v1beta1.PostgresCluster {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Generation = 3
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "one", Replicas: initialize.Int32(2)},
+		}
+		cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{
+			{Name: "one", ReadyReplicas: 2},
+		}
+		return cluster
+	}
+
+	conditions := func(cluster *v1beta1.PostgresCluster) (primary, ready *metav1.Condition) {
+		primary = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.PrimaryAvailable)
+		ready = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.ClusterReady)
+		assert.Assert(t, primary != nil)
+		assert.Assert(t, ready != nil)
+		assert.Equal(t, primary.ObservedGeneration, int64(3))
+		assert.Equal(t, ready.ObservedGeneration, int64(3))
+		return
+	}
+
+	t.Run("Ready", func(t *testing.T) {
+		cluster := newCluster()
+		setClusterConditions(cluster, &observedInstances{forCluster: []*Instance{
+			instance("a", "replica", true), instance("b", "master", true),
+		}})
+
+		primary, ready := conditions(cluster)
+		assert.Equal(t, primary.Status, metav1.ConditionTrue)
+		assert.Equal(t, primary.Reason, "PrimaryAcceptingWrites")
+		assert.Equal(t, primary.Message, "Primary b is accepting writes.")
+		assert.Equal(t, ready.Status, metav1.ConditionTrue)
+		assert.Equal(t, ready.Reason, "AllInstancesReady")
+	})
+
+	t.Run("NoPrimary", func(t *testing.T) {
+		cluster := newCluster()
+		setClusterConditions(cluster, &observedInstances{forCluster: []*Instance{
+			instance("a", "replica", true),
+		}})
+
+		primary, ready := conditions(cluster)
+		assert.Equal(t, primary.Status, metav1.ConditionFalse)
+		assert.Equal(t, primary.Reason, "NoPrimary")
+		assert.Equal(t, ready.Status, metav1.ConditionFalse)
+		assert.Equal(t, ready.Reason, "PrimaryUnavailable")
+	})
+
+	t.Run("PrimaryNotReady", func(t *testing.T) {
+		cluster := newCluster()
+		setClusterConditions(cluster, &observedInstances{forCluster: []*Instance{
+			instance("b", "master", false),
+		}})
+
+		primary, ready := conditions(cluster)
+		assert.Equal(t, primary.Status, metav1.ConditionFalse)
+		assert.Equal(t, primary.Reason, "PrimaryNotReady")
+		assert.Equal(t, ready.Status, metav1.ConditionFalse)
+		assert.Equal(t, ready.Reason, "PrimaryUnavailable")
+	})
+
+	t.Run("InstancesNotReady", func(t *testing.T) {
+		cluster := newCluster()
+		cluster.Spec.InstanceSets = append(cluster.Spec.InstanceSets,
+			v1beta1.PostgresInstanceSetSpec{Name: "two", Replicas: initialize.Int32(1)})
+
+		setClusterConditions(cluster, &observedInstances{forCluster: []*Instance{
+			instance("b", "master", true),
+		}})
+
+		primary, ready := conditions(cluster)
+		assert.Equal(t, primary.Status, metav1.ConditionTrue)
+		assert.Equal(t, ready.Status, metav1.ConditionFalse)
+		assert.Equal(t, ready.Reason, "InstancesNotReady")
+		assert.Equal(t, ready.Message,
+			"Fewer replicas than specified are ready in instance sets: two")
+	})
+
+	t.Run("Standby", func(t *testing.T) {
+		cluster := newCluster()
+		cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: true}
+
+		setClusterConditions(cluster, &observedInstances{forCluster: []*Instance{
+			instance("a", "replica", true), instance("c", "standby_leader", true),
+		}})
+
+		primary, ready := conditions(cluster)
+		assert.Equal(t, primary.Status, metav1.ConditionFalse)
+		assert.Equal(t, primary.Reason, "Standby")
+		assert.Equal(t, ready.Status, metav1.ConditionTrue)
+	})
+
+	t.Run("MigrationNotSucceeded", func(t *testing.T) {
+		cluster := newCluster()
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type: v1beta1.MigrationSucceeded, Status: metav1.ConditionFalse,
+			Reason: "MigrationRunning", Message: `Migrating databases in Job "hippo-migration"`,
+		})
+
+		setClusterConditions(cluster, &observedInstances{forCluster: []*Instance{
+			instance("a", "replica", true), instance("b", "master", true),
+		}})
+
+		primary, ready := conditions(cluster)
+		assert.Equal(t, primary.Status, metav1.ConditionTrue)
+		assert.Equal(t, ready.Status, metav1.ConditionFalse)
+		assert.Equal(t, ready.Reason, "MigrationNotSucceeded")
+		assert.Equal(t, ready.Message, `Migrating databases in Job "hippo-migration"`)
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		cluster := newCluster()
+		cluster.Spec.Shutdown = initialize.Bool(true)
+		cluster.Status.InstanceSets = nil
+		cluster.Spec.InstanceSets[0].Replicas = initialize.Int32(0)
+
+		setClusterConditions(cluster, &observedInstances{})
+
+		primary, ready := conditions(cluster)
+		assert.Equal(t, primary.Reason, "Shutdown")
+		assert.Equal(t, ready.Status, metav1.ConditionFalse)
+		assert.Equal(t, ready.Reason, "Shutdown")
+	})
+}
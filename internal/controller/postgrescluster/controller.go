@@ -21,18 +21,20 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -42,11 +44,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	"github.com/crunchydata/postgres-operator/internal/archive"
+	"github.com/crunchydata/postgres-operator/internal/citus"
 	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/logshipping"
+	"github.com/crunchydata/postgres-operator/internal/maintenance"
+	"github.com/crunchydata/postgres-operator/internal/patroni"
 	"github.com/crunchydata/postgres-operator/internal/pgaudit"
-	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
 	"github.com/crunchydata/postgres-operator/internal/pgbouncer"
 	"github.com/crunchydata/postgres-operator/internal/pgmonitor"
+	"github.com/crunchydata/postgres-operator/internal/pgpartman"
 	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
@@ -57,6 +64,10 @@ const (
 	ControllerName = "postgrescluster-controller"
 )
 
+// errClusterNotFound is returned by [Reconciler.reconcileCluster] when the
+// PostgresCluster no longer exists.
+var errClusterNotFound = errors.New("PostgresCluster not found")
+
 // Reconciler holds resources for the PostgresCluster reconciler
 type Reconciler struct {
 	Client      client.Client
@@ -79,7 +90,27 @@ type Reconciler struct {
 func (r *Reconciler) Reconcile(
 	ctx context.Context, request reconcile.Request) (reconcile.Result, error,
 ) {
-	ctx, span := r.Tracer.Start(ctx, "Reconcile")
+	start := time.Now()
+	result, err := r.reconcileCluster(ctx, request)
+
+	// Forget a deleted cluster rather than record metrics about it again.
+	if errors.Is(err, errClusterNotFound) {
+		forgetClusterMetrics(request.NamespacedName)
+		return result, nil
+	}
+
+	observeReconcile(request.NamespacedName, time.Since(start), err)
+	return result, err
+}
+
+// reconcileCluster reconciles the PostgresCluster of request. See [Reconciler.Reconcile].
+func (r *Reconciler) reconcileCluster(
+	ctx context.Context, request reconcile.Request) (reconcile.Result, error,
+) {
+	ctx, span := r.Tracer.Start(ctx, "Reconcile", trace.WithAttributes(
+		attribute.String("namespace", request.Namespace),
+		attribute.String("name", request.Name),
+	))
 	log := logging.FromContext(ctx)
 	defer span.End()
 
@@ -101,6 +132,8 @@ func (r *Reconciler) Reconcile(
 		if err = client.IgnoreNotFound(err); err != nil {
 			log.Error(err, "unable to fetch PostgresCluster")
 			span.RecordError(err)
+		} else {
+			err = errClusterNotFound
 		}
 		return result, err
 	}
@@ -145,20 +178,22 @@ func (r *Reconciler) Reconcile(
 	// Perform initial validation on a cluster
 	// TODO: Move this to a defaulting (mutating admission) webhook
 	// to leverage regular validation.
-	if cluster.Spec.Standby != nil &&
-		cluster.Spec.Standby.Enabled &&
-		cluster.Spec.Standby.Host == "" &&
-		cluster.Spec.Standby.RepoName == "" {
-		// When a standby cluster is requested but a repoName or host is not provided
-		// the cluster will be created as a non-standby. Reject any clusters with
-		// this configuration and provide an event
-		path := field.NewPath("spec", "standby")
-		err := field.Invalid(path, cluster.Name, "Standby requires a host or repoName to be enabled")
+	if err := validateStandby(cluster); err != nil {
+		// Reject standby clusters that would be created as a non-standby or
+		// could never connect to their primary, and provide an event.
 		r.Recorder.Event(cluster, corev1.EventTypeWarning, "InvalidStandbyConfiguration",
 			err.Error())
 		return result, err
 	}
 
+	if err := validateCitusGroups(cluster); err != nil {
+		// Patroni cannot form a Citus cluster without a coordinator, and every
+		// instance must belong to some group.
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "InvalidCitusConfiguration",
+			err.Error())
+		return result, err
+	}
+
 	var (
 		clusterConfigMap         *corev1.ConfigMap
 		clusterReplicationSecret *corev1.Secret
@@ -179,12 +214,21 @@ func (r *Reconciler) Reconcile(
 	// occurs while attempting to patch the status, while otherwise simply returning the
 	// Result and error variables that are populated while reconciling the PostgresCluster.
 	patchClusterStatus := func() (reconcile.Result, error) {
+		if instances != nil {
+			setClusterConditions(cluster, instances)
+		}
+		observeClusterHealth(cluster)
+
 		if !equality.Semantic.DeepEqual(before.Status, cluster.Status) {
+			ctx, span := r.Tracer.Start(ctx, "patch-cluster-status")
+			defer span.End()
+
 			// NOTE(cbandy): Kubernetes prior to v1.16.10 and v1.17.6 does not track
 			// managed fields on the status subresource: https://issue.k8s.io/88901
 			if err := errors.WithStack(r.Client.Status().Patch(
 				ctx, cluster, client.MergeFrom(before), r.Owner)); err != nil {
 				log.Error(err, "patching cluster status")
+				span.RecordError(err)
 				return result, err
 			}
 			log.V(1).Info("patched cluster status")
@@ -210,14 +254,38 @@ func (r *Reconciler) Reconcile(
 	pgHBAs := postgres.NewHBAs()
 	pgmonitor.PostgreSQLHBAs(cluster, &pgHBAs)
 	pgbouncer.PostgreSQL(cluster, &pgHBAs)
+	maintenance.PostgreSQL(cluster, &pgHBAs)
+	citus.PostgreSQLHBAs(cluster, &pgHBAs)
+	postgres.PasswordHBAs(cluster, &pgHBAs)
+
+	pgParameters := postgresParameters(cluster)
+
+	// The operator overrides some dynamic configuration. The API server rejects
+	// entries that always conflict; see "build/crd/validation.yaml". Warn about
+	// the rest, like parameters of enabled extensions, rather than ignore them
+	// silently. Warn once for each change to the spec, not on every reconcile.
+	if cluster.Status.ObservedGeneration != cluster.GetGeneration() {
+		var dynamicConfiguration map[string]interface{}
+		if cluster.Spec.Patroni != nil {
+			dynamicConfiguration = cluster.Spec.Patroni.DynamicConfiguration
+		}
+		if errs := patroni.DynamicConfigurationConflicts(cluster,
+			dynamicConfiguration, pgParameters); len(errs) > 0 {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "InvalidPatroniConfiguration",
+				errs.ToAggregate().Error())
+		}
 
-	pgParameters := postgres.NewParameters()
-	pgaudit.PostgreSQLParameters(&pgParameters)
-	pgbackrest.PostgreSQL(cluster, &pgParameters)
-	pgmonitor.PostgreSQLParameters(cluster, &pgParameters)
+		if _, errs := postgres.SpecifiedHBAs(cluster); len(errs) > 0 {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "InvalidHBARule",
+				errs.ToAggregate().Error())
+		}
+	}
 
 	if err == nil {
+		ctx, span := r.Tracer.Start(ctx, "reconcile-root-certificate")
 		rootCA, err = r.reconcileRootCertificate(ctx, cluster)
+		span.RecordError(err)
+		span.End()
 	}
 
 	if err == nil {
@@ -242,11 +310,25 @@ func (r *Reconciler) Reconcile(
 	if err == nil {
 		instances, err = r.observeInstances(ctx, cluster)
 	}
+	if err == nil {
+		observeLocaleSupport(cluster, instances)
+	}
 	if err == nil {
 		err = updateResult(r.reconcilePatroniStatus(ctx, cluster, instances))
 	}
 	if err == nil {
-		err = r.reconcilePatroniSwitchover(ctx, cluster, instances)
+		r.reconcilePatroniPrimary(cluster, instances)
+		r.reconcilePatroniMembers(ctx, cluster, instances)
+	}
+	if err == nil {
+		queued, wait := r.queuePatroniSwitchover(cluster, time.Now())
+
+		if queued && wait > 0 {
+			// Check again when the switchover window opens.
+			result = updateReconcileResult(result, reconcile.Result{RequeueAfter: wait})
+		} else if !queued {
+			err = r.reconcilePatroniSwitchover(ctx, cluster, instances)
+		}
 	}
 	// reconcile the Pod service before reconciling any data source in case it is necessary
 	// to start Pods during data source reconciliation that require network connections (e.g.
@@ -278,12 +360,24 @@ func (r *Reconciler) Reconcile(
 			return patchClusterStatus()
 		}
 	}
+	// Upgrade the PostgreSQL data before any instance starts with the new
+	// major version. Instances stay down while the upgrade is in progress.
+	if err == nil {
+		var returnEarly bool
+		returnEarly, err = r.reconcileMajorUpgrade(ctx, cluster, instances, clusterVolumes)
+		if err != nil || returnEarly {
+			return patchClusterStatus()
+		}
+	}
 	if err == nil {
 		clusterConfigMap, err = r.reconcileClusterConfigMap(ctx, cluster, pgHBAs, pgParameters)
 	}
 	if err == nil {
 		clusterReplicationSecret, err = r.reconcileReplicationSecret(ctx, cluster, rootCA)
 	}
+	if err == nil {
+		err = r.reconcileCitusSecret(ctx, cluster, rootCA)
+	}
 	if err == nil {
 		patroniLeaderService, err = r.reconcilePatroniLeaderLease(ctx, cluster)
 	}
@@ -294,13 +388,41 @@ func (r *Reconciler) Reconcile(
 		err = r.reconcileClusterReplicaService(ctx, cluster)
 	}
 	if err == nil {
+		ctx, span := r.Tracer.Start(ctx, "reconcile-cluster-certificate")
 		primaryCertificate, err = r.reconcileClusterCertificate(ctx, rootCA, cluster, primaryService)
+		span.RecordError(err)
+		span.End()
 	}
 	if err == nil {
 		err = r.reconcilePatroniDistributedConfiguration(ctx, cluster)
 	}
 	if err == nil {
-		err = r.reconcilePatroniDynamicConfiguration(ctx, cluster, instances, pgHBAs, pgParameters)
+		err = r.reconcileCitusServices(ctx, cluster)
+	}
+	if err == nil {
+		err = r.reconcilePatroniAPI(ctx, cluster, rootCA)
+	}
+	if err == nil {
+		if r.reconcileStandbyPromotion(ctx, cluster, instances) {
+			// Leave "standby_cluster" in the dynamic configuration until the
+			// standby is safe to promote. Nothing signals when replay catches
+			// up, so check again soon.
+			result = updateReconcileResult(result, reconcile.Result{RequeueAfter: 10 * time.Second})
+		} else {
+			ctx, span := r.Tracer.Start(ctx, "reconcile-patroni-dynamic-configuration")
+			err = r.reconcilePatroniDynamicConfiguration(ctx, cluster, instances, pgHBAs, pgParameters)
+			span.RecordError(err)
+			span.End()
+		}
+	}
+	if err == nil {
+		result = updateReconcileResult(result, r.reconcileStandbyStatus(ctx, cluster, instances))
+	}
+	if err == nil {
+		result = updateReconcileResult(result, r.reconcileReplicationSlotStatus(ctx, cluster, instances))
+	}
+	if err == nil {
+		result = updateReconcileResult(result, r.reconcileReplicationLagStatus(ctx, cluster, instances))
 	}
 	if err == nil {
 		monitoringSecret, err = r.reconcileMonitoringSecret(ctx, cluster)
@@ -309,10 +431,16 @@ func (r *Reconciler) Reconcile(
 		exporterWebConfig, err = r.reconcileExporterWebConfig(ctx, cluster)
 	}
 	if err == nil {
+		err = r.reconcileLogShippingConfigMap(ctx, cluster)
+	}
+	if err == nil {
+		ctx, span := r.Tracer.Start(ctx, "reconcile-instance-sets")
 		err = r.reconcileInstanceSets(
 			ctx, cluster, clusterConfigMap, clusterReplicationSecret,
 			rootCA, clusterPodService, instanceServiceAccount, instances,
 			patroniLeaderService, primaryCertificate, clusterVolumes, exporterWebConfig)
+		span.RecordError(err)
+		span.End()
 	}
 
 	if err == nil {
@@ -323,19 +451,37 @@ func (r *Reconciler) Reconcile(
 	}
 
 	if err == nil {
+		ctx, span := r.Tracer.Start(ctx, "reconcile-pgbackrest")
 		err = updateResult(r.reconcilePGBackRest(ctx, cluster, instances, rootCA))
+		span.RecordError(err)
+		span.End()
 	}
 	if err == nil {
+		ctx, span := r.Tracer.Start(ctx, "reconcile-pgbouncer")
 		err = r.reconcilePGBouncer(ctx, cluster, instances, primaryCertificate, rootCA)
+		span.RecordError(err)
+		span.End()
+	}
+	if err == nil {
+		ctx, span := r.Tracer.Start(ctx, "reconcile-haproxy")
+		err = r.reconcileHAProxy(ctx, cluster)
+		span.RecordError(err)
+		span.End()
 	}
 	if err == nil {
 		err = r.reconcilePGMonitor(ctx, cluster, instances, monitoringSecret)
 	}
+	if err == nil {
+		err = r.reconcileMaintenance(ctx, cluster, instances, primaryCertificate)
+	}
 	if err == nil {
 		err = r.reconcileDatabaseInitSQL(ctx, cluster, instances)
 	}
 	if err == nil {
-		err = r.reconcilePGAdmin(ctx, cluster)
+		err = r.reconcileMigration(ctx, cluster, instances)
+	}
+	if err == nil {
+		err = r.reconcilePGAdmin(ctx, cluster, primaryCertificate)
 	}
 	if err == nil {
 		// This is after [Reconciler.rolloutInstances] to ensure that recreating
@@ -352,6 +498,29 @@ func (r *Reconciler) Reconcile(
 	return patchClusterStatus()
 }
 
+// postgresParameters returns the PostgreSQL parameters that the operator
+// requires or recommends for cluster.
+func postgresParameters(cluster *v1beta1.PostgresCluster) postgres.Parameters {
+	pgParameters := postgres.NewParameters()
+	postgres.TimezoneParameters(cluster, &pgParameters)
+	postgres.PasswordParameters(cluster, &pgParameters)
+	postgres.MemoryParameters(cluster, &pgParameters)
+	pgaudit.PostgreSQLParameters(&pgParameters)
+	pgaudit.Settings(cluster, &pgParameters)
+	pgpartman.PostgreSQLParameters(cluster, &pgParameters)
+	postgres.LogicalDecodingParameters(cluster, &pgParameters)
+	archive.PostgreSQL(cluster, &pgParameters)
+	pgmonitor.PostgreSQLParameters(cluster, &pgParameters)
+	logshipping.PostgreSQLParameters(cluster, &pgParameters)
+
+	// Extensions go after the libraries above so that TimescaleDB can load
+	// first. Citus goes ahead of everything.
+	postgres.ExtensionParameters(cluster, &pgParameters)
+	citus.PostgreSQLParameters(cluster, &pgParameters)
+
+	return pgParameters
+}
+
 // deleteControlled safely deletes object when it is controlled by cluster.
 func (r *Reconciler) deleteControlled(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, object client.Object,
@@ -416,6 +585,8 @@ func (r *Reconciler) setOwnerReference(
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch
 // +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch
 // +kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch
 
 // SetupWithManager adds the PostgresCluster controller to the provided runtime manager
 func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
@@ -458,7 +629,10 @@ func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
 		Owns(&rbacv1.RoleBinding{}).
 		Owns(&batchv1.CronJob{}).
 		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&networkingv1.Ingress{}).
+		Owns(&networkingv1.NetworkPolicy{}).
 		Watches(&source.Kind{Type: &corev1.Pod{}}, r.watchPods()).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, r.watchUserSecrets()).
 		Watches(&source.Kind{Type: &appsv1.StatefulSet{}},
 			r.controllerRefHandlerFuncs()). // watch all StatefulSets
 		Complete(r)
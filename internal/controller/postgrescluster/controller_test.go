@@ -47,6 +47,28 @@ import (
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
+func TestPostgresParameters(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.PostgresVersion = 14
+	cluster.Spec.Extensions = []v1beta1.PostgresExtensionSpec{{Name: "timescaledb"}}
+	cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+		PGMonitor: &v1beta1.PGMonitorSpec{
+			Exporter: &v1beta1.ExporterSpec{Image: "image"},
+		},
+	}
+
+	// TimescaleDB loads before the libraries of monitoring.
+	parameters := postgresParameters(cluster)
+	assert.Equal(t, parameters.Mandatory.Value("shared_preload_libraries"),
+		"timescaledb,pg_stat_statements,pgnodemx,pgaudit")
+
+	// Citus loads before TimescaleDB.
+	cluster.Spec.Citus = &v1beta1.CitusSpec{}
+	parameters = postgresParameters(cluster)
+	assert.Equal(t, parameters.Mandatory.Value("shared_preload_libraries"),
+		"citus,timescaledb,pg_stat_statements,pgnodemx,pgaudit")
+}
+
 func TestDeleteControlled(t *testing.T) {
 	ctx := context.Background()
 	_, cc := setupKubernetes(t)
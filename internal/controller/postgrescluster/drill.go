@@ -0,0 +1,282 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/patroni"
+	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// ConditionRestoreDrillSucceeded is the type used in a condition to indicate whether or not
+	// the latest restore drill restored, recovered, and validated the latest backup
+	ConditionRestoreDrillSucceeded = "PGBackRestRestoreDrillSucceeded"
+
+	// EventRestoreDrillSucceeded is the event reason utilized when a restore drill Job completes
+	// successfully
+	EventRestoreDrillSucceeded = "RestoreDrillSucceeded"
+
+	// EventRestoreDrillFailed is the event reason utilized when a restore drill Job fails
+	EventRestoreDrillFailed = "RestoreDrillFailed"
+)
+
+var (
+	restoreDrillSucceeded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "postgres_operator_restore_drill_succeeded",
+		Help: "Whether or not the latest finished restore drill of a PostgresCluster succeeded.",
+	}, []string{"namespace", "cluster"})
+
+	restoreDrillFinished = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "postgres_operator_restore_drill_finished_timestamp_seconds",
+		Help: "When the latest restore drill of a PostgresCluster finished, in seconds since the Unix epoch.",
+	}, []string{"namespace", "cluster"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(restoreDrillSucceeded, restoreDrillFinished)
+}
+
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=create;patch
+
+// reconcileRestoreDrill writes the CronJob of the restore drill in cluster and
+// reports on the latest Job it created. The CronJob and its Jobs are deleted
+// by [Reconciler.cleanupRepoResources] when the drill is removed from the spec.
+func (r *Reconciler) reconcileRestoreDrill(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, jobs []*batchv1.Job,
+) error {
+	if cluster.Spec.Backups.PGBackRest.RestoreDrill == nil {
+		cluster.Status.PGBackRest.RestoreDrill = nil
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, ConditionRestoreDrillSucceeded)
+		restoreDrillSucceeded.DeleteLabelValues(cluster.Namespace, cluster.Name)
+		restoreDrillFinished.DeleteLabelValues(cluster.Namespace, cluster.Name)
+		return nil
+	}
+
+	// There is nothing to restore until the cluster is bootstrapped.
+	if !patroni.ClusterBootstrapped(cluster) {
+		return nil
+	}
+
+	// Report a drill that can never succeed rather than schedule it.
+	var repoFound bool
+	for _, repo := range cluster.Spec.Backups.PGBackRest.Repos {
+		repoFound = repoFound || repo.Name == cluster.Spec.Backups.PGBackRest.RestoreDrill.RepoName
+	}
+	if !repoFound {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidRestoreDrill",
+			"Unable to find %q as configured for the restore drill. Please ensure "+
+				"this repo is defined in the spec.",
+			cluster.Spec.Backups.PGBackRest.RestoreDrill.RepoName)
+		return nil
+	}
+
+	cronjob := generateRestoreDrillCronJob(cluster)
+	err := errors.WithStack(r.setControllerReference(cluster, cronjob))
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, cronjob))
+	}
+
+	previous := cluster.Status.PGBackRest.RestoreDrill
+	latest := restoreDrillStatus(jobs)
+	cluster.Status.PGBackRest.RestoreDrill = latest
+
+	if latest == nil || !latest.Finished {
+		return err
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionRestoreDrillSucceeded,
+		Status:             metav1.ConditionTrue,
+		Reason:             "DrillSucceeded",
+		Message:            "The latest restore drill restored and validated a backup.",
+		ObservedGeneration: cluster.GetGeneration(),
+	}
+	if latest.Succeeded == 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "DrillFailed"
+		condition.Message = "The latest restore drill failed; the logs of Job " +
+			latest.ID + " describe why."
+	}
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+
+	// Announce and measure each drill once, when it finishes.
+	if previous == nil || previous.ID != latest.ID || !previous.Finished {
+		var value float64
+		if condition.Status == metav1.ConditionTrue {
+			value = 1
+			r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventRestoreDrillSucceeded,
+				"Restore drill %q restored and validated a backup", latest.ID)
+		} else {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventRestoreDrillFailed,
+				"Restore drill %q failed", latest.ID)
+		}
+
+		restoreDrillSucceeded.WithLabelValues(cluster.Namespace, cluster.Name).Set(value)
+		if latest.CompletionTime != nil {
+			restoreDrillFinished.WithLabelValues(cluster.Namespace, cluster.Name).
+				Set(float64(latest.CompletionTime.Unix()))
+		} else {
+			restoreDrillFinished.WithLabelValues(cluster.Namespace, cluster.Name).SetToCurrentTime()
+		}
+	}
+
+	return err
+}
+
+// restoreDrillStatus returns the status of the most recently created Job in
+// jobs, or nil when there are none.
+func restoreDrillStatus(jobs []*batchv1.Job) *v1beta1.PGBackRestJobStatus {
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	sorted := append([]*batchv1.Job{}, jobs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].CreationTimestamp.Before(&sorted[j].CreationTimestamp)
+	})
+	job := sorted[len(sorted)-1]
+
+	return &v1beta1.PGBackRestJobStatus{
+		ID:             job.Name,
+		Finished:       jobCompleted(job) || jobFailed(job),
+		StartTime:      job.Status.StartTime,
+		CompletionTime: job.Status.CompletionTime,
+		Active:         job.Status.Active,
+		Succeeded:      job.Status.Succeeded,
+		Failed:         job.Status.Failed,
+	}
+}
+
+// generateRestoreDrillCronJob returns the CronJob that restores the latest
+// backup of cluster into a scratch volume on the schedule of its drill.
+func generateRestoreDrillCronJob(cluster *v1beta1.PostgresCluster) *batchv1.CronJob {
+	drill := cluster.Spec.Backups.PGBackRest.RestoreDrill
+
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.PGBackRestRestoreDrillCronJob(cluster)}
+	cronjob.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("CronJob"))
+
+	annotations := naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		cluster.Spec.Backups.PGBackRest.Metadata.GetAnnotationsOrNil())
+	labels := naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		cluster.Spec.Backups.PGBackRest.Metadata.GetLabelsOrNil(),
+		naming.PGBackRestRestoreDrillLabels(cluster.Name))
+
+	cronjob.Annotations = annotations
+	cronjob.Labels = labels
+
+	cronjob.Spec.Schedule = drill.Schedule
+	cronjob.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+	cronjob.Spec.JobTemplate.Annotations = annotations
+	cronjob.Spec.JobTemplate.Labels = labels
+	cronjob.Spec.JobTemplate.Spec.Template.Annotations = annotations
+	cronjob.Spec.JobTemplate.Spec.Template.Labels = labels
+
+	// A drill that fails should be reported rather than retried.
+	cronjob.Spec.JobTemplate.Spec.BackoffLimit = initialize.Int32(0)
+
+	// Suspend when shutdown. Any drills that have already started will continue.
+	cronjob.Spec.Suspend = initialize.Bool(
+		cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown)
+
+	if tz := cluster.Spec.Config.Timezone; tz != "" {
+		cronjob.Spec.TimeZone = &tz
+	}
+
+	pgdata := postgres.DataDirectory(cluster)
+	opts := []string{
+		"--stanza=" + pgbackrest.DefaultStanzaName,
+		"--pg1-path=" + pgdata,
+		"--repo=" + regexRepoIndex.FindString(drill.RepoName),
+	}
+
+	// The scratch data directory is a generic ephemeral volume. Kubernetes
+	// creates its claim with the Pod and deletes it with the Pod.
+	dataVolumeMount := postgres.DataVolumeMount()
+	dataVolume := corev1.Volume{Name: dataVolumeMount.Name}
+	dataVolume.Ephemeral = &corev1.EphemeralVolumeSource{
+		VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+			ObjectMeta: metav1.ObjectMeta{Annotations: annotations, Labels: labels},
+			Spec:       drill.DataVolumeClaimSpec,
+		},
+	}
+
+	template := &cronjob.Spec.JobTemplate.Spec.Template
+	template.Spec.Containers = []corev1.Container{{
+		Name:    naming.PGBackRestRestoreContainerName,
+		Command: pgbackrest.RestoreDrillCommand(pgdata, strings.Join(opts, " "), drill.SQL),
+		Env:     []corev1.EnvVar{{Name: "PGHOST", Value: "/tmp"}},
+
+		Image:           config.PostgresContainerImage(cluster),
+		ImagePullPolicy: cluster.Spec.ImagePullPolicy,
+		Resources:       drill.Resources,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+		VolumeMounts:    []corev1.VolumeMount{dataVolumeMount},
+	}}
+	template.Spec.Volumes = []corev1.Volume{dataVolume}
+
+	// Set the priority class name, tolerations, and affinity of backup Jobs.
+	if jobs := cluster.Spec.Backups.PGBackRest.Jobs; jobs != nil {
+		if jobs.PriorityClassName != nil {
+			template.Spec.PriorityClassName = *jobs.PriorityClassName
+		}
+		template.Spec.Tolerations = jobs.Tolerations
+		template.Spec.Affinity = jobs.Affinity
+	}
+
+	// The drill reads the repositories the same way instances do.
+	pgbackrest.AddConfigToInstancePod(cluster, &template.Spec)
+
+	addNSSWrapper(
+		config.PGBackRestContainerImage(cluster),
+		cluster.Spec.ImagePullPolicy,
+		template)
+
+	addTMPEmptyDir(template)
+
+	// pgBackRest does not make any Kubernetes API calls, but it may interact
+	// with a cloud storage provider. Use the instance ServiceAccount for its
+	// possible cloud identity without mounting its Kubernetes API credentials.
+	template.Spec.AutomountServiceAccountToken = initialize.Bool(false)
+	template.Spec.ServiceAccountName = naming.ClusterInstanceRBAC(cluster).Name
+
+	// Disable environment variables for services other than the Kubernetes API.
+	template.Spec.EnableServiceLinks = initialize.Bool(false)
+
+	template.Spec.ImagePullSecrets = cluster.Spec.ImagePullSecrets
+	template.Spec.RestartPolicy = corev1.RestartPolicyNever
+	template.Spec.SecurityContext = postgres.PodSecurityContext(cluster)
+
+	return cronjob
+}
@@ -0,0 +1,123 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGenerateRestoreDrillCronJob(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Name = "pg1"
+	cluster.Spec.PostgresVersion = 14
+	cluster.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "pull"}}
+	cluster.Spec.Backups.PGBackRest.RestoreDrill = &v1beta1.PGBackRestRestoreDrill{
+		Schedule: "0 4 * * 0",
+		RepoName: "repo2",
+		SQL:      "SELECT count(*) FROM orders",
+	}
+
+	cronjob := generateRestoreDrillCronJob(cluster)
+
+	assert.Equal(t, cronjob.Namespace, "ns1")
+	assert.Equal(t, cronjob.Name, "pg1-pgbackrest-drill")
+	assert.Equal(t, cronjob.Spec.Schedule, "0 4 * * 0")
+	assert.Equal(t, cronjob.Spec.ConcurrencyPolicy, batchv1.ForbidConcurrent)
+	assert.Equal(t, *cronjob.Spec.Suspend, false)
+	assert.Equal(t, *cronjob.Spec.JobTemplate.Spec.BackoffLimit, int32(0))
+	assert.DeepEqual(t, cronjob.Labels, map[string]string{
+		"postgres-operator.crunchydata.com/cluster":                  "pg1",
+		"postgres-operator.crunchydata.com/pgbackrest":               "",
+		"postgres-operator.crunchydata.com/pgbackrest-restore-drill": "",
+	})
+
+	pod := cronjob.Spec.JobTemplate.Spec.Template.Spec
+	assert.DeepEqual(t, cronjob.Spec.JobTemplate.Spec.Template.Labels, cronjob.Labels)
+	assert.Equal(t, pod.RestartPolicy, corev1.RestartPolicyNever)
+	assert.Equal(t, *pod.AutomountServiceAccountToken, false)
+	assert.Equal(t, pod.ServiceAccountName, "pg1-instance")
+	assert.DeepEqual(t, pod.ImagePullSecrets, cluster.Spec.ImagePullSecrets)
+
+	container := pod.Containers[0]
+	assert.Equal(t, container.Name, naming.PGBackRestRestoreContainerName)
+	assert.Equal(t, container.Command[len(container.Command)-2],
+		"--stanza=db --pg1-path=/pgdata/pg14 --repo=2")
+	assert.Equal(t, container.Command[len(container.Command)-1], "SELECT count(*) FROM orders")
+
+	var mounts []string
+	for _, mount := range container.VolumeMounts {
+		mounts = append(mounts, mount.MountPath)
+	}
+	assert.Equal(t, strings.Join(mounts, " "), "/pgdata /etc/pgbackrest/conf.d /tmp")
+
+	// The data directory is ephemeral.
+	assert.Equal(t, pod.Volumes[0].Name, "postgres-data")
+	assert.Assert(t, pod.Volumes[0].Ephemeral != nil)
+
+	t.Run("Suspended", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Shutdown = initialize.Bool(true)
+
+		cronjob := generateRestoreDrillCronJob(cluster)
+		assert.Equal(t, *cronjob.Spec.Suspend, true)
+	})
+}
+
+func TestRestoreDrillStatus(t *testing.T) {
+	assert.Assert(t, restoreDrillStatus(nil) == nil)
+
+	now := metav1.Now()
+	older := &batchv1.Job{}
+	older.Name = "older"
+	older.CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+	older.Status.Succeeded = 1
+	older.Status.Conditions = []batchv1.JobCondition{{
+		Type: batchv1.JobComplete, Status: corev1.ConditionTrue,
+	}}
+
+	newer := &batchv1.Job{}
+	newer.Name = "newer"
+	newer.CreationTimestamp = now
+	newer.Status.Active = 1
+
+	status := restoreDrillStatus([]*batchv1.Job{newer, older})
+	assert.Equal(t, status.ID, "newer")
+	assert.Equal(t, status.Finished, false)
+	assert.Equal(t, status.Active, int32(1))
+
+	newer.Status.Active = 0
+	newer.Status.Failed = 1
+	newer.Status.Conditions = []batchv1.JobCondition{{
+		Type: batchv1.JobFailed, Status: corev1.ConditionTrue,
+	}}
+
+	status = restoreDrillStatus([]*batchv1.Job{older, newer})
+	assert.Equal(t, status.ID, "newer")
+	assert.Equal(t, status.Finished, true)
+	assert.Equal(t, status.Failed, int32(1))
+}
@@ -0,0 +1,449 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/haproxy"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// reconcileHAProxy writes the objects necessary to run an HAProxy Pod.
+func (r *Reconciler) reconcileHAProxy(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	defer timeReconciler("haProxy").ObserveDuration()
+
+	var configmap *corev1.ConfigMap
+
+	err := r.reconcileHAProxyBackendsService(ctx, cluster)
+	if err == nil {
+		err = r.reconcileHAProxyService(ctx, cluster)
+	}
+	if err == nil {
+		configmap, err = r.reconcileHAProxyConfigMap(ctx, cluster)
+	}
+	if err == nil {
+		err = r.reconcileHAProxyDeployment(ctx, cluster, configmap)
+	}
+	return err
+}
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=create;delete;patch
+
+// reconcileHAProxyConfigMap writes the ConfigMap for an HAProxy Pod.
+func (r *Reconciler) reconcileHAProxyConfigMap(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) (*corev1.ConfigMap, error) {
+	configmap := &corev1.ConfigMap{ObjectMeta: naming.ClusterHAProxy(cluster)}
+	configmap.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+
+	if cluster.Spec.Proxy == nil || cluster.Spec.Proxy.HAProxy == nil {
+		// HAProxy is disabled; delete the ConfigMap if it exists. Check the
+		// client cache first using Get.
+		key := client.ObjectKeyFromObject(configmap)
+		err := errors.WithStack(r.Client.Get(ctx, key, configmap))
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, configmap))
+		}
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	err := errors.WithStack(r.setControllerReference(cluster, configmap))
+
+	configmap.Annotations = naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		cluster.Spec.Proxy.HAProxy.Metadata.GetAnnotationsOrNil())
+	configmap.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		cluster.Spec.Proxy.HAProxy.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleHAProxy,
+		})
+
+	if err == nil {
+		haproxy.ConfigMap(ctx, cluster, configmap)
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, configmap))
+	}
+
+	return configmap, err
+}
+
+// generateHAProxyBackendsService returns a v1.Service that HAProxy uses to
+// discover PostgreSQL instances.
+func (r *Reconciler) generateHAProxyBackendsService(
+	cluster *v1beta1.PostgresCluster) (*corev1.Service, bool, error,
+) {
+	service := &corev1.Service{ObjectMeta: naming.ClusterHAProxyBackends(cluster)}
+	service.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Service"))
+
+	if cluster.Spec.Proxy == nil || cluster.Spec.Proxy.HAProxy == nil {
+		return service, false, nil
+	}
+
+	service.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+	service.Labels = naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleHAProxy,
+		})
+
+	// Allocate no IP address (headless) and match every PostgreSQL instance,
+	// regardless of its readiness. HAProxy resolves the addresses of these Pods
+	// and decides which to use by asking Patroni about each one.
+	// - https://docs.k8s.io/concepts/services-networking/service/#headless-services
+	service.Spec.ClusterIP = corev1.ClusterIPNone
+	service.Spec.PublishNotReadyAddresses = true
+	service.Spec.Selector = map[string]string{
+		naming.LabelCluster: cluster.Name,
+		naming.LabelData:    naming.DataPostgres,
+	}
+
+	err := errors.WithStack(r.setControllerReference(cluster, service))
+
+	return service, true, err
+}
+
+// +kubebuilder:rbac:groups="",resources="services",verbs={get}
+// +kubebuilder:rbac:groups="",resources="services",verbs={create,delete,patch}
+
+// reconcileHAProxyBackendsService writes the Service that HAProxy uses to
+// discover PostgreSQL instances.
+func (r *Reconciler) reconcileHAProxyBackendsService(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	service, specified, err := r.generateHAProxyBackendsService(cluster)
+
+	if err == nil && !specified {
+		// HAProxy is disabled; delete the Service if it exists. Check the
+		// client cache first using Get.
+		key := client.ObjectKeyFromObject(service)
+		err := errors.WithStack(r.Client.Get(ctx, key, service))
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, service))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, service))
+	}
+	return err
+}
+
+// generateHAProxyService returns a v1.Service that exposes HAProxy pods.
+// The ServiceType comes from the cluster proxy spec.
+func (r *Reconciler) generateHAProxyService(
+	cluster *v1beta1.PostgresCluster) (*corev1.Service, bool, error,
+) {
+	service := &corev1.Service{ObjectMeta: naming.ClusterHAProxy(cluster)}
+	service.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Service"))
+
+	if cluster.Spec.Proxy == nil || cluster.Spec.Proxy.HAProxy == nil {
+		return service, false, nil
+	}
+
+	service.Annotations = naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		cluster.Spec.Proxy.HAProxy.Metadata.GetAnnotationsOrNil())
+	service.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		cluster.Spec.Proxy.HAProxy.Metadata.GetLabelsOrNil())
+
+	if spec := cluster.Spec.Proxy.HAProxy.Service; spec != nil {
+		service.Annotations = naming.Merge(service.Annotations,
+			spec.Metadata.GetAnnotationsOrNil())
+		service.Labels = naming.Merge(service.Labels,
+			spec.Metadata.GetLabelsOrNil())
+	}
+
+	// add our labels last so they aren't overwritten
+	service.Labels = naming.Merge(service.Labels,
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleHAProxy,
+		})
+
+	// Allocate an IP address and/or node port and let Kubernetes manage the
+	// Endpoints by selecting Pods with the HAProxy role.
+	// - https://docs.k8s.io/concepts/services-networking/service/#defining-a-service
+	service.Spec.Selector = map[string]string{
+		naming.LabelCluster: cluster.Name,
+		naming.LabelRole:    naming.RoleHAProxy,
+	}
+
+	// The TargetPorts must be the names (not the numbers) of the HAProxy
+	// ContainerPorts. This allows the port numbers to differ between Pods,
+	// which can happen during a rolling update.
+	primaryPort := corev1.ServicePort{
+		Name:       naming.PortHAProxyPrimary,
+		Port:       *cluster.Spec.Proxy.HAProxy.Port,
+		Protocol:   corev1.ProtocolTCP,
+		TargetPort: intstr.FromString(naming.PortHAProxyPrimary),
+	}
+	replicaPort := corev1.ServicePort{
+		Name:       naming.PortHAProxyReplica,
+		Port:       *cluster.Spec.Proxy.HAProxy.ReplicaPort,
+		Protocol:   corev1.ProtocolTCP,
+		TargetPort: intstr.FromString(naming.PortHAProxyReplica),
+	}
+
+	if spec := cluster.Spec.Proxy.HAProxy.Service; spec == nil {
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+	} else {
+		service.Spec.Type = corev1.ServiceType(spec.Type)
+		service.Spec.IPFamilies = spec.IPFamilies
+		service.Spec.IPFamilyPolicy = spec.IPFamilyPolicy
+		if spec.NodePort != nil {
+			if service.Spec.Type == corev1.ServiceTypeClusterIP {
+				// The NodePort can only be set when the Service type is NodePort or
+				// LoadBalancer. Log an Event and return an error like the other
+				// Services of the cluster.
+				r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "MisconfiguredClusterIP",
+					"NodePort cannot be set with type ClusterIP on Service %q", service.Name)
+				return nil, true, fmt.Errorf("NodePort cannot be set with type ClusterIP on Service %q", service.Name)
+			}
+			// The NodePort applies to the primary port; Kubernetes allocates
+			// one for the replica port.
+			primaryPort.NodePort = *spec.NodePort
+		}
+		setServiceExternalTraffic(spec, service)
+		setServiceRouting(spec, service)
+	}
+	service.Spec.Ports = []corev1.ServicePort{primaryPort, replicaPort}
+
+	err := errors.WithStack(r.setControllerReference(cluster, service))
+
+	return service, true, err
+}
+
+// +kubebuilder:rbac:groups="",resources="services",verbs={get}
+// +kubebuilder:rbac:groups="",resources="services",verbs={create,delete,patch}
+
+// reconcileHAProxyService writes the Service that resolves to HAProxy.
+func (r *Reconciler) reconcileHAProxyService(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	service, specified, err := r.generateHAProxyService(cluster)
+
+	if err == nil && !specified {
+		// HAProxy is disabled; delete the Service if it exists. Check the
+		// client cache first using Get.
+		key := client.ObjectKeyFromObject(service)
+		err := errors.WithStack(r.Client.Get(ctx, key, service))
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, service))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, service))
+	}
+	return err
+}
+
+// generateHAProxyDeployment returns an appsv1.Deployment that runs HAProxy pods.
+func (r *Reconciler) generateHAProxyDeployment(
+	cluster *v1beta1.PostgresCluster, configmap *corev1.ConfigMap,
+) (*appsv1.Deployment, bool, error) {
+	deploy := &appsv1.Deployment{ObjectMeta: naming.ClusterHAProxy(cluster)}
+	deploy.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+
+	if cluster.Spec.Proxy == nil || cluster.Spec.Proxy.HAProxy == nil {
+		return deploy, false, nil
+	}
+
+	deploy.Annotations = naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		cluster.Spec.Proxy.HAProxy.Metadata.GetAnnotationsOrNil())
+	deploy.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		cluster.Spec.Proxy.HAProxy.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleHAProxy,
+		})
+	deploy.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleHAProxy,
+		},
+	}
+	deploy.Spec.Template.Annotations = naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		cluster.Spec.Proxy.HAProxy.Metadata.GetAnnotationsOrNil())
+	deploy.Spec.Template.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		cluster.Spec.Proxy.HAProxy.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleHAProxy,
+		})
+
+	// if the shutdown flag is set, set HAProxy replicas to 0
+	if cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown {
+		deploy.Spec.Replicas = initialize.Int32(0)
+	} else {
+		deploy.Spec.Replicas = cluster.Spec.Proxy.HAProxy.Replicas
+	}
+
+	// Don't clutter the namespace with extra ReplicaSets.
+	deploy.Spec.RevisionHistoryLimit = initialize.Int32(0)
+
+	// Ensure that the number of Ready pods is never less than the specified
+	// Replicas by starting new pods while old pods are still running.
+	// - https://docs.k8s.io/concepts/workloads/controllers/deployment/#rolling-update-deployment
+	deploy.Spec.Strategy.Type = appsv1.RollingUpdateDeploymentStrategyType
+	deploy.Spec.Strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{
+		MaxUnavailable: intstr.ValueOrDefault(nil, intstr.FromInt(0)),
+	}
+
+	// Use scheduling constraints from the cluster spec.
+	deploy.Spec.Template.Spec.Affinity = cluster.Spec.Proxy.HAProxy.Affinity
+	deploy.Spec.Template.Spec.Tolerations = cluster.Spec.Proxy.HAProxy.Tolerations
+
+	if cluster.Spec.Proxy.HAProxy.PriorityClassName != nil {
+		deploy.Spec.Template.Spec.PriorityClassName = *cluster.Spec.Proxy.HAProxy.PriorityClassName
+	}
+
+	deploy.Spec.Template.Spec.TopologySpreadConstraints =
+		cluster.Spec.Proxy.HAProxy.TopologySpreadConstraints
+
+	// if default pod scheduling is not explicitly disabled, add the default
+	// pod topology spread constraints
+	if cluster.Spec.DisableDefaultPodScheduling == nil ||
+		(cluster.Spec.DisableDefaultPodScheduling != nil &&
+			!*cluster.Spec.DisableDefaultPodScheduling) {
+		deploy.Spec.Template.Spec.TopologySpreadConstraints = append(
+			deploy.Spec.Template.Spec.TopologySpreadConstraints,
+			defaultTopologySpreadConstraints(*deploy.Spec.Selector)...)
+	}
+
+	// Restart containers any time they stop, die, are killed, etc.
+	// - https://docs.k8s.io/concepts/workloads/pods/pod-lifecycle/#restart-policy
+	deploy.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+
+	// There's no need for individual DNS names of HAProxy pods.
+	deploy.Spec.Template.Spec.Subdomain = ""
+
+	// HAProxy does not make any Kubernetes API calls. Use the default
+	// ServiceAccount and do not mount its credentials.
+	deploy.Spec.Template.Spec.AutomountServiceAccountToken = initialize.Bool(false)
+
+	// Do not add environment variables describing services in this namespace.
+	deploy.Spec.Template.Spec.EnableServiceLinks = initialize.Bool(false)
+
+	deploy.Spec.Template.Spec.SecurityContext = initialize.PodSecurityContext()
+
+	// set the image pull secrets, if any exist
+	deploy.Spec.Template.Spec.ImagePullSecrets = cluster.Spec.ImagePullSecrets
+
+	err := errors.WithStack(r.setControllerReference(cluster, deploy))
+
+	if err == nil {
+		haproxy.Pod(cluster, configmap, &deploy.Spec.Template.Spec)
+
+		// HAProxy reads its configuration file only when it starts. Roll out
+		// new pods whenever the configuration changes.
+		var revision string
+		revision, err = safeHash32(func(hasher io.Writer) error {
+			_, err := fmt.Fprint(hasher, configmap.Data)
+			return err
+		})
+		deploy.Spec.Template.Annotations = naming.Merge(
+			deploy.Spec.Template.Annotations,
+			map[string]string{naming.HAProxyConfigHash: revision})
+	}
+
+	return deploy, true, err
+}
+
+// +kubebuilder:rbac:groups="apps",resources="deployments",verbs={get}
+// +kubebuilder:rbac:groups="apps",resources="deployments",verbs={create,delete,patch}
+
+// reconcileHAProxyDeployment writes the Deployment that runs HAProxy.
+func (r *Reconciler) reconcileHAProxyDeployment(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, configmap *corev1.ConfigMap,
+) error {
+	deploy, specified, err := r.generateHAProxyDeployment(cluster, configmap)
+
+	// Set observations whether the deployment exists or not.
+	defer func() {
+		cluster.Status.Proxy.HAProxy.Replicas = deploy.Status.Replicas
+		cluster.Status.Proxy.HAProxy.ReadyReplicas = deploy.Status.ReadyReplicas
+
+		// The PgBouncer reconciler removes the ProxyAvailable condition when
+		// PgBouncer is disabled. Report on HAProxy only when it is enabled.
+		if !specified {
+			return
+		}
+
+		var available *appsv1.DeploymentCondition
+		for i := range deploy.Status.Conditions {
+			if deploy.Status.Conditions[i].Type == appsv1.DeploymentAvailable {
+				available = &deploy.Status.Conditions[i]
+			}
+		}
+
+		if available == nil {
+			meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.ProxyAvailable)
+		} else {
+			meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+				Type:    v1beta1.ProxyAvailable,
+				Status:  metav1.ConditionStatus(available.Status),
+				Reason:  available.Reason,
+				Message: available.Message,
+
+				LastTransitionTime: available.LastTransitionTime,
+				ObservedGeneration: cluster.Generation,
+			})
+		}
+	}()
+
+	if err == nil && !specified {
+		// HAProxy is disabled; delete the Deployment if it exists. Check the
+		// client cache first using Get.
+		key := client.ObjectKeyFromObject(deploy)
+		err := errors.WithStack(r.Client.Get(ctx, key, deploy))
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, deploy))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, deploy))
+	}
+	return err
+}
@@ -0,0 +1,285 @@
+//go:build envtest
+// +build envtest
+
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/require"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGenerateHAProxyBackendsService(t *testing.T) {
+	_, cc := setupKubernetes(t)
+	require.ParallelCapacity(t, 0)
+
+	reconciler := &Reconciler{Client: cc}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns2"
+	cluster.Name = "pg4"
+
+	t.Run("Unspecified", func(t *testing.T) {
+		service, specified, err := reconciler.generateHAProxyBackendsService(cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, !specified)
+
+		assert.Assert(t, marshalMatches(service.ObjectMeta, `
+creationTimestamp: null
+name: pg4-haproxy-backends
+namespace: ns2
+		`))
+	})
+
+	cluster.Spec.Proxy = &v1beta1.PostgresProxySpec{
+		HAProxy: &v1beta1.HAProxyPodSpec{},
+	}
+	cluster.Default()
+
+	service, specified, err := reconciler.generateHAProxyBackendsService(cluster)
+	assert.NilError(t, err)
+	assert.Assert(t, specified)
+
+	// Headless, including Pods that are not ready, and no ports.
+	assert.Assert(t, marshalMatches(service.Spec, `
+clusterIP: None
+publishNotReadyAddresses: true
+selector:
+  postgres-operator.crunchydata.com/cluster: pg4
+  postgres-operator.crunchydata.com/data: postgres
+	`))
+}
+
+func TestGenerateHAProxyService(t *testing.T) {
+	_, cc := setupKubernetes(t)
+	require.ParallelCapacity(t, 0)
+
+	reconciler := &Reconciler{
+		Client:   cc,
+		Recorder: new(record.FakeRecorder),
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns5"
+	cluster.Name = "pg7"
+
+	t.Run("Unspecified", func(t *testing.T) {
+		for _, spec := range []*v1beta1.PostgresProxySpec{
+			nil, {PGBouncer: new(v1beta1.PGBouncerPodSpec)},
+		} {
+			cluster := cluster.DeepCopy()
+			cluster.Spec.Proxy = spec
+
+			service, specified, err := reconciler.generateHAProxyService(cluster)
+			assert.NilError(t, err)
+			assert.Assert(t, !specified)
+
+			assert.Assert(t, marshalMatches(service.ObjectMeta, `
+creationTimestamp: null
+name: pg7-haproxy
+namespace: ns5
+			`))
+		}
+	})
+
+	cluster.Spec.Proxy = &v1beta1.PostgresProxySpec{
+		HAProxy: &v1beta1.HAProxyPodSpec{
+			Port:        initialize.Int32(9651),
+			ReplicaPort: initialize.Int32(9652),
+		},
+	}
+
+	t.Run("NoServiceSpec", func(t *testing.T) {
+		service, specified, err := reconciler.generateHAProxyService(cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, specified)
+
+		assert.Assert(t, marshalMatches(service.ObjectMeta, `
+creationTimestamp: null
+labels:
+  postgres-operator.crunchydata.com/cluster: pg7
+  postgres-operator.crunchydata.com/role: haproxy
+name: pg7-haproxy
+namespace: ns5
+ownerReferences:
+- apiVersion: postgres-operator.crunchydata.com/v1beta1
+  blockOwnerDeletion: true
+  controller: true
+  kind: PostgresCluster
+  name: pg7
+  uid: ""
+		`))
+		assert.Assert(t, marshalMatches(service.Spec, `
+ports:
+- name: haproxy-primary
+  port: 9651
+  protocol: TCP
+  targetPort: haproxy-primary
+- name: haproxy-replica
+  port: 9652
+  protocol: TCP
+  targetPort: haproxy-replica
+selector:
+  postgres-operator.crunchydata.com/cluster: pg7
+  postgres-operator.crunchydata.com/role: haproxy
+type: ClusterIP
+		`))
+	})
+
+	t.Run("NodePort", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Proxy.HAProxy.Service = &v1beta1.ServiceSpec{
+			Type:     "NodePort",
+			NodePort: initialize.Int32(32001),
+		}
+
+		service, specified, err := reconciler.generateHAProxyService(cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, specified)
+		assert.Equal(t, service.Spec.Type, corev1.ServiceTypeNodePort)
+
+		// Only the primary port gets the specified node port.
+		assert.Assert(t, marshalMatches(service.Spec.Ports, `
+- name: haproxy-primary
+  nodePort: 32001
+  port: 9651
+  protocol: TCP
+  targetPort: haproxy-primary
+- name: haproxy-replica
+  port: 9652
+  protocol: TCP
+  targetPort: haproxy-replica
+		`))
+	})
+
+	t.Run("NodePortWithClusterIP", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Proxy.HAProxy.Service = &v1beta1.ServiceSpec{
+			Type:     "ClusterIP",
+			NodePort: initialize.Int32(32001),
+		}
+
+		_, specified, err := reconciler.generateHAProxyService(cluster)
+		assert.ErrorContains(t, err, "NodePort cannot be set with type ClusterIP")
+		assert.Assert(t, specified)
+	})
+}
+
+func TestGenerateHAProxyDeployment(t *testing.T) {
+	_, cc := setupKubernetes(t)
+	require.ParallelCapacity(t, 0)
+
+	reconciler := &Reconciler{Client: cc}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns3"
+	cluster.Name = "test-cluster"
+
+	t.Run("Unspecified", func(t *testing.T) {
+		deploy, specified, err := reconciler.generateHAProxyDeployment(cluster, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, !specified)
+
+		assert.Assert(t, marshalMatches(deploy.ObjectMeta, `
+creationTimestamp: null
+name: test-cluster-haproxy
+namespace: ns3
+		`))
+	})
+
+	cluster.Spec.Proxy = &v1beta1.PostgresProxySpec{
+		HAProxy: &v1beta1.HAProxyPodSpec{},
+	}
+	cluster.Default()
+
+	configmap := &corev1.ConfigMap{}
+	configmap.Name = "some-cm2"
+
+	t.Run("PodSpec", func(t *testing.T) {
+		deploy, specified, err := reconciler.generateHAProxyDeployment(cluster, configmap)
+		assert.NilError(t, err)
+		assert.Assert(t, specified)
+
+		assert.DeepEqual(t, deploy.Spec.Selector.MatchLabels, map[string]string{
+			"postgres-operator.crunchydata.com/cluster": "test-cluster",
+			"postgres-operator.crunchydata.com/role":    "haproxy",
+		})
+		assert.Equal(t, *deploy.Spec.Replicas, int32(1))
+
+		// Containers and Volumes should be populated.
+		assert.Assert(t, len(deploy.Spec.Template.Spec.Containers) != 0)
+		assert.Assert(t, len(deploy.Spec.Template.Spec.Volumes) != 0)
+
+		// Ignore Containers and Volumes in the comparison below.
+		deploy.Spec.Template.Spec.Containers = nil
+		deploy.Spec.Template.Spec.Volumes = nil
+
+		assert.Assert(t, marshalMatches(deploy.Spec.Template.Spec, `
+automountServiceAccountToken: false
+containers: null
+enableServiceLinks: false
+restartPolicy: Always
+securityContext:
+  fsGroupChangePolicy: OnRootMismatch
+topologySpreadConstraints:
+- labelSelector:
+    matchLabels:
+      postgres-operator.crunchydata.com/cluster: test-cluster
+      postgres-operator.crunchydata.com/role: haproxy
+  maxSkew: 1
+  topologyKey: kubernetes.io/hostname
+  whenUnsatisfiable: ScheduleAnyway
+- labelSelector:
+    matchLabels:
+      postgres-operator.crunchydata.com/cluster: test-cluster
+      postgres-operator.crunchydata.com/role: haproxy
+  maxSkew: 1
+  topologyKey: topology.kubernetes.io/zone
+  whenUnsatisfiable: ScheduleAnyway
+		`))
+	})
+
+	t.Run("ConfigHash", func(t *testing.T) {
+		deploy, _, err := reconciler.generateHAProxyDeployment(cluster, configmap)
+		assert.NilError(t, err)
+		before := deploy.Spec.Template.Annotations["postgres-operator.crunchydata.com/haproxy-hash"]
+		assert.Assert(t, before != "")
+
+		changed := configmap.DeepCopy()
+		changed.Data = map[string]string{"haproxy.cfg": "changed"}
+
+		deploy, _, err = reconciler.generateHAProxyDeployment(cluster, changed)
+		assert.NilError(t, err)
+		assert.Assert(t, deploy.Spec.Template.Annotations["postgres-operator.crunchydata.com/haproxy-hash"] != before)
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Shutdown = initialize.Bool(true)
+
+		deploy, _, err := reconciler.generateHAProxyDeployment(cluster, configmap)
+		assert.NilError(t, err)
+		assert.Equal(t, *deploy.Spec.Replicas, int32(0))
+	})
+}
@@ -17,9 +17,12 @@ package postgrescluster
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
+	"path"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -37,9 +40,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/crunchydata/postgres-operator/internal/archive"
 	"github.com/crunchydata/postgres-operator/internal/config"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/logshipping"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/patroni"
 	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
@@ -133,6 +138,12 @@ func (i Instance) IsWritable() (writable, known bool) {
 		return false, false
 	}
 
+	// Patroni labels Citus workers differently than the coordinator. Workers
+	// accept writes only for their own group, not the entire cluster.
+	if _, worker := i.Pods[0].Labels[naming.LabelCitusRole]; worker {
+		return false, true
+	}
+
 	member := i.Pods[0].Annotations["status"]
 	role := strings.Index(member, `"role":`)
 
@@ -145,6 +156,18 @@ func (i Instance) IsWritable() (writable, known bool) {
 	return strings.HasPrefix(member[role:], `"role":"master"`), true
 }
 
+// PodDiffersOnlyInImages returns whether or not the Pod for this instance
+// matches its specified PodTemplate in everything but container images.
+// See [annotateTemplateWithoutImages].
+func (i Instance) PodDiffersOnlyInImages() (only bool, known bool) {
+	if i.Runner == nil || len(i.Pods) != 1 {
+		return false, false
+	}
+
+	hash := i.Runner.Spec.Template.Annotations[naming.PodTemplateHashWithoutImages]
+	return hash != "" && hash == i.Pods[0].Annotations[naming.PodTemplateHashWithoutImages], true
+}
+
 // PodMatchesPodTemplate returns whether or not the Pod for this instance
 // matches its specified PodTemplate. When it does not match, the Pod needs to
 // be redeployed.
@@ -320,10 +343,17 @@ func (r *Reconciler) observeInstances(
 
 	observed := newObservedInstances(cluster, runners.Items, pods.Items)
 
+	// Keep the replication lag of each set until it is observed again.
+	// See [Reconciler.reconcileReplicationLagStatus].
+	lags := make(map[string][]v1beta1.ReplicaLagStatus, len(cluster.Status.InstanceSets))
+	for _, status := range cluster.Status.InstanceSets {
+		lags[status.Name] = status.ReplicaLag
+	}
+
 	// Fill out status sorted by set name.
 	cluster.Status.InstanceSets = cluster.Status.InstanceSets[:0]
 	for _, name := range observed.setNames.List() {
-		status := v1beta1.PostgresInstanceSetStatus{Name: name}
+		status := v1beta1.PostgresInstanceSetStatus{Name: name, ReplicaLag: lags[name]}
 
 		for _, instance := range observed.bySet[name] {
 			status.Replicas += int32(len(instance.Pods))
@@ -334,7 +364,12 @@ func (r *Reconciler) observeInstances(
 			if matches, known := instance.PodMatchesPodTemplate(); known && matches {
 				status.UpdatedReplicas++
 			}
+
+			status.Members = append(status.Members, observeMember(instance))
 		}
+		sort.Slice(status.Members, func(i, j int) bool {
+			return status.Members[i].Name < status.Members[j].Name
+		})
 
 		cluster.Status.InstanceSets = append(cluster.Status.InstanceSets, status)
 	}
@@ -342,6 +377,52 @@ func (r *Reconciler) observeInstances(
 	return observed, err
 }
 
+// observeMember describes instance and its Pod for the status of its set.
+func observeMember(instance *Instance) v1beta1.PostgresInstanceStatus {
+	member := v1beta1.PostgresInstanceStatus{Name: instance.Name}
+	if len(instance.Pods) != 1 {
+		return member
+	}
+
+	pod := instance.Pods[0]
+	member.Pod = pod.Name
+	member.Ready, _ = instance.IsReady()
+
+	switch {
+	case patroni.PodIsStandbyLeader(pod):
+		member.Role = "standby-leader"
+	case pod.Labels[naming.LabelRole] == naming.RolePatroniLeader:
+		member.Role = naming.RolePrimary
+	case pod.Labels[naming.LabelRole] == naming.RolePatroniReplica:
+		member.Role = naming.RoleReplica
+	}
+
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			member.PersistentVolumeClaims = append(
+				member.PersistentVolumeClaims, volume.PersistentVolumeClaim.ClaimName)
+		}
+	}
+
+	for _, container := range pod.Spec.Containers {
+		if container.Name != naming.ContainerDatabase {
+			continue
+		}
+		member.Image = container.Image
+
+		// The data directory is named for the major version of PostgreSQL.
+		// See [postgres.ConfigDirectory].
+		for _, env := range container.Env {
+			if env.Name == "PGDATA" {
+				version, _ := strconv.Atoi(strings.TrimPrefix(path.Base(env.Value), "pg"))
+				member.PostgresVersion = int32(version)
+			}
+		}
+	}
+
+	return member
+}
+
 // +kubebuilder:rbac:groups="",resources=pods,verbs=list
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=patch
 
@@ -504,6 +585,7 @@ func (r *Reconciler) reconcileInstanceSets(
 	clusterVolumes []corev1.PersistentVolumeClaim,
 	exporterWebConfig *corev1.ConfigMap,
 ) error {
+	defer timeReconciler("instanceSets").ObserveDuration()
 
 	// Go through the observed instances and check if a primary has been determined.
 	// If the cluster is being shutdown and this instance is the primary, store
@@ -698,18 +780,19 @@ func (r *Reconciler) rolloutInstance(
 	primary, known := instance.IsPrimary()
 	primary = primary && known
 
-	// When the cluster has more than one instance participating in failover,
-	// perform a controlled switchover to one of those instances. Patroni will
-	// choose the best candidate and demote the primary. It stops PostgreSQL
-	// using what it calls "graceful" mode: it takes an immediate checkpoint in
-	// the background then uses "pg_ctl" to perform a "fast" shutdown when the
-	// checkpoint completes.
+	// When the cluster has more than one instance participating in failover
+	// and its policy allows it, perform a controlled switchover to one of
+	// those instances. Patroni will choose the best candidate and demote the
+	// primary. It stops PostgreSQL using what it calls "graceful" mode: it
+	// takes an immediate checkpoint in the background then uses "pg_ctl" to
+	// perform a "fast" shutdown when the checkpoint completes.
 	// - https://github.com/zalando/patroni/blob/v2.0.2/patroni/ha.py#L815
 	// - https://www.postgresql.org/docs/current/sql-checkpoint.html
 	//
 	// NOTE(cbandy): The StatefulSet controlling this Pod reflects this change
 	// in its Status and triggers another reconcile.
-	if primary && len(instances.forCluster) > 1 {
+	if primary && len(instances.forCluster) > 1 &&
+		cluster.Spec.ImageUpdatePolicy != v1beta1.ImageUpdateRestart {
 		var span trace.Span
 		ctx, span = r.Tracer.Start(ctx, "patroni-change-primary")
 		defer span.End()
@@ -723,9 +806,10 @@ func (r *Reconciler) rolloutInstance(
 		return err
 	}
 
-	// When the cluster has only one instance for failover, perform a series of
-	// immediate checkpoints to increase the likelihood that a "fast" shutdown
-	// will complete before the SIGKILL near TerminationGracePeriodSeconds.
+	// When the cluster has only one instance for failover or its policy is to
+	// restart the primary, perform a series of immediate checkpoints to
+	// increase the likelihood that a "fast" shutdown will complete before the
+	// SIGKILL near TerminationGracePeriodSeconds.
 	// - https://docs.k8s.io/concepts/workloads/pods/pod-lifecycle/#pod-termination
 	if primary {
 		graceSeconds := int64(corev1.DefaultTerminationGracePeriodSeconds)
@@ -837,6 +921,20 @@ func (r *Reconciler) rolloutInstances(
 	const maxUnavailable = 1
 	numUnavailable := numSpecified - numAvailable
 
+	// When the policy is to update images manually, leave running every
+	// instance that differs from its template only in images. Each is
+	// recreated from its template when its Pod is deleted. Other changes,
+	// such as to certificates, configuration, or volumes, still roll out.
+	if cluster.Spec.ImageUpdatePolicy == v1beta1.ImageUpdateManual {
+		changed := consider[:0]
+		for _, instance := range consider {
+			if only, known := instance.PodDiffersOnlyInImages(); !known || !only {
+				changed = append(changed, instance)
+			}
+		}
+		consider = changed
+	}
+
 	// When multiple instances need to redeploy, sort them so the lowest
 	// priority instances are first.
 	if len(consider) > 1 {
@@ -1071,6 +1169,7 @@ func (r *Reconciler) reconcileInstance(
 		instanceCertificates *corev1.Secret
 		postgresDataVolume   *corev1.PersistentVolumeClaim
 		postgresWALVolume    *corev1.PersistentVolumeClaim
+		tablespaceVolumes    []*corev1.PersistentVolumeClaim
 	)
 
 	if err == nil {
@@ -1086,15 +1185,20 @@ func (r *Reconciler) reconcileInstance(
 	if err == nil {
 		postgresWALVolume, err = r.reconcilePostgresWALVolume(ctx, cluster, spec, instance, observed, clusterVolumes)
 	}
+	if err == nil {
+		tablespaceVolumes, err = r.reconcileTablespaceVolumes(ctx, cluster, spec, instance, clusterVolumes)
+	}
 	if err == nil {
 		postgres.InstancePod(
 			ctx, cluster, spec,
 			primaryCertificate, replicationCertSecretProjection(clusterReplicationSecret),
-			postgresDataVolume, postgresWALVolume,
+			postgresDataVolume, postgresWALVolume, tablespaceVolumes,
 			&instance.Spec.Template.Spec)
 
 		addPGBackRestToInstancePodSpec(
 			cluster, instanceCertificates, &instance.Spec.Template.Spec)
+		archive.InstancePod(cluster, &instance.Spec.Template.Spec)
+		logshipping.InstancePod(cluster, &instance.Spec.Template.Spec)
 
 		err = patroni.InstancePod(
 			ctx, cluster, clusterConfigMap, clusterPodService, patroniLeaderService,
@@ -1126,6 +1230,10 @@ func (r *Reconciler) reconcileInstance(
 		addDevSHM(&instance.Spec.Template)
 	}
 
+	if err == nil {
+		err = annotateTemplateWithoutImages(&instance.Spec.Template)
+	}
+
 	if err == nil {
 		err = errors.WithStack(r.apply(ctx, instance))
 	}
@@ -1136,6 +1244,29 @@ func (r *Reconciler) reconcileInstance(
 	return err
 }
 
+// annotateTemplateWithoutImages records a hash of template without its
+// container images so that rolloutInstances can tell when only images
+// changed. Call it after template is otherwise complete.
+func annotateTemplateWithoutImages(template *corev1.PodTemplateSpec) error {
+	stripped := template.DeepCopy()
+	delete(stripped.Annotations, naming.PodTemplateHashWithoutImages)
+	for i := range stripped.Spec.InitContainers {
+		stripped.Spec.InitContainers[i].Image = ""
+	}
+	for i := range stripped.Spec.Containers {
+		stripped.Spec.Containers[i].Image = ""
+	}
+
+	hash, err := safeHash32(func(w io.Writer) error {
+		return json.NewEncoder(w).Encode(stripped)
+	})
+	if err == nil {
+		template.Annotations = naming.Merge(template.Annotations,
+			map[string]string{naming.PodTemplateHashWithoutImages: hash})
+	}
+	return errors.WithStack(err)
+}
+
 func generateInstanceStatefulSetIntent(_ context.Context,
 	cluster *v1beta1.PostgresCluster,
 	spec *v1beta1.PostgresInstanceSetSpec,
@@ -171,6 +171,37 @@ func TestReconcilerRolloutInstance(t *testing.T) {
 			err := reconciler.rolloutInstance(ctx, cluster, observed, instances[0])
 			assert.ErrorContains(t, err, "switchover")
 		})
+
+		t.Run("Restart", func(t *testing.T) {
+			cluster := cluster.DeepCopy()
+			cluster.Spec.ImageUpdatePolicy = v1beta1.ImageUpdateRestart
+
+			key := client.ObjectKey{Namespace: "ns1", Name: "the-pod"}
+			reconciler := &Reconciler{}
+			reconciler.Client = fake.NewClientBuilder().WithObjects(instances[0].Pods[0]).Build()
+			reconciler.Tracer = otel.Tracer(t.Name())
+
+			execCalls := 0
+			reconciler.PodExec = func(
+				_, _, _ string, stdin io.Reader, _, _ io.Writer, command ...string,
+			) error {
+				execCalls++
+
+				// Checkpoint rather than switchover.
+				b, _ := io.ReadAll(stdin)
+				assert.Equal(t, string(b), "SET statement_timeout = :'timeout'; CHECKPOINT;")
+				assert.Assert(t, cmp.Contains(strings.Join(command, " "), "psql"))
+
+				return nil
+			}
+
+			assert.NilError(t, reconciler.rolloutInstance(ctx, cluster, observed, instances[0]))
+			assert.Equal(t, execCalls, 1, "expected PodExec to be called")
+
+			err := reconciler.Client.Get(ctx, key, &corev1.Pod{})
+			assert.Assert(t, apierrors.IsNotFound(err),
+				"expected pod to be deleted, got: %#v", err)
+		})
 	})
 }
 
@@ -301,6 +332,76 @@ func TestReconcilerRolloutInstances(t *testing.T) {
 		assert.Equal(t, redeploys[0].Name, "one")
 	})
 
+	// Single healthy instance, Pod does not match PodTemplate, manual updates.
+	t.Run("SingletonOutdatedManual", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.ImageUpdatePolicy = v1beta1.ImageUpdateManual
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "00", Replicas: initialize.Int32(1)},
+		}
+		instances := []*Instance{
+			{
+				Name: "one",
+				Spec: &cluster.Spec.InstanceSets[0],
+				Pods: []*corev1.Pod{{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							"postgres-operator.crunchydata.com/template-hash-without-images": "same",
+						},
+						Labels: map[string]string{
+							"controller-revision-hash":               "beta",
+							"postgres-operator.crunchydata.com/role": "master",
+						},
+					},
+					Status: corev1.PodStatus{
+						Conditions: []corev1.PodCondition{{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						}},
+					},
+				}},
+				Runner: &appsv1.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation: 1,
+					},
+					Spec: appsv1.StatefulSetSpec{
+						Template: corev1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{
+								Annotations: map[string]string{
+									"postgres-operator.crunchydata.com/template-hash-without-images": "same",
+								},
+							},
+						},
+					},
+					Status: appsv1.StatefulSetStatus{
+						ObservedGeneration: 1,
+						UpdateRevision:     "gamma",
+					},
+				},
+			},
+		}
+		observed := &observedInstances{forCluster: instances}
+
+		logSpanAttributes(t)
+		assert.NilError(t, reconciler.rolloutInstances(ctx, cluster, observed,
+			func(context.Context, *Instance) error {
+				t.Fatal("expected no redeploys when only images changed")
+				return nil
+			}))
+
+		// Changes other than images roll out.
+		instances[0].Runner.Spec.Template.Annotations[
+			"postgres-operator.crunchydata.com/template-hash-without-images"] = "different"
+
+		var redeploys []string
+		assert.NilError(t, reconciler.rolloutInstances(ctx, cluster, observed,
+			func(_ context.Context, instance *Instance) error {
+				redeploys = append(redeploys, instance.Name)
+				return nil
+			}))
+		assert.DeepEqual(t, redeploys, []string{"one"})
+	})
+
 	// Two ready instances do not match PodTemplate, no primary.
 	t.Run("ManyOutdated", func(t *testing.T) {
 		cluster := new(v1beta1.PostgresCluster)
@@ -144,6 +144,12 @@ func TestInstanceIsWritable(t *testing.T) {
 	writable, known = instance.IsWritable()
 	assert.Assert(t, known)
 	assert.Assert(t, !writable)
+	// Citus worker leader
+	instance.Pods[0].Annotations["status"] = `{"role":"master"}`
+	instance.Pods[0].Labels = map[string]string{naming.LabelCitusRole: "master"}
+	writable, known = instance.IsWritable()
+	assert.Assert(t, known)
+	assert.Assert(t, !writable)
 }
 
 func TestNewObservedInstances(t *testing.T) {
@@ -269,6 +275,65 @@ func TestNewObservedInstances(t *testing.T) {
 	})
 }
 
+func TestObserveMember(t *testing.T) {
+	t.Run("NoPod", func(t *testing.T) {
+		member := observeMember(&Instance{Name: "some-name"})
+		assert.DeepEqual(t, member, v1beta1.PostgresInstanceStatus{Name: "some-name"})
+	})
+
+	t.Run("Pod", func(t *testing.T) {
+		pod := &corev1.Pod{}
+		pod.Name = "some-name-0"
+		pod.Labels = map[string]string{naming.LabelRole: "master"}
+		pod.Spec.Containers = []corev1.Container{
+			{Name: "other", Image: "other-image"},
+			{
+				Name:  "database",
+				Image: "postgres-image",
+				Env:   []corev1.EnvVar{{Name: "PGDATA", Value: "/pgdata/pg14"}},
+			},
+		}
+		pod.Spec.Volumes = []corev1.Volume{
+			{Name: "config"},
+			{Name: "postgres-data", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "some-name-pgdata",
+				},
+			}},
+			{Name: "postgres-wal", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "some-name-pgwal",
+				},
+			}},
+		}
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:   corev1.PodReady,
+			Status: corev1.ConditionTrue,
+		}}
+
+		member := observeMember(&Instance{Name: "some-name", Pods: []*corev1.Pod{pod}})
+		assert.DeepEqual(t, member, v1beta1.PostgresInstanceStatus{
+			Name:                   "some-name",
+			Pod:                    "some-name-0",
+			Role:                   "primary",
+			Ready:                  true,
+			PersistentVolumeClaims: []string{"some-name-pgdata", "some-name-pgwal"},
+			PostgresVersion:        14,
+			Image:                  "postgres-image",
+		})
+
+		pod.Labels[naming.LabelRole] = "replica"
+		pod.Status.Conditions[0].Status = corev1.ConditionFalse
+		member = observeMember(&Instance{Name: "some-name", Pods: []*corev1.Pod{pod}})
+		assert.Equal(t, member.Role, "replica")
+		assert.Equal(t, member.Ready, false)
+
+		pod.Annotations = map[string]string{"status": `{"role":"standby_leader"}`}
+		member = observeMember(&Instance{Name: "some-name", Pods: []*corev1.Pod{pod}})
+		assert.Equal(t, member.Role, "standby-leader")
+	})
+}
+
 func TestWritablePod(t *testing.T) {
 	container := "container"
 
@@ -1208,6 +1273,31 @@ func TestDeleteInstance(t *testing.T) {
 	}
 }
 
+func TestAnnotateTemplateWithoutImages(t *testing.T) {
+	const key = "postgres-operator.crunchydata.com/template-hash-without-images"
+
+	template := &corev1.PodTemplateSpec{}
+	template.Spec.InitContainers = []corev1.Container{{Name: "init", Image: "one"}}
+	template.Spec.Containers = []corev1.Container{{Name: "database", Image: "one"}}
+	assert.NilError(t, annotateTemplateWithoutImages(template))
+
+	original := template.Annotations[key]
+	assert.Assert(t, original != "")
+
+	// Images do not change the value, and neither does the previous value.
+	images := template.DeepCopy()
+	images.Spec.InitContainers[0].Image = "two"
+	images.Spec.Containers[0].Image = "two"
+	assert.NilError(t, annotateTemplateWithoutImages(images))
+	assert.Equal(t, images.Annotations[key], original)
+
+	// Everything else does.
+	other := template.DeepCopy()
+	other.Spec.Containers[0].Args = []string{"more"}
+	assert.NilError(t, annotateTemplateWithoutImages(other))
+	assert.Assert(t, other.Annotations[key] != original)
+}
+
 func TestGenerateInstanceStatefulSetIntent(t *testing.T) {
 	type intentParams struct {
 		cluster                    *v1beta1.PostgresCluster
@@ -0,0 +1,220 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/maintenance"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="batch",resources="cronjobs",verbs={create,delete,patch}
+// +kubebuilder:rbac:groups="",resources="persistentvolumeclaims",verbs={create,delete,patch}
+// +kubebuilder:rbac:groups="batch",resources="jobs",verbs={list}
+
+// reconcileLogicalBackups writes the CronJob and volume of logical backups in
+// cluster and reports on the latest Job they create. Both are deleted when
+// logical backups are removed from the spec.
+func (r *Reconciler) reconcileLogicalBackups(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	primaryCertificate *corev1.SecretProjection, secret *corev1.Secret,
+) error {
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.ClusterLogicalBackup(cluster)}
+	volume := &corev1.PersistentVolumeClaim{ObjectMeta: naming.ClusterLogicalBackup(cluster)}
+
+	if !maintenance.LogicalBackupsEnabled(cluster) {
+		cluster.Status.LogicalBackup = nil
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.LogicalBackupSucceeded)
+
+		err := errors.WithStack(
+			client.IgnoreNotFound(r.deleteControlled(ctx, cluster, cronjob)))
+		if err == nil {
+			err = errors.WithStack(
+				client.IgnoreNotFound(r.deleteControlled(ctx, cluster, volume)))
+		}
+		return err
+	}
+
+	var err error
+	if spec := cluster.Spec.Backups.Logical; spec.VolumeClaimSpec != nil {
+		volume.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"))
+		volume.Annotations = cluster.Spec.Metadata.GetAnnotationsOrNil()
+		volume.Labels = naming.Merge(
+			cluster.Spec.Metadata.GetLabelsOrNil(),
+			map[string]string{
+				naming.LabelCluster: cluster.Name,
+				naming.LabelRole:    naming.RoleLogicalBackup,
+			})
+		volume.Spec = *spec.VolumeClaimSpec
+
+		err = errors.WithStack(r.setControllerReference(cluster, volume))
+		if err == nil {
+			err = r.handlePersistentVolumeClaimError(cluster,
+				errors.WithStack(r.apply(ctx, volume)))
+		}
+	} else {
+		err = errors.WithStack(
+			client.IgnoreNotFound(r.deleteControlled(ctx, cluster, volume)))
+	}
+
+	if err == nil {
+		cronjob = generateLogicalBackupCronJob(cluster, primaryCertificate, secret)
+		err = errors.WithStack(r.setControllerReference(cluster, cronjob))
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, cronjob))
+	}
+
+	jobs := &batchv1.JobList{}
+	if err == nil {
+		var selector labels.Selector
+		selector, err = naming.AsSelector(naming.ClusterLogicalBackups(cluster.Name))
+		if err == nil {
+			err = errors.WithStack(
+				r.Client.List(ctx, jobs,
+					client.InNamespace(cluster.Namespace),
+					client.MatchingLabelsSelector{Selector: selector},
+				))
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if logicalBackupStatus(cluster, jobs.Items) {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "LogicalBackupFailed",
+			"Logical backup Job %q failed", cluster.Status.LogicalBackup.JobName)
+	}
+
+	return nil
+}
+
+// logicalBackupStatus reports on the most recently created Job in jobs and
+// sets the LogicalBackupSucceeded condition of cluster. It returns true when
+// that Job has failed since the status was last reported.
+func logicalBackupStatus(cluster *v1beta1.PostgresCluster, jobs []batchv1.Job) bool {
+	if len(jobs) == 0 {
+		cluster.Status.LogicalBackup = nil
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.LogicalBackupSucceeded)
+		return false
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreationTimestamp.Before(&jobs[j].CreationTimestamp)
+	})
+	latest := &jobs[len(jobs)-1]
+	previous := cluster.Status.LogicalBackup
+	prior := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.LogicalBackupSucceeded)
+
+	cluster.Status.LogicalBackup = &v1beta1.LogicalBackupStatus{
+		JobName:        latest.Name,
+		StartTime:      latest.Status.StartTime,
+		CompletionTime: latest.Status.CompletionTime,
+		Active:         latest.Status.Active,
+		Succeeded:      latest.Status.Succeeded,
+		Failed:         latest.Status.Failed,
+	}
+
+	condition := metav1.Condition{
+		Type:               v1beta1.LogicalBackupSucceeded,
+		Status:             metav1.ConditionTrue,
+		Reason:             "JobSucceeded",
+		Message:            "The latest logical backup succeeded or has not finished.",
+		ObservedGeneration: cluster.GetGeneration(),
+	}
+	if jobFailed(latest) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "JobFailed"
+		condition.Message = "The latest logical backup failed; the logs of Job " +
+			latest.Name + " describe why."
+	}
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+
+	return condition.Status == metav1.ConditionFalse &&
+		(previous == nil || previous.JobName != latest.Name ||
+			prior == nil || prior.Status != metav1.ConditionFalse)
+}
+
+// generateLogicalBackupCronJob returns the CronJob that exports the databases
+// of cluster on the schedule of its logical backups.
+func generateLogicalBackupCronJob(
+	cluster *v1beta1.PostgresCluster,
+	primaryCertificate *corev1.SecretProjection, secret *corev1.Secret,
+) *batchv1.CronJob {
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.ClusterLogicalBackup(cluster)}
+	cronjob.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("CronJob"))
+
+	labels := naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleLogicalBackup,
+		})
+	annotations := cluster.Spec.Metadata.GetAnnotationsOrNil()
+
+	cronjob.Annotations = annotations
+	cronjob.Labels = labels
+
+	cronjob.Spec.Schedule = cluster.Spec.Backups.Logical.Schedule
+	cronjob.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+	cronjob.Spec.JobTemplate.Annotations = annotations
+	cronjob.Spec.JobTemplate.Labels = labels
+	cronjob.Spec.JobTemplate.Spec.Template.Annotations = annotations
+	cronjob.Spec.JobTemplate.Spec.Template.Labels = labels
+
+	// Suspend when shutdown. The maintenance user cannot be written while
+	// every instance is read-only, so suspend a standby cluster, too. Any
+	// exports that have already started will continue.
+	cronjob.Spec.Suspend = initialize.Bool(
+		(cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown) ||
+			(cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled))
+
+	if tz := cluster.Spec.Config.Timezone; tz != "" {
+		cronjob.Spec.TimeZone = &tz
+	}
+
+	pod := &cronjob.Spec.JobTemplate.Spec.Template.Spec
+	maintenance.LogicalBackupPod(cluster, primaryCertificate, secret, pod)
+
+	// Exports connect over the network and do not call the Kubernetes API.
+	pod.AutomountServiceAccountToken = initialize.Bool(false)
+
+	// Disable environment variables for services other than the Kubernetes API.
+	// - https://docs.k8s.io/concepts/services-networking/connect-applications-service/#accessing-the-service
+	// - https://releases.k8s.io/v1.23.0/pkg/kubelet/kubelet_pods.go#L553-L563
+	pod.EnableServiceLinks = initialize.Bool(false)
+
+	pod.ImagePullSecrets = cluster.Spec.ImagePullSecrets
+	pod.RestartPolicy = corev1.RestartPolicyNever
+
+	// Set a filesystem group so exports can be written to any volume.
+	pod.SecurityContext = postgres.PodSecurityContext(cluster)
+
+	return cronjob
+}
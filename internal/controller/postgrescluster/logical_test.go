@@ -0,0 +1,110 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGenerateLogicalBackupCronJob(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Name = "pg1"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "pull"}}
+	cluster.Spec.Backups.Logical = &v1beta1.LogicalBackups{Schedule: "0 3 * * *"}
+
+	secret := &corev1.Secret{ObjectMeta: naming.ClusterMaintenance(cluster)}
+	certificate := &corev1.SecretProjection{}
+
+	cronjob := generateLogicalBackupCronJob(cluster, certificate, secret)
+
+	assert.Equal(t, cronjob.Namespace, "ns1")
+	assert.Equal(t, cronjob.Name, "pg1-logical-backup")
+	assert.Equal(t, cronjob.Spec.Schedule, "0 3 * * *")
+	assert.Equal(t, cronjob.Spec.ConcurrencyPolicy, batchv1.ForbidConcurrent)
+	assert.Equal(t, *cronjob.Spec.Suspend, false)
+	assert.DeepEqual(t, cronjob.Labels, map[string]string{
+		"postgres-operator.crunchydata.com/cluster": "pg1",
+		"postgres-operator.crunchydata.com/role":    "logical-backup",
+	})
+
+	pod := cronjob.Spec.JobTemplate.Spec.Template.Spec
+	assert.DeepEqual(t, cronjob.Spec.JobTemplate.Spec.Template.Labels, cronjob.Labels)
+	assert.Equal(t, pod.RestartPolicy, corev1.RestartPolicyNever)
+	assert.Equal(t, *pod.AutomountServiceAccountToken, false)
+	assert.DeepEqual(t, pod.ImagePullSecrets, cluster.Spec.ImagePullSecrets)
+	assert.Equal(t, pod.Containers[0].Name, naming.ContainerLogicalBackup)
+	assert.Assert(t, pod.SecurityContext.FSGroup != nil)
+
+	t.Run("Suspended", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Shutdown = initialize.Bool(true)
+
+		cronjob := generateLogicalBackupCronJob(cluster, certificate, secret)
+		assert.Equal(t, *cronjob.Spec.Suspend, true)
+	})
+}
+
+func TestLogicalBackupStatus(t *testing.T) {
+	now := time.Now()
+	job := func(name string, age time.Duration, failed bool) batchv1.Job {
+		j := batchv1.Job{}
+		j.Name = name
+		j.CreationTimestamp = metav1.NewTime(now.Add(-age))
+		if failed {
+			j.Status.Failed = 1
+			j.Status.Conditions = []batchv1.JobCondition{{
+				Type: batchv1.JobFailed, Status: corev1.ConditionTrue,
+			}}
+		} else {
+			j.Status.Succeeded = 1
+		}
+		return j
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+
+	// There is nothing to report without Jobs.
+	assert.Assert(t, !logicalBackupStatus(cluster, nil))
+	assert.Assert(t, cluster.Status.LogicalBackup == nil)
+	assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions, "LogicalBackupSucceeded") == nil)
+
+	// The latest Job is reported.
+	assert.Assert(t, !logicalBackupStatus(cluster, []batchv1.Job{
+		job("old", time.Hour, true), job("new", time.Minute, false),
+	}))
+	assert.Equal(t, cluster.Status.LogicalBackup.JobName, "new")
+	assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, "LogicalBackupSucceeded"))
+
+	// A failure is announced once.
+	jobs := []batchv1.Job{job("new", time.Minute, false), job("newer", time.Second, true)}
+	assert.Assert(t, logicalBackupStatus(cluster, jobs))
+	assert.Assert(t, !logicalBackupStatus(cluster, jobs))
+	assert.Equal(t, cluster.Status.LogicalBackup.JobName, "newer")
+	assert.Assert(t, meta.IsStatusConditionFalse(cluster.Status.Conditions, "LogicalBackupSucceeded"))
+}
@@ -0,0 +1,66 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/logshipping"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=create;delete;patch
+
+// reconcileLogShippingConfigMap writes the ConfigMap of log shipper
+// configuration managed by PGO. It deletes the ConfigMap when logs are not
+// shipped.
+func (r *Reconciler) reconcileLogShippingConfigMap(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	if !logshipping.Enabled(cluster) {
+		existing := &corev1.ConfigMap{ObjectMeta: naming.LogShippingConfigMap(cluster)}
+		err := errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing))
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, existing))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	configmap := &corev1.ConfigMap{ObjectMeta: naming.LogShippingConfigMap(cluster)}
+	configmap.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+
+	configmap.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+	configmap.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleMonitoring,
+		})
+
+	logshipping.ConfigMap(cluster, configmap)
+
+	err := errors.WithStack(r.setControllerReference(cluster, configmap))
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, configmap))
+	}
+	return err
+}
@@ -0,0 +1,419 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/maintenance"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// reconcileMaintenance writes the objects necessary to run scheduled
+// maintenance jobs and logical backups and reports on the Jobs they create.
+func (r *Reconciler) reconcileMaintenance(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	primaryCertificate *corev1.SecretProjection,
+) error {
+	secret, err := r.reconcileMaintenanceSecret(ctx, cluster)
+	if err == nil {
+		err = r.reconcileMaintenanceInPostgreSQL(ctx, cluster, instances, secret)
+	}
+	if err == nil {
+		err = r.reconcileMaintenanceCronJobs(ctx, cluster, primaryCertificate, secret)
+	}
+	if err == nil {
+		err = r.reconcileLogicalBackups(ctx, cluster, primaryCertificate, secret)
+	}
+	if err == nil {
+		err = r.reconcileMaintenanceStatus(ctx, cluster)
+	}
+	return err
+}
+
+// +kubebuilder:rbac:groups="batch",resources="cronjobs",verbs={list}
+// +kubebuilder:rbac:groups="batch",resources="cronjobs",verbs={create,delete,patch}
+
+// reconcileMaintenanceCronJobs writes a CronJob for every maintenance job in
+// the spec and deletes any others.
+func (r *Reconciler) reconcileMaintenanceCronJobs(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	primaryCertificate *corev1.SecretProjection, secret *corev1.Secret,
+) error {
+	existing := &batchv1.CronJobList{}
+	selector, err := naming.AsSelector(naming.ClusterMaintenanceJobs(cluster.Name))
+	if err == nil {
+		err = errors.WithStack(
+			r.Client.List(ctx, existing,
+				client.InNamespace(cluster.Namespace),
+				client.MatchingLabelsSelector{Selector: selector},
+			))
+	}
+
+	specified := sets.NewString()
+	if maintenance.Enabled(cluster) {
+		for i := range cluster.Spec.Maintenance.Jobs {
+			job := &cluster.Spec.Maintenance.Jobs[i]
+			specified.Insert(job.Name)
+
+			if err == nil {
+				cronjob := generateMaintenanceCronJob(cluster, job, primaryCertificate, secret)
+				err = errors.WithStack(r.setControllerReference(cluster, cronjob))
+
+				if err == nil {
+					err = errors.WithStack(r.apply(ctx, cronjob))
+				}
+			}
+		}
+	}
+
+	// Delete CronJobs that are no longer in the spec.
+	for i := range existing.Items {
+		if err == nil && !specified.Has(existing.Items[i].Labels[naming.LabelMaintenanceJob]) {
+			err = errors.WithStack(
+				client.IgnoreNotFound(r.deleteControlled(ctx, cluster, &existing.Items[i])))
+		}
+	}
+
+	return err
+}
+
+// generateMaintenanceCronJob returns the CronJob that runs job on its schedule.
+func generateMaintenanceCronJob(
+	cluster *v1beta1.PostgresCluster, job *v1beta1.MaintenanceJobSpec,
+	primaryCertificate *corev1.SecretProjection, secret *corev1.Secret,
+) *batchv1.CronJob {
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.MaintenanceCronJob(cluster, job.Name)}
+	cronjob.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("CronJob"))
+
+	labels := naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster:        cluster.Name,
+			naming.LabelRole:           naming.RoleMaintenance,
+			naming.LabelMaintenanceJob: job.Name,
+		})
+	annotations := cluster.Spec.Metadata.GetAnnotationsOrNil()
+
+	cronjob.Annotations = annotations
+	cronjob.Labels = labels
+
+	cronjob.Spec.Schedule = job.Schedule
+	cronjob.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+	cronjob.Spec.JobTemplate.Annotations = annotations
+	cronjob.Spec.JobTemplate.Labels = labels
+	cronjob.Spec.JobTemplate.Spec.Template.Annotations = annotations
+	cronjob.Spec.JobTemplate.Spec.Template.Labels = labels
+
+	// Suspend when shutdown or when every instance is read-only. Any jobs that
+	// have already started will continue.
+	cronjob.Spec.Suspend = initialize.Bool(
+		(cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown) ||
+			(cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled))
+
+	if tz := cluster.Spec.Config.Timezone; tz != "" {
+		cronjob.Spec.TimeZone = &tz
+	}
+
+	pod := &cronjob.Spec.JobTemplate.Spec.Template.Spec
+	maintenance.Pod(cluster, job, primaryCertificate, secret, pod)
+
+	// Maintenance jobs connect over the network and do not call the
+	// Kubernetes API.
+	pod.AutomountServiceAccountToken = initialize.Bool(false)
+
+	// Disable environment variables for services other than the Kubernetes API.
+	// - https://docs.k8s.io/concepts/services-networking/connect-applications-service/#accessing-the-service
+	// - https://releases.k8s.io/v1.23.0/pkg/kubelet/kubelet_pods.go#L553-L563
+	pod.EnableServiceLinks = initialize.Bool(false)
+
+	pod.ImagePullSecrets = cluster.Spec.ImagePullSecrets
+	pod.RestartPolicy = corev1.RestartPolicyNever
+	pod.SecurityContext = initialize.PodSecurityContext()
+
+	return cronjob
+}
+
+// +kubebuilder:rbac:groups="",resources="pods",verbs={get,list}
+
+// reconcileMaintenanceInPostgreSQL writes the user needed by maintenance jobs
+// inside of PostgreSQL.
+func (r *Reconciler) reconcileMaintenanceInPostgreSQL(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	secret *corev1.Secret,
+) error {
+	if !maintenance.UserEnabled(cluster) &&
+		(cluster.Status.Maintenance == nil || cluster.Status.Maintenance.PostgreSQLRevision == "") {
+		// Maintenance is disabled and was never installed; there's nothing to do.
+		return nil
+	}
+
+	var pod *corev1.Pod
+
+	// Find the PostgreSQL instance that can execute SQL that writes system
+	// catalogs. When there is none, return early.
+	for _, instance := range instances.forCluster {
+		writable, known := instance.IsWritable()
+		if writable && known && len(instance.Pods) > 0 {
+			pod = instance.Pods[0]
+			break
+		}
+	}
+	if pod == nil {
+		return nil
+	}
+
+	action := func(ctx context.Context, exec postgres.Executor) error {
+		return errors.WithStack(maintenance.EnableInPostgreSQL(ctx, exec, cluster, secret))
+	}
+	if !maintenance.UserEnabled(cluster) {
+		action = func(ctx context.Context, exec postgres.Executor) error {
+			return errors.WithStack(maintenance.DisableInPostgreSQL(ctx, exec))
+		}
+	}
+
+	// First, calculate a hash of the SQL that should be executed in PostgreSQL.
+	revision, err := safeHash32(func(hasher io.Writer) error {
+		// Discard log messages from the maintenance package about executing
+		// SQL. Nothing is being "executed" yet.
+		return action(logging.NewContext(ctx, logging.Discard()), func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			_, err := io.Copy(hasher, stdin)
+			if err == nil {
+				_, err = fmt.Fprint(hasher, command)
+			}
+			return err
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if cluster.Status.Maintenance != nil &&
+		revision == cluster.Status.Maintenance.PostgreSQLRevision {
+		// The necessary SQL has already been applied; there's nothing more to do.
+		return nil
+	}
+
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx).WithValues("revision", revision))
+	err = action(ctx, func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	})
+
+	if err == nil {
+		if maintenance.UserEnabled(cluster) {
+			if cluster.Status.Maintenance == nil {
+				cluster.Status.Maintenance = &v1beta1.MaintenanceStatus{}
+			}
+			cluster.Status.Maintenance.PostgreSQLRevision = revision
+		} else {
+			cluster.Status.Maintenance.PostgreSQLRevision = ""
+		}
+	}
+
+	return err
+}
+
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={create,delete,patch}
+
+// reconcileMaintenanceSecret writes the Secret used by maintenance jobs.
+func (r *Reconciler) reconcileMaintenanceSecret(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) (*corev1.Secret, error) {
+	existing := &corev1.Secret{ObjectMeta: naming.ClusterMaintenance(cluster)}
+	err := errors.WithStack(
+		r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing))
+	if client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+
+	if !maintenance.UserEnabled(cluster) {
+		// Maintenance is disabled; delete the Secret if it exists.
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, existing))
+		}
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	err = client.IgnoreNotFound(err)
+
+	intent := &corev1.Secret{ObjectMeta: naming.ClusterMaintenance(cluster)}
+	intent.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	intent.Type = corev1.SecretTypeOpaque
+
+	if err == nil {
+		err = errors.WithStack(r.setControllerReference(cluster, intent))
+	}
+
+	intent.Annotations = cluster.Spec.Metadata.GetAnnotationsOrNil()
+	intent.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleMaintenance,
+		})
+
+	if err == nil {
+		err = maintenance.Secret(ctx, cluster, existing, intent)
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, intent))
+	}
+
+	return intent, err
+}
+
+// +kubebuilder:rbac:groups="batch",resources="jobs",verbs={list}
+
+// reconcileMaintenanceStatus reports on the Jobs created by maintenance
+// CronJobs and sets the MaintenanceSucceeded condition.
+func (r *Reconciler) reconcileMaintenanceStatus(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	if !maintenance.Enabled(cluster) {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.MaintenanceSucceeded)
+		if cluster.Status.Maintenance != nil {
+			cluster.Status.Maintenance.Jobs = nil
+			if cluster.Status.Maintenance.PostgreSQLRevision == "" {
+				cluster.Status.Maintenance = nil
+			}
+		}
+		return nil
+	}
+
+	jobs := &batchv1.JobList{}
+	selector, err := naming.AsSelector(naming.ClusterMaintenanceJobs(cluster.Name))
+	if err == nil {
+		err = errors.WithStack(
+			r.Client.List(ctx, jobs,
+				client.InNamespace(cluster.Namespace),
+				client.MatchingLabelsSelector{Selector: selector},
+			))
+	}
+	if err != nil {
+		return err
+	}
+
+	if cluster.Status.Maintenance == nil {
+		cluster.Status.Maintenance = &v1beta1.MaintenanceStatus{}
+	}
+	previous := cluster.Status.Maintenance.Jobs
+	cluster.Status.Maintenance.Jobs, cluster.Status.Conditions = maintenanceJobStatus(
+		cluster, jobs.Items, cluster.Status.Conditions)
+
+	for _, job := range newlyFailedIntegrityChecks(cluster, previous) {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "IntegrityCheckFailed",
+			"Integrity check %q failed in Job %q; its logs describe any corruption found",
+			job.Name, job.JobName)
+	}
+
+	return nil
+}
+
+// newlyFailedIntegrityChecks returns the status of "Amcheck" Jobs in cluster
+// that have failed since previous.
+func newlyFailedIntegrityChecks(
+	cluster *v1beta1.PostgresCluster, previous []v1beta1.MaintenanceJobStatus,
+) []v1beta1.MaintenanceJobStatus {
+	amcheck := sets.NewString()
+	for _, job := range cluster.Spec.Maintenance.Jobs {
+		if job.Type == v1beta1.MaintenanceAmcheck {
+			amcheck.Insert(job.Name)
+		}
+	}
+
+	failed := map[string]int32{}
+	for _, status := range previous {
+		failed[status.JobName] = status.Failed
+	}
+
+	var result []v1beta1.MaintenanceJobStatus
+	for _, status := range cluster.Status.Maintenance.Jobs {
+		if amcheck.Has(status.Name) && status.Failed > failed[status.JobName] {
+			result = append(result, status)
+		}
+	}
+	return result
+}
+
+// maintenanceJobStatus returns the status of jobs, oldest first, and updates
+// conditions with the outcome of the latest Job of each maintenance job.
+func maintenanceJobStatus(
+	cluster *v1beta1.PostgresCluster, jobs []batchv1.Job, conditions []metav1.Condition,
+) ([]v1beta1.MaintenanceJobStatus, []metav1.Condition) {
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreationTimestamp.Before(&jobs[j].CreationTimestamp)
+	})
+
+	var statuses []v1beta1.MaintenanceJobStatus
+	latest := map[string]*batchv1.Job{}
+	for i := range jobs {
+		name := jobs[i].Labels[naming.LabelMaintenanceJob]
+		latest[name] = &jobs[i]
+
+		statuses = append(statuses, v1beta1.MaintenanceJobStatus{
+			Name:           name,
+			JobName:        jobs[i].Name,
+			StartTime:      jobs[i].Status.StartTime,
+			CompletionTime: jobs[i].Status.CompletionTime,
+			Active:         jobs[i].Status.Active,
+			Succeeded:      jobs[i].Status.Succeeded,
+			Failed:         jobs[i].Status.Failed,
+		})
+	}
+
+	var failed []string
+	for _, job := range cluster.Spec.Maintenance.Jobs {
+		if latest[job.Name] != nil && jobFailed(latest[job.Name]) {
+			failed = append(failed, job.Name)
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               v1beta1.MaintenanceSucceeded,
+		Status:             metav1.ConditionTrue,
+		Reason:             "JobsSucceeded",
+		Message:            "The latest run of every maintenance job succeeded or has not finished.",
+		ObservedGeneration: cluster.GetGeneration(),
+	}
+	if len(failed) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "JobFailed"
+		condition.Message = "The latest run of these maintenance jobs failed: " +
+			strings.Join(failed, ", ")
+	}
+	meta.SetStatusCondition(&conditions, condition)
+
+	return statuses, conditions
+}
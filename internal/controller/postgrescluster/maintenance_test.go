@@ -0,0 +1,174 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGenerateMaintenanceCronJob(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Name = "pg1"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "pull"}}
+
+	job := &v1beta1.MaintenanceJobSpec{
+		Name: "nightly", Schedule: "0 2 * * *", Type: "VacuumAnalyze",
+	}
+	secret := &corev1.Secret{ObjectMeta: naming.ClusterMaintenance(cluster)}
+	certificate := &corev1.SecretProjection{}
+
+	cronjob := generateMaintenanceCronJob(cluster, job, certificate, secret)
+
+	assert.Equal(t, cronjob.Namespace, "ns1")
+	assert.Equal(t, cronjob.Name, "pg1-maintenance-nightly")
+	assert.Equal(t, cronjob.Spec.Schedule, "0 2 * * *")
+	assert.Equal(t, cronjob.Spec.ConcurrencyPolicy, batchv1.ForbidConcurrent)
+	assert.Equal(t, *cronjob.Spec.Suspend, false)
+	assert.Assert(t, cronjob.Spec.TimeZone == nil)
+	assert.DeepEqual(t, cronjob.Labels, map[string]string{
+		"postgres-operator.crunchydata.com/cluster":         "pg1",
+		"postgres-operator.crunchydata.com/role":            "maintenance",
+		"postgres-operator.crunchydata.com/maintenance-job": "nightly",
+	})
+
+	pod := cronjob.Spec.JobTemplate.Spec.Template.Spec
+	assert.DeepEqual(t, cronjob.Spec.JobTemplate.Spec.Template.Labels, cronjob.Labels)
+	assert.Equal(t, pod.RestartPolicy, corev1.RestartPolicyNever)
+	assert.Equal(t, *pod.AutomountServiceAccountToken, false)
+	assert.DeepEqual(t, pod.ImagePullSecrets, cluster.Spec.ImagePullSecrets)
+	assert.Equal(t, pod.Containers[0].Name, naming.ContainerMaintenance)
+
+	t.Run("Suspended", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Shutdown = initialize.Bool(true)
+
+		cronjob := generateMaintenanceCronJob(cluster, job, certificate, secret)
+		assert.Equal(t, *cronjob.Spec.Suspend, true)
+
+		cluster.Spec.Shutdown = nil
+		cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: true}
+
+		cronjob = generateMaintenanceCronJob(cluster, job, certificate, secret)
+		assert.Equal(t, *cronjob.Spec.Suspend, true)
+	})
+
+	t.Run("Timezone", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Config.Timezone = "Asia/Tokyo"
+
+		cronjob := generateMaintenanceCronJob(cluster, job, certificate, secret)
+		assert.Equal(t, *cronjob.Spec.TimeZone, "Asia/Tokyo")
+	})
+}
+
+func TestMaintenanceJobStatus(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.Maintenance = &v1beta1.MaintenanceSpec{
+		Jobs: []v1beta1.MaintenanceJobSpec{{Name: "one"}, {Name: "two"}},
+	}
+
+	now := time.Now()
+	job := func(name, jobName string, age time.Duration, failed bool) batchv1.Job {
+		j := batchv1.Job{}
+		j.Name = jobName
+		j.Labels = map[string]string{naming.LabelMaintenanceJob: name}
+		j.CreationTimestamp = metav1.NewTime(now.Add(-age))
+		if failed {
+			j.Status.Failed = 1
+			j.Status.Conditions = []batchv1.JobCondition{{
+				Type: batchv1.JobFailed, Status: corev1.ConditionTrue,
+			}}
+		} else {
+			j.Status.Succeeded = 1
+			j.Status.Conditions = []batchv1.JobCondition{{
+				Type: batchv1.JobComplete, Status: corev1.ConditionTrue,
+			}}
+		}
+		return j
+	}
+
+	t.Run("Succeeded", func(t *testing.T) {
+		jobs := []batchv1.Job{
+			job("one", "one-b", time.Minute, false),
+			job("one", "one-a", time.Hour, true),
+		}
+
+		statuses, conditions := maintenanceJobStatus(cluster, jobs, nil)
+		assert.Equal(t, len(statuses), 2)
+		assert.Equal(t, statuses[0].JobName, "one-a", "expected oldest first")
+		assert.Equal(t, statuses[1].JobName, "one-b")
+		assert.Equal(t, statuses[1].Name, "one")
+
+		condition := meta.FindStatusCondition(conditions, v1beta1.MaintenanceSucceeded)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		jobs := []batchv1.Job{
+			job("one", "one-a", time.Hour, false),
+			job("two", "two-a", time.Minute, true),
+		}
+
+		_, conditions := maintenanceJobStatus(cluster, jobs, nil)
+		condition := meta.FindStatusCondition(conditions, v1beta1.MaintenanceSucceeded)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "JobFailed")
+		assert.Assert(t, condition.Message != "")
+	})
+}
+
+func TestNewlyFailedIntegrityChecks(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.Maintenance = &v1beta1.MaintenanceSpec{
+		Jobs: []v1beta1.MaintenanceJobSpec{
+			{Name: "check", Type: "Amcheck"},
+			{Name: "vacuum", Type: "Vacuum"},
+		},
+	}
+	cluster.Status.Maintenance = &v1beta1.MaintenanceStatus{
+		Jobs: []v1beta1.MaintenanceJobStatus{
+			{Name: "check", JobName: "check-a", Failed: 1},
+			{Name: "check", JobName: "check-b", Failed: 2},
+			{Name: "check", JobName: "check-c", Failed: 1},
+			{Name: "vacuum", JobName: "vacuum-a", Failed: 1},
+		},
+	}
+
+	previous := []v1beta1.MaintenanceJobStatus{
+		{Name: "check", JobName: "check-a", Failed: 1},
+		{Name: "check", JobName: "check-b", Failed: 1},
+	}
+
+	failed := newlyFailedIntegrityChecks(cluster, previous)
+	assert.Equal(t, len(failed), 2)
+	assert.Equal(t, failed[0].JobName, "check-b")
+	assert.Equal(t, failed[1].JobName, "check-c")
+}
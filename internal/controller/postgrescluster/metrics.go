@@ -0,0 +1,116 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// NOTE: controller-runtime already registers metrics of the workqueue and of
+// every request to the Kubernetes API, e.g. "rest_client_requests_total".
+// The metrics here are about each PostgresCluster.
+
+var (
+	clusterReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "postgres_operator_cluster_reconcile_duration_seconds",
+		Help:    "How long each reconcile of a PostgresCluster took, in seconds.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"namespace", "cluster"})
+
+	clusterReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "postgres_operator_cluster_reconcile_errors_total",
+		Help: "How many reconciles of a PostgresCluster returned an error.",
+	}, []string{"namespace", "cluster"})
+
+	clusterDegraded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "postgres_operator_cluster_degraded",
+		Help: "Whether or not a PostgresCluster has fewer ready instances or proxies than specified or a lagging replica.",
+	}, []string{"namespace", "cluster"})
+
+	clusterReplicaLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "postgres_operator_cluster_replica_lag_bytes",
+		Help: "How far the furthest replica of a PostgresCluster is behind its primary, in bytes of WAL.",
+	}, []string{"namespace", "cluster"})
+
+	reconcilerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "postgres_operator_reconciler_duration_seconds",
+		Help:    "How long each component of a PostgresCluster took to reconcile, in seconds.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"reconciler"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		clusterReconcileDuration, clusterReconcileErrors,
+		clusterDegraded, clusterReplicaLag, reconcilerDuration)
+}
+
+// observeReconcile records the duration and any error of one reconcile of the
+// PostgresCluster called name.
+func observeReconcile(name types.NamespacedName, elapsed time.Duration, err error) {
+	clusterReconcileDuration.WithLabelValues(name.Namespace, name.Name).Observe(elapsed.Seconds())
+
+	if err != nil {
+		clusterReconcileErrors.WithLabelValues(name.Namespace, name.Name).Inc()
+	}
+}
+
+// observeClusterHealth records whether or not cluster is degraded.
+func observeClusterHealth(cluster *v1beta1.PostgresCluster) {
+	var value float64
+	if clusterIsDegraded(cluster) {
+		value = 1
+	}
+	clusterDegraded.WithLabelValues(cluster.Namespace, cluster.Name).Set(value)
+}
+
+// forgetClusterMetrics removes every metric about the PostgresCluster called
+// name. Call this after it is deleted.
+func forgetClusterMetrics(name types.NamespacedName) {
+	for _, vector := range []*prometheus.MetricVec{
+		clusterReconcileDuration.MetricVec,
+		clusterReconcileErrors.MetricVec,
+		clusterDegraded.MetricVec,
+		clusterReplicaLag.MetricVec,
+		restoreDrillSucceeded.MetricVec,
+		restoreDrillFinished.MetricVec,
+	} {
+		vector.DeleteLabelValues(name.Namespace, name.Name)
+	}
+}
+
+// timeReconciler starts a timer for the component called reconciler. Call
+// ObserveDuration on the result when that component is done, usually with
+// defer.
+func timeReconciler(reconciler string) *prometheus.Timer {
+	return prometheus.NewTimer(reconcilerDuration.WithLabelValues(reconciler))
+}
+
+// clusterIsDegraded returns true when any instance set of cluster has fewer
+// ready Pods than specified, when a replica is too far behind, or when its
+// proxy is not available.
+func clusterIsDegraded(cluster *v1beta1.PostgresCluster) bool {
+	return len(instanceSetsNotReady(cluster)) > 0 ||
+		meta.IsStatusConditionFalse(cluster.Status.Conditions, v1beta1.ReplicationHealthy) ||
+		meta.IsStatusConditionFalse(cluster.Status.Conditions, v1beta1.ProxyAvailable)
+}
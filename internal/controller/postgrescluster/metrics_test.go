@@ -0,0 +1,86 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestClusterIsDegraded(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+		{Name: "one", Replicas: initialize.Int32(2)},
+		{Name: "two", Replicas: initialize.Int32(1)},
+	}
+
+	t.Run("NoStatus", func(t *testing.T) {
+		assert.Assert(t, clusterIsDegraded(cluster))
+	})
+
+	cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{
+		{Name: "one", ReadyReplicas: 2, Replicas: 2},
+		{Name: "two", ReadyReplicas: 1, Replicas: 1},
+	}
+
+	t.Run("Ready", func(t *testing.T) {
+		assert.Assert(t, !clusterIsDegraded(cluster))
+	})
+
+	t.Run("InstanceNotReady", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.InstanceSets[0].ReadyReplicas = 1
+		assert.Assert(t, clusterIsDegraded(cluster))
+	})
+
+	t.Run("ProxyUnavailable", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.Conditions = []metav1.Condition{{
+			Type: v1beta1.ProxyAvailable, Status: metav1.ConditionFalse,
+		}}
+		assert.Assert(t, clusterIsDegraded(cluster))
+	})
+}
+
+func TestClusterMetrics(t *testing.T) {
+	name := types.NamespacedName{Namespace: "ns1", Name: "metrics"}
+
+	observeReconcile(name, time.Second, nil)
+	observeReconcile(name, time.Second, errors.New("boom"))
+	assert.Equal(t, testutil.ToFloat64(
+		clusterReconcileErrors.WithLabelValues("ns1", "metrics")), float64(1))
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Namespace, cluster.Name = "ns1", "metrics"
+	observeClusterHealth(cluster)
+	assert.Equal(t, testutil.ToFloat64(
+		clusterDegraded.WithLabelValues("ns1", "metrics")), float64(0))
+
+	forgetClusterMetrics(name)
+	assert.Equal(t, testutil.CollectAndCount(clusterReconcileDuration,
+		"postgres_operator_cluster_reconcile_duration_seconds"), 0)
+	assert.Equal(t, testutil.CollectAndCount(clusterDegraded,
+		"postgres_operator_cluster_degraded"), 0)
+}
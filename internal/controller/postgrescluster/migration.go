@@ -0,0 +1,227 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// EventMigrationSucceeded is the event reason utilized when the migration
+	// Job completes.
+	EventMigrationSucceeded = "MigrationSucceeded"
+
+	// EventMigrationFailed is the event reason utilized when the migration Job
+	// fails.
+	EventMigrationFailed = "MigrationFailed"
+)
+
+// migrationRevision returns a hash of the migration container of cluster and,
+// when it runs after restores, the latest in-place restore.
+func migrationRevision(cluster *v1beta1.PostgresCluster) (string, error) {
+	spec := cluster.Spec.Migration
+	return safeHash32(func(hasher io.Writer) error {
+		err := json.NewEncoder(hasher).Encode(spec.Container)
+		if err == nil && spec.RunAfterRestore != nil && *spec.RunAfterRestore &&
+			cluster.Status.PGBackRest != nil && cluster.Status.PGBackRest.Restore != nil {
+			_, err = fmt.Fprint(hasher, cluster.Status.PGBackRest.Restore.ID)
+		}
+		return err
+	})
+}
+
+// +kubebuilder:rbac:groups="batch",resources="jobs",verbs={create,delete,patch}
+
+// reconcileMigration runs the migration container of cluster in a Job once
+// PostgreSQL accepts writes. It runs again when the container changes and,
+// optionally, after in-place restores. The result is recorded in the
+// "MigrationSucceeded" condition.
+func (r *Reconciler) reconcileMigration(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	existing := &batchv1.Job{ObjectMeta: naming.MigrationJob(cluster)}
+	err := errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing))
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+	found := err == nil
+
+	if cluster.Spec.Migration == nil {
+		cluster.Status.MigrationRevision = ""
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.MigrationSucceeded)
+
+		if found {
+			err = r.deleteMigrationJob(ctx, cluster, existing)
+		}
+		return err
+	}
+
+	setCondition := func(status metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type:               v1beta1.MigrationSucceeded,
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: cluster.GetGeneration(),
+		})
+	}
+
+	revision, err := migrationRevision(cluster)
+	if err != nil {
+		return err
+	}
+	if revision == cluster.Status.MigrationRevision {
+		setCondition(metav1.ConditionTrue, "MigrationSucceeded", "Databases are migrated")
+		return nil
+	}
+
+	// Remove a Job that ran a different revision so that the current one can
+	// run.
+	if found && existing.GetAnnotations()[naming.MigrationRevision] != revision {
+		setCondition(metav1.ConditionFalse, "MigrationPending",
+			"Removing the Job of a previous migration")
+		return r.deleteMigrationJob(ctx, cluster, existing)
+	}
+
+	if found {
+		previous := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MigrationSucceeded)
+
+		switch {
+		case jobCompleted(existing):
+			cluster.Status.MigrationRevision = revision
+			setCondition(metav1.ConditionTrue, "MigrationSucceeded", "Databases are migrated")
+			r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventMigrationSucceeded,
+				"Migration Job %q completed successfully", existing.Name)
+
+		case jobFailed(existing):
+			if previous == nil || previous.Reason != "MigrationFailed" {
+				r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventMigrationFailed,
+					"Migration Job %q failed; change the container or delete the Job to try again",
+					existing.Name)
+			}
+			setCondition(metav1.ConditionFalse, "MigrationFailed", fmt.Sprintf(
+				"Migration Job %q failed", existing.Name))
+
+		default:
+			setCondition(metav1.ConditionFalse, "MigrationRunning", fmt.Sprintf(
+				"Migrating databases in Job %q", existing.Name))
+		}
+		return nil
+	}
+
+	// Wait for a primary that accepts writes.
+	if pod, _ := instances.writablePod(naming.ContainerDatabase); pod == nil {
+		setCondition(metav1.ConditionFalse, "MigrationWaiting",
+			"Waiting for PostgreSQL to accept writes")
+		return nil
+	}
+
+	job := generateMigrationJob(cluster, revision)
+	err = errors.WithStack(r.setControllerReference(cluster, job))
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, job))
+	}
+	if err == nil {
+		setCondition(metav1.ConditionFalse, "MigrationRunning", fmt.Sprintf(
+			"Migrating databases in Job %q", job.Name))
+	}
+	return err
+}
+
+// deleteMigrationJob deletes job and its Pods when cluster controls it.
+func (r *Reconciler) deleteMigrationJob(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, job *batchv1.Job,
+) error {
+	if !metav1.IsControlledBy(job, cluster) {
+		return nil
+	}
+	err := r.Client.Delete(ctx, job,
+		client.PropagationPolicy(metav1.DeletePropagationBackground))
+	return client.IgnoreNotFound(errors.WithStack(err))
+}
+
+// generateMigrationJob returns the Job that runs the migration container of
+// cluster at revision.
+func generateMigrationJob(cluster *v1beta1.PostgresCluster, revision string) *batchv1.Job {
+	spec := cluster.Spec.Migration
+
+	job := &batchv1.Job{ObjectMeta: naming.MigrationJob(cluster)}
+	job.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
+
+	job.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		map[string]string{naming.MigrationRevision: revision})
+	job.Labels = naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleMigration,
+		})
+
+	container := *spec.Container.DeepCopy()
+	if container.SecurityContext == nil {
+		container.SecurityContext = initialize.RestrictedSecurityContext()
+	}
+	if container.ImagePullPolicy == "" {
+		container.ImagePullPolicy = cluster.Spec.ImagePullPolicy
+	}
+
+	job.Spec = batchv1.JobSpec{
+		BackoffLimit: initialize.Int32(2),
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: job.Annotations,
+				Labels:      job.Labels,
+			},
+			Spec: corev1.PodSpec{
+				Containers:      []corev1.Container{container},
+				SecurityContext: initialize.PodSecurityContext(),
+
+				// Set the image pull secrets, if any exist.
+				// This is set here rather than using the service account due to the lack
+				// of propagation to existing pods when the CRD is updated:
+				// https://github.com/kubernetes/kubernetes/issues/88456
+				ImagePullSecrets: cluster.Spec.ImagePullSecrets,
+
+				// Set RestartPolicy to "Never" since we want a new Pod to be
+				// created by the Job controller when there is a failure
+				// (instead of the container simply restarting).
+				RestartPolicy: corev1.RestartPolicyNever,
+
+				// These Jobs don't make Kubernetes API calls, so we can just
+				// use the default ServiceAccount and not mount its credentials.
+				AutomountServiceAccountToken: initialize.Bool(false),
+			},
+		},
+	}
+	if spec.BackoffLimit != nil {
+		job.Spec.BackoffLimit = initialize.Int32(*spec.BackoffLimit)
+	}
+
+	return job
+}
@@ -0,0 +1,212 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestMigrationRevision(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.Migration = &v1beta1.MigrationSpec{
+		Container: corev1.Container{Name: "flyway", Image: "flyway:9"},
+	}
+
+	first, err := migrationRevision(cluster)
+	assert.NilError(t, err)
+
+	// Restores do not matter by default.
+	cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+		Restore: &v1beta1.PGBackRestJobStatus{ID: "one"},
+	}
+	second, err := migrationRevision(cluster)
+	assert.NilError(t, err)
+	assert.Equal(t, first, second)
+
+	cluster.Spec.Migration.RunAfterRestore = initialize.Bool(true)
+	third, err := migrationRevision(cluster)
+	assert.NilError(t, err)
+	assert.Assert(t, third != second, "expected the restore to change the revision")
+
+	cluster.Spec.Migration.Container.Image = "flyway:10"
+	fourth, err := migrationRevision(cluster)
+	assert.NilError(t, err)
+	assert.Assert(t, fourth != third, "expected the container to change the revision")
+}
+
+func TestGenerateMigrationJob(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Namespace = "ns1"
+	cluster.Name = "hippo"
+	cluster.Spec.ImagePullPolicy = corev1.PullAlways
+	cluster.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "registry"}}
+	cluster.Spec.Migration = &v1beta1.MigrationSpec{
+		Container: corev1.Container{
+			Name: "flyway", Image: "flyway:9", Args: []string{"migrate"},
+		},
+		BackoffLimit: initialize.Int32(5),
+	}
+
+	job := generateMigrationJob(cluster, "abc")
+	assert.Equal(t, job.Namespace, "ns1")
+	assert.Equal(t, job.Name, "hippo-migration")
+	assert.Equal(t, job.Annotations[naming.MigrationRevision], "abc")
+	assert.Equal(t, job.Labels[naming.LabelCluster], "hippo")
+	assert.Equal(t, job.Labels[naming.LabelRole], naming.RoleMigration)
+	assert.Equal(t, *job.Spec.BackoffLimit, int32(5))
+
+	pod := job.Spec.Template.Spec
+	assert.Equal(t, pod.RestartPolicy, corev1.RestartPolicyNever)
+	assert.DeepEqual(t, pod.ImagePullSecrets, cluster.Spec.ImagePullSecrets)
+	assert.Equal(t, len(pod.Containers), 1)
+	assert.Equal(t, pod.Containers[0].Image, "flyway:9")
+	assert.DeepEqual(t, pod.Containers[0].Args, []string{"migrate"})
+	assert.Equal(t, pod.Containers[0].ImagePullPolicy, corev1.PullAlways)
+	assert.Assert(t, pod.Containers[0].SecurityContext != nil)
+
+	// The spec is not modified.
+	assert.Assert(t, cluster.Spec.Migration.Container.SecurityContext == nil)
+}
+
+func TestReconcileMigration(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Namespace = "ns1"
+	cluster.Name = "hippo"
+	cluster.UID = "hippo-uid"
+	cluster.Spec.Migration = &v1beta1.MigrationSpec{
+		Container: corev1.Container{Name: "flyway", Image: "flyway:9"},
+	}
+	revision, err := migrationRevision(cluster)
+	assert.NilError(t, err)
+
+	existing := func(condition batchv1.JobConditionType) *batchv1.Job {
+		job := generateMigrationJob(cluster, revision)
+		job.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: v1beta1.GroupVersion.String(), Kind: "PostgresCluster",
+			Name: cluster.Name, UID: cluster.UID, Controller: initialize.Bool(true),
+		}}
+		if condition != "" {
+			job.Status.Conditions = []batchv1.JobCondition{{
+				Type: condition, Status: corev1.ConditionTrue,
+			}}
+		}
+		return job
+	}
+
+	t.Run("Waiting", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		r := &Reconciler{Client: fake.NewClientBuilder().Build()}
+
+		assert.NilError(t, r.reconcileMigration(ctx, cluster, &observedInstances{}))
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MigrationSucceeded)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "MigrationWaiting")
+	})
+
+	t.Run("Running", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		r := &Reconciler{Client: fake.NewClientBuilder().WithObjects(existing("")).Build()}
+
+		assert.NilError(t, r.reconcileMigration(ctx, cluster, &observedInstances{}))
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MigrationSucceeded)
+		assert.Equal(t, condition.Reason, "MigrationRunning")
+		assert.Equal(t, cluster.Status.MigrationRevision, "")
+	})
+
+	t.Run("Succeeded", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		recorder := record.NewFakeRecorder(10)
+		r := &Reconciler{
+			Client:   fake.NewClientBuilder().WithObjects(existing(batchv1.JobComplete)).Build(),
+			Recorder: recorder,
+		}
+
+		assert.NilError(t, r.reconcileMigration(ctx, cluster, &observedInstances{}))
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MigrationSucceeded)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Equal(t, cluster.Status.MigrationRevision, revision)
+		assert.Equal(t, len(recorder.Events), 1)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		recorder := record.NewFakeRecorder(10)
+		r := &Reconciler{
+			Client:   fake.NewClientBuilder().WithObjects(existing(batchv1.JobFailed)).Build(),
+			Recorder: recorder,
+		}
+
+		assert.NilError(t, r.reconcileMigration(ctx, cluster, &observedInstances{}))
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MigrationSucceeded)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "MigrationFailed")
+		assert.Equal(t, len(recorder.Events), 1)
+
+		// The warning happens once.
+		assert.NilError(t, r.reconcileMigration(ctx, cluster, &observedInstances{}))
+		assert.Equal(t, len(recorder.Events), 1)
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Migration.Container.Image = "flyway:10"
+		job := existing(batchv1.JobComplete)
+		r := &Reconciler{Client: fake.NewClientBuilder().WithObjects(job).Build()}
+
+		assert.NilError(t, r.reconcileMigration(ctx, cluster, &observedInstances{}))
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MigrationSucceeded)
+		assert.Equal(t, condition.Reason, "MigrationPending")
+
+		err := r.Client.Get(ctx, client.ObjectKeyFromObject(job), job)
+		assert.Assert(t, apierrors.IsNotFound(err), "expected the Job to be deleted, got %v", err)
+	})
+
+	t.Run("Removed", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.MigrationRevision = revision
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type: v1beta1.MigrationSucceeded, Status: metav1.ConditionTrue, Reason: "MigrationSucceeded",
+		})
+		cluster.Spec.Migration = nil
+		job := existing(batchv1.JobComplete)
+		r := &Reconciler{Client: fake.NewClientBuilder().WithObjects(job).Build()}
+
+		assert.NilError(t, r.reconcileMigration(ctx, cluster, &observedInstances{}))
+		assert.Equal(t, cluster.Status.MigrationRevision, "")
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.MigrationSucceeded) == nil)
+
+		err := r.Client.Get(ctx, client.ObjectKeyFromObject(job), job)
+		assert.Assert(t, apierrors.IsNotFound(err), "expected the Job to be deleted, got %v", err)
+	})
+}
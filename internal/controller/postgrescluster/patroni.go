@@ -19,25 +19,33 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/crunchydata/postgres-operator/internal/citus"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/patroni"
 	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/util"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // +kubebuilder:rbac:groups="",resources=endpoints,verbs=deletecollection
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=deletecollection
 
 func (r *Reconciler) deletePatroniArtifacts(
 	ctx context.Context, cluster *v1beta1.PostgresCluster,
@@ -46,10 +54,15 @@ func (r *Reconciler) deletePatroniArtifacts(
 	// as Patroni creates them. Would their events cause too many reconciles?
 	// Foreground deletion may force us to adopt and set finalizers anyway.
 
+	var dcs client.Object = &corev1.Endpoints{}
+	if patroni.UseConfigMaps(cluster) {
+		dcs = &corev1.ConfigMap{}
+	}
+
 	selector, err := naming.AsSelector(naming.ClusterPatronis(cluster))
 	if err == nil {
 		err = errors.WithStack(
-			r.Client.DeleteAllOf(ctx, &corev1.Endpoints{},
+			r.Client.DeleteAllOf(ctx, dcs,
 				client.InNamespace(cluster.Namespace),
 				client.MatchingLabelsSelector{Selector: selector},
 			))
@@ -63,6 +76,35 @@ func (r *Reconciler) handlePatroniRestarts(
 ) error {
 	const container = naming.ContainerDatabase
 	var primaryNeedsRestart, replicaNeedsRestart *Instance
+	var pending []string
+
+	for _, instance := range instances.forCluster {
+		if len(instance.Pods) > 0 && patroni.PodRequiresRestart(instance.Pods[0]) {
+			pending = append(pending, instance.Name)
+		}
+	}
+
+	// Report any instances that need to restart so a parameter takes effect.
+	if len(pending) > 0 {
+		sort.Strings(pending)
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			ObservedGeneration: cluster.GetGeneration(),
+			Type:               v1beta1.PostgresRestartPending,
+			Status:             metav1.ConditionTrue,
+			Reason:             "ParametersChanged",
+			Message: "PostgreSQL must restart for parameters to take effect on " +
+				strings.Join(pending, ", "),
+		})
+	} else if meta.FindStatusCondition(cluster.Status.Conditions,
+		v1beta1.PostgresRestartPending) != nil {
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			ObservedGeneration: cluster.GetGeneration(),
+			Type:               v1beta1.PostgresRestartPending,
+			Status:             metav1.ConditionFalse,
+			Reason:             "Restarted",
+			Message:            "PostgreSQL is running with its current parameters",
+		})
+	}
 
 	// Look for one primary and one replica that need to restart. Ignore
 	// containers that are terminating or not running; Kubernetes will start
@@ -145,6 +187,11 @@ func (r *Reconciler) handlePatroniRestarts(
 func (r *Reconciler) reconcilePatroniDistributedConfiguration(
 	ctx context.Context, cluster *v1beta1.PostgresCluster,
 ) error {
+	if patroni.UseConfigMaps(cluster) {
+		// Kubernetes does not remove ConfigMaps, so there is nothing to protect.
+		return nil
+	}
+
 	// When using Endpoints for DCS, Patroni needs a Service to ensure that the
 	// Endpoints object is not removed by Kubernetes at startup. Patroni will
 	// create this object if it has permission to do so, but it won't set any
@@ -187,47 +234,91 @@ func (r *Reconciler) reconcilePatroniDynamicConfiguration(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
 	pgHBAs postgres.HBAs, pgParameters postgres.Parameters,
 ) error {
+	defer timeReconciler("patroniDynamicConfiguration").ObserveDuration()
+
 	if !patroni.ClusterBootstrapped(cluster) {
 		// Patroni has not yet bootstrapped. Dynamic configuration happens through
 		// configuration files during bootstrap, so there's nothing to do here.
 		return nil
 	}
 
-	var pod *corev1.Pod
+	// Patroni in Citus mode stores the configuration of each group separately.
+	// Calling `patronictl` in a Pod changes the configuration of its group, so
+	// find a running Patroni container in every group.
+	pods := map[int32]*corev1.Pod{}
 	for _, instance := range instances.forCluster {
+		var group int32
+		if citus.Enabled(cluster) && instance.Spec != nil && instance.Spec.CitusGroup != nil {
+			group = *instance.Spec.CitusGroup
+		}
+		if pods[group] != nil {
+			continue
+		}
 		if terminating, known := instance.IsTerminating(); !terminating && known {
 			running, known := instance.IsRunning(naming.ContainerDatabase)
 
 			if running && known && len(instance.Pods) > 0 {
-				pod = instance.Pods[0]
-				break
+				pods[group] = instance.Pods[0]
 			}
 		}
 	}
-	if pod == nil {
+	if len(pods) == 0 {
 		// There are no running Patroni containers; nothing to do.
 		return nil
 	}
 
-	// NOTE(cbandy): Despite the guards above, calling PodExec may still fail
-	// due to a missing or stopped container.
-
-	exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
-		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
-	}
-
 	var configuration map[string]interface{}
 	if cluster.Spec.Patroni != nil {
 		configuration = cluster.Spec.Patroni.DynamicConfiguration
 	}
 	configuration = patroni.DynamicConfiguration(cluster, configuration, pgHBAs, pgParameters)
 
-	return errors.WithStack(
-		patroni.Executor(exec).ReplaceConfiguration(ctx, configuration))
+	var err error
+	for _, pod := range pods {
+		// NOTE(cbandy): Despite the guards above, calling PodExec may still fail
+		// due to a missing or stopped container.
+		pod := pod
+		exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+			return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+		}
+
+		if err == nil {
+			err = errors.WithStack(
+				patroni.Executor(exec).ReplaceConfiguration(ctx, configuration))
+		}
+	}
+
+	// Report maintenance mode once Patroni has the configuration.
+	paused := cluster.Spec.Patroni != nil &&
+		cluster.Spec.Patroni.Paused != nil && *cluster.Spec.Patroni.Paused
+
+	if err == nil && paused {
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			ObservedGeneration: cluster.GetGeneration(),
+			Type:               v1beta1.PatroniPaused,
+			Status:             metav1.ConditionTrue,
+			Reason:             "Paused",
+			Message:            "Patroni is not managing PostgreSQL; automatic failover is disabled",
+		})
+	}
+	if err == nil && !paused &&
+		meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.PatroniPaused) != nil {
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			ObservedGeneration: cluster.GetGeneration(),
+			Type:               v1beta1.PatroniPaused,
+			Status:             metav1.ConditionFalse,
+			Reason:             "Resumed",
+			Message:            "Patroni is managing PostgreSQL",
+		})
+	}
+
+	return err
 }
 
 // generatePatroniLeaderLeaseService returns a v1.Service that exposes the
-// Patroni leader when Patroni is using Endpoints for its leader elections.
+// Patroni leader. When Patroni is using Endpoints for its leader elections,
+// Patroni manages the Endpoints of this Service. Otherwise, the Service
+// selects the leader Pod by the role label Patroni sets on it.
 func (r *Reconciler) generatePatroniLeaderLeaseService(
 	cluster *v1beta1.PostgresCluster) (*corev1.Service, error,
 ) {
@@ -258,6 +349,17 @@ func (r *Reconciler) generatePatroniLeaderLeaseService(
 	// - https://docs.k8s.io/concepts/services-networking/service/#services-without-selectors
 	service.Spec.Selector = nil
 
+	// Patroni does not manage Endpoints when it is using ConfigMaps. Select
+	// the Pod that Patroni labels as leader instead. Citus workers are labeled
+	// with a different key, so this selects only the coordinator.
+	if patroni.UseConfigMaps(cluster) {
+		service.Spec.Selector = map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelPatroni: naming.PatroniScope(cluster),
+			naming.LabelRole:    naming.RolePatroniLeader,
+		}
+	}
+
 	// The TargetPort must be the name (not the number) of the PostgreSQL
 	// ContainerPort. This name allows the port number to differ between
 	// instances, which can happen during a rolling update.
@@ -272,6 +374,8 @@ func (r *Reconciler) generatePatroniLeaderLeaseService(
 		service.Spec.Type = corev1.ServiceTypeClusterIP
 	} else {
 		service.Spec.Type = corev1.ServiceType(spec.Type)
+		service.Spec.IPFamilies = spec.IPFamilies
+		service.Spec.IPFamilyPolicy = spec.IPFamilyPolicy
 		if spec.NodePort != nil {
 			if service.Spec.Type == corev1.ServiceTypeClusterIP {
 				// The NodePort can only be set when the Service type is NodePort or
@@ -286,6 +390,8 @@ func (r *Reconciler) generatePatroniLeaderLeaseService(
 			}
 			servicePort.NodePort = *spec.NodePort
 		}
+		setServiceExternalTraffic(spec, service)
+		setServiceRouting(spec, service)
 	}
 	service.Spec.Ports = []corev1.ServicePort{servicePort}
 
@@ -296,8 +402,8 @@ func (r *Reconciler) generatePatroniLeaderLeaseService(
 // +kubebuilder:rbac:groups="",resources="services",verbs={create,patch}
 
 // reconcilePatroniLeaderLease sets labels and ownership on the objects Patroni
-// creates for its leader elections. When Patroni is using Endpoints for this,
-// the returned Service resolves to the elected leader. Otherwise, it is nil.
+// creates for its leader elections. The returned Service resolves to the
+// elected leader.
 func (r *Reconciler) reconcilePatroniLeaderLease(
 	ctx context.Context, cluster *v1beta1.PostgresCluster,
 ) (*corev1.Service, error) {
@@ -319,6 +425,8 @@ func (r *Reconciler) reconcilePatroniStatus(
 	ctx context.Context, cluster *v1beta1.PostgresCluster,
 	observedInstances *observedInstances,
 ) (reconcile.Result, error) {
+	defer timeReconciler("patroniStatus").ObserveDuration()
+
 	result := reconcile.Result{}
 	log := logging.FromContext(ctx)
 
@@ -329,14 +437,21 @@ func (r *Reconciler) reconcilePatroniStatus(
 		}
 	}
 
-	dcs := &corev1.Endpoints{ObjectMeta: naming.PatroniDistributedConfiguration(cluster)}
+	var dcs client.Object = &corev1.Endpoints{
+		ObjectMeta: naming.PatroniDistributedConfiguration(cluster),
+	}
+	if patroni.UseConfigMaps(cluster) {
+		dcs = &corev1.ConfigMap{ObjectMeta: naming.PatroniDistributedConfiguration(cluster)}
+	}
 	err := errors.WithStack(client.IgnoreNotFound(
 		r.Client.Get(ctx, client.ObjectKeyFromObject(dcs), dcs)))
 
 	if err == nil {
-		if dcs.Annotations["initialize"] != "" {
+		cluster.Status.Patroni.History = patroniHistoryStatus(ctx, dcs.GetAnnotations()["history"])
+
+		if dcs.GetAnnotations()["initialize"] != "" {
 			// After bootstrap, Patroni writes the cluster system identifier to DCS.
-			cluster.Status.Patroni.SystemIdentifier = dcs.Annotations["initialize"]
+			cluster.Status.Patroni.SystemIdentifier = dcs.GetAnnotations()["initialize"]
 		} else if readyInstance {
 			// While we typically expect a value for the initialize key to be present in the
 			// Endpoints above by the time the StatefulSet for any instance indicates "ready"
@@ -354,6 +469,113 @@ func (r *Reconciler) reconcilePatroniStatus(
 	return result, err
 }
 
+// patroniHistoryStatus returns the most recent entries of the history that
+// Patroni keeps in its DCS. It returns nil when there are none.
+func patroniHistoryStatus(ctx context.Context, value string) []v1beta1.PatroniHistoryStatus {
+	if value == "" {
+		return nil
+	}
+
+	entries, err := patroni.ParseHistory(value)
+	if err != nil {
+		logging.FromContext(ctx).Error(err, "unable to parse Patroni history")
+		return nil
+	}
+
+	const limit = 10
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+
+	history := make([]v1beta1.PatroniHistoryStatus, 0, len(entries))
+	for _, entry := range entries {
+		status := v1beta1.PatroniHistoryStatus{
+			Timeline: entry.Timeline,
+			Reason:   entry.Reason,
+			Leader:   entry.Leader,
+		}
+		if entry.Time != nil {
+			status.Time = &metav1.Time{Time: *entry.Time}
+		}
+		history = append(history, status)
+	}
+	return history
+}
+
+// reconcilePatroniPrimary records the instance that Patroni labeled as its
+// leader in cluster.Status.Patroni.Primary. It emits an event when that changes,
+// such as after a failover. It leaves the status unchanged when no instance is
+// labeled, e.g. while Patroni elects a new leader.
+func (r *Reconciler) reconcilePatroniPrimary(
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) {
+	var primary *Instance
+	for _, instance := range instances.forCluster {
+		if is, known := instance.IsPrimary(); is && known {
+			primary = instance
+		}
+	}
+	if primary == nil {
+		return
+	}
+
+	if previous := cluster.Status.Patroni.Primary; previous != "" && previous != primary.Name {
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "PrimaryChanged",
+			"Primary changed from %q to %q on timeline %d",
+			previous, primary.Name, patroni.PodTimeline(primary.Pods[0]))
+	}
+
+	cluster.Status.Patroni.Primary = primary.Name
+}
+
+// reconcilePatroniMembers populates cluster.Status.Patroni.Members with the
+// members reported by Patroni. It leaves the status unchanged when no Pod can
+// be asked or Patroni cannot answer.
+func (r *Reconciler) reconcilePatroniMembers(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	instances *observedInstances,
+) {
+	var runningPod *corev1.Pod
+	for _, instance := range instances.forCluster {
+		if running, known := instance.IsRunning(naming.ContainerDatabase); running &&
+			known && len(instance.Pods) == 1 {
+
+			runningPod = instance.Pods[0]
+			break
+		}
+	}
+	if runningPod == nil {
+		return
+	}
+
+	exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer,
+		command ...string) error {
+		return r.PodExec(runningPod.Namespace, runningPod.Name, naming.ContainerDatabase, stdin,
+			stdout, stderr, command...)
+	}
+
+	// The members are informational. Keep the last observation rather than
+	// interrupt reconciliation when Patroni does not answer.
+	members, err := patroni.Executor(exec).ListMembers(ctx)
+	if err != nil {
+		logging.FromContext(ctx).Error(err, "unable to list Patroni members")
+		return
+	}
+
+	cluster.Status.Patroni.Members = make([]v1beta1.PatroniMemberStatus, 0, len(members))
+	for _, member := range members {
+		cluster.Status.Patroni.Members = append(cluster.Status.Patroni.Members,
+			v1beta1.PatroniMemberStatus{
+				Name:           member.Name,
+				Role:           member.Role,
+				State:          member.State,
+				Timeline:       member.Timeline,
+				LagMegabytes:   member.LagMegabytes,
+				PendingRestart: member.PendingRestart,
+			})
+	}
+}
+
 // reconcileReplicationSecret creates a secret containing the TLS
 // certificate, key and CA certificate for use with the replication and
 // pg_rewind accounts in Postgres.
@@ -453,6 +675,78 @@ func replicationCertSecretProjection(certificate *corev1.Secret) *corev1.SecretP
 	}
 }
 
+// queuePatroniSwitchover returns true when a requested switchover must wait
+// for its window to open, along with how long until it does.
+func (r *Reconciler) queuePatroniSwitchover(
+	cluster *v1beta1.PostgresCluster, now time.Time,
+) (bool, time.Duration) {
+	if cluster.Spec.Patroni == nil ||
+		cluster.Spec.Patroni.Switchover == nil ||
+		!cluster.Spec.Patroni.Switchover.Enabled ||
+		cluster.Spec.Patroni.Switchover.Window == nil ||
+		cluster.Spec.Patroni.Switchover.Type == v1beta1.PatroniSwitchoverTypeFailover {
+		cluster.Status.Patroni.SwitchoverQueued = nil
+		return false, 0
+	}
+
+	annotation := cluster.GetAnnotations()[naming.PatroniSwitchover]
+	window := cluster.Spec.Patroni.Switchover.Window
+	status := cluster.Status.Patroni.Switchover
+
+	if annotation == "" || (status != nil && *status == annotation) {
+		cluster.Status.Patroni.SwitchoverQueued = nil
+		return false, 0
+	}
+
+	// Announce a queued switchover once, and again when the spec changes.
+	announce := cluster.Status.Patroni.SwitchoverQueued == nil ||
+		*cluster.Status.Patroni.SwitchoverQueued != annotation ||
+		cluster.Status.ObservedGeneration != cluster.GetGeneration()
+
+	schedule, err := cron.ParseStandard(window.Schedule)
+	if err != nil {
+		if announce {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidSwitchoverWindow",
+				"Switchover %q cannot start: %v", annotation, err)
+		}
+		cluster.Status.Patroni.SwitchoverQueued = initialize.String(annotation)
+		return true, 0
+	}
+
+	// Interpret the schedule the same way as other scheduled work.
+	if tz := cluster.Spec.Config.Timezone; tz != "" {
+		if location, err := time.LoadLocation(tz); err == nil {
+			now = now.In(location)
+		}
+	}
+
+	// The window is open when it started during the last duration.
+	duration := time.Duration(window.DurationSeconds) * time.Second
+	if opened := schedule.Next(now.Add(-duration)); !opened.IsZero() && !opened.After(now) {
+		cluster.Status.Patroni.SwitchoverQueued = nil
+		return false, 0
+	}
+
+	cluster.Status.Patroni.SwitchoverQueued = initialize.String(annotation)
+
+	next := schedule.Next(now)
+	if next.IsZero() {
+		if announce {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidSwitchoverWindow",
+				"Switchover %q cannot start: its window never opens", annotation)
+		}
+		return true, 0
+	}
+
+	if announce {
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "SwitchoverQueued",
+			"Switchover %q will start when its window opens at %s",
+			annotation, next.Format(time.RFC3339))
+	}
+
+	return true, next.Sub(now)
+}
+
 func (r *Reconciler) reconcilePatroniSwitchover(ctx context.Context,
 	cluster *v1beta1.PostgresCluster, instances *observedInstances) error {
 	log := logging.FromContext(ctx)
@@ -609,7 +903,349 @@ func (r *Reconciler) reconcilePatroniSwitchover(ctx context.Context,
 	if err == nil {
 		cluster.Status.Patroni.Switchover = initialize.String(annotation)
 		cluster.Status.Patroni.SwitchoverTimeline = nil
+
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "SwitchoverCompleted",
+			"Switchover %q changed the primary", annotation)
 	}
 
 	return err
 }
+
+// reconcilePatroniAPI writes the objects that expose the Patroni REST API to
+// tools outside the PostgresCluster. It deletes them when the API Service is
+// not specified.
+func (r *Reconciler) reconcilePatroniAPI(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	root *pki.RootCertificateAuthority,
+) error {
+	err := r.reconcilePatroniAPIClientSecret(ctx, cluster, root)
+
+	if err == nil {
+		err = r.reconcilePatroniAPIAuthSecret(ctx, cluster)
+	}
+	if err == nil {
+		err = r.reconcilePatroniAPIService(ctx, cluster)
+	}
+	if err == nil {
+		err = r.reconcilePatroniAPINetworkPolicy(ctx, cluster)
+	}
+	return err
+}
+
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={create,delete,patch}
+
+// reconcilePatroniAPIClientSecret writes the Secret containing a client
+// certificate that Patroni accepts for "unsafe" REST API endpoints and the
+// certificate authority that signed the Patroni server certificates.
+func (r *Reconciler) reconcilePatroniAPIClientSecret(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	root *pki.RootCertificateAuthority,
+) error {
+	const keyCertificate, keyPrivateKey, rootCA = "tls.crt", "tls.key", "ca.crt"
+
+	existing := &corev1.Secret{ObjectMeta: naming.PatroniAPIClientSecret(cluster)}
+	err := errors.WithStack(
+		r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing))
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+
+	if cluster.Spec.Patroni == nil || cluster.Spec.Patroni.APIService == nil {
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, existing))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	// Patroni verifies client certificates against its certificate authority
+	// but does not look at their names. That authority is the root shared by
+	// every cluster, so they all accept this certificate. The API type warns
+	// about this; see [v1beta1.PatroniSpec.APIService].
+	// - https://patroni.readthedocs.io/en/latest/security.html#protecting-the-rest-api
+	leaf := &pki.LeafCertificate{}
+	commonName := "patroni-api-client"
+	dnsNames := []string{commonName}
+
+	// Unmarshal and validate the stored leaf. These first errors can
+	// be ignored because they result in an invalid leaf which is then
+	// correctly regenerated.
+	_ = leaf.Certificate.UnmarshalText(existing.Data[keyCertificate])
+	_ = leaf.PrivateKey.UnmarshalText(existing.Data[keyPrivateKey])
+
+	leaf, err = root.RegenerateLeafWhenNecessary(leaf, commonName, dnsNames)
+	err = errors.WithStack(err)
+
+	intent := &corev1.Secret{ObjectMeta: naming.PatroniAPIClientSecret(cluster)}
+	intent.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	intent.Data = make(map[string][]byte)
+
+	intent.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+	intent.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster:            cluster.Name,
+			naming.LabelClusterCertificate: "patroni-api-client-tls",
+		})
+
+	if err == nil {
+		err = errors.WithStack(r.setControllerReference(cluster, intent))
+	}
+	if err == nil {
+		intent.Data[rootCA], err = root.Certificate.MarshalText()
+		err = errors.WithStack(err)
+	}
+	if err == nil {
+		intent.Data[keyCertificate], err = leaf.Certificate.MarshalText()
+		err = errors.WithStack(err)
+	}
+	if err == nil {
+		intent.Data[keyPrivateKey], err = leaf.PrivateKey.MarshalText()
+		err = errors.WithStack(err)
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, intent))
+	}
+	return err
+}
+
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={create,delete,patch}
+
+// reconcilePatroniAPIAuthSecret writes the Secret containing generated
+// credentials of the Patroni REST API. It deletes the Secret when the REST API
+// requires no credentials or they come from another Secret.
+func (r *Reconciler) reconcilePatroniAPIAuthSecret(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	existing := &corev1.Secret{ObjectMeta: naming.PatroniAPIAuthSecret(cluster)}
+	err := errors.WithStack(
+		r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing))
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
+
+	if patroni.APIAuthenticationSecretName(cluster) != existing.Name {
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, existing))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	intent := &corev1.Secret{ObjectMeta: naming.PatroniAPIAuthSecret(cluster)}
+	intent.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	intent.Data = make(map[string][]byte)
+
+	intent.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+	intent.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+		})
+
+	intent.Data["username"] = existing.Data["username"]
+	intent.Data["password"] = existing.Data["password"]
+
+	if len(intent.Data["username"]) == 0 {
+		intent.Data["username"] = []byte("patroni")
+	}
+	if len(intent.Data["password"]) == 0 {
+		password, err := util.GenerateAlphaNumericPassword(util.DefaultGeneratedPasswordLength)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		intent.Data["password"] = []byte(password)
+	}
+
+	err = errors.WithStack(r.setControllerReference(cluster, intent))
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, intent))
+	}
+	return err
+}
+
+// generatePatroniAPIService returns a v1.Service that exposes the Patroni REST
+// API of every instance. The second return value is false when the Service is
+// not specified.
+func (r *Reconciler) generatePatroniAPIService(
+	cluster *v1beta1.PostgresCluster) (*corev1.Service, bool, error,
+) {
+	service := &corev1.Service{ObjectMeta: naming.PatroniAPIService(cluster)}
+	service.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Service"))
+
+	if cluster.Spec.Patroni == nil || cluster.Spec.Patroni.APIService == nil {
+		return service, false, nil
+	}
+	spec := &cluster.Spec.Patroni.APIService.ServiceSpec
+
+	service.Annotations = naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		spec.Metadata.GetAnnotationsOrNil())
+	service.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelPatroni: naming.PatroniScope(cluster),
+		})
+
+	// Allocate an IP address and/or node port and let Kubernetes manage the
+	// Endpoints by selecting every Pod that runs Patroni.
+	service.Spec.Selector = naming.ClusterPatronis(cluster).MatchLabels
+
+	// The Patroni REST API does not have a named ContainerPort, so target its
+	// number. Changing the number restarts every instance anyway.
+	servicePort := corev1.ServicePort{
+		Name:       naming.PortPatroni,
+		Port:       *cluster.Spec.Patroni.Port,
+		Protocol:   corev1.ProtocolTCP,
+		TargetPort: intstr.FromInt(int(*cluster.Spec.Patroni.Port)),
+	}
+
+	service.Spec.Type = corev1.ServiceType(spec.Type)
+	service.Spec.IPFamilies = spec.IPFamilies
+	service.Spec.IPFamilyPolicy = spec.IPFamilyPolicy
+	if spec.NodePort != nil {
+		if service.Spec.Type == corev1.ServiceTypeClusterIP {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "MisconfiguredClusterIP",
+				"NodePort cannot be set with type ClusterIP on Service %q", service.Name)
+			return nil, true, fmt.Errorf("NodePort cannot be set with type ClusterIP on Service %q", service.Name)
+		}
+		servicePort.NodePort = *spec.NodePort
+	}
+	setServiceExternalTraffic(spec, service)
+	setServiceRouting(spec, service)
+	service.Spec.Ports = []corev1.ServicePort{servicePort}
+
+	err := errors.WithStack(r.setControllerReference(cluster, service))
+
+	return service, true, err
+}
+
+// +kubebuilder:rbac:groups="",resources="services",verbs={get}
+// +kubebuilder:rbac:groups="",resources="services",verbs={create,delete,patch}
+
+// reconcilePatroniAPIService writes the Service that exposes the Patroni REST API.
+func (r *Reconciler) reconcilePatroniAPIService(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	service, specified, err := r.generatePatroniAPIService(cluster)
+
+	if err == nil && !specified {
+		// The Service is not specified; delete it if it exists. Check the
+		// client cache first using Get.
+		key := client.ObjectKeyFromObject(service)
+		err := errors.WithStack(r.Client.Get(ctx, key, service))
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, service))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, service))
+	}
+	return err
+}
+
+// generatePatroniAPINetworkPolicy returns a NetworkPolicy that limits which
+// sources can connect to the Patroni REST API. The second return value is
+// false when no sources are specified.
+func (r *Reconciler) generatePatroniAPINetworkPolicy(
+	cluster *v1beta1.PostgresCluster,
+) (*networkingv1.NetworkPolicy, bool, error) {
+	policy := &networkingv1.NetworkPolicy{ObjectMeta: naming.PatroniAPIService(cluster)}
+	policy.SetGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy"))
+
+	if cluster.Spec.Patroni == nil || cluster.Spec.Patroni.APIService == nil ||
+		len(cluster.Spec.Patroni.APIService.AllowedSources) == 0 {
+		return policy, false, nil
+	}
+
+	policy.Annotations = naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil())
+	policy.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelPatroni: naming.PatroniScope(cluster),
+		})
+
+	// Once a NetworkPolicy selects a Pod, only the connections it allows can
+	// reach that Pod. Allow everything that worked before, except connections
+	// to Patroni from outside the PostgresCluster. The TCP ports around Patroni
+	// are ranges, which require Kubernetes 1.22 or newer.
+	// - https://docs.k8s.io/concepts/services-networking/network-policies/#the-two-sorts-of-pod-isolation
+	// - https://docs.k8s.io/concepts/services-networking/network-policies/#targeting-a-range-of-ports
+	patroni := *cluster.Spec.Patroni.Port
+	patroniPort := intstr.FromInt(int(patroni))
+	belowPatroni := intstr.FromInt(1)
+	abovePatroni := intstr.FromInt(int(patroni) + 1)
+	tcp, udp, sctp := corev1.ProtocolTCP, corev1.ProtocolUDP, corev1.ProtocolSCTP
+
+	others := []networkingv1.NetworkPolicyPort{
+		{Protocol: &tcp, Port: &belowPatroni, EndPort: initialize.Int32(patroni - 1)},
+	}
+	if patroni < 65535 {
+		others = append(others, networkingv1.NetworkPolicyPort{
+			Protocol: &tcp, Port: &abovePatroni, EndPort: initialize.Int32(65535),
+		})
+	}
+	others = append(others,
+		networkingv1.NetworkPolicyPort{Protocol: &udp},
+		networkingv1.NetworkPolicyPort{Protocol: &sctp})
+
+	policy.Spec.PodSelector = naming.ClusterPatronis(cluster)
+	policy.Spec.PolicyTypes = []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}
+	policy.Spec.Ingress = []networkingv1.NetworkPolicyIngressRule{
+		{
+			// Pods of this cluster, such as other instances, pgBackRest, and
+			// PgBouncer, connect to any port.
+			From: []networkingv1.NetworkPolicyPeer{{
+				PodSelector: &metav1.LabelSelector{
+					MatchLabels: map[string]string{naming.LabelCluster: cluster.Name},
+				},
+			}},
+		},
+		{
+			// Anything can connect to every other port, such as PostgreSQL,
+			// its exporter, and any sidecars.
+			Ports: others,
+		},
+		{
+			From:  cluster.Spec.Patroni.APIService.AllowedSources,
+			Ports: []networkingv1.NetworkPolicyPort{{Protocol: &tcp, Port: &patroniPort}},
+		},
+	}
+
+	err := errors.WithStack(r.setControllerReference(cluster, policy))
+
+	return policy, true, err
+}
+
+// +kubebuilder:rbac:groups="networking.k8s.io",resources="networkpolicies",verbs={get}
+// +kubebuilder:rbac:groups="networking.k8s.io",resources="networkpolicies",verbs={create,delete,patch}
+
+// reconcilePatroniAPINetworkPolicy writes the NetworkPolicy that limits
+// connections to the Patroni REST API.
+func (r *Reconciler) reconcilePatroniAPINetworkPolicy(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	policy, specified, err := r.generatePatroniAPINetworkPolicy(cluster)
+
+	if err == nil && !specified {
+		// The NetworkPolicy is not specified; delete it if it exists. Check
+		// the client cache first using Get.
+		key := client.ObjectKeyFromObject(policy)
+		err := errors.WithStack(r.Client.Get(ctx, key, policy))
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, policy))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, policy))
+	}
+	return err
+}
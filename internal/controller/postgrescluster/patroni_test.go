@@ -32,7 +32,9 @@ import (
 	"gotest.tools/v3/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -41,6 +43,8 @@ import (
 
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
@@ -100,6 +104,21 @@ ownerReferences:
 		`))
 	})
 
+	t.Run("ConfigMaps", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni = &v1beta1.PatroniSpec{UseConfigMaps: initialize.Bool(true)}
+
+		service, err := reconciler.generatePatroniLeaderLeaseService(cluster)
+		assert.NilError(t, err)
+
+		// Selects the Pod that Patroni labels as leader.
+		assert.DeepEqual(t, service.Spec.Selector, map[string]string{
+			"postgres-operator.crunchydata.com/cluster": "pg2",
+			"postgres-operator.crunchydata.com/patroni": "pg2-ha",
+			"postgres-operator.crunchydata.com/role":    "master",
+		})
+	})
+
 	t.Run("AnnotationsLabels", func(t *testing.T) {
 		cluster := cluster.DeepCopy()
 		cluster.Spec.Metadata = &v1beta1.Metadata{
@@ -238,6 +257,39 @@ ownerReferences:
 			test.Expect(t, service, err)
 		})
 	}
+
+	t.Run("ExternalTrafficAndIPFamilies", func(t *testing.T) {
+		local := corev1.ServiceExternalTrafficPolicyTypeLocal
+		preferDual := corev1.IPFamilyPolicyPreferDualStack
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Service = &v1beta1.ServiceSpec{
+			Type:                     "LoadBalancer",
+			ExternalTrafficPolicy:    &local,
+			IPFamilies:               []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol},
+			IPFamilyPolicy:           &preferDual,
+			LoadBalancerSourceRanges: []string{"192.0.2.0/24"},
+		}
+
+		service, err := reconciler.generatePatroniLeaderLeaseService(cluster)
+		assert.NilError(t, err)
+		alwaysExpect(t, service)
+		assert.Assert(t, marshalMatches(service.Spec, `
+externalTrafficPolicy: Local
+ipFamilies:
+- IPv6
+- IPv4
+ipFamilyPolicy: PreferDualStack
+loadBalancerSourceRanges:
+- 192.0.2.0/24
+ports:
+- name: postgres
+  port: 9876
+  protocol: TCP
+  targetPort: postgres
+type: LoadBalancer
+		`))
+	})
 }
 
 func TestReconcilePatroniLeaderLease(t *testing.T) {
@@ -527,6 +579,94 @@ func TestReconcilePatroniStatus(t *testing.T) {
 	}
 }
 
+func TestQueuePatroniSwitchover(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := Reconciler{Recorder: recorder}
+
+	// Tuesday, March 15th at 10:30 UTC
+	now := time.Date(2022, time.March, 15, 10, 30, 0, 0, time.UTC)
+
+	cluster := testCluster()
+	cluster.Annotations = map[string]string{naming.PatroniSwitchover: "trigger"}
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+		Switchover: &v1beta1.PatroniSwitchover{Enabled: true},
+	}
+
+	t.Run("NoWindow", func(t *testing.T) {
+		queued, _ := r.queuePatroniSwitchover(cluster, now)
+		assert.Assert(t, !queued)
+	})
+
+	t.Run("Open", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni.Switchover.Window = &v1beta1.PatroniSwitchoverWindow{
+			Schedule: "0 10 * * *", DurationSeconds: 3600,
+		}
+		queued, _ := r.queuePatroniSwitchover(cluster, now)
+		assert.Assert(t, !queued)
+	})
+
+	t.Run("Closed", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni.Switchover.Window = &v1beta1.PatroniSwitchoverWindow{
+			Schedule: "0 10 * * *", DurationSeconds: 600,
+		}
+		queued, wait := r.queuePatroniSwitchover(cluster, now)
+		assert.Assert(t, queued)
+		assert.Equal(t, wait, 23*time.Hour+30*time.Minute)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "SwitchoverQueued"))
+		assert.DeepEqual(t, cluster.Status.Patroni.SwitchoverQueued, initialize.String("trigger"))
+
+		t.Run("Repeated", func(t *testing.T) {
+			cluster := cluster.DeepCopy()
+
+			queued, _ := r.queuePatroniSwitchover(cluster, now.Add(time.Minute))
+			assert.Assert(t, queued)
+			assert.Equal(t, len(recorder.Events), 0, "expected no event")
+		})
+
+		t.Run("Timezone", func(t *testing.T) {
+			cluster := cluster.DeepCopy()
+			cluster.Spec.Config.Timezone = "America/New_York"
+			cluster.Generation++
+
+			// 10:30 UTC is 06:30 in New York.
+			queued, wait := r.queuePatroniSwitchover(cluster, now)
+			assert.Assert(t, queued)
+			assert.Equal(t, wait, 3*time.Hour+30*time.Minute)
+			assert.Assert(t, cmp.Contains(<-recorder.Events, "SwitchoverQueued"))
+		})
+
+		t.Run("Failover", func(t *testing.T) {
+			cluster := cluster.DeepCopy()
+			cluster.Spec.Patroni.Switchover.Type = v1beta1.PatroniSwitchoverTypeFailover
+
+			queued, _ := r.queuePatroniSwitchover(cluster, now)
+			assert.Assert(t, !queued)
+		})
+
+		t.Run("Completed", func(t *testing.T) {
+			cluster := cluster.DeepCopy()
+			cluster.Status.Patroni.Switchover = initialize.String("trigger")
+
+			queued, _ := r.queuePatroniSwitchover(cluster, now)
+			assert.Assert(t, !queued)
+			assert.Assert(t, cluster.Status.Patroni.SwitchoverQueued == nil)
+		})
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni.Switchover.Window = &v1beta1.PatroniSwitchoverWindow{
+			Schedule: "0 10 * *", DurationSeconds: 600,
+		}
+		queued, wait := r.queuePatroniSwitchover(cluster, now)
+		assert.Assert(t, queued)
+		assert.Equal(t, wait, time.Duration(0))
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "InvalidSwitchoverWindow"))
+	})
+}
+
 func TestReconcilePatroniSwitchover(t *testing.T) {
 	_, client := setupKubernetes(t)
 	require.ParallelCapacity(t, 0)
@@ -534,7 +674,8 @@ func TestReconcilePatroniSwitchover(t *testing.T) {
 	var called, failover, callError, callFails bool
 	var timelineCallNoLeader, timelineCall bool
 	r := Reconciler{
-		Client: client,
+		Client:   client,
+		Recorder: new(record.FakeRecorder),
 		PodExec: func(namespace, pod, container string,
 			stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
 			called = true
@@ -1005,3 +1146,375 @@ func TestReconcilePatroniSwitchover(t *testing.T) {
 		assert.Assert(t, cluster.Status.Patroni.SwitchoverTimeline == nil)
 	})
 }
+
+func TestGeneratePatroniAPIService(t *testing.T) {
+	_, cc := setupKubernetes(t)
+	require.ParallelCapacity(t, 0)
+
+	reconciler := &Reconciler{
+		Client:   cc,
+		Recorder: new(record.FakeRecorder),
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Name = "pg2"
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{Port: initialize.Int32(8008)}
+
+	t.Run("Unspecified", func(t *testing.T) {
+		service, specified, err := reconciler.generatePatroniAPIService(cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, !specified)
+		assert.Equal(t, service.Name, "pg2-patroni-api")
+	})
+
+	cluster.Spec.Patroni.APIService = &v1beta1.PatroniAPIServiceSpec{
+		ServiceSpec: v1beta1.ServiceSpec{
+			Metadata: &v1beta1.Metadata{
+				Annotations: map[string]string{"some": "note"},
+			},
+			Type: "ClusterIP",
+		},
+	}
+
+	t.Run("ClusterIP", func(t *testing.T) {
+		service, specified, err := reconciler.generatePatroniAPIService(cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, specified)
+
+		assert.Assert(t, marshalMatches(service.ObjectMeta, `
+annotations:
+  some: note
+creationTimestamp: null
+labels:
+  postgres-operator.crunchydata.com/cluster: pg2
+  postgres-operator.crunchydata.com/patroni: pg2-ha
+name: pg2-patroni-api
+namespace: ns1
+ownerReferences:
+- apiVersion: postgres-operator.crunchydata.com/v1beta1
+  blockOwnerDeletion: true
+  controller: true
+  kind: PostgresCluster
+  name: pg2
+  uid: ""
+		`))
+		assert.Assert(t, marshalMatches(service.Spec, `
+ports:
+- name: patroni
+  port: 8008
+  protocol: TCP
+  targetPort: 8008
+selector:
+  postgres-operator.crunchydata.com/cluster: pg2
+  postgres-operator.crunchydata.com/patroni: pg2-ha
+type: ClusterIP
+		`))
+	})
+
+	t.Run("ClusterIPWithNodePort", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni.APIService.NodePort = initialize.Int32(32000)
+
+		_, specified, err := reconciler.generatePatroniAPIService(cluster)
+		assert.Assert(t, specified)
+		assert.ErrorContains(t, err, "NodePort cannot be set with type ClusterIP")
+	})
+}
+
+func TestGeneratePatroniAPINetworkPolicy(t *testing.T) {
+	_, cc := setupKubernetes(t)
+	require.ParallelCapacity(t, 0)
+
+	reconciler := &Reconciler{Client: cc}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Name = "pg2"
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{Port: initialize.Int32(8008)}
+	cluster.Spec.Patroni.APIService = new(v1beta1.PatroniAPIServiceSpec)
+
+	t.Run("NoSources", func(t *testing.T) {
+		_, specified, err := reconciler.generatePatroniAPINetworkPolicy(cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, !specified)
+	})
+
+	cluster.Spec.Patroni.APIService.AllowedSources = []networkingv1.NetworkPolicyPeer{{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"purpose": "monitoring"},
+		},
+	}}
+
+	t.Run("Sources", func(t *testing.T) {
+		policy, specified, err := reconciler.generatePatroniAPINetworkPolicy(cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, specified)
+
+		assert.Assert(t, marshalMatches(policy.TypeMeta, `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+		`))
+		assert.Assert(t, marshalMatches(policy.Spec, `
+ingress:
+- from:
+  - podSelector:
+      matchLabels:
+        postgres-operator.crunchydata.com/cluster: pg2
+- ports:
+  - endPort: 8007
+    port: 1
+    protocol: TCP
+  - endPort: 65535
+    port: 8009
+    protocol: TCP
+  - protocol: UDP
+  - protocol: SCTP
+- from:
+  - namespaceSelector:
+      matchLabels:
+        purpose: monitoring
+  ports:
+  - port: 8008
+    protocol: TCP
+podSelector:
+  matchLabels:
+    postgres-operator.crunchydata.com/cluster: pg2
+    postgres-operator.crunchydata.com/patroni: pg2-ha
+policyTypes:
+- Ingress
+		`))
+	})
+
+	t.Run("HighestPort", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni.Port = initialize.Int32(65535)
+
+		policy, specified, err := reconciler.generatePatroniAPINetworkPolicy(cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, specified)
+
+		assert.Assert(t, marshalMatches(policy.Spec.Ingress[1], `
+ports:
+- endPort: 65534
+  port: 1
+  protocol: TCP
+- protocol: UDP
+- protocol: SCTP
+		`))
+	})
+}
+
+func TestReconcilePatroniAPIClientSecret(t *testing.T) {
+	ctx := context.Background()
+	_, cc := setupKubernetes(t)
+	require.ParallelCapacity(t, 1)
+
+	reconciler := &Reconciler{Client: cc, Owner: client.FieldOwner(t.Name())}
+
+	cluster := testCluster()
+	cluster.Namespace = setupNamespace(t, cc).Name
+	assert.NilError(t, cc.Create(ctx, cluster))
+
+	root, err := reconciler.reconcileRootCertificate(ctx, cluster)
+	assert.NilError(t, err)
+
+	secret := &corev1.Secret{ObjectMeta: naming.PatroniAPIClientSecret(cluster)}
+
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+		APIService: new(v1beta1.PatroniAPIServiceSpec),
+	}
+	assert.NilError(t, reconciler.reconcilePatroniAPIClientSecret(ctx, cluster, root))
+	assert.NilError(t, cc.Get(ctx, client.ObjectKeyFromObject(secret), secret))
+
+	for _, key := range []string{"ca.crt", "tls.crt", "tls.key"} {
+		assert.Assert(t, len(secret.Data[key]) > 0, "expected %q", key)
+	}
+
+	cluster.Spec.Patroni.APIService = nil
+	assert.NilError(t, reconciler.reconcilePatroniAPIClientSecret(ctx, cluster, root))
+
+	err = cc.Get(ctx, client.ObjectKeyFromObject(secret), secret)
+	assert.Assert(t, apierrors.IsNotFound(err) || secret.DeletionTimestamp != nil, "got %v", err)
+}
+
+func TestPatroniHistoryStatus(t *testing.T) {
+	ctx := context.Background()
+
+	assert.Assert(t, patroniHistoryStatus(ctx, "") == nil)
+	assert.Assert(t, patroniHistoryStatus(ctx, "bogus") == nil)
+
+	var entries []string
+	for i := 1; i <= 12; i++ {
+		entries = append(entries, fmt.Sprintf(
+			`[%d, 100, "no recovery target specified", "2022-03-%02dT10:30:00+00:00", "hippo-%d"]`, i, i, i))
+	}
+
+	history := patroniHistoryStatus(ctx, "["+strings.Join(entries, ",")+"]")
+	assert.Equal(t, len(history), 10)
+	assert.Equal(t, history[0].Timeline, int64(3))
+	assert.Equal(t, history[9].Timeline, int64(12))
+	assert.Equal(t, history[9].Leader, "hippo-12")
+	assert.Equal(t, history[9].Reason, "no recovery target specified")
+	assert.Equal(t, history[9].Time.UTC().Format(time.RFC3339), "2022-03-12T10:30:00Z")
+}
+
+func TestReconcilePatroniMembers(t *testing.T) {
+	ctx := context.Background()
+
+	called := false
+	reconciler := &Reconciler{
+		PodExec: func(
+			namespace, pod, container string,
+			stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			called = true
+			assert.Equal(t, pod, "running-pod")
+			assert.DeepEqual(t, command, []string{"patronictl", "list", "--format", "json"})
+
+			_, err := stdout.Write([]byte(`[` +
+				`{"Cluster":"hippo-ha","Member":"hippo-a-0","Role":"Leader","State":"running","TL":3},` +
+				`{"Cluster":"hippo-ha","Member":"hippo-b-0","Role":"Replica","State":"running","TL":3,"Lag in MB":4,"Pending restart":"*"}` +
+				`]`))
+			return err
+		},
+	}
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Status.Patroni.Members = []v1beta1.PatroniMemberStatus{{Name: "stale"}}
+
+	t.Run("NoRunningPod", func(t *testing.T) {
+		reconciler.reconcilePatroniMembers(ctx, cluster,
+			&observedInstances{forCluster: []*Instance{{Pods: []*corev1.Pod{{}}}}})
+		assert.Assert(t, !called)
+		assert.Equal(t, cluster.Status.Patroni.Members[0].Name, "stale")
+	})
+
+	t.Run("Running", func(t *testing.T) {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "running-pod"}}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  naming.ContainerDatabase,
+			State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+		}}
+
+		reconciler.reconcilePatroniMembers(ctx, cluster,
+			&observedInstances{forCluster: []*Instance{{Pods: []*corev1.Pod{pod}}}})
+		assert.Assert(t, called)
+		assert.DeepEqual(t, cluster.Status.Patroni.Members, []v1beta1.PatroniMemberStatus{
+			{Name: "hippo-a-0", Role: "Leader", State: "running", Timeline: initialize.Int64(3)},
+			{
+				Name: "hippo-b-0", Role: "Replica", State: "running", Timeline: initialize.Int64(3),
+				LagMegabytes: initialize.Int64(4), PendingRestart: true,
+			},
+		})
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "running-pod"}}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  naming.ContainerDatabase,
+			State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+		}}
+
+		reconciler := &Reconciler{
+			PodExec: func(string, string, string, io.Reader, io.Writer, io.Writer, ...string) error {
+				return errors.New("boom")
+			},
+		}
+
+		before := cluster.Status.Patroni.Members
+		reconciler.reconcilePatroniMembers(ctx, cluster,
+			&observedInstances{forCluster: []*Instance{{Pods: []*corev1.Pod{pod}}}})
+		assert.DeepEqual(t, cluster.Status.Patroni.Members, before)
+	})
+}
+
+func TestReconcilePatroniPrimary(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{Recorder: recorder}
+
+	instance := func(name, role string) *Instance {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name + "-0"}}
+		pod.Labels = map[string]string{naming.LabelRole: role}
+		pod.Annotations = map[string]string{"status": `{"role":"` + role + `","timeline":4}`}
+		return &Instance{Name: name, Pods: []*corev1.Pod{pod}}
+	}
+
+	cluster := new(v1beta1.PostgresCluster)
+
+	// Nothing is recorded while there is no leader.
+	reconciler.reconcilePatroniPrimary(cluster, &observedInstances{
+		forCluster: []*Instance{instance("hippo-a", "replica")},
+	})
+	assert.Equal(t, cluster.Status.Patroni.Primary, "")
+
+	// The first primary is recorded without an event.
+	reconciler.reconcilePatroniPrimary(cluster, &observedInstances{
+		forCluster: []*Instance{instance("hippo-a", "master"), instance("hippo-b", "replica")},
+	})
+	assert.Equal(t, cluster.Status.Patroni.Primary, "hippo-a")
+	assert.Equal(t, len(recorder.Events), 0)
+
+	// The status is kept during an election.
+	reconciler.reconcilePatroniPrimary(cluster, &observedInstances{
+		forCluster: []*Instance{instance("hippo-a", "replica"), instance("hippo-b", "replica")},
+	})
+	assert.Equal(t, cluster.Status.Patroni.Primary, "hippo-a")
+	assert.Equal(t, len(recorder.Events), 0)
+
+	// A failover is recorded with an event.
+	reconciler.reconcilePatroniPrimary(cluster, &observedInstances{
+		forCluster: []*Instance{instance("hippo-a", "replica"), instance("hippo-b", "master")},
+	})
+	assert.Equal(t, cluster.Status.Patroni.Primary, "hippo-b")
+	assert.Equal(t, len(recorder.Events), 1)
+	assert.Equal(t, <-recorder.Events,
+		`Normal PrimaryChanged Primary changed from "hippo-a" to "hippo-b" on timeline 4`)
+}
+
+func TestReconcilePatroniDynamicConfigurationPaused(t *testing.T) {
+	ctx := context.Background()
+
+	var stdin string
+	reconciler := &Reconciler{
+		PodExec: func(
+			namespace, pod, container string,
+			in io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			b, _ := io.ReadAll(in)
+			stdin = string(b)
+			return nil
+		},
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "running-pod"}}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+	instances := &observedInstances{forCluster: []*Instance{{Pods: []*corev1.Pod{pod}}}}
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Default()
+	cluster.Status.Patroni.SystemIdentifier = "123"
+	cluster.Spec.Patroni.Paused = initialize.Bool(true)
+
+	assert.NilError(t, reconciler.reconcilePatroniDynamicConfiguration(
+		ctx, cluster, instances, postgres.HBAs{}, postgres.Parameters{}))
+	assert.Assert(t, strings.Contains(stdin, `"pause":true`), "got %q", stdin)
+
+	condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.PatroniPaused)
+	assert.Assert(t, condition != nil)
+	assert.Equal(t, condition.Status, metav1.ConditionTrue)
+	assert.Equal(t, condition.Reason, "Paused")
+
+	cluster.Spec.Patroni.Paused = nil
+	assert.NilError(t, reconciler.reconcilePatroniDynamicConfiguration(
+		ctx, cluster, instances, postgres.HBAs{}, postgres.Parameters{}))
+	assert.Assert(t, !strings.Contains(stdin, `"pause"`), "got %q", stdin)
+
+	condition = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.PatroniPaused)
+	assert.Assert(t, condition != nil)
+	assert.Equal(t, condition.Status, metav1.ConditionFalse)
+	assert.Equal(t, condition.Reason, "Resumed")
+}
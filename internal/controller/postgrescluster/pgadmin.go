@@ -23,6 +23,7 @@ import (
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -39,6 +40,7 @@ import (
 // reconcilePGAdmin writes the objects necessary to run a pgAdmin Pod.
 func (r *Reconciler) reconcilePGAdmin(
 	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	primaryCertificate *corev1.SecretProjection,
 ) error {
 	// NOTE: [Reconciler.reconcilePGAdminUsers] is called in [Reconciler.reconcilePostgresUsers].
 
@@ -46,7 +48,11 @@ func (r *Reconciler) reconcilePGAdmin(
 	// but it may be useful during upcoming feature enhancements. If not, we
 	// may consider removing the service return altogether and refactoring
 	// this function to only return errors.
-	_, err := r.reconcilePGAdminService(ctx, cluster)
+	service, err := r.reconcilePGAdminService(ctx, cluster)
+
+	if err == nil {
+		err = r.reconcilePGAdminIngress(ctx, cluster, service)
+	}
 
 	var configmap *corev1.ConfigMap
 	var dataVolume *corev1.PersistentVolumeClaim
@@ -58,7 +64,8 @@ func (r *Reconciler) reconcilePGAdmin(
 		dataVolume, err = r.reconcilePGAdminDataVolume(ctx, cluster)
 	}
 	if err == nil {
-		err = r.reconcilePGAdminStatefulSet(ctx, cluster, configmap, dataVolume)
+		err = r.reconcilePGAdminStatefulSet(ctx, cluster,
+			configmap, primaryCertificate, dataVolume)
 	}
 	return err
 }
@@ -177,6 +184,8 @@ func (r *Reconciler) generatePGAdminService(
 		service.Spec.Type = corev1.ServiceTypeClusterIP
 	} else {
 		service.Spec.Type = corev1.ServiceType(spec.Type)
+		service.Spec.IPFamilies = spec.IPFamilies
+		service.Spec.IPFamilyPolicy = spec.IPFamilyPolicy
 		if spec.NodePort != nil {
 			if service.Spec.Type == corev1.ServiceTypeClusterIP {
 				// The NodePort can only be set when the Service type is NodePort or
@@ -191,6 +200,8 @@ func (r *Reconciler) generatePGAdminService(
 			}
 			servicePort.NodePort = *spec.NodePort
 		}
+		setServiceExternalTraffic(spec, service)
+		setServiceRouting(spec, service)
 	}
 	service.Spec.Ports = []corev1.ServicePort{servicePort}
 
@@ -225,13 +236,104 @@ func (r *Reconciler) reconcilePGAdminService(
 	return service, err
 }
 
+// generatePGAdminIngress returns a networking/v1 Ingress that routes to the
+// pgAdmin Service. The second return value is false when an Ingress is not
+// specified.
+func (r *Reconciler) generatePGAdminIngress(
+	cluster *v1beta1.PostgresCluster, service *corev1.Service,
+) (*networkingv1.Ingress, bool, error) {
+	ingress := &networkingv1.Ingress{ObjectMeta: naming.ClusterPGAdmin(cluster)}
+	ingress.SetGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("Ingress"))
+
+	if cluster.Spec.UserInterface == nil || cluster.Spec.UserInterface.PGAdmin == nil ||
+		cluster.Spec.UserInterface.PGAdmin.Ingress == nil || service == nil {
+		return ingress, false, nil
+	}
+	spec := cluster.Spec.UserInterface.PGAdmin.Ingress
+
+	ingress.Annotations = naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		cluster.Spec.UserInterface.PGAdmin.Metadata.GetAnnotationsOrNil(),
+		spec.Metadata.GetAnnotationsOrNil())
+	ingress.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		cluster.Spec.UserInterface.PGAdmin.Metadata.GetLabelsOrNil(),
+		spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RolePGAdmin,
+		})
+
+	// Send every request for the host to the pgAdmin Service by port name so
+	// the Ingress keeps working when the Service port changes.
+	pathType := networkingv1.PathTypePrefix
+	ingress.Spec.IngressClassName = spec.IngressClassName
+	ingress.Spec.Rules = []networkingv1.IngressRule{{
+		Host: spec.Host,
+		IngressRuleValue: networkingv1.IngressRuleValue{
+			HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{{
+					Path:     "/",
+					PathType: &pathType,
+					Backend: networkingv1.IngressBackend{
+						Service: &networkingv1.IngressServiceBackend{
+							Name: service.Name,
+							Port: networkingv1.ServiceBackendPort{
+								Name: naming.PortPGAdmin,
+							},
+						},
+					},
+				}},
+			},
+		},
+	}}
+
+	if spec.TLSSecretName != "" {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{{
+			Hosts:      []string{spec.Host},
+			SecretName: spec.TLSSecretName,
+		}}
+	}
+
+	err := errors.WithStack(r.setControllerReference(cluster, ingress))
+
+	return ingress, true, err
+}
+
+// +kubebuilder:rbac:groups="networking.k8s.io",resources="ingresses",verbs={get}
+// +kubebuilder:rbac:groups="networking.k8s.io",resources="ingresses",verbs={create,delete,patch}
+
+// reconcilePGAdminIngress writes the Ingress that routes to the pgAdmin Service.
+func (r *Reconciler) reconcilePGAdminIngress(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, service *corev1.Service,
+) error {
+	ingress, specified, err := r.generatePGAdminIngress(cluster, service)
+
+	if err == nil && !specified {
+		// The Ingress is not specified; delete it if it exists. Check the
+		// client cache first using Get.
+		key := client.ObjectKeyFromObject(ingress)
+		err := errors.WithStack(r.Client.Get(ctx, key, ingress))
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, ingress))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, ingress))
+	}
+	return err
+}
+
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=create;delete;patch
 
 // reconcilePGAdminStatefulSet writes the StatefulSet that runs pgAdmin.
 func (r *Reconciler) reconcilePGAdminStatefulSet(
 	ctx context.Context, cluster *v1beta1.PostgresCluster,
-	configmap *corev1.ConfigMap, dataVolume *corev1.PersistentVolumeClaim,
+	configmap *corev1.ConfigMap, primaryCertificate *corev1.SecretProjection,
+	dataVolume *corev1.PersistentVolumeClaim,
 ) error {
 	sts := &appsv1.StatefulSet{ObjectMeta: naming.ClusterPGAdmin(cluster)}
 	sts.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("StatefulSet"))
@@ -332,7 +434,7 @@ func (r *Reconciler) reconcilePGAdminStatefulSet(
 		return err
 	}
 
-	pgadmin.Pod(cluster, configmap, &sts.Spec.Template.Spec, dataVolume)
+	pgadmin.Pod(cluster, configmap, primaryCertificate, &sts.Spec.Template.Spec, dataVolume)
 
 	// add nss_wrapper init container and add nss_wrapper env vars to the pgAdmin
 	// container
@@ -362,6 +362,129 @@ ownerReferences:
 	}
 }
 
+func TestGeneratePGAdminIngress(t *testing.T) {
+	_, cc := setupKubernetes(t)
+	require.ParallelCapacity(t, 0)
+
+	reconciler := &Reconciler{Client: cc}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "my-ns"
+	cluster.Name = "my-cluster"
+
+	service := &corev1.Service{ObjectMeta: naming.ClusterPGAdmin(cluster)}
+
+	t.Run("Unspecified", func(t *testing.T) {
+		for _, spec := range []*v1beta1.UserInterfaceSpec{
+			nil, new(v1beta1.UserInterfaceSpec),
+			{PGAdmin: new(v1beta1.PGAdminPodSpec)},
+		} {
+			cluster := cluster.DeepCopy()
+			cluster.Spec.UserInterface = spec
+
+			ingress, specified, err := reconciler.generatePGAdminIngress(cluster, service)
+			assert.NilError(t, err)
+			assert.Assert(t, !specified)
+
+			assert.Assert(t, marshalMatches(ingress.ObjectMeta, `
+creationTimestamp: null
+name: my-cluster-pgadmin
+namespace: my-ns
+			`))
+		}
+	})
+
+	cluster.Spec.Metadata = &v1beta1.Metadata{
+		Annotations: map[string]string{"a": "v1"},
+		Labels:      map[string]string{"b": "v2"},
+	}
+	cluster.Spec.UserInterface = &v1beta1.UserInterfaceSpec{
+		PGAdmin: &v1beta1.PGAdminPodSpec{
+			Ingress: &v1beta1.PGAdminIngressSpec{
+				Metadata: &v1beta1.Metadata{
+					Annotations: map[string]string{"c": "v3"},
+					Labels: map[string]string{"d": "v4",
+						"postgres-operator.crunchydata.com/cluster": "wrongName"},
+				},
+				Host: "pgadmin.example.com",
+			},
+		},
+	}
+
+	t.Run("Host", func(t *testing.T) {
+		ingress, specified, err := reconciler.generatePGAdminIngress(cluster, service)
+		assert.NilError(t, err)
+		assert.Assert(t, specified)
+
+		assert.Assert(t, marshalMatches(ingress.TypeMeta, `
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+		`))
+		assert.Assert(t, marshalMatches(ingress.ObjectMeta, `
+annotations:
+  a: v1
+  c: v3
+creationTimestamp: null
+labels:
+  b: v2
+  d: v4
+  postgres-operator.crunchydata.com/cluster: my-cluster
+  postgres-operator.crunchydata.com/role: pgadmin
+name: my-cluster-pgadmin
+namespace: my-ns
+ownerReferences:
+- apiVersion: postgres-operator.crunchydata.com/v1beta1
+  blockOwnerDeletion: true
+  controller: true
+  kind: PostgresCluster
+  name: my-cluster
+  uid: ""
+		`))
+		assert.Assert(t, marshalMatches(ingress.Spec, `
+rules:
+- host: pgadmin.example.com
+  http:
+    paths:
+    - backend:
+        service:
+          name: my-cluster-pgadmin
+          port:
+            name: pgadmin
+      path: /
+      pathType: Prefix
+		`))
+	})
+
+	t.Run("ClassAndTLS", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.UserInterface.PGAdmin.Ingress.IngressClassName = initialize.String("nginx")
+		cluster.Spec.UserInterface.PGAdmin.Ingress.TLSSecretName = "pgadmin-tls"
+
+		ingress, specified, err := reconciler.generatePGAdminIngress(cluster, service)
+		assert.NilError(t, err)
+		assert.Assert(t, specified)
+
+		assert.Assert(t, marshalMatches(ingress.Spec, `
+ingressClassName: nginx
+rules:
+- host: pgadmin.example.com
+  http:
+    paths:
+    - backend:
+        service:
+          name: my-cluster-pgadmin
+          port:
+            name: pgadmin
+      path: /
+      pathType: Prefix
+tls:
+- hosts:
+  - pgadmin.example.com
+  secretName: pgadmin-tls
+		`))
+	})
+}
+
 func TestReconcilePGAdminService(t *testing.T) {
 	ctx := context.Background()
 	_, cc := setupKubernetes(t)
@@ -464,11 +587,14 @@ func TestReconcilePGAdminStatefulSet(t *testing.T) {
 	configmap := &corev1.ConfigMap{}
 	configmap.Name = "test-cm"
 
+	certificate := &corev1.SecretProjection{}
+	certificate.Name = "test-tls"
+
 	pvc := &corev1.PersistentVolumeClaim{}
 	pvc.Name = "test-pvc"
 
 	t.Run("verify StatefulSet", func(t *testing.T) {
-		err := reconciler.reconcilePGAdminStatefulSet(ctx, cluster, configmap, pvc)
+		err := reconciler.reconcilePGAdminStatefulSet(ctx, cluster, configmap, certificate, pvc)
 		assert.NilError(t, err)
 
 		selector, err := naming.AsSelector(metav1.LabelSelector{
@@ -579,7 +705,7 @@ terminationGracePeriodSeconds: 30
 		assert.NilError(t, cc.Create(ctx, customcluster))
 		t.Cleanup(func() { assert.Check(t, cc.Delete(ctx, customcluster)) })
 
-		err := reconciler.reconcilePGAdminStatefulSet(ctx, customcluster, configmap, pvc)
+		err := reconciler.reconcilePGAdminStatefulSet(ctx, customcluster, configmap, certificate, pvc)
 		assert.NilError(t, err)
 
 		selector, err := naming.AsSelector(metav1.LabelSelector{
@@ -21,6 +21,7 @@ import (
 	"io"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,6 +32,7 @@ import (
 	rbacv1 "k8s.io/api/rbac/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
@@ -50,6 +52,7 @@ import (
 	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
 	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/walg"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
@@ -78,6 +81,18 @@ const (
 	// and in-place pgBackRest restore is in progress
 	ConditionPGBackRestRestoreProgressing = "PGBackRestoreProgressing"
 
+	// ConditionBackupsVerified is the type used in a condition to indicate whether or not the
+	// latest scheduled pgBackRest verify of every repository found its backups and WAL intact
+	ConditionBackupsVerified = "BackupsVerified"
+
+	// ConditionLastBackupSuccessful is the type used in a condition to indicate whether or not
+	// the latest finished pgBackRest backup Job succeeded
+	ConditionLastBackupSuccessful = "LastBackupSuccessful"
+
+	// ConditionStanzaUpgraded is the type used in a condition to indicate whether or not the
+	// pgBackRest stanza has been upgraded following a change to the PostgreSQL major version
+	ConditionStanzaUpgraded = "PGBackRestStanzaUpgraded"
+
 	// EventRepoHostNotFound is used to indicate that a pgBackRest repository was not
 	// found when reconciling
 	EventRepoHostNotFound = "RepoDeploymentNotFound"
@@ -94,6 +109,27 @@ const (
 	// completes successfully
 	EventStanzasCreated = "StanzasCreated"
 
+	// EventStanzasUpgraded is the event reason utilized when a pgBackRest stanza upgrade command
+	// completes successfully after the PostgreSQL major version changes
+	EventStanzasUpgraded = "StanzasUpgraded"
+
+	// EventBackupStarted is the event reason utilized when a pgBackRest backup Job starts
+	EventBackupStarted = "BackupStarted"
+
+	// EventBackupCompleted is the event reason utilized when a pgBackRest backup Job completes
+	// successfully
+	EventBackupCompleted = "BackupCompleted"
+
+	// EventBackupFailed is the event reason utilized when a pgBackRest backup Job fails
+	EventBackupFailed = "BackupFailed"
+
+	// EventRestoreCompleted is the event reason utilized when a pgBackRest restore Job completes
+	// successfully
+	EventRestoreCompleted = "RestoreCompleted"
+
+	// EventRestoreFailed is the event reason utilized when a pgBackRest restore Job fails
+	EventRestoreFailed = "RestoreFailed"
+
 	// EventUnableToCreatePGBackRestCronJob is the event reason utilized when a pgBackRest backup
 	// CronJob fails to create successfully
 	EventUnableToCreatePGBackRestCronJob = "UnableToCreatePGBackRestCronJob"
@@ -111,6 +147,9 @@ const (
 	incremental  = "incr"
 )
 
+// verify is the schedule type of CronJobs that run pgBackRest verify
+const verify = "verify"
+
 // regexRepoIndex is the regex used to obtain the repo index from a pgBackRest repo name
 var regexRepoIndex = regexp.MustCompile(`\d+`)
 
@@ -120,6 +159,9 @@ type RepoResources struct {
 	cronjobs                []*batchv1.CronJob
 	manualBackupJobs        []*batchv1.Job
 	replicaCreateBackupJobs []*batchv1.Job
+	scheduledBackupJobs     []*batchv1.Job
+	restoreDrillJobs        []*batchv1.Job
+	verifyJobs              []*batchv1.Job
 	hosts                   []*appsv1.StatefulSet
 	pvcs                    []*corev1.PersistentVolumeClaim
 }
@@ -304,6 +346,11 @@ func (r *Reconciler) cleanupRepoResources(ctx context.Context,
 				}
 			}
 		case hasLabel(naming.LabelPGBackRestCronJob):
+			// Scheduled backups are not reconciled while WAL-G archives WAL, so
+			// delete their CronJobs.
+			if walg.Enabled(postgresCluster) {
+				break
+			}
 			for _, repo := range postgresCluster.Spec.Backups.PGBackRest.Repos {
 				if repo.Name == owned.GetLabels()[naming.LabelPGBackRestRepo] {
 					if backupScheduleFound(repo,
@@ -314,6 +361,14 @@ func (r *Reconciler) cleanupRepoResources(ctx context.Context,
 					break
 				}
 			}
+		case hasLabel(naming.LabelPGBackRestRestoreDrill):
+			// Keep the restore drill CronJob and its Jobs while a drill is defined
+			// and pgBackRest archives WAL.
+			if postgresCluster.Spec.Backups.PGBackRest.RestoreDrill != nil &&
+				!walg.Enabled(postgresCluster) {
+				ownedNoDelete = append(ownedNoDelete, owned)
+				delete = false
+			}
 		case hasLabel(naming.LabelPGBackRestRestore):
 			// When a cluster is prepared for restore, the system identifier is removed from status
 			// and the cluster is therefore no longer bootstrapped.  Only once the restore Job is
@@ -350,6 +405,8 @@ func backupScheduleFound(repo v1beta1.PGBackRestRepo, backupType string) bool {
 			return repo.BackupSchedules.Differential != nil
 		case incremental:
 			return repo.BackupSchedules.Incremental != nil
+		case verify:
+			return repo.BackupSchedules.Verify != nil
 		default:
 			return false
 		}
@@ -369,8 +426,21 @@ func unstructuredToRepoResources(kind string, repoResources *RepoResources,
 			FromUnstructured(uList.UnstructuredContent(), &jobList); err != nil {
 			return errors.WithStack(err)
 		}
-		// we care about replica create backup jobs and manual backup jobs
+		// we care about replica create backup jobs, manual backup jobs, restore drills, and
+		// verify jobs
 		for i, job := range jobList.Items {
+			if _, drill := job.GetLabels()[naming.LabelPGBackRestRestoreDrill]; drill {
+				repoResources.restoreDrillJobs =
+					append(repoResources.restoreDrillJobs, &jobList.Items[i])
+			}
+			switch job.GetLabels()[naming.LabelPGBackRestCronJob] {
+			case full, differential, incremental:
+				repoResources.scheduledBackupJobs =
+					append(repoResources.scheduledBackupJobs, &jobList.Items[i])
+			case verify:
+				repoResources.verifyJobs =
+					append(repoResources.verifyJobs, &jobList.Items[i])
+			}
 			switch job.GetLabels()[naming.LabelPGBackRestBackup] {
 			case string(naming.BackupReplicaCreate):
 				repoResources.replicaCreateBackupJobs =
@@ -470,6 +540,23 @@ func (r *Reconciler) setScheduledJobStatus(ctx context.Context,
 	if postgresCluster.Status.PGBackRest == nil {
 		postgresCluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{}
 	}
+
+	// Announce each scheduled backup once, when its Job is first seen to have started.
+	for _, sbs := range scheduledStatus {
+		if sbs.StartTime == nil || sbs.Type == verify {
+			continue
+		}
+		var seen bool
+		for _, previous := range postgresCluster.Status.PGBackRest.ScheduledBackups {
+			seen = seen || (previous.CronJobName == sbs.CronJobName &&
+				previous.StartTime != nil && previous.StartTime.Equal(sbs.StartTime))
+		}
+		if !seen {
+			r.Recorder.Eventf(postgresCluster, corev1.EventTypeNormal, EventBackupStarted,
+				"Scheduled %s backup to %s started", sbs.Type, sbs.RepoName)
+		}
+	}
+
 	postgresCluster.Status.PGBackRest.ScheduledBackups = scheduledStatus
 }
 
@@ -666,6 +753,29 @@ func generateBackupJobSpecIntent(postgresCluster *v1beta1.PostgresCluster,
 	repo v1beta1.PGBackRestRepo, serviceAccountName string,
 	labels, annotations map[string]string, opts ...string) (*batchv1.JobSpec, error) {
 
+	// The repository host knows every instance, so pgBackRest can find a
+	// replica there. Backups of other repositories run on the primary which
+	// knows only itself.
+	if repo.BackupFromStandby && repo.Volume != nil {
+		opts = append([]string{"--backup-standby"}, opts...)
+	}
+
+	jobSpec, err := generateCommandJobSpecIntent(postgresCluster, repo, "backup",
+		serviceAccountName, labels, annotations, opts...)
+
+	if err == nil && postgresCluster.Spec.Backups.PGBackRest.Jobs != nil {
+		jobSpec.TTLSecondsAfterFinished =
+			postgresCluster.Spec.Backups.PGBackRest.Jobs.TTLSecondsAfterFinished
+	}
+	return jobSpec, err
+}
+
+// generateCommandJobSpecIntent generates a JobSpec for a Job that runs the pgBackRest command
+// against repo in the pod that has access to it
+func generateCommandJobSpecIntent(postgresCluster *v1beta1.PostgresCluster,
+	repo v1beta1.PGBackRestRepo, command, serviceAccountName string,
+	labels, annotations map[string]string, opts ...string) (*batchv1.JobSpec, error) {
+
 	selector, containerName, err := getPGBackRestExecSelector(postgresCluster, repo)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -681,7 +791,7 @@ func generateBackupJobSpecIntent(postgresCluster *v1beta1.PostgresCluster,
 	container := corev1.Container{
 		Command: []string{"/opt/crunchy/bin/pgbackrest"},
 		Env: []corev1.EnvVar{
-			{Name: "COMMAND", Value: "backup"},
+			{Name: "COMMAND", Value: command},
 			{Name: "COMMAND_OPTS", Value: strings.Join(cmdOpts, " ")},
 			{Name: "COMPARE_HASH", Value: "true"},
 			{Name: "CONTAINER", Value: containerName},
@@ -698,6 +808,11 @@ func generateBackupJobSpecIntent(postgresCluster *v1beta1.PostgresCluster,
 		container.Resources = postgresCluster.Spec.Backups.PGBackRest.Jobs.Resources
 	}
 
+	// Log timestamps in the same time zone as PostgreSQL and backup schedules.
+	if tz := postgresCluster.Spec.Config.Timezone; tz != "" {
+		container.Env = append(container.Env, corev1.EnvVar{Name: "TZ", Value: tz})
+	}
+
 	jobSpec := &batchv1.JobSpec{
 		Template: corev1.PodTemplateSpec{
 			ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: annotations},
@@ -746,45 +861,57 @@ func generateBackupJobSpecIntent(postgresCluster *v1beta1.PostgresCluster,
 	return jobSpec, nil
 }
 
+// +kubebuilder:rbac:groups="",resources=endpoints,verbs=get
+
+// observePatroniDCS returns the Endpoints or ConfigMaps that Patroni created
+// for cluster (i.e. DCS, leader and failover objects) and that currently exist.
+func (r *Reconciler) observePatroniDCS(ctx context.Context,
+	cluster *v1beta1.PostgresCluster) ([]client.Object, error) {
+
+	// lookup the various patroni DCS objects
+	leaderEP, dcsEP, failoverEP := &corev1.Endpoints{}, &corev1.Endpoints{}, &corev1.Endpoints{}
+	leaderEP.ObjectMeta = naming.PatroniLeaderEndpoints(cluster)
+	dcsEP.ObjectMeta = naming.PatroniDistributedConfiguration(cluster)
+	failoverEP.ObjectMeta = naming.PatroniTrigger(cluster)
+	dcsObjects := []client.Object{leaderEP, dcsEP, failoverEP}
+
+	if patroni.UseConfigMaps(cluster) {
+		leaderCM, dcsCM, failoverCM := &corev1.ConfigMap{}, &corev1.ConfigMap{}, &corev1.ConfigMap{}
+		leaderCM.ObjectMeta = naming.PatroniLeaderConfigMap(cluster)
+		dcsCM.ObjectMeta = naming.PatroniDistributedConfiguration(cluster)
+		failoverCM.ObjectMeta = naming.PatroniTrigger(cluster)
+		dcsObjects = []client.Object{leaderCM, dcsCM, failoverCM}
+	}
+
+	currentEndpoints := []client.Object{}
+	for _, object := range dcsObjects {
+		if err := r.Client.Get(ctx, client.ObjectKeyFromObject(object), object); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, errors.WithStack(err)
+			}
+		} else {
+			currentEndpoints = append(currentEndpoints, object)
+		}
+	}
+
+	return currentEndpoints, nil
+}
+
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=list;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=list;delete
-// +kubebuilder:rbac:groups="",resources=endpoints,verbs=get
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=list
 
 // observeRestoreEnv observes the current Kubernetes environment to obtain any resources applicable
 // to performing pgBackRest restores (e.g. when initializing a new cluster using an existing
 // pgBackRest backup, or when restoring in-place).  This includes finding any existing Endpoints
-// created by Patroni (i.e. DCS, leader and failover Endpoints), while then also finding any existing
-// restore Jobs and then updating pgBackRest restore status accordingly.
+// or ConfigMaps created by Patroni (i.e. DCS, leader and failover objects), while then also finding
+// any existing restore Jobs and then updating pgBackRest restore status accordingly.
 func (r *Reconciler) observeRestoreEnv(ctx context.Context,
-	cluster *v1beta1.PostgresCluster) ([]corev1.Endpoints, *batchv1.Job, error) {
+	cluster *v1beta1.PostgresCluster) ([]client.Object, *batchv1.Job, error) {
 
-	// lookup the various patroni endpoints
-	leaderEP, dcsEP, failoverEP := corev1.Endpoints{}, corev1.Endpoints{}, corev1.Endpoints{}
-	currentEndpoints := []corev1.Endpoints{}
-	if err := r.Client.Get(ctx, naming.AsObjectKey(naming.PatroniLeaderEndpoints(cluster)),
-		&leaderEP); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return nil, nil, errors.WithStack(err)
-		}
-	} else {
-		currentEndpoints = append(currentEndpoints, leaderEP)
-	}
-	if err := r.Client.Get(ctx, naming.AsObjectKey(naming.PatroniDistributedConfiguration(cluster)),
-		&dcsEP); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return nil, nil, errors.WithStack(err)
-		}
-	} else {
-		currentEndpoints = append(currentEndpoints, dcsEP)
-	}
-	if err := r.Client.Get(ctx, naming.AsObjectKey(naming.PatroniTrigger(cluster)),
-		&failoverEP); err != nil {
-		if !apierrors.IsNotFound(err) {
-			return nil, nil, errors.WithStack(err)
-		}
-	} else {
-		currentEndpoints = append(currentEndpoints, failoverEP)
+	currentEndpoints, err := r.observePatroniDCS(ctx, cluster)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	restoreJobs := &batchv1.JobList{}
@@ -819,6 +946,18 @@ func (r *Reconciler) observeRestoreEnv(ctx context.Context,
 			}
 		}
 
+		// Announce the outcome of the restore once, when the condition below first reflects it.
+		previous := meta.FindStatusCondition(cluster.Status.Conditions,
+			ConditionPostgresDataInitialized)
+		if completed && (previous == nil || previous.Reason != "PGBackRestRestoreComplete") {
+			r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventRestoreCompleted,
+				"pgBackRest restore Job %q completed successfully", restoreJob.Name)
+		}
+		if failed && (previous == nil || previous.Reason != "PGBackRestRestoreFailed") {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventRestoreFailed,
+				"pgBackRest restore Job %q failed", restoreJob.Name)
+		}
+
 		// update the data source initialized condition if the Job has finished running, and is
 		// therefore in a completed or failed
 		if completed {
@@ -874,17 +1013,18 @@ func (r *Reconciler) observeRestoreEnv(ctx context.Context,
 }
 
 // +kubebuilder:rbac:groups="",resources=endpoints,verbs=delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=delete
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=delete
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=delete
 
 // prepareForRestore is responsible for reconciling an in place restore for the PostgresCluster.
 // This includes setting a "PreparingForRestore" condition, and then removing all existing
-// instance runners, as well as any Endpoints created by Patroni.  And once the cluster is no
+// instance runners, as well as any Endpoints or ConfigMaps created by Patroni.  And once the cluster is no
 // longer running, the "PostgresDataInitialized" condition is removed, which will cause the
 // cluster to re-bootstrap using a restored data directory.
 func (r *Reconciler) prepareForRestore(ctx context.Context,
 	cluster *v1beta1.PostgresCluster, observed *observedInstances,
-	currentEndpoints []corev1.Endpoints, restoreJob *batchv1.Job, restoreID string) error {
+	currentEndpoints []client.Object, restoreJob *batchv1.Job, restoreID string) error {
 
 	setPreparingClusterCondition := func(resource string) {
 		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
@@ -982,13 +1122,16 @@ func (r *Reconciler) prepareForRestore(ctx context.Context,
 		// are no longer valid
 		cluster.Status.Proxy.PGBouncer.PostgreSQLRevision = ""
 		cluster.Status.Monitoring.ExporterConfiguration = ""
+		// the backup may predate databases and extensions in the spec, such as pgvector, so
+		// create them again once the cluster is restored
+		cluster.Status.DatabaseRevision = ""
 		return nil
 	}
 
 	setPreparingClusterCondition("removing DCS")
-	// delete any Endpoints
+	// delete any Endpoints or ConfigMaps
 	for i := range currentEndpoints {
-		if err := r.Client.Delete(ctx, &currentEndpoints[i]); client.IgnoreNotFound(err) != nil {
+		if err := r.Client.Delete(ctx, currentEndpoints[i]); client.IgnoreNotFound(err) != nil {
 			return errors.WithStack(err)
 		}
 	}
@@ -996,6 +1139,64 @@ func (r *Reconciler) prepareForRestore(ctx context.Context,
 	return nil
 }
 
+// restoreTargetOptions returns the pgBackRest restore options for the typed
+// recovery target of dataSource. When those fields are invalid, it returns a
+// message explaining why.
+func restoreTargetOptions(dataSource *v1beta1.PostgresClusterDataSource) ([]string, string) {
+	// https://www.gnu.org/software/bash/manual/html_node/Quoting.html
+	quote := func(s string) string { return `'` + strings.ReplaceAll(s, `'`, `'"'"'`) + `'` }
+
+	kind := dataSource.TargetType
+	if kind == "" && dataSource.TargetTime != nil {
+		kind = "time"
+	}
+
+	switch {
+	case kind == "":
+		if dataSource.Target != "" {
+			return nil, "The 'target' field requires 'targetType'"
+		}
+		return nil, ""
+
+	case kind == "immediate":
+		if dataSource.Target != "" || dataSource.TargetTime != nil {
+			return nil, "The 'immediate' target type takes no 'target' or 'targetTime'"
+		}
+		return []string{"--type=immediate"}, ""
+
+	case kind == "time":
+		if dataSource.TargetTime == nil || dataSource.Target != "" {
+			return nil, "The 'time' target type requires 'targetTime' and no 'target'"
+		}
+		// pgBackRest passes this to PostgreSQL as "recovery_target_time".
+		// - https://www.postgresql.org/docs/current/datatype-datetime.html
+		return []string{"--type=time", "--target=" +
+			quote(dataSource.TargetTime.UTC().Format("2006-01-02 15:04:05+00"))}, ""
+
+	default:
+		if dataSource.Target == "" || dataSource.TargetTime != nil {
+			return nil, fmt.Sprintf(
+				"The '%s' target type requires 'target' and no 'targetTime'", kind)
+		}
+		return []string{"--type=" + kind, "--target=" + quote(dataSource.Target)}, ""
+	}
+}
+
+// hasRestoreOption returns true when opt, which may hold more than one option,
+// sets any of names. Names must match exactly, so "--target" does not match
+// "--target-timeline".
+func hasRestoreOption(opt string, names ...string) bool {
+	for _, field := range strings.Fields(opt) {
+		name, _, _ := strings.Cut(field, "=")
+		for _, n := range names {
+			if name == n {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=patch
 
 // reconcileRestoreJob is responsible for reconciling a Job that performs a pgBackRest restore in
@@ -1003,12 +1204,19 @@ func (r *Reconciler) prepareForRestore(ctx context.Context,
 func (r *Reconciler) reconcileRestoreJob(ctx context.Context,
 	cluster *v1beta1.PostgresCluster, sourceCluster *v1beta1.PostgresCluster,
 	pgdataVolume, pgwalVolume *corev1.PersistentVolumeClaim,
+	tablespaceVolumes []*corev1.PersistentVolumeClaim,
 	dataSource *v1beta1.PostgresClusterDataSource,
 	instanceName, instanceSetName, configHash, stanzaName string) error {
 
 	repoName := dataSource.RepoName
 	options := dataSource.Options
 
+	target, msg := restoreTargetOptions(dataSource)
+	if msg != "" {
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "InvalidDataSource", msg)
+		return nil
+	}
+
 	// ensure options are properly set
 	// TODO (andrewlecuyer): move validation logic to a webhook
 	for _, opt := range options {
@@ -1030,6 +1238,9 @@ func (r *Reconciler) reconcileRestoreJob(ctx context.Context,
 		case strings.Contains(opt, "--link-map"):
 			msg = "Option '--link-map' is not allowed: the operator will automatically set this " +
 				"option "
+		case len(target) > 0 && hasRestoreOption(opt, "--type", "--target"):
+			msg = "Options '--type' and '--target' are not allowed with the 'targetType' " +
+				"and 'targetTime' fields"
 		}
 		if msg != "" {
 			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidDataSource", msg, repoName)
@@ -1040,7 +1251,8 @@ func (r *Reconciler) reconcileRestoreJob(ctx context.Context,
 	pgdata := postgres.DataDirectory(cluster)
 	// combine options provided by user in the spec with those populated by the operator for a
 	// successful restore
-	opts := append(options, []string{
+	opts := append(append([]string{}, options...), target...)
+	opts = append(opts, []string{
 		"--stanza=" + stanzaName,
 		"--pg1-path=" + pgdata,
 		"--repo=" + regexRepoIndex.FindString(repoName)}...)
@@ -1048,7 +1260,7 @@ func (r *Reconciler) reconcileRestoreJob(ctx context.Context,
 	var deltaOptFound, foundTarget bool
 	for _, opt := range opts {
 		switch {
-		case strings.Contains(opt, "--target"):
+		case strings.Contains(opt, "--target"), strings.Contains(opt, "--type=immediate"):
 			foundTarget = true
 		case strings.Contains(opt, "--delta"):
 			deltaOptFound = true
@@ -1085,6 +1297,29 @@ func (r *Reconciler) reconcileRestoreJob(ctx context.Context,
 	// to do any escaping or use eval.
 	cmd := pgbackrest.RestoreCommand(pgdata, strings.Join(opts, " "))
 
+	// Restore the latest WAL-G backup when the source cluster archives with
+	// WAL-G. Its environment locates the archive, so it must be in this
+	// namespace. The pgBackRest options above do not apply.
+	var walgEnv []corev1.EnvVar
+	if sourceCluster != nil && walg.Enabled(sourceCluster) {
+		var msg string
+		switch {
+		case sourceCluster.Namespace != cluster.Namespace:
+			msg = fmt.Sprintf("PostgresCluster %q archives with WAL-G and must be in "+
+				"the same namespace to restore from it", sourceCluster.Name)
+		case len(options) > 0:
+			msg = "The 'options' field is not allowed when restoring from WAL-G: " +
+				"use the 'targetType', 'target', and 'targetTime' fields instead"
+		}
+		if msg != "" {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "InvalidDataSource", msg)
+			return nil
+		}
+
+		cmd = walg.RestoreCommand(pgdata, "LATEST", walg.RestoreTarget(dataSource))
+		walgEnv = sourceCluster.Spec.Backups.WALG.Env
+	}
+
 	// create the volume resources required for the postgres data directory
 	dataVolumeMount := postgres.DataVolumeMount()
 	dataVolume := corev1.Volume{
@@ -1112,6 +1347,23 @@ func (r *Reconciler) reconcileRestoreJob(ctx context.Context,
 		volumeMounts = append(volumeMounts, walVolumeMount)
 	}
 
+	// pgBackRest restores tablespaces through their links in PGDATA, so
+	// mount them where the instance does.
+	for i := range tablespaceVolumes {
+		tablespaceVolumeMount := postgres.TablespaceVolumeMount(
+			tablespaceVolumes[i].Labels[naming.LabelTablespace])
+		tablespaceVolume := corev1.Volume{
+			Name: tablespaceVolumeMount.Name,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: tablespaceVolumes[i].GetName(),
+				},
+			},
+		}
+		volumes = append(volumes, tablespaceVolume)
+		volumeMounts = append(volumeMounts, tablespaceVolumeMount)
+	}
+
 	restoreJob := &batchv1.Job{}
 	if err := r.generateRestoreJobIntent(cluster, configHash, instanceName, cmd,
 		volumeMounts, volumes, dataSource, restoreJob); err != nil {
@@ -1121,6 +1373,12 @@ func (r *Reconciler) reconcileRestoreJob(ctx context.Context,
 	// add pgBackRest configs to template
 	pgbackrest.AddConfigToRestorePod(cluster, sourceCluster, &restoreJob.Spec.Template.Spec)
 
+	// add the WAL-G environment of the source cluster to the restore container
+	if len(walgEnv) > 0 {
+		restoreJob.Spec.Template.Spec.Containers[0].Env = append(
+			restoreJob.Spec.Template.Spec.Containers[0].Env, walgEnv...)
+	}
+
 	// add nss_wrapper init container and add nss_wrapper env vars to the pgbackrest restore
 	// container
 	addNSSWrapper(
@@ -1223,6 +1481,7 @@ func (r *Reconciler) reconcilePGBackRest(ctx context.Context,
 
 	// add some additional context about what component is being reconciled
 	log := logging.FromContext(ctx).WithValues("reconciler", "pgBackRest")
+	defer timeReconciler("pgBackRest").ObserveDuration()
 
 	// if nil, create the pgBackRest status that will be updated when reconciling various
 	// pgBackRest resources
@@ -1274,14 +1533,6 @@ func (r *Reconciler) reconcilePGBackRest(ctx context.Context,
 		return result, nil
 	}
 
-	// reconcile all pgbackrest repository repos
-	replicaCreateRepo, err := r.reconcileRepos(ctx, postgresCluster, configHashes, repoResources)
-	if err != nil {
-		log.Error(err, "unable to reconcile pgBackRest repo host")
-		result = updateReconcileResult(result, reconcile.Result{Requeue: true})
-		return result, nil
-	}
-
 	// gather instance names and reconcile all pgbackrest configuration and secrets
 	instanceNames := []string{}
 	for _, instance := range instances.forCluster {
@@ -1296,6 +1547,22 @@ func (r *Reconciler) reconcilePGBackRest(ctx context.Context,
 		result = updateReconcileResult(result, reconcile.Result{Requeue: true})
 	}
 
+	// WAL-G archives WAL in place of pgBackRest. Its backups are taken outside
+	// the operator, so there are no repository volumes, stanzas, nor backups to
+	// manage. PostgreSQL instances still mount the configuration above, and any
+	// existing repository volumes are kept.
+	if walg.Enabled(postgresCluster) {
+		return result, nil
+	}
+
+	// reconcile all pgbackrest repository repos
+	replicaCreateRepo, err := r.reconcileRepos(ctx, postgresCluster, configHashes, repoResources)
+	if err != nil {
+		log.Error(err, "unable to reconcile pgBackRest repo host")
+		result = updateReconcileResult(result, reconcile.Result{Requeue: true})
+		return result, nil
+	}
+
 	// reconcile the RBAC required to run pgBackRest Jobs (e.g. for backups)
 	sa, err := r.reconcilePGBackRestRBAC(ctx, postgresCluster)
 	if err != nil {
@@ -1340,6 +1607,16 @@ func (r *Reconciler) reconcilePGBackRest(ctx context.Context,
 		result = updateReconcileResult(result, reconcile.Result{RequeueAfter: 10 * time.Second})
 	}
 
+	// report on the latest pgBackRest verify of each repository
+	setVerifyStatus(postgresCluster, repoResources.verifyJobs)
+
+	// Reconcile the scheduled restore drill and report on its latest Job.
+	if err := r.reconcileRestoreDrill(ctx, postgresCluster,
+		repoResources.restoreDrillJobs); err != nil {
+		log.Error(err, "unable to reconcile restore drill")
+		result = updateReconcileResult(result, reconcile.Result{Requeue: true})
+	}
+
 	// Reconcile the initial backup that is needed to enable replica creation using pgBackRest.
 	// This is done once stanza creation is successful
 	if err := r.reconcileReplicaCreateBackup(ctx, postgresCluster, instances,
@@ -1356,6 +1633,16 @@ func (r *Reconciler) reconcilePGBackRest(ctx context.Context,
 		result = updateReconcileResult(result, reconcile.Result{Requeue: true})
 	}
 
+	// Report on the latest backup Job and the backups it left in each repository
+	backupJobs := append(append(append([]*batchv1.Job{},
+		repoResources.replicaCreateBackupJobs...),
+		repoResources.manualBackupJobs...),
+		repoResources.scheduledBackupJobs...)
+	if err := r.reconcileBackupInfo(ctx, postgresCluster, instances, backupJobs); err != nil {
+		log.Error(err, "unable to reconcile backup information")
+		result = updateReconcileResult(result, reconcile.Result{RequeueAfter: 10 * time.Second})
+	}
+
 	return result, nil
 }
 
@@ -1504,9 +1791,13 @@ func (r *Reconciler) reconcilePostgresClusterDataSource(ctx context.Context,
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	tablespaces, err := r.reconcileTablespaceVolumes(ctx, cluster, instanceSet, fakeSTS, clusterVolumes)
+	if err != nil {
+		return errors.WithStack(err)
+	}
 
 	// reconcile the pgBackRest restore Job to populate the cluster's data directory
-	if err := r.reconcileRestoreJob(ctx, cluster, sourceCluster, pgdata, pgwal,
+	if err := r.reconcileRestoreJob(ctx, cluster, sourceCluster, pgdata, pgwal, tablespaces,
 		dataSource, instanceName, instanceSetName, configHash, pgbackrest.DefaultStanzaName); err != nil {
 		return errors.WithStack(err)
 	}
@@ -1594,6 +1885,10 @@ func (r *Reconciler) reconcileCloudBasedDataSource(ctx context.Context,
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	tablespaces, err := r.reconcileTablespaceVolumes(ctx, cluster, instanceSet, fakeSTS, clusterVolumes)
+	if err != nil {
+		return errors.WithStack(err)
+	}
 
 	// The `reconcileRestoreJob` was originally designed to take a PostgresClusterDataSource
 	// and rather than reconfigure that func's signature, we translate the PGBackRestDataSource
@@ -1608,7 +1903,7 @@ func (r *Reconciler) reconcileCloudBasedDataSource(ctx context.Context,
 
 	// reconcile the pgBackRest restore Job to populate the cluster's data directory
 	// Note that the 'source cluster' is nil as this is not used by this restore type.
-	if err := r.reconcileRestoreJob(ctx, cluster, nil, pgdata, pgwal, tmpDataSource,
+	if err := r.reconcileRestoreJob(ctx, cluster, nil, pgdata, pgwal, tablespaces, tmpDataSource,
 		instanceName, instanceSetName, configHash, dataSource.Stanza); err != nil {
 		return errors.WithStack(err)
 	}
@@ -2256,6 +2551,11 @@ func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 		return errors.WithStack(err)
 	}
 
+	if currentBackupJob == nil {
+		r.Recorder.Eventf(postgresCluster, corev1.EventTypeNormal, EventBackupStarted,
+			"Manual backup %q to %s started", manualAnnotation, repoName)
+	}
+
 	return nil
 }
 
@@ -2431,6 +2731,11 @@ func (r *Reconciler) reconcileReplicaCreateBackup(ctx context.Context,
 		return errors.WithStack(err)
 	}
 
+	if job == nil {
+		r.Recorder.Eventf(postgresCluster, corev1.EventTypeNormal, EventBackupStarted,
+			"Replica creation backup to %s started", replicaCreateRepo.Name)
+	}
+
 	return nil
 }
 
@@ -2550,15 +2855,40 @@ func (r *Reconciler) reconcileStanzaCreate(ctx context.Context,
 		}
 	}
 
+	// Stanzas that were created before the operator recorded their PostgreSQL version are
+	// assumed to match the current version.
+	stanzaVersion := postgresCluster.Status.PGBackRest.StanzaPostgresVersion
+	if stanzasCreated && stanzaVersion == 0 {
+		stanzaVersion = postgresCluster.Spec.PostgresVersion
+		postgresCluster.Status.PGBackRest.StanzaPostgresVersion = stanzaVersion
+	}
+
+	// The stanza must be upgraded when the PostgreSQL major version changes. Until then,
+	// pgBackRest refuses to archive WAL from the new version, so archiving stays blocked
+	// without any change to "archive_command".
+	// - https://pgbackrest.org/command.html#command-stanza-upgrade
+	upgrade := stanzaVersion != 0 && stanzaVersion != postgresCluster.Spec.PostgresVersion
+	if upgrade {
+		meta.SetStatusCondition(&postgresCluster.Status.Conditions, metav1.Condition{
+			ObservedGeneration: postgresCluster.GetGeneration(),
+			Type:               ConditionStanzaUpgraded,
+			Status:             metav1.ConditionFalse,
+			Reason:             "StanzaUpgradePending",
+			Message: fmt.Sprintf("pgBackRest stanza upgrade from PostgreSQL %d to %d is pending; "+
+				"WAL is not archived until it completes",
+				stanzaVersion, postgresCluster.Spec.PostgresVersion),
+		})
+	}
+
 	// returns if the cluster is not yet writable, or if it has been initialized and
-	// all stanzas have already been created successfully
+	// all stanzas have already been created (and upgraded) successfully
 	//
 	// TODO (andrewlecuyer): Since reconciliation doesn't currently occur when a leader is elected,
 	// the operator may not get another chance to create the stanza if a writable instance is not
 	// detected, and it then returns without requeing.  To ensure this doesn't occur and that the
 	// operator always has a chance to reconcile when an instance becomes writable, we should watch
 	// Pods in the cluster for leader election events, and trigger reconciles accordingly.
-	if !clusterWritable || stanzasCreated {
+	if !clusterWritable || (stanzasCreated && !upgrade) {
 		return false, nil
 	}
 
@@ -2569,7 +2899,8 @@ func (r *Reconciler) reconcileStanzaCreate(ctx context.Context,
 			naming.ContainerDatabase, stdin, stdout, stderr, command...)
 	}
 
-	// Always attempt to create pgBackRest stanza first
+	// Always attempt to create pgBackRest stanza first. This upgrades any stanza that was
+	// created for a different PostgreSQL version.
 	configHashMismatch, err := pgbackrest.Executor(exec).StanzaCreateOrUpgrade(ctx, configHash,
 		false)
 	if err != nil {
@@ -2577,6 +2908,16 @@ func (r *Reconciler) reconcileStanzaCreate(ctx context.Context,
 		r.Recorder.Event(postgresCluster, corev1.EventTypeWarning, EventUnableToCreateStanzas,
 			err.Error())
 
+		if upgrade {
+			meta.SetStatusCondition(&postgresCluster.Status.Conditions, metav1.Condition{
+				ObservedGeneration: postgresCluster.GetGeneration(),
+				Type:               ConditionStanzaUpgraded,
+				Status:             metav1.ConditionFalse,
+				Reason:             "StanzaUpgradeFailed",
+				Message:            "pgBackRest stanza upgrade failed: " + err.Error(),
+			})
+		}
+
 		return false, errors.WithStack(err)
 	}
 	// Don't record event or return an error if configHashMismatch is true, since this just means
@@ -2588,8 +2929,24 @@ func (r *Reconciler) reconcileStanzaCreate(ctx context.Context,
 	}
 
 	// record an event indicating successful stanza creation
-	r.Recorder.Event(postgresCluster, corev1.EventTypeNormal, EventStanzasCreated,
-		"pgBackRest stanza creation completed successfully")
+	if upgrade {
+		r.Recorder.Eventf(postgresCluster, corev1.EventTypeNormal, EventStanzasUpgraded,
+			"pgBackRest stanza upgrade to PostgreSQL %d completed successfully",
+			postgresCluster.Spec.PostgresVersion)
+
+		meta.SetStatusCondition(&postgresCluster.Status.Conditions, metav1.Condition{
+			ObservedGeneration: postgresCluster.GetGeneration(),
+			Type:               ConditionStanzaUpgraded,
+			Status:             metav1.ConditionTrue,
+			Reason:             "StanzaUpgraded",
+			Message: fmt.Sprintf("pgBackRest stanza upgraded to PostgreSQL %d",
+				postgresCluster.Spec.PostgresVersion),
+		})
+	} else {
+		r.Recorder.Event(postgresCluster, corev1.EventTypeNormal, EventStanzasCreated,
+			"pgBackRest stanza creation completed successfully")
+	}
+	postgresCluster.Status.PGBackRest.StanzaPostgresVersion = postgresCluster.Spec.PostgresVersion
 
 	// if no errors then stanza(s) created successfully
 	for i := range postgresCluster.Status.PGBackRest.Repos {
@@ -2777,6 +3134,13 @@ func (r *Reconciler) reconcileScheduledBackups(
 					requeue = true
 				}
 			}
+			if repo.BackupSchedules.Verify != nil {
+				if err := r.reconcilePGBackRestCronJob(ctx, cluster, repo,
+					verify, repo.BackupSchedules.Verify, sa, cronjobs); err != nil {
+					log.Error(err, "unable to reconcile verify for "+repo.Name)
+					requeue = true
+				}
+			}
 		}
 	}
 	return requeue
@@ -2785,7 +3149,7 @@ func (r *Reconciler) reconcileScheduledBackups(
 // +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=create;patch
 
 // reconcilePGBackRestCronJob creates the CronJob for the given repo, pgBackRest
-// backup type and schedule
+// backup type (or verify) and schedule
 func (r *Reconciler) reconcilePGBackRestCronJob(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, repo v1beta1.PGBackRestRepo,
 	backupType string, schedule *string, serviceAccount *corev1.ServiceAccount,
@@ -2862,11 +3226,18 @@ func (r *Reconciler) reconcilePGBackRestCronJob(
 		return nil
 	}
 
-	// set backup type (i.e. "full", "diff", "incr")
-	backupOpts := []string{"--type=" + backupType}
+	var jobSpec *batchv1.JobSpec
+	var err error
+	if backupType == verify {
+		jobSpec, err = generateCommandJobSpecIntent(cluster, repo, verify,
+			serviceAccount.GetName(), labels, annotations)
+	} else {
+		// set backup type (i.e. "full", "diff", "incr")
+		backupOpts := []string{"--type=" + backupType}
 
-	jobSpec, err := generateBackupJobSpecIntent(cluster, repo,
-		serviceAccount.GetName(), labels, annotations, backupOpts...)
+		jobSpec, err = generateBackupJobSpecIntent(cluster, repo,
+			serviceAccount.GetName(), labels, annotations, backupOpts...)
+	}
 	if err != nil {
 		return errors.WithStack(err)
 	}
@@ -2893,6 +3264,13 @@ func (r *Reconciler) reconcilePGBackRestCronJob(
 		},
 	}
 
+	// Evaluate the schedule in the time zone of the cluster. Kubernetes ignores
+	// this field unless the "CronJobTimeZone" feature gate is enabled.
+	// - https://docs.k8s.io/concepts/workloads/controllers/cron-jobs/#time-zones
+	if tz := cluster.Spec.Config.Timezone; tz != "" {
+		pgBackRestCronJob.Spec.TimeZone = &tz
+	}
+
 	// Set the image pull secrets, if any exist.
 	// This is set here rather than using the service account due to the lack
 	// of propagation to existing pods when the CRD is updated:
@@ -2915,3 +3293,237 @@ func (r *Reconciler) reconcilePGBackRestCronJob(
 	}
 	return err
 }
+
+// setVerifyStatus records when the scheduled pgBackRest verify of each repository last succeeded
+// and sets ConditionBackupsVerified according to the latest verify Job of each repository that
+// finished.
+func setVerifyStatus(cluster *v1beta1.PostgresCluster, jobs []*batchv1.Job) {
+	var scheduled bool
+	var failed, pending []string
+
+	for _, repo := range cluster.Spec.Backups.PGBackRest.Repos {
+		if repo.BackupSchedules == nil || repo.BackupSchedules.Verify == nil {
+			continue
+		}
+		scheduled = true
+
+		// find the most recently created verify Job of the repo that finished
+		var latest *batchv1.Job
+		for _, job := range jobs {
+			if job.GetLabels()[naming.LabelPGBackRestRepo] != repo.Name ||
+				!(jobCompleted(job) || jobFailed(job)) {
+				continue
+			}
+			if latest == nil || latest.CreationTimestamp.Before(&job.CreationTimestamp) {
+				latest = job
+			}
+		}
+
+		switch {
+		case latest == nil:
+			pending = append(pending, repo.Name)
+		case jobFailed(latest):
+			failed = append(failed, repo.Name)
+		default:
+			for i := range cluster.Status.PGBackRest.Repos {
+				if cluster.Status.PGBackRest.Repos[i].Name == repo.Name {
+					cluster.Status.PGBackRest.Repos[i].LastVerified = latest.Status.CompletionTime
+				}
+			}
+		}
+	}
+
+	if !scheduled {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, ConditionBackupsVerified)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionBackupsVerified,
+		Status:             metav1.ConditionTrue,
+		Reason:             "VerifySucceeded",
+		Message:            "pgBackRest verify found no problems in any repository.",
+		ObservedGeneration: cluster.GetGeneration(),
+	}
+	switch {
+	case len(failed) > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "VerifyFailed"
+		condition.Message = "pgBackRest verify failed for " + strings.Join(failed, ", ") +
+			"; the logs of its latest verify Job describe why."
+	case len(pending) > 0:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "VerifyPending"
+		condition.Message = "pgBackRest verify has not yet finished for " +
+			strings.Join(pending, ", ") + "."
+	}
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+}
+
+// reconcileBackupInfo sets ConditionLastBackupSuccessful according to the latest finished backup
+// Job in jobs. The first time it sees that Job finished, it also records the backups in each
+// repository as reported by "pgbackrest info" on the primary.
+func (r *Reconciler) reconcileBackupInfo(ctx context.Context,
+	postgresCluster *v1beta1.PostgresCluster, instances *observedInstances,
+	jobs []*batchv1.Job) error {
+
+	var finished []*batchv1.Job
+	for _, job := range jobs {
+		if jobCompleted(job) || jobFailed(job) {
+			finished = append(finished, job)
+		}
+	}
+	if len(finished) == 0 {
+		return nil
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].CreationTimestamp.Before(&finished[j].CreationTimestamp)
+	})
+	latest := finished[len(finished)-1]
+
+	repoName := latest.GetLabels()[naming.LabelPGBackRestRepo]
+	condition := metav1.Condition{
+		Type:               ConditionLastBackupSuccessful,
+		Status:             metav1.ConditionTrue,
+		Reason:             "BackupSucceeded",
+		Message:            fmt.Sprintf("Backup Job %s to %s succeeded.", latest.Name, repoName),
+		ObservedGeneration: postgresCluster.GetGeneration(),
+	}
+	if jobFailed(latest) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "BackupFailed"
+		condition.Message = fmt.Sprintf("Backup Job %s to %s failed; its logs describe why.",
+			latest.Name, repoName)
+	}
+
+	// Announce each backup Job that finished since the last one recorded in status. The first
+	// time there is none, announce only the latest. Status is recorded only after pgBackRest
+	// info succeeds, so announce only when the condition changes to the latest Job.
+	previous := postgresCluster.Status.PGBackRest.LastBackup
+	if current := meta.FindStatusCondition(postgresCluster.Status.Conditions,
+		condition.Type); current == nil || current.Message != condition.Message {
+		for _, job := range finished {
+			announce := job == latest
+			if previous != nil {
+				announce = job.Name != previous.ID && (job == latest ||
+					(previous.StartTime != nil && job.Status.StartTime != nil &&
+						previous.StartTime.Before(job.Status.StartTime)))
+			}
+			if !announce {
+				continue
+			}
+
+			if jobFailed(job) {
+				r.Recorder.Eventf(postgresCluster, corev1.EventTypeWarning, EventBackupFailed,
+					"Backup Job %q to %s failed", job.Name, job.GetLabels()[naming.LabelPGBackRestRepo])
+			} else {
+				r.Recorder.Eventf(postgresCluster, corev1.EventTypeNormal, EventBackupCompleted,
+					"Backup Job %q to %s completed successfully",
+					job.Name, job.GetLabels()[naming.LabelPGBackRestRepo])
+			}
+		}
+	}
+	meta.SetStatusCondition(&postgresCluster.Status.Conditions, condition)
+
+	// pgBackRest info runs once for each backup Job that finishes.
+	if previous != nil && previous.ID == latest.Name {
+		return nil
+	}
+
+	// The primary is configured with every repository.
+	var writableInstanceName string
+	for _, instance := range instances.forCluster {
+		if writable, known := instance.IsWritable(); writable && known {
+			writableInstanceName = instance.Name + "-0"
+			break
+		}
+	}
+	if writableInstanceName == "" {
+		return nil
+	}
+
+	exec := func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer,
+		command ...string) error {
+		return r.PodExec(postgresCluster.GetNamespace(), writableInstanceName,
+			naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	}
+
+	info, err := pgbackrest.Executor(exec).Info(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range postgresCluster.Status.PGBackRest.Repos {
+		postgresCluster.Status.PGBackRest.Repos[i].Backups =
+			repoBackupsStatus(info, postgresCluster.Status.PGBackRest.Repos[i].Name)
+	}
+	postgresCluster.Status.PGBackRest.LastBackup = &v1beta1.PGBackRestJobStatus{
+		ID:             latest.Name,
+		Finished:       true,
+		StartTime:      latest.Status.StartTime,
+		CompletionTime: latest.Status.CompletionTime,
+		Active:         latest.Status.Active,
+		Succeeded:      latest.Status.Succeeded,
+		Failed:         latest.Status.Failed,
+	}
+	return nil
+}
+
+// repoBackupsStatus returns the latest successful backup of each type and the range of archived
+// WAL in the named repository according to info. It returns nil when the repository has neither.
+func repoBackupsStatus(info *pgbackrest.InfoStanza, repoName string) *v1beta1.RepoBackupsStatus {
+	key, err := strconv.Atoi(regexRepoIndex.FindString(repoName))
+	if err != nil {
+		return nil
+	}
+
+	var found bool
+	status := &v1beta1.RepoBackupsStatus{}
+
+	// Backups are oldest first, so later ones replace earlier ones of the same type.
+	for _, backup := range info.Backup {
+		if backup.Database.RepoKey != key || backup.Error {
+			continue
+		}
+
+		start := metav1.Unix(backup.Timestamp.Start, 0)
+		stop := metav1.Unix(backup.Timestamp.Stop, 0)
+		value := &v1beta1.RepoBackupStatus{
+			Label:          backup.Label,
+			StartTime:      &start,
+			StopTime:       &stop,
+			DatabaseSize:   resource.NewQuantity(backup.Info.Size, resource.BinarySI),
+			RepositorySize: resource.NewQuantity(backup.Info.Repository.Size, resource.BinarySI),
+			WALStart:       backup.Archive.Start,
+			WALStop:        backup.Archive.Stop,
+		}
+
+		switch backup.Type {
+		case full:
+			status.Full, found = value, true
+		case differential:
+			status.Differential, found = value, true
+		case incremental:
+			status.Incremental, found = value, true
+		}
+	}
+
+	// Archives are ordered by database, oldest first.
+	for _, archive := range info.Archive {
+		if archive.Database.RepoKey != key {
+			continue
+		}
+		if status.WALMin == "" {
+			status.WALMin = archive.Min
+		}
+		if archive.Max != "" {
+			status.WALMax = archive.Max
+		}
+		found = true
+	}
+
+	if !found {
+		return nil
+	}
+	return status
+}
@@ -46,6 +46,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -545,11 +546,13 @@ topologySpreadConstraints:
 				Full:         &testCronSchedule,
 				Differential: &testCronSchedule,
 				Incremental:  &testCronSchedule,
+				Verify:       &testCronSchedule,
 			}}
 
 		assert.Assert(t, backupScheduleFound(testrepo, "full"))
 		assert.Assert(t, backupScheduleFound(testrepo, "diff"))
 		assert.Assert(t, backupScheduleFound(testrepo, "incr"))
+		assert.Assert(t, backupScheduleFound(testrepo, "verify"))
 
 	})
 
@@ -857,7 +860,11 @@ func TestReconcileReplicaCreateBackup(t *testing.T) {
 	_, tClient := setupKubernetes(t)
 	require.ParallelCapacity(t, 1)
 
-	r := &Reconciler{Client: tClient, Owner: client.FieldOwner(t.Name())}
+	r := &Reconciler{
+		Client:   tClient,
+		Owner:    client.FieldOwner(t.Name()),
+		Recorder: new(record.FakeRecorder),
+	}
 
 	clusterName := "hippocluster"
 	clusterUID := "hippouid"
@@ -2061,6 +2068,160 @@ func TestReconcilePostgresClusterDataSource(t *testing.T) {
 	}
 }
 
+func TestReconcilePostgresClusterDataSourceSnapshot(t *testing.T) {
+	tEnv, tClient := setupKubernetes(t)
+	require.ParallelCapacity(t, 1)
+
+	r := &Reconciler{}
+	ctx, cancel := setupManager(t, tEnv.Config, func(mgr manager.Manager) {
+		r = &Reconciler{
+			Client:   tClient,
+			Recorder: mgr.GetEventRecorderFor(ControllerName),
+			Tracer:   otel.Tracer(ControllerName),
+			Owner:    ControllerName,
+		}
+	})
+	t.Cleanup(func() { teardownManager(cancel, t) })
+
+	namespace := setupNamespace(t, tClient).Name
+	rootCA, err := pki.NewRootCertificateAuthority()
+	assert.NilError(t, err)
+
+	sourceCluster := fakePostgresCluster("snapshot-source", namespace, "snapshot-source", true)
+	assert.NilError(t, tClient.Create(ctx, sourceCluster))
+	assert.NilError(t, tClient.Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: naming.PGBackRestConfig(sourceCluster),
+		Data:       map[string]string{"pgbackrest_instance.conf": "source-stuff"},
+	}))
+
+	cluster := fakePostgresCluster("snapshot-target", namespace, "snapshot-target", true)
+	cluster.Spec.DataSource = &v1beta1.DataSource{
+		PostgresCluster: &v1beta1.PostgresClusterDataSource{
+			ClusterName: sourceCluster.Name, RepoName: "repo1",
+		},
+		Volumes: &v1beta1.DataSourceVolumes{
+			PGDataVolume: &v1beta1.DataSourceVolume{
+				PVCName:            "snapshot-target-pgdata",
+				VolumeSnapshotName: "snapshot-source-pgdata",
+			},
+		},
+	}
+	assert.NilError(t, tClient.Create(ctx, cluster))
+	cluster.Status.StartupInstance = "testinstance"
+	cluster.Status.StartupInstanceSet = "instance1"
+	assert.NilError(t, tClient.Status().Update(ctx, cluster))
+
+	volumes, err := r.configureExistingPGVolumes(ctx, cluster, nil, "testinstance")
+	assert.NilError(t, err)
+	assert.Assert(t, len(volumes) == 1)
+
+	assert.NilError(t, r.reconcilePostgresClusterDataSource(ctx, cluster,
+		cluster.Spec.DataSource.PostgresCluster, "testhash", volumes, rootCA))
+
+	// The volume created from the snapshot keeps its data source.
+	pvc := &corev1.PersistentVolumeClaim{}
+	assert.NilError(t, tClient.Get(ctx,
+		client.ObjectKey{Namespace: namespace, Name: "snapshot-target-pgdata"}, pvc))
+	assert.Assert(t, pvc.Spec.DataSource != nil)
+	assert.Equal(t, pvc.Spec.DataSource.Kind, "VolumeSnapshot")
+	assert.Equal(t, pvc.Spec.DataSource.Name, "snapshot-source-pgdata")
+
+	// The restore runs on that volume and reads the stanza of the source repository.
+	restoreJobs := &batchv1.JobList{}
+	assert.NilError(t, tClient.List(ctx, restoreJobs, &client.ListOptions{
+		LabelSelector: naming.PGBackRestRestoreJobSelector(cluster.Name),
+		Namespace:     namespace,
+	}))
+	assert.Equal(t, len(restoreJobs.Items), 1)
+
+	spec := restoreJobs.Items[0].Spec.Template.Spec
+	command := strings.Join(spec.Containers[0].Command, " ")
+	assert.Assert(t, strings.Contains(command, "--stanza="+pgbackrest.DefaultStanzaName), "got %q", command)
+	assert.Assert(t, strings.Contains(command, "--repo=1"), "got %q", command)
+	assert.Assert(t, strings.Contains(command, "--delta"), "got %q", command)
+
+	var claimed bool
+	for _, volume := range spec.Volumes {
+		if volume.PersistentVolumeClaim != nil &&
+			volume.PersistentVolumeClaim.ClaimName == "snapshot-target-pgdata" {
+			claimed = true
+		}
+	}
+	assert.Assert(t, claimed, "got %#v", spec.Volumes)
+}
+
+func TestRestoreTargetOptions(t *testing.T) {
+	moment := metav1.NewTime(time.Date(2022, time.March, 15, 10, 30, 0, 0, time.UTC))
+
+	for _, tt := range []struct {
+		desc       string
+		dataSource v1beta1.PostgresClusterDataSource
+		options    []string
+		message    string
+	}{
+		{desc: "empty"},
+		{
+			desc:       "time",
+			dataSource: v1beta1.PostgresClusterDataSource{TargetTime: &moment},
+			options:    []string{"--type=time", "--target='2022-03-15 10:30:00+00'"},
+		},
+		{
+			desc:       "immediate",
+			dataSource: v1beta1.PostgresClusterDataSource{TargetType: "immediate"},
+			options:    []string{"--type=immediate"},
+		},
+		{
+			desc:       "name",
+			dataSource: v1beta1.PostgresClusterDataSource{TargetType: "name", Target: "it's here"},
+			options:    []string{"--type=name", `--target='it'"'"'s here'`},
+		},
+		{
+			desc:       "target without type",
+			dataSource: v1beta1.PostgresClusterDataSource{Target: "1234"},
+			message:    "requires 'targetType'",
+		},
+		{
+			desc:       "xid without target",
+			dataSource: v1beta1.PostgresClusterDataSource{TargetType: "xid"},
+			message:    "'xid' target type requires 'target'",
+		},
+		{
+			desc: "time with target",
+			dataSource: v1beta1.PostgresClusterDataSource{
+				TargetType: "time", TargetTime: &moment, Target: "1234",
+			},
+			message: "'time' target type requires 'targetTime' and no 'target'",
+		},
+	} {
+		t.Run(tt.desc, func(t *testing.T) {
+			options, message := restoreTargetOptions(&tt.dataSource)
+			assert.DeepEqual(t, options, tt.options)
+			if tt.message == "" {
+				assert.Equal(t, message, "")
+			} else {
+				assert.Assert(t, strings.Contains(message, tt.message), "got %q", message)
+			}
+		})
+	}
+}
+
+func TestHasRestoreOption(t *testing.T) {
+	for _, tt := range []struct {
+		opt    string
+		expect bool
+	}{
+		{opt: "--type=time", expect: true},
+		{opt: "--type time", expect: true},
+		{opt: "--target='2022-03-15'", expect: true},
+		{opt: "--delta --target=x", expect: true},
+		{opt: "--target-timeline=current"},
+		{opt: "--target-exclusive"},
+		{opt: "--delta"},
+	} {
+		assert.Equal(t, hasRestoreOption(tt.opt, "--type", "--target"), tt.expect, "opt: %q", tt.opt)
+	}
+}
+
 func TestReconcileCloudBasedDataSource(t *testing.T) {
 	tEnv, tClient := setupKubernetes(t)
 	require.ParallelCapacity(t, 4)
@@ -2489,6 +2650,52 @@ func TestCopyConfigurationResources(t *testing.T) {
 }
 
 func TestGenerateBackupJobIntent(t *testing.T) {
+	t.Run("TTLSecondsAfterFinished", func(t *testing.T) {
+		cluster := &v1beta1.PostgresCluster{}
+		repo := v1beta1.PGBackRestRepo{Name: "repo1", Volume: &v1beta1.RepoPVC{}}
+
+		spec, err := generateBackupJobSpecIntent(cluster, repo, "", nil, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, spec.TTLSecondsAfterFinished == nil)
+
+		cluster.Spec.Backups.PGBackRest.Jobs = &v1beta1.BackupJobs{
+			TTLSecondsAfterFinished: initialize.Int32(3600),
+		}
+		spec, err = generateBackupJobSpecIntent(cluster, repo, "", nil, nil)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, spec.TTLSecondsAfterFinished, initialize.Int32(3600))
+
+		// Verify Jobs are kept to report on them.
+		spec, err = generateCommandJobSpecIntent(cluster, repo, verify, "", nil, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, spec.TTLSecondsAfterFinished == nil)
+	})
+
+	t.Run("BackupFromStandby", func(t *testing.T) {
+		cluster := &v1beta1.PostgresCluster{}
+		cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{{
+			Name:              "repo1",
+			Volume:            &v1beta1.RepoPVC{},
+			BackupFromStandby: true,
+		}}
+
+		spec, err := generateBackupJobSpecIntent(cluster,
+			cluster.Spec.Backups.PGBackRest.Repos[0], "", nil, nil, "--type=full")
+		assert.NilError(t, err)
+		assert.Equal(t, spec.Template.Spec.Containers[0].Env[1].Value,
+			"--stanza=db --repo=1 --backup-standby --type=full")
+
+		// Other repositories back up on the primary, which has no replicas
+		// in its configuration.
+		repo := v1beta1.PGBackRestRepo{
+			Name: "repo2", S3: &v1beta1.RepoS3{}, BackupFromStandby: true,
+		}
+		spec, err = generateBackupJobSpecIntent(cluster, repo, "", nil, nil)
+		assert.NilError(t, err)
+		assert.Equal(t, spec.Template.Spec.Containers[0].Env[1].Value,
+			"--stanza=db --repo=2")
+	})
+
 	t.Run("empty", func(t *testing.T) {
 		spec, err := generateBackupJobSpecIntent(
 			&v1beta1.PostgresCluster{}, v1beta1.PGBackRestRepo{},
@@ -2680,6 +2887,25 @@ volumes:
 		assert.NilError(t, err)
 		assert.DeepEqual(t, job.Template.Spec.Tolerations, tolerations)
 	})
+
+	t.Run("Timezone", func(t *testing.T) {
+		cluster := &v1beta1.PostgresCluster{
+			Spec: v1beta1.PostgresClusterSpec{
+				Config: v1beta1.PostgresAdditionalConfig{
+					Timezone: "America/New_York",
+				},
+			},
+		}
+		job, err := generateBackupJobSpecIntent(
+			cluster, v1beta1.PGBackRestRepo{},
+			"",
+			nil, nil,
+		)
+		assert.NilError(t, err)
+		env := job.Template.Spec.Containers[0].Env
+		assert.DeepEqual(t, env[len(env)-1],
+			corev1.EnvVar{Name: "TZ", Value: "America/New_York"})
+	})
 }
 
 func TestGenerateRepoHostIntent(t *testing.T) {
@@ -2944,7 +3170,11 @@ func TestObserveRestoreEnv(t *testing.T) {
 	_, tClient := setupKubernetes(t)
 	require.ParallelCapacity(t, 1)
 
-	r := &Reconciler{Client: tClient, Owner: client.FieldOwner(t.Name())}
+	r := &Reconciler{
+		Client:   tClient,
+		Owner:    client.FieldOwner(t.Name()),
+		Recorder: new(record.FakeRecorder),
+	}
 	namespace := setupNamespace(t, tClient).Name
 
 	generateJob := func(clusterName string, completed, failed *bool) *batchv1.Job {
@@ -3204,13 +3434,13 @@ func TestPrepareForRestore(t *testing.T) {
 	for _, dedicated := range []bool{true, false} {
 		testCases := []struct {
 			desc            string
-			createResources func(t *testing.T, cluster *v1beta1.PostgresCluster) (*batchv1.Job, []corev1.Endpoints)
+			createResources func(t *testing.T, cluster *v1beta1.PostgresCluster) (*batchv1.Job, []client.Object)
 			fakeObserved    *observedInstances
 			result          testResult
 		}{{
 			desc: "remove restore jobs",
 			createResources: func(t *testing.T,
-				cluster *v1beta1.PostgresCluster) (*batchv1.Job, []corev1.Endpoints) {
+				cluster *v1beta1.PostgresCluster) (*batchv1.Job, []client.Object) {
 				job := generateJob(cluster.Name)
 				assert.NilError(t, r.Client.Create(ctx, job))
 				return job, nil
@@ -3228,7 +3458,7 @@ func TestPrepareForRestore(t *testing.T) {
 		}, {
 			desc: "remove patroni endpoints",
 			createResources: func(t *testing.T,
-				cluster *v1beta1.PostgresCluster) (*batchv1.Job, []corev1.Endpoints) {
+				cluster *v1beta1.PostgresCluster) (*batchv1.Job, []client.Object) {
 				fakeLeaderEP := corev1.Endpoints{}
 				fakeLeaderEP.ObjectMeta = naming.PatroniLeaderEndpoints(cluster)
 				fakeLeaderEP.ObjectMeta.Namespace = namespace
@@ -3241,7 +3471,7 @@ func TestPrepareForRestore(t *testing.T) {
 				fakeFailoverEP.ObjectMeta = naming.PatroniTrigger(cluster)
 				fakeFailoverEP.ObjectMeta.Namespace = namespace
 				assert.NilError(t, r.Client.Create(ctx, &fakeFailoverEP))
-				return nil, []corev1.Endpoints{fakeLeaderEP, fakeDCSEP, fakeFailoverEP}
+				return nil, []client.Object{&fakeLeaderEP, &fakeDCSEP, &fakeFailoverEP}
 			},
 			result: testResult{
 				restoreJobExists: false,
@@ -3256,8 +3486,8 @@ func TestPrepareForRestore(t *testing.T) {
 		}, {
 			desc: "cluster fully prepared",
 			createResources: func(t *testing.T,
-				cluster *v1beta1.PostgresCluster) (*batchv1.Job, []corev1.Endpoints) {
-				return nil, []corev1.Endpoints{}
+				cluster *v1beta1.PostgresCluster) (*batchv1.Job, []client.Object) {
+				return nil, []client.Object{}
 			},
 			result: testResult{
 				restoreJobExists: false,
@@ -3281,8 +3511,8 @@ func TestPrepareForRestore(t *testing.T) {
 				}}},
 			}},
 			createResources: func(t *testing.T,
-				cluster *v1beta1.PostgresCluster) (*batchv1.Job, []corev1.Endpoints) {
-				return nil, []corev1.Endpoints{}
+				cluster *v1beta1.PostgresCluster) (*batchv1.Job, []client.Object) {
+				return nil, []client.Object{}
 			},
 			result: testResult{
 				restoreJobExists: false,
@@ -3312,6 +3542,7 @@ func TestPrepareForRestore(t *testing.T) {
 				cluster.Status.Patroni = v1beta1.PatroniStatus{SystemIdentifier: "abcde12345"}
 				cluster.Status.Proxy.PGBouncer.PostgreSQLRevision = "abcde12345"
 				cluster.Status.Monitoring.ExporterConfiguration = "abcde12345"
+				cluster.Status.DatabaseRevision = "abcde12345"
 				meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
 					ObservedGeneration: cluster.GetGeneration(),
 					Type:               ConditionPostgresDataInitialized,
@@ -3387,6 +3618,7 @@ func TestPrepareForRestore(t *testing.T) {
 						assert.Assert(t, cluster.Status.Patroni.SystemIdentifier == "")
 						assert.Assert(t, cluster.Status.Proxy.PGBouncer.PostgreSQLRevision == "")
 						assert.Assert(t, cluster.Status.Monitoring.ExporterConfiguration == "")
+						assert.Assert(t, cluster.Status.DatabaseRevision == "")
 						assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
 							ConditionPostgresDataInitialized) == nil)
 					}
@@ -3752,3 +3984,354 @@ func TestSetScheduledJobStatus(t *testing.T) {
 		assert.Assert(t, len(postgresCluster.Status.PGBackRest.ScheduledBackups) == 0)
 	})
 }
+
+func TestSetVerifyStatus(t *testing.T) {
+	schedule := "@daily"
+	earlier := metav1.NewTime(time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(earlier.Add(time.Hour))
+
+	newJob := func(repo string, created metav1.Time, status batchv1.JobConditionType) *batchv1.Job {
+		job := &batchv1.Job{}
+		job.Labels = naming.PGBackRestCronJobLabels("hippo", repo, verify)
+		job.CreationTimestamp = created
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type: status, Status: corev1.ConditionTrue,
+		}}
+		if status == batchv1.JobComplete {
+			job.Status.CompletionTime = &created
+		}
+		return job
+	}
+
+	newCluster := func() *v1beta1.PostgresCluster {
+		cluster := &v1beta1.PostgresCluster{}
+		cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{
+			{Name: "repo1", BackupSchedules: &v1beta1.PGBackRestBackupSchedules{Verify: &schedule}},
+			{Name: "repo2", BackupSchedules: &v1beta1.PGBackRestBackupSchedules{Verify: &schedule}},
+			{Name: "repo3"},
+		}
+		cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+			Repos: []v1beta1.RepoStatus{{Name: "repo1"}, {Name: "repo2"}, {Name: "repo3"}},
+		}
+		return cluster
+	}
+
+	t.Run("NotScheduled", func(t *testing.T) {
+		cluster := newCluster()
+		cluster.Spec.Backups.PGBackRest.Repos = cluster.Spec.Backups.PGBackRest.Repos[2:]
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type: ConditionBackupsVerified, Status: metav1.ConditionTrue, Reason: "VerifySucceeded",
+		})
+
+		setVerifyStatus(cluster, nil)
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
+			ConditionBackupsVerified) == nil)
+	})
+
+	t.Run("Pending", func(t *testing.T) {
+		cluster := newCluster()
+		setVerifyStatus(cluster, []*batchv1.Job{newJob("repo1", earlier, batchv1.JobComplete)})
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, ConditionBackupsVerified)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionUnknown)
+		assert.Assert(t, strings.Contains(condition.Message, "repo2"))
+		assert.DeepEqual(t, cluster.Status.PGBackRest.Repos[0].LastVerified, &earlier)
+		assert.Assert(t, cluster.Status.PGBackRest.Repos[1].LastVerified == nil)
+	})
+
+	t.Run("Succeeded", func(t *testing.T) {
+		cluster := newCluster()
+		setVerifyStatus(cluster, []*batchv1.Job{
+			newJob("repo1", earlier, batchv1.JobFailed),
+			newJob("repo1", later, batchv1.JobComplete),
+			newJob("repo2", earlier, batchv1.JobComplete),
+		})
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, ConditionBackupsVerified)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.DeepEqual(t, cluster.Status.PGBackRest.Repos[0].LastVerified, &later)
+		assert.DeepEqual(t, cluster.Status.PGBackRest.Repos[1].LastVerified, &earlier)
+		assert.Assert(t, cluster.Status.PGBackRest.Repos[2].LastVerified == nil)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		cluster := newCluster()
+		cluster.Status.PGBackRest.Repos[1].LastVerified = &earlier
+
+		setVerifyStatus(cluster, []*batchv1.Job{
+			newJob("repo1", earlier, batchv1.JobComplete),
+			newJob("repo2", earlier, batchv1.JobComplete),
+			newJob("repo2", later, batchv1.JobFailed),
+		})
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, ConditionBackupsVerified)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "VerifyFailed")
+		assert.Assert(t, strings.Contains(condition.Message, "repo2"))
+
+		// The last success remains.
+		assert.DeepEqual(t, cluster.Status.PGBackRest.Repos[1].LastVerified, &earlier)
+	})
+}
+
+func TestRepoBackupsStatus(t *testing.T) {
+	info := &pgbackrest.InfoStanza{Name: "db"}
+	info.Archive = []pgbackrest.InfoArchive{
+		{Database: pgbackrest.InfoDatabase{ID: 1, RepoKey: 1},
+			Min: "000000010000000000000001", Max: "000000010000000000000009"},
+		{Database: pgbackrest.InfoDatabase{ID: 2, RepoKey: 1},
+			Min: "00000001000000000000000A", Max: "00000002000000000000000C"},
+	}
+
+	backup := func(key int, label, kind string, stop int64, failed bool) pgbackrest.InfoBackup {
+		var b pgbackrest.InfoBackup
+		b.Database.RepoKey = key
+		b.Error = failed
+		b.Label = label
+		b.Type = kind
+		b.Archive.Start = "000000010000000000000002"
+		b.Archive.Stop = "000000010000000000000003"
+		b.Info.Size = 1024 * 1024
+		b.Info.Repository.Size = 2048
+		b.Timestamp.Start = stop - 60
+		b.Timestamp.Stop = stop
+		return b
+	}
+	info.Backup = []pgbackrest.InfoBackup{
+		backup(1, "20220701-000000F", "full", 1656633660, false),
+		backup(1, "20220702-000000F", "full", 1656720060, false),
+		backup(1, "20220702-000000F_20220703-000000I", "incr", 1656806460, false),
+		backup(1, "20220702-000000F_20220704-000000D", "diff", 1656892860, true),
+		backup(2, "20220705-000000F", "full", 1656979260, false),
+	}
+
+	status := repoBackupsStatus(info, "repo1")
+	assert.Assert(t, status != nil)
+	assert.Equal(t, status.Full.Label, "20220702-000000F")
+	assert.Assert(t, status.Differential == nil, "expected failed backups to be ignored")
+	assert.Equal(t, status.Incremental.Label, "20220702-000000F_20220703-000000I")
+	assert.Equal(t, status.Full.StopTime.Unix(), int64(1656720060))
+	assert.Equal(t, status.Full.StartTime.Unix(), int64(1656720000))
+	assert.Equal(t, status.Full.DatabaseSize.String(), "1Mi")
+	assert.Equal(t, status.Full.RepositorySize.String(), "2Ki")
+	assert.Equal(t, status.Full.WALStart, "000000010000000000000002")
+	assert.Equal(t, status.Full.WALStop, "000000010000000000000003")
+	assert.Equal(t, status.WALMin, "000000010000000000000001")
+	assert.Equal(t, status.WALMax, "00000002000000000000000C")
+
+	status = repoBackupsStatus(info, "repo2")
+	assert.Assert(t, status != nil)
+	assert.Equal(t, status.Full.Label, "20220705-000000F")
+	assert.Equal(t, status.WALMin, "")
+
+	assert.Assert(t, repoBackupsStatus(info, "repo3") == nil)
+}
+
+func TestReconcileBackupInfo(t *testing.T) {
+	ctx := context.Background()
+	earlier := metav1.NewTime(time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC))
+	later := metav1.NewTime(earlier.Add(time.Hour))
+
+	newJob := func(name string, created metav1.Time, status batchv1.JobConditionType) *batchv1.Job {
+		job := &batchv1.Job{}
+		job.Name = name
+		job.Labels = map[string]string{naming.LabelPGBackRestRepo: "repo1"}
+		job.CreationTimestamp = created
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type: status, Status: corev1.ConditionTrue,
+		}}
+		return job
+	}
+
+	primary := &corev1.Pod{}
+	primary.Annotations = map[string]string{"status": `{"role":"master"}`}
+	instances := &observedInstances{forCluster: []*Instance{{
+		Name: "hippo-abcd", Pods: []*corev1.Pod{primary},
+	}}}
+
+	var calls int
+	recorder := record.NewFakeRecorder(100)
+	r := &Reconciler{
+		Recorder: recorder,
+		PodExec: func(namespace, pod, container string,
+			stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+			calls++
+			assert.Equal(t, pod, "hippo-abcd-0")
+			assert.Equal(t, container, naming.ContainerDatabase)
+			_, err := io.WriteString(stdout, `[{"name":"db","archive":[{
+				"database":{"id":1,"repo-key":1},
+				"min":"000000010000000000000001","max":"000000010000000000000004"}]}]`)
+			return err
+		},
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+		Repos: []v1beta1.RepoStatus{{Name: "repo1"}},
+	}
+
+	t.Run("NoneFinished", func(t *testing.T) {
+		running := newJob("running", later, batchv1.JobSuspended)
+		assert.NilError(t, r.reconcileBackupInfo(ctx, cluster, instances, []*batchv1.Job{running}))
+		assert.Equal(t, calls, 0)
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
+			ConditionLastBackupSuccessful) == nil)
+	})
+
+	t.Run("Succeeded", func(t *testing.T) {
+		jobs := []*batchv1.Job{
+			newJob("old", earlier, batchv1.JobFailed),
+			newJob("new", later, batchv1.JobComplete),
+		}
+		assert.NilError(t, r.reconcileBackupInfo(ctx, cluster, instances, jobs))
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions,
+			ConditionLastBackupSuccessful)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Assert(t, strings.Contains(condition.Message, "new"))
+
+		assert.Equal(t, calls, 1)
+		assert.Equal(t, cluster.Status.PGBackRest.LastBackup.ID, "new")
+		assert.Equal(t, cluster.Status.PGBackRest.Repos[0].Backups.WALMax,
+			"000000010000000000000004")
+
+		// Only the latest Job is announced the first time.
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Assert(t, strings.Contains(<-recorder.Events, "BackupCompleted"))
+
+		// pgBackRest info runs and events are emitted once per Job.
+		assert.NilError(t, r.reconcileBackupInfo(ctx, cluster, instances, jobs))
+		assert.Equal(t, calls, 1)
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		jobs := []*batchv1.Job{
+			newJob("new", later, batchv1.JobComplete),
+			newJob("newer", metav1.NewTime(later.Add(time.Hour)), batchv1.JobFailed),
+		}
+		assert.NilError(t, r.reconcileBackupInfo(ctx, cluster, instances, jobs))
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions,
+			ConditionLastBackupSuccessful)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "BackupFailed")
+		assert.Equal(t, calls, 2)
+		assert.Equal(t, cluster.Status.PGBackRest.LastBackup.ID, "newer")
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Assert(t, strings.Contains(<-recorder.Events, "BackupFailed"))
+	})
+
+	t.Run("NoPrimary", func(t *testing.T) {
+		jobs := []*batchv1.Job{
+			newJob("newer", metav1.NewTime(later.Add(time.Hour)), batchv1.JobFailed),
+			newJob("newest", metav1.NewTime(later.Add(2*time.Hour)), batchv1.JobComplete),
+		}
+		assert.NilError(t, r.reconcileBackupInfo(ctx, cluster, &observedInstances{}, jobs))
+		assert.Equal(t, calls, 2)
+		assert.Equal(t, cluster.Status.PGBackRest.LastBackup.ID, "newer")
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Assert(t, strings.Contains(<-recorder.Events, "BackupCompleted"))
+
+		// Events are not repeated while pgBackRest info waits for a primary.
+		assert.NilError(t, r.reconcileBackupInfo(ctx, cluster, &observedInstances{}, jobs))
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+}
+
+func TestReconcileStanzaUpgrade(t *testing.T) {
+	ctx := context.Background()
+
+	instances := newObservedInstances(&v1beta1.PostgresCluster{}, nil, []corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{"status": `"role":"master"`},
+			Labels:      map[string]string{naming.LabelInstance: "hippo-abcd"},
+		},
+	}})
+
+	newCluster := func() *v1beta1.PostgresCluster {
+		cluster := &v1beta1.PostgresCluster{}
+		cluster.Spec.PostgresVersion = 14
+		cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{{Name: "repo1"}}
+		cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+			Repos: []v1beta1.RepoStatus{{Name: "repo1", StanzaCreated: true}},
+		}
+		return cluster
+	}
+
+	t.Run("RecordsVersion", func(t *testing.T) {
+		cluster := newCluster()
+		r := &Reconciler{
+			PodExec: func(string, string, string, io.Reader, io.Writer, io.Writer, ...string) error {
+				t.Fatal("expected no exec")
+				return nil
+			},
+		}
+
+		_, err := r.reconcileStanzaCreate(ctx, cluster, instances, "hash")
+		assert.NilError(t, err)
+		assert.Equal(t, cluster.Status.PGBackRest.StanzaPostgresVersion, 14)
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
+			ConditionStanzaUpgraded) == nil)
+	})
+
+	t.Run("Upgraded", func(t *testing.T) {
+		cluster := newCluster()
+		cluster.Status.PGBackRest.StanzaPostgresVersion = 13
+
+		var commands [][]string
+		recorder := record.NewFakeRecorder(10)
+		r := &Reconciler{
+			Recorder: recorder,
+			PodExec: func(_, pod, _ string, _ io.Reader, _, stderr io.Writer, command ...string) error {
+				assert.Equal(t, pod, "hippo-abcd-0")
+				commands = append(commands, command)
+
+				if command[len(command)-1] == "stanza-create" {
+					_, _ = io.WriteString(stderr,
+						"backup and archive info files exist but do not match the database")
+					return errors.New("exit status 28")
+				}
+				return nil
+			},
+		}
+
+		_, err := r.reconcileStanzaCreate(ctx, cluster, instances, "hash")
+		assert.NilError(t, err)
+		assert.Equal(t, len(commands), 2)
+		assert.Equal(t, commands[1][len(commands[1])-1], "stanza-upgrade")
+
+		assert.Equal(t, cluster.Status.PGBackRest.StanzaPostgresVersion, 14)
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, ConditionStanzaUpgraded)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Assert(t, strings.Contains(<-recorder.Events, "StanzasUpgraded"))
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		cluster := newCluster()
+		cluster.Status.PGBackRest.StanzaPostgresVersion = 13
+
+		r := &Reconciler{
+			Recorder: record.NewFakeRecorder(10),
+			PodExec: func(string, string, string, io.Reader, io.Writer, io.Writer, ...string) error {
+				return errors.New("boom")
+			},
+		}
+
+		_, err := r.reconcileStanzaCreate(ctx, cluster, instances, "hash")
+		assert.ErrorContains(t, err, "boom")
+		assert.Equal(t, cluster.Status.PGBackRest.StanzaPostgresVersion, 13)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, ConditionStanzaUpgraded)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "StanzaUpgradeFailed")
+	})
+}
@@ -27,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crunchydata/postgres-operator/internal/initialize"
@@ -38,12 +39,75 @@ import (
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
+// validatePGBouncerPooling returns an error when the pooling settings of
+// cluster conflict with each other or could never be satisfied by PostgreSQL.
+func validatePGBouncerPooling(cluster *v1beta1.PostgresCluster) error {
+	if cluster.Spec.Proxy == nil || cluster.Spec.Proxy.PGBouncer == nil ||
+		cluster.Spec.Proxy.PGBouncer.Pooling == nil {
+		return nil
+	}
+
+	pooling := cluster.Spec.Proxy.PGBouncer.Pooling
+	path := field.NewPath("spec", "proxy", "pgBouncer", "pooling")
+
+	// Every PgBouncer pod may open a full pool to PostgreSQL, which accepts
+	// at most max_connections.
+	connections := postgres.MaxConnections(cluster)
+	pods := int32(1)
+	if r := cluster.Spec.Proxy.PGBouncer.Replicas; r != nil && *r > 1 {
+		pods = *r
+	}
+
+	check := func(path *field.Path, size, min *int32, sizeField string) error {
+		if size != nil && *size*pods > connections {
+			return field.Invalid(path.Child(sizeField), *size, fmt.Sprintf(
+				"%d PgBouncer pods with this pool size exceed max_connections (%d)",
+				pods, connections))
+		}
+		if size != nil && min != nil && *min > *size {
+			return field.Invalid(path.Child("minPoolSize"), *min,
+				"cannot be greater than "+sizeField)
+		}
+		return nil
+	}
+
+	if err := check(path, pooling.DefaultPoolSize, pooling.MinPoolSize, "defaultPoolSize"); err != nil {
+		return err
+	}
+	for i, pool := range pooling.Databases {
+		size, min := pool.PoolSize, pool.MinPoolSize
+		if size == nil {
+			size = pooling.DefaultPoolSize
+		}
+		if min == nil {
+			min = pooling.MinPoolSize
+		}
+		if err := check(path.Child("databases").Index(i), size, min, "poolSize"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // reconcilePGBouncer writes the objects necessary to run a PgBouncer Pod.
 func (r *Reconciler) reconcilePGBouncer(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
 	primaryCertificate *corev1.SecretProjection,
 	root *pki.RootCertificateAuthority,
 ) error {
+	defer timeReconciler("pgBouncer").ObserveDuration()
+
+	// Leave PgBouncer as it is while its pooling settings are invalid; it
+	// would fail to open or keep its pools of connections. Report that once
+	// for each change to the spec.
+	if err := validatePGBouncerPooling(cluster); err != nil {
+		if cluster.Status.ObservedGeneration != cluster.GetGeneration() {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "InvalidPGBouncerConfiguration",
+				err.Error())
+		}
+		return nil
+	}
+
 	var (
 		configmap *corev1.ConfigMap
 		secret    *corev1.Secret
@@ -301,6 +365,8 @@ func (r *Reconciler) generatePGBouncerService(
 		service.Spec.Type = corev1.ServiceTypeClusterIP
 	} else {
 		service.Spec.Type = corev1.ServiceType(spec.Type)
+		service.Spec.IPFamilies = spec.IPFamilies
+		service.Spec.IPFamilyPolicy = spec.IPFamilyPolicy
 		if spec.NodePort != nil {
 			if service.Spec.Type == corev1.ServiceTypeClusterIP {
 				// The NodePort can only be set when the Service type is NodePort or
@@ -315,6 +381,8 @@ func (r *Reconciler) generatePGBouncerService(
 			}
 			servicePort.NodePort = *spec.NodePort
 		}
+		setServiceExternalTraffic(spec, service)
+		setServiceRouting(spec, service)
 	}
 	service.Spec.Ports = []corev1.ServicePort{servicePort}
 
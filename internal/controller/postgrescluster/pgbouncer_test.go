@@ -20,6 +20,7 @@ package postgrescluster
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -28,6 +29,7 @@ import (
 	policyv1 "k8s.io/api/policy/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -37,6 +39,96 @@ import (
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
+func TestValidatePGBouncerPooling(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	assert.NilError(t, validatePGBouncerPooling(cluster))
+
+	cluster.Spec.Proxy = &v1beta1.PostgresProxySpec{
+		PGBouncer: &v1beta1.PGBouncerPodSpec{
+			Replicas: initialize.Int32(2),
+			Pooling: &v1beta1.PGBouncerPoolingSpec{
+				DefaultPoolSize: initialize.Int32(50),
+				MinPoolSize:     initialize.Int32(10),
+			},
+		},
+	}
+	assert.NilError(t, validatePGBouncerPooling(cluster))
+
+	t.Run("MaxConnections", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Proxy.PGBouncer.Replicas = initialize.Int32(3)
+		assert.ErrorContains(t, validatePGBouncerPooling(cluster),
+			"spec.proxy.pgBouncer.pooling.defaultPoolSize")
+
+		cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+			DynamicConfiguration: map[string]interface{}{
+				"postgresql": map[string]interface{}{
+					"parameters": map[string]interface{}{"max_connections": int64(150)},
+				},
+			},
+		}
+		assert.NilError(t, validatePGBouncerPooling(cluster))
+
+		// The spec takes precedence over the dynamic configuration.
+		cluster.Spec.Config.Parameters = map[string]intstr.IntOrString{
+			"max_connections": intstr.FromInt(120),
+		}
+		assert.ErrorContains(t, validatePGBouncerPooling(cluster),
+			"spec.proxy.pgBouncer.pooling.defaultPoolSize")
+
+		cluster.Spec.Config.Parameters = map[string]intstr.IntOrString{
+			"max_connections": intstr.FromInt(200),
+		}
+		assert.NilError(t, validatePGBouncerPooling(cluster))
+
+		cluster.Spec.Proxy.PGBouncer.Pooling.Databases = []v1beta1.PGBouncerDatabasePoolSpec{
+			{Name: "big", PoolSize: initialize.Int32(100)},
+		}
+		assert.ErrorContains(t, validatePGBouncerPooling(cluster),
+			"spec.proxy.pgBouncer.pooling.databases[0].poolSize")
+	})
+
+	t.Run("MinPoolSize", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Proxy.PGBouncer.Pooling.MinPoolSize = initialize.Int32(60)
+		assert.ErrorContains(t, validatePGBouncerPooling(cluster),
+			"spec.proxy.pgBouncer.pooling.minPoolSize")
+
+		cluster.Spec.Proxy.PGBouncer.Pooling.MinPoolSize = nil
+		cluster.Spec.Proxy.PGBouncer.Pooling.Databases = []v1beta1.PGBouncerDatabasePoolSpec{
+			{Name: "small", PoolSize: initialize.Int32(5), MinPoolSize: initialize.Int32(6)},
+		}
+		assert.ErrorContains(t, validatePGBouncerPooling(cluster),
+			"spec.proxy.pgBouncer.pooling.databases[0].minPoolSize")
+	})
+}
+
+func TestReconcilePGBouncerInvalidPooling(t *testing.T) {
+	ctx := context.Background()
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{Recorder: recorder}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Generation = 2
+	cluster.Spec.Proxy = &v1beta1.PostgresProxySpec{
+		PGBouncer: &v1beta1.PGBouncerPodSpec{
+			Pooling: &v1beta1.PGBouncerPoolingSpec{
+				DefaultPoolSize: initialize.Int32(200),
+			},
+		},
+	}
+
+	// Nothing is written while the settings are invalid.
+	assert.NilError(t, reconciler.reconcilePGBouncer(ctx, cluster, nil, nil, nil))
+	assert.Equal(t, len(recorder.Events), 1)
+	assert.Assert(t, strings.Contains(<-recorder.Events, "InvalidPGBouncerConfiguration"))
+
+	// The same spec is not reported again.
+	cluster.Status.ObservedGeneration = 2
+	assert.NilError(t, reconciler.reconcilePGBouncer(ctx, cluster, nil, nil, nil))
+	assert.Equal(t, len(recorder.Events), 0)
+}
+
 func TestGeneratePGBouncerService(t *testing.T) {
 	_, cc := setupKubernetes(t)
 	require.ParallelCapacity(t, 0)
@@ -22,6 +22,9 @@ import (
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crunchydata/postgres-operator/internal/config"
@@ -59,7 +62,77 @@ func (r *Reconciler) reconcilePGMonitor(ctx context.Context,
 	monitoringSecret *corev1.Secret) error {
 
 	err := r.reconcilePGMonitorExporter(ctx, cluster, instances, monitoringSecret)
+	if err == nil {
+		err = r.reconcilePrometheusRule(ctx, cluster)
+	}
+
+	return err
+}
+
+// +kubebuilder:rbac:groups="monitoring.coreos.com",resources="prometheusrules",verbs={get}
+// +kubebuilder:rbac:groups="monitoring.coreos.com",resources="prometheusrules",verbs={create,delete,patch}
+
+// reconcilePrometheusRule writes the PrometheusRule of alerts about cluster
+// when they are requested and deletes it otherwise. The Prometheus Operator
+// is optional, so its types are handled as unstructured objects.
+func (r *Reconciler) reconcilePrometheusRule(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	gvk := schema.GroupVersionKind{
+		Group: "monitoring.coreos.com", Version: "v1", Kind: "PrometheusRule",
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(gvk)
+	existing.SetNamespace(naming.ClusterPrometheusRule(cluster).Namespace)
+	existing.SetName(naming.ClusterPrometheusRule(cluster).Name)
+
+	err := errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing))
+	if meta.IsNoMatchError(err) {
+		// The Prometheus Operator is not installed.
+		if pgmonitor.AlertsEnabled(cluster) {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "PrometheusRuleUnavailable",
+				"Unable to create alerts; the PrometheusRule API is not installed")
+		}
+		return nil
+	}
+	if client.IgnoreNotFound(err) != nil {
+		return err
+	}
 
+	if !pgmonitor.AlertsEnabled(cluster) {
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, existing))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	intent := &unstructured.Unstructured{}
+	intent.SetGroupVersionKind(gvk)
+	intent.SetNamespace(existing.GetNamespace())
+	intent.SetName(existing.GetName())
+	intent.SetAnnotations(naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil()))
+	intent.SetLabels(naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		cluster.Spec.Monitoring.PGMonitor.Alerts.Labels,
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleMonitoring,
+		}))
+	intent.Object["spec"] = pgmonitor.PrometheusRuleSpec(cluster)
+
+	err = errors.WithStack(r.setControllerReference(cluster, intent))
+
+	// Send the whole object as an apply-patch; there is no zero value to
+	// compare against as in [Reconciler.apply].
+	var data []byte
+	if err == nil {
+		data, err = intent.MarshalJSON()
+	}
+	if err == nil {
+		err = errors.WithStack(r.patch(ctx, intent,
+			client.RawPatch(client.Apply.Type(), data), client.ForceOwnership))
+	}
 	return err
 }
 
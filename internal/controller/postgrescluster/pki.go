@@ -136,6 +136,16 @@ func (r *Reconciler) reconcileClusterCertificate(
 	dnsNames := naming.ServiceDNSNames(ctx, primaryService)
 	dnsFQDN := dnsNames[0]
 
+	// Clients of the replica Service, such as PgBouncer, verify it as well.
+	dnsNames = append(dnsNames, naming.ServiceDNSNames(ctx,
+		&corev1.Service{ObjectMeta: naming.ClusterReplicaService(cluster)})...)
+
+	// Clients of HAProxy connect through its Service and verify it, too.
+	if cluster.Spec.Proxy != nil && cluster.Spec.Proxy.HAProxy != nil {
+		dnsNames = append(dnsNames, naming.ServiceDNSNames(ctx,
+			&corev1.Service{ObjectMeta: naming.ClusterHAProxy(cluster)})...)
+	}
+
 	if err == nil {
 		// Unmarshal and validate the stored leaf. These first errors can
 		// be ignored because they result in an invalid leaf which is then
@@ -370,12 +370,52 @@ func TestReconcileCerts(t *testing.T) {
 				strings.HasPrefix(leaf.Certificate.CommonName(), "the-primary."+namespace+".svc."),
 				"got %q", leaf.Certificate.CommonName())
 
-			if dnsNames := leaf.Certificate.DNSNames(); assert.Check(t, len(dnsNames) > 1) {
-				assert.DeepEqual(t, dnsNames[1:], []string{
+			if dnsNames := leaf.Certificate.DNSNames(); assert.Check(t, len(dnsNames) == 8) {
+				assert.DeepEqual(t, dnsNames[1:4], []string{
 					"the-primary." + namespace + ".svc",
 					"the-primary." + namespace,
 					"the-primary",
 				})
+
+				// The replica Service is included as well.
+				assert.Assert(t, strings.HasPrefix(dnsNames[4],
+					cluster1.Name+"-replicas."+namespace+".svc."), "got %q", dnsNames[4])
+				assert.DeepEqual(t, dnsNames[5:], []string{
+					cluster1.Name + "-replicas." + namespace + ".svc",
+					cluster1.Name + "-replicas." + namespace,
+					cluster1.Name + "-replicas",
+				})
+			}
+		})
+
+		t.Run("HAProxy", func(t *testing.T) {
+			cluster := cluster1.DeepCopy()
+			cluster.Spec.Proxy = &v1beta1.PostgresProxySpec{HAProxy: &v1beta1.HAProxyPodSpec{}}
+
+			root, err := r.reconcileRootCertificate(ctx, cluster)
+			assert.NilError(t, err)
+
+			_, err = r.reconcileClusterCertificate(ctx, root, cluster, primaryService)
+			assert.NilError(t, err)
+
+			secret := &corev1.Secret{}
+			assert.NilError(t, tClient.Get(ctx, types.NamespacedName{
+				Name:      fmt.Sprintf(naming.ClusterCertSecret, cluster.Name),
+				Namespace: namespace,
+			}, secret))
+
+			leaf := &pki.LeafCertificate{}
+			assert.NilError(t, leaf.Certificate.UnmarshalText(secret.Data["tls.crt"]))
+
+			// The HAProxy Service is included after the replica Service.
+			if dnsNames := leaf.Certificate.DNSNames(); assert.Check(t, len(dnsNames) == 12) {
+				assert.Assert(t, strings.HasPrefix(dnsNames[8],
+					cluster.Name+"-haproxy."+namespace+".svc."), "got %q", dnsNames[8])
+				assert.DeepEqual(t, dnsNames[9:], []string{
+					cluster.Name + "-haproxy." + namespace + ".svc",
+					cluster.Name + "-haproxy." + namespace,
+					cluster.Name + "-haproxy",
+				})
 			}
 		})
 	})
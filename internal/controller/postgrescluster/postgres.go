@@ -23,11 +23,14 @@ import (
 	"net"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
@@ -37,6 +40,7 @@ import (
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/pgaudit"
+	"github.com/crunchydata/postgres-operator/internal/pgpartman"
 	"github.com/crunchydata/postgres-operator/internal/postgis"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
 	pgpassword "github.com/crunchydata/postgres-operator/internal/postgres/password"
@@ -44,6 +48,74 @@ import (
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
+const (
+	// ConditionPostgresLocaleSupported is the type used in a condition to
+	// indicate whether or not PostgreSQL in the image can bootstrap the
+	// cluster with its locale provider.
+	ConditionPostgresLocaleSupported = "PostgresLocaleSupported"
+)
+
+// observeLocaleSupport sets a condition when the startup container of an
+// instance reports that PostgreSQL in the image cannot use the ICU locale
+// provider. That container stops before bootstrap rather than let initdb fail.
+func observeLocaleSupport(cluster *v1beta1.PostgresCluster, instances *observedInstances) {
+	locale := cluster.Spec.Config.Locale
+	if locale == nil || locale.Provider != v1beta1.PostgresLocaleProviderICU {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, ConditionPostgresLocaleSupported)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionPostgresLocaleSupported,
+		ObservedGeneration: cluster.GetGeneration(),
+	}
+
+	for _, instance := range instances.forCluster {
+		for _, pod := range instance.Pods {
+			for _, status := range pod.Status.InitContainerStatuses {
+				if status.Name != naming.ContainerPostgresStartup {
+					continue
+				}
+				for _, terminated := range []*corev1.ContainerStateTerminated{
+					status.State.Terminated, status.LastTerminationState.Terminated,
+				} {
+					if terminated == nil {
+						continue
+					}
+					if terminated.ExitCode != 0 &&
+						strings.Contains(terminated.Message, postgres.ICUUnsupportedMessage) {
+						condition.Status = metav1.ConditionFalse
+						condition.Reason = "ICUUnsupported"
+						condition.Message = fmt.Sprintf("Pod %s: %s",
+							pod.Name, postgres.ICUUnsupportedMessage)
+					}
+					if terminated.ExitCode == 0 && condition.Status == "" {
+						condition.Status = metav1.ConditionTrue
+						condition.Reason = "ICUSupported"
+						condition.Message = "The startup container found no problem with the icu locale provider"
+					}
+				}
+			}
+		}
+	}
+
+	if condition.Status != "" {
+		meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+	}
+}
+
+// libpqKeywordValue returns a connection string of libpq keyword/value pairs.
+// Every value is quoted, so it may contain spaces and other special characters.
+// - https://www.postgresql.org/docs/current/libpq-connect.html#LIBPQ-CONNSTRING
+func libpqKeywordValue(pairs ...string) string {
+	escape := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	words := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		words = append(words, pairs[i]+"='"+escape.Replace(pairs[i+1])+"'")
+	}
+	return strings.Join(words, " ")
+}
+
 // generatePostgresUserSecret returns a Secret containing a password and
 // connection details for the first database in spec. When existing is nil or
 // lacks a password or verifier, a new password and verifier are generated.
@@ -92,24 +164,47 @@ func (r *Reconciler) generatePostgresUserSecret(
 		intent.Data["verifier"] = nil
 	}
 
+	// Discard a verifier of the wrong type so the password is hashed again.
+	// MD5 verifiers begin with "md5" while SCRAM verifiers begin with "SCRAM".
+	// - https://www.postgresql.org/docs/current/catalog-pg-authid.html
+	md5 := postgres.PasswordType(cluster, spec) == v1beta1.PasswordTypeMD5
+	if md5 != bytes.HasPrefix(intent.Data["verifier"], []byte("md5")) {
+		intent.Data["verifier"] = nil
+	}
+
 	// When a password has been generated or the verifier is empty,
 	// generate a verifier based on the current password.
 	// NOTE(cbandy): We don't have a function to compare a plaintext
 	// password to a SCRAM verifier.
 	if len(intent.Data["verifier"]) == 0 {
-		verifier, err := pgpassword.NewSCRAMPassword(string(intent.Data["password"])).Build()
+		var builder pgpassword.PostgresPassword = pgpassword.NewSCRAMPassword(
+			string(intent.Data["password"]))
+		if md5 {
+			builder = pgpassword.NewMD5Password(username, string(intent.Data["password"]))
+		}
+
+		verifier, err := builder.Build()
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
 		intent.Data["verifier"] = []byte(verifier)
 	}
 
+	// Include a keyword/value connection string. libpq connects to a database
+	// named after the user when none is specified.
+	dsn := []string{
+		"host", hostname, "port", port,
+		"user", username, "password", string(intent.Data["password"]),
+	}
+	intent.Data["dsn"] = []byte(libpqKeywordValue(dsn...))
+
 	// When a database has been specified, include it and a connection URI.
 	// - https://www.postgresql.org/docs/current/libpq-connect.html#LIBPQ-CONNSTRING
 	if len(spec.Databases) > 0 {
 		database := string(spec.Databases[0])
 
 		intent.Data["dbname"] = []byte(database)
+		intent.Data["dsn"] = []byte(libpqKeywordValue(append(dsn, "dbname", database)...))
 		intent.Data["uri"] = []byte((&url.URL{
 			Scheme: "postgresql",
 			User:   url.UserPassword(username, string(intent.Data["password"])),
@@ -139,9 +234,18 @@ func (r *Reconciler) generatePostgresUserSecret(
 		intent.Data["pgbouncer-host"] = []byte(hostname)
 		intent.Data["pgbouncer-port"] = []byte(port)
 
+		dsn := []string{
+			"host", hostname, "port", port,
+			"user", username, "password", string(intent.Data["password"]),
+		}
+		intent.Data["pgbouncer-dsn"] = []byte(libpqKeywordValue(dsn...))
+
 		if len(spec.Databases) > 0 {
 			database := string(spec.Databases[0])
 
+			intent.Data["pgbouncer-dsn"] = []byte(
+				libpqKeywordValue(append(dsn, "dbname", database)...))
+
 			intent.Data["pgbouncer-uri"] = []byte((&url.URL{
 				Scheme: "postgresql",
 				User:   url.UserPassword(username, string(intent.Data["password"])),
@@ -229,9 +333,51 @@ func (r *Reconciler) reconcilePostgresDatabases(
 		}
 	}
 
+	// Gather the tablespaces declared in any instance set.
+
+	tablespaces := sets.String{}
+	for _, set := range cluster.Spec.InstanceSets {
+		for _, tablespace := range set.TablespaceVolumes {
+			tablespaces.Insert(tablespace.Name)
+		}
+	}
+
+	// Gather the connection strings of subscriptions from their Secrets.
+	// Subscriptions without one are skipped until it exists.
+
+	var publications []v1beta1.PostgresPublicationSpec
+	var subscriptions []v1beta1.PostgresSubscriptionSpec
+	connections := map[string]string{}
+	connectionsOK := true
+	if cluster.Spec.Replication != nil {
+		publications = cluster.Spec.Replication.Publications
+
+		for _, subscription := range cluster.Spec.Replication.Subscriptions {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      subscription.Connection.Name,
+			}}
+			err := errors.WithStack(
+				r.Client.Get(ctx, client.ObjectKeyFromObject(secret), secret))
+			if client.IgnoreNotFound(err) != nil {
+				return err
+			}
+
+			if value, ok := secret.Data[subscription.Connection.Key]; err == nil && ok {
+				connections[string(subscription.Name)] = string(value)
+				subscriptions = append(subscriptions, subscription)
+			} else {
+				connectionsOK = false
+				r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "SubscriptionSecretNotFound",
+					"Unable to find key %q in Secret %q for subscription %q",
+					subscription.Connection.Key, secret.Name, subscription.Name)
+			}
+		}
+	}
+
 	// Calculate a hash of the SQL that should be executed in PostgreSQL.
 
-	var pgAuditOK, postgisInstallOK bool
+	var pgAuditOK, postgisInstallOK, extensionsOK, partmanOK, replicationOK bool
 	create := func(ctx context.Context, exec postgres.Executor) error {
 		if pgAuditOK = pgaudit.EnableInPostgreSQL(ctx, exec) == nil; !pgAuditOK {
 			// pgAudit can only be enabled after its shared library is loaded,
@@ -254,7 +400,76 @@ func (r *Reconciler) reconcilePostgresDatabases(
 				"Unable to install PostGIS")
 		}
 
-		return postgres.CreateDatabasesInPostgreSQL(ctx, exec, databases.List())
+		// Create tablespaces before databases so they can be used right away.
+		if len(tablespaces) > 0 {
+			if err := postgres.CreateTablespacesInPostgreSQL(ctx, exec, tablespaces.List()); err != nil {
+				return err
+			}
+		}
+
+		// Create the databases in the cluster spec before those of users so
+		// their locales apply.
+		if len(cluster.Spec.Databases) > 0 {
+			if err := postgres.WriteDatabasesInPostgreSQL(ctx, exec, cluster); err != nil {
+				return err
+			}
+		}
+
+		err := postgres.CreateDatabasesInPostgreSQL(ctx, exec, databases.List())
+
+		// Create extensions once the databases exist. An extension fails when
+		// its shared library is not yet loaded; PostgreSQL restarts to load
+		// it, and this runs again until it succeeds.
+		if err == nil && len(cluster.Spec.Extensions) > 0 {
+			if extensionsOK = postgres.CreateExtensionsInPostgreSQL(
+				ctx, exec, cluster.Spec.Extensions) == nil; !extensionsOK {
+				r.Recorder.Event(cluster, corev1.EventTypeWarning, "ExtensionsNotCreated",
+					"Unable to create extensions; PostgreSQL may need to restart")
+
+				// Restarting does not help when the image lacks an extension.
+				if missing, _ := postgres.MissingExtensions(
+					ctx, exec, cluster.Spec.Extensions); len(missing) > 0 {
+					r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "ExtensionsNotAvailable",
+						"The PostgreSQL image does not include extensions: %s",
+						strings.Join(missing, ", "))
+				}
+			}
+		} else {
+			extensionsOK = true
+		}
+
+		// Create pg_partman once its databases exist. It fails until
+		// PostgreSQL restarts to load the background worker.
+		if err == nil && cluster.Spec.PGPartman != nil {
+			if partmanOK = pgpartman.EnableInPostgreSQL(
+				ctx, exec, cluster.Spec.PGPartman) == nil; !partmanOK {
+				r.Recorder.Event(cluster, corev1.EventTypeWarning, "PGPartmanDisabled",
+					"Unable to install pg_partman; PostgreSQL may need to restart")
+			}
+		} else {
+			partmanOK = true
+		}
+
+		// Write publications and subscriptions once the databases exist. A
+		// table that does not exist or a publisher that cannot be reached
+		// fails; this runs again until it succeeds.
+		replicationOK = connectionsOK
+		if err == nil && len(publications) > 0 {
+			if postgres.WritePublicationsInPostgreSQL(ctx, exec, publications) != nil {
+				replicationOK = false
+				r.Recorder.Event(cluster, corev1.EventTypeWarning, "PublicationsNotWritten",
+					"Unable to create or alter publications")
+			}
+		}
+		if err == nil && len(subscriptions) > 0 {
+			if postgres.WriteSubscriptionsInPostgreSQL(ctx, exec, subscriptions, connections) != nil {
+				replicationOK = false
+				r.Recorder.Event(cluster, corev1.EventTypeWarning, "SubscriptionsNotWritten",
+					"Unable to create or alter subscriptions")
+			}
+		}
+
+		return err
 	}
 
 	revision, err := safeHash32(func(hasher io.Writer) error {
@@ -285,7 +500,8 @@ func (r *Reconciler) reconcilePostgresDatabases(
 		log := logging.FromContext(ctx).WithValues("revision", revision)
 		err = errors.WithStack(create(logging.NewContext(ctx, log), podExecutor))
 	}
-	if err == nil && pgAuditOK && postgisInstallOK {
+	if err == nil && pgAuditOK && postgisInstallOK && extensionsOK && partmanOK &&
+		replicationOK {
 		cluster.Status.DatabaseRevision = revision
 	}
 
@@ -412,6 +628,15 @@ func (r *Reconciler) reconcilePostgresUserSecrets(
 			secret = defaultSecret
 		}
 
+		if err == nil && len(user.SecretName) > 0 {
+			secret, err = r.providedPostgresUserSecret(ctx, cluster, user, secret)
+
+			// Wait for the provided password rather than generate one.
+			if err == nil && secret == nil {
+				delete(userSecrets, userName)
+				continue
+			}
+		}
 		if err == nil {
 			userSecrets[userName], err = r.generatePostgresUserSecret(cluster, user, secret)
 		}
@@ -423,6 +648,56 @@ func (r *Reconciler) reconcilePostgresUserSecrets(
 	return specUsers, userSecrets, err
 }
 
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
+
+// providedPostgresUserSecret reads the password and verifier of user from the
+// Secret named in its spec. When that Secret is missing or has no password, it
+// returns existing so the current password stays in place, or nil when there
+// is no current password.
+func (r *Reconciler) providedPostgresUserSecret(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	user *v1beta1.PostgresUserSpec, existing *corev1.Secret,
+) (*corev1.Secret, error) {
+	provided := &corev1.Secret{}
+	err := errors.WithStack(r.Client.Get(ctx, client.ObjectKey{
+		Namespace: cluster.Namespace, Name: user.SecretName,
+	}, provided))
+
+	var problem string
+	if apierrors.IsNotFound(err) {
+		problem = fmt.Sprintf("Secret %q for user %q was not found", user.SecretName, user.Name)
+	} else if err == nil && len(provided.Data["password"]) == 0 {
+		problem = fmt.Sprintf("Secret %q has no password for user %q", user.SecretName, user.Name)
+	} else if err != nil {
+		return existing, err
+	}
+
+	if problem != "" {
+		// Report the problem once per change to the spec.
+		if cluster.Status.ObservedGeneration != cluster.GetGeneration() {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "InvalidUserSecret", problem)
+		}
+		if existing == nil || len(existing.Data["password"]) == 0 {
+			return nil, nil
+		}
+		return existing, nil
+	}
+
+	result := &corev1.Secret{Data: map[string][]byte{
+		"password": provided.Data["password"],
+		"verifier": provided.Data["verifier"],
+	}}
+
+	// Keep a verifier that was built earlier from the same password. An empty
+	// verifier is regenerated from the password.
+	if len(result.Data["verifier"]) == 0 && existing != nil &&
+		bytes.Equal(existing.Data["password"], provided.Data["password"]) {
+		result.Data["verifier"] = existing.Data["verifier"]
+	}
+
+	return result, nil
+}
+
 // reconcilePostgresUsersInPostgreSQL creates users inside of PostgreSQL and
 // sets their options and database access as specified.
 func (r *Reconciler) reconcilePostgresUsersInPostgreSQL(
@@ -553,6 +828,12 @@ func (r *Reconciler) reconcilePostgresDataVolume(
 
 	pvc.Spec = instanceSpec.DataVolumeClaimSpec
 
+	// Keep the snapshot of an existing volume; it cannot be removed.
+	if source := cluster.Spec.DataSource; source != nil && source.Volumes != nil &&
+		source.Volumes.PGDataVolume != nil && source.Volumes.PGDataVolume.PVCName == pvc.Name {
+		setVolumeSnapshotDataSource(pvc, source.Volumes.PGDataVolume)
+	}
+
 	if err == nil {
 		err = r.handlePersistentVolumeClaimError(cluster,
 			errors.WithStack(r.apply(ctx, pvc)))
@@ -656,6 +937,12 @@ func (r *Reconciler) reconcilePostgresWALVolume(
 
 	pvc.Spec = *instanceSpec.WALVolumeClaimSpec
 
+	// Keep the snapshot of an existing volume; it cannot be removed.
+	if source := cluster.Spec.DataSource; source != nil && source.Volumes != nil &&
+		source.Volumes.PGWALVolume != nil && source.Volumes.PGWALVolume.PVCName == pvc.Name {
+		setVolumeSnapshotDataSource(pvc, source.Volumes.PGWALVolume)
+	}
+
 	if err == nil {
 		err = r.handlePersistentVolumeClaimError(cluster,
 			errors.WithStack(r.apply(ctx, pvc)))
@@ -664,6 +951,72 @@ func (r *Reconciler) reconcilePostgresWALVolume(
 	return pvc, err
 }
 
+// reconcileTablespaceVolumes writes the PersistentVolumeClaims for the
+// tablespaces of instance. PVCs of tablespaces removed from the spec are kept
+// because they may still contain data.
+func (r *Reconciler) reconcileTablespaceVolumes(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	instanceSpec *v1beta1.PostgresInstanceSetSpec, instance *appsv1.StatefulSet,
+	clusterVolumes []corev1.PersistentVolumeClaim,
+) ([]*corev1.PersistentVolumeClaim, error) {
+	var pvcs []*corev1.PersistentVolumeClaim
+
+	for _, tablespace := range instanceSpec.TablespaceVolumes {
+		labelMap := map[string]string{
+			naming.LabelCluster:     cluster.Name,
+			naming.LabelInstanceSet: instanceSpec.Name,
+			naming.LabelInstance:    instance.Name,
+			naming.LabelRole:        naming.RolePostgresTablespace,
+			naming.LabelTablespace:  tablespace.Name,
+			naming.LabelData:        naming.DataPostgres,
+		}
+
+		var pvc *corev1.PersistentVolumeClaim
+		existingPVCName, err := getPGPVCName(labelMap, clusterVolumes)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if existingPVCName != "" {
+			pvc = &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.GetNamespace(),
+				Name:      existingPVCName,
+			}}
+		} else {
+			pvc = &corev1.PersistentVolumeClaim{
+				ObjectMeta: naming.InstanceTablespaceVolume(instance, tablespace.Name),
+			}
+		}
+
+		pvc.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"))
+
+		err = errors.WithStack(r.setControllerReference(cluster, pvc))
+
+		pvc.Annotations = naming.Merge(
+			cluster.Spec.Metadata.GetAnnotationsOrNil(),
+			instanceSpec.Metadata.GetAnnotationsOrNil())
+
+		pvc.Labels = naming.Merge(
+			cluster.Spec.Metadata.GetLabelsOrNil(),
+			instanceSpec.Metadata.GetLabelsOrNil(),
+			labelMap,
+		)
+
+		pvc.Spec = tablespace.DataVolumeClaimSpec
+
+		if err == nil {
+			err = r.handlePersistentVolumeClaimError(cluster,
+				errors.WithStack(r.apply(ctx, pvc)))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		pvcs = append(pvcs, pvc)
+	}
+
+	return pvcs, nil
+}
+
 // reconcileDatabaseInitSQL runs custom SQL files in the database. When
 // DatabaseInitSQL is defined, the function will find the primary pod and run
 // SQL from the defined ConfigMap
@@ -705,6 +1058,26 @@ func (r *Reconciler) reconcileDatabaseInitSQL(ctx context.Context,
 		}
 
 		key := cluster.Spec.DatabaseInitSQL.Key
+		if key == "" {
+			// Run every SQL file in the ConfigMap in order of their names.
+			keys := make([]string, 0, len(cm.Data))
+			for k := range cm.Data {
+				if strings.HasSuffix(k, ".sql") {
+					keys = append(keys, k)
+				}
+			}
+			if len(keys) == 0 {
+				return "", errors.New("ConfigMap did not contain any keys ending in .sql")
+			}
+			sort.Strings(keys)
+
+			files := make([]string, len(keys))
+			for i, k := range keys {
+				files[i] = cm.Data[k]
+			}
+			return strings.Join(files, "\n"), nil
+		}
+
 		if _, ok := cm.Data[key]; !ok {
 			err := errors.Errorf("ConfigMap did not contain expected key: %s", key)
 			return "", err
@@ -737,8 +1110,31 @@ func (r *Reconciler) reconcileDatabaseInitSQL(ctx context.Context,
 
 	// A writable pod executor has been found and we have the sql provided by
 	// the user. Setup a write function to execute the sql using the podExecutor
+	variables := map[string]string{}
+	for k, v := range cluster.Spec.DatabaseInitSQL.Variables {
+		variables[k] = v
+	}
+
+	// Stop at the first command that fails unless the SQL is a single key that
+	// runs in the maintenance database. That has always continued past errors,
+	// so it stops only when asked.
+	stop := cluster.Spec.DatabaseInitSQL.Key == "" || cluster.Spec.DatabaseInitSQL.Database != ""
+	if cluster.Spec.DatabaseInitSQL.StopOnError != nil {
+		stop = *cluster.Spec.DatabaseInitSQL.StopOnError
+	}
+	if stop {
+		variables["ON_ERROR_STOP"] = "on"
+	}
+
 	write := func(ctx context.Context, exec postgres.Executor) error {
-		stdout, stderr, err := exec.Exec(ctx, strings.NewReader(data), map[string]string{})
+		var stdout, stderr string
+		var err error
+		if database := cluster.Spec.DatabaseInitSQL.Database; database != "" {
+			stdout, stderr, err = exec.ExecInDatabasesFromQuery(ctx,
+				`SELECT `+util.SQLQuoteLiteral(string(database)), data, variables)
+		} else {
+			stdout, stderr, err = exec.Exec(ctx, strings.NewReader(data), variables)
+		}
 		log.V(1).Info("applied init SQL", "stdout", stdout, "stderr", stderr)
 		return err
 	}
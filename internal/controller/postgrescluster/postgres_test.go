@@ -20,7 +20,10 @@ package postgrescluster
 
 import (
 	"context"
+	"crypto/md5"
+	"fmt"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -29,14 +32,17 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/internal/testing/events"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
@@ -153,6 +159,38 @@ func TestGeneratePostgresUserSecret(t *testing.T) {
 			assert.Equal(t, string(secret.Data["password"]), "asdf")
 			assert.Equal(t, string(secret.Data["verifier"]), "some$thing")
 		}
+
+		t.Run("PasswordType", func(t *testing.T) {
+			spec := spec.DeepCopy()
+			spec.PasswordType = "MD5"
+
+			// MD5 of the password and user name.
+			existing := &corev1.Secret{Data: map[string][]byte{
+				"password": []byte(`asdf`),
+				"verifier": []byte(`SCRAM-SHA-256$4096:some$thing`),
+			}}
+			secret, err := reconciler.generatePostgresUserSecret(cluster, spec, existing)
+			assert.NilError(t, err)
+
+			if assert.Check(t, secret != nil) {
+				assert.Equal(t, string(secret.Data["password"]), "asdf")
+				assert.Equal(t, string(secret.Data["verifier"]),
+					"md5"+fmt.Sprintf("%x", md5.Sum([]byte("asdfsome-user-name"))))
+			}
+
+			// SCRAM-SHA-256 again when the cluster calls for it.
+			cluster := cluster.DeepCopy()
+			cluster.Spec.Config.PasswordType = "SCRAM-SHA-256"
+			spec.PasswordType = ""
+
+			secret, err = reconciler.generatePostgresUserSecret(cluster, spec, secret)
+			assert.NilError(t, err)
+
+			if assert.Check(t, secret != nil) {
+				assert.Equal(t, string(secret.Data["password"]), "asdf")
+				assert.Assert(t, strings.HasPrefix(string(secret.Data["verifier"]), "SCRAM-SHA-256$"))
+			}
+		})
 	})
 
 	t.Run("Database", func(t *testing.T) {
@@ -166,6 +204,9 @@ func TestGeneratePostgresUserSecret(t *testing.T) {
 			assert.Assert(t, secret.Data["dbname"] == nil)
 			assert.Assert(t, secret.Data["uri"] == nil)
 			assert.Assert(t, secret.Data["jdbc-uri"] == nil)
+			assert.Assert(t, cmp.Regexp(
+				`^host='hippo2-primary.ns1.svc' port='9999' user='some-user-name' password='.+'$`,
+				string(secret.Data["dsn"])))
 		}
 
 		// Present when specified.
@@ -176,6 +217,9 @@ func TestGeneratePostgresUserSecret(t *testing.T) {
 
 		if assert.Check(t, secret != nil) {
 			assert.Equal(t, string(secret.Data["dbname"]), "db1")
+			assert.Assert(t, cmp.Regexp(
+				`^host='hippo2-primary.ns1.svc' port='9999' user='some-user-name' password='.+' dbname='db1'$`,
+				string(secret.Data["dsn"])))
 			assert.Assert(t, cmp.Regexp(
 				`^postgresql://some-user-name:[^@]+@hippo2-primary.ns1.svc:9999/db1$`,
 				string(secret.Data["uri"])))
@@ -216,6 +260,9 @@ func TestGeneratePostgresUserSecret(t *testing.T) {
 			assert.Equal(t, string(secret.Data["pgbouncer-port"]), "10220")
 			assert.Assert(t, secret.Data["pgbouncer-uri"] == nil)
 			assert.Assert(t, secret.Data["pgbouncer-jdbc-uri"] == nil)
+			assert.Assert(t, cmp.Regexp(
+				`^host='hippo2-pgbouncer.ns1.svc' port='10220' user='some-user-name' password='.+'$`,
+				string(secret.Data["pgbouncer-dsn"])))
 		}
 
 		// Includes a URI when possible.
@@ -233,10 +280,169 @@ func TestGeneratePostgresUserSecret(t *testing.T) {
 				`^jdbc:postgresql://hippo2-pgbouncer.ns1.svc:10220/yes`+
 					`[?]password=[^&]+&prepareThreshold=0&user=some-user-name$`,
 				string(secret.Data["pgbouncer-jdbc-uri"])))
+			assert.Assert(t, cmp.Regexp(
+				`^host='hippo2-pgbouncer.ns1.svc' .+ dbname='yes'$`,
+				string(secret.Data["pgbouncer-dsn"])))
 		}
 	})
 }
 
+func TestProvidedPostgresUserSecret(t *testing.T) {
+	ctx := context.Background()
+	_, tClient := setupKubernetes(t)
+	require.ParallelCapacity(t, 0)
+
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	recorder := events.NewRecorder(t, scheme)
+	reconciler := &Reconciler{Client: tClient, Recorder: recorder}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = setupNamespace(t, tClient).Name
+	cluster.Name = "byo"
+	cluster.Generation = 1
+
+	user := &v1beta1.PostgresUserSpec{Name: "provided", SecretName: "from-vault"}
+	existing := &corev1.Secret{Data: map[string][]byte{
+		"password": []byte("before"),
+		"verifier": []byte("SCRAM-SHA-256$before"),
+	}}
+
+	t.Run("Missing", func(t *testing.T) {
+		secret, err := reconciler.providedPostgresUserSecret(ctx, cluster, user, existing)
+		assert.NilError(t, err)
+		assert.Assert(t, secret == existing, "expected the current password")
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Reason, "InvalidUserSecret")
+		assert.Assert(t, cmp.Contains(recorder.Events[0].Note, "not found"))
+
+		t.Run("NoPassword", func(t *testing.T) {
+			secret, err := reconciler.providedPostgresUserSecret(ctx, cluster, user, nil)
+			assert.NilError(t, err)
+			assert.Assert(t, secret == nil, "expected no password to be generated")
+		})
+
+		t.Run("Reported", func(t *testing.T) {
+			cluster := cluster.DeepCopy()
+			cluster.Status.ObservedGeneration = cluster.Generation
+			recorder.Events = nil
+
+			_, err := reconciler.providedPostgresUserSecret(ctx, cluster, user, existing)
+			assert.NilError(t, err)
+			assert.Equal(t, len(recorder.Events), 0, "expected no repeated event")
+		})
+	})
+
+	provided := &corev1.Secret{Data: map[string][]byte{"password": []byte("before")}}
+	provided.Namespace, provided.Name = cluster.Namespace, "from-vault"
+	assert.NilError(t, tClient.Create(ctx, provided))
+
+	t.Run("SamePassword", func(t *testing.T) {
+		secret, err := reconciler.providedPostgresUserSecret(ctx, cluster, user, existing)
+		assert.NilError(t, err)
+		assert.Equal(t, string(secret.Data["password"]), "before")
+		assert.Equal(t, string(secret.Data["verifier"]), "SCRAM-SHA-256$before")
+	})
+
+	t.Run("ChangedPassword", func(t *testing.T) {
+		provided.Data["password"] = []byte("after")
+		assert.NilError(t, tClient.Update(ctx, provided))
+
+		secret, err := reconciler.providedPostgresUserSecret(ctx, cluster, user, existing)
+		assert.NilError(t, err)
+		assert.Equal(t, string(secret.Data["password"]), "after")
+		assert.Assert(t, len(secret.Data["verifier"]) == 0, "expected a new verifier")
+
+		// The generated Secret has a verifier of the provided password.
+		generated, err := reconciler.generatePostgresUserSecret(cluster, user, secret)
+		assert.NilError(t, err)
+		assert.Equal(t, string(generated.Data["password"]), "after")
+		assert.Assert(t, len(generated.Data["verifier"]) > 0)
+	})
+}
+
+func TestObserveLocaleSupport(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Generation = 2
+	cluster.Spec.PostgresVersion = 15
+
+	startup := func(name string, code int32, message string) *Instance {
+		pod := new(corev1.Pod)
+		pod.Name = name
+		pod.Status.InitContainerStatuses = []corev1.ContainerStatus{{
+			Name: naming.ContainerPostgresStartup,
+			State: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{ExitCode: code, Message: message},
+			},
+		}}
+		return &Instance{Pods: []*corev1.Pod{pod}}
+	}
+
+	t.Run("NoICU", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.Conditions = []metav1.Condition{{Type: ConditionPostgresLocaleSupported}}
+
+		observeLocaleSupport(cluster, &observedInstances{forCluster: []*Instance{
+			startup("pod-0", 1, postgres.ICUUnsupportedMessage),
+		}})
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
+			ConditionPostgresLocaleSupported) == nil)
+	})
+
+	cluster.Spec.Config.Locale = &v1beta1.PostgresLocaleSpec{
+		Provider: v1beta1.PostgresLocaleProviderICU, ICULocale: "und-u-ks-level2",
+	}
+
+	t.Run("Unknown", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+
+		observeLocaleSupport(cluster, &observedInstances{forCluster: []*Instance{
+			{Pods: []*corev1.Pod{{}}},
+			startup("pod-1", 1, "Expected PostgreSQL version"),
+		}})
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
+			ConditionPostgresLocaleSupported) == nil)
+	})
+
+	t.Run("Supported", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+
+		observeLocaleSupport(cluster, &observedInstances{forCluster: []*Instance{
+			startup("pod-0", 0, ""),
+		}})
+		condition := meta.FindStatusCondition(cluster.Status.Conditions,
+			ConditionPostgresLocaleSupported)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Equal(t, condition.Reason, "ICUSupported")
+		assert.Equal(t, condition.ObservedGeneration, int64(2))
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+
+		observeLocaleSupport(cluster, &observedInstances{forCluster: []*Instance{
+			startup("pod-0", 0, ""),
+			startup("pod-1", 1, "...\n"+postgres.ICUUnsupportedMessage+"\n"),
+		}})
+		condition := meta.FindStatusCondition(cluster.Status.Conditions,
+			ConditionPostgresLocaleSupported)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "ICUUnsupported")
+		assert.Equal(t, condition.Message,
+			"Pod pod-1: PostgreSQL in this image does not support the icu locale provider")
+	})
+}
+
+func TestLibpqKeywordValue(t *testing.T) {
+	assert.Equal(t, libpqKeywordValue(), "")
+	assert.Equal(t, libpqKeywordValue("host", "h", "port", "5432"), `host='h' port='5432'`)
+	assert.Equal(t, libpqKeywordValue("password", `a b'c\d`), `password='a b\'c\\d'`)
+}
+
 func TestReconcilePostgresVolumes(t *testing.T) {
 	ctx := context.Background()
 	_, tClient := setupKubernetes(t)
@@ -287,6 +493,48 @@ volumeMode: Filesystem
 		`))
 	})
 
+	t.Run("TablespaceVolumes", func(t *testing.T) {
+		pvcs, err := reconciler.reconcileTablespaceVolumes(ctx, cluster, spec, instance, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, len(pvcs) == 0)
+
+		spec := spec.DeepCopy()
+		assert.NilError(t, yaml.Unmarshal([]byte(`{
+			tablespaceVolumes: [{
+				name: fast,
+				dataVolumeClaimSpec: {
+					accessModes: [ReadWriteOnce],
+					resources: { requests: { storage: 3Gi } },
+					storageClassName: "storage-class-for-ssd",
+				},
+			}],
+		}`), spec))
+
+		pvcs, err = reconciler.reconcileTablespaceVolumes(ctx, cluster, spec, instance, nil)
+		assert.NilError(t, err)
+		assert.Equal(t, len(pvcs), 1)
+
+		pvc := pvcs[0]
+		assert.Assert(t, metav1.IsControlledBy(pvc, cluster))
+		assert.Equal(t, pvc.Name, instance.Name+"-fast-tablespace")
+
+		assert.Equal(t, pvc.Labels[naming.LabelCluster], cluster.Name)
+		assert.Equal(t, pvc.Labels[naming.LabelInstance], instance.Name)
+		assert.Equal(t, pvc.Labels[naming.LabelInstanceSet], spec.Name)
+		assert.Equal(t, pvc.Labels[naming.LabelRole], "pgtablespace")
+		assert.Equal(t, pvc.Labels[naming.LabelTablespace], "fast")
+
+		assert.Assert(t, marshalMatches(pvc.Spec, `
+accessModes:
+- ReadWriteOnce
+resources:
+  requests:
+    storage: 3Gi
+storageClassName: storage-class-for-ssd
+volumeMode: Filesystem
+		`))
+	})
+
 	t.Run("WALVolume", func(t *testing.T) {
 		observed := &Instance{}
 
@@ -552,6 +800,7 @@ func TestReconcileDatabaseInitSQL(t *testing.T) {
 func TestReconcileDatabaseInitSQLConfigMap(t *testing.T) {
 	ctx := context.Background()
 	var called bool
+	var calledWith []string
 
 	// Test Environment Setup
 	_, client := setupKubernetes(t)
@@ -564,7 +813,7 @@ func TestReconcileDatabaseInitSQLConfigMap(t *testing.T) {
 		// call would have been made
 		PodExec: func(namespace, pod, container string, stdin io.Reader, stdout,
 			stderr io.Writer, command ...string) error {
-			called = true
+			called, calledWith = true, command
 			return nil
 		},
 	}
@@ -659,5 +908,64 @@ func TestReconcileDatabaseInitSQLConfigMap(t *testing.T) {
 
 		assert.NilError(t, r.reconcileDatabaseInitSQL(ctx, cluster, observed))
 		assert.Assert(t, called)
+		assert.Assert(t, !strings.Contains(strings.Join(calledWith, "\n"), "ON_ERROR_STOP"),
+			"expected a single key to continue past errors by default")
+	})
+
+	t.Run("found SQL files", func(t *testing.T) {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "found-sql-files",
+				Namespace: ns.Name,
+			},
+			Data: map[string]string{
+				"b.sql":     "SELECT 'b';",
+				"a.sql":     "SELECT :'who';",
+				"README.md": "ignored",
+			},
+		}
+		assert.NilError(t, client.Create(ctx, cm))
+
+		var stdin string
+		var command []string
+		r := &Reconciler{
+			Client: client,
+			PodExec: func(namespace, pod, container string, in io.Reader, stdout,
+				stderr io.Writer, args ...string) error {
+				b, _ := io.ReadAll(in)
+				stdin, command = string(b), args
+				return nil
+			},
+		}
+
+		cluster := testCluster.DeepCopy()
+		cluster.Spec.DatabaseInitSQL = &v1beta1.DatabaseInitSQL{
+			Name:      cm.Name,
+			Database:  "zoo",
+			Variables: map[string]string{"who": "hippo"},
+		}
+
+		assert.NilError(t, r.reconcileDatabaseInitSQL(ctx, cluster, observed))
+		assert.Equal(t, stdin, "SELECT :'who';\nSELECT 'b';")
+		assert.Assert(t, cmp.Contains(strings.Join(command, "\n"), `SELECT 'zoo'`))
+		assert.Assert(t, cmp.Contains(strings.Join(command, "\n"), `--set=ON_ERROR_STOP=on`))
+		assert.Assert(t, cmp.Contains(strings.Join(command, "\n"), `--set=who=hippo`))
+	})
+
+	t.Run("found no SQL files", func(t *testing.T) {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "found-no-sql-files",
+				Namespace: ns.Name,
+			},
+			Data: map[string]string{"init.txt": "SELECT 1;"},
+		}
+		assert.NilError(t, client.Create(ctx, cm))
+
+		cluster := testCluster.DeepCopy()
+		cluster.Spec.DatabaseInitSQL = &v1beta1.DatabaseInitSQL{Name: cm.Name}
+
+		err := r.reconcileDatabaseInitSQL(ctx, cluster, observed)
+		assert.ErrorContains(t, err, "did not contain any keys ending in .sql")
 	})
 }
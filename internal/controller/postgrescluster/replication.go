@@ -0,0 +1,314 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// EventReplicationSlotLost is the event reason utilized when PostgreSQL
+// removes WAL that a replication slot still needs.
+const EventReplicationSlotLost = "ReplicationSlotLost"
+
+// observeReplicationSlots asks PostgreSQL in pod for its replication slots and
+// the WAL each one retains.
+func (r *Reconciler) observeReplicationSlots(
+	ctx context.Context, pod *corev1.Pod,
+) ([]v1beta1.ReplicationSlotStatus, error) {
+	exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	}
+
+	// The "wal_status" column is new in PostgreSQL 13, so read it from JSON
+	// rather than by name.
+	// - https://www.postgresql.org/docs/current/view-pg-replication-slots.html
+	stdout, stderr, err := postgres.Executor(exec).Exec(ctx, strings.NewReader(`
+		\pset format unaligned
+		\pset tuples_only on
+		SELECT COALESCE(pg_catalog.json_agg(pg_catalog.json_build_object(
+		  'name', s.slot_name,
+		  'type', s.slot_type,
+		  'active', s.active,
+		  'retainedBytes', pg_catalog.pg_wal_lsn_diff(pg_catalog.pg_current_wal_lsn(), s.restart_lsn),
+		  'walStatus', pg_catalog.to_jsonb(s) ->> 'wal_status'
+		) ORDER BY s.slot_name), '[]')
+		FROM pg_catalog.pg_replication_slots s`),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful commands to stdout.
+		})
+
+	logging.FromContext(ctx).V(1).Info("observed replication slots", "stdout", stdout, "stderr", stderr)
+
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return parseReplicationSlots(stdout)
+}
+
+// parseReplicationSlots decodes the JSON printed by psql.
+func parseReplicationSlots(stdout string) ([]v1beta1.ReplicationSlotStatus, error) {
+	var rows []struct {
+		Name, Type, WALStatus string
+		Active                bool
+		RetainedBytes         *float64
+	}
+	if err := json.Unmarshal([]byte(stdout), &rows); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	slots := make([]v1beta1.ReplicationSlotStatus, len(rows))
+	for i, row := range rows {
+		slots[i] = v1beta1.ReplicationSlotStatus{
+			Name:      row.Name,
+			Type:      row.Type,
+			Active:    row.Active,
+			WALStatus: row.WALStatus,
+		}
+		if row.RetainedBytes != nil {
+			slots[i].RetainedWAL = resource.NewQuantity(int64(*row.RetainedBytes), resource.BinarySI)
+		}
+	}
+	return slots, nil
+}
+
+// reconcileReplicationSlotStatus populates cluster.Status.ReplicationSlots
+// while the cluster enables logical decoding and clears it otherwise. It warns
+// when a slot loses WAL that its client has not consumed. It keeps the last
+// observation when the primary cannot be asked.
+func (r *Reconciler) reconcileReplicationSlotStatus(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) reconcile.Result {
+	if cluster.Spec.Replication == nil || cluster.Spec.Replication.LogicalDecoding == nil {
+		cluster.Status.ReplicationSlots = nil
+		return reconcile.Result{}
+	}
+
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if pod == nil {
+		// There is no primary to ask; keep the last observation.
+		return reconcile.Result{}
+	}
+
+	slots, err := r.observeReplicationSlots(ctx, pod)
+	if err != nil {
+		logging.FromContext(ctx).Error(err, "unable to observe replication slots")
+	} else {
+		previous := make(map[string]string, len(cluster.Status.ReplicationSlots))
+		for _, slot := range cluster.Status.ReplicationSlots {
+			previous[slot.Name] = slot.WALStatus
+		}
+		for _, slot := range slots {
+			if slot.WALStatus == "lost" && previous[slot.Name] != "lost" {
+				r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventReplicationSlotLost,
+					"Replication slot %q retained more WAL than allowed and is no longer usable",
+					slot.Name)
+			}
+		}
+		cluster.Status.ReplicationSlots = slots
+	}
+
+	// Nothing signals when clients consume from their slots, so observe them
+	// again periodically.
+	return reconcile.Result{RequeueAfter: time.Minute}
+}
+
+// defaultMaxReplicaLag is how far a replica can be behind the primary when
+// the spec does not say.
+var defaultMaxReplicaLag = resource.MustParse("50Mi")
+
+// observeReplicationLag asks PostgreSQL in pod how far behind each of its
+// streaming replicas is. The result is keyed by the name of the replica's Pod.
+func (r *Reconciler) observeReplicationLag(
+	ctx context.Context, pod *corev1.Pod,
+) (map[string]v1beta1.ReplicaLagStatus, error) {
+	exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	}
+
+	// Patroni sets "application_name" of each replica to its member name,
+	// which is the name of its Pod.
+	// - https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-REPLICATION-VIEW
+	stdout, stderr, err := postgres.Executor(exec).Exec(ctx, strings.NewReader(`
+		\pset format unaligned
+		\pset tuples_only on
+		SELECT COALESCE(pg_catalog.json_agg(pg_catalog.json_build_object(
+		  'name', r.application_name,
+		  'lagBytes', pg_catalog.pg_wal_lsn_diff(pg_catalog.pg_current_wal_lsn(), r.replay_lsn),
+		  'lagSeconds', EXTRACT(EPOCH FROM r.replay_lag)
+		) ORDER BY r.application_name), '[]')
+		FROM pg_catalog.pg_stat_replication r`),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful commands to stdout.
+		})
+
+	logging.FromContext(ctx).V(1).Info("observed replication lag", "stdout", stdout, "stderr", stderr)
+
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return parseReplicationLag(stdout)
+}
+
+// parseReplicationLag decodes the JSON printed by psql.
+func parseReplicationLag(stdout string) (map[string]v1beta1.ReplicaLagStatus, error) {
+	var rows []struct {
+		Name       string
+		LagBytes   *float64
+		LagSeconds *float64
+	}
+	if err := json.Unmarshal([]byte(stdout), &rows); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	lags := make(map[string]v1beta1.ReplicaLagStatus, len(rows))
+	for _, row := range rows {
+		var lag v1beta1.ReplicaLagStatus
+		if row.LagBytes != nil {
+			lag.Lag = resource.NewQuantity(int64(*row.LagBytes), resource.BinarySI)
+		}
+		if row.LagSeconds != nil {
+			lag.LagTime = &metav1.Duration{
+				Duration: time.Duration(*row.LagSeconds * float64(time.Second)),
+			}
+		}
+		lags[row.Name] = lag
+	}
+	return lags, nil
+}
+
+// reconcileReplicationLagStatus records how far each replica of cluster is
+// behind the primary and sets the ReplicationHealthy condition accordingly.
+// Running replicas that are not streaming from the primary are unhealthy. It
+// keeps the last observation when the primary cannot be asked.
+func (r *Reconciler) reconcileReplicationLagStatus(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) reconcile.Result {
+	if cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled {
+		// The leader of a standby cluster is itself replaying WAL from
+		// elsewhere. See [Reconciler.reconcileStandbyStatus].
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.ReplicationHealthy)
+		return reconcile.Result{}
+	}
+
+	pod, primary := instances.writablePod(naming.ContainerDatabase)
+	if pod == nil {
+		// There is no primary to ask; keep the last observation.
+		return reconcile.Result{}
+	}
+
+	lags, err := r.observeReplicationLag(ctx, pod)
+	if err != nil {
+		logging.FromContext(ctx).Error(err, "unable to observe replication lag")
+		return reconcile.Result{RequeueAfter: time.Minute}
+	}
+
+	threshold := defaultMaxReplicaLag
+	if cluster.Spec.Replication != nil && cluster.Spec.Replication.MaxReplicaLag != nil {
+		threshold = *cluster.Spec.Replication.MaxReplicaLag
+	}
+
+	var disconnected, lagging []string
+	var replicas int
+	var worst int64
+	for i := range cluster.Status.InstanceSets {
+		set := &cluster.Status.InstanceSets[i]
+		set.ReplicaLag = nil
+
+		for _, instance := range instances.bySet[set.Name] {
+			if instance == primary || len(instance.Pods) == 0 {
+				continue
+			}
+			lag, ok := lags[instance.Pods[0].Name]
+			if !ok {
+				// A replica that is running should be streaming. One that is
+				// not running is reported by the InstanceSets status.
+				if running, known := instance.IsRunning(naming.ContainerDatabase); running && known {
+					disconnected = append(disconnected, instance.Name)
+					replicas++
+				}
+				continue
+			}
+
+			lag.Name = instance.Name
+			set.ReplicaLag = append(set.ReplicaLag, lag)
+			replicas++
+
+			if lag.Lag != nil {
+				if lag.Lag.Cmp(threshold) > 0 {
+					lagging = append(lagging, instance.Name)
+				}
+				if lag.Lag.Value() > worst {
+					worst = lag.Lag.Value()
+				}
+			}
+		}
+	}
+	clusterReplicaLag.WithLabelValues(cluster.Namespace, cluster.Name).Set(float64(worst))
+
+	condition := metav1.Condition{
+		Type:   v1beta1.ReplicationHealthy,
+		Status: metav1.ConditionTrue,
+		Reason: "ReplicasStreaming",
+		Message: "Every streaming replica is within " + threshold.String() +
+			" of the primary.",
+
+		ObservedGeneration: cluster.GetGeneration(),
+	}
+	if len(lagging) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ReplicaLagging"
+		condition.Message = "Replicas are more than " + threshold.String() +
+			" behind the primary: " + strings.Join(lagging, ", ")
+	}
+	if len(disconnected) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ReplicaNotStreaming"
+		condition.Message = "Replicas are running but not streaming from the primary: " +
+			strings.Join(disconnected, ", ")
+		if len(lagging) > 0 {
+			condition.Message += ". Replicas are more than " + threshold.String() +
+				" behind the primary: " + strings.Join(lagging, ", ")
+		}
+	}
+
+	if replicas == 0 {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.ReplicationHealthy)
+	} else {
+		meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+	}
+
+	// Nothing signals when replicas fall behind, so observe them again
+	// periodically.
+	return reconcile.Result{RequeueAfter: time.Minute}
+}
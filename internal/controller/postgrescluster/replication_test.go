@@ -0,0 +1,245 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestParseReplicationSlots(t *testing.T) {
+	_, err := parseReplicationSlots("")
+	assert.Assert(t, err != nil, "expected an error for empty output")
+
+	slots, err := parseReplicationSlots("[]\n")
+	assert.NilError(t, err)
+	assert.Equal(t, len(slots), 0)
+
+	slots, err = parseReplicationSlots(`[` +
+		`{"name" : "cdc", "type" : "logical", "active" : true, "retainedBytes" : 16777216, "walStatus" : "reserved"}, ` +
+		`{"name" : "old", "type" : "logical", "active" : false, "retainedBytes" : null, "walStatus" : null}]` + "\n")
+	assert.NilError(t, err)
+	assert.Equal(t, len(slots), 2)
+	assert.Equal(t, slots[0].Name, "cdc")
+	assert.Equal(t, slots[0].Type, "logical")
+	assert.Assert(t, slots[0].Active)
+	assert.Equal(t, slots[0].RetainedWAL.String(), "16Mi")
+	assert.Equal(t, slots[0].WALStatus, "reserved")
+	assert.Assert(t, slots[1].RetainedWAL == nil)
+	assert.Equal(t, slots[1].WALStatus, "")
+}
+
+func TestReconcileReplicationSlotStatus(t *testing.T) {
+	ctx := context.Background()
+
+	primary := &Instance{
+		Name: "primary",
+		Pods: []*corev1.Pod{{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "primary",
+				Annotations: map[string]string{"status": `{"role":"master"}`},
+			},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "database", State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+			}}},
+		}},
+	}
+
+	output := `[{"name" : "cdc", "type" : "logical", "walStatus" : "lost"}]`
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Recorder: recorder,
+		PodExec: func(
+			namespace, pod, container string,
+			stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Equal(t, pod, "primary")
+
+			b, _ := io.ReadAll(stdin)
+			assert.Assert(t, strings.Contains(string(b), "pg_replication_slots"))
+
+			_, err := stdout.Write([]byte(output))
+			return err
+		},
+	}
+
+	cluster := new(v1beta1.PostgresCluster)
+	instances := &observedInstances{forCluster: []*Instance{primary}}
+
+	// Nothing is observed without logical decoding.
+	result := reconciler.reconcileReplicationSlotStatus(ctx, cluster, instances)
+	assert.Equal(t, result.RequeueAfter, time.Duration(0))
+	assert.Assert(t, cluster.Status.ReplicationSlots == nil)
+
+	cluster.Spec.Replication = &v1beta1.PostgresReplicationSpec{
+		LogicalDecoding: &v1beta1.LogicalDecodingSpec{},
+	}
+	result = reconciler.reconcileReplicationSlotStatus(ctx, cluster, instances)
+	assert.Assert(t, result.RequeueAfter > 0, "expected to observe again")
+	assert.Equal(t, len(cluster.Status.ReplicationSlots), 1)
+	assert.Equal(t, cluster.Status.ReplicationSlots[0].WALStatus, "lost")
+	assert.Equal(t, len(recorder.Events), 1)
+	assert.Assert(t, strings.Contains(<-recorder.Events, "ReplicationSlotLost"))
+
+	// The warning happens once per lost slot.
+	reconciler.reconcileReplicationSlotStatus(ctx, cluster, instances)
+	assert.Equal(t, len(recorder.Events), 0)
+
+	// The last observation remains when the primary cannot be asked.
+	output = `not json`
+	result = reconciler.reconcileReplicationSlotStatus(ctx, cluster, instances)
+	assert.Assert(t, result.RequeueAfter > 0, "expected to observe again")
+	assert.Equal(t, len(cluster.Status.ReplicationSlots), 1)
+
+	// Status is cleared when logical decoding is disabled.
+	cluster.Spec.Replication.LogicalDecoding = nil
+	reconciler.reconcileReplicationSlotStatus(ctx, cluster, instances)
+	assert.Assert(t, cluster.Status.ReplicationSlots == nil)
+}
+
+func TestParseReplicationLag(t *testing.T) {
+	_, err := parseReplicationLag("")
+	assert.Assert(t, err != nil, "expected an error for empty output")
+
+	lags, err := parseReplicationLag(`[` +
+		`{"name" : "pod-a-0", "lagBytes" : 1048576, "lagSeconds" : 1.5}, ` +
+		`{"name" : "pod-b-0", "lagBytes" : null, "lagSeconds" : null}]` + "\n")
+	assert.NilError(t, err)
+	assert.Equal(t, len(lags), 2)
+	assert.Equal(t, lags["pod-a-0"].Lag.String(), "1Mi")
+	assert.Equal(t, lags["pod-a-0"].LagTime.Duration, 1500*time.Millisecond)
+	assert.Assert(t, lags["pod-b-0"].Lag == nil)
+	assert.Assert(t, lags["pod-b-0"].LagTime == nil)
+}
+
+func TestReconcileReplicationLagStatus(t *testing.T) {
+	ctx := context.Background()
+
+	pod := func(name, role string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				Annotations: map[string]string{"status": `{"role":"` + role + `"}`},
+			},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "database", State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+			}}},
+		}
+	}
+	primary := &Instance{Name: "one-a", Pods: []*corev1.Pod{pod("one-a-0", "master")}}
+	replica := &Instance{Name: "one-b", Pods: []*corev1.Pod{pod("one-b-0", "replica")}}
+
+	var output string
+	reconciler := &Reconciler{
+		PodExec: func(
+			namespace, pod, container string,
+			stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Equal(t, pod, "one-a-0")
+
+			b, _ := io.ReadAll(stdin)
+			assert.Assert(t, strings.Contains(string(b), "pg_stat_replication"))
+
+			_, err := stdout.Write([]byte(output))
+			return err
+		},
+	}
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{{Name: "one"}}
+	instances := &observedInstances{
+		forCluster: []*Instance{primary, replica},
+		bySet:      map[string][]*Instance{"one": {primary, replica}},
+	}
+
+	t.Run("NoReplicas", func(t *testing.T) {
+		output = `[]`
+		instances := &observedInstances{
+			forCluster: []*Instance{primary},
+			bySet:      map[string][]*Instance{"one": {primary}},
+		}
+		result := reconciler.reconcileReplicationLagStatus(ctx, cluster, instances)
+		assert.Assert(t, result.RequeueAfter > 0, "expected to observe again")
+		assert.Assert(t, cluster.Status.InstanceSets[0].ReplicaLag == nil)
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
+			v1beta1.ReplicationHealthy) == nil)
+	})
+
+	t.Run("NotStreaming", func(t *testing.T) {
+		output = `[]`
+		reconciler.reconcileReplicationLagStatus(ctx, cluster, instances)
+		assert.Assert(t, cluster.Status.InstanceSets[0].ReplicaLag == nil)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.ReplicationHealthy)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "ReplicaNotStreaming")
+		assert.Assert(t, strings.Contains(condition.Message, "one-b"))
+	})
+
+	t.Run("Healthy", func(t *testing.T) {
+		output = `[{"name" : "one-b-0", "lagBytes" : 1024, "lagSeconds" : 0.1}]`
+		reconciler.reconcileReplicationLagStatus(ctx, cluster, instances)
+		assert.Equal(t, len(cluster.Status.InstanceSets[0].ReplicaLag), 1)
+		assert.Equal(t, cluster.Status.InstanceSets[0].ReplicaLag[0].Name, "one-b")
+		assert.Equal(t, cluster.Status.InstanceSets[0].ReplicaLag[0].Lag.String(), "1Ki")
+		assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions,
+			v1beta1.ReplicationHealthy))
+	})
+
+	t.Run("Lagging", func(t *testing.T) {
+		output = `[{"name" : "one-b-0", "lagBytes" : 1048576, "lagSeconds" : 30}]`
+		cluster.Spec.Replication = &v1beta1.PostgresReplicationSpec{
+			MaxReplicaLag: resource.NewQuantity(1024, resource.BinarySI),
+		}
+		reconciler.reconcileReplicationLagStatus(ctx, cluster, instances)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.ReplicationHealthy)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "ReplicaLagging")
+		assert.Assert(t, strings.Contains(condition.Message, "one-b"))
+		assert.Assert(t, clusterIsDegraded(cluster))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		output = `not json`
+		before := cluster.Status.DeepCopy()
+		result := reconciler.reconcileReplicationLagStatus(ctx, cluster, instances)
+		assert.Assert(t, result.RequeueAfter > 0, "expected to observe again")
+		assert.DeepEqual(t, cluster.Status, *before)
+	})
+
+	t.Run("Standby", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: true}
+		reconciler.reconcileReplicationLagStatus(ctx, cluster, instances)
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
+			v1beta1.ReplicationHealthy) == nil)
+	})
+}
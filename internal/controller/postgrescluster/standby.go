@@ -0,0 +1,391 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/patroni"
+	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// EventStandbyCaughtUp is the event reason utilized when a standby cluster
+	// has replayed all the WAL of its former primary and is being promoted.
+	EventStandbyCaughtUp = "StandbyCaughtUp"
+
+	// EventStandbyPromoted is the event reason utilized when a standby cluster
+	// has become a primary and accepts writes.
+	EventStandbyPromoted = "StandbyPromoted"
+
+	// EventStandbyDemoted is the event reason utilized when a former primary
+	// has been demoted and follows another cluster.
+	EventStandbyDemoted = "StandbyDemoted"
+)
+
+// validateStandby returns an error when cluster is a standby cluster that
+// cannot follow its primary.
+func validateStandby(cluster *v1beta1.PostgresCluster) error {
+	standby := cluster.Spec.Standby
+	if standby == nil || !standby.Enabled {
+		return nil
+	}
+
+	path := field.NewPath("spec", "standby")
+
+	// When a standby cluster is requested but a repoName or host is not provided
+	// the cluster will be created as a non-standby.
+	if standby.Host == "" && standby.RepoName == "" {
+		return field.Invalid(path, cluster.Name, "Standby requires a host or repoName to be enabled")
+	}
+
+	// A standby that streams from another cluster authenticates with its
+	// replication certificate and verifies the other server with the same
+	// certificate authority. Generated certificates are trusted only within
+	// a namespace, so custom certificates are necessary everywhere else, and
+	// they work only as a pair.
+	if standby.Host != "" &&
+		(cluster.Spec.CustomTLSSecret == nil) != (cluster.Spec.CustomReplicationClientTLSSecret == nil) {
+		return field.Invalid(path.Child("host"), standby.Host,
+			"Streaming replication requires both customTLSSecret and customReplicationTLSSecret or neither")
+	}
+
+	return nil
+}
+
+// standbyLeader returns the Pod of the Patroni leader when it is following
+// another cluster. It returns false when no leader has been observed.
+func standbyLeader(instances *observedInstances) (*corev1.Pod, bool) {
+	var known bool
+	for _, instance := range instances.forCluster {
+		if len(instance.Pods) != 1 {
+			continue
+		}
+		if patroni.PodIsStandbyLeader(instance.Pods[0]) {
+			return instance.Pods[0], true
+		}
+		if writable, ok := instance.IsWritable(); writable && ok {
+			known = true
+		}
+	}
+	return nil, known
+}
+
+// standbyReplay describes how far a standby is from having applied all the
+// WAL of its primary.
+type standbyReplay string
+
+const (
+	standbyReplayStreaming standbyReplay = "streaming"
+	standbyReplayBehind    standbyReplay = "behind"
+	standbyReplayRestoring standbyReplay = "restoring"
+	standbyReplayCaughtUp  standbyReplay = "caught-up"
+)
+
+// standbyReplayFile is a common table expression, "replay", with one column,
+// "wal_file", that names the WAL file containing the replay location.
+// PostgreSQL cannot name WAL files during recovery, so calculate the name from
+// the replay location, the WAL segment size, and the timeline of the last
+// checkpoint. That timeline can be older than the one being replayed.
+// - https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-RECOVERY-INFO-TABLE
+// - https://git.postgresql.org/gitweb/?p=postgresql.git;f=src/include/access/xlog_internal.h;hb=REL_13_0#l153
+const standbyReplayFile = `
+		replay AS (
+		  SELECT pg_catalog.upper(
+		    pg_catalog.lpad(pg_catalog.to_hex(timeline), 8, '0') ||
+		    pg_catalog.lpad(pg_catalog.to_hex(pg_catalog.div(bytes, 4294967296)::bigint), 8, '0') ||
+		    pg_catalog.lpad(pg_catalog.to_hex(pg_catalog.div(pg_catalog.mod(bytes, 4294967296), segment)::bigint), 8, '0')
+		  ) AS wal_file
+		  FROM (SELECT
+		    pg_catalog.pg_last_wal_replay_lsn() - '0/0' AS bytes,
+		    pg_catalog.pg_size_bytes(pg_catalog.current_setting('wal_segment_size')) AS segment,
+		    (SELECT timeline_id FROM pg_catalog.pg_control_checkpoint()) AS timeline
+		  ) AS location
+		)`
+
+// observeStandbyReplay asks PostgreSQL in pod whether it is still connected
+// to its primary and whether it has replayed what it received. When cluster
+// follows a pgBackRest repository, it also asks pgBackRest for the newest WAL
+// archived there.
+func (r *Reconciler) observeStandbyReplay(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, pod *corev1.Pod,
+) (standbyReplay, error) {
+	exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	}
+
+	// A WAL receiver in any state, including one that is still connecting,
+	// means the former primary may be reachable. A standby that follows a
+	// pgBackRest repository has no WAL receiver and no receive location, so
+	// also name the WAL file that contains the replay location.
+	// - https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-WAL-RECEIVER-VIEW
+	// - https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-RECOVERY-INFO-TABLE
+	stdout, stderr, err := postgres.Executor(exec).Exec(ctx, strings.NewReader(`
+		WITH `+standbyReplayFile+`
+		SELECT CASE
+		  WHEN EXISTS (SELECT 1 FROM pg_catalog.pg_stat_wal_receiver)
+		  THEN 'streaming'
+		  WHEN pg_catalog.pg_last_wal_receive_lsn() > pg_catalog.pg_last_wal_replay_lsn()
+		  THEN 'behind'
+		  ELSE 'caught-up ' || wal_file
+		END FROM replay`),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful commands to stdout.
+		})
+
+	logging.FromContext(ctx).V(1).Info("observed standby replay", "stdout", stdout, "stderr", stderr)
+
+	replay, replayed := parseStandbyReplay(stdout)
+	if err != nil || replay != standbyReplayCaughtUp ||
+		cluster.Spec.Standby == nil || cluster.Spec.Standby.RepoName == "" {
+		return replay, errors.WithStack(err)
+	}
+
+	// The former primary may have archived more WAL than it sent, and a
+	// standby without a WAL receiver knows nothing about WAL it has not yet
+	// fetched. It is caught up once it has replayed into the newest WAL file
+	// in its repository. Compare only the segments of the files; the replay
+	// location is reported on the timeline of the last checkpoint.
+	info, err := pgbackrest.Executor(exec).Info(ctx)
+	if err != nil {
+		return "", err
+	}
+	if status := repoBackupsStatus(info, cluster.Spec.Standby.RepoName); status != nil &&
+		len(status.WALMax) == 24 {
+		if len(replayed) != 24 {
+			return "", errors.Errorf("unexpected WAL file from psql: %q", replayed)
+		}
+		if replayed[8:] < status.WALMax[8:] {
+			return standbyReplayRestoring, nil
+		}
+	}
+	return replay, nil
+}
+
+// parseStandbyReplay finds the standbyReplay and any WAL file that follows it
+// in the aligned output of psql. It returns empty values when there are none.
+func parseStandbyReplay(stdout string) (standbyReplay, string) {
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch value := standbyReplay(fields[0]); value {
+		case standbyReplayStreaming, standbyReplayBehind, standbyReplayCaughtUp:
+			return value, strings.Join(fields[1:], " ")
+		}
+	}
+	return "", ""
+}
+
+// reconcileStandbyPromotion compares the role Patroni reports to the one in
+// cluster.Spec.Standby and records any difference in the "Standby" condition.
+// It returns true when promotion must wait; the standby should keep following
+// its primary until it has applied everything that primary sent or archived
+// to its repository and is no longer connected to it. Promotion also waits when those checks fail, unless
+// cluster.Spec.Standby.ForcePromotion is true.
+func (r *Reconciler) reconcileStandbyPromotion(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) bool {
+	leader, known := standbyLeader(instances)
+	if !known {
+		// Patroni has not elected a leader yet; there is nothing to compare.
+		return false
+	}
+
+	enabled := cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled
+	force := cluster.Spec.Standby != nil && cluster.Spec.Standby.ForcePromotion != nil &&
+		*cluster.Spec.Standby.ForcePromotion
+	condition := metav1.Condition{
+		Type:               v1beta1.PostgresClusterStandby,
+		ObservedGeneration: cluster.GetGeneration(),
+	}
+
+	switch {
+	case enabled && leader != nil:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Following"
+		condition.Message = "PostgreSQL is applying WAL from another cluster."
+
+	case enabled:
+		// Patroni demotes the leader when the "standby_cluster" section
+		// appears in its dynamic configuration.
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Demoting"
+		condition.Message = "The primary is being demoted to follow another cluster."
+
+	case leader == nil:
+		// Clusters that were never a standby need no condition.
+		if meta.FindStatusCondition(cluster.Status.Conditions, condition.Type) == nil {
+			return false
+		}
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Promoted"
+		condition.Message = "PostgreSQL is accepting writes."
+
+	case force:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Promoting"
+		condition.Message = "The standby leader is being promoted without waiting for replay."
+
+	default:
+		replay, err := r.observeStandbyReplay(ctx, cluster, leader)
+		if err == nil && replay == "" {
+			err = errors.New("unexpected output from psql")
+		}
+
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "PromotionPending"
+
+		switch {
+		case err != nil:
+			// Keep following the former primary and reconcile everything else.
+			// The caller checks again soon.
+			logging.FromContext(ctx).Error(err, "unable to observe standby replay")
+			condition.Message = "Waiting to promote until " + leader.Name +
+				" reports its replay. Set spec.standby.forcePromotion to promote anyway."
+		case replay == standbyReplayStreaming:
+			condition.Message = "Waiting to promote until the former primary is stopped" +
+				" and " + leader.Name + " is no longer connected to it."
+		case replay == standbyReplayBehind:
+			condition.Message = "Waiting to promote until " + leader.Name +
+				" replays all the WAL it received."
+		case replay == standbyReplayRestoring:
+			condition.Message = "Waiting to promote until " + leader.Name +
+				" replays the newest WAL in " + cluster.Spec.Standby.RepoName + "."
+		case replay == standbyReplayCaughtUp:
+			condition.Reason = "Promoting"
+			condition.Message = "The standby leader is being promoted."
+		}
+	}
+
+	// Announce the steps that an external coordinator waits on when moving
+	// the primary from one cluster to another.
+	if previous := meta.FindStatusCondition(cluster.Status.Conditions, condition.Type); previous == nil ||
+		previous.Reason != condition.Reason {
+		switch {
+		case condition.Reason == "Promoting" && force:
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, EventStandbyCaughtUp,
+				"Standby leader "+leader.Name+" is being promoted without checking its replay")
+		case condition.Reason == "Promoting":
+			r.Recorder.Event(cluster, corev1.EventTypeNormal, EventStandbyCaughtUp,
+				"Standby leader "+leader.Name+" replayed all the WAL it received")
+		case condition.Reason == "Promoted":
+			r.Recorder.Event(cluster, corev1.EventTypeNormal, EventStandbyPromoted,
+				"PostgreSQL is accepting writes")
+		case condition.Reason == "Following" && previous != nil && previous.Reason == "Demoting":
+			r.Recorder.Event(cluster, corev1.EventTypeNormal, EventStandbyDemoted,
+				"PostgreSQL is following another cluster")
+		}
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+
+	return condition.Reason == "PromotionPending"
+}
+
+// observeStandbyStatus asks PostgreSQL in pod for the WAL it has received and
+// replayed while following another cluster.
+func (r *Reconciler) observeStandbyStatus(
+	ctx context.Context, pod *corev1.Pod,
+) (*v1beta1.PostgresStandbyStatus, error) {
+	exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	}
+
+	// Name the WAL file that contains the replay location only when there is
+	// no WAL receiver; that file came from the repository.
+	// - https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-RECOVERY-INFO-TABLE
+	stdout, stderr, err := postgres.Executor(exec).Exec(ctx, strings.NewReader(`
+		\pset format unaligned
+		\pset tuples_only on
+		WITH `+standbyReplayFile+`
+		SELECT pg_catalog.json_build_object(
+		  'observedAt', pg_catalog.clock_timestamp(),
+		  'receivedLSN', pg_catalog.pg_last_wal_receive_lsn(),
+		  'replayedLSN', pg_catalog.pg_last_wal_replay_lsn(),
+		  'lastReplayedTransaction', pg_catalog.pg_last_xact_replay_timestamp(),
+		  'lastRestoredWAL', CASE
+		    WHEN NOT EXISTS (SELECT 1 FROM pg_catalog.pg_stat_wal_receiver) THEN wal_file
+		  END
+		) FROM replay`),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful commands to stdout.
+		})
+
+	logging.FromContext(ctx).V(1).Info("observed standby status", "stdout", stdout, "stderr", stderr)
+
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return parseStandbyStatus(stdout)
+}
+
+// parseStandbyStatus decodes the JSON printed by psql and calculates the lag
+// between the last replayed transaction and the observation.
+func parseStandbyStatus(stdout string) (*v1beta1.PostgresStandbyStatus, error) {
+	status := new(v1beta1.PostgresStandbyStatus)
+	if err := json.Unmarshal([]byte(stdout), status); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if status.ObservedAt != nil && status.LastReplayedTransaction != nil {
+		lag := int64(status.ObservedAt.Sub(status.LastReplayedTransaction.Time).Seconds())
+		status.ReplayLagSeconds = &lag
+	}
+	return status, nil
+}
+
+// reconcileStandbyStatus populates cluster.Status.Standby while the cluster
+// follows another cluster and clears it otherwise. It keeps the last
+// observation when the leader cannot be asked.
+func (r *Reconciler) reconcileStandbyStatus(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) reconcile.Result {
+	leader, _ := standbyLeader(instances)
+	if leader == nil || cluster.Spec.Standby == nil || !cluster.Spec.Standby.Enabled {
+		cluster.Status.Standby = nil
+		return reconcile.Result{}
+	}
+
+	status, err := r.observeStandbyStatus(ctx, leader)
+	if err == nil {
+		cluster.Status.Standby = status
+	} else {
+		logging.FromContext(ctx).Error(err, "unable to observe standby replay")
+	}
+
+	// Nothing signals when the standby replays more WAL, so observe it again
+	// periodically.
+	return reconcile.Result{RequeueAfter: time.Minute}
+}
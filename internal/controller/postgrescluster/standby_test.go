@@ -0,0 +1,369 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestValidateStandby(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	assert.NilError(t, validateStandby(cluster))
+
+	cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: false}
+	assert.NilError(t, validateStandby(cluster))
+
+	t.Run("NoSource", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Standby.Enabled = true
+
+		assert.ErrorContains(t, validateStandby(cluster), "requires a host or repoName")
+	})
+
+	t.Run("Repository", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Standby.Enabled = true
+		cluster.Spec.Standby.RepoName = "repo1"
+		cluster.Spec.CustomTLSSecret = new(corev1.SecretProjection)
+
+		assert.NilError(t, validateStandby(cluster))
+	})
+
+	t.Run("Streaming", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Standby.Enabled = true
+		cluster.Spec.Standby.Host = "primary.example.com"
+
+		// Generated certificates in the same namespace.
+		assert.NilError(t, validateStandby(cluster))
+
+		cluster.Spec.CustomTLSSecret = new(corev1.SecretProjection)
+		assert.ErrorContains(t, validateStandby(cluster), "spec.standby.host")
+
+		cluster.Spec.CustomReplicationClientTLSSecret = new(corev1.SecretProjection)
+		assert.NilError(t, validateStandby(cluster))
+
+		cluster.Spec.CustomTLSSecret = nil
+		assert.ErrorContains(t, validateStandby(cluster), "both customTLSSecret")
+	})
+}
+
+func TestStandbyLeader(t *testing.T) {
+	pod := func(name, status string) *corev1.Pod {
+		pod := new(corev1.Pod)
+		pod.Name = name
+		pod.Annotations = map[string]string{"status": status}
+		return pod
+	}
+
+	leader, known := standbyLeader(&observedInstances{})
+	assert.Assert(t, leader == nil)
+	assert.Assert(t, !known, "expected unknown without instances")
+
+	replica := &Instance{Pods: []*corev1.Pod{pod("a", `{"role":"replica"}`)}}
+	leader, known = standbyLeader(&observedInstances{forCluster: []*Instance{replica}})
+	assert.Assert(t, leader == nil)
+	assert.Assert(t, !known, "expected unknown without a leader")
+
+	primary := &Instance{Pods: []*corev1.Pod{pod("b", `{"role":"master"}`)}}
+	leader, known = standbyLeader(&observedInstances{forCluster: []*Instance{replica, primary}})
+	assert.Assert(t, leader == nil)
+	assert.Assert(t, known)
+
+	standby := &Instance{Pods: []*corev1.Pod{pod("c", `{"role":"standby_leader"}`)}}
+	leader, known = standbyLeader(&observedInstances{forCluster: []*Instance{replica, standby}})
+	assert.Assert(t, leader != nil && leader.Name == "c")
+	assert.Assert(t, known)
+}
+
+func TestParseStandbyReplay(t *testing.T) {
+	replay, wal := parseStandbyReplay("")
+	assert.Equal(t, replay, standbyReplay(""))
+	assert.Equal(t, wal, "")
+
+	replay, wal = parseStandbyReplay(
+		"   case    \n-----------\n caught-up 000000010000000000000003\n(1 row)\n\n")
+	assert.Equal(t, replay, standbyReplayCaughtUp)
+	assert.Equal(t, wal, "000000010000000000000003")
+
+	replay, wal = parseStandbyReplay(
+		"   case    \n-----------\n streaming\n(1 row)\n\n")
+	assert.Equal(t, replay, standbyReplayStreaming)
+	assert.Equal(t, wal, "")
+}
+
+func TestReconcileStandbyPromotion(t *testing.T) {
+	ctx := context.Background()
+
+	standby := &Instance{Pods: []*corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "leader",
+			Annotations: map[string]string{"status": `{"role":"standby_leader"}`},
+		},
+	}}}
+	primary := &Instance{Pods: []*corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "leader",
+			Annotations: map[string]string{"status": `{"role":"master"}`},
+		},
+	}}}
+
+	replay := "caught-up"
+	var failure error
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Recorder: recorder,
+		PodExec: func(
+			namespace, pod, container string,
+			stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Equal(t, pod, "leader")
+			assert.Equal(t, container, "database")
+
+			if failure != nil {
+				return failure
+			}
+			if command[0] == "pgbackrest" {
+				_, err := stdout.Write([]byte(`[{"name":"db","archive":[` +
+					`{"database":{"id":1,"repo-key":1},"max":"000000010000000000000005"}]}]`))
+				return err
+			}
+
+			b, _ := io.ReadAll(stdin)
+			assert.Assert(t, strings.Contains(string(b), "pg_stat_wal_receiver"))
+			assert.Assert(t, strings.Contains(string(b), standbyReplayFile))
+
+			_, err := stdout.Write([]byte(" case\n------\n " + replay + "\n(1 row)\n"))
+			return err
+		},
+	}
+
+	reason := func(cluster *v1beta1.PostgresCluster) string {
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.PostgresClusterStandby)
+		if condition == nil {
+			return ""
+		}
+		return condition.Reason
+	}
+
+	t.Run("NeverStandby", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		pending := reconciler.reconcileStandbyPromotion(ctx, cluster,
+			&observedInstances{forCluster: []*Instance{primary}})
+		assert.Assert(t, !pending)
+		assert.Equal(t, reason(cluster), "")
+	})
+
+	t.Run("Following", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: true, RepoName: "repo1"}
+
+		pending := reconciler.reconcileStandbyPromotion(ctx, cluster,
+			&observedInstances{forCluster: []*Instance{standby}})
+		assert.Assert(t, !pending)
+		assert.Equal(t, reason(cluster), "Following")
+	})
+
+	t.Run("Demoting", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: true, RepoName: "repo1"}
+
+		pending := reconciler.reconcileStandbyPromotion(ctx, cluster,
+			&observedInstances{forCluster: []*Instance{primary}})
+		assert.Assert(t, !pending)
+		assert.Equal(t, reason(cluster), "Demoting")
+
+		pending = reconciler.reconcileStandbyPromotion(ctx, cluster,
+			&observedInstances{forCluster: []*Instance{standby}})
+		assert.Assert(t, !pending)
+		assert.Equal(t, reason(cluster), "Following")
+		assert.Equal(t, <-recorder.Events, "Normal StandbyDemoted PostgreSQL is following another cluster")
+	})
+
+	t.Run("Promoting", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: false, Host: "elsewhere"}
+		observed := &observedInstances{forCluster: []*Instance{standby}}
+
+		replay = "streaming"
+		pending := reconciler.reconcileStandbyPromotion(ctx, cluster, observed)
+		assert.Assert(t, pending, "expected to wait for the former primary")
+		assert.Equal(t, reason(cluster), "PromotionPending")
+
+		replay = "behind"
+		pending = reconciler.reconcileStandbyPromotion(ctx, cluster, observed)
+		assert.Assert(t, pending, "expected to wait for replay")
+		assert.Equal(t, reason(cluster), "PromotionPending")
+
+		replay = "caught-up"
+		pending = reconciler.reconcileStandbyPromotion(ctx, cluster, observed)
+		assert.Assert(t, !pending)
+		assert.Equal(t, reason(cluster), "Promoting")
+		assert.Equal(t, <-recorder.Events, "Normal StandbyCaughtUp Standby leader leader replayed all the WAL it received")
+
+		pending = reconciler.reconcileStandbyPromotion(ctx, cluster,
+			&observedInstances{forCluster: []*Instance{primary}})
+		assert.Assert(t, !pending)
+		assert.Equal(t, reason(cluster), "Promoted")
+		assert.Equal(t, <-recorder.Events, "Normal StandbyPromoted PostgreSQL is accepting writes")
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("Repository", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: false, RepoName: "repo1"}
+		observed := &observedInstances{forCluster: []*Instance{standby}}
+
+		replay = "caught-up 000000020000000000000004"
+		pending := reconciler.reconcileStandbyPromotion(ctx, cluster, observed)
+		assert.Assert(t, pending, "expected to wait for the repository")
+		assert.Equal(t, reason(cluster), "PromotionPending")
+		assert.Equal(t, meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.PostgresClusterStandby).Message,
+			"Waiting to promote until leader replays the newest WAL in repo1.")
+
+		replay = "caught-up 000000020000000000000005"
+		pending = reconciler.reconcileStandbyPromotion(ctx, cluster, observed)
+		assert.Assert(t, !pending)
+		assert.Equal(t, reason(cluster), "Promoting")
+		assert.Equal(t, <-recorder.Events, "Normal StandbyCaughtUp Standby leader leader replayed all the WAL it received")
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("Unobserved", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: false, Host: "elsewhere"}
+		observed := &observedInstances{forCluster: []*Instance{standby}}
+
+		failure = errors.New("boom")
+		defer func() { failure = nil }()
+
+		pending := reconciler.reconcileStandbyPromotion(ctx, cluster, observed)
+		assert.Assert(t, pending, "expected to wait for an observation")
+		assert.Equal(t, reason(cluster), "PromotionPending")
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("Forced", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{
+			Enabled: false, Host: "elsewhere", ForcePromotion: initialize.Bool(true),
+		}
+		observed := &observedInstances{forCluster: []*Instance{standby}}
+
+		replay = "streaming"
+		failure = errors.New("boom")
+		defer func() { failure = nil }()
+
+		pending := reconciler.reconcileStandbyPromotion(ctx, cluster, observed)
+		assert.Assert(t, !pending)
+		assert.Equal(t, reason(cluster), "Promoting")
+		assert.Equal(t, <-recorder.Events,
+			"Warning StandbyCaughtUp Standby leader leader is being promoted without checking its replay")
+	})
+}
+
+func TestParseStandbyStatus(t *testing.T) {
+	_, err := parseStandbyStatus("")
+	assert.Assert(t, err != nil, "expected an error for empty output")
+
+	status, err := parseStandbyStatus(`{` +
+		`"observedAt" : "2022-06-01T10:00:30.5+00:00", ` +
+		`"receivedLSN" : null, ` +
+		`"replayedLSN" : "0/3000148", ` +
+		`"lastReplayedTransaction" : "2022-06-01T10:00:00.25+00:00", ` +
+		`"lastRestoredWAL" : "000000010000000000000003"}` + "\n")
+	assert.NilError(t, err)
+	assert.Equal(t, status.ReceivedLSN, "")
+	assert.Equal(t, status.ReplayedLSN, "0/3000148")
+	assert.Equal(t, status.LastRestoredWAL, "000000010000000000000003")
+	assert.Assert(t, status.ReplayLagSeconds != nil)
+	assert.Equal(t, *status.ReplayLagSeconds, int64(30))
+
+	status, err = parseStandbyStatus(`{"observedAt" : "2022-06-01T10:00:30+00:00", ` +
+		`"receivedLSN" : "0/4000000", "replayedLSN" : "0/3000148", ` +
+		`"lastReplayedTransaction" : null, "lastRestoredWAL" : null}`)
+	assert.NilError(t, err)
+	assert.Equal(t, status.ReceivedLSN, "0/4000000")
+	assert.Equal(t, status.LastRestoredWAL, "")
+	assert.Assert(t, status.ReplayLagSeconds == nil)
+}
+
+func TestReconcileStandbyStatus(t *testing.T) {
+	ctx := context.Background()
+
+	leader := &Instance{Pods: []*corev1.Pod{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "leader",
+			Annotations: map[string]string{"status": `{"role":"standby_leader"}`},
+		},
+	}}}
+
+	reconciler := &Reconciler{
+		PodExec: func(
+			namespace, pod, container string,
+			stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Equal(t, pod, "leader")
+
+			b, _ := io.ReadAll(stdin)
+			assert.Assert(t, strings.Contains(string(b), standbyReplayFile))
+
+			_, err := stdout.Write([]byte(`{"replayedLSN" : "0/3000148"}`))
+			return err
+		},
+	}
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: true, RepoName: "repo1"}
+
+	result := reconciler.reconcileStandbyStatus(ctx, cluster,
+		&observedInstances{forCluster: []*Instance{leader}})
+	assert.Assert(t, result.RequeueAfter > 0, "expected to observe again")
+	assert.Assert(t, cluster.Status.Standby != nil)
+	assert.Equal(t, cluster.Status.Standby.ReplayedLSN, "0/3000148")
+
+	// The last observation remains when the leader cannot be asked.
+	failing := &Reconciler{
+		PodExec: func(string, string, string, io.Reader, io.Writer, io.Writer, ...string) error {
+			return errors.New("boom")
+		},
+	}
+	result = failing.reconcileStandbyStatus(ctx, cluster,
+		&observedInstances{forCluster: []*Instance{leader}})
+	assert.Assert(t, result.RequeueAfter > 0, "expected to observe again")
+	assert.Equal(t, cluster.Status.Standby.ReplayedLSN, "0/3000148")
+
+	// Status is cleared once the cluster is promoted.
+	cluster.Spec.Standby.Enabled = false
+	result = reconciler.reconcileStandbyStatus(ctx, cluster,
+		&observedInstances{forCluster: []*Instance{leader}})
+	assert.Equal(t, result.RequeueAfter, time.Duration(0))
+	assert.Assert(t, cluster.Status.Standby == nil)
+}
@@ -0,0 +1,344 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// EventPGUpgradeCompleted is the event reason utilized when a major
+	// upgrade Job completes successfully
+	EventPGUpgradeCompleted = "PGUpgradeCompleted"
+
+	// EventPGUpgradeFailed is the event reason utilized when a major upgrade
+	// Job fails
+	EventPGUpgradeFailed = "PGUpgradeFailed"
+)
+
+// +kubebuilder:rbac:groups="",resources=endpoints,verbs=delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=delete
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=delete
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;create;patch
+
+// reconcileMajorUpgrade upgrades the data of cluster to its PostgreSQL major
+// version when spec.upgrade asks for it. It stops every instance, runs
+// pg_upgrade in a Job against the volumes of the primary, and removes the DCS
+// so Patroni starts the primary from the upgraded data. Replicas are cloned
+// again. It returns true while the instances should not be reconciled.
+func (r *Reconciler) reconcileMajorUpgrade(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, observed *observedInstances,
+	clusterVolumes []corev1.PersistentVolumeClaim,
+) (bool, error) {
+	setCondition := func(status metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			ObservedGeneration: cluster.GetGeneration(),
+			Type:               v1beta1.PostgresUpgradeProgressing,
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	// Clusters bootstrapped before the operator recorded their PostgreSQL
+	// version are assumed to be at the version they are upgrading from, or
+	// else at the version in their spec.
+	version := cluster.Status.PostgresVersion
+	if version == 0 && cluster.Status.Patroni.SystemIdentifier != "" {
+		version = cluster.Spec.PostgresVersion
+		if cluster.Spec.Upgrade != nil {
+			version = cluster.Spec.Upgrade.FromPostgresVersion
+		}
+		cluster.Status.PostgresVersion = version
+	}
+
+	// There is nothing to upgrade before the cluster is bootstrapped or once
+	// its data matches the spec.
+	if version == 0 || version == cluster.Spec.PostgresVersion {
+		return false, nil
+	}
+
+	to := cluster.Spec.PostgresVersion
+	if cluster.Spec.Upgrade == nil ||
+		cluster.Spec.Upgrade.FromPostgresVersion != version || version > to {
+		setCondition(metav1.ConditionFalse, "PGUpgradeNotRequested", fmt.Sprintf(
+			"PostgreSQL data is version %d; set spec.upgrade.fromPostgresVersion to %d "+
+				"to upgrade it to version %d", version, version, to))
+
+		// Keep the instances on their current spec. Rolling them onto the
+		// new major version would start PostgreSQL against an empty data
+		// directory while the DCS holds the old system identifier.
+		return true, nil
+	}
+
+	if config.PGUpgradeContainerImage(cluster) == "" {
+		setCondition(metav1.ConditionFalse, "PGUpgradeImageMissing",
+			"Set spec.upgrade.image or the RELATED_IMAGE_PGUPGRADE environment "+
+				"variable of the operator to upgrade PostgreSQL")
+		return true, nil
+	}
+
+	job := &batchv1.Job{ObjectMeta: naming.PGUpgradeJob(cluster)}
+	err := errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(job), job))
+	if apierrors.IsNotFound(err) {
+		job, err = nil, nil
+	}
+	if err != nil {
+		return true, err
+	}
+
+	previous := meta.FindStatusCondition(cluster.Status.Conditions,
+		v1beta1.PostgresUpgradeProgressing)
+
+	switch {
+	case job != nil && jobFailed(job):
+		if previous == nil || previous.Reason != "PGUpgradeFailed" {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, EventPGUpgradeFailed,
+				"pg_upgrade Job %q failed", job.Name)
+		}
+		setCondition(metav1.ConditionFalse, "PGUpgradeFailed", fmt.Sprintf(
+			"pg_upgrade from version %d to %d failed; see the logs of Job %q",
+			version, to, job.Name))
+		return true, nil
+
+	case job != nil && jobCompleted(job):
+		// Remove the DCS so that Patroni does not find the system identifier
+		// of the old data.
+		endpoints, err := r.observePatroniDCS(ctx, cluster)
+		if err != nil {
+			return true, err
+		}
+		if len(endpoints) > 0 {
+			setCondition(metav1.ConditionTrue, "PGUpgradeRunning", "Removing DCS")
+			for i := range endpoints {
+				if err := r.Client.Delete(ctx, endpoints[i]); client.IgnoreNotFound(err) != nil {
+					return true, errors.WithStack(err)
+				}
+			}
+			return true, nil
+		}
+
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, EventPGUpgradeCompleted,
+			"pg_upgrade Job %q completed successfully", job.Name)
+		setCondition(metav1.ConditionFalse, "PGUpgradeSucceeded", fmt.Sprintf(
+			"PostgreSQL data upgraded from version %d to %d", version, to))
+
+		cluster.Status.PostgresVersion = to
+
+		// the primary is bootstrapped again from the upgraded data
+		cluster.Status.Patroni.SystemIdentifier = ""
+		// the upgrade may change the contents of the database, so the pgbouncer and exporter
+		// hashes are no longer valid
+		cluster.Status.Proxy.PGBouncer.PostgreSQLRevision = ""
+		cluster.Status.Monitoring.ExporterConfiguration = ""
+		// the databases and users are written again against the upgraded data
+		cluster.Status.DatabaseRevision = ""
+		return false, nil
+
+	case job != nil:
+		// give the Job time to finish
+		return true, nil
+	}
+
+	// Upgrade the data of the primary. The other instances stay down until
+	// it starts again.
+	if cluster.Status.StartupInstance == "" {
+		for _, instance := range observed.forCluster {
+			if primary, known := instance.IsPrimary(); primary && known {
+				cluster.Status.StartupInstance = instance.Name
+				cluster.Status.StartupInstanceSet = instance.Spec.Name
+			}
+		}
+	}
+	if cluster.Status.StartupInstance == "" {
+		setCondition(metav1.ConditionFalse, "PGUpgradeWaiting",
+			"Waiting for a primary instance to upgrade")
+		return true, nil
+	}
+
+	// stop every instance
+	var running bool
+	for _, instance := range observed.forCluster {
+		if len(instance.Pods) > 0 {
+			running = true
+		}
+		if instance.Runner != nil {
+			err := r.Client.Delete(ctx, instance.Runner,
+				client.PropagationPolicy(metav1.DeletePropagationForeground))
+			if client.IgnoreNotFound(err) != nil {
+				return true, errors.WithStack(err)
+			}
+		}
+	}
+	if running {
+		setCondition(metav1.ConditionTrue, "PGUpgradeRunning",
+			"Stopping instances before pg_upgrade")
+		return true, nil
+	}
+
+	job, err = generateUpgradeJob(cluster, version, clusterVolumes)
+	if err == nil {
+		err = errors.WithStack(r.setControllerReference(cluster, job))
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, job))
+	}
+	if err == nil {
+		setCondition(metav1.ConditionTrue, "PGUpgradeRunning", fmt.Sprintf(
+			"Upgrading PostgreSQL data from version %d to %d in Job %q",
+			version, to, job.Name))
+	}
+	return true, err
+}
+
+// generateUpgradeJob returns the Job that runs pg_upgrade against the volumes
+// of the startup instance of cluster.
+func generateUpgradeJob(
+	cluster *v1beta1.PostgresCluster, from int,
+	clusterVolumes []corev1.PersistentVolumeClaim,
+) (*batchv1.Job, error) {
+	var instanceSet *v1beta1.PostgresInstanceSetSpec
+	for i := range cluster.Spec.InstanceSets {
+		if cluster.Spec.InstanceSets[i].Name == cluster.Status.StartupInstanceSet {
+			instanceSet = &cluster.Spec.InstanceSets[i]
+		}
+	}
+	if instanceSet == nil {
+		return nil, errors.Errorf("unable to find instance set %q to upgrade",
+			cluster.Status.StartupInstanceSet)
+	}
+
+	job := &batchv1.Job{ObjectMeta: naming.PGUpgradeJob(cluster)}
+	job.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
+
+	job.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+	job.Labels = naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RolePGUpgrade,
+		})
+
+	container := corev1.Container{
+		Command:         postgres.UpgradeCommand(cluster, instanceSet, from),
+		Image:           config.PGUpgradeContainerImage(cluster),
+		ImagePullPolicy: cluster.Spec.ImagePullPolicy,
+		Name:            naming.ContainerPGUpgrade,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+		Resources:       instanceSet.Resources,
+	}
+	if cluster.Spec.Upgrade.Resources != nil {
+		container.Resources = *cluster.Spec.Upgrade.Resources
+	}
+
+	// Mount the data, WAL, and tablespace volumes of the startup instance
+	// where the instance mounts them.
+	var volumes []corev1.Volume
+	for i := range clusterVolumes {
+		labels := clusterVolumes[i].GetLabels()
+		if labels[naming.LabelInstance] != cluster.Status.StartupInstance {
+			continue
+		}
+
+		var mount corev1.VolumeMount
+		switch labels[naming.LabelRole] {
+		case naming.RolePostgresData:
+			mount = postgres.DataVolumeMount()
+		case naming.RolePostgresWAL:
+			mount = postgres.WALVolumeMount()
+		case naming.RolePostgresTablespace:
+			mount = postgres.TablespaceVolumeMount(labels[naming.LabelTablespace])
+		default:
+			continue
+		}
+
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+		volumes = append(volumes, corev1.Volume{
+			Name: mount.Name,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: clusterVolumes[i].Name,
+				},
+			},
+		})
+	}
+
+	var hasData bool
+	for _, mount := range container.VolumeMounts {
+		hasData = hasData || mount.Name == postgres.DataVolumeMount().Name
+	}
+	if !hasData {
+		return nil, errors.Errorf("unable to find the data volume of instance %q to upgrade",
+			cluster.Status.StartupInstance)
+	}
+
+	job.Spec = batchv1.JobSpec{
+		// pg_upgrade cannot run again after it links the data files. Create
+		// only one Pod and leave it in place when it fails.
+		BackoffLimit: initialize.Int32(0),
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: job.Annotations,
+				Labels:      job.Labels,
+			},
+			Spec: corev1.PodSpec{
+				Containers:      []corev1.Container{container},
+				SecurityContext: postgres.PodSecurityContext(cluster),
+				Volumes:         volumes,
+
+				// Set the image pull secrets, if any exist.
+				// This is set here rather than using the service account due to the lack
+				// of propagation to existing pods when the CRD is updated:
+				// https://github.com/kubernetes/kubernetes/issues/88456
+				ImagePullSecrets: cluster.Spec.ImagePullSecrets,
+
+				// Schedule the Pod like the instance so it can reach its volumes.
+				Affinity:                  instanceSet.Affinity,
+				Tolerations:               instanceSet.Tolerations,
+				TopologySpreadConstraints: instanceSet.TopologySpreadConstraints,
+
+				// Set RestartPolicy to "Never" since we want a new Pod to be
+				// created by the Job controller when there is a failure
+				// (instead of the container simply restarting).
+				RestartPolicy: corev1.RestartPolicyNever,
+
+				// These Jobs don't make Kubernetes API calls, so we can just
+				// use the default ServiceAccount and not mount its credentials.
+				AutomountServiceAccountToken: initialize.Bool(false),
+				EnableServiceLinks:           initialize.Bool(false),
+			},
+		},
+	}
+	if instanceSet.PriorityClassName != nil {
+		job.Spec.Template.Spec.PriorityClassName = *instanceSet.PriorityClassName
+	}
+
+	return job, nil
+}
@@ -0,0 +1,209 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestReconcileMajorUpgradeNotNeeded(t *testing.T) {
+	ctx := context.Background()
+	r := &Reconciler{}
+
+	t.Run("NotBootstrapped", func(t *testing.T) {
+		cluster := &v1beta1.PostgresCluster{}
+		cluster.Spec.PostgresVersion = 14
+		cluster.Spec.Upgrade = &v1beta1.PGUpgradeSpec{FromPostgresVersion: 13}
+
+		returnEarly, err := r.reconcileMajorUpgrade(ctx, cluster, &observedInstances{}, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, !returnEarly)
+		assert.Equal(t, cluster.Status.PostgresVersion, 0)
+	})
+
+	t.Run("RecordsVersion", func(t *testing.T) {
+		cluster := &v1beta1.PostgresCluster{}
+		cluster.Spec.PostgresVersion = 14
+		cluster.Status.Patroni.SystemIdentifier = "1234"
+
+		returnEarly, err := r.reconcileMajorUpgrade(ctx, cluster, &observedInstances{}, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, !returnEarly)
+		assert.Equal(t, cluster.Status.PostgresVersion, 14)
+	})
+
+	t.Run("Upgraded", func(t *testing.T) {
+		cluster := &v1beta1.PostgresCluster{}
+		cluster.Spec.PostgresVersion = 14
+		cluster.Spec.Upgrade = &v1beta1.PGUpgradeSpec{FromPostgresVersion: 13}
+		cluster.Status.PostgresVersion = 14
+		cluster.Status.Patroni.SystemIdentifier = "1234"
+
+		returnEarly, err := r.reconcileMajorUpgrade(ctx, cluster, &observedInstances{}, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, !returnEarly)
+		assert.Equal(t, cluster.Status.PostgresVersion, 14)
+	})
+
+	t.Run("NotRequested", func(t *testing.T) {
+		cluster := &v1beta1.PostgresCluster{}
+		cluster.Spec.PostgresVersion = 15
+		cluster.Spec.Upgrade = &v1beta1.PGUpgradeSpec{FromPostgresVersion: 14}
+		cluster.Status.PostgresVersion = 13
+
+		returnEarly, err := r.reconcileMajorUpgrade(ctx, cluster, &observedInstances{}, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, returnEarly, "expected instances to stay on their current spec")
+		assert.Equal(t, cluster.Status.PostgresVersion, 13)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions,
+			v1beta1.PostgresUpgradeProgressing)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Reason, "PGUpgradeNotRequested")
+		assert.Assert(t, strings.Contains(condition.Message, "fromPostgresVersion to 13"),
+			"got %q", condition.Message)
+	})
+}
+
+func TestReconcileMajorUpgradeImageMissing(t *testing.T) {
+	t.Setenv("RELATED_IMAGE_PGUPGRADE", "")
+
+	ctx := context.Background()
+	r := &Reconciler{}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.PostgresVersion = 14
+	cluster.Spec.Upgrade = &v1beta1.PGUpgradeSpec{FromPostgresVersion: 13}
+	cluster.Status.PostgresVersion = 13
+
+	returnEarly, err := r.reconcileMajorUpgrade(ctx, cluster, &observedInstances{}, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, returnEarly)
+
+	condition := meta.FindStatusCondition(cluster.Status.Conditions,
+		v1beta1.PostgresUpgradeProgressing)
+	assert.Assert(t, condition != nil)
+	assert.Equal(t, condition.Reason, "PGUpgradeImageMissing")
+}
+
+func TestGenerateUpgradeJob(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Name = "pg1"
+	cluster.Spec.PostgresVersion = 14
+	cluster.Spec.ImagePullSecrets = []corev1.LocalObjectReference{{Name: "pull"}}
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{
+		Name:               "one",
+		WALVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{},
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		},
+	}}
+	cluster.Spec.Upgrade = &v1beta1.PGUpgradeSpec{
+		FromPostgresVersion: 13,
+		Image:               "upgrade-image",
+	}
+	cluster.Status.StartupInstance = "pg1-one-abcd"
+	cluster.Status.StartupInstanceSet = "one"
+
+	volume := func(name, instance, role string) corev1.PersistentVolumeClaim {
+		pvc := corev1.PersistentVolumeClaim{}
+		pvc.Name = name
+		pvc.Labels = map[string]string{
+			naming.LabelCluster:  "pg1",
+			naming.LabelInstance: instance,
+			naming.LabelRole:     role,
+		}
+		return pvc
+	}
+	volumes := []corev1.PersistentVolumeClaim{
+		volume("pg1-one-abcd-pgdata", "pg1-one-abcd", naming.RolePostgresData),
+		volume("pg1-one-abcd-pgwal", "pg1-one-abcd", naming.RolePostgresWAL),
+		volume("pg1-one-wxyz-pgdata", "pg1-one-wxyz", naming.RolePostgresData),
+		volume("pg1-one-abcd-fast-tablespace", "pg1-one-abcd", naming.RolePostgresTablespace),
+	}
+	volumes[3].Labels[naming.LabelTablespace] = "fast"
+
+	job, err := generateUpgradeJob(cluster, 13, volumes)
+	assert.NilError(t, err)
+
+	assert.Equal(t, job.Namespace, "ns1")
+	assert.Equal(t, job.Name, "pg1-pgupgrade-pg14")
+	assert.Equal(t, *job.Spec.BackoffLimit, int32(0))
+	assert.DeepEqual(t, job.Labels, map[string]string{
+		"postgres-operator.crunchydata.com/cluster": "pg1",
+		"postgres-operator.crunchydata.com/role":    "pgupgrade",
+	})
+
+	pod := job.Spec.Template.Spec
+	assert.DeepEqual(t, job.Spec.Template.Labels, job.Labels)
+	assert.Equal(t, pod.RestartPolicy, corev1.RestartPolicyNever)
+	assert.Equal(t, *pod.AutomountServiceAccountToken, false)
+	assert.DeepEqual(t, pod.ImagePullSecrets, cluster.Spec.ImagePullSecrets)
+
+	container := pod.Containers[0]
+	assert.Equal(t, container.Name, "pgupgrade")
+	assert.Equal(t, container.Image, "upgrade-image")
+	assert.Equal(t, container.Resources.Limits.Memory().String(), "1Gi")
+	assert.Assert(t, strings.Contains(strings.Join(container.Command, " "),
+		"/usr/pgsql-13/bin /usr/pgsql-14/bin /pgdata/pg13 /pgdata/pg14 /pgwal/pg14_wal"))
+
+	var mounts, claims []string
+	for _, mount := range container.VolumeMounts {
+		mounts = append(mounts, mount.MountPath)
+	}
+	for _, volume := range pod.Volumes {
+		claims = append(claims, volume.PersistentVolumeClaim.ClaimName)
+	}
+	assert.Equal(t, strings.Join(mounts, " "), "/pgdata /pgwal /tablespaces/fast")
+	assert.Equal(t, strings.Join(claims, " "),
+		"pg1-one-abcd-pgdata pg1-one-abcd-pgwal pg1-one-abcd-fast-tablespace")
+
+	t.Run("Resources", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Upgrade.Resources = &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+		}
+
+		job, err := generateUpgradeJob(cluster, 13, volumes)
+		assert.NilError(t, err)
+		assert.Equal(t,
+			job.Spec.Template.Spec.Containers[0].Resources.Limits.Memory().String(), "2Gi")
+	})
+
+	t.Run("NoDataVolume", func(t *testing.T) {
+		_, err := generateUpgradeJob(cluster, 13, volumes[1:2])
+		assert.ErrorContains(t, err, "data volume")
+	})
+
+	t.Run("NoInstanceSet", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.StartupInstanceSet = "two"
+
+		_, err := generateUpgradeJob(cluster, 13, volumes)
+		assert.ErrorContains(t, err, "instance set")
+	})
+}
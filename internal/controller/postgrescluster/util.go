@@ -28,6 +28,7 @@ import (
 
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 var tmpDirSizeLimit = resource.MustParse("16Mi")
@@ -316,3 +317,39 @@ func updateReconcileResult(currResult, newResult reconcile.Result) reconcile.Res
 
 	return currResult
 }
+
+// setServiceExternalTraffic copies the settings of spec that control traffic
+// from outside the Kubernetes cluster onto service. Kubernetes rejects some of
+// these on Services of the wrong type, so only those that apply to the type
+// of service are copied.
+func setServiceExternalTraffic(spec *v1beta1.ServiceSpec, service *corev1.Service) {
+	switch service.Spec.Type {
+	case corev1.ServiceTypeLoadBalancer:
+		service.Spec.LoadBalancerClass = spec.LoadBalancerClass
+		service.Spec.LoadBalancerSourceRanges = spec.LoadBalancerSourceRanges
+		fallthrough
+
+	case corev1.ServiceTypeNodePort:
+		if spec.ExternalTrafficPolicy != nil {
+			service.Spec.ExternalTrafficPolicy = *spec.ExternalTrafficPolicy
+		}
+	}
+}
+
+// setServiceRouting copies the settings of spec that control how traffic is
+// distributed among endpoints onto service.
+func setServiceRouting(spec *v1beta1.ServiceSpec, service *corev1.Service) {
+	if spec.SessionAffinity != nil {
+		service.Spec.SessionAffinity = *spec.SessionAffinity
+	}
+
+	// Kubernetes 1.23 and later allocate zone hints to the EndpointSlices of
+	// Services with this annotation; kube-proxy uses them when all zones have
+	// enough endpoints.
+	// - https://docs.k8s.io/concepts/services-networking/topology-aware-hints/
+	if spec.TopologyAwareHints {
+		service.Annotations = naming.Merge(service.Annotations, map[string]string{
+			corev1.AnnotationTopologyAwareHints: "auto",
+		})
+	}
+}
@@ -28,8 +28,10 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestSafeHash32(t *testing.T) {
@@ -529,3 +531,91 @@ func TestJobFailed(t *testing.T) {
 		})
 	}
 }
+
+func TestSetServiceExternalTraffic(t *testing.T) {
+	local := corev1.ServiceExternalTrafficPolicyTypeLocal
+	spec := &v1beta1.ServiceSpec{
+		ExternalTrafficPolicy:    &local,
+		LoadBalancerClass:        initialize.String("some-class"),
+		LoadBalancerSourceRanges: []string{"192.0.2.0/24", "2001:db8::/32"},
+	}
+
+	t.Run("ClusterIP", func(t *testing.T) {
+		service := new(corev1.Service)
+		service.Spec.Type = corev1.ServiceTypeClusterIP
+		before := service.DeepCopy()
+
+		setServiceExternalTraffic(spec, service)
+		assert.DeepEqual(t, before, service)
+	})
+
+	t.Run("NodePort", func(t *testing.T) {
+		service := new(corev1.Service)
+		service.Spec.Type = corev1.ServiceTypeNodePort
+
+		setServiceExternalTraffic(spec, service)
+		assert.Assert(t, cmp.MarshalMatches(service.Spec, `
+externalTrafficPolicy: Local
+type: NodePort
+		`))
+	})
+
+	t.Run("LoadBalancer", func(t *testing.T) {
+		service := new(corev1.Service)
+		service.Spec.Type = corev1.ServiceTypeLoadBalancer
+
+		setServiceExternalTraffic(spec, service)
+		assert.Assert(t, cmp.MarshalMatches(service.Spec, `
+externalTrafficPolicy: Local
+loadBalancerClass: some-class
+loadBalancerSourceRanges:
+- 192.0.2.0/24
+- 2001:db8::/32
+type: LoadBalancer
+		`))
+	})
+
+	t.Run("Unset", func(t *testing.T) {
+		service := new(corev1.Service)
+		service.Spec.Type = corev1.ServiceTypeLoadBalancer
+
+		setServiceExternalTraffic(new(v1beta1.ServiceSpec), service)
+		assert.Assert(t, cmp.MarshalMatches(service.Spec, `
+type: LoadBalancer
+		`))
+	})
+}
+
+func TestSetServiceRouting(t *testing.T) {
+	t.Run("Unset", func(t *testing.T) {
+		service := new(corev1.Service)
+		before := service.DeepCopy()
+
+		setServiceRouting(new(v1beta1.ServiceSpec), service)
+		assert.DeepEqual(t, before, service)
+	})
+
+	t.Run("Set", func(t *testing.T) {
+		affinity := corev1.ServiceAffinityClientIP
+		spec := &v1beta1.ServiceSpec{
+			SessionAffinity:    &affinity,
+			TopologyAwareHints: true,
+		}
+
+		service := new(corev1.Service)
+		service.Annotations = map[string]string{"some": "note"}
+
+		setServiceRouting(spec, service)
+		assert.Assert(t, cmp.MarshalMatches(service, `
+metadata:
+  annotations:
+    service.kubernetes.io/topology-aware-hints: auto
+    some: note
+  creationTimestamp: null
+spec:
+  sessionAffinity: ClientIP
+status:
+  loadBalancer: {}
+		`))
+	})
+}
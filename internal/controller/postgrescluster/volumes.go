@@ -221,6 +221,8 @@ func (r *Reconciler) configureExistingPGVolumes(
 				},
 				Spec: cluster.Spec.InstanceSets[0].DataVolumeClaimSpec,
 			}
+			setVolumeSnapshotDataSource(volume,
+				cluster.Spec.DataSource.Volumes.PGDataVolume)
 
 			volume.ObjectMeta.Labels = map[string]string{
 				naming.LabelCluster:     cluster.Name,
@@ -274,6 +276,8 @@ func (r *Reconciler) configureExistingPGWALVolume(
 			},
 			Spec: cluster.Spec.InstanceSets[0].DataVolumeClaimSpec,
 		}
+		setVolumeSnapshotDataSource(volume,
+			cluster.Spec.DataSource.Volumes.PGWALVolume)
 
 		volume.ObjectMeta.Labels = map[string]string{
 			naming.LabelCluster:     cluster.Name,
@@ -295,6 +299,21 @@ func (r *Reconciler) configureExistingPGWALVolume(
 	return volumes, nil
 }
 
+// setVolumeSnapshotDataSource populates volume from the VolumeSnapshot named
+// in source, if any. The data source of a PVC cannot change after it is created.
+// - https://kubernetes.io/docs/concepts/storage/persistent-volumes/#volume-snapshot-and-restore-volume-from-snapshot-support
+func setVolumeSnapshotDataSource(
+	volume *corev1.PersistentVolumeClaim, source *v1beta1.DataSourceVolume,
+) {
+	if source.VolumeSnapshotName != "" {
+		volume.Spec.DataSource = &corev1.TypedLocalObjectReference{
+			APIGroup: initialize.String("snapshot.storage.k8s.io"),
+			Kind:     "VolumeSnapshot",
+			Name:     source.VolumeSnapshotName,
+		}
+	}
+}
+
 // +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=create;patch
 
 // configureExistingRepoVolumes first searches the observed volumes list to see
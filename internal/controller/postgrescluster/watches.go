@@ -16,6 +16,8 @@
 package postgrescluster
 
 import (
+	"context"
+
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -24,6 +26,7 @@ import (
 
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/patroni"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // watchPods returns a handler.EventHandler for Pods.
@@ -46,6 +49,17 @@ func (*Reconciler) watchPods() handler.Funcs {
 				return
 			}
 
+			// Queue an event when Patroni changes the role of a pod, such as
+			// during a failover, so the new primary is recorded promptly.
+			if len(cluster) != 0 &&
+				e.ObjectOld.GetLabels()[naming.LabelRole] != labels[naming.LabelRole] {
+				q.Add(reconcile.Request{NamespacedName: client.ObjectKey{
+					Namespace: e.ObjectNew.GetNamespace(),
+					Name:      cluster,
+				}})
+				return
+			}
+
 			// Queue an event when a Patroni pod indicates it needs to restart
 			// or finished restarting.
 			if len(cluster) != 0 &&
@@ -60,3 +74,30 @@ func (*Reconciler) watchPods() handler.Funcs {
 		},
 	}
 }
+
+// watchUserSecrets returns a handler.EventHandler for Secrets. It queues every
+// PostgresCluster in the namespace of a Secret that has a user reading its
+// password from that Secret.
+func (r *Reconciler) watchUserSecrets() handler.EventHandler {
+	return handler.EnqueueRequestsFromMapFunc(func(secret client.Object) []reconcile.Request {
+		clusters := &v1beta1.PostgresClusterList{}
+		if err := r.Client.List(context.Background(), clusters,
+			client.InNamespace(secret.GetNamespace()),
+		); err != nil {
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for i := range clusters.Items {
+			for _, user := range clusters.Items[i].Spec.Users {
+				if user.SecretName == secret.GetName() {
+					requests = append(requests, reconcile.Request{
+						NamespacedName: client.ObjectKeyFromObject(&clusters.Items[i]),
+					})
+					break
+				}
+			}
+		}
+		return requests
+	})
+}
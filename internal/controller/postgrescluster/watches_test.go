@@ -22,9 +22,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestWatchPodsUpdate(t *testing.T) {
@@ -91,6 +96,40 @@ func TestWatchPodsUpdate(t *testing.T) {
 	assert.Equal(t, item, expected)
 	queue.Done(item)
 
+	t.Run("RoleChanged", func(t *testing.T) {
+		expected := reconcile.Request{}
+		expected.Namespace = "some-ns"
+		expected.Name = "starfish"
+
+		replica := &corev1.Pod{}
+		replica.Namespace = "some-ns"
+		replica.Labels = map[string]string{
+			"postgres-operator.crunchydata.com/cluster": "starfish",
+			"postgres-operator.crunchydata.com/role":    "replica",
+		}
+
+		primary := replica.DeepCopy()
+		primary.Labels["postgres-operator.crunchydata.com/role"] = "master"
+
+		// Promoted; one reconcile by label.
+		update(event.UpdateEvent{
+			ObjectOld: replica.DeepCopy(),
+			ObjectNew: primary.DeepCopy(),
+		}, queue)
+		assert.Equal(t, queue.Len(), 1, "expected one reconcile")
+
+		item, _ := queue.Get()
+		assert.Equal(t, item, expected)
+		queue.Done(item)
+
+		// Same role; no reconcile.
+		update(event.UpdateEvent{
+			ObjectOld: primary.DeepCopy(),
+			ObjectNew: primary.DeepCopy(),
+		}, queue)
+		assert.Equal(t, queue.Len(), 0)
+	})
+
 	t.Run("PendingRestart", func(t *testing.T) {
 		expected := reconcile.Request{}
 		expected.Namespace = "some-ns"
@@ -141,3 +180,46 @@ func TestWatchPodsUpdate(t *testing.T) {
 		queue.Done(item)
 	})
 }
+
+func TestWatchUserSecrets(t *testing.T) {
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	queue := controllertest.Queue{Interface: workqueue.New()}
+	reconciler := &Reconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&v1beta1.PostgresCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "byo"},
+			Spec: v1beta1.PostgresClusterSpec{Users: []v1beta1.PostgresUserSpec{
+				{Name: "generated"},
+				{Name: "provided", SecretName: "from-vault"},
+			}},
+		},
+		&v1beta1.PostgresCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "other"},
+			Spec: v1beta1.PostgresClusterSpec{Users: []v1beta1.PostgresUserSpec{
+				{Name: "provided", SecretName: "from-vault"},
+			}},
+		},
+	).Build()}
+
+	handler := reconciler.watchUserSecrets()
+
+	// Unrelated Secret; no reconcile.
+	handler.Create(event.CreateEvent{
+		Object: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "nope"}},
+	}, queue)
+	assert.Equal(t, queue.Len(), 0)
+
+	// Referenced Secret; reconcile only the cluster in its namespace.
+	handler.Update(event.UpdateEvent{
+		ObjectOld: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "from-vault"}},
+		ObjectNew: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "from-vault"}},
+	}, queue)
+	assert.Equal(t, queue.Len(), 1)
+
+	item, _ := queue.Get()
+	expected := reconcile.Request{}
+	expected.NamespacedName = client.ObjectKey{Namespace: "ns1", Name: "byo"}
+	assert.Equal(t, item, expected)
+	queue.Done(item)
+}
@@ -0,0 +1,156 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	configDirectory = "/etc/haproxy"
+
+	authorityAbsolutePath  = configDirectory + "/" + authorityProjectionPath
+	configFileAbsolutePath = configDirectory + "/" + configFileProjectionPath
+
+	authorityProjectionPath  = "~postgres-operator/patroni-ca.crt"
+	configFileProjectionPath = "haproxy.cfg"
+
+	configFileConfigMapKey = "haproxy.cfg"
+
+	// minBackends is the fewest servers HAProxy allocates in each backend.
+	// HAProxy fills them from DNS as instances come and go, so there is room
+	// to scale without changing the configuration.
+	minBackends = 64
+)
+
+const (
+	configGeneratedWarning = "" +
+		"# Generated by postgres-operator. DO NOT EDIT.\n" +
+		"# Your changes will not be saved.\n"
+)
+
+// configFile returns the contents of the HAProxy configuration file for cluster.
+func configFile(ctx context.Context, cluster *v1beta1.PostgresCluster) string {
+	var (
+		backends = naming.ClusterHAProxyBackends(cluster)
+		servers  = serverCount(cluster)
+		domain   = naming.KubernetesClusterDomain(ctx)
+		spec     = cluster.Spec.Proxy.HAProxy
+
+		// HAProxy resolves instances by the fully qualified name of a headless
+		// Service. It reads only the nameservers from "/etc/resolv.conf", not
+		// the search domains.
+		// - https://docs.haproxy.org/2.6/configuration.html#5.3.2
+		address = fmt.Sprintf("%s.%s.svc.%s:%d",
+			backends.Name, backends.Namespace, domain, *cluster.Spec.Port)
+	)
+
+	var b strings.Builder
+	b.WriteString(configGeneratedWarning)
+
+	// Log to stdout of the container.
+	b.WriteString(`
+global
+  log stdout format raw local0
+`)
+
+	// Route connections without looking at them. Every server is checked by
+	// asking the Patroni REST API of that instance about its role, verifying
+	// the certificate Patroni presents. When a server fails its check, close
+	// its client connections so they can reconnect to the new primary.
+	// - https://patroni.readthedocs.io/en/latest/rest_api.html#health-check-endpoints
+	fmt.Fprintf(&b, `
+defaults
+  log global
+  mode tcp
+  option tcplog
+  option dontlognull
+  timeout connect 5s
+  timeout client 1h
+  timeout server 1h
+  timeout check 5s
+  default-server init-addr none resolvers kubernetes check port %d check-ssl verify required ca-file %s inter 3s fall 3 rise 2 on-marked-down shutdown-sessions
+`, *cluster.Spec.Patroni.Port, authorityAbsolutePath)
+
+	// Use the nameservers of the Pod and accept large responses so that every
+	// instance fits in one.
+	b.WriteString(`
+resolvers kubernetes
+  parse-resolv-conf
+  accepted_payload_size 8192
+  hold valid 5s
+`)
+
+	for _, route := range []struct {
+		name, check string
+		port        int32
+	}{
+		{name: "primary", check: "/primary", port: *spec.Port},
+		{name: "replicas", check: "/replica", port: *spec.ReplicaPort},
+	} {
+		fmt.Fprintf(&b, `
+frontend %[1]s
+  bind :%[3]d
+  default_backend %[1]s
+
+backend %[1]s
+  option httpchk
+  http-check send meth GET uri %[2]s
+  http-check expect status 200
+  server-template instance %[4]d %[5]s
+`, route.name, route.check, route.port, servers, address)
+	}
+
+	return b.String()
+}
+
+// serverCount returns the number of servers HAProxy needs in each backend to
+// route to every PostgreSQL instance in the spec of cluster.
+func serverCount(cluster *v1beta1.PostgresCluster) int {
+	var count int
+	for _, set := range cluster.Spec.InstanceSets {
+		if set.Replicas != nil {
+			count += int(*set.Replicas)
+		}
+	}
+	if count < minBackends {
+		count = minBackends
+	}
+	return count
+}
+
+// ConfigMap populates the HAProxy ConfigMap.
+func ConfigMap(ctx context.Context,
+	inCluster *v1beta1.PostgresCluster,
+	outConfigMap *corev1.ConfigMap,
+) {
+	if inCluster.Spec.Proxy == nil || inCluster.Spec.Proxy.HAProxy == nil {
+		// HAProxy is disabled; there is nothing to do.
+		return
+	}
+
+	initialize.StringMap(&outConfigMap.Data)
+
+	outConfigMap.Data[configFileConfigMapKey] = configFile(ctx, inCluster)
+}
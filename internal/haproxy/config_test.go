@@ -0,0 +1,112 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestConfigFile(t *testing.T) {
+	ctx := context.Background()
+	domain := naming.KubernetesClusterDomain(ctx)
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Namespace = "ns1"
+	cluster.Name = "hippo"
+	cluster.Spec.Proxy = &v1beta1.PostgresProxySpec{HAProxy: new(v1beta1.HAProxyPodSpec)}
+	cluster.Default()
+	*cluster.Spec.Port = 9999
+
+	assert.Equal(t, configFile(ctx, cluster), strings.Trim(`
+# Generated by postgres-operator. DO NOT EDIT.
+# Your changes will not be saved.
+
+global
+  log stdout format raw local0
+
+defaults
+  log global
+  mode tcp
+  option tcplog
+  option dontlognull
+  timeout connect 5s
+  timeout client 1h
+  timeout server 1h
+  timeout check 5s
+  default-server init-addr none resolvers kubernetes check port 8008 check-ssl verify required ca-file /etc/haproxy/~postgres-operator/patroni-ca.crt inter 3s fall 3 rise 2 on-marked-down shutdown-sessions
+
+resolvers kubernetes
+  parse-resolv-conf
+  accepted_payload_size 8192
+  hold valid 5s
+
+frontend primary
+  bind :5432
+  default_backend primary
+
+backend primary
+  option httpchk
+  http-check send meth GET uri /primary
+  http-check expect status 200
+  server-template instance 64 hippo-haproxy-backends.ns1.svc.`+domain+`:9999
+
+frontend replicas
+  bind :5433
+  default_backend replicas
+
+backend replicas
+  option httpchk
+  http-check send meth GET uri /replica
+  http-check expect status 200
+  server-template instance 64 hippo-haproxy-backends.ns1.svc.`+domain+`:9999
+	`, "\t\n")+"\n")
+
+	t.Run("ManyInstances", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "one", Replicas: initialize.Int32(50)},
+			{Name: "two", Replicas: initialize.Int32(30)},
+		}
+
+		assert.Assert(t, strings.Contains(configFile(ctx, cluster),
+			"\n  server-template instance 80 hippo-haproxy-backends."))
+	})
+}
+
+func TestConfigMap(t *testing.T) {
+	ctx := context.Background()
+	cluster := new(v1beta1.PostgresCluster)
+	config := new(corev1.ConfigMap)
+
+	// Nothing happens when HAProxy is disabled.
+	ConfigMap(ctx, cluster, config)
+	assert.Assert(t, config.Data == nil)
+
+	cluster.Spec.Proxy = &v1beta1.PostgresProxySpec{HAProxy: new(v1beta1.HAProxyPodSpec)}
+	cluster.Default()
+
+	ConfigMap(ctx, cluster, config)
+	assert.Equal(t, config.Data["haproxy.cfg"], configFile(ctx, cluster))
+}
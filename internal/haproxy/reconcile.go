@@ -0,0 +1,99 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package haproxy
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// Pod populates a PodSpec with the container and volumes needed to run HAProxy.
+func Pod(
+	inCluster *v1beta1.PostgresCluster,
+	inConfigMap *corev1.ConfigMap,
+	outPod *corev1.PodSpec,
+) {
+	if inCluster.Spec.Proxy == nil || inCluster.Spec.Proxy.HAProxy == nil {
+		// HAProxy is disabled; there is nothing to do.
+		return
+	}
+
+	configVolumeMount := corev1.VolumeMount{
+		Name: "haproxy-config", MountPath: configDirectory, ReadOnly: true,
+	}
+	configVolume := corev1.Volume{Name: configVolumeMount.Name}
+	configVolume.Projected = &corev1.ProjectedVolumeSource{
+		Sources: []corev1.VolumeProjection{
+			{
+				ConfigMap: &corev1.ConfigMapProjection{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: inConfigMap.Name,
+					},
+					Items: []corev1.KeyToPath{{
+						Key:  configFileConfigMapKey,
+						Path: configFileProjectionPath,
+					}},
+				},
+			},
+			{
+				// Patroni presents a certificate signed by the root certificate
+				// authority of the namespace.
+				Secret: &corev1.SecretProjection{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: naming.RootCertSecret,
+					},
+					Items: []corev1.KeyToPath{{
+						Key:  "root.crt",
+						Path: authorityProjectionPath,
+					}},
+				},
+			},
+		},
+	}
+
+	container := corev1.Container{
+		Name: naming.ContainerHAProxy,
+
+		// Stay in the foreground and read only our configuration file.
+		Command:         []string{"haproxy", "-db", "-f", configFileAbsolutePath},
+		Image:           config.HAProxyContainerImage(inCluster),
+		ImagePullPolicy: inCluster.Spec.ImagePullPolicy,
+		Resources:       inCluster.Spec.Proxy.HAProxy.Resources,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          naming.PortHAProxyPrimary,
+				ContainerPort: *inCluster.Spec.Proxy.HAProxy.Port,
+				Protocol:      corev1.ProtocolTCP,
+			},
+			{
+				Name:          naming.PortHAProxyReplica,
+				ContainerPort: *inCluster.Spec.Proxy.HAProxy.ReplicaPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+
+		VolumeMounts: []corev1.VolumeMount{configVolumeMount},
+	}
+
+	outPod.Containers = []corev1.Container{container}
+	outPod.Volumes = []corev1.Volume{configVolume}
+}
@@ -0,0 +1,89 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package haproxy
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestPod(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	configMap := new(corev1.ConfigMap)
+	configMap.Name = "hippo-haproxy"
+	pod := new(corev1.PodSpec)
+
+	Pod(cluster, configMap, pod)
+	assert.DeepEqual(t, pod, new(corev1.PodSpec))
+
+	cluster.Spec.Proxy = &v1beta1.PostgresProxySpec{
+		HAProxy: &v1beta1.HAProxyPodSpec{Image: "haproxy-image"},
+	}
+	cluster.Spec.ImagePullPolicy = corev1.PullAlways
+	cluster.Default()
+
+	Pod(cluster, configMap, pod)
+	assert.Assert(t, cmp.MarshalMatches(pod, `
+containers:
+- command:
+  - haproxy
+  - -db
+  - -f
+  - /etc/haproxy/haproxy.cfg
+  image: haproxy-image
+  imagePullPolicy: Always
+  name: haproxy
+  ports:
+  - containerPort: 5432
+    name: haproxy-primary
+    protocol: TCP
+  - containerPort: 5433
+    name: haproxy-replica
+    protocol: TCP
+  resources: {}
+  securityContext:
+    allowPrivilegeEscalation: false
+    capabilities:
+      drop:
+      - ALL
+    privileged: false
+    readOnlyRootFilesystem: true
+    runAsNonRoot: true
+  volumeMounts:
+  - mountPath: /etc/haproxy
+    name: haproxy-config
+    readOnly: true
+volumes:
+- name: haproxy-config
+  projected:
+    sources:
+    - configMap:
+        items:
+        - key: haproxy.cfg
+          path: haproxy.cfg
+        name: hippo-haproxy
+    - secret:
+        items:
+        - key: root.crt
+          path: ~postgres-operator/patroni-ca.crt
+        name: pgo-root-cacert
+	`))
+}
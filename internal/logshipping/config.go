@@ -0,0 +1,176 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package logshipping
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	configDirectory = "/etc/fluent-bit/pgo"
+	customDirectory = "/etc/fluent-bit/custom"
+
+	// stateDirectory is where Fluent Bit records how far it has read each
+	// file. It is writable and lasts as long as the Pod.
+	stateDirectory = "/var/lib/fluent-bit"
+
+	configFileKey  = "fluent-bit.conf"
+	parsersFileKey = "parsers.conf"
+)
+
+// csvlogRegex returns a regular expression that matches the leading fields
+// of a PostgreSQL CSV log entry, through the message. Text fields are quoted,
+// and quotes within them are doubled.
+// - https://www.postgresql.org/docs/current/runtime-config-logging.html#RUNTIME-CONFIG-LOGGING-CSVLOG
+func csvlogRegex() string {
+	plain := func(name string) string { return `(?<` + name + `>[^,]*)` }
+	quoted := func(name string) string { return `(?:"(?<` + name + `>(?:[^"]|"")*)")?` }
+
+	return `^` + strings.Join([]string{
+		plain("log_time"),
+		quoted("user"),
+		quoted("database"),
+		plain("pid"),
+		plain("connection_from"),
+		plain("session_id"),
+		plain("session_line_num"),
+		quoted("command_tag"),
+		plain("session_start_time"),
+		plain("virtual_transaction_id"),
+		plain("transaction_id"),
+		plain("severity"),
+		plain("sql_state"),
+		quoted("message"),
+	}, `,`)
+}
+
+// parsersFile returns the Fluent Bit parsers for PostgreSQL and pgBackRest
+// log files.
+// - https://docs.fluentbit.io/manual/pipeline/parsers/configuring-parser
+// - https://docs.fluentbit.io/manual/administration/configuring-fluent-bit/multiline-parsing
+func parsersFile() string {
+	// Entries of PostgreSQL log files start with a timestamp. Lines that do
+	// not start with one continue the entry before them.
+	timestamp := `\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}`
+
+	return strings.Join([]string{
+		`# Generated by postgres-operator. DO NOT EDIT.`,
+		`# Your changes will not be saved.`,
+		``,
+		`[MULTILINE_PARSER]`,
+		`    name          postgres-csvlog`,
+		`    type          regex`,
+		`    flush_timeout 1000`,
+		`    rule "start_state" "/^` + timestamp + `/" "cont"`,
+		`    rule "cont" "/^(?!` + timestamp + `)/" "cont"`,
+		``,
+		`[PARSER]`,
+		`    Name   postgres-csvlog`,
+		`    Format regex`,
+		`    Regex  ` + csvlogRegex(),
+		``,
+		`[PARSER]`,
+		`    Name   postgres-jsonlog`,
+		`    Format json`,
+		``,
+		`[PARSER]`,
+		`    Name   pgbackrest`,
+		`    Format regex`,
+		`    Regex  ^(?<log_time>` + timestamp + `\.\d{3}) (?<process>P\d+) +(?<severity>[A-Z]+): (?<message>.*)$`,
+		``,
+	}, "\n")
+}
+
+// configFile returns the Fluent Bit configuration that reads PostgreSQL and
+// pgBackRest log files in the data volume. Records are tagged "postgres" and
+// "pgbackrest" and have "cluster", "namespace", and "pod" fields. PostgreSQL
+// records have "user", "database", "severity", and "sql_state" fields in both
+// CSV and JSON formats.
+// - https://docs.fluentbit.io/manual/pipeline/inputs/tail
+// - https://www.postgresql.org/docs/current/runtime-config-logging.html#RUNTIME-CONFIG-LOGGING-JSONLOG
+func configFile() string {
+	return strings.Join([]string{
+		`# Generated by postgres-operator. DO NOT EDIT.`,
+		`# Your changes will not be saved.`,
+		``,
+		`[SERVICE]`,
+		`    Flush        5`,
+		`    Parsers_File ` + configDirectory + `/` + parsersFileKey,
+		``,
+		`[INPUT]`,
+		`    Name             tail`,
+		`    Tag              postgres`,
+		`    Path             ` + naming.PostgresPGDataLogPath + `/*.csv`,
+		`    DB               ` + stateDirectory + `/csvlog.db`,
+		`    multiline.parser postgres-csvlog`,
+		``,
+		`[INPUT]`,
+		`    Name   tail`,
+		`    Tag    postgres`,
+		`    Path   ` + naming.PostgresPGDataLogPath + `/*.json`,
+		`    DB     ` + stateDirectory + `/jsonlog.db`,
+		`    Parser postgres-jsonlog`,
+		``,
+		`[INPUT]`,
+		`    Name   tail`,
+		`    Tag    pgbackrest`,
+		`    Path   ` + naming.PGBackRestPGDataLogPath + `/*.log`,
+		`    DB     ` + stateDirectory + `/pgbackrest.db`,
+		`    Parser pgbackrest`,
+		``,
+		`[FILTER]`,
+		`    Name     parser`,
+		`    Match    postgres`,
+		`    Key_Name log`,
+		`    Parser   postgres-csvlog`,
+		``,
+		`[FILTER]`,
+		`    Name   modify`,
+		`    Match  postgres`,
+		`    Rename dbname database`,
+		`    Rename error_severity severity`,
+		`    Rename state_code sql_state`,
+		``,
+		`[FILTER]`,
+		`    Name   record_modifier`,
+		`    Match  *`,
+		`    Record cluster ${PGO_CLUSTER}`,
+		`    Record namespace ${PGO_NAMESPACE}`,
+		`    Record pod ${HOSTNAME}`,
+		``,
+		`@INCLUDE ` + customDirectory + `/*.conf`,
+		``,
+	}, "\n")
+}
+
+// ConfigMap populates the log shipper ConfigMap with the configuration managed
+// by PGO.
+func ConfigMap(inCluster *v1beta1.PostgresCluster, outConfigMap *corev1.ConfigMap) {
+	if !Enabled(inCluster) {
+		return
+	}
+
+	initialize.StringMap(&outConfigMap.Data)
+
+	outConfigMap.Data[configFileKey] = configFile()
+	outConfigMap.Data[parsersFileKey] = parsersFile()
+}
@@ -0,0 +1,103 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package logshipping
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestCSVLogRegex(t *testing.T) {
+	// Fluent Bit uses Onigmo which spells named groups differently than Go.
+	re := regexp.MustCompile(strings.ReplaceAll(csvlogRegex(), `(?<`, `(?P<`))
+
+	line := `2022-10-17 14:01:02.345 UTC,"app","orders",1234,"10.0.0.1:5432",` +
+		`634d5f1e.4d2,3,"INSERT",2022-10-17 14:00:00 UTC,3/17,0,ERROR,23505,` +
+		`"duplicate key value violates unique constraint ""orders_pkey""",` +
+		`"Key (id)=(1) already exists.",,,,,"INSERT INTO orders VALUES (1)",,,"psql","client backend",,0`
+
+	match := re.FindStringSubmatch(line)
+	assert.Assert(t, match != nil)
+
+	field := func(name string) string { return match[re.SubexpIndex(name)] }
+	assert.Equal(t, field("log_time"), "2022-10-17 14:01:02.345 UTC")
+	assert.Equal(t, field("user"), "app")
+	assert.Equal(t, field("database"), "orders")
+	assert.Equal(t, field("pid"), "1234")
+	assert.Equal(t, field("command_tag"), "INSERT")
+	assert.Equal(t, field("severity"), "ERROR")
+	assert.Equal(t, field("sql_state"), "23505")
+	assert.Equal(t, field("message"),
+		`duplicate key value violates unique constraint ""orders_pkey""`)
+
+	// Background processes have no user or database.
+	line = `2022-10-17 14:01:02.345 UTC,,,99,,634d5f1e.63,1,,2022-10-17 14:00:00 UTC,,0,LOG,00000,` +
+		`"checkpoint starting: time",,,,,,,,,"","checkpointer",,0`
+
+	match = re.FindStringSubmatch(line)
+	assert.Assert(t, match != nil)
+	assert.Equal(t, field("user"), "")
+	assert.Equal(t, field("database"), "")
+	assert.Equal(t, field("severity"), "LOG")
+	assert.Equal(t, field("message"), "checkpoint starting: time")
+}
+
+func TestConfigMap(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	configmap := &corev1.ConfigMap{}
+
+	ConfigMap(cluster, configmap)
+	assert.Assert(t, configmap.Data == nil, "expected nothing when disabled")
+
+	cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+		LogShipping: &v1beta1.LogShippingSpec{},
+	}
+	ConfigMap(cluster, configmap)
+
+	config := configmap.Data["fluent-bit.conf"]
+	assert.Assert(t, strings.Contains(config, "Parsers_File /etc/fluent-bit/pgo/parsers.conf"))
+	assert.Assert(t, strings.Contains(config, "Path             /pgdata/postgres/log/*.csv"))
+	assert.Assert(t, strings.Contains(config, "Path   /pgdata/pgbackrest/log/*.log"))
+	assert.Assert(t, strings.Contains(config, "DB               /var/lib/fluent-bit/csvlog.db"))
+	assert.Assert(t, strings.Contains(config, "DB     /var/lib/fluent-bit/pgbackrest.db"))
+	assert.Assert(t, strings.HasSuffix(config, "@INCLUDE /etc/fluent-bit/custom/*.conf\n"))
+
+	parsers := configmap.Data["parsers.conf"]
+	assert.Assert(t, strings.Contains(parsers, "name          postgres-csvlog"))
+	assert.Assert(t, strings.Contains(parsers, "Name   postgres-jsonlog"))
+	assert.Assert(t, strings.Contains(parsers, "Name   pgbackrest"))
+}
+
+func TestPGBackRestRegex(t *testing.T) {
+	var expression string
+	for _, line := range strings.Split(parsersFile(), "\n") {
+		if strings.HasPrefix(line, "    Regex  ") && strings.Contains(line, "process") {
+			expression = strings.TrimPrefix(line, "    Regex  ")
+		}
+	}
+	re := regexp.MustCompile(strings.ReplaceAll(expression, `(?<`, `(?P<`))
+
+	match := re.FindStringSubmatch(`2022-10-17 14:01:02.345 P00   INFO: archive-push command end: completed successfully`)
+	assert.Assert(t, match != nil)
+	assert.Equal(t, match[re.SubexpIndex("severity")], "INFO")
+	assert.Equal(t, match[re.SubexpIndex("message")], "archive-push command end: completed successfully")
+}
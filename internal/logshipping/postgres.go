@@ -0,0 +1,45 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package logshipping
+
+import (
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// PostgreSQLParameters sets the parameters required to write PostgreSQL logs
+// to files that the log shipper can read.
+func PostgreSQLParameters(cluster *v1beta1.PostgresCluster, outParameters *postgres.Parameters) {
+	if !Enabled(cluster) {
+		return
+	}
+
+	// Write one structured file per day of the week, each replacing the file
+	// from a week ago. These files are in the data volume, so they persist
+	// across restarts of PostgreSQL and the log shipper.
+	// - https://www.postgresql.org/docs/current/runtime-config-logging.html
+	outParameters.Mandatory.Add("logging_collector", "on")
+	outParameters.Mandatory.Add("log_destination", postgresLogFormat(cluster))
+	outParameters.Mandatory.Add("log_directory", naming.PostgresPGDataLogPath)
+	outParameters.Mandatory.Add("log_filename", "postgresql-%a")
+	outParameters.Mandatory.Add("log_rotation_age", "1d")
+	outParameters.Mandatory.Add("log_rotation_size", "0")
+	outParameters.Mandatory.Add("log_truncate_on_rotation", "on")
+
+	// Allow the group of PostgreSQL to read its log files, too.
+	outParameters.Mandatory.Add("log_file_mode", "0640")
+}
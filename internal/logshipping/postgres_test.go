@@ -0,0 +1,75 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package logshipping
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestPostgreSQLParameters(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		inCluster := &v1beta1.PostgresCluster{}
+		outParameters := postgres.NewParameters()
+		PostgreSQLParameters(inCluster, &outParameters)
+		assert.Assert(t, !outParameters.Mandatory.Has("logging_collector"))
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		inCluster := &v1beta1.PostgresCluster{}
+		inCluster.Spec.PostgresVersion = 14
+		inCluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+			LogShipping: &v1beta1.LogShippingSpec{},
+		}
+		outParameters := postgres.NewParameters()
+		PostgreSQLParameters(inCluster, &outParameters)
+
+		collector, _ := outParameters.Mandatory.Get("logging_collector")
+		assert.Equal(t, collector, "on")
+		destination, _ := outParameters.Mandatory.Get("log_destination")
+		assert.Equal(t, destination, "csvlog")
+		directory, _ := outParameters.Mandatory.Get("log_directory")
+		assert.Equal(t, directory, "/pgdata/postgres/log")
+		truncate, _ := outParameters.Mandatory.Get("log_truncate_on_rotation")
+		assert.Equal(t, truncate, "on")
+		mode, _ := outParameters.Mandatory.Get("log_file_mode")
+		assert.Equal(t, mode, "0640")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		inCluster := &v1beta1.PostgresCluster{}
+		inCluster.Spec.PostgresVersion = 14
+		inCluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+			LogShipping: &v1beta1.LogShippingSpec{Format: "jsonlog"},
+		}
+
+		// PostgreSQL 14 cannot write JSON.
+		outParameters := postgres.NewParameters()
+		PostgreSQLParameters(inCluster, &outParameters)
+		destination, _ := outParameters.Mandatory.Get("log_destination")
+		assert.Equal(t, destination, "csvlog")
+
+		inCluster.Spec.PostgresVersion = 15
+		outParameters = postgres.NewParameters()
+		PostgreSQLParameters(inCluster, &outParameters)
+		destination, _ = outParameters.Mandatory.Get("log_destination")
+		assert.Equal(t, destination, "jsonlog")
+	})
+}
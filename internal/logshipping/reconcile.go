@@ -0,0 +1,96 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package logshipping
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// InstancePod populates a PodSpec with the container and volumes needed to
+// ship logs. The PostgreSQL data volume must already be in the PodSpec.
+func InstancePod(inCluster *v1beta1.PostgresCluster, outPod *corev1.PodSpec) {
+	if !Enabled(inCluster) {
+		return
+	}
+
+	spec := inCluster.Spec.Monitoring.LogShipping
+
+	// Read log files from the data volume without changing them.
+	dataMount := postgres.DataVolumeMount()
+	dataMount.ReadOnly = true
+
+	// The default image runs as root. OpenShift assigns every container the
+	// same UID through its SecurityContextConstraints. Otherwise, run as the
+	// UID of PostgreSQL so the log shipper can read the log directory.
+	// - https://cloud.redhat.com/blog/a-guide-to-openshift-and-uids
+	securityContext := initialize.RestrictedSecurityContext()
+	if inCluster.Spec.OpenShift == nil || !*inCluster.Spec.OpenShift {
+		securityContext.RunAsUser = initialize.Int64(26)
+	}
+
+	container := corev1.Container{
+		Name:            naming.ContainerLogShipper,
+		Image:           config.LogShipperContainerImage(inCluster),
+		ImagePullPolicy: inCluster.Spec.ImagePullPolicy,
+		Resources:       spec.Resources,
+		SecurityContext: securityContext,
+
+		Command: []string{"/fluent-bit/bin/fluent-bit", "--config=" + configDirectory + "/" + configFileKey},
+		Env: []corev1.EnvVar{
+			{Name: "PGO_CLUSTER", Value: inCluster.Name},
+			{Name: "PGO_NAMESPACE", Value: inCluster.Namespace},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			dataMount,
+			{Name: "log-shipper-config", MountPath: configDirectory, ReadOnly: true},
+			{Name: "log-shipper-custom", MountPath: customDirectory, ReadOnly: true},
+			{Name: "log-shipper-state", MountPath: stateDirectory},
+		},
+	}
+
+	outPod.Containers = append(outPod.Containers, container)
+	outPod.Volumes = append(outPod.Volumes,
+		corev1.Volume{
+			Name: "log-shipper-config",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: naming.LogShippingConfigMap(inCluster).Name,
+					},
+				},
+			},
+		},
+		corev1.Volume{
+			Name: "log-shipper-custom",
+			VolumeSource: corev1.VolumeSource{
+				Projected: &corev1.ProjectedVolumeSource{
+					Sources: spec.Configuration,
+				},
+			},
+		},
+		corev1.Volume{
+			Name: "log-shipper-state",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+}
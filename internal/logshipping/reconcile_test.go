@@ -0,0 +1,108 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package logshipping
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestInstancePod(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Name = "hippo"
+	cluster.Spec.ImagePullPolicy = corev1.PullAlways
+
+	pod := &corev1.PodSpec{}
+	InstancePod(cluster, pod)
+	assert.Equal(t, len(pod.Containers), 0, "expected nothing when disabled")
+
+	cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+		LogShipping: &v1beta1.LogShippingSpec{
+			Image: "fluent-bit",
+			Configuration: []corev1.VolumeProjection{{
+				ConfigMap: &corev1.ConfigMapProjection{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "outputs"},
+				},
+			}},
+		},
+	}
+	InstancePod(cluster, pod)
+
+	assert.Assert(t, cmp.MarshalMatches(pod, `
+containers:
+- command:
+  - /fluent-bit/bin/fluent-bit
+  - --config=/etc/fluent-bit/pgo/fluent-bit.conf
+  env:
+  - name: PGO_CLUSTER
+    value: hippo
+  - name: PGO_NAMESPACE
+    value: ns1
+  image: fluent-bit
+  imagePullPolicy: Always
+  name: log-shipper
+  resources: {}
+  securityContext:
+    allowPrivilegeEscalation: false
+    capabilities:
+      drop:
+      - ALL
+    privileged: false
+    readOnlyRootFilesystem: true
+    runAsNonRoot: true
+    runAsUser: 26
+  volumeMounts:
+  - mountPath: /pgdata
+    name: postgres-data
+    readOnly: true
+  - mountPath: /etc/fluent-bit/pgo
+    name: log-shipper-config
+    readOnly: true
+  - mountPath: /etc/fluent-bit/custom
+    name: log-shipper-custom
+    readOnly: true
+  - mountPath: /var/lib/fluent-bit
+    name: log-shipper-state
+volumes:
+- configMap:
+    name: hippo-log-shipping
+  name: log-shipper-config
+- name: log-shipper-custom
+  projected:
+    sources:
+    - configMap:
+        name: outputs
+- emptyDir: {}
+  name: log-shipper-state
+	`))
+
+	t.Run("OpenShift", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.OpenShift = initialize.Bool(true)
+
+		pod := &corev1.PodSpec{}
+		InstancePod(cluster, pod)
+		assert.Assert(t, pod.Containers[0].SecurityContext.RunAsUser == nil,
+			"expected OpenShift to assign the UID")
+	})
+}
@@ -0,0 +1,37 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package logshipping
+
+import (
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// Enabled returns whether or not cluster ships its logs.
+func Enabled(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.Monitoring != nil && cluster.Spec.Monitoring.LogShipping != nil
+}
+
+// postgresLogFormat returns the log_destination that PostgreSQL in cluster
+// writes to files. PostgreSQL 15 introduced "jsonlog".
+// - https://www.postgresql.org/docs/current/runtime-config-logging.html#RUNTIME-CONFIG-LOGGING-CSVLOG
+// - https://www.postgresql.org/docs/current/runtime-config-logging.html#RUNTIME-CONFIG-LOGGING-JSONLOG
+func postgresLogFormat(cluster *v1beta1.PostgresCluster) string {
+	if cluster.Spec.Monitoring.LogShipping.Format == "jsonlog" &&
+		cluster.Spec.PostgresVersion >= 15 {
+		return "jsonlog"
+	}
+	return "csvlog"
+}
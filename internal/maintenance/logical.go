@@ -0,0 +1,149 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// logicalBackupDirectory is where the volume of exports is mounted.
+const logicalBackupDirectory = "/pgdump"
+
+// LogicalBackupsEnabled returns whether or not cluster has scheduled logical
+// backups.
+func LogicalBackupsEnabled(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.Backups.Logical != nil
+}
+
+// LogicalBackupPod populates a PodSpec with the containers and volumes needed
+// to export the databases of inCluster. When there is an upload container,
+// the export runs first as an init container.
+func LogicalBackupPod(
+	inCluster *v1beta1.PostgresCluster,
+	inPostgreSQLCertificate *corev1.SecretProjection,
+	inSecret *corev1.Secret,
+	outPod *corev1.PodSpec,
+) {
+	spec := inCluster.Spec.Backups.Logical
+
+	// Dump from the primary; replicas can cancel long queries that conflict
+	// with recovery.
+	// - https://www.postgresql.org/docs/current/hot-standby.html#HOT-STANDBY-CONFLICT
+	tlsVolume, tlsVolumeMount, env := connection(
+		inCluster, inPostgreSQLCertificate, inSecret,
+		naming.ClusterPrimaryService(inCluster).Name, "verify-full", "postgres")
+
+	dataVolumeMount := corev1.VolumeMount{Name: "pgdump", MountPath: logicalBackupDirectory}
+	dataVolume := corev1.Volume{Name: dataVolumeMount.Name}
+	if spec.VolumeClaimSpec != nil {
+		dataVolume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: naming.ClusterLogicalBackup(inCluster).Name,
+		}
+	} else {
+		dataVolume.EmptyDir = &corev1.EmptyDirVolumeSource{}
+	}
+
+	// Each export goes into a directory named for its Job. Kubernetes adds
+	// this label to every Pod of a Job.
+	// - https://docs.k8s.io/concepts/workloads/controllers/job/#pod-template
+	directory := []corev1.EnvVar{
+		{Name: "PGDUMP_JOB", ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{
+				FieldPath: "metadata.labels['job-name']",
+			},
+		}},
+		{Name: "PGDUMP_DIRECTORY", Value: logicalBackupDirectory + "/$(PGDUMP_JOB)"},
+	}
+
+	env = append(env, directory...)
+	if spec.Retain != nil {
+		env = append(env, corev1.EnvVar{
+			Name: "PGDUMP_RETAIN", Value: fmt.Sprint(*spec.Retain),
+		})
+	}
+
+	dump := corev1.Container{
+		Name: naming.ContainerLogicalBackup,
+
+		Command:         logicalBackupCommand(spec.Databases),
+		Env:             env,
+		Image:           config.PostgresContainerImage(inCluster),
+		ImagePullPolicy: inCluster.Spec.ImagePullPolicy,
+		Resources:       spec.Resources,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+
+		VolumeMounts: []corev1.VolumeMount{tlsVolumeMount, dataVolumeMount},
+	}
+
+	outPod.Volumes = []corev1.Volume{tlsVolume, dataVolume}
+
+	if spec.Upload == nil {
+		outPod.Containers = []corev1.Container{dump}
+		return
+	}
+
+	upload := corev1.Container{
+		Name: naming.ContainerLogicalBackupUpload,
+
+		Command:         spec.Upload.Command,
+		Env:             append(append([]corev1.EnvVar{}, directory...), spec.Upload.Env...),
+		Image:           spec.Upload.Image,
+		ImagePullPolicy: inCluster.Spec.ImagePullPolicy,
+		Resources:       spec.Upload.Resources,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+
+		VolumeMounts: []corev1.VolumeMount{dataVolumeMount},
+	}
+
+	outPod.InitContainers = []corev1.Container{dump}
+	outPod.Containers = []corev1.Container{upload}
+}
+
+// logicalBackupCommand returns the command that exports databases into the
+// PGDUMP_DIRECTORY and removes all but the newest PGDUMP_RETAIN exports. Only
+// superusers can read the passwords of roles, so those are not exported.
+func logicalBackupCommand(databases []v1beta1.PostgresIdentifier) []string {
+	const script = `
+mkdir -p "${PGDUMP_DIRECTORY}" && cd "${PGDUMP_DIRECTORY}"
+
+if (( $# )); then
+  pg_dumpall --globals-only --no-role-passwords --file=globals.sql
+  for database; do
+    PGDATABASE="${database}" pg_dump --format=custom --file="${database//\//_}.dump"
+  done
+else
+  pg_dumpall --no-role-passwords --file=all.sql
+fi
+
+if [[ -n "${PGDUMP_RETAIN-}" ]]; then
+  cd .. && ls -1dt -- */ | grep -vx 'lost+found/' |
+    tail -n "+$(( PGDUMP_RETAIN + 1 ))" | xargs --delimiter='\n' --no-run-if-empty rm -rf --
+fi
+`
+	command := []string{"bash", "-ceu", "--", strings.TrimSpace(script), "-"}
+	for _, database := range databases {
+		command = append(command, string(database))
+	}
+	return command
+}
@@ -0,0 +1,169 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/internal/testing/require"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestUserEnabled(t *testing.T) {
+	t.Parallel()
+
+	cluster := new(v1beta1.PostgresCluster)
+	assert.Assert(t, !UserEnabled(cluster))
+
+	cluster.Spec.Backups.Logical = &v1beta1.LogicalBackups{Schedule: "@daily"}
+	assert.Assert(t, UserEnabled(cluster))
+	assert.Assert(t, !Enabled(cluster), "expected no maintenance jobs")
+}
+
+func TestLogicalBackupPod(t *testing.T) {
+	t.Parallel()
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Name = "hippo"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.Image = "image-town"
+
+	certificate := &corev1.SecretProjection{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "some-cert"},
+		Items:                []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+	}
+	secret := new(corev1.Secret)
+	secret.Name = "hippo-maintenance"
+
+	t.Run("Volume", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Backups.Logical = &v1beta1.LogicalBackups{
+			Schedule:        "@daily",
+			Retain:          initialize.Int32(3),
+			VolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{},
+		}
+
+		pod := new(corev1.PodSpec)
+		LogicalBackupPod(cluster, certificate, secret, pod)
+
+		assert.Equal(t, len(pod.InitContainers), 0)
+		assert.Equal(t, len(pod.Containers), 1)
+		assert.Equal(t, pod.Containers[0].Name, "pgdump")
+
+		env := map[string]string{}
+		for _, v := range pod.Containers[0].Env {
+			env[v.Name] = v.Value
+		}
+		assert.Equal(t, env["PGHOST"], "hippo-primary")
+		assert.Equal(t, env["PGSSLMODE"], "verify-full")
+		assert.Equal(t, env["PGDUMP_DIRECTORY"], "/pgdump/$(PGDUMP_JOB)")
+		assert.Equal(t, env["PGDUMP_RETAIN"], "3")
+
+		assert.Assert(t, cmp.MarshalMatches(pod.Volumes, `
+- name: maintenance-tls
+  projected:
+    sources:
+    - secret:
+        items:
+        - key: ca.crt
+          path: ca.crt
+        name: some-cert
+- name: pgdump
+  persistentVolumeClaim:
+    claimName: hippo-logical-backup
+		`))
+	})
+
+	t.Run("Upload", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Backups.Logical = &v1beta1.LogicalBackups{
+			Schedule:  "@daily",
+			Databases: []v1beta1.PostgresIdentifier{"app"},
+			Upload: &v1beta1.LogicalBackupUpload{
+				Image:   "aws-cli",
+				Command: []string{"upload"},
+				Env:     []corev1.EnvVar{{Name: "AWS_REGION", Value: "us-east-1"}},
+			},
+		}
+
+		pod := new(corev1.PodSpec)
+		LogicalBackupPod(cluster, certificate, secret, pod)
+
+		// The export finishes before the upload starts.
+		assert.Equal(t, len(pod.InitContainers), 1)
+		assert.Equal(t, pod.InitContainers[0].Name, "pgdump")
+		assert.DeepEqual(t, pod.InitContainers[0].Command[4:], []string{"-", "app"})
+
+		assert.Assert(t, cmp.MarshalMatches(pod.Containers, `
+- command:
+  - upload
+  env:
+  - name: PGDUMP_JOB
+    valueFrom:
+      fieldRef:
+        fieldPath: metadata.labels['job-name']
+  - name: PGDUMP_DIRECTORY
+    value: /pgdump/$(PGDUMP_JOB)
+  - name: AWS_REGION
+    value: us-east-1
+  image: aws-cli
+  name: pgdump-upload
+  resources: {}
+  securityContext:
+    allowPrivilegeEscalation: false
+    capabilities:
+      drop:
+      - ALL
+    privileged: false
+    readOnlyRootFilesystem: true
+    runAsNonRoot: true
+  volumeMounts:
+  - mountPath: /pgdump
+    name: pgdump
+		`))
+
+		assert.Assert(t, cmp.MarshalMatches(pod.Volumes[1], `
+emptyDir: {}
+name: pgdump
+		`))
+	})
+}
+
+func TestLogicalBackupCommand(t *testing.T) {
+	shellcheck := require.ShellCheck(t)
+
+	command := logicalBackupCommand([]v1beta1.PostgresIdentifier{"one", "two"})
+	assert.DeepEqual(t, command[:3], []string{"bash", "-ceu", "--"})
+	assert.DeepEqual(t, command[4:], []string{"-", "one", "two"})
+
+	// Write out that inline script.
+	dir := t.TempDir()
+	file := filepath.Join(dir, "script.bash")
+	assert.NilError(t, os.WriteFile(file, []byte(command[3]), 0o600))
+
+	// Expect shellcheck to be happy.
+	cmd := exec.Command(shellcheck, "--enable=all", "--shell=bash", file)
+	output, err := cmd.CombinedOutput()
+	assert.NilError(t, err, "%q\n%s", cmd.Args, output)
+}
@@ -0,0 +1,223 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/postgres/password"
+	"github.com/crunchydata/postgres-operator/internal/util"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const postgresqlUser = "_crunchymaintenance"
+
+// Enabled returns whether or not cluster has any scheduled maintenance.
+func Enabled(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.Maintenance != nil && len(cluster.Spec.Maintenance.Jobs) > 0
+}
+
+// UserEnabled returns whether or not anything scheduled in cluster connects
+// as the maintenance user: maintenance jobs or logical backups.
+func UserEnabled(cluster *v1beta1.PostgresCluster) bool {
+	return Enabled(cluster) || LogicalBackupsEnabled(cluster)
+}
+
+// DisableInPostgreSQL removes the maintenance user. Anything it owns is given
+// to the "postgres" superuser first.
+func DisableInPostgreSQL(ctx context.Context, exec postgres.Executor) error {
+	log := logging.FromContext(ctx)
+
+	// Maintenance SQL may have created objects in any database. Keep them;
+	// they could be important to the application. Then drop the privileges
+	// granted to the maintenance user so that it can be removed.
+	// - https://www.postgresql.org/docs/current/role-removal.html
+	stdout, stderr, err := exec.ExecInAllDatabases(ctx,
+		strings.TrimSpace(`
+SELECT pg_catalog.format('REASSIGN OWNED BY %I TO %I', :'username', 'postgres'),
+       pg_catalog.format('DROP OWNED BY %I', :'username')
+ WHERE EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = :'username')
+\gexec`),
+		map[string]string{
+			"username": postgresqlUser,
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("reassigned maintenance objects", "stdout", stdout, "stderr", stderr)
+
+	if err == nil {
+		stdout, stderr, err = exec.ExecInDatabasesFromQuery(ctx,
+			`SELECT pg_catalog.current_database()`,
+			`SET client_min_messages = WARNING; DROP ROLE IF EXISTS :"username";`,
+			map[string]string{
+				"username": postgresqlUser,
+
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("removed maintenance user", "stdout", stdout, "stderr", stderr)
+	}
+
+	return err
+}
+
+// EnableInPostgreSQL creates the maintenance user and sets its password. It
+// also creates the extensions used by "Amcheck" and "Repack" jobs in the
+// databases they run against.
+func EnableInPostgreSQL(
+	ctx context.Context, exec postgres.Executor,
+	cluster *v1beta1.PostgresCluster, clusterSecret *corev1.Secret,
+) error {
+	log := logging.FromContext(ctx)
+
+	// The maintenance user logs in over the network, so it is never a
+	// superuser. It has the predefined roles below and anything else that is
+	// granted to it in PostgreSQL.
+	// - https://www.postgresql.org/docs/current/predefined-roles.html
+	statements := []string{
+		// Quiet NOTICE messages from IF NOT EXISTS statements.
+		// - https://www.postgresql.org/docs/current/runtime-config-client.html
+		`SET client_min_messages = WARNING;`,
+
+		strings.TrimSpace(`
+SELECT pg_catalog.format('CREATE ROLE %I NOLOGIN', :'username')
+ WHERE NOT EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = :'username')
+\gexec`),
+
+		`ALTER ROLE :"username" NOSUPERUSER NOCREATEDB NOCREATEROLE NOREPLICATION NOBYPASSRLS` +
+			` LOGIN PASSWORD :'verifier';`,
+	}
+
+	// pg_dump can read every table with the "pg_read_all_data" role of
+	// PostgreSQL 14.
+	if cluster.Spec.PostgresVersion >= 14 {
+		if LogicalBackupsEnabled(cluster) {
+			statements = append(statements, `GRANT pg_read_all_data TO :"username";`)
+		} else {
+			statements = append(statements, `REVOKE pg_read_all_data FROM :"username";`)
+		}
+	}
+
+	stdout, stderr, err := exec.ExecInDatabasesFromQuery(ctx,
+		`SELECT pg_catalog.current_database()`,
+		strings.Join(statements, "\n"),
+		map[string]string{
+			"username": postgresqlUser,
+			"verifier": string(clusterSecret.Data[verifierSecretKey]),
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("applied maintenance user", "stdout", stdout, "stderr", stderr)
+
+	// Replicas are read-only, so extensions must be created on the primary
+	// before anything can use them. Only superusers can create these, and the
+	// functions of amcheck are restricted to superusers until granted.
+	// - https://www.postgresql.org/docs/current/amcheck.html
+	// - https://reorg.github.io/pg_repack/#installation
+	for _, extension := range []struct{ job, sql string }{
+		{
+			job: v1beta1.MaintenanceAmcheck,
+			sql: strings.TrimSpace(`
+CREATE EXTENSION IF NOT EXISTS amcheck;
+SELECT pg_catalog.format('GRANT EXECUTE ON FUNCTION %s TO %I', objid::pg_catalog.regprocedure, :'username')
+  FROM pg_catalog.pg_depend
+ WHERE classid = 'pg_catalog.pg_proc'::pg_catalog.regclass AND deptype = 'e'
+   AND refobjid = (SELECT oid FROM pg_catalog.pg_extension WHERE extname = 'amcheck')
+\gexec`),
+		},
+		{
+			job: v1beta1.MaintenanceRepack,
+			sql: strings.TrimSpace(`
+CREATE EXTENSION IF NOT EXISTS pg_repack;
+GRANT USAGE, CREATE ON SCHEMA repack TO :"username";`),
+		},
+	} {
+		databases := jobDatabases(cluster, extension.job)
+		if err != nil || len(databases) == 0 {
+			continue
+		}
+
+		var encoded []byte
+		encoded, err = json.Marshal(databases)
+
+		if err == nil {
+			stdout, stderr, err = exec.ExecInDatabasesFromQuery(ctx,
+				`SELECT datname FROM pg_catalog.pg_database`+
+					` WHERE datname IN (SELECT pg_catalog.json_array_elements_text(:'databases'))`,
+				strings.Join([]string{
+					`SET client_min_messages = WARNING;`,
+					extension.sql,
+				}, "\n"),
+				map[string]string{
+					"databases": string(encoded),
+					"username":  postgresqlUser,
+
+					"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+					"QUIET":         "on", // Do not print successful statements to stdout.
+				})
+
+			log.V(1).Info("enabled extension", "job", extension.job, "stdout", stdout, "stderr", stderr)
+		}
+	}
+
+	return err
+}
+
+// jobDatabases returns the sorted names of databases in which jobs of kind
+// jobType run in cluster.
+func jobDatabases(cluster *v1beta1.PostgresCluster, jobType string) []string {
+	databases := sets.NewString()
+	if Enabled(cluster) {
+		for _, job := range cluster.Spec.Maintenance.Jobs {
+			if job.Type == jobType {
+				databases.Insert(string(job.Database))
+			}
+		}
+	}
+	return databases.List()
+}
+
+func generatePassword() (plaintext, verifier string, err error) {
+	plaintext, err = util.GenerateASCIIPassword(32)
+	if err == nil {
+		verifier, err = password.NewSCRAMPassword(plaintext).Build()
+	}
+	return
+}
+
+func postgresqlHBAs() []postgres.HostBasedAuthentication {
+	// Maintenance jobs must connect over TLS using a SCRAM password. Other
+	// network connections are forbidden.
+	// - https://www.postgresql.org/docs/current/auth-pg-hba-conf.html
+	// - https://www.postgresql.org/docs/current/auth-password.html
+
+	return []postgres.HostBasedAuthentication{
+		*postgres.NewHBA().User(postgresqlUser).TLS().Method("scram-sha-256"),
+		*postgres.NewHBA().User(postgresqlUser).TCP().Method("reject"),
+	}
+}
@@ -0,0 +1,222 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestEnabled(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	assert.Assert(t, !Enabled(cluster))
+
+	cluster.Spec.Maintenance = new(v1beta1.MaintenanceSpec)
+	assert.Assert(t, !Enabled(cluster))
+
+	cluster.Spec.Maintenance.Jobs = []v1beta1.MaintenanceJobSpec{{Name: "some"}}
+	assert.Assert(t, Enabled(cluster))
+}
+
+func TestDisableInPostgreSQL(t *testing.T) {
+	expected := errors.New("whoops")
+	calls := 0
+
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		calls++
+		assert.Assert(t, stdout != nil, "should capture stdout")
+		assert.Assert(t, stderr != nil, "should capture stderr")
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+
+		gomega.NewWithT(t).Expect(command).To(gomega.ContainElement(
+			`--set=username=_crunchymaintenance`,
+		), "expected query parameters")
+
+		switch calls {
+		case 1:
+			assert.Assert(t, strings.Contains(strings.Join(command, "\n"),
+				`SELECT datname FROM pg_catalog.pg_database`,
+			), "expected all databases and templates")
+			assert.Equal(t, string(b), strings.TrimSpace(`
+SELECT pg_catalog.format('REASSIGN OWNED BY %I TO %I', :'username', 'postgres'),
+       pg_catalog.format('DROP OWNED BY %I', :'username')
+ WHERE EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = :'username')
+\gexec`))
+			return nil
+		default:
+			gomega.NewWithT(t).Expect(command).To(gomega.ContainElement(
+				`SELECT pg_catalog.current_database()`,
+			), "expected the default database")
+			assert.Equal(t, string(b), `SET client_min_messages = WARNING; DROP ROLE IF EXISTS :"username";`)
+			return expected
+		}
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, expected, DisableInPostgreSQL(ctx, exec))
+	assert.Equal(t, calls, 2)
+}
+
+func TestEnableInPostgreSQL(t *testing.T) {
+	expected := errors.New("whoops")
+	secret := new(corev1.Secret)
+	secret.Data = map[string][]byte{
+		"verifier": []byte("digest$and==:whatnot"),
+	}
+
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		assert.Assert(t, stdout != nil, "should capture stdout")
+		assert.Assert(t, stderr != nil, "should capture stderr")
+		gomega.NewWithT(t).Expect(command).To(gomega.ContainElement(
+			`SELECT pg_catalog.current_database()`,
+		), "expected the default database")
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Equal(t, string(b), strings.TrimSpace(`
+SET client_min_messages = WARNING;
+SELECT pg_catalog.format('CREATE ROLE %I NOLOGIN', :'username')
+ WHERE NOT EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = :'username')
+\gexec
+ALTER ROLE :"username" NOSUPERUSER NOCREATEDB NOCREATEROLE NOREPLICATION NOBYPASSRLS LOGIN PASSWORD :'verifier';`))
+
+		gomega.NewWithT(t).Expect(command).To(gomega.ContainElements(
+			`--set=username=_crunchymaintenance`,
+			`--set=verifier=digest$and==:whatnot`,
+		), "expected query parameters")
+
+		return expected
+	}
+
+	ctx := context.Background()
+	cluster := new(v1beta1.PostgresCluster)
+	assert.Equal(t, expected, EnableInPostgreSQL(ctx, exec, cluster, secret))
+
+	t.Run("PredefinedRoles", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.PostgresVersion = 15
+		cluster.Spec.Maintenance = &v1beta1.MaintenanceSpec{
+			Jobs: []v1beta1.MaintenanceJobSpec{{Name: "a", Type: "Vacuum"}},
+		}
+
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, strings.HasSuffix(string(b),
+				"'verifier';\n"+`REVOKE pg_read_all_data FROM :"username";`), "got %q", b)
+			return expected
+		}
+
+		assert.Equal(t, expected, EnableInPostgreSQL(ctx, exec, cluster, secret))
+
+		cluster.Spec.PostgresVersion = 14
+		cluster.Spec.Maintenance = nil
+		cluster.Spec.Backups.Logical = new(v1beta1.LogicalBackups)
+
+		exec = func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, strings.HasSuffix(string(b),
+				"'verifier';\n"+`GRANT pg_read_all_data TO :"username";`), "got %q", b)
+			return expected
+		}
+
+		assert.Equal(t, expected, EnableInPostgreSQL(ctx, exec, cluster, secret))
+	})
+
+	t.Run("Extensions", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.Maintenance = &v1beta1.MaintenanceSpec{
+			Jobs: []v1beta1.MaintenanceJobSpec{
+				{Name: "a", Type: "Amcheck", Database: "two"},
+				{Name: "b", Type: "Vacuum", Database: "three"},
+				{Name: "c", Type: "Amcheck", Database: "one"},
+				{Name: "d", Type: "Amcheck", Database: "two"},
+				{Name: "e", Type: "Repack", Database: "four"},
+			},
+		}
+
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+			if calls == 1 {
+				return nil
+			}
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			gomega.NewWithT(t).Expect(command).To(gomega.ContainElements(
+				`SELECT datname FROM pg_catalog.pg_database WHERE datname IN (SELECT pg_catalog.json_array_elements_text(:'databases'))`,
+				`--set=username=_crunchymaintenance`,
+			), "expected query parameters")
+
+			if calls == 2 {
+				assert.Equal(t, string(b), strings.TrimSpace(`
+SET client_min_messages = WARNING;
+CREATE EXTENSION IF NOT EXISTS amcheck;
+SELECT pg_catalog.format('GRANT EXECUTE ON FUNCTION %s TO %I', objid::pg_catalog.regprocedure, :'username')
+  FROM pg_catalog.pg_depend
+ WHERE classid = 'pg_catalog.pg_proc'::pg_catalog.regclass AND deptype = 'e'
+   AND refobjid = (SELECT oid FROM pg_catalog.pg_extension WHERE extname = 'amcheck')
+\gexec`))
+				gomega.NewWithT(t).Expect(command).To(gomega.ContainElement(
+					`--set=databases=["one","two"]`,
+				), "expected only the checked databases")
+				return nil
+			}
+
+			assert.Equal(t, string(b), strings.TrimSpace(`
+SET client_min_messages = WARNING;
+CREATE EXTENSION IF NOT EXISTS pg_repack;
+GRANT USAGE, CREATE ON SCHEMA repack TO :"username";`))
+			gomega.NewWithT(t).Expect(command).To(gomega.ContainElement(
+				`--set=databases=["four"]`,
+			), "expected only the repacked databases")
+			return expected
+		}
+
+		assert.Equal(t, expected, EnableInPostgreSQL(ctx, exec, cluster, secret))
+		assert.Equal(t, calls, 3)
+	})
+}
+
+func TestPostgreSQLHBAs(t *testing.T) {
+	rules := postgresqlHBAs()
+	assert.Equal(t, len(rules), 2)
+	assert.Equal(t, rules[0].String(), `hostssl all "_crunchymaintenance" all scram-sha-256`)
+	assert.Equal(t, rules[1].String(), `host all "_crunchymaintenance" all reject`)
+}
@@ -0,0 +1,298 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// configDirectory is where the PostgreSQL certificate authority is mounted.
+	configDirectory = "/etc/maintenance"
+
+	certAuthorityAbsolutePath   = configDirectory + "/" + certAuthorityProjectionPath
+	certAuthorityProjectionPath = "ca.crt"
+
+	passwordSecretKey = "password"
+	verifierSecretKey = "verifier"
+)
+
+// Secret populates the maintenance Secret.
+func Secret(ctx context.Context,
+	inCluster *v1beta1.PostgresCluster,
+	inSecret *corev1.Secret,
+	outSecret *corev1.Secret,
+) error {
+	if !UserEnabled(inCluster) {
+		// There is no maintenance; there is nothing to do.
+		return nil
+	}
+
+	var err error
+	initialize.ByteMap(&outSecret.Data)
+
+	// Use the existing password and verifier. Generate both when either is missing.
+	plaintext := string(inSecret.Data[passwordSecretKey])
+	verifier := string(inSecret.Data[verifierSecretKey])
+
+	if len(plaintext) == 0 || len(verifier) == 0 {
+		plaintext, verifier, err = generatePassword()
+		err = errors.WithStack(err)
+	}
+
+	if err == nil {
+		outSecret.Data[passwordSecretKey] = []byte(plaintext)
+		outSecret.Data[verifierSecretKey] = []byte(verifier)
+	}
+
+	return err
+}
+
+// Pod populates a PodSpec with the container and volumes needed to run job
+// against the PostgreSQL instances of inCluster.
+func Pod(
+	inCluster *v1beta1.PostgresCluster,
+	inJob *v1beta1.MaintenanceJobSpec,
+	inPostgreSQLCertificate *corev1.SecretProjection,
+	inSecret *corev1.Secret,
+	outPod *corev1.PodSpec,
+) {
+	// The certificate of PostgreSQL names only the primary Service. Verify the
+	// certificate authority, but not the hostname, when connecting to replicas.
+	// - https://www.postgresql.org/docs/current/libpq-ssl.html
+	host, sslmode := naming.ClusterPrimaryService(inCluster).Name, "verify-full"
+	if inJob.Target == "replica" {
+		host, sslmode = naming.ClusterReplicaService(inCluster).Name, "verify-ca"
+	}
+
+	database := string(inJob.Database)
+	if database == "" {
+		database = "postgres"
+	}
+
+	tlsVolume, tlsVolumeMount, env := connection(
+		inCluster, inPostgreSQLCertificate, inSecret, host, sslmode, database)
+
+	container := corev1.Container{
+		Name: naming.ContainerMaintenance,
+
+		Command:         jobCommand(inJob),
+		Env:             env,
+		Image:           config.PostgresContainerImage(inCluster),
+		ImagePullPolicy: inCluster.Spec.ImagePullPolicy,
+		Resources:       inJob.Resources,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+
+		VolumeMounts: []corev1.VolumeMount{tlsVolumeMount},
+	}
+
+	outPod.Containers = []corev1.Container{container}
+	outPod.Volumes = []corev1.Volume{tlsVolume}
+}
+
+// connection returns the volume, its mount, and the environment variables
+// that connect libpq to host as the maintenance user.
+func connection(
+	inCluster *v1beta1.PostgresCluster,
+	inPostgreSQLCertificate *corev1.SecretProjection,
+	inSecret *corev1.Secret,
+	host, sslmode, database string,
+) (corev1.Volume, corev1.VolumeMount, []corev1.EnvVar) {
+	tlsVolumeMount := corev1.VolumeMount{
+		Name: "maintenance-tls", MountPath: configDirectory, ReadOnly: true,
+	}
+	tlsVolume := corev1.Volume{Name: tlsVolumeMount.Name}
+	tlsVolume.Projected = &corev1.ProjectedVolumeSource{
+		Sources: []corev1.VolumeProjection{
+			certificateAuthority(inPostgreSQLCertificate),
+		},
+	}
+
+	// Connect using libpq environment variables.
+	// - https://www.postgresql.org/docs/current/libpq-envars.html
+	env := []corev1.EnvVar{
+		{Name: "PGAPPNAME", Value: "postgres-operator-maintenance"},
+		{Name: "PGDATABASE", Value: database},
+		{Name: "PGHOST", Value: host},
+		{Name: "PGPASSWORD", ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: inSecret.Name},
+				Key:                  passwordSecretKey,
+			},
+		}},
+		{Name: "PGPORT", Value: fmt.Sprint(*inCluster.Spec.Port)},
+		{Name: "PGSSLMODE", Value: sslmode},
+		{Name: "PGSSLROOTCERT", Value: certAuthorityAbsolutePath},
+		{Name: "PGUSER", Value: postgresqlUser},
+	}
+
+	return tlsVolume, tlsVolumeMount, env
+}
+
+// PostgreSQL populates outHBAs with any records needed to run maintenance jobs.
+func PostgreSQL(
+	inCluster *v1beta1.PostgresCluster,
+	outHBAs *postgres.HBAs,
+) {
+	if !UserEnabled(inCluster) {
+		// There is no maintenance; there is nothing to do.
+		return
+	}
+
+	outHBAs.Mandatory = append(outHBAs.Mandatory, postgresqlHBAs()...)
+}
+
+// certificateAuthority creates a volume projection of the PostgreSQL server
+// certificate authority.
+func certificateAuthority(postgres *corev1.SecretProjection) corev1.VolumeProjection {
+	var items []corev1.KeyToPath
+	result := postgres.DeepCopy()
+
+	for i := range result.Items {
+		// The PostgreSQL server projection expects Path to match typical Keys.
+		if result.Items[i].Path == certAuthorityProjectionPath {
+			items = append(items, result.Items[i])
+		}
+	}
+
+	if len(items) == 0 {
+		items = []corev1.KeyToPath{{
+			Key:  certAuthorityProjectionPath,
+			Path: certAuthorityProjectionPath,
+		}}
+	}
+
+	result.Items = items
+	return corev1.VolumeProjection{Secret: result}
+}
+
+// ownerCheckScript fails when the current user cannot maintain a table selected
+// by the JSON array in its first argument, or any table when that is empty.
+// Otherwise, it runs the command in its remaining arguments. The current user
+// can maintain tables it owns and, since PostgreSQL 17, tables on which it has
+// the MAINTAIN privilege, such as through pg_maintain. When the second argument
+// is "true", owning the database is enough, too.
+// Tables in system schemas are ignored; some only superusers can maintain.
+const ownerCheckScript = `
+tables=$(psql --no-psqlrc --quiet --no-align --tuples-only --set=ON_ERROR_STOP=1 \
+  --set=tables="$1" --set=database="$2" --file=- <<'SQL'
+SELECT c.oid::pg_catalog.regclass
+  FROM pg_catalog.pg_class c
+  JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+ WHERE c.relkind IN ('r', 'm', 'p')
+   AND n.nspname !~ '^pg_' AND n.nspname <> 'information_schema'
+   AND NOT CASE
+         WHEN pg_catalog.current_setting('server_version_num')::integer >= 170000
+         THEN pg_catalog.has_table_privilege(c.oid, 'MAINTAIN')
+         ELSE pg_catalog.pg_has_role(c.relowner, 'USAGE') END
+   AND NOT (:database AND pg_catalog.pg_has_role((SELECT datdba FROM pg_catalog.pg_database
+         WHERE datname = pg_catalog.current_database()), 'USAGE'))
+   AND (pg_catalog.json_array_length(:'tables') = 0 OR c.oid IN (
+         SELECT pg_catalog.regexp_replace(value, '\s*\(.*\)\s*$', '')::pg_catalog.regclass
+           FROM pg_catalog.json_array_elements_text(:'tables')))
+ ORDER BY 1
+SQL
+)
+if [[ -n "${tables}" ]]; then
+  printf >&2 'the current user cannot maintain these tables:\n%s\n' "${tables}"
+  exit 1
+fi
+exec "${@:3}"
+`
+
+// jobCommand returns the command that performs job.
+func jobCommand(job *v1beta1.MaintenanceJobSpec) []string {
+	var command []string
+
+	switch job.Type {
+	case v1beta1.MaintenanceSQL:
+		// Send the statements through standard input so that psql runs each
+		// one separately, outside of a transaction block. This allows commands
+		// like REINDEX CONCURRENTLY and VACUUM.
+		// - https://www.postgresql.org/docs/current/app-psql.html
+		const script = `exec psql --no-psqlrc --set=ON_ERROR_STOP=1 --file=- <<< "$1"`
+		return []string{"bash", "-ceu", "--", script, "-", job.SQL}
+
+	case v1beta1.MaintenanceAmcheck:
+		// pg_amcheck exits nonzero and describes any corruption it finds. It
+		// also checks the B-tree indexes of any selected tables.
+		// - https://www.postgresql.org/docs/current/app-pgamcheck.html
+		command = []string{"pg_amcheck", "--echo"}
+		if job.HeapAllIndexed {
+			command = append(command, "--heapallindexed")
+		}
+
+	case v1beta1.MaintenanceRepack:
+		// The pg_repack extension is created before the job runs. The client
+		// expects a superuser, but table owners are able to repack.
+		// - https://reorg.github.io/pg_repack/#options
+		command = []string{"pg_repack", "--no-superuser-check", "--echo"}
+
+	case v1beta1.MaintenanceReindex:
+		// - https://www.postgresql.org/docs/current/app-reindexdb.html
+		command = []string{"reindexdb", "--concurrently", "--echo"}
+
+	case v1beta1.MaintenanceAnalyze:
+		// - https://www.postgresql.org/docs/current/app-vacuumdb.html
+		command = []string{"vacuumdb", "--analyze-only", "--echo"}
+
+	case v1beta1.MaintenanceVacuumAnalyze:
+		command = []string{"vacuumdb", "--analyze", "--echo"}
+
+	default:
+		command = []string{"vacuumdb", "--echo"}
+	}
+
+	for _, table := range job.Tables {
+		command = append(command, "--table="+table)
+	}
+
+	if job.Type == v1beta1.MaintenanceAmcheck {
+		return command
+	}
+
+	// Only the owner of a table or its database can vacuum or analyze it.
+	// vacuumdb skips other tables with a warning and exits zero, so check
+	// ownership first and fail rather than report success. Before PostgreSQL
+	// 17, reindexing or repacking a table requires owning that table; owning
+	// the database is not enough.
+	// - https://www.postgresql.org/docs/current/sql-vacuum.html
+	// - https://www.postgresql.org/docs/current/sql-reindex.html
+	tables := job.Tables
+	if tables == nil {
+		tables = []string{}
+	}
+	encoded, _ := json.Marshal(tables)
+	database := job.Type != v1beta1.MaintenanceReindex &&
+		job.Type != v1beta1.MaintenanceRepack
+
+	return append([]string{
+		"bash", "-ceu", "--", ownerCheckScript, "-", string(encoded),
+		strconv.FormatBool(database),
+	}, command...)
+}
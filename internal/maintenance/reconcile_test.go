@@ -0,0 +1,272 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/internal/testing/require"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestSecret(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cluster := new(v1beta1.PostgresCluster)
+	existing := new(corev1.Secret)
+	intent := new(corev1.Secret)
+
+	// Nothing happens when there is no maintenance.
+	assert.NilError(t, Secret(ctx, cluster, existing, intent))
+	assert.Assert(t, intent.Data == nil)
+
+	cluster.Spec.Maintenance = &v1beta1.MaintenanceSpec{
+		Jobs: []v1beta1.MaintenanceJobSpec{{Name: "some"}},
+	}
+
+	// A password and verifier are generated.
+	assert.NilError(t, Secret(ctx, cluster, existing, intent))
+	assert.Assert(t, len(intent.Data["password"]) > 0)
+	assert.Assert(t, len(intent.Data["verifier"]) > 0)
+
+	// They are kept on later calls.
+	existing.Data = intent.Data
+	intent = new(corev1.Secret)
+	assert.NilError(t, Secret(ctx, cluster, existing, intent))
+	assert.DeepEqual(t, intent.Data, existing.Data)
+}
+
+func TestPod(t *testing.T) {
+	t.Parallel()
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Name = "hippo"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.Image = "image-town"
+
+	certificate := &corev1.SecretProjection{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "some-cert"},
+		Items: []corev1.KeyToPath{
+			{Key: "ca.crt", Path: "ca.crt"},
+			{Key: "tls.crt", Path: "tls.crt"},
+		},
+	}
+	secret := new(corev1.Secret)
+	secret.Name = "hippo-maintenance"
+
+	t.Run("Primary", func(t *testing.T) {
+		job := &v1beta1.MaintenanceJobSpec{
+			Name:   "vacuum",
+			Type:   "VacuumAnalyze",
+			Tables: []string{"public.orders", `"Mixed Case"`},
+		}
+		pod := new(corev1.PodSpec)
+		Pod(cluster, job, certificate, secret, pod)
+
+		assert.Assert(t, cmp.MarshalMatches(pod, `
+containers:
+- command:
+  - bash
+  - -ceu
+  - --
+  - |2
+
+    tables=$(psql --no-psqlrc --quiet --no-align --tuples-only --set=ON_ERROR_STOP=1 \
+      --set=tables="$1" --set=database="$2" --file=- <<'SQL'
+    SELECT c.oid::pg_catalog.regclass
+      FROM pg_catalog.pg_class c
+      JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+     WHERE c.relkind IN ('r', 'm', 'p')
+       AND n.nspname !~ '^pg_' AND n.nspname <> 'information_schema'
+       AND NOT CASE
+             WHEN pg_catalog.current_setting('server_version_num')::integer >= 170000
+             THEN pg_catalog.has_table_privilege(c.oid, 'MAINTAIN')
+             ELSE pg_catalog.pg_has_role(c.relowner, 'USAGE') END
+       AND NOT (:database AND pg_catalog.pg_has_role((SELECT datdba FROM pg_catalog.pg_database
+             WHERE datname = pg_catalog.current_database()), 'USAGE'))
+       AND (pg_catalog.json_array_length(:'tables') = 0 OR c.oid IN (
+             SELECT pg_catalog.regexp_replace(value, '\s*\(.*\)\s*$', '')::pg_catalog.regclass
+               FROM pg_catalog.json_array_elements_text(:'tables')))
+     ORDER BY 1
+    SQL
+    )
+    if [[ -n "${tables}" ]]; then
+      printf >&2 'the current user cannot maintain these tables:\n%s\n' "${tables}"
+      exit 1
+    fi
+    exec "${@:3}"
+  - '-'
+  - '["public.orders","\"Mixed Case\""]'
+  - "true"
+  - vacuumdb
+  - --analyze
+  - --echo
+  - --table=public.orders
+  - --table="Mixed Case"
+  env:
+  - name: PGAPPNAME
+    value: postgres-operator-maintenance
+  - name: PGDATABASE
+    value: postgres
+  - name: PGHOST
+    value: hippo-primary
+  - name: PGPASSWORD
+    valueFrom:
+      secretKeyRef:
+        key: password
+        name: hippo-maintenance
+  - name: PGPORT
+    value: "5432"
+  - name: PGSSLMODE
+    value: verify-full
+  - name: PGSSLROOTCERT
+    value: /etc/maintenance/ca.crt
+  - name: PGUSER
+    value: _crunchymaintenance
+  image: image-town
+  name: maintenance
+  resources: {}
+  securityContext:
+    allowPrivilegeEscalation: false
+    capabilities:
+      drop:
+      - ALL
+    privileged: false
+    readOnlyRootFilesystem: true
+    runAsNonRoot: true
+  volumeMounts:
+  - mountPath: /etc/maintenance
+    name: maintenance-tls
+    readOnly: true
+volumes:
+- name: maintenance-tls
+  projected:
+    sources:
+    - secret:
+        items:
+        - key: ca.crt
+          path: ca.crt
+        name: some-cert
+		`))
+	})
+
+	t.Run("Replica", func(t *testing.T) {
+		job := &v1beta1.MaintenanceJobSpec{
+			Name: "report", Type: "SQL", Target: "replica", Database: "app",
+			SQL: "SELECT 1;",
+		}
+		pod := new(corev1.PodSpec)
+		Pod(cluster, job, certificate, secret, pod)
+
+		assert.DeepEqual(t, pod.Containers[0].Command, []string{
+			"bash", "-ceu", "--",
+			`exec psql --no-psqlrc --set=ON_ERROR_STOP=1 --file=- <<< "$1"`,
+			"-", "SELECT 1;",
+		})
+		env := map[string]string{}
+		for _, v := range pod.Containers[0].Env {
+			env[v.Name] = v.Value
+		}
+		assert.Equal(t, env["PGDATABASE"], "app")
+		assert.Equal(t, env["PGHOST"], "hippo-replicas")
+		assert.Equal(t, env["PGSSLMODE"], "verify-ca")
+	})
+}
+
+func TestJobCommand(t *testing.T) {
+	checked := func(tables, database string, command ...string) []string {
+		return append([]string{"bash", "-ceu", "--", ownerCheckScript, "-", tables, database}, command...)
+	}
+
+	for _, tt := range []struct {
+		job    v1beta1.MaintenanceJobSpec
+		expect []string
+	}{
+		{
+			job:    v1beta1.MaintenanceJobSpec{Type: "Analyze"},
+			expect: checked(`[]`, "true", "vacuumdb", "--analyze-only", "--echo"),
+		},
+		{
+			job:    v1beta1.MaintenanceJobSpec{Type: "Vacuum", Tables: []string{"t"}},
+			expect: checked(`["t"]`, "true", "vacuumdb", "--echo", "--table=t"),
+		},
+		{
+			job:    v1beta1.MaintenanceJobSpec{Type: "VacuumAnalyze", Tables: []string{"t(a, b)"}},
+			expect: checked(`["t(a, b)"]`, "true", "vacuumdb", "--analyze", "--echo", "--table=t(a, b)"),
+		},
+		{
+			job:    v1beta1.MaintenanceJobSpec{Type: "Repack", Tables: []string{"public.t"}},
+			expect: checked(`["public.t"]`, "false", "pg_repack", "--no-superuser-check", "--echo", "--table=public.t"),
+		},
+		{
+			job:    v1beta1.MaintenanceJobSpec{Type: "Amcheck"},
+			expect: []string{"pg_amcheck", "--echo"},
+		},
+		{
+			job:    v1beta1.MaintenanceJobSpec{Type: "Amcheck", HeapAllIndexed: true, Tables: []string{"t"}},
+			expect: []string{"pg_amcheck", "--echo", "--heapallindexed", "--table=t"},
+		},
+		{
+			job:    v1beta1.MaintenanceJobSpec{Type: "Reindex", Tables: []string{"a", "b"}},
+			expect: checked(`["a","b"]`, "false", "reindexdb", "--concurrently", "--echo", "--table=a", "--table=b"),
+		},
+	} {
+		assert.DeepEqual(t, jobCommand(&tt.job), tt.expect)
+	}
+
+	t.Run("ShellCheck", func(t *testing.T) {
+		shellcheck := require.ShellCheck(t)
+
+		// Write out that inline script.
+		dir := t.TempDir()
+		file := filepath.Join(dir, "script.bash")
+		assert.NilError(t, os.WriteFile(file, []byte(ownerCheckScript), 0o600))
+
+		// Expect shellcheck to be happy.
+		cmd := exec.Command(shellcheck, "--enable=all", "--shell=bash", file)
+		output, err := cmd.CombinedOutput()
+		assert.NilError(t, err, "%q\n%s", cmd.Args, output)
+	})
+}
+
+func TestPostgreSQL(t *testing.T) {
+	t.Parallel()
+
+	cluster := new(v1beta1.PostgresCluster)
+	hbas := postgres.HBAs{}
+
+	PostgreSQL(cluster, &hbas)
+	assert.Equal(t, len(hbas.Mandatory), 0)
+
+	cluster.Spec.Maintenance = &v1beta1.MaintenanceSpec{
+		Jobs: []v1beta1.MaintenanceJobSpec{{Name: "some"}},
+	}
+	PostgreSQL(cluster, &hbas)
+	assert.Equal(t, len(hbas.Mandatory), 2)
+	assert.Equal(t, hbas.Mandatory[0].String(), postgresqlHBAs()[0].String())
+	assert.Equal(t, hbas.Mandatory[1].String(), postgresqlHBAs()[1].String())
+}
@@ -37,6 +37,15 @@ const (
 	// (and therefore must be recreated)
 	PGBackRestConfigHash = annotationPrefix + "pgbackrest-hash"
 
+	// PodTemplateHashWithoutImages is an annotation used to specify the hash value
+	// of an instance Pod template with its container images removed. A Pod with
+	// the same value as its template differs from it only in images.
+	PodTemplateHashWithoutImages = annotationPrefix + "template-hash-without-images"
+
+	// HAProxyConfigHash is an annotation used to specify the hash value of the HAProxy
+	// configuration so that HAProxy pods restart when it changes.
+	HAProxyConfigHash = annotationPrefix + "haproxy-hash"
+
 	// PGBackRestCurrentConfig is an annotation used to indicate the name of the pgBackRest
 	// configuration associated with a specific Job as determined by either the current primary
 	// (if no dedicated repository host is enabled), or the dedicated repository host.  This helps
@@ -53,9 +62,13 @@ const (
 
 	// PGBackRestIPVersion is an annotation used to indicate whether an IPv6 wildcard address should be
 	// used for the pgBackRest "tls-server-address" or not. If the user wants to use IPv6, the value
-	// should be "IPv6". As of right now, if the annotation is not present or if the annotation's value
-	// is anything other than "IPv6", the "tls-server-address" will default to IPv4 (0.0.0.0). The need
+	// should be "IPv6". The same happens when IPv6 is the first of the cluster Service's IP families.
+	// Otherwise, the "tls-server-address" will default to IPv4 (0.0.0.0). The need
 	// for this annotation is due to an issue in pgBackRest (#1841) where using a wildcard address to
 	// bind all addresses does not work in certain IPv6 environments.
 	PGBackRestIPVersion = annotationPrefix + "pgbackrest-ip-version"
+
+	// MigrationRevision is the annotation on the migration Job that identifies
+	// the revision of the migration it runs.
+	MigrationRevision = annotationPrefix + "migration-revision"
 )
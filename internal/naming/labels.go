@@ -33,12 +33,19 @@ const (
 	LabelPatroni = labelPrefix + "patroni"
 	LabelRole    = labelPrefix + "role"
 
+	// LabelCitusRole is the label that Patroni sets on the Pods of Citus
+	// workers in place of LabelRole. Only the coordinator has LabelRole.
+	LabelCitusRole = labelPrefix + "citus-role"
+
 	// LabelClusterCertificate is used to identify a secret containing a cluster certificate
 	LabelClusterCertificate = labelPrefix + "cluster-certificate"
 
 	// LabelData is used to identify Pods and Volumes store Postgres data.
 	LabelData = labelPrefix + "data"
 
+	// LabelTablespace is used to identify the tablespace of a Volume.
+	LabelTablespace = labelPrefix + "tablespace"
+
 	// LabelMoveJob is used to identify a directory move Job.
 	LabelMoveJob = labelPrefix + "move-job"
 
@@ -74,9 +81,17 @@ const (
 
 	LabelPGBackRestCronJob = labelPrefix + "pgbackrest-cronjob"
 
+	// LabelMaintenanceJob is used to indicate that a CronJob, Job, or Pod is
+	// for the scheduled maintenance job named by its value.
+	LabelMaintenanceJob = labelPrefix + "maintenance-job"
+
 	// LabelPGBackRestRestore is used to indicate that a Job or Pod is for a pgBackRest restore
 	LabelPGBackRestRestore = labelPrefix + "pgbackrest-restore"
 
+	// LabelPGBackRestRestoreDrill is used to indicate that a CronJob or Job is
+	// for a scheduled restore drill
+	LabelPGBackRestRestoreDrill = labelPrefix + "pgbackrest-restore-drill"
+
 	// LabelPGBackRestRestoreConfig is used to indicate that a configuration
 	// resource (e.g. a ConfigMap or Secret) is for a pgBackRest restore
 	LabelPGBackRestRestoreConfig = labelPrefix + "pgbackrest-restore-config"
@@ -105,6 +120,9 @@ const (
 	// RolePGBouncer is the LabelRole applied to PgBouncer objects.
 	RolePGBouncer = "pgbouncer"
 
+	// RoleHAProxy is the LabelRole applied to HAProxy objects.
+	RoleHAProxy = "haproxy"
+
 	// RolePGAdmin is the LabelRole applied to pgAdmin objects.
 	RolePGAdmin = "pgadmin"
 
@@ -117,8 +135,23 @@ const (
 	// RolePostgresWAL is the LabelRole applied to PostgreSQL WAL volumes.
 	RolePostgresWAL = "pgwal"
 
+	// RolePostgresTablespace is the LabelRole applied to PostgreSQL tablespace volumes.
+	RolePostgresTablespace = "pgtablespace"
+
+	// RoleMigration is the LabelRole applied to schema migration resources.
+	RoleMigration = "migration"
+
+	// RolePGUpgrade is the LabelRole applied to PostgreSQL major upgrade resources.
+	RolePGUpgrade = "pgupgrade"
+
 	// RoleMonitoring is the LabelRole applied to Monitoring resources
 	RoleMonitoring = "monitoring"
+
+	// RoleLogicalBackup is the LabelRole applied to logical backup resources.
+	RoleLogicalBackup = "logical-backup"
+
+	// RoleMaintenance is the LabelRole applied to scheduled maintenance resources.
+	RoleMaintenance = "maintenance"
 )
 
 const (
@@ -224,6 +257,16 @@ func PGBackRestRestoreJobSelector(clusterName string) labels.Selector {
 	return PGBackRestRestoreJobLabels(clusterName).AsSelector()
 }
 
+// PGBackRestRestoreDrillLabels provides labels for the pgBackRest restore drill
+// CronJob and the Jobs it creates.
+func PGBackRestRestoreDrillLabels(clusterName string) labels.Set {
+	commonLabels := PGBackRestLabels(clusterName)
+	drillLabels := map[string]string{
+		LabelPGBackRestRestoreDrill: "",
+	}
+	return labels.Merge(drillLabels, commonLabels)
+}
+
 // PGBackRestRepoLabels provides common labels for pgBackRest repository
 // resources.
 func PGBackRestRepoLabels(clusterName, repoName string) labels.Set {
@@ -42,6 +42,7 @@ func TestLabelsValid(t *testing.T) {
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPGBackRestRepoVolume))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPGBackRestRestore))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPGBackRestRestoreConfig))
+	assert.Assert(t, nil == validation.IsQualifiedName(LabelPGBackRestRestoreDrill))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPGMonitorDiscovery))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPostgresUser))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelStartupInstance))
@@ -55,6 +56,7 @@ func TestLabelValuesValid(t *testing.T) {
 	assert.Assert(t, nil == validation.IsValidLabelValue(RolePatroniReplica))
 	assert.Assert(t, nil == validation.IsValidLabelValue(RolePGAdmin))
 	assert.Assert(t, nil == validation.IsValidLabelValue(RolePGBouncer))
+	assert.Assert(t, nil == validation.IsValidLabelValue(RoleHAProxy))
 	assert.Assert(t, nil == validation.IsValidLabelValue(RolePostgresData))
 	assert.Assert(t, nil == validation.IsValidLabelValue(RolePostgresUser))
 	assert.Assert(t, nil == validation.IsValidLabelValue(RolePostgresWAL))
@@ -214,6 +216,12 @@ func TestPGBackRestLabelFuncs(t *testing.T) {
 	assert.Check(t, pgBackRestRestoreJobLabels.Has(LabelPGBackRest))
 	assert.Check(t, pgBackRestRestoreJobLabels.Has(LabelPGBackRestRestore))
 
+	// verify the labels that identify pgBackRest restore drill resources
+	pgBackRestRestoreDrillLabels := PGBackRestRestoreDrillLabels(clusterName)
+	assert.Equal(t, pgBackRestRestoreDrillLabels.Get(LabelCluster), clusterName)
+	assert.Check(t, pgBackRestRestoreDrillLabels.Has(LabelPGBackRest))
+	assert.Check(t, pgBackRestRestoreDrillLabels.Has(LabelPGBackRestRestoreDrill))
+
 	// verify the labels that identify pgBackRest restore configuration resources
 	pgBackRestRestoreConfigLabels := PGBackRestRestoreConfigLabels(clusterName)
 	assert.Equal(t, pgBackRestRestoreConfigLabels.Get(LabelCluster), clusterName)
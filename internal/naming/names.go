@@ -33,6 +33,20 @@ const (
 	// supporting tools: Patroni, pgBackRest, etc.
 	ContainerDatabase = "database"
 
+	// ContainerLogicalBackup is the name of a container exporting databases
+	// with pg_dump.
+	ContainerLogicalBackup = "pgdump"
+	// ContainerLogicalBackupUpload is the name of a container sending those
+	// exports elsewhere.
+	ContainerLogicalBackupUpload = "pgdump-upload"
+
+	// ContainerHAProxy is the name of a container running HAProxy.
+	ContainerHAProxy = "haproxy"
+
+	// ContainerMaintenance is the name of a container running scheduled
+	// maintenance against PostgreSQL.
+	ContainerMaintenance = "maintenance"
+
 	// ContainerPGAdmin is the name of a container running pgAdmin.
 	ContainerPGAdmin = "pgadmin"
 
@@ -40,6 +54,9 @@ const (
 	// that prepares the filesystem for pgAdmin.
 	ContainerPGAdminStartup = "pgadmin-startup"
 
+	// ContainerPGUpgrade is the name of a container running pg_upgrade.
+	ContainerPGUpgrade = "pgupgrade"
+
 	// ContainerPGBackRestConfig is the name of a container supporting pgBackRest.
 	ContainerPGBackRestConfig = "pgbackrest-config"
 
@@ -67,6 +84,9 @@ const (
 	// ContainerPGMonitorExporter is the name of a container running postgres_exporter
 	ContainerPGMonitorExporter = "exporter"
 
+	// ContainerLogShipper is the name of a container that forwards log files
+	ContainerLogShipper = "log-shipper"
+
 	// ContainerJobMovePGDataDir is the name of the job container utilized to copy v4 Operator
 	// pgData directories to the v5 default location
 	ContainerJobMovePGDataDir = "pgdata-move-job"
@@ -81,8 +101,16 @@ const (
 const (
 	// PortExporter is the named port for the "exporter" container
 	PortExporter = "exporter"
+	// PortHAProxyPrimary is the name of a port on HAProxy that connects to
+	// the PostgreSQL primary.
+	PortHAProxyPrimary = "haproxy-primary"
+	// PortHAProxyReplica is the name of a port on HAProxy that connects to
+	// PostgreSQL replicas.
+	PortHAProxyReplica = "haproxy-replica"
 	// PortPGAdmin is the name of a port that connects to pgAdmin.
 	PortPGAdmin = "pgadmin"
+	// PortPatroni is the name of a port that connects to the Patroni REST API.
+	PortPatroni = "patroni"
 	// PortPGBouncer is the name of a port that connects to PgBouncer.
 	PortPGBouncer = "pgbouncer"
 	// PortPostgreSQL is the name of a port that connects to PostgreSQL.
@@ -139,6 +167,29 @@ const (
 	// ReplicationCACertPath is the path to the postgrescluster's replication/rewind
 	// user's client CA certificate
 	ReplicationCACertPath = "replication/ca.crt"
+
+	// CitusDirectory is the directory at CertMountPath where the certificate
+	// and key that Citus nodes use to connect to one another are mounted
+	CitusDirectory = "/citus"
+
+	// CitusTmp is the directory where the Citus certificate and key can have
+	// the proper permissions set due to:
+	// https://github.com/kubernetes/kubernetes/issues/57923
+	CitusTmp = "/tmp/citus"
+
+	// CitusCert is the secret key to the client certificate of the
+	// superuser that Citus nodes use to connect to one another
+	CitusCert = "tls.crt"
+
+	// CitusCertPath is the path to the Citus client certificate
+	CitusCertPath = "citus/tls.crt"
+
+	// CitusPrivateKey is the secret key to the client private key of the
+	// superuser that Citus nodes use to connect to one another
+	CitusPrivateKey = "tls.key"
+
+	// CitusPrivateKeyPath is the path to the Citus client private key
+	CitusPrivateKeyPath = "citus/tls.key"
 )
 
 const (
@@ -156,6 +207,18 @@ const (
 	// PostgreSQL instance.
 	PGBackRestPGDataLogPath = "/pgdata/pgbackrest/log"
 
+	// PGBackRestPGDataSpoolPath is the pgBackRest spool path used by the
+	// PostgreSQL instance when archiving asynchronously.
+	PGBackRestPGDataSpoolPath = "/pgdata/pgbackrest/spool"
+
+	// PatroniPGDataLogPath is the Patroni log path used by the PostgreSQL
+	// instance when Patroni is configured to log to files.
+	PatroniPGDataLogPath = "/pgdata/patroni/log"
+
+	// PostgresPGDataLogPath is the PostgreSQL log path used by the PostgreSQL
+	// instance when its logs are shipped.
+	PostgresPGDataLogPath = "/pgdata/postgres/log"
+
 	// PGBackRestRepoLogPath is the pgBackRest default log path configuration used by the
 	// dedicated repo host, if configured.
 	PGBackRestRepoLogPath = "/pgbackrest/%s/log"
@@ -210,6 +273,33 @@ func ClusterInstanceRBAC(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	}
 }
 
+// ClusterMaintenance returns the ObjectMeta necessary to lookup the Secret
+// used by cluster's scheduled maintenance jobs.
+func ClusterMaintenance(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-maintenance",
+	}
+}
+
+// ClusterLogicalBackup returns the ObjectMeta necessary to lookup the CronJob
+// and PersistentVolumeClaim of cluster's scheduled logical backups.
+func ClusterLogicalBackup(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-logical-backup",
+	}
+}
+
+// MaintenanceCronJob returns the ObjectMeta for the CronJob of the scheduled
+// maintenance job named jobName.
+func MaintenanceCronJob(cluster *v1beta1.PostgresCluster, jobName string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-maintenance-" + jobName,
+	}
+}
+
 // ClusterPGAdmin returns the ObjectMeta necessary to lookup the ConfigMap,
 // Service, StatefulSet, or Volume for the cluster's pgAdmin user interface.
 func ClusterPGAdmin(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
@@ -229,6 +319,24 @@ func ClusterPGBouncer(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	}
 }
 
+// ClusterHAProxy returns the ObjectMeta necessary to lookup the ConfigMap,
+// Deployment, and Service that is cluster's HAProxy.
+func ClusterHAProxy(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-haproxy",
+	}
+}
+
+// ClusterHAProxyBackends returns the ObjectMeta necessary to lookup the
+// Service that HAProxy uses to find PostgreSQL instances.
+func ClusterHAProxyBackends(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-haproxy-backends",
+	}
+}
+
 // ClusterPodService returns the ObjectMeta necessary to lookup the Service
 // that is responsible for the network identity of Pods.
 func ClusterPodService(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
@@ -334,6 +442,15 @@ func InstancePostgresWALVolume(instance *appsv1.StatefulSet) metav1.ObjectMeta {
 	}
 }
 
+// InstanceTablespaceVolume returns the ObjectMeta for the volume of tablespace
+// on instance.
+func InstanceTablespaceVolume(instance *appsv1.StatefulSet, tablespace string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: instance.GetNamespace(),
+		Name:      instance.GetName() + "-" + tablespace + "-tablespace",
+	}
+}
+
 // MonitoringUserSecret returns ObjectMeta necessary to lookup the Secret
 // containing authentication credentials for monitoring tools.
 func MonitoringUserSecret(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
@@ -362,6 +479,15 @@ func OperatorConfigurationSecret() metav1.ObjectMeta {
 	}
 }
 
+// CitusClientCertSecret returns ObjectMeta necessary to lookup the Secret
+// containing the certificate that Citus nodes use to connect to one another.
+func CitusClientCertSecret(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-citus-cert",
+	}
+}
+
 // ReplicationClientCertSecret returns ObjectMeta necessary to lookup the Secret
 // containing the Patroni client authentication certificate information.
 func ReplicationClientCertSecret(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
@@ -377,7 +503,7 @@ func ReplicationClientCertSecret(cluster *v1beta1.PostgresCluster) metav1.Object
 func PatroniDistributedConfiguration(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	return metav1.ObjectMeta{
 		Namespace: cluster.Namespace,
-		Name:      PatroniScope(cluster) + "-config",
+		Name:      patroniLeaderPath(cluster) + "-config",
 	}
 }
 
@@ -387,7 +513,7 @@ func PatroniDistributedConfiguration(cluster *v1beta1.PostgresCluster) metav1.Ob
 func PatroniLeaderConfigMap(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	return metav1.ObjectMeta{
 		Namespace: cluster.Namespace,
-		Name:      PatroniScope(cluster) + "-leader",
+		Name:      patroniLeaderPath(cluster) + "-leader",
 	}
 }
 
@@ -397,10 +523,39 @@ func PatroniLeaderConfigMap(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta
 func PatroniLeaderEndpoints(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	return metav1.ObjectMeta{
 		Namespace: cluster.Namespace,
-		Name:      PatroniScope(cluster),
+		Name:      patroniLeaderPath(cluster),
 	}
 }
 
+// PatroniCitusGroupConfiguration returns the ObjectMeta necessary to lookup
+// the DCS created by Patroni for a single Citus group of cluster.
+func PatroniCitusGroupConfiguration(cluster *v1beta1.PostgresCluster, group int32) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      fmt.Sprintf("%s-%d-config", PatroniScope(cluster), group),
+	}
+}
+
+// PatroniCitusGroupLeaderEndpoints returns the ObjectMeta necessary to lookup
+// the Endpoints created by Patroni for the leader election of a single Citus
+// group of cluster.
+func PatroniCitusGroupLeaderEndpoints(cluster *v1beta1.PostgresCluster, group int32) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      fmt.Sprintf("%s-%d", PatroniScope(cluster), group),
+	}
+}
+
+// patroniLeaderPath returns the prefix of objects Patroni creates for the
+// leader of cluster. Patroni in Citus mode appends the group to the scope;
+// the coordinator, group zero, leads the cluster.
+func patroniLeaderPath(cluster *v1beta1.PostgresCluster) string {
+	if cluster.Spec.Citus != nil {
+		return PatroniScope(cluster) + "-0"
+	}
+	return PatroniScope(cluster)
+}
+
 // PatroniScope returns the "scope" Patroni uses for cluster.
 func PatroniScope(cluster *v1beta1.PostgresCluster) string {
 	return cluster.Name + "-ha"
@@ -412,7 +567,34 @@ func PatroniScope(cluster *v1beta1.PostgresCluster) string {
 func PatroniTrigger(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	return metav1.ObjectMeta{
 		Namespace: cluster.Namespace,
-		Name:      PatroniScope(cluster) + "-failover",
+		Name:      patroniLeaderPath(cluster) + "-failover",
+	}
+}
+
+// PatroniAPIService returns the ObjectMeta necessary to lookup the Service
+// and NetworkPolicy for the Patroni REST API.
+func PatroniAPIService(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-patroni-api",
+	}
+}
+
+// PatroniAPIClientSecret returns the ObjectMeta necessary to lookup the Secret
+// containing a client certificate for the Patroni REST API.
+func PatroniAPIClientSecret(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-patroni-api-client",
+	}
+}
+
+// PatroniAPIAuthSecret returns the ObjectMeta necessary to lookup the Secret
+// containing generated credentials of the Patroni REST API.
+func PatroniAPIAuthSecret(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-patroni-api-auth",
 	}
 }
 
@@ -449,6 +631,15 @@ func PGBackRestRestoreJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	}
 }
 
+// PGBackRestRestoreDrillCronJob returns the ObjectMeta for the CronJob that
+// restores the latest backup of cluster on a schedule.
+func PGBackRestRestoreDrillCronJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      cluster.Name + "-pgbackrest-drill",
+	}
+}
+
 // PGBackRestRBAC returns the ObjectMeta necessary to lookup the ServiceAccount, Role, and
 // RoleBinding for pgBackRest Jobs
 func PGBackRestRBAC(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
@@ -547,6 +738,42 @@ func MovePGBackRestRepoDirJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMet
 	}
 }
 
+// ClusterPrometheusRule returns the ObjectMeta for the PrometheusRule of
+// alerts about cluster.
+func ClusterPrometheusRule(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      cluster.Name + "-alerts",
+	}
+}
+
+// LogShippingConfigMap returns the ObjectMeta for the ConfigMap of log
+// shipper configuration managed by PGO.
+func LogShippingConfigMap(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      cluster.Name + "-log-shipping",
+	}
+}
+
+// MigrationJob returns the ObjectMeta for the Job that migrates the schema of
+// databases in cluster.
+func MigrationJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      cluster.Name + "-migration",
+	}
+}
+
+// PGUpgradeJob returns the ObjectMeta for the Job that upgrades the data of
+// cluster to its PostgreSQL major version.
+func PGUpgradeJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      fmt.Sprintf("%s-pgupgrade-pg%d", cluster.Name, cluster.Spec.PostgresVersion),
+	}
+}
+
 // UpgradeCheckConfigMap returns the ObjectMeta for the PGO ConfigMap
 func UpgradeCheckConfigMap() metav1.ObjectMeta {
 	return metav1.ObjectMeta{
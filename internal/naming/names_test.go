@@ -44,6 +44,8 @@ func TestContainerNamesUniqueAndValid(t *testing.T) {
 	names := sets.NewString()
 	for _, name := range []string{
 		ContainerDatabase,
+		ContainerHAProxy,
+		ContainerMaintenance,
 		ContainerNSSWrapperInit,
 		ContainerPGAdmin,
 		ContainerPGAdminStartup,
@@ -53,6 +55,7 @@ func TestContainerNamesUniqueAndValid(t *testing.T) {
 		ContainerPGBouncerConfig,
 		ContainerPostgresStartup,
 		ContainerPGMonitorExporter,
+		ContainerLogShipper,
 	} {
 		assert.Assert(t, !names.Has(name), "%q defined already", name)
 		assert.Assert(t, nil == validation.IsDNS1123Label(name))
@@ -93,8 +96,10 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 	t.Run("ConfigMaps", func(t *testing.T) {
 		testUniqueAndValid(t, []test{
 			{"ClusterConfigMap", ClusterConfigMap(cluster)},
+			{"ClusterHAProxy", ClusterHAProxy(cluster)},
 			{"ClusterPGAdmin", ClusterPGAdmin(cluster)},
 			{"ClusterPGBouncer", ClusterPGBouncer(cluster)},
+			{"LogShippingConfigMap", LogShippingConfigMap(cluster)},
 			{"PatroniDistributedConfiguration", PatroniDistributedConfiguration(cluster)},
 			{"PatroniLeaderConfigMap", PatroniLeaderConfigMap(cluster)},
 			{"PatroniTrigger", PatroniTrigger(cluster)},
@@ -109,11 +114,14 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 			{"PGBackRestCronJon", PGBackRestCronJob(cluster, "incr", "repo2")},
 			{"PGBackRestCronJon", PGBackRestCronJob(cluster, "diff", "repo3")},
 			{"PGBackRestCronJon", PGBackRestCronJob(cluster, "full", "repo4")},
+			{"MaintenanceCronJob", MaintenanceCronJob(cluster, "vacuum")},
+			{"ClusterLogicalBackup", ClusterLogicalBackup(cluster)},
 		})
 	})
 
 	t.Run("Deployments", func(t *testing.T) {
 		testUniqueAndValid(t, []test{
+			{"ClusterHAProxy", ClusterHAProxy(cluster)},
 			{"ClusterPGBouncer", ClusterPGBouncer(cluster)},
 		})
 	})
@@ -122,6 +130,9 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 		testUniqueAndValid(t, []test{
 			{"PGBackRestBackupJob", PGBackRestBackupJob(cluster)},
 			{"PGBackRestRestoreJob", PGBackRestRestoreJob(cluster)},
+			{"PGBackRestRestoreDrillCronJob", PGBackRestRestoreDrillCronJob(cluster)},
+			{"MigrationJob", MigrationJob(cluster)},
+			{"PGUpgradeJob", PGUpgradeJob(cluster)},
 		})
 	})
 
@@ -132,6 +143,12 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 		})
 	})
 
+	t.Run("PrometheusRules", func(t *testing.T) {
+		testUniqueAndValid(t, []test{
+			{"ClusterPrometheusRule", ClusterPrometheusRule(cluster)},
+		})
+	})
+
 	t.Run("RoleBindings", func(t *testing.T) {
 		testUniqueAndValid(t, []test{
 			{"ClusterInstanceRBAC", ClusterInstanceRBAC(cluster)},
@@ -148,8 +165,12 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 
 	t.Run("Secrets", func(t *testing.T) {
 		names := testUniqueAndValid(t, []test{
+			{"CitusClientCertSecret", CitusClientCertSecret(cluster)},
+			{"ClusterMaintenance", ClusterMaintenance(cluster)},
 			{"ClusterPGBouncer", ClusterPGBouncer(cluster)},
 			{"DeprecatedPostgresUserSecret", DeprecatedPostgresUserSecret(cluster)},
+			{"PatroniAPIAuthSecret", PatroniAPIAuthSecret(cluster)},
+			{"PatroniAPIClientSecret", PatroniAPIClientSecret(cluster)},
 			{"PostgresTLSSecret", PostgresTLSSecret(cluster)},
 			{"ReplicationClientCertSecret", ReplicationClientCertSecret(cluster)},
 			{"PGBackRestSSHSecret", PGBackRestSSHSecret(cluster)},
@@ -185,16 +206,41 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 
 	t.Run("Services", func(t *testing.T) {
 		testUniqueAndValid(t, []test{
+			{"ClusterHAProxy", ClusterHAProxy(cluster)},
+			{"ClusterHAProxyBackends", ClusterHAProxyBackends(cluster)},
 			{"ClusterPGBouncer", ClusterPGBouncer(cluster)},
 			{"ClusterPGAdmin", ClusterPGAdmin(cluster)},
 			{"ClusterPodService", ClusterPodService(cluster)},
 			{"ClusterPrimaryService", ClusterPrimaryService(cluster)},
 			{"ClusterReplicaService", ClusterReplicaService(cluster)},
+			{"PatroniAPIService", PatroniAPIService(cluster)},
 			// Patroni can use Endpoints which relate directly to a Service.
 			{"PatroniDistributedConfiguration", PatroniDistributedConfiguration(cluster)},
 			{"PatroniLeaderEndpoints", PatroniLeaderEndpoints(cluster)},
 			{"PatroniTrigger", PatroniTrigger(cluster)},
 		})
+
+		t.Run("Citus", func(t *testing.T) {
+			cluster := cluster.DeepCopy()
+			cluster.Spec.Citus = new(v1beta1.CitusSpec)
+
+			testUniqueAndValid(t, []test{
+				{"ClusterPodService", ClusterPodService(cluster)},
+				{"ClusterPrimaryService", ClusterPrimaryService(cluster)},
+				{"ClusterReplicaService", ClusterReplicaService(cluster)},
+				{"PatroniCitusGroupConfiguration", PatroniCitusGroupConfiguration(cluster, 1)},
+				{"PatroniCitusGroupLeaderEndpoints", PatroniCitusGroupLeaderEndpoints(cluster, 1)},
+				{"PatroniDistributedConfiguration", PatroniDistributedConfiguration(cluster)},
+				{"PatroniLeaderEndpoints", PatroniLeaderEndpoints(cluster)},
+				{"PatroniTrigger", PatroniTrigger(cluster)},
+			})
+
+			// Patroni appends the group to the scope. The coordinator leads.
+			assert.Equal(t, PatroniLeaderEndpoints(cluster).Name,
+				PatroniCitusGroupLeaderEndpoints(cluster, 0).Name)
+			assert.Equal(t, PatroniDistributedConfiguration(cluster).Name,
+				PatroniCitusGroupConfiguration(cluster, 0).Name)
+		})
 	})
 
 	t.Run("StatefulSets", func(t *testing.T) {
@@ -205,6 +251,7 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 
 	t.Run("Volumes", func(t *testing.T) {
 		testUniqueAndValid(t, []test{
+			{"ClusterLogicalBackup", ClusterLogicalBackup(cluster)},
 			{"ClusterPGAdmin", ClusterPGAdmin(cluster)},
 			{"PGBackRestRepoVolume", PGBackRestRepoVolume(cluster, repoName)},
 		})
@@ -321,6 +368,8 @@ func TestPortNamesUniqueAndValid(t *testing.T) {
 	names := sets.NewString()
 	for _, name := range []string{
 		PortExporter,
+		PortHAProxyPrimary,
+		PortHAProxyReplica,
 		PortPGAdmin,
 		PortPGBouncer,
 		PortPostgreSQL,
@@ -105,6 +105,28 @@ func ClusterInstanceSets(cluster string) metav1.LabelSelector {
 	}
 }
 
+// ClusterLogicalBackups selects things for logical backups in cluster.
+func ClusterLogicalBackups(cluster string) metav1.LabelSelector {
+	return metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			LabelCluster: cluster,
+			LabelRole:    RoleLogicalBackup,
+		},
+	}
+}
+
+// ClusterMaintenanceJobs selects things for scheduled maintenance in cluster.
+func ClusterMaintenanceJobs(cluster string) metav1.LabelSelector {
+	return metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			LabelCluster: cluster,
+		},
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: LabelMaintenanceJob, Operator: metav1.LabelSelectorOpExists},
+		},
+	}
+}
+
 // ClusterPatronis selects things labeled for Patroni in cluster.
 func ClusterPatronis(cluster *v1beta1.PostgresCluster) metav1.LabelSelector {
 	return metav1.LabelSelector{
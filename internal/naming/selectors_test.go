@@ -103,6 +103,30 @@ func TestClusterInstanceSets(t *testing.T) {
 	assert.ErrorContains(t, err, "Invalid")
 }
 
+func TestClusterLogicalBackups(t *testing.T) {
+	s, err := AsSelector(ClusterLogicalBackups("something"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, s.String(), strings.Join([]string{
+		"postgres-operator.crunchydata.com/cluster=something",
+		"postgres-operator.crunchydata.com/role=logical-backup",
+	}, ","))
+
+	_, err = AsSelector(ClusterLogicalBackups("--whoa/yikes"))
+	assert.ErrorContains(t, err, "Invalid")
+}
+
+func TestClusterMaintenanceJobs(t *testing.T) {
+	s, err := AsSelector(ClusterMaintenanceJobs("something"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, s.String(), strings.Join([]string{
+		"postgres-operator.crunchydata.com/cluster=something",
+		"postgres-operator.crunchydata.com/maintenance-job",
+	}, ","))
+
+	_, err = AsSelector(ClusterMaintenanceJobs("--whoa/yikes"))
+	assert.ErrorContains(t, err, "Invalid")
+}
+
 func TestClusterPatronis(t *testing.T) {
 	cluster := &v1beta1.PostgresCluster{}
 	cluster.Name = "something"
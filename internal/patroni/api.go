@@ -20,8 +20,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/crunchydata/postgres-operator/internal/logging"
 )
@@ -37,7 +39,9 @@ type API interface {
 	ReplaceConfiguration(ctx context.Context, configuration map[string]interface{}) error
 }
 
-// Executor implements API by calling "patronictl".
+// Executor implements API by calling "patronictl" in the database container.
+// There it presents the client certificate in "ctl.certfile" and any
+// "restapi.authentication" credentials from the environment of the container.
 type Executor func(
 	ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
 ) error
@@ -217,3 +221,126 @@ func (exec Executor) GetTimeline(ctx context.Context) (int64, error) {
 
 	return 0, err
 }
+
+// Member describes one member of a Patroni cluster as reported by "patronictl list".
+type Member struct {
+	Name     string
+	Role     string
+	State    string
+	Timeline *int64
+
+	// LagMegabytes is how far behind the leader this member is, when known.
+	LagMegabytes *int64
+
+	// PendingRestart is true when a parameter change requires a restart of
+	// this member.
+	PendingRestart bool
+}
+
+// ListMembers gets the patronictl status and returns every member of the
+// cluster. Similar to the "GET /cluster" REST endpoint.
+func (exec Executor) ListMembers(ctx context.Context) ([]Member, error) {
+	var stdout, stderr bytes.Buffer
+
+	// The following exits zero when it is able to read the DCS and communicate
+	// with the Patroni HTTP API. It prints the result of calling "GET /cluster"
+	// - https://github.com/zalando/patroni/blob/v2.1.1/patroni/ctl.py#L849
+	err := exec(ctx, nil, &stdout, &stderr,
+		"patronictl", "list", "--format", "json")
+	if err != nil {
+		return nil, err
+	}
+
+	if stderr.String() != "" {
+		return nil, errors.New(stderr.String())
+	}
+
+	// Columns that do not apply to a member are empty strings rather than
+	// numbers, and "Pending restart" is "*" only when a restart is pending.
+	var listed []struct {
+		Member  string
+		Role    string
+		State   string
+		TL      interface{}
+		Lag     interface{} `json:"Lag in MB"`
+		Pending string      `json:"Pending restart"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &listed); err != nil {
+		return nil, err
+	}
+
+	number := func(v interface{}) *int64 {
+		if f, ok := v.(float64); ok {
+			i := int64(f)
+			return &i
+		}
+		return nil
+	}
+
+	members := make([]Member, 0, len(listed))
+	for _, m := range listed {
+		members = append(members, Member{
+			Name:           m.Member,
+			Role:           m.Role,
+			State:          m.State,
+			Timeline:       number(m.TL),
+			LagMegabytes:   number(m.Lag),
+			PendingRestart: m.Pending != "",
+		})
+	}
+	return members, nil
+}
+
+// HistoryEntry describes one change of timeline, such as a failover or
+// switchover, in the history that Patroni keeps in its DCS.
+type HistoryEntry struct {
+	// Timeline is the timeline that ended.
+	Timeline int64
+
+	// Reason is what PostgreSQL wrote to the history file of the new timeline.
+	Reason string
+
+	// Time is when the timeline ended, when known.
+	Time *time.Time
+
+	// Leader is the member that became primary, when known.
+	Leader string
+}
+
+// ParseHistory returns the entries of the "history" key in the DCS, oldest
+// first. Each entry is a JSON array of timeline, LSN, reason, and, in newer
+// versions of Patroni, a timestamp and the name of the new leader.
+// - https://github.com/zalando/patroni/blob/v2.1.1/patroni/ha.py#L411
+func ParseHistory(value string) ([]HistoryEntry, error) {
+	var lines [][]interface{}
+	if err := json.Unmarshal([]byte(value), &lines); err != nil {
+		return nil, err
+	}
+
+	entries := make([]HistoryEntry, 0, len(lines))
+	for _, line := range lines {
+		var entry HistoryEntry
+
+		if len(line) < 3 {
+			return nil, fmt.Errorf("unexpected history entry: %v", line)
+		}
+		if f, ok := line[0].(float64); ok {
+			entry.Timeline = int64(f)
+		}
+		entry.Reason, _ = line[2].(string)
+
+		if len(line) > 3 {
+			if s, ok := line[3].(string); ok {
+				if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+					entry.Time = &t
+				}
+			}
+		}
+		if len(line) > 4 {
+			entry.Leader, _ = line[4].(string)
+		}
+
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -23,6 +23,7 @@ import (
 	"os/exec"
 	"strings"
 	"testing"
+	"time"
 
 	"gotest.tools/v3/assert"
 )
@@ -298,3 +299,80 @@ func TestExecutorGetTimeline(t *testing.T) {
 		assert.Equal(t, tl, int64(4))
 	})
 }
+
+func TestExecutorListMembers(t *testing.T) {
+	t.Run("Arguments", func(t *testing.T) {
+		called := false
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			called = true
+			assert.DeepEqual(t, command, strings.Fields(`patronictl list --format json`))
+			assert.Assert(t, stdin == nil, "expected no stdin, got %T", stdin)
+			return nil
+		}
+
+		_, _ = Executor(exec).ListMembers(context.Background())
+		assert.Assert(t, called)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		expected := errors.New("bang")
+		_, actual := Executor(func(
+			context.Context, io.Reader, io.Writer, io.Writer, ...string,
+		) error {
+			return expected
+		}).ListMembers(context.Background())
+
+		assert.Equal(t, expected, actual)
+	})
+
+	t.Run("Members", func(t *testing.T) {
+		members, err := Executor(func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			_, err := stdout.Write([]byte(`[` +
+				`{"Cluster":"hippo-ha","Member":"hippo-a-0","Role":"Leader","State":"running","TL":4,"Pending restart":"*"},` +
+				`{"Cluster":"hippo-ha","Member":"hippo-b-0","Role":"Replica","State":"running","TL":4,"Lag in MB":0},` +
+				`{"Cluster":"hippo-ha","Member":"hippo-c-0","Role":"Replica","State":"stopped","TL":"","Lag in MB":"unknown"}` +
+				`]`))
+			return err
+		}).ListMembers(context.Background())
+
+		four, zero := int64(4), int64(0)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, members, []Member{
+			{Name: "hippo-a-0", Role: "Leader", State: "running", Timeline: &four, PendingRestart: true},
+			{Name: "hippo-b-0", Role: "Replica", State: "running", Timeline: &four, LagMegabytes: &zero},
+			{Name: "hippo-c-0", Role: "Replica", State: "stopped"},
+		})
+	})
+}
+
+func TestParseHistory(t *testing.T) {
+	_, err := ParseHistory(`{}`)
+	assert.ErrorContains(t, err, "cannot unmarshal")
+
+	_, err = ParseHistory(`[[1, 25]]`)
+	assert.ErrorContains(t, err, "unexpected")
+
+	entries, err := ParseHistory(`[` +
+		`[1, 25623960, "no recovery target specified"],` +
+		`[2, 50331808, "no recovery target specified", "2022-03-15T10:30:00.123456+00:00"],` +
+		`[3, 83886240, "no recovery target specified", "2022-03-16T04:00:00+00:00", "hippo-b-0"]` +
+		`]`)
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 3)
+
+	assert.Equal(t, entries[0].Timeline, int64(1))
+	assert.Equal(t, entries[0].Reason, "no recovery target specified")
+	assert.Assert(t, entries[0].Time == nil)
+	assert.Equal(t, entries[0].Leader, "")
+
+	assert.Equal(t, entries[1].Timeline, int64(2))
+	assert.Assert(t, entries[1].Time != nil)
+	assert.Equal(t, entries[1].Time.UTC().Format(time.RFC3339Nano), "2022-03-15T10:30:00.123456Z")
+
+	assert.Equal(t, entries[2].Timeline, int64(3))
+	assert.Equal(t, entries[2].Leader, "hippo-b-0")
+}
@@ -18,11 +18,14 @@ package patroni
 import (
 	"fmt"
 	"path"
+	"sort"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/yaml"
 
+	"github.com/crunchydata/postgres-operator/internal/archive"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
@@ -33,6 +36,12 @@ const (
 	configMapFileKey = "patroni.yaml"
 )
 
+const (
+	// callbacksConfigPath is the directory, relative to configDirectory, of
+	// the scripts Patroni runs for "postgresql.callbacks".
+	callbacksConfigPath = "~postgres-operator/callbacks"
+)
+
 const (
 	basebackupCreateReplicaMethod = "basebackup"
 	pgBackRestCreateReplicaMethod = "pgbackrest"
@@ -60,8 +69,8 @@ func clusterYAML(
 		// lifetime.
 		"scope": naming.PatroniScope(cluster),
 
-		// Use Kubernetes Endpoints for the distributed configuration store (DCS).
-		// These values cannot change during the cluster's lifetime.
+		// Use Kubernetes Endpoints or ConfigMaps for the distributed configuration
+		// store (DCS). These values cannot change during the cluster's lifetime.
 		//
 		// NOTE(cbandy): It *might* be possible to *carefully* change the role and
 		// scope labels, but there is no way to reconfigure all instances at once.
@@ -69,7 +78,7 @@ func clusterYAML(
 			"namespace":     cluster.Namespace,
 			"role_label":    naming.LabelRole,
 			"scope_label":   naming.LabelPatroni,
-			"use_endpoints": true,
+			"use_endpoints": !UseConfigMaps(cluster),
 
 			// In addition to "scope_label" above, Patroni will add the following to
 			// every object it creates. It will also use these as filters when doing
@@ -80,14 +89,11 @@ func clusterYAML(
 		},
 
 		"postgresql": map[string]interface{}{
-			// TODO(cbandy): "callbacks"
+			// Missing here is "callbacks" which is set below, when specified.
 
-			// Custom configuration "must exist on all cluster nodes".
-			//
-			// TODO(cbandy): I imagine we will always set this to a file we own. At
-			// the very least, it will start with an "include_dir" directive.
+			// Custom configuration "must exist on all cluster nodes". It is set
+			// below when there are additional config files.
 			// - https://www.postgresql.org/docs/current/config-setting.html#CONFIG-INCLUDES
-			//"custom_conf": nil,
 
 			// TODO(cbandy): Should "parameters", "pg_hba", and "pg_ident" be set in
 			// DCS? If so, are they are automatically regenerated and reloaded?
@@ -136,9 +142,7 @@ func clusterYAML(
 			// - https://issue.k8s.io/92647
 			"verify_client": "optional",
 
-			// TODO(cbandy): The next release of Patroni will allow more control over
-			// the TLS protocols/ciphers.
-			// Maybe "ciphers": "EECDH+AESGCM+FIPS:EDH+AESGCM+FIPS". Maybe add ":!DHE".
+			// Missing here is "ciphers" which is set below, when specified.
 			// - https://github.com/zalando/patroni/commit/ba4ab58d4069ee30
 		},
 
@@ -162,6 +166,57 @@ func clusterYAML(
 		},
 	}
 
+	// Write logs to files in the data volume rather than stderr. Patroni
+	// rotates "file_num" old files in addition to the current one.
+	if cluster.Spec.Patroni != nil && cluster.Spec.Patroni.Logging != nil {
+		logging := cluster.Spec.Patroni.Logging
+		log := map[string]interface{}{
+			"dir":      naming.PatroniPGDataLogPath,
+			"file_num": 1,
+		}
+		if logging.StorageLimit != nil {
+			// Round up so that the smallest limit is still at least one byte.
+			log["file_size"] = (logging.StorageLimit.Value() + 1) / 2
+		}
+		if logging.Level != nil {
+			log["level"] = *logging.Level
+		}
+		if logging.Format != nil {
+			log["type"] = *logging.Format
+		}
+		root["log"] = log
+	}
+
+	if cluster.Spec.Patroni != nil && cluster.Spec.Patroni.APICiphers != "" {
+		root["restapi"].(map[string]interface{})["ciphers"] = cluster.Spec.Patroni.APICiphers
+	}
+
+	// These are not part of the dynamic configuration, so they are set here.
+	if cluster.Spec.Patroni != nil && cluster.Spec.Patroni.Rewind != nil {
+		rewind := cluster.Spec.Patroni.Rewind
+		postgresql := root["postgresql"].(map[string]interface{})
+		if rewind.RemoveDataDirectoryOnFailure != nil {
+			postgresql["remove_data_directory_on_rewind_failure"] =
+				*rewind.RemoveDataDirectoryOnFailure
+		}
+		if rewind.RemoveDataDirectoryOnDivergedTimelines != nil {
+			postgresql["remove_data_directory_on_diverged_timelines"] =
+				*rewind.RemoveDataDirectoryOnDivergedTimelines
+		}
+	}
+
+	// PostgreSQL reads the custom configuration file before parameters set
+	// by Patroni. See [postgres.CustomConfigFile].
+	if file := postgres.CustomConfigFile(cluster); file != "" {
+		root["postgresql"].(map[string]interface{})["custom_conf"] = file
+	}
+
+	// Patroni runs callbacks from the paths at which they are projected into
+	// the instance configuration volume. See [instanceCallbacks].
+	if callbacks := instanceCallbacksPaths(cluster); len(callbacks) > 0 {
+		root["postgresql"].(map[string]interface{})["callbacks"] = callbacks
+	}
+
 	if !ClusterBootstrapped(cluster) {
 		// Patroni has not yet bootstrapped. Populate the "bootstrap.dcs" field to
 		// facilitate it. When Patroni is already bootstrapped, this field is ignored.
@@ -200,6 +255,36 @@ func DynamicConfiguration(
 	root["ttl"] = *cluster.Spec.Patroni.LeaderLeaseDurationSeconds
 	root["loop_wait"] = *cluster.Spec.Patroni.SyncPeriodSeconds
 
+	// Failover settings in the spec override any of the same name in the input.
+	if v := cluster.Spec.Patroni.MaximumLagOnFailover; v != nil {
+		root["maximum_lag_on_failover"] = v.Value()
+	}
+	// Patroni 2.1 calls this "master_start_timeout".
+	// - https://patroni.readthedocs.io/en/v2.1.1/SETTINGS.html#dynamic-configuration-settings
+	if v := cluster.Spec.Patroni.PrimaryStartTimeoutSeconds; v != nil {
+		root["master_start_timeout"] = *v
+	}
+	if v := cluster.Spec.Patroni.RetryTimeoutSeconds; v != nil {
+		root["retry_timeout"] = *v
+	}
+
+	// Synchronous replication settings in the spec override any of the same
+	// name in the input.
+	if v := cluster.Spec.Patroni.SynchronousMode; v != nil {
+		root["synchronous_mode"] = *v
+	}
+	if v := cluster.Spec.Patroni.SynchronousModeStrict; v != nil {
+		root["synchronous_mode_strict"] = *v
+	}
+	if v := cluster.Spec.Patroni.SynchronousNodeCount; v != nil {
+		root["synchronous_node_count"] = *v
+	}
+
+	// Maintenance mode in the spec overrides any in the input.
+	if v := cluster.Spec.Patroni.Paused; v != nil {
+		root["pause"] = *v
+	}
+
 	// Copy the "postgresql" section before making any changes.
 	postgresql := map[string]interface{}{
 		// TODO(cbandy): explain this. requires an archive, perhaps.
@@ -212,6 +297,55 @@ func DynamicConfiguration(
 	}
 	root["postgresql"] = postgresql
 
+	// Copy the "slots" section and add permanent slots from the spec. Patroni
+	// manages no slots at all unless "postgresql.use_slots" is enabled.
+	var decodingSlots []v1beta1.LogicalDecodingSlotSpec
+	if cluster.Spec.Replication != nil && cluster.Spec.Replication.LogicalDecoding != nil {
+		decodingSlots = cluster.Spec.Replication.LogicalDecoding.Slots
+	}
+	if len(cluster.Spec.ReplicationSlots)+len(decodingSlots) > 0 {
+		slots := make(map[string]interface{})
+		if section, ok := root["slots"].(map[string]interface{}); ok {
+			for k, v := range section {
+				slots[k] = v
+			}
+		}
+		for _, slot := range cluster.Spec.ReplicationSlots {
+			if slot.Type == "physical" {
+				slots[slot.Name] = map[string]interface{}{"type": "physical"}
+			} else {
+				slots[slot.Name] = map[string]interface{}{
+					"type":     "logical",
+					"database": slot.Database,
+					"plugin":   slot.Plugin,
+				}
+			}
+		}
+		for _, slot := range decodingSlots {
+			plugin := slot.Plugin
+			if plugin == "" {
+				plugin = "pgoutput"
+			}
+			slots[slot.Name] = map[string]interface{}{
+				"type":     "logical",
+				"database": string(slot.Database),
+				"plugin":   plugin,
+			}
+		}
+		root["slots"] = slots
+		postgresql["use_slots"] = true
+
+		// Patroni drops any slot it does not expect once "use_slots" is enabled.
+		// Leave logical slots that are not declared above, such as those of
+		// subscriptions and other clients, to whoever created them.
+		// - https://patroni.readthedocs.io/en/latest/SETTINGS.html#dynamic-configuration-settings
+		ignore := []interface{}{}
+		if section, ok := root["ignore_slots"].([]interface{}); ok {
+			ignore = append(ignore, section...)
+		}
+		root["ignore_slots"] = append(ignore, map[string]interface{}{"type": "logical"})
+	}
+
 	// Copy the "postgresql.parameters" section over any defaults.
 	parameters := make(map[string]interface{})
 	if pgParameters.Default != nil {
@@ -224,6 +358,10 @@ func DynamicConfiguration(
 			parameters[k] = v
 		}
 	}
+	// Then any parameters from the spec.
+	for k, v := range cluster.Spec.Config.Parameters {
+		parameters[k] = v.String()
+	}
 	// Override the above with mandatory parameters.
 	if pgParameters.Mandatory != nil {
 		for k, v := range pgParameters.Mandatory.AsMap() {
@@ -247,6 +385,9 @@ func DynamicConfiguration(
 	for i := range pgHBAs.Mandatory {
 		hba = append(hba, pgHBAs.Mandatory[i].String())
 	}
+	// Then any rules from the spec. Invalid rules are reported elsewhere.
+	specified, _ := postgres.SpecifiedHBAs(cluster)
+	hba = append(hba, specified...)
 	if section, ok := postgresql["pg_hba"].([]interface{}); ok {
 		for i := range section {
 			// any pg_hba values that are not strings will be skipped
@@ -256,13 +397,25 @@ func DynamicConfiguration(
 		}
 	}
 	// When the section is missing or empty, include the recommended defaults.
-	if len(hba) == len(pgHBAs.Mandatory) {
+	if len(hba) == len(pgHBAs.Mandatory)+len(specified) {
 		for i := range pgHBAs.Default {
 			hba = append(hba, pgHBAs.Default[i].String())
 		}
 	}
 	postgresql["pg_hba"] = hba
 
+	// Copy the "postgresql.pg_ident" section after any mandatory values.
+	if section, ok := postgresql["pg_ident"].([]interface{}); ok || len(pgHBAs.Idents) > 0 {
+		ident := append([]string{}, pgHBAs.Idents...)
+		for i := range section {
+			// any pg_ident values that are not strings will be skipped
+			if value, ok := section[i].(string); ok {
+				ident = append(ident, value)
+			}
+		}
+		postgresql["pg_ident"] = ident
+	}
+
 	// Enabling `pg_rewind` allows a former primary to automatically rejoin the
 	// cluster even if it has commits that were not sent to a replica. In other
 	// words, this favors availability over consistency.
@@ -272,6 +425,11 @@ func DynamicConfiguration(
 	// PostgreSQL v10 and earlier require superuser access over the network.
 	postgresql["use_pg_rewind"] = cluster.Spec.PostgresVersion > 10
 
+	// Users can choose consistency instead.
+	if rewind := cluster.Spec.Patroni.Rewind; rewind != nil && rewind.Enabled != nil {
+		postgresql["use_pg_rewind"] = *rewind.Enabled && cluster.Spec.PostgresVersion > 10
+	}
+
 	if cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled {
 		// Copy the "standby_cluster" section before making any changes.
 		standby := make(map[string]interface{})
@@ -312,6 +470,93 @@ func DynamicConfiguration(
 	return root
 }
 
+// DynamicConfigurationConflicts returns an error for every entry of
+// configuration that DynamicConfiguration overrides or that Patroni ignores
+// in its dynamic configuration.
+func DynamicConfigurationConflicts(
+	cluster *v1beta1.PostgresCluster, configuration map[string]interface{},
+	pgParameters postgres.Parameters,
+) field.ErrorList {
+	path := field.NewPath("spec", "patroni", "dynamicConfiguration")
+	var errs field.ErrorList
+
+	// These are local settings that only the operator configures.
+	for _, key := range []string{"kubernetes", "scope"} {
+		if _, ok := configuration[key]; ok {
+			errs = append(errs, field.Forbidden(path.Child(key),
+				"is managed by the operator and ignored here"))
+		}
+	}
+
+	// These are set from typed fields of the spec.
+	overrides := map[string]string{
+		"loop_wait": "syncPeriodSeconds",
+		"ttl":       "leaderLeaseDurationSeconds",
+	}
+	if spec := cluster.Spec.Patroni; spec != nil {
+		if spec.MaximumLagOnFailover != nil {
+			overrides["maximum_lag_on_failover"] = "maximumLagOnFailover"
+		}
+		if spec.PrimaryStartTimeoutSeconds != nil {
+			overrides["master_start_timeout"] = "primaryStartTimeoutSeconds"
+		}
+		if spec.RetryTimeoutSeconds != nil {
+			overrides["retry_timeout"] = "retryTimeoutSeconds"
+		}
+		if spec.Paused != nil {
+			overrides["pause"] = "paused"
+		}
+		if spec.SynchronousMode != nil {
+			overrides["synchronous_mode"] = "synchronousMode"
+		}
+		if spec.SynchronousModeStrict != nil {
+			overrides["synchronous_mode_strict"] = "synchronousModeStrict"
+		}
+		if spec.SynchronousNodeCount != nil {
+			overrides["synchronous_node_count"] = "synchronousNodeCount"
+		}
+	}
+	for key, name := range overrides {
+		if _, ok := configuration[key]; ok {
+			errs = append(errs, field.Forbidden(path.Child(key),
+				"is overridden by spec.patroni."+name))
+		}
+	}
+
+	// Mandatory PostgreSQL parameters override those here, except for
+	// shared_preload_libraries which is appended.
+	if section, ok := configuration["postgresql"].(map[string]interface{}); ok {
+		if _, ok := section["use_pg_rewind"]; ok {
+			errs = append(errs, field.Forbidden(path.Child("postgresql", "use_pg_rewind"),
+				"is overridden by spec.patroni.rewind.enabled"))
+		}
+		if parameters, ok := section["parameters"].(map[string]interface{}); ok &&
+			pgParameters.Mandatory != nil {
+			for key := range parameters {
+				if key != "shared_preload_libraries" && pgParameters.Mandatory.Has(key) {
+					errs = append(errs, field.Forbidden(
+						path.Child("postgresql", "parameters", key),
+						"is managed by the operator and overridden"))
+				}
+			}
+		}
+	}
+	if pgParameters.Mandatory != nil {
+		for key := range cluster.Spec.Config.Parameters {
+			if key != "shared_preload_libraries" && pgParameters.Mandatory.Has(key) {
+				errs = append(errs, field.Forbidden(
+					field.NewPath("spec", "config", "parameters").Key(key),
+					"is managed by the operator and overridden"))
+			}
+		}
+	}
+
+	// Sort for consistent messages.
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+
+	return errs
+}
+
 // instanceEnvironment returns the environment variables needed by Patroni's
 // instance container.
 func instanceEnvironment(
@@ -434,9 +679,97 @@ func instanceEnvironment(
 		},
 	}
 
+	// Set "restapi.authentication" from a Secret so the credentials stay out
+	// of the configuration files. The client `patronictl` uses them, too.
+	// Patroni must be reloaded when changing these values.
+	if name := APIAuthenticationSecretName(cluster); name != "" {
+		secretKey := func(key string) *corev1.EnvVarSource {
+			return &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Key:                  key,
+			}}
+		}
+		variables = append(variables,
+			corev1.EnvVar{Name: "PATRONI_RESTAPI_USERNAME", ValueFrom: secretKey("username")},
+			corev1.EnvVar{Name: "PATRONI_RESTAPI_PASSWORD", ValueFrom: secretKey("password")},
+		)
+	}
+
 	return variables
 }
 
+// APIAuthenticationSecretName returns the name of the Secret containing
+// credentials of the Patroni REST API or empty string when it requires none.
+func APIAuthenticationSecretName(cluster *v1beta1.PostgresCluster) string {
+	if cluster.Spec.Patroni == nil || cluster.Spec.Patroni.APIAuthentication == nil {
+		return ""
+	}
+	if name := cluster.Spec.Patroni.APIAuthentication.SecretName; name != "" {
+		return name
+	}
+	return naming.PatroniAPIAuthSecret(cluster).Name
+}
+
+// instanceCallbacksPaths returns the "postgresql.callbacks" settings for the
+// callbacks specified on cluster, keyed by Patroni callback name.
+func instanceCallbacksPaths(cluster *v1beta1.PostgresCluster) map[string]string {
+	paths := map[string]string{}
+	for name, selector := range callbacksOf(cluster) {
+		if selector != nil {
+			paths[name] = path.Join(configDirectory, callbacksConfigPath, name)
+		}
+	}
+	return paths
+}
+
+// callbacksOf returns the callbacks specified on cluster keyed by Patroni
+// callback name. Values may be nil.
+func callbacksOf(cluster *v1beta1.PostgresCluster) map[string]*corev1.ConfigMapKeySelector {
+	if cluster.Spec.Patroni == nil || cluster.Spec.Patroni.Callbacks == nil {
+		return nil
+	}
+	callbacks := cluster.Spec.Patroni.Callbacks
+	return map[string]*corev1.ConfigMapKeySelector{
+		"on_role_change": callbacks.OnRoleChange,
+		"on_start":       callbacks.OnStart,
+		"on_stop":        callbacks.OnStop,
+	}
+}
+
+// instanceCallbacks returns projections of the callback scripts specified on
+// cluster to include in the instance configuration volume.
+func instanceCallbacks(cluster *v1beta1.PostgresCluster) []corev1.VolumeProjection {
+	callbacks := callbacksOf(cluster)
+
+	// Iterate in a consistent order so the Pod template does not change.
+	names := make([]string, 0, len(callbacks))
+	for name := range callbacks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Patroni executes the scripts directly, so they must be executable.
+	mode := int32(0o555)
+
+	var projections []corev1.VolumeProjection
+	for _, name := range names {
+		if selector := callbacks[name]; selector != nil {
+			projections = append(projections, corev1.VolumeProjection{
+				ConfigMap: &corev1.ConfigMapProjection{
+					LocalObjectReference: selector.LocalObjectReference,
+					Optional:             selector.Optional,
+					Items: []corev1.KeyToPath{{
+						Key:  selector.Key,
+						Path: path.Join(callbacksConfigPath, name),
+						Mode: &mode,
+					}},
+				},
+			})
+		}
+	}
+	return projections
+}
+
 // instanceConfigFiles returns projections of Patroni's configuration files
 // to include in the instance configuration volume.
 func instanceConfigFiles(cluster, instance *corev1.ConfigMap) []corev1.VolumeProjection {
@@ -469,7 +802,7 @@ func instanceConfigFiles(cluster, instance *corev1.ConfigMap) []corev1.VolumePro
 // instanceYAML returns Patroni settings that apply to instance.
 func instanceYAML(
 	cluster *v1beta1.PostgresCluster, instance *v1beta1.PostgresInstanceSetSpec,
-	pgbackrestReplicaCreateCommand []string,
+	archiveReplicaCreateCommand []string,
 ) (string, error) {
 	root := map[string]interface{}{
 		// Missing here is "name" which cannot be known until the instance Pod is
@@ -530,9 +863,10 @@ func instanceYAML(
 	}
 	methods := []string{"basebackup"}
 
-	// Prefer a pgBackRest method when it is available, and fallback to other
-	// methods when it fails.
-	if command := pgbackrestReplicaCreateCommand; len(command) > 0 {
+	// Prefer the archive method, pgBackRest or WAL-G, when it is available,
+	// and fallback to other methods when it fails.
+	archiveMethod := archive.Name(cluster)
+	if command := archiveReplicaCreateCommand; len(command) > 0 {
 
 		// Regardless of the "keep_data" setting below, Patroni deletes the
 		// data directory when all methods fail. pgBackRest will not restore
@@ -555,67 +889,162 @@ func instanceYAML(
 		for i := range command {
 			quoted[i] = quoteShellWord(command[i])
 		}
-		postgresql[pgBackRestCreateReplicaMethod] = map[string]interface{}{
+		postgresql[archiveMethod] = map[string]interface{}{
 			"command":   strings.Join(quoted, " "),
-			"keep_data": true,
+			"keep_data": archive.ReplicaCreateKeepsData(cluster),
 			"no_master": true,
 			"no_params": true,
 		}
-		methods = append([]string{pgBackRestCreateReplicaMethod}, methods...)
+		methods = append([]string{archiveMethod}, methods...)
+	}
+
+	// Users can reorder or remove the methods above and pass more options to
+	// `pg_basebackup`. Methods that are not available are skipped.
+	if spec := cluster.Spec.Patroni; spec != nil && spec.CreateReplica != nil {
+		postgresql["basebackup"] = append(postgresql["basebackup"].([]string),
+			spec.CreateReplica.BasebackupOptions...)
+
+		if len(spec.CreateReplica.Methods) > 0 {
+			available := methods
+			methods = make([]string, 0, len(available))
+			for _, method := range spec.CreateReplica.Methods {
+				for _, a := range available {
+					if method == a {
+						methods = append(methods, method)
+					}
+				}
+			}
+			if len(methods) == 0 {
+				methods = []string{basebackupCreateReplicaMethod}
+			}
+		}
 	}
 
-	// NOTE(cbandy): Is there any chance a user might want to specify their own
-	// method? This is a list and cannot be merged.
 	postgresql["create_replica_methods"] = methods
 
+	// Patroni in Citus mode manages each instance set as one Citus group. It
+	// creates the extension and registers the leader of every worker group
+	// with the coordinator.
+	// - https://patroni.readthedocs.io/en/latest/citus.html
+	if cluster.Spec.Citus != nil && instance.CitusGroup != nil {
+		database := string(cluster.Spec.Citus.Database)
+		if database == "" {
+			database = "citus"
+		}
+		root["citus"] = map[string]interface{}{
+			"database": database,
+			"group":    *instance.CitusGroup,
+		}
+
+		if *instance.CitusGroup > 0 {
+			// Every group has a leader. Label workers differently so that the
+			// coordinator is the only primary of the PostgresCluster.
+			root["kubernetes"].(map[string]interface{})["role_label"] = naming.LabelCitusRole
+
+			// pgBackRest archives and backs up only the coordinator. Workers
+			// must not push WAL into that stanza nor restore replicas from it.
+			parameters, _ := postgresql["parameters"].(map[string]interface{})
+			if parameters == nil {
+				parameters = make(map[string]interface{})
+			}
+			parameters["archive_command"] = "true"
+			postgresql["parameters"] = parameters
+			postgresql["create_replica_methods"] = []string{basebackupCreateReplicaMethod}
+			delete(postgresql, archiveMethod)
+		}
+	}
+
 	if !ClusterBootstrapped(cluster) {
 		isRestore := (cluster.Status.PGBackRest != nil && cluster.Status.PGBackRest.Restore != nil)
 		isDataSource := (cluster.Spec.DataSource != nil && cluster.Spec.DataSource.Volumes != nil &&
 			cluster.Spec.DataSource.Volumes.PGDataVolume != nil &&
 			cluster.Spec.DataSource.Volumes.PGDataVolume.Directory != "")
+		// PostgreSQL 12 replaced "recovery.conf" with "recovery.signal"
+		// and ordinary parameters like "restore_command".
+		// - https://www.postgresql.org/docs/release/12.0/
+		// A restore from a PostgresCluster or pgBackRest data source has
+		// already replayed WAL on top of the snapshot.
+		isSnapshot := (cluster.Spec.DataSource != nil && cluster.Spec.DataSource.Volumes != nil &&
+			cluster.Spec.DataSource.Volumes.PGDataVolume != nil &&
+			cluster.Spec.DataSource.Volumes.PGDataVolume.VolumeSnapshotName != "" &&
+			cluster.Spec.DataSource.PostgresCluster == nil &&
+			cluster.Spec.DataSource.PGBackRest == nil &&
+			cluster.Spec.PostgresVersion >= 12)
 		// If the cluster is being bootstrapped using existing volumes, or if the cluster is being
 		// bootstrapped following a restore, then use the "existing"
 		// bootstrap method.  Otherwise use "initdb".
-		if isRestore || isDataSource {
+		if isRestore || isDataSource || isSnapshot {
 			data_dir := postgres.DataDirectory(cluster)
+			existing := map[string]interface{}{
+				"command":   fmt.Sprintf(`mv %q %q`, data_dir+"_bootstrap", data_dir),
+				"no_params": "true",
+			}
+			if isSnapshot {
+				// A snapshot is only crash consistent. Ask PostgreSQL to perform
+				// archive recovery so that WAL pushed to pgBackRest after the
+				// snapshot is replayed using "restore_command". PostgreSQL
+				// promotes itself when it reaches the end of the archive.
+				// - https://www.postgresql.org/docs/current/continuous-archiving.html#BACKUP-PITR-RECOVERY
+				// - https://patroni.readthedocs.io/en/latest/replica_bootstrap.html#bootstrap
+				command := fmt.Sprintf(`touch %q`, data_dir+"/recovery.signal")
+				if isRestore || isDataSource {
+					command = existing["command"].(string) + " && " + command
+				}
+				existing["command"] = command
+				existing["keep_existing_recovery_conf"] = true
+			}
 			root["bootstrap"] = map[string]interface{}{
-				"method": "existing",
-				"existing": map[string]interface{}{
-					"command":   fmt.Sprintf(`mv %q %q`, data_dir+"_bootstrap", data_dir),
-					"no_params": "true",
-				},
+				"method":   "existing",
+				"existing": existing,
 			}
 		} else {
 			// Populate some "bootstrap" fields to initialize the cluster.
 			// When Patroni is already bootstrapped, this section is ignored.
 			// - https://github.com/zalando/patroni/blob/v2.0.2/docs/SETTINGS.rst#bootstrap-configuration
 			// - https://github.com/zalando/patroni/blob/v2.0.2/docs/replica_bootstrap.rst#bootstrap
+			//
+			// The "initdb" bootstrap method is configured differently from others.
+			// Patroni prepends "--" before it calls `initdb`.
+			// - https://github.com/zalando/patroni/blob/v2.0.2/patroni/postgresql/bootstrap.py#L45
+			initdb := []string{
+				// Enable checksums on data pages to help detect corruption of
+				// storage that would otherwise be silent. This also enables
+				// "wal_log_hints" which is a prerequisite for using `pg_rewind`.
+				// - https://www.postgresql.org/docs/current/app-initdb.html
+				// - https://www.postgresql.org/docs/current/app-pgrewind.html
+				// - https://www.postgresql.org/docs/current/runtime-config-wal.html
+				//
+				// The benefits of checksums in the Kubernetes storage landscape
+				// outweigh their negligible overhead, and enabling them later
+				// is costly. (Every file of the cluster must be rewritten.)
+				// PostgreSQL v12 introduced the `pg_checksums` utility which
+				// can cheaply disable them while PostgreSQL is stopped.
+				// - https://www.postgresql.org/docs/current/app-pgchecksums.html
+				"data-checksums",
+				"encoding=UTF8",
+
+				// NOTE(cbandy): The "--waldir" option was introduced in PostgreSQL v10.
+				"waldir=" + postgres.WALDirectory(cluster, instance),
+			}
+
+			// Apply any locale settings to the template databases. Databases
+			// created later inherit these unless they specify otherwise.
+			// - https://www.postgresql.org/docs/current/locale.html
+			if locale := cluster.Spec.Config.Locale; locale != nil {
+				if locale.Locale != "" {
+					initdb = append(initdb, "locale="+locale.Locale)
+				}
+				if locale.Provider != "" {
+					initdb = append(initdb, "locale-provider="+locale.Provider)
+				}
+				if locale.ICULocale != "" {
+					initdb = append(initdb, "icu-locale="+locale.ICULocale)
+				}
+			}
+
 			root["bootstrap"] = map[string]interface{}{
 				"method": "initdb",
-
-				// The "initdb" bootstrap method is configured differently from others.
-				// Patroni prepends "--" before it calls `initdb`.
-				// - https://github.com/zalando/patroni/blob/v2.0.2/patroni/postgresql/bootstrap.py#L45
-				"initdb": []string{
-					// Enable checksums on data pages to help detect corruption of
-					// storage that would otherwise be silent. This also enables
-					// "wal_log_hints" which is a prerequisite for using `pg_rewind`.
-					// - https://www.postgresql.org/docs/current/app-initdb.html
-					// - https://www.postgresql.org/docs/current/app-pgrewind.html
-					// - https://www.postgresql.org/docs/current/runtime-config-wal.html
-					//
-					// The benefits of checksums in the Kubernetes storage landscape
-					// outweigh their negligible overhead, and enabling them later
-					// is costly. (Every file of the cluster must be rewritten.)
-					// PostgreSQL v12 introduced the `pg_checksums` utility which
-					// can cheaply disable them while PostgreSQL is stopped.
-					// - https://www.postgresql.org/docs/current/app-pgchecksums.html
-					"data-checksums",
-					"encoding=UTF8",
-
-					// NOTE(cbandy): The "--waldir" option was introduced in PostgreSQL v10.
-					"waldir=" + postgres.WALDirectory(cluster, instance),
-				},
+				"initdb": initdb,
 			}
 		}
 	}
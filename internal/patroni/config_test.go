@@ -24,7 +24,9 @@ import (
 
 	"gotest.tools/v3/assert"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/yaml"
 
 	"github.com/crunchydata/postgres-operator/internal/initialize"
@@ -151,11 +153,22 @@ watchdog:
   mode: "off"
 	`)+"\n")
 	})
+
+	t.Run("ConfigMaps", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Default()
+		cluster.Spec.Patroni.UseConfigMaps = initialize.Bool(true)
+
+		data, err := clusterYAML(cluster, postgres.HBAs{}, postgres.Parameters{})
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(data, "\n  use_endpoints: false\n"), "got:\n%s", data)
+	})
 }
 
 func TestDynamicConfiguration(t *testing.T) {
 	t.Parallel()
 
+	newBool := func(b bool) *bool { return &b }
 	newInt32 := func(i int32) *int32 { return &i }
 	parameters := func(in map[string]string) *postgres.ParameterSet {
 		out := postgres.NewParameterSet()
@@ -228,6 +241,215 @@ func TestDynamicConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "top-level: synchronous replication overrides input",
+			cluster: &v1beta1.PostgresCluster{
+				Spec: v1beta1.PostgresClusterSpec{
+					Patroni: &v1beta1.PatroniSpec{
+						SynchronousMode:       newBool(true),
+						SynchronousModeStrict: newBool(false),
+						SynchronousNodeCount:  newInt32(2),
+					},
+				},
+			},
+			input: map[string]interface{}{
+				"synchronous_mode":        false,
+				"synchronous_mode_strict": true,
+				"synchronous_node_count":  5,
+			},
+			expected: map[string]interface{}{
+				"loop_wait":               int32(10),
+				"ttl":                     int32(30),
+				"synchronous_mode":        true,
+				"synchronous_mode_strict": false,
+				"synchronous_node_count":  int32(2),
+				"postgresql": map[string]interface{}{
+					"parameters":    map[string]interface{}{},
+					"pg_hba":        []string{},
+					"use_pg_rewind": true,
+					"use_slots":     false,
+				},
+			},
+		},
+		{
+			name: "top-level: synchronous replication input passes through",
+			input: map[string]interface{}{
+				"synchronous_mode": true,
+			},
+			expected: map[string]interface{}{
+				"loop_wait":        int32(10),
+				"ttl":              int32(30),
+				"synchronous_mode": true,
+				"postgresql": map[string]interface{}{
+					"parameters":    map[string]interface{}{},
+					"pg_hba":        []string{},
+					"use_pg_rewind": true,
+					"use_slots":     false,
+				},
+			},
+		},
+		{
+			name: "top-level: pause overrides input",
+			cluster: &v1beta1.PostgresCluster{
+				Spec: v1beta1.PostgresClusterSpec{
+					Patroni: &v1beta1.PatroniSpec{
+						Paused: newBool(false),
+					},
+				},
+			},
+			input: map[string]interface{}{
+				"pause": true,
+			},
+			expected: map[string]interface{}{
+				"loop_wait": int32(10),
+				"ttl":       int32(30),
+				"pause":     false,
+				"postgresql": map[string]interface{}{
+					"parameters":    map[string]interface{}{},
+					"pg_hba":        []string{},
+					"use_pg_rewind": true,
+					"use_slots":     false,
+				},
+			},
+		},
+		{
+			name: "slots: spec merges with input",
+			cluster: &v1beta1.PostgresCluster{
+				Spec: v1beta1.PostgresClusterSpec{
+					ReplicationSlots: []v1beta1.PostgresReplicationSlotSpec{
+						{Name: "debezium", Type: "logical", Database: "app", Plugin: "pgoutput"},
+						{Name: "archiver", Type: "physical"},
+					},
+				},
+			},
+			input: map[string]interface{}{
+				"postgresql": map[string]interface{}{
+					"use_slots": false,
+				},
+				"slots": map[string]interface{}{
+					"debezium": map[string]interface{}{"type": "physical"},
+					"other":    map[string]interface{}{"type": "physical"},
+				},
+			},
+			expected: map[string]interface{}{
+				"loop_wait": int32(10),
+				"ttl":       int32(30),
+				"ignore_slots": []interface{}{
+					map[string]interface{}{"type": "logical"},
+				},
+				"slots": map[string]interface{}{
+					"archiver": map[string]interface{}{"type": "physical"},
+					"debezium": map[string]interface{}{
+						"type": "logical", "database": "app", "plugin": "pgoutput",
+					},
+					"other": map[string]interface{}{"type": "physical"},
+				},
+				"postgresql": map[string]interface{}{
+					"parameters":    map[string]interface{}{},
+					"pg_hba":        []string{},
+					"use_pg_rewind": true,
+					"use_slots":     true,
+				},
+			},
+		},
+		{
+			name: "slots: logical decoding",
+			cluster: &v1beta1.PostgresCluster{
+				Spec: v1beta1.PostgresClusterSpec{
+					Replication: &v1beta1.PostgresReplicationSpec{
+						LogicalDecoding: &v1beta1.LogicalDecodingSpec{
+							Slots: []v1beta1.LogicalDecodingSlotSpec{
+								{Name: "cdc", Database: "app"},
+								{Name: "json", Database: "app", Plugin: "wal2json"},
+							},
+						},
+					},
+				},
+			},
+			input: map[string]interface{}{
+				"ignore_slots": []interface{}{
+					map[string]interface{}{"name": "existing", "type": "physical"},
+				},
+			},
+			expected: map[string]interface{}{
+				"loop_wait": int32(10),
+				"ttl":       int32(30),
+				"ignore_slots": []interface{}{
+					map[string]interface{}{"name": "existing", "type": "physical"},
+					map[string]interface{}{"type": "logical"},
+				},
+				"slots": map[string]interface{}{
+					"cdc": map[string]interface{}{
+						"type": "logical", "database": "app", "plugin": "pgoutput",
+					},
+					"json": map[string]interface{}{
+						"type": "logical", "database": "app", "plugin": "wal2json",
+					},
+				},
+				"postgresql": map[string]interface{}{
+					"parameters":    map[string]interface{}{},
+					"pg_hba":        []string{},
+					"use_pg_rewind": true,
+					"use_slots":     true,
+				},
+			},
+		},
+		{
+			name: "top-level: failover settings override input",
+			cluster: &v1beta1.PostgresCluster{
+				Spec: v1beta1.PostgresClusterSpec{
+					Patroni: &v1beta1.PatroniSpec{
+						MaximumLagOnFailover:       resource.NewQuantity(16<<20, resource.BinarySI),
+						PrimaryStartTimeoutSeconds: newInt32(0),
+						RetryTimeoutSeconds:        newInt32(5),
+					},
+				},
+			},
+			input: map[string]interface{}{
+				"maximum_lag_on_failover": 1,
+				"master_start_timeout":    300,
+				"retry_timeout":           "nope",
+			},
+			expected: map[string]interface{}{
+				"loop_wait":               int32(10),
+				"ttl":                     int32(30),
+				"maximum_lag_on_failover": int64(16 << 20),
+				"master_start_timeout":    int32(0),
+				"retry_timeout":           int32(5),
+				"postgresql": map[string]interface{}{
+					"parameters":    map[string]interface{}{},
+					"pg_hba":        []string{},
+					"use_pg_rewind": true,
+					"use_slots":     false,
+				},
+			},
+		},
+		{
+			name: "postgresql: rewind can be disabled",
+			cluster: &v1beta1.PostgresCluster{
+				Spec: v1beta1.PostgresClusterSpec{
+					PostgresVersion: 14,
+					Patroni: &v1beta1.PatroniSpec{
+						Rewind: &v1beta1.PatroniRewind{Enabled: newBool(false)},
+					},
+				},
+			},
+			input: map[string]interface{}{
+				"postgresql": map[string]interface{}{
+					"use_pg_rewind": true,
+				},
+			},
+			expected: map[string]interface{}{
+				"loop_wait": int32(10),
+				"ttl":       int32(30),
+				"postgresql": map[string]interface{}{
+					"parameters":    map[string]interface{}{},
+					"pg_hba":        []string{},
+					"use_pg_rewind": false,
+					"use_slots":     false,
+				},
+			},
+		},
 		{
 			name: "postgresql: wrong-type is ignored",
 			input: map[string]interface{}{
@@ -367,6 +589,52 @@ func TestDynamicConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "postgresql.parameters: spec overrides input",
+			cluster: &v1beta1.PostgresCluster{
+				Spec: v1beta1.PostgresClusterSpec{
+					Config: v1beta1.PostgresAdditionalConfig{
+						Parameters: map[string]intstr.IntOrString{
+							"something":       intstr.FromString("spec"),
+							"max_connections": intstr.FromInt(200),
+							"unrelated":       intstr.FromString("ignored"),
+
+							"autovacuum_vacuum_scale_factor": intstr.FromString("0.05"),
+						},
+					},
+				},
+			},
+			input: map[string]interface{}{
+				"postgresql": map[string]interface{}{
+					"parameters": map[string]interface{}{
+						"something": "str",
+						"another":   5,
+					},
+				},
+			},
+			params: postgres.Parameters{
+				Mandatory: parameters(map[string]string{
+					"unrelated": "setting",
+				}),
+			},
+			expected: map[string]interface{}{
+				"loop_wait": int32(10),
+				"ttl":       int32(30),
+				"postgresql": map[string]interface{}{
+					"parameters": map[string]interface{}{
+						"something":       "spec",
+						"another":         5,
+						"max_connections": "200",
+						"unrelated":       "setting",
+
+						"autovacuum_vacuum_scale_factor": "0.05",
+					},
+					"pg_hba":        []string{},
+					"use_pg_rewind": true,
+					"use_slots":     false,
+				},
+			},
+		},
 		{
 			name: "postgresql.parameters: mandatory shared_preload_libraries",
 			input: map[string]interface{}{
@@ -515,6 +783,72 @@ func TestDynamicConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "postgresql.pg_hba: spec after mandatory and before others",
+			cluster: &v1beta1.PostgresCluster{
+				Spec: v1beta1.PostgresClusterSpec{
+					Config: v1beta1.PostgresAdditionalConfig{
+						HBA: []v1beta1.PostgresHBARule{
+							{Rule: "hostssl all all all cert"},
+							{Users: []v1beta1.PostgresIdentifier{"app"}, Method: "ldap"},
+						},
+					},
+				},
+			},
+			input: map[string]interface{}{
+				"postgresql": map[string]interface{}{
+					"pg_hba": []interface{}{"custom"},
+				},
+			},
+			hbas: postgres.HBAs{
+				Mandatory: []postgres.HostBasedAuthentication{
+					*postgres.NewHBA().Local().Method("peer"),
+				},
+			},
+			expected: map[string]interface{}{
+				"loop_wait": int32(10),
+				"ttl":       int32(30),
+				"postgresql": map[string]interface{}{
+					"parameters": map[string]interface{}{},
+					"pg_hba": []string{
+						"local all all peer",
+						"hostssl all all all cert",
+						`hostssl all "app" all ldap`,
+						"custom",
+					},
+					"use_pg_rewind": true,
+					"use_slots":     false,
+				},
+			},
+		},
+		{
+			name: "postgresql.pg_hba: spec and default when no input",
+			cluster: &v1beta1.PostgresCluster{
+				Spec: v1beta1.PostgresClusterSpec{
+					Config: v1beta1.PostgresAdditionalConfig{
+						HBA: []v1beta1.PostgresHBARule{{Rule: "hostssl all all all cert"}},
+					},
+				},
+			},
+			hbas: postgres.HBAs{
+				Default: []postgres.HostBasedAuthentication{
+					*postgres.NewHBA().Local().Method("peer"),
+				},
+			},
+			expected: map[string]interface{}{
+				"loop_wait": int32(10),
+				"ttl":       int32(30),
+				"postgresql": map[string]interface{}{
+					"parameters": map[string]interface{}{},
+					"pg_hba": []string{
+						"hostssl all all all cert",
+						"local all all peer",
+					},
+					"use_pg_rewind": true,
+					"use_slots":     false,
+				},
+			},
+		},
 		{
 			name: "postgresql.pg_hba: ignore non-string types",
 			input: map[string]interface{}{
@@ -541,6 +875,28 @@ func TestDynamicConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "postgresql.pg_ident: mandatory before input",
+			input: map[string]interface{}{
+				"postgresql": map[string]interface{}{
+					"pg_ident": []interface{}{1, "custom"},
+				},
+			},
+			hbas: postgres.HBAs{
+				Idents: []string{"map system role"},
+			},
+			expected: map[string]interface{}{
+				"loop_wait": int32(10),
+				"ttl":       int32(30),
+				"postgresql": map[string]interface{}{
+					"parameters":    map[string]interface{}{},
+					"pg_hba":        []string{},
+					"pg_ident":      []string{"map system role", "custom"},
+					"use_pg_rewind": true,
+					"use_slots":     false,
+				},
+			},
+		},
 		{
 			name: "standby_cluster: input passes through",
 			input: map[string]interface{}{
@@ -745,6 +1101,205 @@ func TestInstanceConfigFiles(t *testing.T) {
 	`))
 }
 
+func TestClusterYAMLAPICiphers(t *testing.T) {
+	t.Parallel()
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Default()
+	cluster.Spec.Patroni.APICiphers = "ECDHE+AESGCM:!DHE"
+
+	data, err := clusterYAML(cluster, postgres.HBAs{}, postgres.Parameters{})
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(data, `
+restapi:
+  cafile: /etc/patroni/~postgres-operator/patroni.ca-roots
+  certfile: /etc/patroni/~postgres-operator/patroni.crt+key
+  ciphers: ECDHE+AESGCM:!DHE
+`), "got:\n%s", data)
+}
+
+func TestClusterYAMLLogging(t *testing.T) {
+	t.Parallel()
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Default()
+	cluster.Spec.Patroni.Logging = &v1beta1.PatroniLogConfig{
+		StorageLimit: resource.NewQuantity(10_000_000, resource.DecimalSI),
+		Level:        initialize.String("DEBUG"),
+		Format:       initialize.String("json"),
+	}
+
+	data, err := clusterYAML(cluster, postgres.HBAs{}, postgres.Parameters{})
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(data, `
+log:
+  dir: /pgdata/patroni/log
+  file_num: 1
+  file_size: 5000000
+  level: DEBUG
+  type: json
+`), "got:\n%s", data)
+
+	t.Run("Tiny", func(t *testing.T) {
+		cluster.Spec.Patroni.Logging = &v1beta1.PatroniLogConfig{
+			StorageLimit: resource.NewMilliQuantity(1, resource.DecimalSI),
+		}
+
+		data, err := clusterYAML(cluster, postgres.HBAs{}, postgres.Parameters{})
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(data, `
+  file_size: 1
+`), "got:\n%s", data)
+	})
+}
+
+func TestDynamicConfigurationConflicts(t *testing.T) {
+	t.Parallel()
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Default()
+
+	parameters := postgres.NewParameters()
+	parameters.Mandatory.Add("wal_level", "logical")
+	parameters.Mandatory.Add("shared_preload_libraries", "pgaudit")
+
+	assert.Assert(t, len(DynamicConfigurationConflicts(cluster, nil, parameters)) == 0)
+	assert.Assert(t, len(DynamicConfigurationConflicts(cluster, map[string]interface{}{
+		"retry_timeout":    10,
+		"synchronous_mode": true,
+		"postgresql": map[string]interface{}{
+			"parameters": map[string]interface{}{
+				"shared_preload_libraries": "other",
+				"work_mem":                 "1MB",
+			},
+		},
+	}, parameters)) == 0)
+
+	cluster.Spec.Patroni.PrimaryStartTimeoutSeconds = initialize.Int32(60)
+	cluster.Spec.Patroni.SynchronousMode = initialize.Bool(true)
+	errs := DynamicConfigurationConflicts(cluster, map[string]interface{}{
+		"master_start_timeout": 300,
+		"scope":                "nope",
+		"ttl":                  5,
+		"synchronous_mode":     false,
+		"postgresql": map[string]interface{}{
+			"parameters": map[string]interface{}{
+				"WAL_LEVEL": "replica",
+			},
+		},
+	}, parameters)
+
+	assert.Equal(t, errs.ToAggregate().Error(), "["+strings.Join([]string{
+		`spec.patroni.dynamicConfiguration.master_start_timeout: Forbidden: is overridden by spec.patroni.primaryStartTimeoutSeconds`,
+		`spec.patroni.dynamicConfiguration.postgresql.parameters.WAL_LEVEL: Forbidden: is managed by the operator and overridden`,
+		`spec.patroni.dynamicConfiguration.scope: Forbidden: is managed by the operator and ignored here`,
+		`spec.patroni.dynamicConfiguration.synchronous_mode: Forbidden: is overridden by spec.patroni.synchronousMode`,
+		`spec.patroni.dynamicConfiguration.ttl: Forbidden: is overridden by spec.patroni.leaderLeaseDurationSeconds`,
+	}, ", ")+"]")
+
+	cluster.Spec.Patroni.PrimaryStartTimeoutSeconds = nil
+	cluster.Spec.Patroni.SynchronousMode = nil
+	cluster.Spec.Config.Parameters = map[string]intstr.IntOrString{
+		"shared_preload_libraries": intstr.FromString("other"),
+		"wal_level":                intstr.FromString("minimal"),
+		"work_mem":                 intstr.FromString("1MB"),
+	}
+	errs = DynamicConfigurationConflicts(cluster, nil, parameters)
+	assert.Equal(t, errs.ToAggregate().Error(),
+		`spec.config.parameters[wal_level]: Forbidden: is managed by the operator and overridden`)
+}
+
+func TestClusterYAMLRewind(t *testing.T) {
+	t.Parallel()
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Default()
+	cluster.Spec.Patroni.Rewind = &v1beta1.PatroniRewind{
+		RemoveDataDirectoryOnFailure:           initialize.Bool(true),
+		RemoveDataDirectoryOnDivergedTimelines: initialize.Bool(false),
+	}
+
+	data, err := clusterYAML(cluster, postgres.HBAs{}, postgres.Parameters{})
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(data, `
+  remove_data_directory_on_diverged_timelines: false
+  remove_data_directory_on_rewind_failure: true
+`), "got:\n%s", data)
+}
+
+func TestClusterYAMLCustomConfig(t *testing.T) {
+	t.Parallel()
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Default()
+
+	data, err := clusterYAML(cluster, postgres.HBAs{}, postgres.Parameters{})
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(data, "custom_conf"), "got:\n%s", data)
+
+	cluster.Spec.Config.Files = []corev1.VolumeProjection{{
+		ConfigMap: &corev1.ConfigMapProjection{},
+	}}
+
+	data, err = clusterYAML(cluster, postgres.HBAs{}, postgres.Parameters{})
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(data, `
+  custom_conf: /etc/postgres/~postgres-operator/custom.conf
+`), "got:\n%s", data)
+}
+
+func TestInstanceCallbacks(t *testing.T) {
+	t.Parallel()
+
+	cluster := new(v1beta1.PostgresCluster)
+	assert.Assert(t, instanceCallbacks(cluster) == nil)
+	assert.Assert(t, len(instanceCallbacksPaths(cluster)) == 0)
+
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+		Callbacks: &v1beta1.PatroniCallbacks{
+			OnRoleChange: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "scripts"},
+				Key:                  "notify.sh",
+			},
+			OnStop: &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "other"},
+				Key:                  "stop",
+				Optional:             initialize.Bool(true),
+			},
+		},
+	}
+
+	assert.Assert(t, cmp.MarshalMatches(instanceCallbacks(cluster), `
+- configMap:
+    items:
+    - key: notify.sh
+      mode: 365
+      path: ~postgres-operator/callbacks/on_role_change
+    name: scripts
+- configMap:
+    items:
+    - key: stop
+      mode: 365
+      path: ~postgres-operator/callbacks/on_stop
+    name: other
+    optional: true
+	`))
+
+	assert.DeepEqual(t, instanceCallbacksPaths(cluster), map[string]string{
+		"on_role_change": "/etc/patroni/~postgres-operator/callbacks/on_role_change",
+		"on_stop":        "/etc/patroni/~postgres-operator/callbacks/on_stop",
+	})
+
+	cluster.Default()
+	data, err := clusterYAML(cluster, postgres.HBAs{}, postgres.Parameters{})
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(data, `
+  callbacks:
+    on_role_change: /etc/patroni/~postgres-operator/callbacks/on_role_change
+    on_stop: /etc/patroni/~postgres-operator/callbacks/on_stop
+`), "got:\n%s", data)
+}
+
 func TestInstanceEnvironment(t *testing.T) {
 	t.Parallel()
 
@@ -829,6 +1384,29 @@ func TestInstanceEnvironment(t *testing.T) {
   value: /etc/patroni
 		`))
 	})
+
+	t.Run("APIAuthentication", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Name = "hippo"
+		cluster.Spec.Patroni.APIAuthentication = &v1beta1.PatroniAPIAuthentication{}
+
+		vars := instanceEnvironment(cluster, podService, leaderService, nil)
+		assert.Assert(t, cmp.MarshalMatches(vars[len(vars)-2:], `
+- name: PATRONI_RESTAPI_USERNAME
+  valueFrom:
+    secretKeyRef:
+      key: username
+      name: hippo-patroni-api-auth
+- name: PATRONI_RESTAPI_PASSWORD
+  valueFrom:
+    secretKeyRef:
+      key: password
+      name: hippo-patroni-api-auth
+		`))
+
+		cluster.Spec.Patroni.APIAuthentication.SecretName = "custom"
+		assert.Equal(t, APIAuthenticationSecretName(cluster), "custom")
+	})
 }
 
 func TestInstanceYAML(t *testing.T) {
@@ -889,6 +1467,187 @@ postgresql:
 restapi: {}
 tags: {}
 	`, "\t\n")+"\n")
+
+	t.Run("CreateReplica", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+			CreateReplica: &v1beta1.PatroniCreateReplica{
+				Methods:           []string{"basebackup", "pgbackrest"},
+				BasebackupOptions: []string{"max-rate=100M"},
+			},
+		}
+
+		data, err := instanceYAML(cluster, instance, []string{"some", "backrest", "cmd"})
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(data, `
+  basebackup:
+  - waldir=/pgdata/pg12_wal
+  - max-rate=100M
+  create_replica_methods:
+  - basebackup
+  - pgbackrest
+`), "got:\n%s", data)
+
+		// Methods that are not available are skipped.
+		cluster.Spec.Patroni.CreateReplica.Methods = []string{"pgbackrest"}
+
+		data, err = instanceYAML(cluster, instance, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(data, `
+  create_replica_methods:
+  - basebackup
+`), "got:\n%s", data)
+	})
+
+	t.Run("WALG", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Backups.WALG = &v1beta1.WALGArchive{
+			Env: []corev1.EnvVar{{Name: "WALG_S3_PREFIX", Value: "s3://bucket"}},
+		}
+
+		data, err := instanceYAML(cluster, instance, []string{"some", "walg", "cmd"})
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(data, `
+  create_replica_methods:
+  - walg
+  - basebackup
+`), "got:\n%s", data)
+		assert.Assert(t, strings.Contains(data, `
+  walg:
+    command: '''bash'' ''-ceu'' ''--'' ''install --directory --mode=0700 "${PGDATA?}"
+      && exec "$@"'' ''-'' ''some'' ''walg'' ''cmd'''
+    keep_data: false
+    no_master: true
+    no_params: true
+`), "got:\n%s", data)
+		assert.Assert(t, !strings.Contains(data, "pgbackrest"), "got:\n%s", data)
+	})
+
+	t.Run("Locale", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.PostgresVersion = 15
+		cluster.Spec.Config.Locale = &v1beta1.PostgresLocaleSpec{
+			Provider:  "icu",
+			Locale:    "en_US.UTF-8",
+			ICULocale: "en-US",
+		}
+
+		data, err := instanceYAML(cluster, instance, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(data, `
+  initdb:
+  - data-checksums
+  - encoding=UTF8
+  - waldir=/pgdata/pg15_wal
+  - locale=en_US.UTF-8
+  - locale-provider=icu
+  - icu-locale=en-US
+`), "got:\n%s", data)
+	})
+
+	t.Run("VolumeSnapshot", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.DataSource = &v1beta1.DataSource{
+			Volumes: &v1beta1.DataSourceVolumes{
+				PGDataVolume: &v1beta1.DataSourceVolume{
+					PVCName:            "some-pvc",
+					VolumeSnapshotName: "some-snapshot",
+				},
+			},
+		}
+
+		data, err := instanceYAML(cluster, instance, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(data, `
+bootstrap:
+  existing:
+    command: touch "/pgdata/pg12/recovery.signal"
+    keep_existing_recovery_conf: true
+    no_params: "true"
+  method: existing
+`), "got:\n%s", data)
+
+		// The existing directory is moved before recovery.
+		cluster.Spec.DataSource.Volumes.PGDataVolume.Directory = "old"
+
+		data, err = instanceYAML(cluster, instance, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(data,
+			`command: mv "/pgdata/pg12_bootstrap" "/pgdata/pg12" && touch "/pgdata/pg12/recovery.signal"`,
+		), "got:\n%s", data)
+
+		// A restore from the source repository has already recovered.
+		cluster.Spec.DataSource.Volumes.PGDataVolume.Directory = ""
+		cluster.Spec.DataSource.PostgresCluster = &v1beta1.PostgresClusterDataSource{
+			ClusterName: "source", RepoName: "repo1",
+		}
+		cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+			Restore: &v1beta1.PGBackRestJobStatus{Finished: true},
+		}
+
+		data, err = instanceYAML(cluster, instance, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(data, `
+bootstrap:
+  existing:
+    command: mv "/pgdata/pg12_bootstrap" "/pgdata/pg12"
+    no_params: "true"
+  method: existing
+`), "got:\n%s", data)
+
+		// PostgreSQL 11 and earlier only perform crash recovery.
+		cluster.Spec.PostgresVersion = 11
+		cluster.Spec.DataSource.PostgresCluster = nil
+		cluster.Status.PGBackRest = nil
+
+		data, err = instanceYAML(cluster, instance, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(data, `method: initdb`), "got:\n%s", data)
+	})
+
+	t.Run("Citus", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Citus = &v1beta1.CitusSpec{Database: "app"}
+
+		coordinator := instance.DeepCopy()
+		coordinator.CitusGroup = initialize.Int32(0)
+
+		data, err := instanceYAML(cluster, coordinator, []string{"some", "command"})
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(data, `
+citus:
+  database: app
+  group: 0
+kubernetes: {}
+`), "got:\n%s", data)
+		assert.Assert(t, strings.Contains(data, `
+  create_replica_methods:
+  - pgbackrest
+  - basebackup
+`), "got:\n%s", data)
+
+		worker := instance.DeepCopy()
+		worker.CitusGroup = initialize.Int32(2)
+
+		data, err = instanceYAML(cluster, worker, []string{"some", "command"})
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(data, `
+citus:
+  database: app
+  group: 2
+kubernetes:
+  role_label: postgres-operator.crunchydata.com/citus-role
+`), "got:\n%s", data)
+		assert.Assert(t, strings.Contains(data, `
+  create_replica_methods:
+  - basebackup
+`), "got:\n%s", data)
+		assert.Assert(t, strings.Contains(data, `
+  parameters:
+    archive_command: "true"
+`), "got:\n%s", data)
+		assert.Assert(t, !strings.Contains(data, "pgbackrest"), "got:\n%s", data)
+	})
 }
 
 func TestPGBackRestCreateReplicaCommand(t *testing.T) {
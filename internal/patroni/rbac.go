@@ -27,8 +27,6 @@ import (
 // +kubebuilder:rbac:namespace=patroni,groups="",resources=pods,verbs=list;watch
 // +kubebuilder:rbac:namespace=patroni,groups="",resources=pods,verbs=patch
 
-// TODO(cbandy): Separate these so that one can choose ConfigMap over Endpoints.
-
 // When using Endpoints for DCS, "create", "list", "patch", and "watch" are
 // required. Include "get" for good measure. The `patronictl scaffold` and
 // `patronictl remove` commands require "deletecollection".
@@ -38,6 +36,12 @@ import (
 // +kubebuilder:rbac:namespace=patroni,groups="",resources=endpoints,verbs=patch
 // +kubebuilder:rbac:namespace=patroni,groups="",resources=services,verbs=create
 
+// When using ConfigMaps for DCS, the same verbs are required on ConfigMaps.
+// +kubebuilder:rbac:namespace=patroni,groups="",resources=configmaps,verbs=get
+// +kubebuilder:rbac:namespace=patroni,groups="",resources=configmaps,verbs=create;deletecollection
+// +kubebuilder:rbac:namespace=patroni,groups="",resources=configmaps,verbs=list;watch
+// +kubebuilder:rbac:namespace=patroni,groups="",resources=configmaps,verbs=patch
+
 // The OpenShift RestrictedEndpointsAdmission plugin requires special
 // authorization to create Endpoints that contain Pod IPs.
 // - https://github.com/openshift/origin/pull/9383
@@ -45,10 +49,24 @@ import (
 
 // Permissions returns the RBAC rules Patroni needs for cluster.
 func Permissions(cluster *v1beta1.PostgresCluster) []rbacv1.PolicyRule {
-	// TODO(cbandy): This must change when using ConfigMaps for DCS.
-
 	rules := make([]rbacv1.PolicyRule, 0, 4)
 
+	if UseConfigMaps(cluster) {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{corev1.SchemeGroupVersion.Group},
+			Resources: []string{"configmaps"},
+			Verbs:     []string{"create", "deletecollection", "get", "list", "patch", "watch"},
+		})
+
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{corev1.SchemeGroupVersion.Group},
+			Resources: []string{"pods"},
+			Verbs:     []string{"get", "list", "patch", "watch"},
+		})
+
+		return rules
+	}
+
 	rules = append(rules, rbacv1.PolicyRule{
 		APIGroups: []string{corev1.SchemeGroupVersion.Group},
 		Resources: []string{"endpoints"},
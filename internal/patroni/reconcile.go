@@ -17,15 +17,16 @@ package patroni
 
 import (
 	"context"
+	"encoding/json"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
+	"github.com/crunchydata/postgres-operator/internal/archive"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
-	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
 	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
@@ -37,6 +38,13 @@ func ClusterBootstrapped(postgresCluster *v1beta1.PostgresCluster) bool {
 	return postgresCluster.Status.Patroni.SystemIdentifier != ""
 }
 
+// UseConfigMaps returns whether or not Patroni stores its distributed
+// configuration in ConfigMaps rather than Endpoints for cluster.
+func UseConfigMaps(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.Patroni != nil &&
+		cluster.Spec.Patroni.UseConfigMaps != nil && *cluster.Spec.Patroni.UseConfigMaps
+}
+
 // ClusterConfigMap populates the shared ConfigMap with fields needed to run Patroni.
 func ClusterConfigMap(ctx context.Context,
 	inCluster *v1beta1.PostgresCluster,
@@ -44,6 +52,9 @@ func ClusterConfigMap(ctx context.Context,
 	inParameters postgres.Parameters,
 	outClusterConfigMap *corev1.ConfigMap,
 ) error {
+	_, span := tracer.Start(ctx, "patroni-cluster-configmap")
+	defer span.End()
+
 	var err error
 
 	initialize.StringMap(&outClusterConfigMap.Data)
@@ -51,6 +62,7 @@ func ClusterConfigMap(ctx context.Context,
 	outClusterConfigMap.Data[configMapFileKey], err = clusterYAML(inCluster, inHBAs,
 		inParameters)
 
+	span.RecordError(err)
 	return err
 }
 
@@ -60,15 +72,19 @@ func InstanceConfigMap(ctx context.Context,
 	inInstanceSpec *v1beta1.PostgresInstanceSetSpec,
 	outInstanceConfigMap *corev1.ConfigMap,
 ) error {
+	_, span := tracer.Start(ctx, "patroni-instance-configmap")
+	defer span.End()
+
 	var err error
 
 	initialize.StringMap(&outInstanceConfigMap.Data)
 
-	command := pgbackrest.ReplicaCreateCommand(inCluster, inInstanceSpec)
+	command := archive.ReplicaCreateCommand(inCluster, inInstanceSpec)
 
 	outInstanceConfigMap.Data[configMapFileKey], err = instanceYAML(
 		inCluster, inInstanceSpec, command)
 
+	span.RecordError(err)
 	return err
 }
 
@@ -127,8 +143,9 @@ func InstancePod(ctx context.Context,
 	// Add our projections after those specified in the CR. Items later in the
 	// list take precedence over earlier items (that is, last write wins).
 	// - https://kubernetes.io/docs/concepts/storage/volumes/#projected
-	volume.Projected.Sources = append(append(volume.Projected.Sources,
+	volume.Projected.Sources = append(append(append(volume.Projected.Sources,
 		instanceConfigFiles(inClusterConfigMap, inInstanceConfigMap)...),
+		instanceCallbacks(inCluster)...),
 		instanceCertificates(inInstanceCertificates)...)
 
 	outInstancePod.Spec.Volumes = append(outInstancePod.Spec.Volumes, volume)
@@ -211,3 +228,19 @@ func PodRequiresRestart(pod metav1.Object) bool {
 	status := pod.GetAnnotations()["status"]
 	return strings.Contains(status, `"pending_restart":true`)
 }
+
+// PodTimeline returns the PostgreSQL timeline that Patroni last reported for
+// pod. It returns zero when the timeline is not known.
+func PodTimeline(pod metav1.Object) int64 {
+	if pod == nil {
+		return 0
+	}
+
+	// Patroni writes this annotation only when Kubernetes is its DCS.
+	// - https://github.com/zalando/patroni/blob/v2.1.1/patroni/ha.py#L196
+	var status struct {
+		Timeline int64 `json:"timeline"`
+	}
+	_ = json.Unmarshal([]byte(pod.GetAnnotations()["status"]), &status)
+	return status.Timeline
+}
@@ -276,3 +276,20 @@ func TestPodRequiresRestart(t *testing.T) {
 	pod.Annotations["status"] = `{"pending_restart":true}`
 	assert.Assert(t, PodRequiresRestart(pod))
 }
+
+func TestPodTimeline(t *testing.T) {
+	// No object
+	assert.Equal(t, PodTimeline(nil), int64(0))
+
+	// No annotations
+	pod := &corev1.Pod{}
+	assert.Equal(t, PodTimeline(pod), int64(0))
+
+	// Unexpected value
+	pod.Annotations = map[string]string{"status": `{"timeline":"mystery"}`}
+	assert.Equal(t, PodTimeline(pod), int64(0))
+
+	// Expected value
+	pod.Annotations["status"] = `{"role":"master","state":"running","timeline":3}`
+	assert.Equal(t, PodTimeline(pod), int64(3))
+}
@@ -59,6 +59,12 @@ const (
 	// configMountPath is where to mount configuration files, secrets, etc.
 	configMountPath = "/etc/pgadmin/conf.d"
 
+	// certAuthorityAbsolutePath is the path to the certificate authority of
+	// the PostgreSQL server; libpq reads it through PGSSLROOTCERT.
+	certAuthorityAbsolutePath   = configMountPath + "/" + certAuthorityProjectionPath
+	certAuthorityProjectionPath = "~postgres-operator/ca.crt"
+	certAuthoritySecretKey      = "ca.crt"
+
 	settingsAbsolutePath   = configMountPath + "/" + settingsProjectionPath
 	settingsConfigMapKey   = "pgadmin-settings.json"
 	settingsProjectionPath = "~postgres-operator/pgadmin.json"
@@ -75,6 +81,31 @@ const (
 	configSystemAbsolutePath = startupMountPath + "/config_system.py"
 )
 
+// certAuthority creates a volume projection of the PostgreSQL server
+// certificate authority.
+func certAuthority(postgres *corev1.SecretProjection) corev1.VolumeProjection {
+	var items []corev1.KeyToPath
+	result := postgres.DeepCopy()
+
+	for i := range result.Items {
+		// The PostgreSQL server projection expects Path to match typical Keys.
+		if result.Items[i].Path == certAuthoritySecretKey {
+			result.Items[i].Path = certAuthorityProjectionPath
+			items = append(items, result.Items[i])
+		}
+	}
+
+	if len(items) == 0 {
+		items = []corev1.KeyToPath{{
+			Key:  certAuthoritySecretKey,
+			Path: certAuthorityProjectionPath,
+		}}
+	}
+
+	result.Items = items
+	return corev1.VolumeProjection{Secret: result}
+}
+
 // podConfigFiles returns projections of pgAdmin's configuration files to
 // include in the configuration volume.
 func podConfigFiles(configmap *corev1.ConfigMap, spec v1beta1.PGAdminPodSpec) []corev1.VolumeProjection {
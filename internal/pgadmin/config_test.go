@@ -15,6 +15,41 @@ import (
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
+func TestCertAuthority(t *testing.T) {
+	t.Run("Generated", func(t *testing.T) {
+		postgres := &corev1.SecretProjection{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "some-tls"},
+			Items: []corev1.KeyToPath{
+				{Key: "ca.crt", Path: "ca.crt"},
+				{Key: "tls.crt", Path: "tls.crt"},
+				{Key: "tls.key", Path: "tls.key"},
+			},
+		}
+
+		assert.Assert(t, cmp.MarshalMatches(certAuthority(postgres), `
+secret:
+  items:
+  - key: ca.crt
+    path: ~postgres-operator/ca.crt
+  name: some-tls
+		`))
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		postgres := &corev1.SecretProjection{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "custom-tls"},
+		}
+
+		assert.Assert(t, cmp.MarshalMatches(certAuthority(postgres), `
+secret:
+  items:
+  - key: ca.crt
+    path: ~postgres-operator/ca.crt
+  name: custom-tls
+		`))
+	})
+}
+
 func TestPodConfigFiles(t *testing.T) {
 	configmap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "some-cm"}}
 
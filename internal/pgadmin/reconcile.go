@@ -169,6 +169,7 @@ func ConfigMap(
 func Pod(
 	inCluster *v1beta1.PostgresCluster,
 	inConfigMap *corev1.ConfigMap,
+	inPostgreSQLCertificate *corev1.SecretProjection,
 	outPod *corev1.PodSpec, pgAdminVolume *corev1.PersistentVolumeClaim,
 ) {
 	if inCluster.Spec.UserInterface == nil || inCluster.Spec.UserInterface.PGAdmin == nil {
@@ -200,7 +201,9 @@ func Pod(
 	}
 	configVolume := corev1.Volume{Name: configVolumeMount.Name}
 	configVolume.Projected = &corev1.ProjectedVolumeSource{
-		Sources: podConfigFiles(inConfigMap, *inCluster.Spec.UserInterface.PGAdmin),
+		Sources: append(
+			podConfigFiles(inConfigMap, *inCluster.Spec.UserInterface.PGAdmin),
+			certAuthority(inPostgreSQLCertificate)),
 	}
 
 	startupVolumeMount := corev1.VolumeMount{
@@ -242,6 +245,15 @@ func Pod(
 				Name:  "KRB5RCACHEDIR",
 				Value: "/tmp",
 			},
+			// Server connections verify the PostgreSQL certificate using the
+			// cluster's certificate authority. pgAdmin resolves "sslrootcert"
+			// relative to each user's storage directory, so the location is
+			// given to libpq through its environment instead.
+			// - https://www.postgresql.org/docs/current/libpq-envars.html
+			{
+				Name:  "PGSSLROOTCERT",
+				Value: certAuthorityAbsolutePath,
+			},
 		},
 		Command:         []string{"bash", "-c", startupScript},
 		Image:           config.PGAdminContainerImage(inCluster),
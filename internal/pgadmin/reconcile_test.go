@@ -82,10 +82,12 @@ func TestPod(t *testing.T) {
 
 	cluster := new(v1beta1.PostgresCluster)
 	config := new(corev1.ConfigMap)
+	certificate := new(corev1.SecretProjection)
+	certificate.Name = "some-tls"
 	pod := new(corev1.PodSpec)
 	pvc := new(corev1.PersistentVolumeClaim)
 
-	call := func() { Pod(cluster, config, pod, pvc) }
+	call := func() { Pod(cluster, config, certificate, pod, pvc) }
 
 	t.Run("Disabled", func(t *testing.T) {
 		before := pod.DeepCopy()
@@ -220,6 +222,8 @@ containers:
     value: /etc/pgadmin/conf.d/krb5.conf
   - name: KRB5RCACHEDIR
     value: /tmp
+  - name: PGSSLROOTCERT
+    value: /etc/pgadmin/conf.d/~postgres-operator/ca.crt
   livenessProbe:
     initialDelaySeconds: 15
     periodSeconds: 20
@@ -304,6 +308,11 @@ volumes:
         items:
         - key: pgadmin-settings.json
           path: ~postgres-operator/pgadmin.json
+    - secret:
+        items:
+        - key: ca.crt
+          path: ~postgres-operator/ca.crt
+        name: some-tls
 - emptyDir:
     medium: Memory
     sizeLimit: 32Ki
@@ -454,6 +463,8 @@ containers:
     value: /etc/pgadmin/conf.d/krb5.conf
   - name: KRB5RCACHEDIR
     value: /tmp
+  - name: PGSSLROOTCERT
+    value: /etc/pgadmin/conf.d/~postgres-operator/ca.crt
   image: new-image
   imagePullPolicy: Always
   livenessProbe:
@@ -553,6 +564,11 @@ volumes:
         - key: podtestpw
           path: ~postgres-operator/ldap-bind-password
         name: podtest
+    - secret:
+        items:
+        - key: ca.crt
+          path: ~postgres-operator/ca.crt
+        name: some-tls
 - emptyDir:
     medium: Memory
     sizeLimit: 32Ki
@@ -34,18 +34,26 @@ type Executor func(
 
 // WriteUsersInPGAdmin uses exec and "python" to create users in pgAdmin and
 // update their passwords when they already exist. A blank password for a user
-// blocks that user from logging in to pgAdmin. The pgAdmin configuration
-// database must exist before calling this.
+// blocks that user from logging in to pgAdmin, as does removing the user from
+// users. The pgAdmin configuration database must exist before calling this.
 func WriteUsersInPGAdmin(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, exec Executor,
 	users []v1beta1.PostgresUserSpec, passwords map[string]string,
 ) error {
 	primary := naming.ClusterPrimaryService(cluster)
 
+	// Certificates generated by the operator list the primary Service. A custom
+	// certificate might not, so verify only the certificate authority of those.
+	sslmode := "verify-full"
+	if cluster.Spec.CustomTLSSecret != nil {
+		sslmode = "verify-ca"
+	}
+
 	args := []string{
 		cluster.Name,
 		primary.Name + "." + primary.Namespace + ".svc",
 		fmt.Sprint(*cluster.Spec.Port),
+		sslmode,
 	}
 	script := strings.Join([]string{
 		// Unpack arguments into an object.
@@ -55,7 +63,7 @@ import sys
 import types
 
 cluster = types.SimpleNamespace()
-(cluster.name, cluster.hostname, cluster.port) = sys.argv[1:]`,
+(cluster.name, cluster.hostname, cluster.port, cluster.sslmode) = sys.argv[1:]`,
 
 		// The location of pgAdmin files can vary by container image. Look for
 		// typical names in the module search path: the PyPI package is named
@@ -123,12 +131,14 @@ with create_app().app_context():`,
 		// model since pgAdmin v4.21.
 		// - https://github.com/pgadmin-org/pgadmin4/blob/REL-4_30/web/pgadmin/model/__init__.py#L66
 		`
+    managed = set()
     for line in sys.stdin:
         if not line.strip():
             continue
 
         data = json.loads(line)
         address = data['username'] + '@pgo'
+        managed.add(address)
         user = (
             db.session.query(User).filter_by(username=address).first() or
             User()
@@ -185,6 +195,9 @@ with create_app().app_context():`,
 		// modified. Changes to a server connection will generally persist until a
 		// change is made to the corresponding user. For custom server connections,
 		// a new server should be created with a unique name.
+		//
+		// Connections verify the server certificate against the certificate
+		// authority in PGSSLROOTCERT. See [Pod].
 		`
         server = (
             db.session.query(Server).filter_by(
@@ -201,7 +214,7 @@ with create_app().app_context():`,
         server.servergroup_id = group.id
         server.user_id = user.id
         server.maintenance_db = "postgres"
-        server.ssl_mode = "prefer"`,
+        server.ssl_mode = cluster.sslmode`,
 
 		// Encrypt the Server password with the User's plaintext password.
 		// - https://github.com/pgadmin-org/pgadmin4/blob/REL-4_30/web/pgadmin/__init__.py#L601
@@ -236,6 +249,22 @@ with create_app().app_context():`,
 
         db.session.add(server)
         db.session.commit()`,
+
+		// Users that were created above but are no longer in the spec should
+		// not be able to log in. Deactivate them and clear their passwords the
+		// same way as the initial user. Their server connections remain so
+		// that nothing in pgAdmin is lost should they be added again.
+		`
+    for user in db.session.query(User).filter(
+        User.auth_source == INTERNAL,
+        User.username.endswith('@pgo'),
+    ):
+        if user.username not in managed:
+            user.active = False
+            user.password = ''
+            db.session.add(user)
+
+    db.session.commit()`,
 	}, "\n") + "\n"
 
 	var err error
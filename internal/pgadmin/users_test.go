@@ -26,6 +26,7 @@ import (
 	"testing"
 
 	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/crunchydata/postgres-operator/internal/initialize"
@@ -62,7 +63,7 @@ import sys
 import types
 
 cluster = types.SimpleNamespace()
-(cluster.name, cluster.hostname, cluster.port) = sys.argv[1:]
+(cluster.name, cluster.hostname, cluster.port, cluster.sslmode) = sys.argv[1:]
 
 import importlib.util
 import os
@@ -96,12 +97,14 @@ with create_app().app_context():
     db.session.add(admin)
     db.session.commit()
 
+    managed = set()
     for line in sys.stdin:
         if not line.strip():
             continue
 
         data = json.loads(line)
         address = data['username'] + '@pgo'
+        managed.add(address)
         user = (
             db.session.query(User).filter_by(username=address).first() or
             User()
@@ -145,7 +148,7 @@ with create_app().app_context():
         server.servergroup_id = group.id
         server.user_id = user.id
         server.maintenance_db = "postgres"
-        server.ssl_mode = "prefer"
+        server.ssl_mode = cluster.sslmode
 
         server.username = data['username']
         server.password = encrypt(data['password'], data['password'])
@@ -159,10 +162,22 @@ with create_app().app_context():
 
         db.session.add(server)
         db.session.commit()
+
+    for user in db.session.query(User).filter(
+        User.auth_source == INTERNAL,
+        User.username.endswith('@pgo'),
+    ):
+        if user.username not in managed:
+            user.active = False
+            user.password = ''
+            db.session.add(user)
+
+    db.session.commit()
 `,
 				"testcluster",
 				"testcluster-primary.testnamespace.svc",
 				"5432",
+				"verify-full",
 			})
 			return expected
 		}
@@ -170,6 +185,25 @@ with create_app().app_context():
 		assert.Equal(t, expected, WriteUsersInPGAdmin(ctx, cluster, exec, nil, nil))
 	})
 
+	t.Run("CustomTLSSecret", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.CustomTLSSecret = new(corev1.SecretProjection)
+
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			calls++
+
+			// A custom certificate might not list the Service.
+			assert.Equal(t, command[len(command)-1], "verify-ca")
+			return nil
+		}
+
+		assert.NilError(t, WriteUsersInPGAdmin(ctx, cluster, exec, nil, nil))
+		assert.Equal(t, calls, 1)
+	})
+
 	t.Run("Flake8", func(t *testing.T) {
 		flake8 := require.Flake8(t)
 
@@ -21,6 +21,7 @@ import (
 
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // When the pgAudit shared library is not loaded, the extension cannot be
@@ -71,3 +72,42 @@ func PostgreSQLParameters(outParameters *postgres.Parameters) {
 	outParameters.Mandatory.Add("shared_preload_libraries",
 		strings.TrimPrefix(shared+",pgaudit", ","))
 }
+
+// Settings populates outParameters with the audit settings of inCluster, if
+// any. PostgreSQL must be reloaded when changing these values.
+// - https://github.com/pgaudit/pgaudit#settings
+func Settings(inCluster *v1beta1.PostgresCluster, outParameters *postgres.Parameters) {
+	audit := inCluster.Spec.Audit
+	if audit == nil {
+		return
+	}
+
+	onOff := func(b bool) string {
+		if b {
+			return "on"
+		}
+		return "off"
+	}
+
+	classes := make([]string, 0, len(audit.Log))
+	for _, class := range audit.Log {
+		classes = append(classes, string(class))
+	}
+	if len(classes) == 0 {
+		classes = append(classes, "none")
+	}
+	outParameters.Mandatory.Add("pgaudit.log", strings.Join(classes, ","))
+
+	if audit.Role != "" {
+		outParameters.Mandatory.Add("pgaudit.role", string(audit.Role))
+	}
+	if audit.LogParameter != nil {
+		outParameters.Mandatory.Add("pgaudit.log_parameter", onOff(*audit.LogParameter))
+	}
+	if audit.LogRelation != nil {
+		outParameters.Mandatory.Add("pgaudit.log_relation", onOff(*audit.LogRelation))
+	}
+	if audit.LogCatalog != nil {
+		outParameters.Mandatory.Add("pgaudit.log_catalog", onOff(*audit.LogCatalog))
+	}
+}
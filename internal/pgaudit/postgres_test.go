@@ -24,7 +24,9 @@ import (
 
 	"gotest.tools/v3/assert"
 
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestEnableInPostgreSQL(t *testing.T) {
@@ -74,3 +76,34 @@ func TestPostgreSQLParameters(t *testing.T) {
 		"shared_preload_libraries": "some,existing,pgaudit",
 	})
 }
+
+func TestSettings(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	parameters := postgres.Parameters{
+		Mandatory: postgres.NewParameterSet(),
+	}
+
+	Settings(cluster, &parameters)
+	assert.Assert(t, parameters.Default == nil)
+	assert.DeepEqual(t, parameters.Mandatory.AsMap(), map[string]string{})
+
+	cluster.Spec.Audit = new(v1beta1.PGAuditSpec)
+	Settings(cluster, &parameters)
+	assert.DeepEqual(t, parameters.Mandatory.AsMap(), map[string]string{
+		"pgaudit.log": "none",
+	})
+
+	cluster.Spec.Audit = &v1beta1.PGAuditSpec{
+		Log:          []v1beta1.PGAuditLogClass{"DDL", "ROLE"},
+		Role:         "auditor",
+		LogParameter: initialize.Bool(true),
+		LogCatalog:   initialize.Bool(false),
+	}
+	Settings(cluster, &parameters)
+	assert.DeepEqual(t, parameters.Mandatory.AsMap(), map[string]string{
+		"pgaudit.log":           "DDL,ROLE",
+		"pgaudit.log_catalog":   "off",
+		"pgaudit.log_parameter": "on",
+		"pgaudit.role":          "auditor",
+	})
+}
@@ -108,6 +108,7 @@ func CreatePGBackRestConfigMapIntent(postgresCluster *v1beta1.PostgresCluster,
 		populatePGInstanceConfigurationMap(
 			serviceName, serviceNamespace, repoHostName,
 			pgdataDir, pgPort, postgresCluster.Spec.Backups.PGBackRest.Repos,
+			postgresCluster.Spec.Backups.PGBackRest.ArchiveAsync,
 			postgresCluster.Spec.Backups.PGBackRest.Global,
 		).String()
 
@@ -205,7 +206,28 @@ func RestoreCommand(pgdata string, args ...string) []string {
 	// The 'pg_ctl' timeout is set to a very large value (1 year) to ensure there
 	// are no timeouts when starting or stopping Postgres.
 
-	const restoreScript = `declare -r pgdata="$1" opts="$2"
+	const restoreScript = recoverScript + `
+pg_ctl stop --silent --wait --timeout=31536000
+mv "${pgdata}" "${pgdata}_bootstrap"`
+
+	return append([]string{"bash", "-ceu", "--", restoreScript, "-", pgdata}, args...)
+}
+
+// RestoreDrillCommand returns the command for a restore drill. It restores
+// files with pgBackRest and waits for recovery to finish like [RestoreCommand].
+// Then it runs sql as the "postgres" user, stopping at the first error, and
+// stops PostgreSQL. WAL is never archived because the archive_command fails.
+func RestoreDrillCommand(pgdata, opts, sql string) []string {
+	const drillScript = recoverScript + `
+psql -Xw --set=ON_ERROR_STOP=1 --file=- <<< "$3"
+pg_ctl stop --silent --wait --timeout=31536000`
+
+	return []string{"bash", "-ceu", "--", drillScript, "-", pgdata, opts, sql}
+}
+
+// recoverScript restores files with pgBackRest then starts PostgreSQL and
+// waits for recovery to finish. See [RestoreCommand].
+const recoverScript = `declare -r pgdata="$1" opts="$2"
 install --directory --mode=0700 "${pgdata}"
 rm -f "${pgdata}/postmaster.pid"
 bash -xc "pgbackrest restore ${opts}"
@@ -244,18 +266,14 @@ recovery=$(psql -Atc "SELECT CASE
   ELSE pg_catalog.pg_wal_replay_resume()::text = ''
 END recovery" && sleep 1) || true
 done
-
-pg_ctl stop --silent --wait --timeout=31536000
-mv "${pgdata}" "${pgdata}_bootstrap"`
-
-	return append([]string{"bash", "-ceu", "--", restoreScript, "-", pgdata}, args...)
-}
+`
 
 // populatePGInstanceConfigurationMap returns options representing the pgBackRest configuration for
 // a PostgreSQL instance
 func populatePGInstanceConfigurationMap(
 	serviceName, serviceNamespace, repoHostName, pgdataDir string,
 	pgPort int32, repos []v1beta1.PGBackRestRepo,
+	archiveAsync *v1beta1.PGBackRestArchiveAsync,
 	globalConfig map[string]string,
 ) iniSectionSet {
 
@@ -266,13 +284,41 @@ func populatePGInstanceConfigurationMap(
 
 	global := iniMultiSet{}
 	stanza := iniMultiSet{}
+	sections := iniSectionSet{
+		"global":          global,
+		DefaultStanzaName: stanza,
+	}
+
+	// Options from the spec can change the defaults above but not the options below.
+	for _, repo := range repos {
+		global.Set(repo.Name+"-path", defaultRepo1Path+repo.Name)
+	}
+	setCustomOptions(global, repos, globalConfig)
 
 	// pgBackRest will log to the pgData volume for commands run on the PostgreSQL instance
 	global.Set("log-path", naming.PGBackRestPGDataLogPath)
 
-	for _, repo := range repos {
-		global.Set(repo.Name+"-path", defaultRepo1Path+repo.Name)
+	// Queue WAL files on the pgData volume, too. Only the archive commands
+	// use more processes so backups and restores are unaffected.
+	// - https://pgbackrest.org/user-guide.html#async-archiving
+	if archiveAsync != nil {
+		global.Set("archive-async", "y")
+		global.Set("spool-path", naming.PGBackRestPGDataSpoolPath)
 
+		if archiveAsync.PushQueueMax != nil {
+			global.Set("archive-push-queue-max", fmt.Sprint(archiveAsync.PushQueueMax.Value()))
+		}
+		if archiveAsync.GetQueueMax != nil {
+			global.Set("archive-get-queue-max", fmt.Sprint(archiveAsync.GetQueueMax.Value()))
+		}
+		if archiveAsync.ProcessMax != nil {
+			processes := fmt.Sprint(*archiveAsync.ProcessMax)
+			sections["global:archive-get"] = iniMultiSet{"process-max": {processes}}
+			sections["global:archive-push"] = iniMultiSet{"process-max": {processes}}
+		}
+	}
+
+	for _, repo := range repos {
 		// repo volumes do not contain configuration (unlike other repo types which has actual
 		// pgBackRest settings such as "bucket", "region", etc.), so only grab the name from the
 		// repo if a Volume is detected, and don't attempt to get an configs
@@ -282,6 +328,10 @@ func populatePGInstanceConfigurationMap(
 			}
 		}
 
+		if repo.Encryption != nil {
+			global.Set(repo.Name+"-cipher-type", repoCipherType(repo))
+		}
+
 		// Only "volume" (i.e. PVC-based) repos should ever have a repo host configured.  This
 		// means cloud-based repos (S3, GCS or Azure) should not have a repo host configured.
 		if repoHostName != "" && repo.Volume != nil {
@@ -294,20 +344,13 @@ func populatePGInstanceConfigurationMap(
 		}
 	}
 
-	for option, val := range globalConfig {
-		global.Set(option, val)
-	}
-
 	// Now add the local PG instance to the stanza section. The local PG host must always be
 	// index 1: https://github.com/pgbackrest/pgbackrest/issues/1197#issuecomment-708381800
 	stanza.Set("pg1-path", pgdataDir)
 	stanza.Set("pg1-port", fmt.Sprint(pgPort))
 	stanza.Set("pg1-socket-path", postgres.SocketDirectory)
 
-	return iniSectionSet{
-		"global":          global,
-		DefaultStanzaName: stanza,
-	}
+	return sections
 }
 
 // populateRepoHostConfigurationMap returns options representing the pgBackRest configuration for
@@ -321,10 +364,14 @@ func populateRepoHostConfigurationMap(
 	global := iniMultiSet{}
 	stanza := iniMultiSet{}
 
-	var pgBackRestLogPathSet bool
+	// Options from the spec can change the defaults above but not the options below.
 	for _, repo := range repos {
 		global.Set(repo.Name+"-path", defaultRepo1Path+repo.Name)
+	}
+	setCustomOptions(global, repos, globalConfig)
 
+	var pgBackRestLogPathSet bool
+	for _, repo := range repos {
 		// repo volumes do not contain configuration (unlike other repo types which has actual
 		// pgBackRest settings such as "bucket", "region", etc.), so only grab the name from the
 		// repo if a Volume is detected, and don't attempt to get an configs
@@ -334,6 +381,10 @@ func populateRepoHostConfigurationMap(
 			}
 		}
 
+		if repo.Encryption != nil {
+			global.Set(repo.Name+"-cipher-type", repoCipherType(repo))
+		}
+
 		if !pgBackRestLogPathSet && repo.Volume != nil {
 			// pgBackRest will log to the first configured repo volume when commands
 			// are run on the pgBackRest repo host. With our previous check in
@@ -344,10 +395,6 @@ func populateRepoHostConfigurationMap(
 		}
 	}
 
-	for option, val := range globalConfig {
-		global.Set(option, val)
-	}
-
 	// set the configs for all PG hosts
 	for i, pgHost := range pgHosts {
 		// TODO(cbandy): pass a FQDN in already.
@@ -372,6 +419,21 @@ func populateRepoHostConfigurationMap(
 	}
 }
 
+// setCustomOptions sets the global options from the spec followed by the options of each repo.
+// The options of a repo take precedence over global options with the same name.
+func setCustomOptions(
+	global iniMultiSet, repos []v1beta1.PGBackRestRepo, globalConfig map[string]string,
+) {
+	for option, val := range globalConfig {
+		global.Set(option, val)
+	}
+	for _, repo := range repos {
+		for option, val := range repo.Options {
+			global.Set(repo.Name+"-"+option, val)
+		}
+	}
+}
+
 // getExternalRepoConfigs returns a map containing the configuration settings for an external
 // pgBackRest repository as defined in the PostgresCluster spec
 func getExternalRepoConfigs(repo v1beta1.PGBackRestRepo) map[string]string {
@@ -394,6 +456,14 @@ func getExternalRepoConfigs(repo v1beta1.PGBackRestRepo) map[string]string {
 	return repoConfigs
 }
 
+// repoCipherType returns the pgBackRest cipher of an encrypted repo.
+func repoCipherType(repo v1beta1.PGBackRestRepo) string {
+	if repo.Encryption.CipherType != "" {
+		return repo.Encryption.CipherType
+	}
+	return "aes-256-cbc"
+}
+
 // reloadCommand returns an entrypoint that convinces the pgBackRest TLS server
 // to reload its options and certificate files when they change. The process
 // will appear as name in `ps` and `top`.
@@ -476,6 +546,14 @@ func serverConfig(cluster *v1beta1.PostgresCluster) iniSectionSet {
 		global.Set("tls-server-address", "::")
 	}
 
+	// The same is true when IPv6 is the preferred family of the cluster's
+	// Service. Pod DNS names resolve to IPv6 addresses first in that case, and
+	// an IPv4 listener would be unreachable in IPv6-only clusters.
+	if spec := cluster.Spec.Service; spec != nil &&
+		len(spec.IPFamilies) > 0 && spec.IPFamilies[0] == corev1.IPv6Protocol {
+		global.Set("tls-server-address", "::")
+	}
+
 	// The client certificate for this cluster is allowed to connect for any stanza.
 	// Without the wildcard "*", the "pgbackrest info" and "pgbackrest repo-ls"
 	// commands fail with "access denied" when invoked without a "--stanza" flag.
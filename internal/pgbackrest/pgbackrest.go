@@ -18,6 +18,7 @@ package pgbackrest
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -98,3 +99,72 @@ fi
 
 	return false, nil
 }
+
+// InfoStanza is the part of the "pgbackrest info --output=json" description of a stanza that
+// the operator uses.
+// - https://pgbackrest.org/command.html#command-info
+type InfoStanza struct {
+	Name    string        `json:"name"`
+	Archive []InfoArchive `json:"archive"`
+	Backup  []InfoBackup  `json:"backup"`
+}
+
+// InfoArchive describes the range of WAL archived in one repository for one database.
+type InfoArchive struct {
+	Database InfoDatabase `json:"database"`
+	Min      string       `json:"min"`
+	Max      string       `json:"max"`
+}
+
+// InfoBackup describes one backup in one repository.
+type InfoBackup struct {
+	Database InfoDatabase `json:"database"`
+	Error    bool         `json:"error"`
+	Label    string       `json:"label"`
+	Type     string       `json:"type"`
+
+	Archive struct {
+		Start string `json:"start"`
+		Stop  string `json:"stop"`
+	} `json:"archive"`
+
+	Info struct {
+		Size       int64 `json:"size"`
+		Repository struct {
+			Size int64 `json:"size"`
+		} `json:"repository"`
+	} `json:"info"`
+
+	Timestamp struct {
+		Start int64 `json:"start"`
+		Stop  int64 `json:"stop"`
+	} `json:"timestamp"`
+}
+
+// InfoDatabase identifies the repository and database of an archive or backup.
+type InfoDatabase struct {
+	ID      int `json:"id"`
+	RepoKey int `json:"repo-key"`
+}
+
+// Info runs the pgBackRest "info" command and returns its description of the stanza that the
+// operator creates. Archives and backups are in the order pgBackRest reports them, oldest first.
+func (exec Executor) Info(ctx context.Context) (*InfoStanza, error) {
+	var stdout, stderr bytes.Buffer
+
+	if err := exec(ctx, nil, &stdout, &stderr, "pgbackrest", "info",
+		"--stanza="+DefaultStanzaName, "--output=json"); err != nil {
+		return nil, errors.WithStack(fmt.Errorf("%w: %v", err, stderr.String()))
+	}
+
+	var stanzas []InfoStanza
+	if err := json.Unmarshal(stdout.Bytes(), &stanzas); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	for i := range stanzas {
+		if stanzas[i].Name == DefaultStanzaName {
+			return &stanzas[i], nil
+		}
+	}
+	return nil, errors.Errorf("stanza %q not found", DefaultStanzaName)
+}
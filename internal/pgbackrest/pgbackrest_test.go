@@ -17,6 +17,7 @@ package pgbackrest
 
 import (
 	"context"
+	"errors"
 	"io"
 	"os"
 	"os/exec"
@@ -72,3 +73,73 @@ fi
 	output, err := cmd.CombinedOutput()
 	assert.NilError(t, err, "%q\n%s", cmd.Args, output)
 }
+
+func TestInfo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Parse", func(t *testing.T) {
+		exec := func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer,
+			command ...string) error {
+			assert.DeepEqual(t, command,
+				[]string{"pgbackrest", "info", "--stanza=db", "--output=json"})
+
+			_, err := io.WriteString(stdout, `[{
+				"archive": [{
+					"database": {"id": 1, "repo-key": 1},
+					"id": "14-1",
+					"max": "000000010000000000000005",
+					"min": "000000010000000000000001"
+				}],
+				"backup": [{
+					"archive": {"start": "000000010000000000000002", "stop": "000000010000000000000002"},
+					"database": {"id": 1, "repo-key": 1},
+					"error": false,
+					"info": {"delta": 100, "repository": {"delta": 20, "size": 20}, "size": 100},
+					"label": "20220701-000000F",
+					"timestamp": {"start": 1656633600, "stop": 1656633660},
+					"type": "full"
+				}],
+				"name": "db",
+				"status": {"code": 0, "message": "ok"}
+			}]`)
+			return err
+		}
+
+		info, err := Executor(exec).Info(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, info.Name, "db")
+		assert.Equal(t, len(info.Archive), 1)
+		assert.Equal(t, info.Archive[0].Database.RepoKey, 1)
+		assert.Equal(t, info.Archive[0].Min, "000000010000000000000001")
+		assert.Equal(t, info.Archive[0].Max, "000000010000000000000005")
+		assert.Equal(t, len(info.Backup), 1)
+		assert.Equal(t, info.Backup[0].Label, "20220701-000000F")
+		assert.Equal(t, info.Backup[0].Type, "full")
+		assert.Equal(t, info.Backup[0].Info.Size, int64(100))
+		assert.Equal(t, info.Backup[0].Info.Repository.Size, int64(20))
+		assert.Equal(t, info.Backup[0].Timestamp.Stop, int64(1656633660))
+		assert.Equal(t, info.Backup[0].Archive.Stop, "000000010000000000000002")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		exec := func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer,
+			command ...string) error {
+			_, _ = io.WriteString(stderr, "some problem")
+			return errors.New("exit status 1")
+		}
+
+		_, err := Executor(exec).Info(ctx)
+		assert.ErrorContains(t, err, "some problem")
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		exec := func(ctx context.Context, stdin io.Reader, stdout, stderr io.Writer,
+			command ...string) error {
+			_, err := io.WriteString(stdout, `[]`)
+			return err
+		}
+
+		_, err := Executor(exec).Info(ctx)
+		assert.ErrorContains(t, err, "not found")
+	})
+}
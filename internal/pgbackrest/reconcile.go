@@ -144,7 +144,8 @@ func AddConfigToInstancePod(
 		sources = append(sources, configmap)
 	}
 
-	addConfigVolumeAndMounts(pod, sources)
+	addConfigVolumeAndMounts(pod, sources,
+		repoCipherEnvironment(cluster.Spec.Backups.PGBackRest.Repos))
 }
 
 // AddConfigToRepoPod adds and mounts the pgBackRest configuration volume for
@@ -171,7 +172,8 @@ func AddConfigToRepoPod(
 	sources := append([]corev1.VolumeProjection{},
 		cluster.Spec.Backups.PGBackRest.Configuration...)
 
-	addConfigVolumeAndMounts(pod, append(sources, configmap, secret))
+	addConfigVolumeAndMounts(pod, append(sources, configmap, secret),
+		repoCipherEnvironment(cluster.Spec.Backups.PGBackRest.Repos))
 }
 
 // AddConfigToRestorePod adds and mounts the pgBackRest configuration volume
@@ -204,27 +206,61 @@ func AddConfigToRestorePod(
 	// - https://kubernetes.io/docs/concepts/storage/volumes/#projected
 	sources := append([]corev1.VolumeProjection{},
 		cluster.Spec.Backups.PGBackRest.Configuration...)
+	repos := append([]v1beta1.PGBackRestRepo{},
+		cluster.Spec.Backups.PGBackRest.Repos...)
 
 	if cluster.Spec.DataSource != nil &&
-		cluster.Spec.DataSource.PGBackRest != nil &&
-		cluster.Spec.DataSource.PGBackRest.Configuration != nil {
+		cluster.Spec.DataSource.PGBackRest != nil {
 		sources = append(sources, cluster.Spec.DataSource.PGBackRest.Configuration...)
+		repos = append(repos, cluster.Spec.DataSource.PGBackRest.Repo)
 	}
 
 	// For a PostgresCluster restore, append all pgBackRest configuration from
 	// the source cluster for the restore
 	if sourceCluster != nil {
 		sources = append(sources, sourceCluster.Spec.Backups.PGBackRest.Configuration...)
+		repos = append(repos, sourceCluster.Spec.Backups.PGBackRest.Repos...)
 	}
 
-	addConfigVolumeAndMounts(pod, append(sources, configmap, secret))
+	addConfigVolumeAndMounts(pod, append(sources, configmap, secret),
+		repoCipherEnvironment(repos))
+}
+
+// repoCipherEnvironment returns environment variables that pass the cipher
+// passphrase of every encrypted repo to pgBackRest. Repos later in the list
+// take precedence over earlier repos of the same name.
+// - https://pgbackrest.org/command.html#introduction
+func repoCipherEnvironment(repos []v1beta1.PGBackRestRepo) []corev1.EnvVar {
+	var variables []corev1.EnvVar
+	index := make(map[string]int)
+
+	for _, repo := range repos {
+		if repo.Encryption == nil {
+			continue
+		}
+
+		variable := corev1.EnvVar{
+			Name: "PGBACKREST_" + strings.ToUpper(repo.Name) + "_CIPHER_PASS",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: repo.Encryption.Passphrase.DeepCopy(),
+			},
+		}
+
+		if i, ok := index[variable.Name]; ok {
+			variables[i] = variable
+		} else {
+			index[variable.Name] = len(variables)
+			variables = append(variables, variable)
+		}
+	}
+	return variables
 }
 
 // addConfigVolumeAndMounts adds the config projections to pod as the
 // configuration volume. It mounts that volume to the database container and
-// all pgBackRest containers in pod.
+// all pgBackRest containers in pod and adds env to their environment.
 func addConfigVolumeAndMounts(
-	pod *corev1.PodSpec, config []corev1.VolumeProjection,
+	pod *corev1.PodSpec, config []corev1.VolumeProjection, env []corev1.EnvVar,
 ) {
 	configVolumeMount := corev1.VolumeMount{
 		Name:      "pgbackrest-config",
@@ -250,6 +286,7 @@ func addConfigVolumeAndMounts(
 			naming.PGBackRestRestoreContainerName:
 
 			container.VolumeMounts = append(container.VolumeMounts, configVolumeMount)
+			container.Env = append(container.Env, env...)
 		}
 	}
 
@@ -257,7 +294,8 @@ func addConfigVolumeAndMounts(
 }
 
 // addServerContainerAndVolume adds the TLS server container and certificate
-// projections to pod. Any PostgreSQL data and WAL volumes in pod are also mounted.
+// projections to pod. Any PostgreSQL data, WAL, and tablespace volumes in pod
+// are also mounted.
 func addServerContainerAndVolume(
 	cluster *v1beta1.PostgresCluster, pod *corev1.PodSpec,
 	certificates []corev1.VolumeProjection, resources *corev1.ResourceRequirements,
@@ -297,14 +335,20 @@ func addServerContainerAndVolume(
 		container.Resources = *resources
 	}
 
-	// Mount PostgreSQL volumes that are present in pod.
+	// Mount PostgreSQL volumes that are present in pod. Tablespaces are
+	// backed up and restored through their links in PGDATA, so mount them too.
 	postgresMounts := map[string]corev1.VolumeMount{
 		postgres.DataVolumeMount().Name: postgres.DataVolumeMount(),
 		postgres.WALVolumeMount().Name:  postgres.WALVolumeMount(),
 	}
+	tablespacePrefix := postgres.TablespaceVolumeMount("").Name
 	for i := range pod.Volumes {
-		if mount, ok := postgresMounts[pod.Volumes[i].Name]; ok {
+		name := pod.Volumes[i].Name
+		if mount, ok := postgresMounts[name]; ok {
 			container.VolumeMounts = append(container.VolumeMounts, mount)
+		} else if strings.HasPrefix(name, tablespacePrefix) {
+			container.VolumeMounts = append(container.VolumeMounts,
+				postgres.TablespaceVolumeMount(strings.TrimPrefix(name, tablespacePrefix)))
 		}
 	}
 
@@ -484,6 +528,9 @@ func Secret(ctx context.Context,
 	inSecret *corev1.Secret,
 	outSecret *corev1.Secret,
 ) error {
+	ctx, span := tracer.Start(ctx, "pgbackrest-secret")
+	defer span.End()
+
 	var err error
 
 	// Save the CA and generate a TLS client certificate for the entire cluster.
@@ -548,5 +595,6 @@ func Secret(ctx context.Context,
 		}
 	}
 
+	span.RecordError(err)
 	return err
 }
@@ -647,6 +647,31 @@ func TestAddServerToInstancePod(t *testing.T) {
         name: instance-secret-name
 		`))
 	})
+
+	t.Run("Tablespaces", func(t *testing.T) {
+		out := pod.DeepCopy()
+		out.Volumes = append(out.Volumes, corev1.Volume{Name: "tablespace-trial"})
+		AddServerToInstancePod(&cluster, out, "instance-secret-name")
+
+		// The TLS server mounts tablespace volumes where PostgreSQL does.
+		var mounts []corev1.VolumeMount
+		for _, container := range out.Containers {
+			if container.Name == "pgbackrest" {
+				mounts = container.VolumeMounts
+			}
+		}
+		assert.Assert(t, marshalMatches(mounts, `
+- mountPath: /etc/pgbackrest/server
+  name: pgbackrest-server
+  readOnly: true
+- mountPath: /pgdata
+  name: postgres-data
+- mountPath: /pgwal
+  name: postgres-wal
+- mountPath: /tablespaces/trial
+  name: tablespace-trial
+		`))
+	})
 }
 
 func TestAddServerToRepoPod(t *testing.T) {
@@ -892,3 +917,56 @@ func TestSecret(t *testing.T) {
 		assert.Assert(t, !reflect.DeepEqual(leaf.PrivateKey, leaf2.PrivateKey))
 	})
 }
+
+func TestRepoCipherEnvironment(t *testing.T) {
+	assert.Assert(t, repoCipherEnvironment(nil) == nil)
+
+	passphrase := func(name string) *v1beta1.PGBackRestRepoEncryption {
+		encryption := &v1beta1.PGBackRestRepoEncryption{}
+		encryption.Passphrase.Name = name
+		encryption.Passphrase.Key = "passphrase"
+		return encryption
+	}
+
+	assert.Assert(t, marshalMatches(repoCipherEnvironment([]v1beta1.PGBackRestRepo{
+		{Name: "repo1", Encryption: passphrase("first")},
+		{Name: "repo2"},
+		{Name: "repo3", Encryption: passphrase("third")},
+		{Name: "repo1", Encryption: passphrase("source")},
+	}), `
+- name: PGBACKREST_REPO1_CIPHER_PASS
+  valueFrom:
+    secretKeyRef:
+      key: passphrase
+      name: source
+- name: PGBACKREST_REPO3_CIPHER_PASS
+  valueFrom:
+    secretKeyRef:
+      key: passphrase
+      name: third
+	`))
+
+	t.Run("Pods", func(t *testing.T) {
+		cluster := v1beta1.PostgresCluster{}
+		cluster.Name = "hippo"
+		cluster.Default()
+		cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{
+			{Name: "repo1", Encryption: passphrase("secret")},
+		}
+
+		for _, add := range []func(*corev1.PodSpec){
+			func(pod *corev1.PodSpec) { AddConfigToInstancePod(&cluster, pod) },
+			func(pod *corev1.PodSpec) { AddConfigToRepoPod(&cluster, pod) },
+			func(pod *corev1.PodSpec) { AddConfigToRestorePod(&cluster, nil, pod) },
+		} {
+			pod := &corev1.PodSpec{Containers: []corev1.Container{
+				{Name: "other"}, {Name: "database"}, {Name: "pgbackrest"},
+			}}
+			add(pod)
+
+			assert.Assert(t, len(pod.Containers[0].Env) == 0)
+			assert.Equal(t, pod.Containers[1].Env[0].Name, "PGBACKREST_REPO1_CIPHER_PASS")
+			assert.Equal(t, pod.Containers[2].Env[0].Name, "PGBACKREST_REPO1_CIPHER_PASS")
+		}
+	})
+}
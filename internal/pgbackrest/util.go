@@ -23,6 +23,7 @@ import (
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/rand"
 
+	"github.com/crunchydata/postgres-operator/internal/walg"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
@@ -31,8 +32,12 @@ import (
 const maxPGBackrestRepos = 4
 
 // DedicatedRepoHostEnabled determines whether not a pgBackRest dedicated repository host is
-// enabled according to the provided PostgresCluster
+// enabled according to the provided PostgresCluster. There is no repository host while WAL-G
+// archives WAL.
 func DedicatedRepoHostEnabled(postgresCluster *v1beta1.PostgresCluster) bool {
+	if walg.Enabled(postgresCluster) {
+		return false
+	}
 	for _, repo := range postgresCluster.Spec.Backups.PGBackRest.Repos {
 		if repo.Volume != nil {
 			return true
@@ -132,3 +132,24 @@ func TestCalculateConfigHashes(t *testing.T) {
 		assert.Assert(t, hashMap[repo] != configHashMap[repo])
 	}
 }
+
+func TestDedicatedRepoHostEnabled(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	assert.Assert(t, !DedicatedRepoHostEnabled(cluster))
+
+	cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{{
+		Name: "repo1",
+		S3:   &v1beta1.RepoS3{},
+	}}
+	assert.Assert(t, !DedicatedRepoHostEnabled(cluster), "expected no host for cloud repos")
+
+	cluster.Spec.Backups.PGBackRest.Repos = append(
+		cluster.Spec.Backups.PGBackRest.Repos, v1beta1.PGBackRestRepo{
+			Name:   "repo2",
+			Volume: &v1beta1.RepoPVC{},
+		})
+	assert.Assert(t, DedicatedRepoHostEnabled(cluster), "expected a host for volume repos")
+
+	cluster.Spec.Backups.WALG = &v1beta1.WALGArchive{}
+	assert.Assert(t, !DedicatedRepoHostEnabled(cluster), "expected no host with WAL-G")
+}
@@ -135,6 +135,21 @@ func clusterINI(cluster *v1beta1.PostgresCluster) string {
 		"unix_socket_dir": "",
 	}
 
+	// Apply any pooling settings.
+	pooling := cluster.Spec.Proxy.PGBouncer.Pooling
+	if pooling == nil {
+		pooling = new(v1beta1.PGBouncerPoolingSpec)
+	}
+	if pooling.Mode != "" {
+		global["pool_mode"] = pooling.Mode
+	}
+	if pooling.DefaultPoolSize != nil {
+		global["default_pool_size"] = fmt.Sprint(*pooling.DefaultPoolSize)
+	}
+	if pooling.MinPoolSize != nil {
+		global["min_pool_size"] = fmt.Sprint(*pooling.MinPoolSize)
+	}
+
 	// Override the above with any specified settings.
 	for k, v := range cluster.Spec.Proxy.PGBouncer.Config.Global {
 		global[k] = v
@@ -161,7 +176,41 @@ func clusterINI(cluster *v1beta1.PostgresCluster) string {
 
 	// Replace the above with any specified databases.
 	if len(cluster.Spec.Proxy.PGBouncer.Config.Databases) > 0 {
-		databases = iniValueSet(cluster.Spec.Proxy.PGBouncer.Config.Databases)
+		databases = iniValueSet{}
+		for k, v := range cluster.Spec.Proxy.PGBouncer.Config.Databases {
+			databases[k] = v
+		}
+	}
+
+	// Append pooling settings to particular databases. Those not otherwise
+	// defined connect to the primary service or the replica service.
+	for _, pool := range pooling.Databases {
+		connection, ok := databases[pool.Name]
+		if !ok {
+			host := naming.ClusterPrimaryService(cluster).Name
+			if pool.Target == "replicas" {
+				host = naming.ClusterReplicaService(cluster).Name
+			}
+			connection = fmt.Sprintf("host=%s port=%d", host, postgresPort)
+
+			// Database names can contain spaces and quotes. PgBouncer reads
+			// values in single quotes where two single quotes are one.
+			// - https://www.pgbouncer.org/config.html#section-databases
+			if pool.Database != "" {
+				connection += " dbname='" +
+					strings.ReplaceAll(pool.Database, `'`, `''`) + "'"
+			}
+		}
+		if pool.Mode != "" {
+			connection += " pool_mode=" + pool.Mode
+		}
+		if pool.PoolSize != nil {
+			connection += fmt.Sprintf(" pool_size=%d", *pool.PoolSize)
+		}
+		if pool.MinPoolSize != nil {
+			connection += fmt.Sprintf(" min_pool_size=%d", *pool.MinPoolSize)
+		}
+		databases[pool.Name] = connection
 	}
 
 	users := iniValueSet(cluster.Spec.Proxy.PGBouncer.Config.Users)
@@ -27,6 +27,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
@@ -139,6 +140,44 @@ app = mode=rad
 		cluster.Spec.Proxy.PGBouncer.Config.Global["conffile"] = "too-far"
 		assert.Assert(t, !strings.Contains(clusterINI(cluster), "too-far"))
 	})
+
+	t.Run("Pooling", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Proxy.PGBouncer.Config = v1beta1.PGBouncerConfiguration{
+			Global:    map[string]string{"min_pool_size": "3"},
+			Databases: map[string]string{"appdb": "host=elsewhere"},
+		}
+		cluster.Spec.Proxy.PGBouncer.Pooling = &v1beta1.PGBouncerPoolingSpec{
+			Mode:            "transaction",
+			DefaultPoolSize: initialize.Int32(20),
+			MinPoolSize:     initialize.Int32(5),
+			Databases: []v1beta1.PGBouncerDatabasePoolSpec{
+				{Name: "appdb", PoolSize: initialize.Int32(40)},
+				{Name: "reports", Mode: "session", MinPoolSize: initialize.Int32(1)},
+				{Name: "reports_ro", Database: "reports", Target: "replicas"},
+				{Name: "spaces", Database: "it's a db", Target: "replicas"},
+			},
+		}
+
+		ini := clusterINI(cluster)
+		assert.Assert(t, strings.Contains(ini, "\ndefault_pool_size = 20\n"))
+		assert.Assert(t, strings.Contains(ini, "\npool_mode = transaction\n"))
+
+		// Global settings take precedence.
+		assert.Assert(t, strings.Contains(ini, "\nmin_pool_size = 3\n"))
+
+		assert.Assert(t, strings.HasSuffix(ini, `
+[databases]
+appdb = host=elsewhere pool_size=40
+reports = host=foo-baz-primary port=9999 pool_mode=session min_pool_size=1
+reports_ro = host=foo-baz-replicas port=9999 dbname='reports'
+spaces = host=foo-baz-replicas port=9999 dbname='it''s a db'
+`))
+
+		// The specified databases are not changed.
+		assert.DeepEqual(t, cluster.Spec.Proxy.PGBouncer.Config.Databases,
+			map[string]string{"appdb": "host=elsewhere"})
+	})
 }
 
 func TestPodConfigFiles(t *testing.T) {
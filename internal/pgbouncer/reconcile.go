@@ -60,6 +60,9 @@ func Secret(ctx context.Context,
 		return nil
 	}
 
+	ctx, span := tracer.Start(ctx, "pgbouncer-secret")
+	defer span.End()
+
 	var err error
 	initialize.ByteMap(&outSecret.Data)
 
@@ -109,6 +112,7 @@ func Secret(ctx context.Context,
 		}
 	}
 
+	span.RecordError(err)
 	return err
 }
 
@@ -197,6 +201,16 @@ func Pod(
 	}
 
 	outPod.Volumes = []corev1.Volume{configVolume}
+
+	// When the same feature gate is enabled, add any volumes those sidecars
+	// need. Skip any that would replace the volumes above.
+	if util.DefaultMutableFeatureGate.Enabled(util.PGBouncerSidecars) {
+		for _, volume := range inCluster.Spec.Proxy.PGBouncer.Volumes {
+			if volume.Name != configVolume.Name {
+				outPod.Volumes = append(outPod.Volumes, volume)
+			}
+		}
+	}
 }
 
 // PostgreSQL populates outHBAs with any records needed to run PgBouncer.
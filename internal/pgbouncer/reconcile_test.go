@@ -437,11 +437,16 @@ volumes:
 		cluster.Spec.Proxy.PGBouncer.Containers = []corev1.Container{
 			{Name: "customsidecar1"},
 		}
+		cluster.Spec.Proxy.PGBouncer.Volumes = []corev1.Volume{
+			{Name: "pgbouncer-config"},
+			{Name: "customvolume1"},
+		}
 
 		t.Run("SidecarNotEnabled", func(t *testing.T) {
 
 			call()
 			assert.Equal(t, len(pod.Containers), 2, "expected 2 containers in Pod, got %d", len(pod.Containers))
+			assert.Equal(t, len(pod.Volumes), 1, "expected 1 volume in Pod, got %d", len(pod.Volumes))
 		})
 
 		t.Run("SidecarEnabled", func(t *testing.T) {
@@ -458,6 +463,12 @@ volumes:
 				}
 			}
 			assert.Assert(t, found, "expected custom sidecar 'customsidecar1', but container not found")
+
+			// The custom volume is added, but not one that replaces PgBouncer's.
+			assert.Equal(t, len(pod.Volumes), 2, "expected 2 volumes in Pod, got %d", len(pod.Volumes))
+			assert.Equal(t, pod.Volumes[0].Name, "pgbouncer-config")
+			assert.Assert(t, pod.Volumes[0].Projected != nil)
+			assert.Equal(t, pod.Volumes[1].Name, "customvolume1")
 		})
 	})
 }
@@ -0,0 +1,100 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pgmonitor
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// PrometheusRuleSpec returns the spec of a PrometheusRule that alerts about
+// the metrics that the exporter collects from inCluster. The rules select
+// metrics by the "pg_cluster" label, "namespace:name", that the Prometheus
+// configuration of pgMonitor adds to every target.
+// - https://github.com/CrunchyData/pgmonitor/blob/main/prometheus/linux/alert-rules.d/crunchy-alert-rules-pg.yml.example
+func PrometheusRuleSpec(inCluster *v1beta1.PostgresCluster) map[string]interface{} {
+	spec := inCluster.Spec.Monitoring.PGMonitor.Alerts
+
+	lag := resource.MustParse("50Mi")
+	if spec.ReplicationLag != nil {
+		lag = *spec.ReplicationLag
+	}
+	backupHours := int32(28)
+	if spec.BackupAgeHours != nil {
+		backupHours = *spec.BackupAgeHours
+	}
+	connections := int32(90)
+	if spec.ConnectionsPercent != nil {
+		connections = *spec.ConnectionsPercent
+	}
+
+	selector := fmt.Sprintf(`pg_cluster=%q`, inCluster.Namespace+":"+inCluster.Name)
+	labels := map[string]interface{}{
+		"postgres_cluster": inCluster.Name,
+		"namespace":        inCluster.Namespace,
+	}
+
+	rule := func(alert, expr, duration, severity, summary string) map[string]interface{} {
+		ruleLabels := map[string]interface{}{"severity": severity}
+		for k, v := range labels {
+			ruleLabels[k] = v
+		}
+		return map[string]interface{}{
+			"alert":  alert,
+			"expr":   expr,
+			"for":    duration,
+			"labels": ruleLabels,
+			"annotations": map[string]interface{}{
+				"summary": summary,
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"groups": []interface{}{
+			map[string]interface{}{
+				"name": "postgres-operator/" + inCluster.Namespace + "/" + inCluster.Name,
+				"rules": []interface{}{
+					rule("PGReplicationLag",
+						fmt.Sprintf(`ccp_replication_lag_size_bytes{%s} > %d`, selector, lag.Value()),
+						"5m", "warning",
+						fmt.Sprintf("A replica of %s is more than %s of WAL behind the primary",
+							inCluster.Name, lag.String())),
+					rule("PGArchiveCommandFailing",
+						fmt.Sprintf(`ccp_archive_command_status_seconds_since_last_fail{%s} > 300`, selector),
+						"1m", "critical",
+						fmt.Sprintf("WAL archiving of %s has failed for more than 5 minutes",
+							inCluster.Name)),
+					rule("PGBackupStale",
+						fmt.Sprintf(`min(ccp_backrest_last_incr_backup_time_since_completion_seconds{%s}) > %d`,
+							selector, int64(backupHours)*3600),
+						"5m", "warning",
+						fmt.Sprintf("The latest backup of %s is more than %d hours old",
+							inCluster.Name, backupHours)),
+					rule("PGConnectionsSaturated",
+						fmt.Sprintf(`100 * ccp_connection_stats_total{%[1]s} / ccp_connection_stats_max_connections{%[1]s} > %d`,
+							selector, connections),
+						"5m", "warning",
+						fmt.Sprintf("Connections to %s exceed %d%% of max_connections",
+							inCluster.Name, connections)),
+				},
+			},
+		},
+	}
+}
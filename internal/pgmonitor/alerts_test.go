@@ -0,0 +1,84 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pgmonitor
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestPrometheusRuleSpec(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Name = "hippo"
+	cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+		PGMonitor: &v1beta1.PGMonitorSpec{
+			Exporter: &v1beta1.ExporterSpec{},
+			Alerts:   &v1beta1.PGMonitorAlertsSpec{},
+		},
+	}
+
+	// expressions returns the expression of each alert in spec.
+	expressions := func(t *testing.T, spec map[string]interface{}) map[string]string {
+		groups := spec["groups"].([]interface{})
+		assert.Equal(t, len(groups), 1)
+		group := groups[0].(map[string]interface{})
+		assert.Equal(t, group["name"], "postgres-operator/ns1/hippo")
+
+		result := map[string]string{}
+		for _, rule := range group["rules"].([]interface{}) {
+			rule := rule.(map[string]interface{})
+			labels := rule["labels"].(map[string]interface{})
+			assert.Equal(t, labels["postgres_cluster"], "hippo")
+			assert.Equal(t, labels["namespace"], "ns1")
+			result[rule["alert"].(string)] = rule["expr"].(string)
+		}
+		return result
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		assert.DeepEqual(t, expressions(t, PrometheusRuleSpec(cluster)), map[string]string{
+			"PGReplicationLag": `ccp_replication_lag_size_bytes{pg_cluster="ns1:hippo"} > 52428800`,
+			"PGArchiveCommandFailing": `` +
+				`ccp_archive_command_status_seconds_since_last_fail{pg_cluster="ns1:hippo"} > 300`,
+			"PGBackupStale": `` +
+				`min(ccp_backrest_last_incr_backup_time_since_completion_seconds{pg_cluster="ns1:hippo"}) > 100800`,
+			"PGConnectionsSaturated": `100 * ccp_connection_stats_total{pg_cluster="ns1:hippo"}` +
+				` / ccp_connection_stats_max_connections{pg_cluster="ns1:hippo"} > 90`,
+		})
+	})
+
+	t.Run("Thresholds", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		lag := resource.MustParse("1Gi")
+		cluster.Spec.Monitoring.PGMonitor.Alerts = &v1beta1.PGMonitorAlertsSpec{
+			ReplicationLag:     &lag,
+			BackupAgeHours:     initialize.Int32(2),
+			ConnectionsPercent: initialize.Int32(75),
+		}
+
+		exprs := expressions(t, PrometheusRuleSpec(cluster))
+		assert.Assert(t, cmp.Contains(exprs["PGReplicationLag"], ` > 1073741824`))
+		assert.Assert(t, cmp.Contains(exprs["PGBackupStale"], `) > 7200`))
+		assert.Assert(t, cmp.Contains(exprs["PGConnectionsSaturated"], ` > 75`))
+	})
+}
@@ -32,3 +32,9 @@ func ExporterEnabled(cluster *v1beta1.PostgresCluster) bool {
 	}
 	return true
 }
+
+// AlertsEnabled returns true if the exporter is enabled and alerts about its
+// metrics are requested
+func AlertsEnabled(cluster *v1beta1.PostgresCluster) bool {
+	return ExporterEnabled(cluster) && cluster.Spec.Monitoring.PGMonitor.Alerts != nil
+}
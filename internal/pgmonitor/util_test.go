@@ -37,3 +37,16 @@ func TestExporterEnabled(t *testing.T) {
 	assert.Assert(t, ExporterEnabled(cluster))
 
 }
+
+func TestAlertsEnabled(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	assert.Assert(t, !AlertsEnabled(cluster))
+
+	cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+		PGMonitor: &v1beta1.PGMonitorSpec{Alerts: &v1beta1.PGMonitorAlertsSpec{}},
+	}
+	assert.Assert(t, !AlertsEnabled(cluster), "expected the exporter to be required")
+
+	cluster.Spec.Monitoring.PGMonitor.Exporter = &v1beta1.ExporterSpec{}
+	assert.Assert(t, AlertsEnabled(cluster))
+}
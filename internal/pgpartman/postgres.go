@@ -0,0 +1,99 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pgpartman
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// EnableInPostgreSQL creates pg_partman in the "partman" schema of the
+// databases in spec that exist.
+// - https://github.com/pgpartman/pg_partman#installation
+func EnableInPostgreSQL(ctx context.Context, exec postgres.Executor, spec *v1beta1.PGPartmanSpec) error {
+	log := logging.FromContext(ctx)
+
+	databases, err := json.Marshal(spec.Databases)
+	if err == nil {
+		var stdout, stderr string
+		stdout, stderr, err = exec.ExecInDatabasesFromQuery(ctx,
+			`SELECT datname FROM pg_catalog.pg_database`+
+				` WHERE datallowconn AND datname IN (`+
+				`SELECT pg_catalog.json_array_elements_text(:'databases'))`,
+			strings.Join([]string{
+				// Quiet NOTICE messages from IF NOT EXISTS statements.
+				// - https://www.postgresql.org/docs/current/runtime-config-client.html
+				`SET client_min_messages = WARNING;`,
+				`CREATE SCHEMA IF NOT EXISTS partman;`,
+				`CREATE EXTENSION IF NOT EXISTS pg_partman SCHEMA partman;`,
+			}, "\n"),
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+				"databases":     string(databases),
+			})
+
+		log.V(1).Info("enabled pg_partman", "stdout", stdout, "stderr", stderr)
+	}
+
+	return err
+}
+
+// PostgreSQLParameters populates outParameters with the settings of the
+// pg_partman background worker when inCluster enables it. The worker calls
+// run_maintenance() in each database on an interval. PostgreSQL must be
+// restarted when changing these values.
+// - https://github.com/pgpartman/pg_partman#background-worker
+func PostgreSQLParameters(inCluster *v1beta1.PostgresCluster, outParameters *postgres.Parameters) {
+	spec := inCluster.Spec.PGPartman
+	if spec == nil {
+		return
+	}
+
+	shared := outParameters.Mandatory.Value("shared_preload_libraries")
+	outParameters.Mandatory.Add("shared_preload_libraries",
+		strings.TrimPrefix(shared+",pg_partman_bgw", ","))
+
+	databases := make([]string, 0, len(spec.Databases))
+	for _, database := range spec.Databases {
+		databases = append(databases, string(database))
+	}
+	outParameters.Mandatory.Add("pg_partman_bgw.dbname", strings.Join(databases, ","))
+
+	interval := int32(3600)
+	if spec.IntervalSeconds != nil {
+		interval = *spec.IntervalSeconds
+	}
+	outParameters.Mandatory.Add("pg_partman_bgw.interval", fmt.Sprint(interval))
+
+	role := "postgres"
+	if spec.Role != "" {
+		role = string(spec.Role)
+	}
+	outParameters.Mandatory.Add("pg_partman_bgw.role", role)
+
+	if spec.Analyze != nil && *spec.Analyze {
+		outParameters.Mandatory.Add("pg_partman_bgw.analyze", "on")
+	} else {
+		outParameters.Mandatory.Add("pg_partman_bgw.analyze", "off")
+	}
+}
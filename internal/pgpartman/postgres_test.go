@@ -0,0 +1,96 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package pgpartman
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestEnableInPostgreSQL(t *testing.T) {
+	expected := errors.New("whoops")
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		assert.Assert(t, stdout != nil, "should capture stdout")
+		assert.Assert(t, stderr != nil, "should capture stderr")
+
+		assert.Assert(t, strings.Contains(strings.Join(command, "\n"),
+			`--set=databases=["app","metrics"]`,
+		), "expected databases in a variable")
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Equal(t, string(b), strings.Trim(`
+SET client_min_messages = WARNING;
+CREATE SCHEMA IF NOT EXISTS partman;
+CREATE EXTENSION IF NOT EXISTS pg_partman SCHEMA partman;
+		`, "\t\n"))
+
+		return expected
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, expected, EnableInPostgreSQL(ctx, exec, &v1beta1.PGPartmanSpec{
+		Databases: []v1beta1.PostgresIdentifier{"app", "metrics"},
+	}))
+}
+
+func TestPostgreSQLParameters(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	parameters := postgres.Parameters{
+		Mandatory: postgres.NewParameterSet(),
+	}
+
+	// No pg_partman by default.
+	PostgreSQLParameters(cluster, &parameters)
+	assert.DeepEqual(t, parameters.Mandatory.AsMap(), map[string]string{})
+
+	cluster.Spec.PGPartman = &v1beta1.PGPartmanSpec{
+		Databases: []v1beta1.PostgresIdentifier{"app", "metrics"},
+	}
+	parameters.Mandatory.Add("shared_preload_libraries", "pgaudit")
+	PostgreSQLParameters(cluster, &parameters)
+	assert.DeepEqual(t, parameters.Mandatory.AsMap(), map[string]string{
+		"shared_preload_libraries": "pgaudit,pg_partman_bgw",
+		"pg_partman_bgw.analyze":   "off",
+		"pg_partman_bgw.dbname":    "app,metrics",
+		"pg_partman_bgw.interval":  "3600",
+		"pg_partman_bgw.role":      "postgres",
+	})
+
+	cluster.Spec.PGPartman.IntervalSeconds = initialize.Int32(600)
+	cluster.Spec.PGPartman.Role = "partman"
+	cluster.Spec.PGPartman.Analyze = initialize.Bool(true)
+	parameters.Mandatory = postgres.NewParameterSet()
+	PostgreSQLParameters(cluster, &parameters)
+	assert.DeepEqual(t, parameters.Mandatory.AsMap(), map[string]string{
+		"shared_preload_libraries": "pg_partman_bgw",
+		"pg_partman_bgw.analyze":   "on",
+		"pg_partman_bgw.dbname":    "app,metrics",
+		"pg_partman_bgw.interval":  "600",
+		"pg_partman_bgw.role":      "partman",
+	})
+}
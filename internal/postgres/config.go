@@ -25,6 +25,11 @@ import (
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
+// ICUUnsupportedMessage is the last line logged by the startup container
+// when PostgreSQL in the image cannot initialize a cluster with the ICU
+// locale provider.
+const ICUUnsupportedMessage = "PostgreSQL in this image does not support the icu locale provider"
+
 const (
 	// bashHalt is a Bash function that prints its arguments to stderr then
 	// exits with a non-zero status. It uses the exit status of the prior
@@ -65,6 +70,9 @@ safelink() (
 	// walMountPath is where to mount the optional WAL volume.
 	walMountPath = "/pgwal"
 
+	// tablespaceMountPath is where to mount the optional tablespace volumes.
+	tablespaceMountPath = "/tablespaces"
+
 	// downwardAPIPath is where to mount the downwardAPI volume.
 	downwardAPIPath = "/etc/database-containerinfo"
 
@@ -77,8 +85,30 @@ safelink() (
 
 	// configMountPath is where to mount additional config files
 	configMountPath = "/etc/postgres"
+
+	// customConfigMapKey is the key of the cluster ConfigMap that contains the
+	// custom configuration file included by PostgreSQL.
+	customConfigMapKey = "postgres-custom.conf"
+
+	// customConfigPath and includeConfigPath are where the custom configuration
+	// file and the directory it includes are projected, relative to configMountPath.
+	customConfigPath  = "~postgres-operator/custom.conf"
+	includeConfigPath = "conf.d"
 )
 
+// CustomConfigFile returns the absolute path to the configuration file that
+// PostgreSQL reads before the parameters set by Patroni, or empty string when
+// cluster has no additional config files. Parameters set by Patroni and the
+// operator come later and take precedence.
+// - https://patroni.readthedocs.io/en/latest/yaml_configuration.html#postgresql
+// - https://www.postgresql.org/docs/current/config-setting.html#CONFIG-INCLUDES
+func CustomConfigFile(cluster *v1beta1.PostgresCluster) string {
+	if len(cluster.Spec.Config.Files) == 0 {
+		return ""
+	}
+	return configMountPath + "/" + customConfigPath
+}
+
 // ConfigDirectory returns the absolute path to $PGDATA for cluster.
 // - https://www.postgresql.org/docs/current/runtime-config-file-locations.html
 func ConfigDirectory(cluster *v1beta1.PostgresCluster) string {
@@ -105,6 +135,14 @@ func WALDirectory(
 	return fmt.Sprintf("%s/pg%d_wal", walStorage, cluster.Spec.PostgresVersion)
 }
 
+// TablespaceDirectory returns the absolute path to the directory where an
+// instance stores tablespace. It is inside its volume because PostgreSQL
+// requires an empty directory owned by itself.
+// - https://www.postgresql.org/docs/current/sql-createtablespace.html
+func TablespaceDirectory(tablespace string) string {
+	return fmt.Sprintf("%s/%s/data", tablespaceMountPath, tablespace)
+}
+
 // Environment returns the environment variables required to invoke PostgreSQL
 // utilities.
 func Environment(cluster *v1beta1.PostgresCluster) []corev1.EnvVar {
@@ -143,7 +181,7 @@ func Environment(cluster *v1beta1.PostgresCluster) []corev1.EnvVar {
 // reloadCommand returns an entrypoint that convinces PostgreSQL to reload
 // certificate files when they change. The process will appear as name in `ps`
 // and `top`.
-func reloadCommand(name string) []string {
+func reloadCommand(cluster *v1beta1.PostgresCluster, name string) []string {
 	// Use a Bash loop to periodically check the mtime of the mounted
 	// certificate volume. When it changes, copy the replication certificate,
 	// signal PostgreSQL, and print the observed timestamp.
@@ -166,12 +204,22 @@ func reloadCommand(name string) []string {
 	// descriptor gets closed and reopened to use the builtin `[ -nt` to check
 	// mtimes.
 	// - https://unix.stackexchange.com/a/407383
+	//
+	// Citus nodes read their client certificate every time they connect to
+	// one another, so that copy happens here too.
+	var citus string
+	if cluster.Spec.Citus != nil {
+		citus = fmt.Sprintf(`
+    install -D --mode=0600 -t %q "${directory}"/{%s,%s} &&`,
+			naming.CitusTmp, naming.CitusCertPath, naming.CitusPrivateKeyPath)
+	}
+
 	script := fmt.Sprintf(`
 declare -r directory=%q
 exec {fd}<> <(:)
 while read -r -t 5 -u "${fd}" || true; do
   if [ "${directory}" -nt "/proc/self/fd/${fd}" ] &&
-    install -D --mode=0600 -t %q "${directory}"/{%s,%s,%s} &&
+    install -D --mode=0600 -t %q "${directory}"/{%s,%s,%s} &&`+citus+`
     pkill -HUP --exact --parent=1 postgres
   then
     exec {fd}>&- && exec {fd}<> <(:)
@@ -201,9 +249,10 @@ func startupCommand(
 	version := fmt.Sprint(cluster.Spec.PostgresVersion)
 	walDir := WALDirectory(cluster, instance)
 
-	args := []string{version, walDir, naming.PGBackRestPGDataLogPath}
-	script := strings.Join([]string{
-		`declare -r expected_major_version="$1" pgwal_directory="$2" pgbrLog_directory="$3"`,
+	args := []string{version, walDir, naming.PGBackRestPGDataLogPath, naming.PatroniPGDataLogPath,
+		naming.PostgresPGDataLogPath}
+	script := []string{
+		`declare -r expected_major_version="$1" pgwal_directory="$2" pgbrLog_directory="$3" patroniLog_directory="$4" postgresLog_directory="$5"`,
 
 		// Function to print the permissions of a file or directory and its parents.
 		bashPermissions,
@@ -274,6 +323,17 @@ func startupCommand(
 		`install --directory --mode=0775 "${pgbrLog_directory}" ||`,
 		`halt "$(permissions "${pgbrLog_directory}" ||:)"`,
 
+		// Create the Patroni log directory.
+		`results 'Patroni log directory' "${patroniLog_directory}"`,
+		`install --directory --mode=0775 "${patroniLog_directory}" ||`,
+		`halt "$(permissions "${patroniLog_directory}" ||:)"`,
+
+		// Create the PostgreSQL log directory. PostgreSQL creates only the
+		// last directory in "log_directory", so create all of them here.
+		`results 'PostgreSQL log directory' "${postgresLog_directory}"`,
+		`install --directory --mode=0775 "${postgresLog_directory}" ||`,
+		`halt "$(permissions "${postgresLog_directory}" ||:)"`,
+
 		// Copy replication client certificate files
 		// from the /pgconf/tls/replication directory to the /tmp/replication directory in order
 		// to set proper file permissions. This is required because the group permission settings
@@ -286,7 +346,42 @@ func startupCommand(
 			naming.ReplicationTmp, naming.CertMountPath+naming.ReplicationDirectory,
 			naming.ReplicationCert, naming.ReplicationPrivateKey,
 			naming.ReplicationCACert),
+	}
+
+	// Copy the Citus client certificate files for the same reason.
+	if cluster.Spec.Citus != nil {
+		script = append(script, fmt.Sprintf(`install -D --mode=0600 -t %q %q/{%s,%s}`,
+			naming.CitusTmp, naming.CertMountPath+naming.CitusDirectory,
+			naming.CitusCert, naming.CitusPrivateKey))
+	}
 
+	// Create the tablespace directories. PostgreSQL requires them to be
+	// writable by only itself.
+	for _, tablespace := range instance.TablespaceVolumes {
+		directory := TablespaceDirectory(tablespace.Name)
+		script = append(script,
+			fmt.Sprintf(`results 'tablespace directory' %q`, directory),
+			fmt.Sprintf(`install --directory --mode=0700 %q ||`, directory),
+			fmt.Sprintf(`halt "$(permissions %q ||:)"`, directory),
+		)
+	}
+
+	// Abort before bootstrap when PostgreSQL in the image was built without
+	// ICU. The controller reports this message in a condition. Not every image
+	// has ldd; when it is missing or fails, support is unknown and initdb
+	// reports any problem itself.
+	// - https://www.postgresql.org/docs/current/install-make.html#CONFIGURE-OPTIONS-FEATURES
+	if locale := cluster.Spec.Config.Locale; locale != nil &&
+		locale.Provider == v1beta1.PostgresLocaleProviderICU {
+		script = append(script,
+			`[ -f "${postgres_data_directory}/PG_VERSION" ] ||`,
+			`! postgres_libraries=$(ldd "$(command -v postgres)" 2> /dev/null) ||`,
+			`[[ "${postgres_libraries}" == *libicu* ]] ||`,
+			`halt '`+ICUUnsupportedMessage+`'`,
+		)
+	}
+
+	script = append(script,
 		// When the data directory is empty, there's nothing more to do.
 		`[ -f "${postgres_data_directory}/PG_VERSION" ] || exit 0`,
 
@@ -322,7 +417,7 @@ func startupCommand(
 		// - https://git.postgresql.org/gitweb/?p=postgresql.git;f=src/backend/access/transam/xlog.c;hb=REL_12_0#l5318
 		// TODO(cbandy): Remove this after 5.0 is EOL.
 		`rm -f "${postgres_data_directory}/recovery.signal"`,
-	}, "\n")
+	)
 
-	return append([]string{"bash", "-ceu", "--", script, "startup"}, args...)
+	return append([]string{"bash", "-ceu", "--", strings.Join(script, "\n"), "startup"}, args...)
 }
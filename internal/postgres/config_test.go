@@ -459,6 +459,10 @@ func TestBashSafeLink(t *testing.T) {
 	})
 }
 
+func TestTablespaceDirectory(t *testing.T) {
+	assert.Equal(t, TablespaceDirectory("fast"), "/tablespaces/fast/data")
+}
+
 func TestStartupCommand(t *testing.T) {
 	shellcheck := require.ShellCheck(t)
 
@@ -489,4 +493,90 @@ func TestStartupCommand(t *testing.T) {
 		assert.Assert(t, strings.HasPrefix(string(b), `|`),
 			"expected literal block scalar, got:\n%s", b)
 	})
+
+	t.Run("LogDirectories", func(t *testing.T) {
+		assert.DeepEqual(t, command[5:], []string{
+			"13", "/pgdata/pg13_wal",
+			"/pgdata/pgbackrest/log", "/pgdata/patroni/log", "/pgdata/postgres/log",
+		})
+		assert.Assert(t, strings.Contains(script, `
+install --directory --mode=0775 "${postgresLog_directory}" ||
+halt "$(permissions "${postgresLog_directory}" ||:)"
+`), "got:\n%s", script)
+	})
+
+	t.Run("Citus", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Citus = new(v1beta1.CitusSpec)
+
+		script := startupCommand(cluster, instance)[3]
+		assert.Assert(t, strings.Contains(script,
+			`install -D --mode=0600 -t "/tmp/citus" "/pgconf/tls/citus"/{tls.crt,tls.key}`),
+			"got:\n%s", script)
+	})
+
+	t.Run("ICU", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.PostgresVersion = 15
+		cluster.Spec.Config.Locale = &v1beta1.PostgresLocaleSpec{
+			Provider: "icu", ICULocale: "en-US",
+		}
+
+		script := startupCommand(cluster, instance)[3]
+		assert.Assert(t, strings.Contains(script, `
+[ -f "${postgres_data_directory}/PG_VERSION" ] ||
+! postgres_libraries=$(ldd "$(command -v postgres)" 2> /dev/null) ||
+[[ "${postgres_libraries}" == *libicu* ]] ||
+halt 'PostgreSQL in this image does not support the icu locale provider'
+[ -f "${postgres_data_directory}/PG_VERSION" ] || exit 0
+`), "got:\n%s", script)
+
+		file := filepath.Join(t.TempDir(), "script.bash")
+		assert.NilError(t, os.WriteFile(file, []byte(script), 0o600))
+
+		cmd := exec.Command(shellcheck, "--enable=all", file)
+		output, err := cmd.CombinedOutput()
+		assert.NilError(t, err, "%q\n%s", cmd.Args, output)
+	})
+
+	t.Run("Tablespaces", func(t *testing.T) {
+		instance := instance.DeepCopy()
+		instance.TablespaceVolumes = []v1beta1.TablespaceVolume{{Name: "fast"}}
+
+		script := startupCommand(cluster, instance)[3]
+		assert.Assert(t, strings.Contains(script, `
+install --directory --mode=0700 "/tablespaces/fast/data" ||
+halt "$(permissions "/tablespaces/fast/data" ||:)"
+`), "got:\n%s", script)
+	})
+}
+
+func TestReloadCommand(t *testing.T) {
+	shellcheck := require.ShellCheck(t)
+	cluster := new(v1beta1.PostgresCluster)
+
+	for _, citus := range []bool{false, true} {
+		if citus {
+			cluster.Spec.Citus = new(v1beta1.CitusSpec)
+		}
+
+		command := reloadCommand(cluster, "some-name")
+
+		// Expect a bash command with an inline script.
+		assert.DeepEqual(t, command[:3], []string{"bash", "-ceu", "--"})
+		assert.Assert(t, len(command) > 3)
+		script := command[3]
+
+		// Write out that inline script.
+		dir := t.TempDir()
+		file := filepath.Join(dir, "script.bash")
+		assert.NilError(t, os.WriteFile(file, []byte(script), 0o600))
+
+		// Expect shellcheck to be happy.
+		cmd := exec.Command(shellcheck, "--enable=all", file)
+		output, err := cmd.CombinedOutput()
+		assert.NilError(t, err, "%q\n%s", cmd.Args, output)
+
+		assert.Equal(t, citus, strings.Contains(script, `"/tmp/citus"`), "got:\n%s", script)
+	}
 }
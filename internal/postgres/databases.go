@@ -19,8 +19,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"strings"
 
 	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/util"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // CreateDatabasesInPostgreSQL calls exec to create databases that do not exist
@@ -81,3 +84,176 @@ SELECT pg_catalog.format('CREATE DATABASE %I',
 
 	return err
 }
+
+// WriteDatabasesInPostgreSQL calls exec to create the databases of inCluster
+// and their owners that do not exist in PostgreSQL. Once they exist, it sets
+// the owner of each database and creates any of its specified schemas and
+// extensions.
+func WriteDatabasesInPostgreSQL(
+	ctx context.Context, exec Executor, inCluster *v1beta1.PostgresCluster,
+) error {
+	log := logging.FromContext(ctx)
+	databases := inCluster.Spec.Databases
+
+	// Owners that are also users are created as users so they can login. The
+	// operator creates one user, named after the cluster, when none are specified.
+	users := make(map[v1beta1.PostgresIdentifier]bool, len(inCluster.Spec.Users))
+	for _, user := range inCluster.Spec.Users {
+		users[user.Name] = true
+	}
+	if inCluster.Spec.Users == nil {
+		users[v1beta1.PostgresIdentifier(inCluster.Name)] = true
+	}
+
+	var err error
+	var sql bytes.Buffer
+	var specs []map[string]interface{}
+
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+	encoder := json.NewEncoder(&sql)
+	encoder.SetEscapeHTML(false)
+
+	for i := range databases {
+		spec := databases[i]
+
+		// The locale of a database can only be set when it is created. Copy
+		// from "template0" so the locale can differ from the cluster default.
+		// - https://www.postgresql.org/docs/current/sql-createdatabase.html
+		var options []string
+		if locale := spec.Locale; locale != nil {
+			options = append(options, `TEMPLATE template0`)
+			if locale.Locale != "" {
+				options = append(options,
+					`LC_COLLATE `+util.SQLQuoteLiteral(locale.Locale),
+					`LC_CTYPE `+util.SQLQuoteLiteral(locale.Locale))
+			}
+
+			// PostgreSQL 15 is the first to choose a locale provider per database.
+			if inCluster.Spec.PostgresVersion >= 15 {
+				if locale.Provider != "" {
+					options = append(options, `LOCALE_PROVIDER `+util.SQLQuoteLiteral(locale.Provider))
+				}
+				if locale.ICULocale != "" {
+					options = append(options, `ICU_LOCALE `+util.SQLQuoteLiteral(locale.ICULocale))
+				}
+			}
+		}
+
+		data := map[string]interface{}{
+			"database":   spec.Name,
+			"extensions": spec.Extensions,
+			"login":      users[spec.Owner],
+			"options":    strings.Join(options, " "),
+			"owner":      spec.Owner,
+			"schemas":    spec.Schemas,
+		}
+		specs = append(specs, data)
+
+		if err == nil {
+			err = encoder.Encode(data)
+		}
+	}
+	_, _ = sql.WriteString(`\.` + "\n")
+
+	// Create owners that do not already exist. Only those that are also users
+	// have the LOGIN option.
+	// - https://www.postgresql.org/docs/current/sql-createrole.html
+	// - https://www.postgresql.org/docs/current/sql-createuser.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format(
+       CASE WHEN pg_catalog.json_extract_path_text(input.data, 'login')::boolean
+            THEN 'CREATE USER %I' ELSE 'CREATE ROLE %I NOLOGIN' END,
+       pg_catalog.json_extract_path_text(input.data, 'owner'))
+  FROM input
+ WHERE pg_catalog.json_extract_path_text(input.data, 'owner') <> ''
+   AND NOT EXISTS (
+       SELECT 1 FROM pg_catalog.pg_roles
+       WHERE rolname = pg_catalog.json_extract_path_text(input.data, 'owner'))
+ GROUP BY 1
+\gexec
+`)
+
+	// Create databases that do not already exist.
+	// - https://www.postgresql.org/docs/current/sql-createdatabase.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('CREATE DATABASE %I %s',
+       pg_catalog.json_extract_path_text(input.data, 'database'),
+       pg_catalog.json_extract_path_text(input.data, 'options'))
+  FROM input
+ WHERE NOT EXISTS (
+       SELECT 1 FROM pg_catalog.pg_database
+       WHERE datname = pg_catalog.json_extract_path_text(input.data, 'database'))
+ ORDER BY input.id
+\gexec
+`)
+
+	// Set the owner of every database that has one.
+	// - https://www.postgresql.org/docs/current/sql-alterdatabase.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('ALTER DATABASE %I OWNER TO %I',
+       pg_catalog.json_extract_path_text(input.data, 'database'),
+       pg_catalog.json_extract_path_text(input.data, 'owner'))
+  FROM input
+ WHERE pg_catalog.json_extract_path_text(input.data, 'owner') <> ''
+ ORDER BY input.id
+\gexec
+`)
+
+	stdout, stderr, err := exec.Exec(ctx, &sql,
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("wrote PostgreSQL databases", "stdout", stdout, "stderr", stderr)
+
+	// Create schemas and extensions inside each database. Every database reads
+	// its own specification from the "spec" variable.
+	// - https://www.postgresql.org/docs/current/sql-createschema.html
+	// - https://www.postgresql.org/docs/current/sql-createextension.html
+	var spec []byte
+	if err == nil {
+		spec, err = json.Marshal(specs)
+	}
+	if err == nil {
+		stdout, stderr, err = exec.ExecInDatabasesFromQuery(ctx,
+			`SELECT datname FROM pg_catalog.pg_database`+
+				` JOIN pg_catalog.json_array_elements(:'spec') AS input (data)`+
+				` ON datname = pg_catalog.json_extract_path_text(input.data, 'database')`+
+				` WHERE datallowconn`,
+			strings.Join([]string{
+				// Quiet NOTICE messages from IF NOT EXISTS statements.
+				// - https://www.postgresql.org/docs/current/runtime-config-client.html
+				`SET client_min_messages = WARNING;`,
+
+				`SELECT pg_catalog.format('CREATE SCHEMA IF NOT EXISTS %I %s', schema.name,`,
+				`       CASE WHEN owner.name <> '' THEN pg_catalog.format('AUTHORIZATION %I', owner.name) END)`,
+				`  FROM pg_catalog.json_array_elements(:'spec') AS input (data),`,
+				`       pg_catalog.json_extract_path_text(input.data, 'owner') AS owner (name),`,
+				`       pg_catalog.json_array_elements_text(pg_catalog.json_extract_path(`,
+				`       pg_catalog.json_strip_nulls(input.data), 'schemas')) AS schema (name)`,
+				` WHERE pg_catalog.json_extract_path_text(input.data, 'database') = pg_catalog.current_database()`,
+				`\gexec`,
+
+				`SELECT pg_catalog.format('CREATE EXTENSION IF NOT EXISTS %I', extension.name)`,
+				`  FROM pg_catalog.json_array_elements(:'spec') AS input (data),`,
+				`       pg_catalog.json_array_elements_text(pg_catalog.json_extract_path(`,
+				`       pg_catalog.json_strip_nulls(input.data), 'extensions')) AS extension (name)`,
+				` WHERE pg_catalog.json_extract_path_text(input.data, 'database') = pg_catalog.current_database()`,
+				`\gexec`,
+			}, "\n"),
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+				"spec":          string(spec),
+			})
+
+		log.V(1).Info("wrote PostgreSQL schemas and extensions", "stdout", stdout, "stderr", stderr)
+	}
+
+	return err
+}
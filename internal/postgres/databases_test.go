@@ -25,6 +25,7 @@ import (
 	"gotest.tools/v3/assert"
 
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestCreateDatabasesInPostgreSQL(t *testing.T) {
@@ -101,3 +102,108 @@ SELECT pg_catalog.format('CREATE DATABASE %I',
 		assert.Equal(t, calls, 1)
 	})
 }
+
+func TestWriteDatabasesInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			calls++
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			return expected
+		}
+
+		assert.Equal(t, expected, WriteDatabasesInPostgreSQL(ctx, exec, new(v1beta1.PostgresCluster)))
+		assert.Equal(t, calls, 1, "expected to stop at the first error")
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+
+			switch calls {
+			case 1:
+				assert.DeepEqual(t, command[:3], []string{"psql", "-Xw", "--file=-"})
+				assert.Assert(t, cmp.Contains(string(b), `
+\copy input (data) from stdin with (format text)
+{"database":"app","extensions":["pg_trgm"],"login":false,"options":"","owner":"app owner","schemas":["api","data"]}
+{"database":"sv","extensions":null,"login":false,"options":"TEMPLATE template0 LC_COLLATE 'sv_SE.UTF-8' LC_CTYPE 'sv_SE.UTF-8'","owner":"","schemas":null}
+{"database":"icu","extensions":null,"login":true,"options":"TEMPLATE template0 LOCALE_PROVIDER 'icu' ICU_LOCALE 'en-US'","owner":"hippo","schemas":null}
+\.
+`))
+				assert.Assert(t, cmp.Contains(string(b), `THEN 'CREATE USER %I' ELSE 'CREATE ROLE %I NOLOGIN' END`))
+				assert.Assert(t, cmp.Contains(string(b), `CREATE DATABASE %I %s`))
+				assert.Assert(t, cmp.Contains(string(b), `ALTER DATABASE %I OWNER TO %I`))
+
+			case 2:
+				assert.DeepEqual(t, command[:3], []string{"bash", "-ceu", "--"})
+				assert.Assert(t, cmp.Contains(command[5], `json_array_elements(:'spec')`))
+				assert.Assert(t, cmp.Contains(strings.Join(command[6:], "\n"),
+					`--set=spec=[{"database":"app",`))
+				assert.Assert(t, cmp.Contains(string(b), `CREATE SCHEMA IF NOT EXISTS %I %s`))
+				assert.Assert(t, cmp.Contains(string(b), `CREATE EXTENSION IF NOT EXISTS %I`))
+			}
+			return nil
+		}
+
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.PostgresVersion = 15
+		cluster.Spec.Users = []v1beta1.PostgresUserSpec{{Name: "hippo"}}
+		cluster.Spec.Databases = []v1beta1.PostgresDatabaseSpec{
+			{
+				Name:       "app",
+				Owner:      "app owner",
+				Extensions: []v1beta1.PostgresIdentifier{"pg_trgm"},
+				Schemas:    []v1beta1.PostgresIdentifier{"api", "data"},
+			},
+			{
+				Name:   "sv",
+				Locale: &v1beta1.PostgresLocaleSpec{Locale: "sv_SE.UTF-8"},
+			},
+			{
+				Name:  "icu",
+				Owner: "hippo",
+				Locale: &v1beta1.PostgresLocaleSpec{
+					Provider: v1beta1.PostgresLocaleProviderICU, ICULocale: "en-US",
+				},
+			},
+		}
+
+		assert.NilError(t, WriteDatabasesInPostgreSQL(ctx, exec, cluster))
+		assert.Equal(t, calls, 2)
+	})
+
+	t.Run("BeforeVersion15", func(t *testing.T) {
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, !strings.Contains(string(b), "LOCALE_PROVIDER"))
+			assert.Assert(t, !strings.Contains(string(b), "ICU_LOCALE"))
+			return nil
+		}
+
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.PostgresVersion = 14
+		cluster.Spec.Databases = []v1beta1.PostgresDatabaseSpec{{
+			Name: "icu",
+			Locale: &v1beta1.PostgresLocaleSpec{
+				Provider: v1beta1.PostgresLocaleProviderICU, ICULocale: "en-US",
+			},
+		}}
+
+		assert.NilError(t, WriteDatabasesInPostgreSQL(ctx, exec, cluster))
+	})
+}
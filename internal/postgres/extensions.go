@@ -0,0 +1,194 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// extensionLibraries are the shared libraries of well-known extensions that
+// must be loaded when PostgreSQL starts.
+var extensionLibraries = map[string]string{
+	"pg_cron":            "pg_cron",
+	"pg_squeeze":         "pg_squeeze",
+	"pg_stat_kcache":     "pg_stat_kcache",
+	"pg_stat_statements": "pg_stat_statements",
+	"pg_wait_sampling":   "pg_wait_sampling",
+	"pgaudit":            "pgaudit",
+	"pglogical":          "pglogical",
+	"timescaledb":        "timescaledb",
+}
+
+// extensionDatabases returns the databases in which to create extension.
+func extensionDatabases(extension v1beta1.PostgresExtensionSpec) []string {
+	databases := make([]string, 0, len(extension.Databases))
+	for _, database := range extension.Databases {
+		databases = append(databases, string(database))
+	}
+
+	// pg_cron can only be created in the database named by "cron.database_name".
+	// - https://github.com/citusdata/pg_cron#setting-up-pg_cron
+	if extension.Name == "pg_cron" && len(databases) == 0 {
+		databases = append(databases, "postgres")
+	}
+	return databases
+}
+
+// ExtensionParameters populates outParameters with the shared libraries of
+// the extensions of inCluster. PostgreSQL must be restarted when changing
+// "shared_preload_libraries".
+// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SHARED-PRELOAD-LIBRARIES
+func ExtensionParameters(inCluster *v1beta1.PostgresCluster, outParameters *Parameters) {
+	var libraries []string
+	if shared := outParameters.Mandatory.Value("shared_preload_libraries"); shared != "" {
+		libraries = strings.Split(shared, ",")
+	}
+
+	for _, extension := range inCluster.Spec.Extensions {
+		library := extension.Library
+		if library == "" {
+			library = extensionLibraries[string(extension.Name)]
+		}
+		if library == "" {
+			continue
+		}
+
+		found := false
+		for _, existing := range libraries {
+			found = found || existing == library
+		}
+		if !found && library == "timescaledb" {
+			// TimescaleDB should load before other libraries. Citus still
+			// goes ahead of it when enabled.
+			// - https://docs.timescale.com/self-hosted/latest/configuration/
+			libraries = append([]string{library}, libraries...)
+		} else if !found {
+			libraries = append(libraries, library)
+		}
+
+		// pg_cron schedules jobs from only one database.
+		if extension.Name == "pg_cron" {
+			outParameters.Default.Add("cron.database_name", extensionDatabases(extension)[0])
+		}
+
+		// TimescaleDB sends telemetry to its vendor unless told otherwise.
+		// - https://docs.timescale.com/self-hosted/latest/configuration/telemetry/
+		if extension.Name == "timescaledb" {
+			outParameters.Default.Add("timescaledb.telemetry_level", "off")
+		}
+	}
+
+	if len(libraries) > 0 {
+		outParameters.Mandatory.Add("shared_preload_libraries", strings.Join(libraries, ","))
+	}
+}
+
+// CreateExtensionsInPostgreSQL calls exec to create extensions that do not
+// exist in their databases. Extensions that need a shared library fail until
+// PostgreSQL loads it.
+// - https://www.postgresql.org/docs/current/sql-createextension.html
+func CreateExtensionsInPostgreSQL(
+	ctx context.Context, exec Executor, extensions []v1beta1.PostgresExtensionSpec,
+) error {
+	log := logging.FromContext(ctx)
+
+	specs := make([]map[string]interface{}, 0, len(extensions))
+	for _, extension := range extensions {
+		specs = append(specs, map[string]interface{}{
+			"databases": extensionDatabases(extension),
+			"extension": extension.Name,
+		})
+	}
+
+	spec, err := json.Marshal(specs)
+	if err == nil {
+		var stdout, stderr string
+		stdout, stderr, err = exec.ExecInDatabasesFromQuery(ctx,
+			// Return the names of databases that allow connections, including
+			// "template1". Exclude "template0" to ensure it is never manipulated.
+			// - https://www.postgresql.org/docs/current/managing-databases.html
+			`SELECT datname FROM pg_catalog.pg_database`+
+				` WHERE datallowconn AND datname NOT IN ('template0')`,
+			strings.Join([]string{
+				// Quiet NOTICE messages from IF NOT EXISTS statements.
+				// - https://www.postgresql.org/docs/current/runtime-config-client.html
+				`SET client_min_messages = WARNING;`,
+
+				`SELECT pg_catalog.format('CREATE EXTENSION IF NOT EXISTS %I CASCADE',`,
+				`       pg_catalog.json_extract_path_text(input.data, 'extension'))`,
+				`  FROM pg_catalog.json_array_elements(:'spec') AS input (data)`,
+				` WHERE pg_catalog.json_array_length(pg_catalog.json_extract_path(input.data, 'databases')) = 0`,
+				`    OR pg_catalog.current_database() IN (`,
+				`       SELECT pg_catalog.json_array_elements_text(`,
+				`       pg_catalog.json_extract_path(input.data, 'databases')))`,
+				`\gexec`,
+			}, "\n"),
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+				"spec":          string(spec),
+			})
+
+		log.V(1).Info("created PostgreSQL extensions", "stdout", stdout, "stderr", stderr)
+	}
+
+	return err
+}
+
+// MissingExtensions calls exec to find which of extensions are not available
+// to PostgreSQL, usually because the image does not include them.
+// - https://www.postgresql.org/docs/current/view-pg-available-extensions.html
+func MissingExtensions(
+	ctx context.Context, exec Executor, extensions []v1beta1.PostgresExtensionSpec,
+) ([]string, error) {
+	names := make([]string, 0, len(extensions))
+	for _, extension := range extensions {
+		names = append(names, string(extension.Name))
+	}
+
+	spec, err := json.Marshal(names)
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, stderr, err := exec.Exec(ctx, strings.NewReader(strings.Join([]string{
+		`\pset format unaligned`,
+		`\pset tuples_only on`,
+		`SELECT input.name FROM pg_catalog.json_array_elements_text(:'spec') AS input (name)`,
+		` WHERE input.name NOT IN (SELECT name FROM pg_catalog.pg_available_extensions)`,
+		` ORDER BY 1;`,
+	}, "\n")),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+			"spec":          string(spec),
+		})
+
+	logging.FromContext(ctx).V(1).Info("checked PostgreSQL extensions", "stdout", stdout, "stderr", stderr)
+
+	var missing []string
+	for _, line := range strings.Split(stdout, "\n") {
+		if name := strings.TrimSpace(line); name != "" {
+			missing = append(missing, name)
+		}
+	}
+	return missing, err
+}
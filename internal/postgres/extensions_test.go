@@ -0,0 +1,152 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestExtensionParameters(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+
+	t.Run("Empty", func(t *testing.T) {
+		parameters := NewParameters()
+		ExtensionParameters(cluster, &parameters)
+		assert.Assert(t, !parameters.Mandatory.Has("shared_preload_libraries"))
+	})
+
+	t.Run("Libraries", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Extensions = []v1beta1.PostgresExtensionSpec{
+			{Name: "pg_stat_statements"},
+			{Name: "hstore"},
+			{Name: "pgaudit"},
+			{Name: "pg_cron", Databases: []v1beta1.PostgresIdentifier{"app"}},
+			{Name: "custom", Library: "custom_lib"},
+		}
+
+		parameters := NewParameters()
+		parameters.Mandatory.Add("shared_preload_libraries", "pgaudit")
+		ExtensionParameters(cluster, &parameters)
+
+		assert.Equal(t, parameters.Mandatory.Value("shared_preload_libraries"),
+			"pgaudit,pg_stat_statements,pg_cron,custom_lib")
+		assert.Equal(t, parameters.Default.Value("cron.database_name"), "app")
+	})
+
+	t.Run("TimescaleDB", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Extensions = []v1beta1.PostgresExtensionSpec{
+			{Name: "pg_stat_statements"},
+			{Name: "timescaledb", Databases: []v1beta1.PostgresIdentifier{"metrics"}},
+		}
+
+		parameters := NewParameters()
+		parameters.Mandatory.Add("shared_preload_libraries", "pgaudit")
+		ExtensionParameters(cluster, &parameters)
+
+		assert.Equal(t, parameters.Mandatory.Value("shared_preload_libraries"),
+			"timescaledb,pgaudit,pg_stat_statements")
+		assert.Equal(t, parameters.Default.Value("timescaledb.telemetry_level"), "off")
+	})
+
+	t.Run("CronDefault", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Extensions = []v1beta1.PostgresExtensionSpec{{Name: "pg_cron"}}
+
+		parameters := NewParameters()
+		ExtensionParameters(cluster, &parameters)
+
+		assert.Equal(t, parameters.Mandatory.Value("shared_preload_libraries"), "pg_cron")
+		assert.Equal(t, parameters.Default.Value("cron.database_name"), "postgres")
+	})
+}
+
+func TestCreateExtensionsInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			return expected
+		}
+
+		assert.Equal(t, expected, CreateExtensionsInPostgreSQL(ctx, exec, nil))
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b),
+				`SELECT pg_catalog.format('CREATE EXTENSION IF NOT EXISTS %I CASCADE',`))
+
+			assert.Assert(t, cmp.Contains(strings.Join(command, "\n"),
+				`--set=spec=[{"databases":[],"extension":"hstore"},{"databases":["postgres"],"extension":"pg_cron"},{"databases":["one","two"],"extension":"vector"}]`))
+			return nil
+		}
+
+		assert.NilError(t, CreateExtensionsInPostgreSQL(ctx, exec,
+			[]v1beta1.PostgresExtensionSpec{
+				{Name: "hstore"},
+				{Name: "pg_cron"},
+				{Name: "vector", Databases: []v1beta1.PostgresIdentifier{"one", "two"}},
+			},
+		))
+		assert.Equal(t, calls, 1)
+	})
+}
+
+func TestMissingExtensions(t *testing.T) {
+	ctx := context.Background()
+
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, _ io.Writer, command ...string,
+	) error {
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), `pg_catalog.pg_available_extensions`))
+		assert.Assert(t, cmp.Contains(strings.Join(command, "\n"),
+			`--set=spec=["timescaledb","vector"]`))
+
+		_, err = stdout.Write([]byte("timescaledb\n"))
+		return err
+	}
+
+	missing, err := MissingExtensions(ctx, exec, []v1beta1.PostgresExtensionSpec{
+		{Name: "timescaledb"}, {Name: "vector"},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, missing, []string{"timescaledb"})
+}
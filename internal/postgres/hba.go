@@ -17,7 +17,13 @@ package postgres
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // NewHBAs returns HostBasedAuthentication records required by this package.
@@ -46,8 +52,160 @@ func NewHBAs() HBAs {
 	}
 }
 
+// PasswordHBAs populates outHBAs with rules that verify passwords according to
+// the password types of inCluster and its users. Only when the cluster calls
+// for SCRAM-SHA-256 do the "md5" rules change; they otherwise verify either.
+// - https://www.postgresql.org/docs/current/auth-password.html
+func PasswordHBAs(inCluster *v1beta1.PostgresCluster, outHBAs *HBAs) {
+	if inCluster.Spec.Config.PasswordType != v1beta1.PasswordTypeSCRAM {
+		return
+	}
+
+	// Users that store MD5 passwords can still connect using MD5.
+	rules := []HostBasedAuthentication{}
+	for i := range inCluster.Spec.Users {
+		user := &inCluster.Spec.Users[i]
+		if PasswordType(inCluster, user) == v1beta1.PasswordTypeMD5 {
+			rules = append(rules, *NewHBA().TLS().User(string(user.Name)).Method("md5"))
+		}
+	}
+
+	// Everyone else must use SCRAM-SHA-256.
+	for _, rule := range outHBAs.Default {
+		if rule.method == "md5" {
+			rule.method = "scram-sha-256"
+		}
+		rules = append(rules, rule)
+	}
+	outHBAs.Default = rules
+}
+
+// hbaOptionName matches the names of authentication options in pg_hba.conf.
+var hbaOptionName = regexp.MustCompile(`^[a-z_]+$`)
+
+// SpecifiedHBAs returns the pg_hba.conf lines of the rules in inCluster, in
+// order. Rules without a method or with values that cannot be written on one
+// line are skipped and returned as errors.
+func SpecifiedHBAs(inCluster *v1beta1.PostgresCluster) ([]string, field.ErrorList) {
+	path := field.NewPath("spec", "config", "pg_hba")
+	var errs field.ErrorList
+	var lines []string
+
+	for i, rule := range inCluster.Spec.Config.HBA {
+		if rule.Rule != "" {
+			lines = append(lines, rule.Rule)
+			continue
+		}
+		if rule.Method == "" {
+			errs = append(errs, field.Required(path.Index(i).Child("method"),
+				"either rule or method is required"))
+			continue
+		}
+		if ruleErrs := validateHBARule(path.Index(i), rule); len(ruleErrs) > 0 {
+			errs = append(errs, ruleErrs...)
+			continue
+		}
+
+		hba := NewHBA().TLS().Method(rule.Method)
+		switch rule.Connection {
+		case "local":
+			hba.Local()
+		case "host":
+			hba.TCP()
+		case "hostnossl":
+			hba.NoSSL()
+		}
+		if rule.Address != "" {
+			hba.address = rule.Address
+		}
+		// Keywords lose their meaning when quoted, so leave them bare.
+		// - https://www.postgresql.org/docs/current/auth-pg-hba-conf.html
+		if len(rule.Databases) > 0 {
+			quoted := make([]string, len(rule.Databases))
+			for j, database := range rule.Databases {
+				switch database {
+				case "all", "sameuser", "samerole", "replication":
+					quoted[j] = string(database)
+				default:
+					quoted[j] = hba.quote(string(database))
+				}
+			}
+			hba.database = strings.Join(quoted, ",")
+		}
+		if len(rule.Users) > 0 {
+			quoted := make([]string, len(rule.Users))
+			for j, user := range rule.Users {
+				if user == "all" {
+					quoted[j] = string(user)
+				} else {
+					quoted[j] = hba.quote(string(user))
+				}
+			}
+			hba.user = strings.Join(quoted, ",")
+		}
+
+		// Sort the options so the line is the same every time.
+		keys := make([]string, 0, len(rule.Options))
+		for k := range rule.Options {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		options := make([]string, len(keys))
+		for j, k := range keys {
+			options[j] = k + "=" + hba.quote(rule.Options[k])
+		}
+		hba.options = strings.Join(options, " ")
+
+		lines = append(lines, hba.String())
+	}
+
+	return lines, errs
+}
+
+// validateHBARule returns errors for the values of rule that cannot be written
+// safely into a single line of pg_hba.conf.
+func validateHBARule(path *field.Path, rule v1beta1.PostgresHBARule) field.ErrorList {
+	var errs field.ErrorList
+	multiline := func(s string) bool { return strings.ContainsAny(s, "\r\n") }
+
+	for j, database := range rule.Databases {
+		if multiline(string(database)) {
+			errs = append(errs, field.Invalid(path.Child("databases").Index(j),
+				database, "cannot contain line breaks"))
+		}
+	}
+	for j, user := range rule.Users {
+		if multiline(string(user)) {
+			errs = append(errs, field.Invalid(path.Child("users").Index(j),
+				user, "cannot contain line breaks"))
+		}
+	}
+	keys := make([]string, 0, len(rule.Options))
+	for k := range rule.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if v := rule.Options[k]; !hbaOptionName.MatchString(k) {
+			errs = append(errs, field.Invalid(path.Child("options").Key(k),
+				k, "must contain only lowercase letters and underscores"))
+		} else if multiline(v) {
+			errs = append(errs, field.Invalid(path.Child("options").Key(k),
+				v, "cannot contain line breaks"))
+		}
+	}
+	return errs
+}
+
 // HBAs is a pairing of HostBasedAuthentication records.
-type HBAs struct{ Mandatory, Default []HostBasedAuthentication }
+type HBAs struct {
+	Mandatory, Default []HostBasedAuthentication
+
+	// Idents are lines of pg_ident.conf that map names from a client to the
+	// roles of rules that have a "map" option.
+	// - https://www.postgresql.org/docs/current/auth-username-maps.html
+	Idents []string
+}
 
 // HostBasedAuthentication represents a single record for pg_hba.conf.
 // - https://www.postgresql.org/docs/current/auth-pg-hba-conf.html
@@ -22,6 +22,7 @@ import (
 	"gotest.tools/v3/assert"
 
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestNewHBAs(t *testing.T) {
@@ -51,6 +52,89 @@ hostssl  all  all  all  md5
 	`))
 }
 
+func TestPasswordHBAs(t *testing.T) {
+	printed := func(hbas []HostBasedAuthentication) []string {
+		out := make([]string, len(hbas))
+		for i := range hbas {
+			out[i] = hbas[i].String()
+		}
+		return out
+	}
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.Users = []v1beta1.PostgresUserSpec{
+		{Name: "new"},
+		{Name: "legacy", PasswordType: "MD5"},
+	}
+
+	// Unchanged by default.
+	hba := NewHBAs()
+	PasswordHBAs(cluster, &hba)
+	assert.DeepEqual(t, printed(hba.Default), []string{`hostssl all all all md5`})
+
+	cluster.Spec.Config.PasswordType = "MD5"
+	PasswordHBAs(cluster, &hba)
+	assert.DeepEqual(t, printed(hba.Default), []string{`hostssl all all all md5`})
+
+	// SCRAM-SHA-256 except for users with MD5 passwords.
+	cluster.Spec.Config.PasswordType = "SCRAM-SHA-256"
+	PasswordHBAs(cluster, &hba)
+	assert.DeepEqual(t, printed(hba.Default), []string{
+		`hostssl all "legacy" all md5`,
+		`hostssl all all all scram-sha-256`,
+	})
+	assert.DeepEqual(t, printed(hba.Mandatory), printed(NewHBAs().Mandatory))
+}
+
+func TestSpecifiedHBAs(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+
+	lines, errs := SpecifiedHBAs(cluster)
+	assert.Assert(t, len(lines) == 0)
+	assert.Assert(t, len(errs) == 0)
+
+	cluster.Spec.Config.HBA = []v1beta1.PostgresHBARule{
+		{Rule: `hostgssenc all all 10.0.0.0/8 gss`, Method: "ignored"},
+		{Method: "scram-sha-256"},
+		{Connection: "host", Address: "samenet", Method: "reject"},
+		{Connection: "local", Address: "ignored", Users: []v1beta1.PostgresIdentifier{"a"}, Method: "peer"},
+		{Connection: "nothing"},
+		{
+			Databases: []v1beta1.PostgresIdentifier{"one", `t"wo`},
+			Users:     []v1beta1.PostgresIdentifier{"app"},
+			Address:   "192.168.0.0/16",
+			Method:    "ldap",
+			Options: map[string]string{
+				"ldapserver": "ldap.example.com",
+				"ldapprefix": "cn=",
+			},
+		},
+		{
+			Databases: []v1beta1.PostgresIdentifier{"replication", "all"},
+			Users:     []v1beta1.PostgresIdentifier{"all"},
+			Method:    "scram-sha-256",
+		},
+		{Users: []v1beta1.PostgresIdentifier{"a\nb"}, Method: "trust"},
+		{Method: "cert", Options: map[string]string{"map\nhost": "x"}},
+		{Method: "cert", Options: map[string]string{"map": "x\r\nhost all all all trust"}},
+	}
+
+	lines, errs = SpecifiedHBAs(cluster)
+	assert.DeepEqual(t, lines, []string{
+		`hostgssenc all all 10.0.0.0/8 gss`,
+		`hostssl all all all scram-sha-256`,
+		`host all all samenet reject`,
+		`local all "a" peer`,
+		`hostssl "one","t""wo" "app" 192.168.0.0/16 ldap ldapprefix="cn=" ldapserver="ldap.example.com"`,
+		`hostssl replication,all all all scram-sha-256`,
+	})
+	assert.Equal(t, len(errs), 4)
+	assert.ErrorContains(t, errs[0], "spec.config.pg_hba[4].method")
+	assert.ErrorContains(t, errs[1], "spec.config.pg_hba[7].users[0]")
+	assert.ErrorContains(t, errs[2], "spec.config.pg_hba[8].options[map\nhost]")
+	assert.ErrorContains(t, errs[3], "spec.config.pg_hba[9].options[map]")
+}
+
 func TestHostBasedAuthentication(t *testing.T) {
 	assert.Equal(t, `local all "postgres" peer`,
 		NewHBA().Local().User("postgres").Method("peer").String())
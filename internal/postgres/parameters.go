@@ -16,7 +16,14 @@
 package postgres
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 // NewParameters returns ParameterSets required by this package.
@@ -59,6 +66,106 @@ func NewParameters() Parameters {
 	return parameters
 }
 
+// MaxConnections returns the max_connections of inCluster, which defaults to
+// 100. The value in spec.config.parameters takes precedence over the dynamic
+// configuration of Patroni.
+// - https://www.postgresql.org/docs/current/runtime-config-connection.html
+func MaxConnections(inCluster *v1beta1.PostgresCluster) int32 {
+	connections := int32(100)
+	if inCluster.Spec.Patroni != nil {
+		section, _ := inCluster.Spec.Patroni.DynamicConfiguration["postgresql"].(map[string]interface{})
+		parameters, _ := section["parameters"].(map[string]interface{})
+		if v, ok := parameters["max_connections"]; ok {
+			if i, err := strconv.Atoi(fmt.Sprint(v)); err == nil && i > 0 {
+				connections = int32(i)
+			}
+		}
+	}
+	if v, ok := inCluster.Spec.Config.Parameters["max_connections"]; ok && v.IntValue() > 0 {
+		connections = int32(v.IntValue())
+	}
+	return connections
+}
+
+// MemoryParameters populates outParameters with memory settings sized to the
+// smallest instance set of inCluster when it opts into autotune. Instance sets
+// without a memory limit or request are ignored.
+// - https://www.postgresql.org/docs/current/runtime-config-resource.html#RUNTIME-CONFIG-RESOURCE-MEMORY
+// - https://www.postgresql.org/docs/current/runtime-config-query.html#GUC-EFFECTIVE-CACHE-SIZE
+func MemoryParameters(inCluster *v1beta1.PostgresCluster, outParameters *Parameters) {
+	if inCluster.Spec.Config.AutoTune == nil || !*inCluster.Spec.Config.AutoTune {
+		return
+	}
+
+	var memory *resource.Quantity
+	for i := range inCluster.Spec.InstanceSets {
+		resources := inCluster.Spec.InstanceSets[i].Resources
+		q, ok := resources.Limits[corev1.ResourceMemory]
+		if !ok {
+			q, ok = resources.Requests[corev1.ResourceMemory]
+		}
+		if ok && q.Sign() > 0 && (memory == nil || q.Cmp(*memory) < 0) {
+			memory = &q
+		}
+	}
+	if memory == nil {
+		return
+	}
+
+	// Each connection can use work_mem many times in a query, so divide a
+	// quarter of memory among max_connections.
+	connections := int64(MaxConnections(inCluster))
+
+	kilobytes := memory.Value() / 1024
+	maximum := func(a, b int64) int64 {
+		if a > b {
+			return a
+		}
+		return b
+	}
+	minimum := func(a, b int64) int64 {
+		if a < b {
+			return a
+		}
+		return b
+	}
+
+	// PostgreSQL must be restarted when changing shared_buffers. The others
+	// take effect on reload.
+	outParameters.Default.Add("shared_buffers",
+		fmt.Sprintf("%dkB", maximum(kilobytes/4, 128)))
+	outParameters.Default.Add("effective_cache_size",
+		fmt.Sprintf("%dkB", maximum(kilobytes*3/4, 8)))
+	outParameters.Default.Add("work_mem",
+		fmt.Sprintf("%dkB", maximum(kilobytes/4/connections, 64)))
+	outParameters.Default.Add("maintenance_work_mem",
+		fmt.Sprintf("%dkB", minimum(maximum(kilobytes/16, 1024), 2*1024*1024)))
+}
+
+// PasswordParameters populates outParameters with the password encryption of
+// inCluster. PostgreSQL uses it for passwords that are set without a verifier.
+// - https://www.postgresql.org/docs/current/runtime-config-connection.html#GUC-PASSWORD-ENCRYPTION
+func PasswordParameters(inCluster *v1beta1.PostgresCluster, outParameters *Parameters) {
+	if inCluster.Spec.Config.PasswordType == v1beta1.PasswordTypeMD5 {
+		outParameters.Default.Add("password_encryption", "md5")
+	}
+}
+
+// TimezoneParameters populates outParameters with the time zone of inCluster,
+// if any. Server logs and timestamps then agree with the schedules of backups.
+// PostgreSQL must be reloaded when changing these values.
+// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-TIMEZONE
+// - https://www.postgresql.org/docs/current/runtime-config-logging.html#GUC-LOG-TIMEZONE
+func TimezoneParameters(inCluster *v1beta1.PostgresCluster, outParameters *Parameters) {
+	if tz := inCluster.Spec.Config.Timezone; tz != "" {
+		if outParameters.Mandatory == nil {
+			outParameters.Mandatory = NewParameterSet()
+		}
+		outParameters.Mandatory.Add("timezone", tz)
+		outParameters.Mandatory.Add("log_timezone", tz)
+	}
+}
+
 // Parameters is a pairing of ParameterSets.
 type Parameters struct{ Mandatory, Default *ParameterSet }
 
@@ -19,6 +19,12 @@ import (
 	"testing"
 
 	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestNewParameters(t *testing.T) {
@@ -41,6 +47,145 @@ func TestNewParameters(t *testing.T) {
 	})
 }
 
+func TestMaxConnections(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	assert.Equal(t, MaxConnections(cluster), int32(100))
+
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+		DynamicConfiguration: map[string]interface{}{
+			"postgresql": map[string]interface{}{
+				"parameters": map[string]interface{}{"max_connections": "200"},
+			},
+		},
+	}
+	assert.Equal(t, MaxConnections(cluster), int32(200))
+
+	cluster.Spec.Config.Parameters = map[string]intstr.IntOrString{
+		"max_connections": intstr.FromInt(300),
+	}
+	assert.Equal(t, MaxConnections(cluster), int32(300),
+		"expected spec.config.parameters to take precedence")
+}
+
+func TestMemoryParameters(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+		{Name: "a", Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")},
+		}},
+		{Name: "b", Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("4Gi")},
+		}},
+		{Name: "c"},
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		parameters := NewParameters()
+		MemoryParameters(cluster, &parameters)
+		assert.Assert(t, !parameters.Default.Has("shared_buffers"))
+	})
+
+	t.Run("Smallest", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Config.AutoTune = initialize.Bool(true)
+
+		parameters := NewParameters()
+		MemoryParameters(cluster, &parameters)
+		assert.Equal(t, parameters.Default.Value("shared_buffers"), "1048576kB")
+		assert.Equal(t, parameters.Default.Value("effective_cache_size"), "3145728kB")
+		assert.Equal(t, parameters.Default.Value("work_mem"), "10485kB")
+		assert.Equal(t, parameters.Default.Value("maintenance_work_mem"), "262144kB")
+	})
+
+	t.Run("MaxConnections", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Config.AutoTune = initialize.Bool(true)
+		cluster.Spec.Config.Parameters = map[string]intstr.IntOrString{
+			"max_connections": intstr.FromInt(1000),
+		}
+
+		parameters := NewParameters()
+		MemoryParameters(cluster, &parameters)
+		assert.Equal(t, parameters.Default.Value("work_mem"), "1048kB")
+	})
+
+	t.Run("MaxConnectionsPatroni", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Config.AutoTune = initialize.Bool(true)
+		cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+			DynamicConfiguration: map[string]interface{}{
+				"postgresql": map[string]interface{}{
+					"parameters": map[string]interface{}{"max_connections": 1000},
+				},
+			},
+		}
+
+		parameters := NewParameters()
+		MemoryParameters(cluster, &parameters)
+		assert.Equal(t, parameters.Default.Value("work_mem"), "1048kB")
+	})
+
+	t.Run("Bounds", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.Config.AutoTune = initialize.Bool(true)
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "big", Resources: corev1.ResourceRequirements{
+				Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Gi")},
+			}},
+		}
+
+		parameters := NewParameters()
+		MemoryParameters(cluster, &parameters)
+		assert.Equal(t, parameters.Default.Value("maintenance_work_mem"), "2097152kB")
+
+		cluster.Spec.InstanceSets[0].Resources.Limits[corev1.ResourceMemory] = resource.MustParse("16Mi")
+		MemoryParameters(cluster, &parameters)
+		assert.Equal(t, parameters.Default.Value("work_mem"), "64kB")
+		assert.Equal(t, parameters.Default.Value("maintenance_work_mem"), "1024kB")
+	})
+
+	t.Run("NoMemory", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.Config.AutoTune = initialize.Bool(true)
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "x"}}
+
+		parameters := NewParameters()
+		MemoryParameters(cluster, &parameters)
+		assert.Assert(t, !parameters.Default.Has("shared_buffers"))
+	})
+}
+
+func TestPasswordParameters(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	parameters := NewParameters()
+
+	PasswordParameters(cluster, &parameters)
+	assert.Equal(t, parameters.Default.Value("password_encryption"), "scram-sha-256")
+
+	cluster.Spec.Config.PasswordType = "SCRAM-SHA-256"
+	PasswordParameters(cluster, &parameters)
+	assert.Equal(t, parameters.Default.Value("password_encryption"), "scram-sha-256")
+
+	cluster.Spec.Config.PasswordType = "MD5"
+	PasswordParameters(cluster, &parameters)
+	assert.Equal(t, parameters.Default.Value("password_encryption"), "md5")
+}
+
+func TestTimezoneParameters(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	parameters := Parameters{}
+
+	TimezoneParameters(cluster, &parameters)
+	assert.Assert(t, parameters.Mandatory == nil)
+
+	cluster.Spec.Config.Timezone = "Europe/Berlin"
+	TimezoneParameters(cluster, &parameters)
+	assert.DeepEqual(t, parameters.Mandatory.AsMap(), map[string]string{
+		"log_timezone": "Europe/Berlin",
+		"timezone":     "Europe/Berlin",
+	})
+}
+
 func TestParameterSet(t *testing.T) {
 	ps := NewParameterSet()
 
@@ -17,6 +17,8 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -43,6 +45,15 @@ func WALVolumeMount() corev1.VolumeMount {
 	return corev1.VolumeMount{Name: "postgres-wal", MountPath: walMountPath}
 }
 
+// TablespaceVolumeMount returns the name and mount path of the volume of
+// tablespace.
+func TablespaceVolumeMount(tablespace string) corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      "tablespace-" + tablespace,
+		MountPath: tablespaceMountPath + "/" + tablespace,
+	}
+}
+
 // DownwardAPIVolumeMount returns the name and mount path of the DownwardAPI volume.
 func DownwardAPIVolumeMount() corev1.VolumeMount {
 	return corev1.VolumeMount{
@@ -61,6 +72,28 @@ func AdditionalConfigVolumeMount() corev1.VolumeMount {
 	}
 }
 
+// ClusterConfigMap populates outConfigMap with the custom configuration file
+// of inCluster. It includes every file in the "conf.d" directory of the
+// additional config files, in order of their names.
+func ClusterConfigMap(inCluster *v1beta1.PostgresCluster, outConfigMap *corev1.ConfigMap) {
+	if CustomConfigFile(inCluster) == "" {
+		delete(outConfigMap.Data, customConfigMapKey)
+		return
+	}
+
+	// Patroni includes "postgresql.base.conf" only when there is no custom
+	// configuration file. Include it first here so that settings Patroni
+	// moved there from "postgresql.conf" still apply.
+	// - https://patroni.readthedocs.io/en/latest/yaml_configuration.html#postgresql
+	initialize.StringMap(&outConfigMap.Data)
+	outConfigMap.Data[customConfigMapKey] = strings.Join([]string{
+		`# Generated by postgres-operator. DO NOT EDIT.`,
+		`# Your changes will not be saved.`,
+		fmt.Sprintf(`include_if_exists '%s/postgresql.base.conf'`, ConfigDirectory(inCluster)),
+		fmt.Sprintf(`include_dir '%s/%s'`, configMountPath, includeConfigPath),
+	}, "\n") + "\n"
+}
+
 // InstancePod initializes outInstancePod with the database container and the
 // volumes needed by PostgreSQL.
 func InstancePod(ctx context.Context,
@@ -68,6 +101,7 @@ func InstancePod(ctx context.Context,
 	inInstanceSpec *v1beta1.PostgresInstanceSetSpec,
 	inClusterCertificates, inClientCertificates *corev1.SecretProjection,
 	inDataVolume, inWALVolume *corev1.PersistentVolumeClaim,
+	inTablespaceVolumes []*corev1.PersistentVolumeClaim,
 	outInstancePod *corev1.PodSpec,
 ) {
 	certVolumeMount := corev1.VolumeMount{
@@ -91,6 +125,21 @@ func InstancePod(ctx context.Context,
 		},
 	}
 
+	// Citus nodes connect to one another using a client certificate of the
+	// "postgres" superuser.
+	if inCluster.Spec.Citus != nil {
+		certVolume.Projected.Sources = append(certVolume.Projected.Sources,
+			corev1.VolumeProjection{Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: naming.CitusClientCertSecret(inCluster).Name,
+				},
+				Items: []corev1.KeyToPath{
+					{Key: naming.CitusCert, Path: naming.CitusCertPath},
+					{Key: naming.CitusPrivateKey, Path: naming.CitusPrivateKeyPath},
+				},
+			}})
+	}
+
 	dataVolumeMount := DataVolumeMount()
 	dataVolume := corev1.Volume{
 		Name: dataVolumeMount.Name,
@@ -183,7 +232,7 @@ func InstancePod(ctx context.Context,
 	reloader := corev1.Container{
 		Name: naming.ContainerClientCertCopy,
 
-		Command: reloadCommand(naming.ContainerClientCertCopy),
+		Command: reloadCommand(inCluster, naming.ContainerClientCertCopy),
 
 		Image:           container.Image,
 		ImagePullPolicy: container.ImagePullPolicy,
@@ -209,6 +258,9 @@ func InstancePod(ctx context.Context,
 		Resources:       container.Resources,
 		SecurityContext: initialize.RestrictedSecurityContext(),
 
+		// Keep the reason this container failed in its status.
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+
 		VolumeMounts: []corev1.VolumeMount{certVolumeMount, dataVolumeMount},
 	}
 
@@ -224,6 +276,22 @@ func InstancePod(ctx context.Context,
 		additionalConfigVolume.Projected = &corev1.ProjectedVolumeSource{
 			Sources: append([]corev1.VolumeProjection{}, inCluster.Spec.Config.Files...),
 		}
+
+		// Project the custom configuration file along with the directory it
+		// includes. PostgreSQL ignores the hidden file in that directory, but
+		// it ensures the directory exists.
+		additionalConfigVolume.Projected.Sources = append(
+			additionalConfigVolume.Projected.Sources, corev1.VolumeProjection{
+				ConfigMap: &corev1.ConfigMapProjection{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: naming.ClusterConfigMap(inCluster).Name,
+					},
+					Items: []corev1.KeyToPath{
+						{Key: customConfigMapKey, Path: customConfigPath},
+						{Key: customConfigMapKey, Path: includeConfigPath + "/.postgres-operator"},
+					},
+				},
+			})
 		container.VolumeMounts = append(container.VolumeMounts, additionalConfigVolumeMount)
 		outInstancePod.Volumes = append(outInstancePod.Volumes, additionalConfigVolume)
 	}
@@ -247,6 +315,25 @@ func InstancePod(ctx context.Context,
 		outInstancePod.Volumes = append(outInstancePod.Volumes, walVolume)
 	}
 
+	// Mount each tablespace PVC where the startup command expects it.
+	for i := range inTablespaceVolumes {
+		tablespaceVolumeMount := TablespaceVolumeMount(
+			inTablespaceVolumes[i].Labels[naming.LabelTablespace])
+		tablespaceVolume := corev1.Volume{
+			Name: tablespaceVolumeMount.Name,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: inTablespaceVolumes[i].Name,
+					ReadOnly:  false,
+				},
+			},
+		}
+
+		container.VolumeMounts = append(container.VolumeMounts, tablespaceVolumeMount)
+		startup.VolumeMounts = append(startup.VolumeMounts, tablespaceVolumeMount)
+		outInstancePod.Volumes = append(outInstancePod.Volumes, tablespaceVolume)
+	}
+
 	outInstancePod.Containers = []corev1.Container{container, reloader}
 
 	// If the InstanceSidecars feature gate is enabled and instance sidecars are
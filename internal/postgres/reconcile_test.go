@@ -17,6 +17,7 @@ package postgres
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -118,7 +119,7 @@ func TestInstancePod(t *testing.T) {
 	// without WAL volume nor WAL volume spec
 	pod := new(corev1.PodSpec)
 	InstancePod(ctx, cluster, instance,
-		serverSecretProjection, clientSecretProjection, dataVolume, nil, pod)
+		serverSecretProjection, clientSecretProjection, dataVolume, nil, nil, pod)
 
 	assert.Assert(t, marshalMatches(pod, `
 containers:
@@ -201,7 +202,7 @@ initContainers:
   - -ceu
   - --
   - |-
-    declare -r expected_major_version="$1" pgwal_directory="$2" pgbrLog_directory="$3"
+    declare -r expected_major_version="$1" pgwal_directory="$2" pgbrLog_directory="$3" patroniLog_directory="$4" postgresLog_directory="$5"
     permissions() { while [[ -n "$1" ]]; do set "${1%/*}" "$@"; done; shift; stat -Lc '%A %4u %4g %n' "$@"; }
     halt() { local rc=$?; >&2 echo "$@"; exit "${rc/#0/1}"; }
     results() { printf '::postgres-operator: %s::%s\n' "$@"; }
@@ -239,6 +240,12 @@ initContainers:
     results 'pgBackRest log directory' "${pgbrLog_directory}"
     install --directory --mode=0775 "${pgbrLog_directory}" ||
     halt "$(permissions "${pgbrLog_directory}" ||:)"
+    results 'Patroni log directory' "${patroniLog_directory}"
+    install --directory --mode=0775 "${patroniLog_directory}" ||
+    halt "$(permissions "${patroniLog_directory}" ||:)"
+    results 'PostgreSQL log directory' "${postgresLog_directory}"
+    install --directory --mode=0775 "${postgresLog_directory}" ||
+    halt "$(permissions "${postgresLog_directory}" ||:)"
     install -D --mode=0600 -t "/tmp/replication" "/pgconf/tls/replication"/{tls.crt,tls.key,ca.crt}
     [ -f "${postgres_data_directory}/PG_VERSION" ] || exit 0
     results 'data version' "${postgres_data_version:=$(< "${postgres_data_directory}/PG_VERSION")}"
@@ -253,6 +260,8 @@ initContainers:
   - "11"
   - /pgdata/pg11_wal
   - /pgdata/pgbackrest/log
+  - /pgdata/patroni/log
+  - /pgdata/postgres/log
   env:
   - name: PGDATA
     value: /pgdata/pg11
@@ -277,6 +286,7 @@ initContainers:
     privileged: false
     readOnlyRootFilesystem: true
     runAsNonRoot: true
+  terminationMessagePolicy: FallbackToLogsOnError
   volumeMounts:
   - mountPath: /pgconf/tls
     name: cert-volume
@@ -346,7 +356,7 @@ volumes:
 
 		pod := new(corev1.PodSpec)
 		InstancePod(ctx, cluster, instance,
-			serverSecretProjection, clientSecretProjection, dataVolume, walVolume, pod)
+			serverSecretProjection, clientSecretProjection, dataVolume, walVolume, nil, pod)
 
 		assert.Assert(t, len(pod.Containers) > 0)
 		assert.Assert(t, len(pod.InitContainers) > 0)
@@ -436,11 +446,53 @@ volumes:
 
 		// Startup moves WAL files to data volume.
 		assert.DeepEqual(t, pod.InitContainers[0].Command[4:],
-			[]string{"startup", "11", "/pgdata/pg11_wal", "/pgdata/pgbackrest/log"})
+			[]string{"startup", "11", "/pgdata/pg11_wal", "/pgdata/pgbackrest/log", "/pgdata/patroni/log",
+				"/pgdata/postgres/log"})
+	})
+
+	t.Run("WithTablespaceVolumes", func(t *testing.T) {
+		tablespaceVolume := new(corev1.PersistentVolumeClaim)
+		tablespaceVolume.Name = "tsvol"
+		tablespaceVolume.Labels = map[string]string{
+			"postgres-operator.crunchydata.com/tablespace": "fast",
+		}
+
+		pod := new(corev1.PodSpec)
+		InstancePod(ctx, cluster, instance,
+			serverSecretProjection, clientSecretProjection, dataVolume, nil,
+			[]*corev1.PersistentVolumeClaim{tablespaceVolume}, pod)
+
+		assert.Assert(t, marshalMatches(pod.Containers[0].VolumeMounts, `
+- mountPath: /pgconf/tls
+  name: cert-volume
+  readOnly: true
+- mountPath: /pgdata
+  name: postgres-data
+- mountPath: /etc/database-containerinfo
+  name: database-containerinfo
+  readOnly: true
+- mountPath: /tablespaces/fast
+  name: tablespace-fast`), "expected tablespace mount in %q container", pod.Containers[0].Name)
+
+		assert.Assert(t, marshalMatches(pod.InitContainers[0].VolumeMounts, `
+- mountPath: /pgconf/tls
+  name: cert-volume
+  readOnly: true
+- mountPath: /pgdata
+  name: postgres-data
+- mountPath: /tablespaces/fast
+  name: tablespace-fast`), "expected tablespace mount in %q container", pod.InitContainers[0].Name)
+
+		assert.Assert(t, marshalMatches(pod.Volumes[len(pod.Volumes)-1], `
+name: tablespace-fast
+persistentVolumeClaim:
+  claimName: tsvol
+		`), "expected tablespace volume")
 	})
 
 	t.Run("WithAdditionalConfigFiles", func(t *testing.T) {
 		clusterWithConfig := cluster.DeepCopy()
+		clusterWithConfig.Name = "hippo"
 		clusterWithConfig.Spec.Config.Files = []corev1.VolumeProjection{
 			{
 				Secret: &corev1.SecretProjection{
@@ -453,7 +505,7 @@ volumes:
 
 		pod := new(corev1.PodSpec)
 		InstancePod(ctx, clusterWithConfig, instance,
-			serverSecretProjection, clientSecretProjection, dataVolume, nil, pod)
+			serverSecretProjection, clientSecretProjection, dataVolume, nil, nil, pod)
 
 		assert.Assert(t, len(pod.Containers) > 0)
 		assert.Assert(t, len(pod.InitContainers) > 0)
@@ -480,6 +532,22 @@ volumes:
   readOnly: true
 - mountPath: /pgdata
   name: postgres-data`), "expected WAL mount, no downwardAPI mount in %q container", pod.InitContainers[0].Name)
+
+		// Volume has the files followed by the custom configuration.
+		assert.Assert(t, marshalMatches(pod.Volumes[len(pod.Volumes)-1], `
+name: postgres-config
+projected:
+  sources:
+  - secret:
+      name: keytab
+  - configMap:
+      items:
+      - key: postgres-custom.conf
+        path: ~postgres-operator/custom.conf
+      - key: postgres-custom.conf
+        path: conf.d/.postgres-operator
+      name: hippo-config
+		`))
 	})
 
 	t.Run("WithCustomSidecarContainer", func(t *testing.T) {
@@ -490,7 +558,7 @@ volumes:
 
 		t.Run("SidecarNotEnabled", func(t *testing.T) {
 			InstancePod(ctx, cluster, sidecarInstance,
-				serverSecretProjection, clientSecretProjection, dataVolume, nil, pod)
+				serverSecretProjection, clientSecretProjection, dataVolume, nil, nil, pod)
 
 			assert.Equal(t, len(pod.Containers), 2, "expected 2 containers in Pod, got %d", len(pod.Containers))
 		})
@@ -498,7 +566,7 @@ volumes:
 		t.Run("SidecarEnabled", func(t *testing.T) {
 			assert.NilError(t, util.AddAndSetFeatureGates(string(util.InstanceSidecars+"=true")))
 			InstancePod(ctx, cluster, sidecarInstance,
-				serverSecretProjection, clientSecretProjection, dataVolume, nil, pod)
+				serverSecretProjection, clientSecretProjection, dataVolume, nil, nil, pod)
 
 			assert.Equal(t, len(pod.Containers), 3, "expected 3 containers in Pod, got %d", len(pod.Containers))
 
@@ -522,7 +590,7 @@ volumes:
 
 		pod := new(corev1.PodSpec)
 		InstancePod(ctx, cluster, instance,
-			serverSecretProjection, clientSecretProjection, dataVolume, walVolume, pod)
+			serverSecretProjection, clientSecretProjection, dataVolume, walVolume, nil, pod)
 
 		assert.Assert(t, len(pod.Containers) > 0)
 		assert.Assert(t, len(pod.InitContainers) > 0)
@@ -610,10 +678,36 @@ volumes:
 
 		// Startup moves WAL files to WAL volume.
 		assert.DeepEqual(t, pod.InitContainers[0].Command[4:],
-			[]string{"startup", "11", "/pgwal/pg11_wal", "/pgdata/pgbackrest/log"})
+			[]string{"startup", "11", "/pgwal/pg11_wal", "/pgdata/pgbackrest/log", "/pgdata/patroni/log",
+				"/pgdata/postgres/log"})
 	})
 }
 
+func TestClusterConfigMap(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.PostgresVersion = 14
+	config := new(corev1.ConfigMap)
+
+	ClusterConfigMap(cluster, config)
+	assert.Assert(t, config.Data == nil)
+
+	cluster.Spec.Config.Files = []corev1.VolumeProjection{{
+		ConfigMap: &corev1.ConfigMapProjection{},
+	}}
+	ClusterConfigMap(cluster, config)
+	assert.Equal(t, config.Data["postgres-custom.conf"], strings.TrimLeft(`
+# Generated by postgres-operator. DO NOT EDIT.
+# Your changes will not be saved.
+include_if_exists '/pgdata/pg14/postgresql.base.conf'
+include_dir '/etc/postgres/conf.d'
+`, "\n"))
+
+	cluster.Spec.Config.Files = nil
+	ClusterConfigMap(cluster, config)
+	_, found := config.Data["postgres-custom.conf"]
+	assert.Assert(t, !found)
+}
+
 func TestPodSecurityContext(t *testing.T) {
 	cluster := new(v1beta1.PostgresCluster)
 	cluster.Default()
@@ -0,0 +1,259 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/util"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// quoteQualifiedName quotes each dot-separated part of name, e.g. a table
+// qualified by its schema.
+func quoteQualifiedName(name string) string {
+	parts := strings.Split(name, ".")
+	for i := range parts {
+		parts[i] = util.SQLQuoteIdentifier(parts[i])
+	}
+	return strings.Join(parts, ".")
+}
+
+// LogicalDecodingParameters populates outParameters with enough replication
+// connections and slots for the replicas, permanent slots, and clients of
+// inCluster when it enables logical decoding. The "wal_level" is already
+// "logical" in every cluster. PostgreSQL must be restarted when changing
+// "max_replication_slots" or "max_wal_senders", so these do not follow the
+// number of replicas.
+// - https://www.postgresql.org/docs/current/runtime-config-replication.html
+func LogicalDecodingParameters(inCluster *v1beta1.PostgresCluster, outParameters *Parameters) {
+	if inCluster.Spec.Replication == nil || inCluster.Spec.Replication.LogicalDecoding == nil {
+		return
+	}
+	spec := inCluster.Spec.Replication.LogicalDecoding
+
+	// Reserve the PostgreSQL default of 10 for replicas, then one for each
+	// declared slot and the connections requested for clients.
+	count := int64(10 + len(inCluster.Spec.ReplicationSlots) + len(spec.Slots))
+	if spec.Senders != nil {
+		count += int64(*spec.Senders)
+	} else {
+		count += 10
+	}
+
+	outParameters.Default.Add("max_replication_slots", fmt.Sprint(count))
+	outParameters.Default.Add("max_wal_senders", fmt.Sprint(count))
+
+	// PostgreSQL 13 is the first to invalidate slots that retain too much WAL.
+	// The value is in megabytes, so round up to the next one.
+	// - https://www.postgresql.org/docs/current/runtime-config-replication.html#GUC-MAX-SLOT-WAL-KEEP-SIZE
+	if q := spec.MaxRetainedWAL; q != nil && inCluster.Spec.PostgresVersion >= 13 {
+		megabytes := (q.Value() + 1024*1024 - 1) / (1024 * 1024)
+		outParameters.Default.Add("max_slot_wal_keep_size", fmt.Sprintf("%dMB", megabytes))
+	}
+}
+
+// WritePublicationsInPostgreSQL calls exec to create publications that do not
+// exist in their databases. Once they exist, it sets the tables of those that
+// publish a list of tables.
+// - https://www.postgresql.org/docs/current/sql-createpublication.html
+// - https://www.postgresql.org/docs/current/sql-alterpublication.html
+func WritePublicationsInPostgreSQL(
+	ctx context.Context, exec Executor, publications []v1beta1.PostgresPublicationSpec,
+) error {
+	log := logging.FromContext(ctx)
+
+	var err error
+	var sql bytes.Buffer
+
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+
+	// Quiet NOTICE messages about tables without a replica identity.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html
+	_, _ = sql.WriteString(`SET client_min_messages = WARNING;`)
+
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+	encoder := json.NewEncoder(&sql)
+	encoder.SetEscapeHTML(false)
+
+	for i := range publications {
+		tables := make([]string, len(publications[i].Tables))
+		for j := range publications[i].Tables {
+			tables[j] = quoteQualifiedName(publications[i].Tables[j])
+		}
+
+		if err == nil {
+			err = encoder.Encode(map[string]interface{}{
+				"database": publications[i].Database,
+				"name":     publications[i].Name,
+				"tables":   strings.Join(tables, ", "),
+			})
+		}
+	}
+	_, _ = sql.WriteString(`\.` + "\n")
+
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY VIEW publications AS
+SELECT spec.*, pub.oid IS NOT NULL AS found, pub.puballtables
+  FROM input CROSS JOIN LATERAL pg_catalog.json_to_record(input.data)
+       AS spec (database text, name text, tables text)
+  LEFT JOIN pg_catalog.pg_publication pub ON pub.pubname = spec.name
+ WHERE spec.database = pg_catalog.current_database()
+ ORDER BY input.id;
+
+SELECT pg_catalog.format('CREATE PUBLICATION %I FOR %s', name,
+       CASE WHEN tables = '' THEN 'ALL TABLES' ELSE 'TABLE ' || tables END)
+  FROM publications WHERE NOT found
+\gexec
+
+SELECT pg_catalog.format('ALTER PUBLICATION %I SET TABLE %s', name, tables)
+  FROM publications WHERE found AND NOT puballtables AND tables <> ''
+\gexec
+`)
+
+	if err == nil {
+		var stdout, stderr string
+		stdout, stderr, err = exec.ExecInDatabasesFromQuery(ctx,
+			`SELECT datname FROM pg_catalog.pg_database`+
+				` WHERE datallowconn AND datname NOT IN ('template0')`,
+			sql.String(),
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("wrote PostgreSQL publications", "stdout", stdout, "stderr", stderr)
+	}
+
+	return err
+}
+
+// WriteSubscriptionsInPostgreSQL calls exec to create subscriptions that do
+// not exist in their databases. Once they exist, it sets their connection,
+// publications, and whether or not they are enabled. The connection string
+// of each subscription is in connections by subscription name. Creating a
+// subscription connects to its publisher.
+// - https://www.postgresql.org/docs/current/sql-createsubscription.html
+// - https://www.postgresql.org/docs/current/sql-altersubscription.html
+func WriteSubscriptionsInPostgreSQL(
+	ctx context.Context, exec Executor,
+	subscriptions []v1beta1.PostgresSubscriptionSpec, connections map[string]string,
+) error {
+	log := logging.FromContext(ctx)
+
+	var err error
+	var sql bytes.Buffer
+
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+	_, _ = sql.WriteString(`SET client_min_messages = WARNING;`)
+
+	// Pass the connection strings through stdin rather than psql variables;
+	// they often contain passwords.
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+	encoder := json.NewEncoder(&sql)
+	encoder.SetEscapeHTML(false)
+
+	for i := range subscriptions {
+		spec := subscriptions[i]
+
+		names := make([]string, len(spec.Publications))
+		quoted := make([]string, len(spec.Publications))
+		for j := range spec.Publications {
+			names[j] = string(spec.Publications[j])
+			quoted[j] = util.SQLQuoteIdentifier(names[j])
+		}
+
+		if err == nil {
+			err = encoder.Encode(map[string]interface{}{
+				"connection":   connections[string(spec.Name)],
+				"database":     spec.Database,
+				"enabled":      spec.Enabled == nil || *spec.Enabled,
+				"name":         spec.Name,
+				"names":        names,
+				"publications": strings.Join(quoted, ", "),
+			})
+		}
+	}
+	_, _ = sql.WriteString(`\.` + "\n")
+
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY VIEW subscriptions AS
+SELECT spec.*, sub.oid IS NOT NULL AS found,
+       sub.subconninfo, sub.subenabled,
+       ARRAY(SELECT pg_catalog.unnest(sub.subpublications) ORDER BY 1) AS current_names,
+       ARRAY(SELECT pg_catalog.json_array_elements_text(spec.names) ORDER BY 1) AS desired_names
+  FROM input CROSS JOIN LATERAL pg_catalog.json_to_record(input.data)
+       AS spec (connection text, database text, enabled boolean,
+                name text, names json, publications text)
+  LEFT JOIN pg_catalog.pg_subscription sub ON sub.subname = spec.name
+        AND sub.subdbid = (SELECT oid FROM pg_catalog.pg_database
+                            WHERE datname = pg_catalog.current_database())
+ WHERE spec.database = pg_catalog.current_database()
+ ORDER BY input.id;
+
+SELECT pg_catalog.format('CREATE SUBSCRIPTION %I CONNECTION %L PUBLICATION %s WITH (enabled = %s)',
+       name, connection, publications, enabled)
+  FROM subscriptions WHERE NOT found
+\gexec
+
+SELECT pg_catalog.format('ALTER SUBSCRIPTION %I CONNECTION %L', name, connection)
+  FROM subscriptions WHERE found AND subconninfo IS DISTINCT FROM connection
+\gexec
+
+SELECT pg_catalog.format('ALTER SUBSCRIPTION %I %s', name,
+       CASE WHEN enabled THEN 'ENABLE' ELSE 'DISABLE' END)
+  FROM subscriptions WHERE found AND subenabled <> enabled
+\gexec
+
+-- PostgreSQL 11 changed the syntax of "SET PUBLICATION".
+SELECT CASE
+       WHEN pg_catalog.current_setting('server_version_num')::integer < 110000
+       THEN pg_catalog.format('ALTER SUBSCRIPTION %I SET PUBLICATION %s %s', name, publications,
+            CASE WHEN enabled THEN 'REFRESH' ELSE 'SKIP REFRESH' END)
+       ELSE pg_catalog.format('ALTER SUBSCRIPTION %I SET PUBLICATION %s WITH (refresh = %s)',
+            name, publications, enabled)
+       END
+  FROM subscriptions WHERE found AND current_names <> desired_names
+\gexec
+`)
+
+	if err == nil {
+		var stdout, stderr string
+		stdout, stderr, err = exec.ExecInDatabasesFromQuery(ctx,
+			`SELECT datname FROM pg_catalog.pg_database`+
+				` WHERE datallowconn AND datname NOT IN ('template0')`,
+			sql.String(),
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("wrote PostgreSQL subscriptions", "stdout", stdout, "stderr", stderr)
+	}
+
+	return err
+}
@@ -0,0 +1,196 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestLogicalDecodingParameters(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.PostgresVersion = 14
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+		{Name: "one", Replicas: initialize.Int32(2)},
+		{Name: "two"},
+	}
+
+	parameters := NewParameters()
+	LogicalDecodingParameters(cluster, &parameters)
+	assert.Assert(t, !parameters.Default.Has("max_replication_slots"))
+	assert.Assert(t, !parameters.Default.Has("max_wal_senders"))
+
+	cluster.Spec.Replication = &v1beta1.PostgresReplicationSpec{
+		LogicalDecoding: &v1beta1.LogicalDecodingSpec{
+			Slots: []v1beta1.LogicalDecodingSlotSpec{{Name: "cdc", Database: "app"}},
+		},
+	}
+	LogicalDecodingParameters(cluster, &parameters)
+	assert.Equal(t, parameters.Default.Value("max_replication_slots"), "21")
+	assert.Equal(t, parameters.Default.Value("max_wal_senders"), "21")
+	assert.Assert(t, !parameters.Default.Has("max_slot_wal_keep_size"))
+
+	t.Run("Replicas", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.InstanceSets[0].Replicas = initialize.Int32(5)
+
+		// Scaling does not require PostgreSQL to restart.
+		parameters := NewParameters()
+		LogicalDecodingParameters(cluster, &parameters)
+		assert.Equal(t, parameters.Default.Value("max_replication_slots"), "21")
+		assert.Equal(t, parameters.Default.Value("max_wal_senders"), "21")
+	})
+
+	t.Run("Senders", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Replication.LogicalDecoding.Senders = initialize.Int32(0)
+
+		parameters := NewParameters()
+		LogicalDecodingParameters(cluster, &parameters)
+		assert.Equal(t, parameters.Default.Value("max_replication_slots"), "11")
+		assert.Equal(t, parameters.Default.Value("max_wal_senders"), "11")
+	})
+
+	t.Run("MaxRetainedWAL", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		q := resource.MustParse("1500Ki")
+		cluster.Spec.Replication.LogicalDecoding.MaxRetainedWAL = &q
+
+		parameters := NewParameters()
+		LogicalDecodingParameters(cluster, &parameters)
+		assert.Equal(t, parameters.Default.Value("max_slot_wal_keep_size"), "2MB")
+
+		cluster.Spec.PostgresVersion = 12
+		parameters = NewParameters()
+		LogicalDecodingParameters(cluster, &parameters)
+		assert.Assert(t, !parameters.Default.Has("max_slot_wal_keep_size"))
+	})
+}
+
+func TestWritePublicationsInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			return expected
+		}
+
+		assert.Equal(t, expected, WritePublicationsInPostgreSQL(ctx, exec, nil))
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), strings.Join([]string{
+				`{"database":"app","name":"everything","tables":""}`,
+				`{"database":"app","name":"some","tables":"\"orders\", \"sales\".\"Items\""}`,
+				`\.`,
+			}, "\n")))
+			assert.Assert(t, cmp.Contains(string(b), `'CREATE PUBLICATION %I FOR %s'`))
+			assert.Assert(t, cmp.Contains(string(b), `'ALTER PUBLICATION %I SET TABLE %s'`))
+
+			// Every database is considered.
+			assert.Assert(t, cmp.Contains(strings.Join(command, "\n"), `pg_catalog.pg_database`))
+			return nil
+		}
+
+		assert.NilError(t, WritePublicationsInPostgreSQL(ctx, exec,
+			[]v1beta1.PostgresPublicationSpec{
+				{Name: "everything", Database: "app"},
+				{Name: "some", Database: "app", Tables: []string{"orders", "sales.Items"}},
+			},
+		))
+		assert.Equal(t, calls, 1)
+	})
+}
+
+func TestWriteSubscriptionsInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			return expected
+		}
+
+		assert.Equal(t, expected, WriteSubscriptionsInPostgreSQL(ctx, exec, nil, nil))
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), strings.Join([]string{
+				`{"connection":"host=one password=secret","database":"app","enabled":true,"name":"first","names":["pub"],"publications":"\"pub\""}`,
+				`{"connection":"host=two","database":"app","enabled":false,"name":"second","names":["a","b"],"publications":"\"a\", \"b\""}`,
+				`\.`,
+			}, "\n")))
+			assert.Assert(t, cmp.Contains(string(b), `'CREATE SUBSCRIPTION %I CONNECTION %L PUBLICATION %s WITH (enabled = %s)'`))
+
+			// Connection strings are not in the command line.
+			assert.Assert(t, !strings.Contains(strings.Join(command, "\n"), "secret"))
+			return nil
+		}
+
+		assert.NilError(t, WriteSubscriptionsInPostgreSQL(ctx, exec,
+			[]v1beta1.PostgresSubscriptionSpec{
+				{
+					Name: "first", Database: "app",
+					Publications: []v1beta1.PostgresIdentifier{"pub"},
+					Connection:   corev1.SecretKeySelector{Key: "uri"},
+				},
+				{
+					Name: "second", Database: "app",
+					Publications: []v1beta1.PostgresIdentifier{"a", "b"},
+					Enabled:      initialize.Bool(false),
+				},
+			},
+			map[string]string{"first": "host=one password=secret", "second": "host=two"},
+		))
+		assert.Equal(t, calls, 1)
+	})
+}
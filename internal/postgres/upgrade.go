@@ -0,0 +1,105 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// UpgradeCommand returns an entrypoint that upgrades the data of instance from
+// PostgreSQL major version from to the version of cluster. It runs pg_upgrade
+// in link mode, so the old data directory cannot be used after it succeeds.
+// - https://www.postgresql.org/docs/current/pgupgrade.html
+func UpgradeCommand(
+	cluster *v1beta1.PostgresCluster, instance *v1beta1.PostgresInstanceSetSpec, from int,
+) []string {
+	older := cluster.DeepCopy()
+	older.Spec.PostgresVersion = from
+
+	args := []string{
+		fmt.Sprintf("/usr/pgsql-%d/bin", from),
+		fmt.Sprintf("/usr/pgsql-%d/bin", cluster.Spec.PostgresVersion),
+		DataDirectory(older),
+		DataDirectory(cluster),
+		WALDirectory(cluster, instance),
+
+		// Initialize the new data directory the same way Patroni does, so
+		// pg_upgrade finds matching encodings and locales.
+		"--encoding=UTF8",
+	}
+
+	if locale := cluster.Spec.Config.Locale; locale != nil {
+		if locale.Locale != "" {
+			args = append(args, "--locale="+locale.Locale)
+		}
+		if locale.Provider != "" {
+			args = append(args, "--locale-provider="+locale.Provider)
+		}
+		if locale.ICULocale != "" {
+			args = append(args, "--icu-locale="+locale.ICULocale)
+		}
+	}
+
+	script := strings.Join([]string{
+		`declare -r old_bindir="$1" new_bindir="$2" old_datadir="$3" new_datadir="$4" new_waldir="$5"`,
+		`shift 5`,
+
+		// Function to print a message to stderr then exit non-zero.
+		bashHalt,
+
+		// Function to log values in a basic structured format.
+		`results() { printf '::postgres-operator: %s::%s\n' "$@"; }`,
+
+		`echo Upgrading ...`,
+		`results 'uid' "$(id -u)" 'gid' "$(id -G)"`,
+		`results 'old data directory' "${old_datadir}"`,
+		`results 'new data directory' "${new_datadir}"`,
+		`[[ -f "${old_datadir}/PG_VERSION" ]] || halt Expected data in "${old_datadir}"`,
+
+		// In link mode, pg_upgrade renames the old control file before it
+		// links any data files. After that, the old data directory cannot
+		// start and the upgrade cannot be attempted again.
+		`[[ ! -f "${old_datadir}/global/pg_control.old" ]] ||`,
+		`halt Expected an old data directory that pg_upgrade has not linked`,
+
+		// Remove anything left by an earlier attempt.
+		`rm -rf "${new_datadir}" "${new_waldir}"`,
+
+		// Data page checksums must be the same in both directories.
+		`if "${old_bindir}/pg_controldata" "${old_datadir}" | grep -q '^Data page checksum version: *[1-9]'`,
+		`then set -- --data-checksums "$@"; fi`,
+
+		`results 'initdb options' "$*"`,
+		`"${new_bindir}/initdb" --pgdata="${new_datadir}" --waldir="${new_waldir}" --username=postgres "$@"`,
+
+		// The old configuration files are written by Patroni and refer to
+		// files that are not mounted here. Start the old server without them
+		// and authenticate like the new server does.
+		// pg_upgrade writes its logs into the current directory.
+		`cd "${new_datadir%/*}"`,
+		`"${new_bindir}/pg_upgrade" --link --username=postgres \`,
+		`  --old-bindir="${old_bindir}" --old-datadir="${old_datadir}" \`,
+		`  --new-bindir="${new_bindir}" --new-datadir="${new_datadir}" \`,
+		`  --old-options="-c config_file=/dev/null -c hba_file=${new_datadir}/pg_hba.conf -c ident_file=${new_datadir}/pg_ident.conf"`,
+
+		`echo Upgrade complete`,
+	}, "\n")
+
+	return append([]string{"bash", "-ceu", "--", script, "upgrade"}, args...)
+}
@@ -0,0 +1,82 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/testing/require"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestUpgradeCommand(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.PostgresVersion = 14
+	instance := new(v1beta1.PostgresInstanceSetSpec)
+
+	command := UpgradeCommand(cluster, instance, 12)
+
+	// Expect a bash command with an inline script and its arguments.
+	assert.DeepEqual(t, command[:3], []string{"bash", "-ceu", "--"})
+	assert.DeepEqual(t, command[4:], []string{
+		"upgrade",
+		"/usr/pgsql-12/bin",
+		"/usr/pgsql-14/bin",
+		"/pgdata/pg12",
+		"/pgdata/pg14",
+		"/pgdata/pg14_wal",
+		"--encoding=UTF8",
+	})
+
+	t.Run("ShellCheck", func(t *testing.T) {
+		shellcheck := require.ShellCheck(t)
+
+		// Write out that inline script.
+		dir := t.TempDir()
+		file := filepath.Join(dir, "script.bash")
+		assert.NilError(t, os.WriteFile(file, []byte(command[3]), 0o600))
+
+		// Expect shellcheck to be happy.
+		cmd := exec.Command(shellcheck, "--enable=all", "--shell=bash", file)
+		output, err := cmd.CombinedOutput()
+		assert.NilError(t, err, "%q\n%s", cmd.Args, output)
+	})
+
+	t.Run("Locale", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Config.Locale = &v1beta1.PostgresLocaleSpec{Locale: "C.UTF-8"}
+
+		instance := instance.DeepCopy()
+		instance.WALVolumeClaimSpec = new(corev1.PersistentVolumeClaimSpec)
+
+		command := UpgradeCommand(cluster, instance, 13)
+		assert.DeepEqual(t, command[5:], []string{
+			"/usr/pgsql-13/bin",
+			"/usr/pgsql-14/bin",
+			"/pgdata/pg13",
+			"/pgdata/pg14",
+			"/pgwal/pg14_wal",
+			"--encoding=UTF8",
+			"--locale=C.UTF-8",
+		})
+	})
+}
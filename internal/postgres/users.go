@@ -24,6 +24,18 @@ import (
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
+// PasswordType returns how PostgreSQL should store the password of user: the
+// password type of user, then that of cluster, then SCRAM-SHA-256.
+func PasswordType(cluster *v1beta1.PostgresCluster, user *v1beta1.PostgresUserSpec) string {
+	if user != nil && user.PasswordType != "" {
+		return user.PasswordType
+	}
+	if cluster.Spec.Config.PasswordType != "" {
+		return cluster.Spec.Config.PasswordType
+	}
+	return v1beta1.PasswordTypeSCRAM
+}
+
 // WriteUsersInPostgreSQL calls exec to create users that do not exist in
 // PostgreSQL. Once they exist, it updates their options and passwords and
 // grants them access to their specified databases. The databases must already
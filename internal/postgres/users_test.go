@@ -28,6 +28,20 @@ import (
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
+func TestPasswordType(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	user := new(v1beta1.PostgresUserSpec)
+
+	assert.Equal(t, PasswordType(cluster, nil), "SCRAM-SHA-256")
+	assert.Equal(t, PasswordType(cluster, user), "SCRAM-SHA-256")
+
+	cluster.Spec.Config.PasswordType = "MD5"
+	assert.Equal(t, PasswordType(cluster, user), "MD5")
+
+	user.PasswordType = "SCRAM-SHA-256"
+	assert.Equal(t, PasswordType(cluster, user), "SCRAM-SHA-256")
+}
+
 func TestWriteUsersInPostgreSQL(t *testing.T) {
 	ctx := context.Background()
 
@@ -0,0 +1,54 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package walg
+
+import (
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// Enabled returns whether or not cluster archives WAL using WAL-G rather than
+// pgBackRest.
+func Enabled(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.Backups.WALG != nil
+}
+
+// PostgreSQL populates outParameters with any settings needed to run WAL-G.
+func PostgreSQL(
+	inCluster *v1beta1.PostgresCluster,
+	outParameters *postgres.Parameters,
+) {
+	if outParameters.Mandatory == nil {
+		outParameters.Mandatory = postgres.NewParameterSet()
+	}
+	if outParameters.Default == nil {
+		outParameters.Default = postgres.NewParameterSet()
+	}
+
+	// Send WAL files to storage when not in recovery.
+	// - https://github.com/wal-g/wal-g/blob/master/docs/PostgreSQL.md#wal-push
+	// - https://www.postgresql.org/docs/current/runtime-config-wal.html
+	outParameters.Mandatory.Add("archive_mode", "on")
+	outParameters.Mandatory.Add("archive_command", `wal-g wal-push "%p"`)
+
+	// Switch WAL files at least once a minute, the same as pgBackRest.
+	// - https://www.postgresql.org/docs/current/runtime-config-wal.html#GUC-ARCHIVE-TIMEOUT
+	outParameters.Default.Add("archive_timeout", "60s")
+
+	// Fetch WAL files from storage during recovery.
+	// - https://github.com/wal-g/wal-g/blob/master/docs/PostgreSQL.md#wal-fetch
+	outParameters.Mandatory.Add("restore_command", `wal-g wal-fetch "%f" "%p"`)
+}
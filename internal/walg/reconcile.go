@@ -0,0 +1,137 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package walg
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// InstancePod populates the database container of outInstancePod with the
+// environment WAL-G needs. PostgreSQL passes it to archive_command and
+// restore_command.
+func InstancePod(
+	inCluster *v1beta1.PostgresCluster,
+	outInstancePod *corev1.PodSpec,
+) {
+	for i := range outInstancePod.Containers {
+		if outInstancePod.Containers[i].Name == naming.ContainerDatabase {
+			outInstancePod.Containers[i].Env = append(
+				outInstancePod.Containers[i].Env, inCluster.Spec.Backups.WALG.Env...)
+		}
+	}
+}
+
+// ReplicaCreateCommand returns the command that can initialize the PostgreSQL
+// data directory on an instance from the latest WAL-G backup. Patroni writes a
+// standby signal file after it succeeds.
+// - https://github.com/wal-g/wal-g/blob/master/docs/PostgreSQL.md#backup-fetch
+func ReplicaCreateCommand() []string {
+	return []string{
+		"bash", "-ceu", "--", `exec wal-g backup-fetch "${PGDATA?}" LATEST`,
+	}
+}
+
+// RestoreTarget returns the recovery parameters that stop replaying WAL at the
+// target of dataSource. It returns nil when dataSource has no target. The
+// target fields of dataSource should already be valid together.
+// - https://www.postgresql.org/docs/current/runtime-config-wal.html#RUNTIME-CONFIG-WAL-RECOVERY-TARGET
+func RestoreTarget(dataSource *v1beta1.PostgresClusterDataSource) []string {
+	// https://www.postgresql.org/docs/current/config-setting.html
+	quote := func(s string) string { return `'` + strings.ReplaceAll(s, `'`, `''`) + `'` }
+
+	kind := dataSource.TargetType
+	if kind == "" && dataSource.TargetTime != nil {
+		kind = "time"
+	}
+
+	var target string
+	switch kind {
+	case "":
+		return nil
+	case "immediate":
+		target = "recovery_target = 'immediate'"
+	case "time":
+		target = "recovery_target_time = " +
+			quote(dataSource.TargetTime.UTC().Format("2006-01-02 15:04:05+00"))
+	default:
+		target = "recovery_target_" + kind + " = " + quote(dataSource.Target)
+	}
+
+	// Promote at the target like pgBackRest restores do.
+	return []string{target, "recovery_target_action = 'promote'"}
+}
+
+// RestoreCommand returns the command for a restore Job. It restores the WAL-G
+// backup named backup into pgdata, then starts PostgreSQL and waits while it
+// replays archived WAL files, through the end of the archive or until the
+// recovery parameters in target stop it. It stops PostgreSQL and renames the
+// data directory for the "existing" bootstrap method of Patroni.
+func RestoreCommand(pgdata, backup string, target []string) []string {
+
+	// PostgreSQL 12 replaced "recovery.conf" with "recovery.signal" and
+	// ordinary parameters. In either case, it promotes itself at the end of
+	// the archive. Some parameters cannot be smaller than they were when
+	// PostgreSQL was backed up. Configure them to match the values reported
+	// by "pg_controldata".
+	// - https://www.postgresql.org/docs/current/continuous-archiving.html#BACKUP-PITR-RECOVERY
+	// - https://www.postgresql.org/docs/current/hot-standby.html
+	const script = `declare -r pgdata="$1" backup="$2"
+shift 2
+install --directory --mode=0700 "${pgdata}"
+rm -f "${pgdata}/postmaster.pid"
+wal-g backup-fetch "${pgdata}" "${backup}"
+rm -f "${pgdata}/patroni.dynamic.json"
+export PGDATA="${pgdata}" PGHOST='/tmp'
+
+control=$(pg_controldata)
+read -r max_conn <<< "${control##*max_connections setting:}"
+read -r max_lock <<< "${control##*max_locks_per_xact setting:}"
+read -r max_ptxn <<< "${control##*max_prepared_xacts setting:}"
+read -r max_work <<< "${control##*max_worker_processes setting:}"
+echo > /tmp/pg_hba.restore.conf 'local all "postgres" peer'
+cat > /tmp/postgres.restore.conf <<EOF
+archive_command = 'false'
+archive_mode = 'on'
+hba_file = '/tmp/pg_hba.restore.conf'
+max_connections = '${max_conn}'
+max_locks_per_transaction = '${max_lock}'
+max_prepared_transactions = '${max_ptxn}'
+max_worker_processes = '${max_work}'
+unix_socket_directories = '/tmp'
+EOF
+
+restore="restore_command = 'wal-g wal-fetch \"%f\" \"%p\"'"
+if [ "$(< "${pgdata}/PG_VERSION")" -ge 12 ]; then
+read -r max_wals <<< "${control##*max_wal_senders setting:}"
+echo >> /tmp/postgres.restore.conf "max_wal_senders = '${max_wals}'"
+printf '%s\n' "${restore}" "$@" >> /tmp/postgres.restore.conf
+touch "${pgdata}/recovery.signal"
+else
+printf '%s\n' "${restore}" "$@" > "${pgdata}/recovery.conf"
+fi
+
+pg_ctl start --silent --timeout=31536000 --wait --options='--config-file=/tmp/postgres.restore.conf'
+until [ "$(psql -Atc 'SELECT pg_catalog.pg_is_in_recovery()' || true)" = 'f' ]; do sleep 1; done
+pg_ctl stop --silent --wait --timeout=31536000
+mv "${pgdata}" "${pgdata}_bootstrap"`
+
+	return append([]string{"bash", "-ceu", "--", script, "-", pgdata, backup}, target...)
+}
@@ -0,0 +1,123 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package walg
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/require"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestInstancePod(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.Backups.WALG = &v1beta1.WALGArchive{
+		Env: []corev1.EnvVar{{Name: "WALG_S3_PREFIX", Value: "s3://bucket/path"}},
+	}
+
+	pod := new(corev1.PodSpec)
+	pod.Containers = []corev1.Container{
+		{Name: naming.ContainerDatabase, Env: []corev1.EnvVar{{Name: "PGDATA"}}},
+		{Name: "other"},
+	}
+
+	InstancePod(cluster, pod)
+
+	assert.DeepEqual(t, pod.Containers[0].Env, []corev1.EnvVar{
+		{Name: "PGDATA"},
+		{Name: "WALG_S3_PREFIX", Value: "s3://bucket/path"},
+	})
+	assert.Assert(t, pod.Containers[1].Env == nil)
+}
+
+func TestReplicaCreateCommand(t *testing.T) {
+	shellcheck := require.ShellCheck(t)
+	command := ReplicaCreateCommand()
+
+	assert.DeepEqual(t, command[:3], []string{"bash", "-ceu", "--"})
+	assert.Equal(t, len(command), 4)
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "script.bash")
+	assert.NilError(t, os.WriteFile(file, []byte(command[3]), 0o600))
+
+	cmd := exec.Command(shellcheck, "--enable=all", "--shell=bash", file)
+	output, err := cmd.CombinedOutput()
+	assert.NilError(t, err, "%q\n%s", cmd.Args, output)
+}
+
+func TestRestoreCommand(t *testing.T) {
+	shellcheck := require.ShellCheck(t)
+	command := RestoreCommand("/pgdata/pg13", "LATEST", nil)
+
+	assert.DeepEqual(t, command[:3], []string{"bash", "-ceu", "--"})
+	assert.DeepEqual(t, command[4:], []string{"-", "/pgdata/pg13", "LATEST"})
+
+	command = RestoreCommand("/pgdata/pg13", "LATEST", []string{"a = 'b'"})
+	assert.DeepEqual(t, command[4:], []string{"-", "/pgdata/pg13", "LATEST", "a = 'b'"})
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "script.bash")
+	assert.NilError(t, os.WriteFile(file, []byte(command[3]), 0o600))
+
+	cmd := exec.Command(shellcheck, "--enable=all", "--shell=bash", file)
+	output, err := cmd.CombinedOutput()
+	assert.NilError(t, err, "%q\n%s", cmd.Args, output)
+}
+
+func TestRestoreTarget(t *testing.T) {
+	t.Run("None", func(t *testing.T) {
+		assert.Assert(t, RestoreTarget(&v1beta1.PostgresClusterDataSource{}) == nil)
+	})
+
+	t.Run("Immediate", func(t *testing.T) {
+		assert.DeepEqual(t, RestoreTarget(&v1beta1.PostgresClusterDataSource{
+			TargetType: "immediate",
+		}), []string{
+			"recovery_target = 'immediate'",
+			"recovery_target_action = 'promote'",
+		})
+	})
+
+	t.Run("Time", func(t *testing.T) {
+		when := metav1.NewTime(time.Date(2022, 6, 1, 12, 30, 0, 0, time.FixedZone("", -5*60*60)))
+
+		assert.DeepEqual(t, RestoreTarget(&v1beta1.PostgresClusterDataSource{
+			TargetTime: &when,
+		}), []string{
+			"recovery_target_time = '2022-06-01 17:30:00+00'",
+			"recovery_target_action = 'promote'",
+		})
+	})
+
+	t.Run("Name", func(t *testing.T) {
+		assert.DeepEqual(t, RestoreTarget(&v1beta1.PostgresClusterDataSource{
+			TargetType: "name", Target: "it's here",
+		}), []string{
+			"recovery_target_name = 'it''s here'",
+			"recovery_target_action = 'promote'",
+		})
+	})
+}
@@ -0,0 +1,35 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+// CitusSpec defines a Citus cluster managed through Patroni. Every instance
+// set is one Citus group: group zero is the coordinator and every other group
+// is a worker. The image must provide the Citus extension.
+//
+// Nodes connect to one another as the "postgres" superuser using a client
+// certificate. Other roles that run distributed queries need credentials in
+// the pg_dist_authinfo table. pgBackRest archives and backs up only the
+// coordinator; workers rely on their replicas for high availability.
+// More info: https://patroni.readthedocs.io/en/latest/citus.html
+type CitusSpec struct {
+
+	// The database in which Patroni creates the Citus extension and registers
+	// worker nodes. This value cannot change after the cluster is created.
+	// Defaults to "citus".
+	// +kubebuilder:default=citus
+	// +optional
+	Database PostgresIdentifier `json:"database,omitempty"`
+}
@@ -0,0 +1,116 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import corev1 "k8s.io/api/core/v1"
+
+// HAProxyPodSpec defines the desired state of HAProxy routing connections to
+// PostgreSQL. HAProxy asks the Patroni REST API of each instance whether it
+// is the primary or a replica. It does not pool connections.
+type HAProxyPodSpec struct {
+	// +optional
+	Metadata *Metadata `json:"metadata,omitempty"`
+
+	// Scheduling constraints of an HAProxy pod. Changing this value causes
+	// HAProxy to restart.
+	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/assign-pod-node
+	// +optional
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// Name of a container image that can run HAProxy 2.2 or newer as a
+	// non-root user. Changing this value causes HAProxy to restart. The image
+	// may also be set using the RELATED_IMAGE_HAPROXY environment variable.
+	// More info: https://kubernetes.io/docs/concepts/containers/images
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Port on which HAProxy should listen for connections to the primary.
+	// Changing this value causes HAProxy to restart.
+	// +optional
+	// +kubebuilder:default=5432
+	// +kubebuilder:validation:Minimum=1024
+	Port *int32 `json:"port,omitempty"`
+
+	// Port on which HAProxy should listen for connections to replicas.
+	// Changing this value causes HAProxy to restart.
+	// +optional
+	// +kubebuilder:default=5433
+	// +kubebuilder:validation:Minimum=1024
+	ReplicaPort *int32 `json:"replicaPort,omitempty"`
+
+	// Priority class name for the HAProxy pod. Changing this value causes
+	// HAProxy to restart.
+	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/pod-priority-preemption/
+	// +optional
+	PriorityClassName *string `json:"priorityClassName,omitempty"`
+
+	// Number of desired HAProxy pods.
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Compute resources of an HAProxy container. Changing this value causes
+	// HAProxy to restart.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Specification of the service that exposes HAProxy. The nodePort applies
+	// to connections to the primary.
+	// +optional
+	Service *ServiceSpec `json:"service,omitempty"`
+
+	// Tolerations of an HAProxy pod. Changing this value causes HAProxy to
+	// restart.
+	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/taint-and-toleration
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Topology spread constraints of an HAProxy pod. Changing this value
+	// causes HAProxy to restart.
+	// More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-topology-spread-constraints/
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+}
+
+// Default sets the default ports (5432 and 5433) and replicas (1) of HAProxy
+// when they are not explicitly set.
+func (s *HAProxyPodSpec) Default() {
+	if s.Port == nil {
+		s.Port = new(int32)
+		*s.Port = 5432
+	}
+
+	if s.ReplicaPort == nil {
+		s.ReplicaPort = new(int32)
+		*s.ReplicaPort = 5433
+	}
+
+	if s.Replicas == nil {
+		s.Replicas = new(int32)
+		*s.Replicas = 1
+	}
+}
+
+type HAProxyPodStatus struct {
+
+	// Total number of ready pods.
+	ReadyReplicas int32 `json:"readyReplicas,omitempty"`
+
+	// Total number of non-terminated pods.
+	Replicas int32 `json:"replicas,omitempty"`
+}
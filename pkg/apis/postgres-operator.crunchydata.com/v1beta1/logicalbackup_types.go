@@ -0,0 +1,120 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LogicalBackups defines exports of PostgreSQL databases that run on a
+// schedule. They are portable SQL and pg_dump archives that complement the
+// physical backups of pgBackRest. An export can also run on demand by creating
+// a Job from its CronJob, e.g.
+// `kubectl create job --from=cronjob/{cluster}-logical-backup`.
+type LogicalBackups struct {
+
+	// The schedule of exports in Cron format.
+	// More info: https://k8s.io/docs/concepts/workloads/controllers/cron-jobs/#cron-schedule-syntax
+	// +kubebuilder:validation:MinLength=6
+	Schedule string `json:"schedule"`
+
+	// Databases to export with pg_dump, each to its own archive in the custom
+	// format. Roles and tablespaces are exported alongside them. When empty,
+	// every database is exported with pg_dumpall.
+	// More info: https://www.postgresql.org/docs/current/app-pgdump.html
+	// +listType=set
+	// +optional
+	Databases []PostgresIdentifier `json:"databases,omitempty"`
+
+	// Defines a PersistentVolumeClaim in which to keep exports. Each export is
+	// a directory named for its Job. When this is not set, exports are written
+	// to a temporary volume that is removed after the upload. One or both of
+	// volumeClaimSpec and upload is required.
+	// +optional
+	VolumeClaimSpec *corev1.PersistentVolumeClaimSpec `json:"volumeClaimSpec,omitempty"`
+
+	// The number of exports to keep in the volume. Older exports are removed
+	// after a new one succeeds. When this is not set, every export is kept.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Retain *int32 `json:"retain,omitempty"`
+
+	// Defines a container that runs after each export, e.g. to copy it to
+	// object storage.
+	// +optional
+	Upload *LogicalBackupUpload `json:"upload,omitempty"`
+
+	// Compute resources of the pg_dump container.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// LogicalBackupUpload defines a container that sends an export elsewhere. The
+// directory of the export is in its PGDUMP_DIRECTORY environment variable.
+type LogicalBackupUpload struct {
+
+	// The image of the upload container, e.g. one that provides the AWS CLI.
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// The command of the upload container, e.g.
+	// `["sh", "-c", "aws s3 cp --recursive \"${PGDUMP_DIRECTORY}\" s3://bucket/hippo/"]`.
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+
+	// Environment variables of the upload container, such as credentials for
+	// object storage.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Compute resources of the upload container.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// LogicalBackupStatus represents the latest Job created for logical backups.
+type LogicalBackupStatus struct {
+
+	// The name of the Job.
+	// +kubebuilder:validation:Required
+	JobName string `json:"jobName"`
+
+	// Represents the time the Job was acknowledged by the Job controller.
+	// It is represented in RFC3339 form and is in UTC.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Represents the time the Job was determined by the Job controller to be
+	// completed. This field is only set if the Job completed successfully.
+	// It is represented in RFC3339 form and is in UTC.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The number of actively running Pods.
+	// +optional
+	Active int32 `json:"active,omitempty"`
+
+	// The number of Pods that reached the "Succeeded" phase.
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty"`
+
+	// The number of Pods that reached the "Failed" phase.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+}
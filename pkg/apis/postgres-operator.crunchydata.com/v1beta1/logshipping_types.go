@@ -0,0 +1,48 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import corev1 "k8s.io/api/core/v1"
+
+// LogShippingSpec runs Fluent Bit in each PostgreSQL instance Pod to forward
+// PostgreSQL and pgBackRest logs as structured records.
+// More info: https://docs.fluentbit.io/manual/administration/configuring-fluent-bit/classic-mode
+type LogShippingSpec struct {
+
+	// The image name to use for the log shipper. Defaults to the value of the
+	// RELATED_IMAGE_FLUENT_BIT environment variable.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Fluent Bit configuration files that define where records go. These files
+	// are included after the inputs and filters managed by PGO and must contain
+	// at least one [OUTPUT] section. Records are tagged "postgres" and
+	// "pgbackrest".
+	// +kubebuilder:validation:MinItems=1
+	Configuration []corev1.VolumeProjection `json:"configuration"`
+
+	// The format of PostgreSQL log files, either "csvlog" or "jsonlog". The
+	// "jsonlog" format requires PostgreSQL 15 or later; older versions use
+	// "csvlog". Defaults to csvlog.
+	// +kubebuilder:validation:Enum={csvlog,jsonlog}
+	// +optional
+	Format string `json:"format,omitempty"`
+
+	// Changing this value causes PostgreSQL and the log shipper to restart.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
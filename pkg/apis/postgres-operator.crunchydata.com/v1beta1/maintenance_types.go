@@ -0,0 +1,160 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MaintenanceSpec defines routine maintenance that runs on a schedule.
+type MaintenanceSpec struct {
+
+	// Maintenance jobs to run on a schedule. Each job runs in its own CronJob.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Jobs []MaintenanceJobSpec `json:"jobs,omitempty"`
+}
+
+// MaintenanceJobSpec defines a single scheduled maintenance job. Any of these
+// can also run on demand by creating a Job from its CronJob, e.g.
+// `kubectl create job --from=cronjob/{cluster}-maintenance-{name}`.
+type MaintenanceJobSpec struct {
+
+	// The name of this job. The value may contain only lowercase letters,
+	// numbers, and hyphen so that it fits into Kubernetes metadata.
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=20
+	Name string `json:"name"`
+
+	// The schedule of this job in Cron format.
+	// More info: https://k8s.io/docs/concepts/workloads/controllers/cron-jobs/#cron-schedule-syntax
+	// +kubebuilder:validation:MinLength=6
+	Schedule string `json:"schedule"`
+
+	// The kind of maintenance to run. "Amcheck" calls pg_amcheck to verify the
+	// structure of tables and B-tree indexes, which requires PostgreSQL 14 or
+	// newer; it is usually best to target a replica. "Analyze", "Vacuum", and
+	// "VacuumAnalyze" call vacuumdb. "Reindex" calls reindexdb with its
+	// concurrently option, which requires PostgreSQL 12 or newer. "Repack"
+	// creates the pg_repack extension, when necessary, and calls pg_repack to
+	// remove bloat while holding exclusive locks only briefly; the image must
+	// provide pg_repack. "SQL" runs the statements in the sql field.
+	// Jobs connect as the "_crunchymaintenance" user, which is not a superuser.
+	// Grant it the roles that own the tables involved. Owning the database is
+	// enough for "Analyze", "Vacuum", and "VacuumAnalyze"; since PostgreSQL 17,
+	// pg_maintain is enough for those and "Reindex". Jobs that call vacuumdb,
+	// reindexdb, or pg_repack fail without running when it cannot maintain
+	// every selected table.
+	// More info: https://www.postgresql.org/docs/current/app-pgamcheck.html
+	// More info: https://reorg.github.io/pg_repack/
+	// +kubebuilder:validation:Enum={Amcheck,Analyze,Vacuum,VacuumAnalyze,Reindex,Repack,SQL}
+	Type string `json:"type"`
+
+	// The database in which to run. Defaults to "postgres".
+	// +kubebuilder:default=postgres
+	// +optional
+	Database PostgresIdentifier `json:"database,omitempty"`
+
+	// Tables on which to run. When empty, the job applies to the entire
+	// database. This field is ignored when type is "SQL".
+	// +listType=set
+	// +optional
+	Tables []string `json:"tables,omitempty"`
+
+	// The instances against which to run: the "primary" or any "replica".
+	// Replicas are read-only, so only SQL that does not write can run there.
+	// Defaults to "primary".
+	// +kubebuilder:default=primary
+	// +kubebuilder:validation:Enum={primary,replica}
+	// +optional
+	Target string `json:"target,omitempty"`
+
+	// Whether or not an "Amcheck" job also verifies that every heap tuple has
+	// a matching index entry. This finds more corruption but takes longer.
+	// +optional
+	HeapAllIndexed bool `json:"heapAllIndexed,omitempty"`
+
+	// SQL statements to run when type is "SQL". Statements run outside of a
+	// transaction block and stop at the first error.
+	// +optional
+	SQL string `json:"sql,omitempty"`
+
+	// Compute resources of the maintenance container.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// MaintenanceJobSpec types.
+const (
+	MaintenanceAmcheck       = "Amcheck"
+	MaintenanceAnalyze       = "Analyze"
+	MaintenanceReindex       = "Reindex"
+	MaintenanceRepack        = "Repack"
+	MaintenanceSQL           = "SQL"
+	MaintenanceVacuum        = "Vacuum"
+	MaintenanceVacuumAnalyze = "VacuumAnalyze"
+)
+
+// MaintenanceStatus represents the observed state of scheduled maintenance.
+type MaintenanceStatus struct {
+
+	// Identifies the revision of maintenance assets that have been installed
+	// into PostgreSQL.
+	// +optional
+	PostgreSQLRevision string `json:"postgresRevision,omitempty"`
+
+	// The Jobs that remain from scheduled maintenance.
+	// +optional
+	Jobs []MaintenanceJobStatus `json:"jobs,omitempty"`
+}
+
+// MaintenanceJobStatus represents a Job created for scheduled maintenance.
+type MaintenanceJobStatus struct {
+
+	// The name of the maintenance job in the spec.
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// The name of the Job.
+	// +kubebuilder:validation:Required
+	JobName string `json:"jobName"`
+
+	// Represents the time the Job was acknowledged by the Job controller.
+	// It is represented in RFC3339 form and is in UTC.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// Represents the time the Job was determined by the Job controller to be
+	// completed. This field is only set if the Job completed successfully.
+	// It is represented in RFC3339 form and is in UTC.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The number of actively running Pods.
+	// +optional
+	Active int32 `json:"active,omitempty"`
+
+	// The number of Pods that reached the "Succeeded" phase.
+	// +optional
+	Succeeded int32 `json:"succeeded,omitempty"`
+
+	// The number of Pods that reached the "Failed" phase.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+}
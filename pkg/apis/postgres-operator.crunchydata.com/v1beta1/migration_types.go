@@ -0,0 +1,41 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import corev1 "k8s.io/api/core/v1"
+
+// MigrationSpec runs a container that migrates the schema of databases, such
+// as Flyway or golang-migrate, against the primary.
+type MigrationSpec struct {
+
+	// The container that migrates databases. It runs in a Job once PostgreSQL
+	// accepts writes and again whenever this value changes. It can connect to
+	// the primary using the Secret of a user, e.g. the "uri" key of the
+	// "<cluster>-pguser-<user>" Secret.
+	// +kubebuilder:validation:Required
+	Container corev1.Container `json:"container"`
+
+	// Whether or not to run the container again after an in-place restore.
+	// Defaults to false.
+	// +optional
+	RunAfterRestore *bool `json:"runAfterRestore,omitempty"`
+
+	// The number of retries before the migration is considered failed.
+	// Defaults to 2.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+}
@@ -15,7 +15,41 @@
 
 package v1beta1
 
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
 type PatroniSpec struct {
+	// Specification of a Service that exposes only the Patroni REST API of
+	// every instance to tools outside the PostgresCluster. A client certificate
+	// that the REST API trusts is stored in the "<cluster>-patroni-api-client"
+	// Secret along with the certificate authority. That certificate is signed
+	// by the root certificate authority of the operator, so every cluster of
+	// the operator accepts it for "unsafe" endpoints, such as switchover and
+	// restart. Share it only with tools trusted with all those clusters, and
+	// set apiAuthentication so that this cluster also requires its own
+	// credentials.
+	// More info: https://patroni.readthedocs.io/en/latest/rest_api.html
+	// +optional
+	APIService *PatroniAPIServiceSpec `json:"apiService,omitempty"`
+
+	// The OpenSSL cipher list that the Patroni REST API accepts, e.g.
+	// "ECDHE+AESGCM:!DHE" to allow only FIPS-compatible suites. When omitted,
+	// Patroni uses the defaults of its TLS library.
+	// More info: https://patroni.readthedocs.io/en/latest/yaml_configuration.html#rest-api
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	APICiphers string `json:"apiCiphers,omitempty"`
+
+	// Basic authentication that the Patroni REST API requires, along with a
+	// client certificate, when calling "unsafe" endpoints.
+	// More info: https://patroni.readthedocs.io/en/latest/security.html#protecting-the-rest-api
+	// +optional
+	APIAuthentication *PatroniAPIAuthentication `json:"apiAuthentication,omitempty"`
+
 	// Patroni dynamic configuration settings. Changes to this value will be
 	// automatically reloaded without validation. Changes to certain PostgreSQL
 	// parameters cause PostgreSQL to restart.
@@ -49,16 +83,169 @@ type PatroniSpec struct {
 	// +kubebuilder:validation:Minimum=1
 	SyncPeriodSeconds *int32 `json:"syncPeriodSeconds,omitempty"`
 
+	// How a former primary rejoins the cluster after failover.
+	// +optional
+	Rewind *PatroniRewind `json:"rewind,omitempty"`
+
+	// How long to retry Kubernetes API and PostgreSQL operations before
+	// demoting the primary. The sum of syncPeriodSeconds and twice this value
+	// must be less than leaderLeaseDurationSeconds.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	RetryTimeoutSeconds *int32 `json:"retryTimeoutSeconds,omitempty"`
+
+	// Whether or not Patroni should manage synchronous replication. When set,
+	// this takes precedence over "synchronous_mode" in dynamicConfiguration.
+	// More info: https://patroni.readthedocs.io/en/latest/replication_modes.html#synchronous-mode
+	// +optional
+	SynchronousMode *bool `json:"synchronousMode,omitempty"`
+
+	// Whether or not writes should stop when no synchronous standby is
+	// available. When set, this takes precedence over "synchronous_mode_strict"
+	// in dynamicConfiguration.
+	// +optional
+	SynchronousModeStrict *bool `json:"synchronousModeStrict,omitempty"`
+
+	// The number of synchronous standbys Patroni should maintain. When set,
+	// this takes precedence over "synchronous_node_count" in dynamicConfiguration.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	SynchronousNodeCount *int32 `json:"synchronousNodeCount,omitempty"`
+
+	// Scripts that Patroni runs when PostgreSQL starts, stops, or changes role.
+	// +optional
+	Callbacks *PatroniCallbacks `json:"callbacks,omitempty"`
+
+	// How Patroni creates the data directory of a new replica.
+	// +optional
+	CreateReplica *PatroniCreateReplica `json:"createReplica,omitempty"`
+
+	// The maximum amount of WAL a replica can be behind the primary and still
+	// be eligible to become leader during automatic failover.
+	// More info: https://patroni.readthedocs.io/en/latest/SETTINGS.html#dynamic-configuration-settings
+	// +optional
+	MaximumLagOnFailover *resource.Quantity `json:"maximumLagOnFailover,omitempty"`
+
+	// Patroni logging configuration. When omitted, Patroni logs to stderr.
+	// +optional
+	Logging *PatroniLogConfig `json:"logging,omitempty"`
+
+	// How long to wait for a failed primary to recover before initiating
+	// failover. Zero means failover as soon as possible.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	PrimaryStartTimeoutSeconds *int32 `json:"primaryStartTimeoutSeconds,omitempty"`
+
+	// Whether or not Patroni should stop managing PostgreSQL, including automatic
+	// failover. This is Patroni's maintenance mode; PostgreSQL keeps running.
+	// More info: https://patroni.readthedocs.io/en/latest/pause.html
+	// +optional
+	Paused *bool `json:"paused,omitempty"`
+
 	// Switchover gives options to perform ad hoc switchovers in a PostgresCluster.
 	// +optional
 	Switchover *PatroniSwitchover `json:"switchover,omitempty"`
 
-	// TODO(cbandy): Add UseConfigMaps bool, default false.
+	// Whether or not Patroni should store its distributed configuration and
+	// leader lock in ConfigMaps rather than Endpoints. Use this where Pods are
+	// not allowed to modify Endpoints. When enabled, the primary Service selects
+	// the leader Pod by its labels. This cannot be changed after the cluster
+	// is created.
+	// - https://patroni.readthedocs.io/en/latest/kubernetes.html
+	// +optional
+	UseConfigMaps *bool `json:"useConfigMaps,omitempty"`
+
 	// TODO(cbandy): Allow other DCS: etcd, raft, etc?
 	// N.B. changing this will cause downtime.
 	// - https://patroni.readthedocs.io/en/latest/kubernetes.html
 }
 
+// PatroniCallbacks are scripts in ConfigMaps that Patroni runs with three
+// arguments: the action, the new role, and the cluster scope.
+// More info: https://patroni.readthedocs.io/en/latest/yaml_configuration.html#postgresql
+type PatroniCallbacks struct {
+
+	// A script to run when PostgreSQL is promoted or demoted.
+	// +optional
+	OnRoleChange *corev1.ConfigMapKeySelector `json:"onRoleChange,omitempty"`
+
+	// A script to run when PostgreSQL starts.
+	// +optional
+	OnStart *corev1.ConfigMapKeySelector `json:"onStart,omitempty"`
+
+	// A script to run when PostgreSQL stops.
+	// +optional
+	OnStop *corev1.ConfigMapKeySelector `json:"onStop,omitempty"`
+}
+
+type PatroniCreateReplica struct {
+
+	// The methods Patroni tries, in order, to create a replica. Patroni tries
+	// the next method when one fails. A "pgbackrest" method is skipped when the
+	// cluster has no pgBackRest repository, and a "walg" method is skipped when
+	// the cluster does not archive using WAL-G. Defaults to the archive method
+	// followed by "basebackup".
+	// More info: https://patroni.readthedocs.io/en/latest/replica_bootstrap.html
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:Enum={pgbackrest,walg,basebackup}
+	Methods []string `json:"methods,omitempty"`
+
+	// Additional long options, without leading dashes, that Patroni passes to
+	// pg_basebackup, e.g. "max-rate=100M" or "checkpoint=fast".
+	// More info: https://www.postgresql.org/docs/current/app-pgbasebackup.html
+	// +optional
+	BasebackupOptions []string `json:"basebackupOptions,omitempty"`
+}
+
+// PatroniLogConfig configures Patroni to write its logs to files in the data
+// volume rather than stderr.
+type PatroniLogConfig struct {
+
+	// Limits the total amount of space Patroni log files use in the data
+	// volume. Patroni keeps two files, each up to half this size. Must be
+	// greater than zero.
+	// +required
+	StorageLimit *resource.Quantity `json:"storageLimit"`
+
+	// The Patroni log level. Defaults to INFO.
+	// More info: https://docs.python.org/3/library/logging.html#levels
+	// +optional
+	// +kubebuilder:validation:Enum={CRITICAL,ERROR,WARNING,INFO,DEBUG,NOTSET}
+	Level *string `json:"level,omitempty"`
+
+	// The format of Patroni log messages. The "json" format requires Patroni
+	// v3.2 or later. Defaults to plain.
+	// +optional
+	// +kubebuilder:validation:Enum={plain,json}
+	Format *string `json:"format,omitempty"`
+}
+
+// PatroniRewind configures how Patroni uses pg_rewind when a former primary
+// rejoins the cluster.
+// More info: https://patroni.readthedocs.io/en/latest/yaml_configuration.html#postgresql
+type PatroniRewind struct {
+
+	// Whether or not a former primary should rewind to rejoin the cluster,
+	// discarding commits that were not sent to a replica. When disabled, it
+	// must be reinitialized instead. Ignored for PostgreSQL 10 and earlier,
+	// which cannot rewind. Defaults to true.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Whether or not Patroni should remove the data directory, and so create
+	// the replica again, when pg_rewind fails.
+	// +optional
+	RemoveDataDirectoryOnFailure *bool `json:"removeDataDirectoryOnFailure,omitempty"`
+
+	// Whether or not Patroni should remove the data directory, and so create
+	// the replica again, when its timeline has diverged from the primary and
+	// rewind is disabled.
+	// +optional
+	RemoveDataDirectoryOnDivergedTimelines *bool `json:"removeDataDirectoryOnDivergedTimelines,omitempty"`
+}
+
 type PatroniSwitchover struct {
 
 	// Whether or not the operator should allow switchovers in a PostgresCluster
@@ -80,6 +267,25 @@ type PatroniSwitchover struct {
 	// +kubebuilder:default:=Switchover
 	// +optional
 	Type string `json:"type,omitempty"`
+
+	// When a switchover is allowed to happen. A switchover requested outside
+	// this window waits for it to open. Failovers ignore the window.
+	// +optional
+	Window *PatroniSwitchoverWindow `json:"window,omitempty"`
+}
+
+// PatroniSwitchoverWindow is a recurring period of time for planned switchovers.
+type PatroniSwitchoverWindow struct {
+
+	// When the window opens in Cron format. The schedule is interpreted in
+	// the time zone of spec.config.timezone, which defaults to UTC.
+	// More info: https://k8s.io/docs/concepts/workloads/controllers/cron-jobs/#cron-schedule-syntax
+	// +kubebuilder:validation:MinLength=6
+	Schedule string `json:"schedule"`
+
+	// How long the window stays open, in seconds.
+	// +kubebuilder:validation:Minimum=60
+	DurationSeconds int32 `json:"durationSeconds"`
 }
 
 // PatroniSwitchover types.
@@ -88,6 +294,32 @@ const (
 	PatroniSwitchoverTypeSwitchover = "Switchover"
 )
 
+// PatroniAPIAuthentication identifies the credentials of the Patroni REST API.
+type PatroniAPIAuthentication struct {
+
+	// The name of a Secret in the namespace of the PostgresCluster that has
+	// "username" and "password" keys. When omitted, credentials are generated
+	// and stored in the "<cluster>-patroni-api-auth" Secret.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// PatroniAPIServiceSpec defines a Service that exposes the Patroni REST API.
+type PatroniAPIServiceSpec struct {
+	ServiceSpec `json:",inline"`
+
+	// Sources that are allowed to connect to the Patroni REST API. When set,
+	// a NetworkPolicy limits connections to PostgreSQL instances: Pods of the
+	// PostgresCluster can connect to any port, anything can connect to every
+	// port other than Patroni, and only these sources can connect to Patroni.
+	// This requires Kubernetes 1.22 or newer and a network plugin that enforces
+	// NetworkPolicy port ranges.
+	// More info: https://kubernetes.io/docs/concepts/services-networking/network-policies/
+	// +optional
+	AllowedSources []networkingv1.NetworkPolicyPeer `json:"allowedSources,omitempty"`
+}
+
 // Default sets the default values for certain Patroni configuration attributes,
 // including:
 // - Lock Lease Duration
@@ -118,6 +350,11 @@ type PatroniStatus struct {
 	// +optional
 	SystemIdentifier string `json:"systemIdentifier,omitempty"`
 
+	// The name of the instance that Patroni most recently labeled as its
+	// leader, the primary.
+	// +optional
+	Primary string `json:"primary,omitempty"`
+
 	// Tracks the execution of the switchover requests.
 	// +optional
 	Switchover *string `json:"switchover,omitempty"`
@@ -125,4 +362,65 @@ type PatroniStatus struct {
 	// Tracks the current timeline during switchovers
 	// +optional
 	SwitchoverTimeline *int64 `json:"switchoverTimeline,omitempty"`
+
+	// The switchover request that is waiting for its window to open.
+	// +optional
+	SwitchoverQueued *string `json:"switchoverQueued,omitempty"`
+
+	// The members of the Patroni cluster as last reported by Patroni.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Members []PatroniMemberStatus `json:"members,omitempty"`
+
+	// The most recent changes of timeline, such as failovers and switchovers,
+	// as recorded by Patroni. Oldest first.
+	// +optional
+	// +kubebuilder:validation:MaxItems=10
+	History []PatroniHistoryStatus `json:"history,omitempty"`
+}
+
+type PatroniHistoryStatus struct {
+	// The timeline that ended.
+	// +required
+	Timeline int64 `json:"timeline"`
+
+	// Why the timeline ended, as written by PostgreSQL.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// When the timeline ended, if Patroni recorded it.
+	// +optional
+	Time *metav1.Time `json:"time,omitempty"`
+
+	// The member that became the leader, if Patroni recorded it.
+	// +optional
+	Leader string `json:"leader,omitempty"`
+}
+
+type PatroniMemberStatus struct {
+	// Name of the member. This is the name of its Pod.
+	// +required
+	Name string `json:"name"`
+
+	// The role of the member, e.g. "Leader", "Replica", or "Sync Standby".
+	// +optional
+	Role string `json:"role,omitempty"`
+
+	// The state of PostgreSQL on the member, e.g. "running" or "streaming".
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// The PostgreSQL timeline of the member.
+	// +optional
+	Timeline *int64 `json:"timeline,omitempty"`
+
+	// How far behind the leader the member is, in megabytes of WAL.
+	// +optional
+	LagMegabytes *int64 `json:"lagMegabytes,omitempty"`
+
+	// Whether or not a parameter change requires PostgreSQL on this member to
+	// be restarted.
+	// +optional
+	PendingRestart bool `json:"pendingRestart,omitempty"`
 }
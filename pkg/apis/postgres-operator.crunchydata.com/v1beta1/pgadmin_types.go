@@ -75,6 +75,12 @@ type PGAdminPodSpec struct {
 	// +optional
 	PriorityClassName *string `json:"priorityClassName,omitempty"`
 
+	// Specification of an Ingress that routes HTTP traffic from outside the
+	// Kubernetes cluster to the pgAdmin Service.
+	// More info: https://kubernetes.io/docs/concepts/services-networking/ingress/
+	// +optional
+	Ingress *PGAdminIngressSpec `json:"ingress,omitempty"`
+
 	// Number of desired pgAdmin pods.
 	// +optional
 	// +kubebuilder:default=1
@@ -104,6 +110,28 @@ type PGAdminPodSpec struct {
 	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
 }
 
+// PGAdminIngressSpec defines an Ingress that exposes pgAdmin.
+type PGAdminIngressSpec struct {
+	// +optional
+	Metadata *Metadata `json:"metadata,omitempty"`
+
+	// Name of the IngressClass that should implement this Ingress. When
+	// omitted, the default IngressClass of the Kubernetes cluster is used.
+	// More info: https://kubernetes.io/docs/concepts/services-networking/ingress/#ingress-class
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// Fully qualified domain name of the network host that routes to pgAdmin.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+
+	// Name of a Secret of type kubernetes.io/tls that contains a certificate
+	// for Host. When set, TLS is terminated by the Ingress controller.
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+}
+
 // Default sets the port and replica count for pgAdmin if not set
 func (s *PGAdminPodSpec) Default() {
 	if s.Replicas == nil {
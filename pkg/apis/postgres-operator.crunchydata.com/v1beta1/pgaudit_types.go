@@ -0,0 +1,53 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+// PGAuditSpec configures the session and object audit logging of pgAudit.
+// pgAudit is always loaded; these settings control what it logs. Audit
+// records are written to the PostgreSQL server log with an "AUDIT:" prefix.
+// pgAudit has no log of its own, so there is no separate audit file or volume.
+// More info: https://github.com/pgaudit/pgaudit#settings
+type PGAuditSpec struct {
+
+	// Classes of statements to log by session audit logging. When empty,
+	// session audit logging is disabled.
+	// +listType=set
+	// +optional
+	Log []PGAuditLogClass `json:"log,omitempty"`
+
+	// The role that grants object audit logging. Statements are logged when
+	// this role has privileges on the objects they use. The role is not created.
+	// +optional
+	Role PostgresIdentifier `json:"role,omitempty"`
+
+	// Whether or not to log the parameters of statements. Defaults to false.
+	// +optional
+	LogParameter *bool `json:"logParameter,omitempty"`
+
+	// Whether or not to log a separate entry for every relation in a SELECT
+	// or DML statement. Defaults to false.
+	// +optional
+	LogRelation *bool `json:"logRelation,omitempty"`
+
+	// Whether or not to log statements when every relation is in pg_catalog.
+	// Defaults to true.
+	// +optional
+	LogCatalog *bool `json:"logCatalog,omitempty"`
+}
+
+// PGAuditLogClass is a class of statements that pgAudit can log.
+// +kubebuilder:validation:Enum={READ,WRITE,FUNCTION,ROLE,DDL,MISC,MISC_SET,ALL}
+type PGAuditLogClass string
@@ -17,6 +17,7 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -109,11 +110,21 @@ type PGBackRestArchive struct {
 
 	// Global pgBackRest configuration settings.  These settings are included in the "global"
 	// section of the pgBackRest configuration generated by the PostgreSQL Operator, and then
-	// mounted under "/etc/pgbackrest/conf.d":
+	// mounted under "/etc/pgbackrest/conf.d". They can change defaults such as "repo1-path" but
+	// not the settings the PostgreSQL Operator requires, such as the repository host and its
+	// certificates. Earlier versions let these settings replace required ones; those are now
+	// ignored:
 	// https://pgbackrest.org/configuration.html
 	// +optional
 	Global map[string]string `json:"global,omitempty"`
 
+	// Settings that make PostgreSQL instances push and get WAL asynchronously
+	// using multiple processes. The spool of queued WAL files is kept on the
+	// PostgreSQL data volume.
+	// More info: https://pgbackrest.org/user-guide.html#async-archiving
+	// +optional
+	ArchiveAsync *PGBackRestArchiveAsync `json:"archiveAsync,omitempty"`
+
 	// The image name to use for pgBackRest containers.  Utilized to run
 	// pgBackRest repository hosts and backups. The image may also be set using
 	// the RELATED_IMAGE_PGBACKREST environment variable
@@ -144,11 +155,38 @@ type PGBackRestArchive struct {
 	// +optional
 	Restore *PGBackRestRestore `json:"restore,omitempty"`
 
+	// Defines a scheduled restore of the latest backup that proves the backups
+	// can be restored.
+	// +optional
+	RestoreDrill *PGBackRestRestoreDrill `json:"restoreDrill,omitempty"`
+
 	// Configuration for pgBackRest sidecar containers
 	// +optional
 	Sidecars *PGBackRestSidecars `json:"sidecars,omitempty"`
 }
 
+// PGBackRestArchiveAsync defines asynchronous archiving by pgBackRest.
+type PGBackRestArchiveAsync struct {
+
+	// The number of processes that push or get WAL files at the same time.
+	// Defaults to 1.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ProcessMax *int32 `json:"processMax,omitempty"`
+
+	// The amount of WAL that may wait to be pushed before pgBackRest drops it
+	// to protect PostgreSQL from a full disk. Dropped WAL breaks point-in-time
+	// recovery until the next backup. When omitted, WAL is never dropped.
+	// More info: https://pgbackrest.org/configuration.html#section-archive/option-archive-push-queue-max
+	// +optional
+	PushQueueMax *resource.Quantity `json:"pushQueueMax,omitempty"`
+
+	// The amount of WAL that replicas and restores fetch ahead of recovery.
+	// More info: https://pgbackrest.org/configuration.html#section-archive/option-archive-get-queue-max
+	// +optional
+	GetQueueMax *resource.Quantity `json:"getQueueMax,omitempty"`
+}
+
 // PGBackRestSidecars defines the configuration for pgBackRest sidecar containers
 type PGBackRestSidecars struct {
 	// Defines the configuration for the pgBackRest sidecar container
@@ -180,6 +218,14 @@ type BackupJobs struct {
 	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/taint-and-toleration
 	// +optional
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// Limits the lifetime of a backup Job that has finished. Kubernetes
+	// deletes the Job and its pods this many seconds after it completes or
+	// fails. The operator keeps the outcome of each backup in status.
+	// More info: https://kubernetes.io/docs/concepts/workloads/controllers/ttlafterfinished/
+	// +kubebuilder:validation:Minimum=60
+	// +optional
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
 }
 
 // PGBackRestManualBackup contains information that is used for creating a
@@ -247,6 +293,37 @@ type PGBackRestRestore struct {
 	*PostgresClusterDataSource `json:",inline"`
 }
 
+// PGBackRestRestoreDrill defines a Job that runs on a schedule, restores the
+// latest backup into a scratch volume, recovers PostgreSQL there, and runs
+// validation queries. The scratch volume and PostgreSQL exist only while the
+// Job runs; they never archive WAL to the repository.
+type PGBackRestRestoreDrill struct {
+	// The schedule of the drill in Cron format.
+	// More info: https://k8s.io/docs/concepts/workloads/controllers/cron-jobs/#cron-schedule-syntax
+	// +kubebuilder:validation:MinLength=6
+	Schedule string `json:"schedule"`
+
+	// The name of the pgBackRest repository to restore from.
+	// +kubebuilder:validation:Pattern=^repo[1-4]
+	RepoName string `json:"repoName"`
+
+	// Defines the PersistentVolumeClaim of the scratch data directory. It is
+	// created for each drill and deleted when the drill finishes.
+	// More info: https://docs.k8s.io/concepts/storage/ephemeral-volumes/#generic-ephemeral-volumes
+	// +kubebuilder:validation:Required
+	DataVolumeClaimSpec corev1.PersistentVolumeClaimSpec `json:"dataVolumeClaimSpec"`
+
+	// SQL statements to run as the "postgres" user after recovery finishes.
+	// The drill fails when any statement fails.
+	// +optional
+	SQL string `json:"sql,omitempty"`
+
+	// Compute resources of the drill container.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
 // PGBackRestBackupSchedules defines a pgBackRest scheduled backup
 type PGBackRestBackupSchedules struct {
 	// Validation set to minimum length of six to account for @daily option
@@ -271,6 +348,15 @@ type PGBackRestBackupSchedules struct {
 	// +optional
 	// +kubebuilder:validation:MinLength=6
 	Incremental *string `json:"incremental,omitempty"`
+
+	// Defines the Cron schedule for checking the backups and archived WAL in
+	// the repository with pgBackRest verify.
+	// Follows the standard Cron schedule syntax:
+	// https://k8s.io/docs/concepts/workloads/controllers/cron-jobs/#cron-schedule-syntax
+	// More info: https://pgbackrest.org/command.html#command-verify
+	// +optional
+	// +kubebuilder:validation:MinLength=6
+	Verify *string `json:"verify,omitempty"`
 }
 
 // PGBackRestStatus defines the status of pgBackRest within a PostgresCluster
@@ -297,6 +383,22 @@ type PGBackRestStatus struct {
 	// Status information for in-place restores
 	// +optional
 	Restore *PGBackRestJobStatus `json:"restore,omitempty"`
+
+	// Status information for the latest restore drill. Its ID is the name of
+	// the Job.
+	// +optional
+	RestoreDrill *PGBackRestJobStatus `json:"restoreDrill,omitempty"`
+
+	// The PostgreSQL major version of the stanza as of the latest successful
+	// stanza-create or stanza-upgrade. The operator upgrades the stanza when
+	// this differs from spec.postgresVersion.
+	// +optional
+	StanzaPostgresVersion int `json:"stanzaPostgresVersion,omitempty"`
+
+	// Status information for the latest finished backup Job, whether manual,
+	// scheduled, or for replica creation. Its ID is the name of the Job.
+	// +optional
+	LastBackup *PGBackRestJobStatus `json:"lastBackup,omitempty"`
 }
 
 // PGBackRestRepo represents a pgBackRest repository.  Only one of its members may be specified.
@@ -316,6 +418,30 @@ type PGBackRestRepo struct {
 	// +optional
 	BackupSchedules *PGBackRestBackupSchedules `json:"schedules,omitempty"`
 
+	// Whether or not backups to this repository read files from a replica
+	// rather than the primary, which only coordinates the backup. This applies
+	// only to "volume" repositories, which back up through the repository
+	// host. Backups fail when no replica is streaming from the primary.
+	// More info: https://pgbackrest.org/user-guide.html#standby-backup
+	// +optional
+	BackupFromStandby bool `json:"backupFromStandby,omitempty"`
+
+	// pgBackRest options of this repository without its "repoN-" prefix, e.g.
+	// "retention-full" or "storage-verify-tls". They are included in the
+	// "global" section of the pgBackRest configuration after the prefix is
+	// added, and they take precedence over global settings of the same name.
+	// Like global settings, they cannot change settings the PostgreSQL
+	// Operator requires.
+	// More info: https://pgbackrest.org/configuration.html#section-repository
+	// +optional
+	Options map[string]string `json:"options,omitempty"`
+
+	// Encrypts the contents of the repository. This cannot change after the
+	// repository has backups or archived WAL.
+	// More info: https://pgbackrest.org/user-guide.html#quickstart/configure-encryption
+	// +optional
+	Encryption *PGBackRestRepoEncryption `json:"encryption,omitempty"`
+
 	// Represents a pgBackRest repository that is created using Azure storage
 	// +optional
 	Azure *RepoAzure `json:"azure,omitempty"`
@@ -334,6 +460,22 @@ type PGBackRestRepo struct {
 	Volume *RepoPVC `json:"volume,omitempty"`
 }
 
+// PGBackRestRepoEncryption defines how pgBackRest encrypts a repository.
+type PGBackRestRepoEncryption struct {
+
+	// The cipher that pgBackRest uses to encrypt files in the repository.
+	// +kubebuilder:default=aes-256-cbc
+	// +kubebuilder:validation:Enum={aes-256-cbc}
+	// +optional
+	CipherType string `json:"cipherType,omitempty"`
+
+	// The key of a Secret that contains the passphrase of the repository. It
+	// is passed to pgBackRest through the environment rather than its
+	// configuration files.
+	// +kubebuilder:validation:Required
+	Passphrase corev1.SecretKeySelector `json:"passphrase"`
+}
+
 // RepoHostStatus defines the status of a pgBackRest repository host
 type RepoHostStatus struct {
 	metav1.TypeMeta `json:",inline"`
@@ -407,6 +549,16 @@ type RepoStatus struct {
 	// to bootstrap replicas.
 	ReplicaCreateBackupComplete bool `json:"replicaCreateBackupComplete,omitempty"`
 
+	// The time that the latest successful pgBackRest verify of the repository
+	// finished.
+	// +optional
+	LastVerified *metav1.Time `json:"lastVerified,omitempty"`
+
+	// The backups and archived WAL in the repository as reported by pgBackRest
+	// info after the latest backup Job finished.
+	// +optional
+	Backups *RepoBackupsStatus `json:"backups,omitempty"`
+
 	// A hash of the required fields in the spec for defining an Azure, GCS or S3 repository,
 	// Utilizd to detect changes to these fields and then execute pgBackRest stanza-create
 	// commands accordingly.
@@ -414,6 +566,63 @@ type RepoStatus struct {
 	RepoOptionsHash string `json:"repoOptionsHash,omitempty"`
 }
 
+// RepoBackupsStatus describes the latest successful backup of each type in a
+// pgBackRest repository and the range of WAL archived there.
+type RepoBackupsStatus struct {
+
+	// The latest successful full backup
+	// +optional
+	Full *RepoBackupStatus `json:"full,omitempty"`
+
+	// The latest successful differential backup
+	// +optional
+	Differential *RepoBackupStatus `json:"differential,omitempty"`
+
+	// The latest successful incremental backup
+	// +optional
+	Incremental *RepoBackupStatus `json:"incremental,omitempty"`
+
+	// The oldest WAL segment archived in the repository
+	// +optional
+	WALMin string `json:"walMin,omitempty"`
+
+	// The newest WAL segment archived in the repository
+	// +optional
+	WALMax string `json:"walMax,omitempty"`
+}
+
+// RepoBackupStatus describes one pgBackRest backup.
+type RepoBackupStatus struct {
+
+	// The pgBackRest label of the backup
+	// +kubebuilder:validation:Required
+	Label string `json:"label"`
+
+	// When the backup started
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// When the backup finished
+	// +optional
+	StopTime *metav1.Time `json:"stopTime,omitempty"`
+
+	// The size of the PostgreSQL data that the backup contains
+	// +optional
+	DatabaseSize *resource.Quantity `json:"databaseSize,omitempty"`
+
+	// The space that the backup uses in the repository, after compression
+	// +optional
+	RepositorySize *resource.Quantity `json:"repositorySize,omitempty"`
+
+	// The first WAL segment needed to make the backup consistent
+	// +optional
+	WALStart string `json:"walStart,omitempty"`
+
+	// The last WAL segment needed to make the backup consistent
+	// +optional
+	WALStop string `json:"walStop,omitempty"`
+}
+
 // PGBackRestDataSource defines a pgBackRest configuration specifically for restoring from cloud-based data source
 type PGBackRestDataSource struct {
 	// Projected volumes containing custom pgBackRest configuration.  These files are mounted
@@ -73,11 +73,26 @@ type PGBouncerPodSpec struct {
 	// +optional
 	Config PGBouncerConfiguration `json:"config,omitempty"`
 
+	// Connection pooling settings of PgBouncer. Settings in config.global
+	// take precedence over these.
+	// +optional
+	Pooling *PGBouncerPoolingSpec `json:"pooling,omitempty"`
+
 	// Custom sidecars for a PgBouncer pod. Changing this value causes
 	// PgBouncer to restart.
 	// +optional
 	Containers []corev1.Container `json:"containers,omitempty"`
 
+	// Additional volumes for a PgBouncer pod that custom sidecars may mount.
+	// Volumes named like those of PgBouncer itself are ignored. This requires
+	// the PGBouncerSidecars feature gate. Changing this value causes PgBouncer
+	// to restart.
+	// More info: https://kubernetes.io/docs/concepts/storage/volumes
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Volumes []corev1.Volume `json:"volumes,omitempty"`
+
 	// A secret projection containing a certificate and key with which to encrypt
 	// connections to PgBouncer. The "tls.crt", "tls.key", and "ca.crt" paths must
 	// be PEM-encoded certificates and keys. Changing this value causes PgBouncer
@@ -144,6 +159,79 @@ type PGBouncerPodSpec struct {
 	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
 }
 
+// PGBouncerPoolingSpec defines how PgBouncer shares server connections.
+type PGBouncerPoolingSpec struct {
+
+	// When a server connection is released back to the pool.
+	// More info: https://www.pgbouncer.org/config.html#pool_mode
+	// +optional
+	// +kubebuilder:validation:Enum={session,transaction,statement}
+	Mode string `json:"mode,omitempty"`
+
+	// How many server connections to allow per user/database pair. This,
+	// times the number of PgBouncer pods, cannot exceed the max_connections
+	// of PostgreSQL.
+	// More info: https://www.pgbouncer.org/config.html#default_pool_size
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	DefaultPoolSize *int32 `json:"defaultPoolSize,omitempty"`
+
+	// How many server connections to keep open in each pool, at least.
+	// More info: https://www.pgbouncer.org/config.html#min_pool_size
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MinPoolSize *int32 `json:"minPoolSize,omitempty"`
+
+	// Pooling settings of particular databases. Each of these is added to the
+	// databases section of PgBouncer's configuration, connecting to its target
+	// Service unless config.databases defines it.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Databases []PGBouncerDatabasePoolSpec `json:"databases,omitempty"`
+}
+
+// PGBouncerDatabasePoolSpec defines one database of PgBouncer and its pooling
+// settings.
+type PGBouncerDatabasePoolSpec struct {
+
+	// The name of the database requested by clients.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// The name of the database in PostgreSQL. Defaults to the name requested
+	// by clients.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[^\r\n]*$`
+	Database string `json:"database,omitempty"`
+
+	// Which PostgreSQL instances serve this database: "primary" through the
+	// primary Service, or "replicas" through the "<cluster>-replicas" Service
+	// for read-only traffic. Defaults to "primary".
+	// +optional
+	// +kubebuilder:validation:Enum={primary,replicas}
+	Target string `json:"target,omitempty"`
+
+	// When a server connection to this database is released back to the pool.
+	// More info: https://www.pgbouncer.org/config.html#pool_mode-1
+	// +optional
+	// +kubebuilder:validation:Enum={session,transaction,statement}
+	Mode string `json:"mode,omitempty"`
+
+	// How many server connections to allow per user of this database.
+	// More info: https://www.pgbouncer.org/config.html#pool_size
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	PoolSize *int32 `json:"poolSize,omitempty"`
+
+	// How many server connections to this database to keep open, at least.
+	// More info: https://www.pgbouncer.org/config.html#min_pool_size-1
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MinPoolSize *int32 `json:"minPoolSize,omitempty"`
+}
+
 // PGBouncerSidecars defines the configuration for pgBouncer sidecar containers
 type PGBouncerSidecars struct {
 	// Defines the configuration for the pgBouncer config sidecar container
@@ -0,0 +1,47 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+// PGPartmanSpec installs pg_partman and runs its partition maintenance with
+// the pg_partman background worker. Changing this value causes PostgreSQL to
+// restart.
+// More info: https://github.com/pgpartman/pg_partman
+type PGPartmanSpec struct {
+
+	// Databases in which to create pg_partman and run its maintenance. The
+	// extension is created in the "partman" schema.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	Databases []PostgresIdentifier `json:"databases"`
+
+	// The number of seconds between calls to run_maintenance(). Defaults to
+	// one hour.
+	// +kubebuilder:default=3600
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	IntervalSeconds *int32 `json:"intervalSeconds,omitempty"`
+
+	// The role that runs maintenance. It must own the partitioned tables or
+	// have privileges to create and drop their partitions. Defaults to
+	// "postgres".
+	// +optional
+	Role PostgresIdentifier `json:"role,omitempty"`
+
+	// Whether or not to analyze partitioned tables after creating partitions.
+	// Defaults to false.
+	// +optional
+	Analyze *bool `json:"analyze,omitempty"`
+}
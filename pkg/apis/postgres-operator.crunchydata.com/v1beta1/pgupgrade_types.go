@@ -0,0 +1,43 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import corev1 "k8s.io/api/core/v1"
+
+// PGUpgradeSpec requests a major version upgrade of PostgreSQL using
+// pg_upgrade. The upgrade happens when the data of the cluster is at
+// FromPostgresVersion and spec.postgresVersion is a later major version.
+// More info: https://www.postgresql.org/docs/current/pgupgrade.html
+type PGUpgradeSpec struct {
+
+	// The major version of PostgreSQL that the data is upgraded from.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=15
+	FromPostgresVersion int `json:"fromPostgresVersion"`
+
+	// The image name to use for the upgrade Job. It must contain the binaries
+	// of both PostgreSQL versions in /usr/pgsql-{version}/bin. When omitted,
+	// the value comes from the RELATED_IMAGE_PGUPGRADE environment variable.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Compute resources of the upgrade container. When omitted, the resources
+	// of the first instance set are used.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
@@ -22,6 +22,38 @@ package v1beta1
 // +kubebuilder:validation:MaxLength=63
 type PostgresIdentifier string
 
+// PostgresLocaleSpec defines the locale of a new PostgreSQL cluster.
+type PostgresLocaleSpec struct {
+
+	// The locale provider of the cluster. Valid options are "libc" and "icu".
+	// The "icu" provider requires PostgreSQL 15 or newer and an image built
+	// with ICU support. Instances of other images stop before bootstrap, and
+	// the "PostgresLocaleSupported" condition reports why. Defaults to "libc".
+	// More info: https://www.postgresql.org/docs/current/locale.html#LOCALE-PROVIDERS
+	// +kubebuilder:validation:Enum={libc,icu}
+	// +optional
+	Provider string `json:"provider,omitempty"`
+
+	// The libc locale, e.g. "en_US.UTF-8", used for any categories that are
+	// not otherwise provided. Defaults to the environment of the image.
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_.@-]+$`
+	// +optional
+	Locale string `json:"locale,omitempty"`
+
+	// The ICU locale, e.g. "en-US" or "und-u-ks-level2", of the default
+	// collation. Required when the provider is "icu" and not allowed otherwise.
+	// More info: https://unicode-org.github.io/icu/userguide/locale/
+	// +kubebuilder:validation:Pattern=`^[A-Za-z]{2,8}([-_][A-Za-z0-9]{1,8})*$`
+	// +optional
+	ICULocale string `json:"icuLocale,omitempty"`
+}
+
+// PostgresLocaleSpec providers.
+const (
+	PostgresLocaleProviderICU  = "icu"
+	PostgresLocaleProviderLibc = "libc"
+)
+
 type PostgresPasswordSpec struct {
 	// Type of password to generate. Defaults to ASCII. Valid options are ASCII
 	// and AlphaNumeric.
@@ -38,6 +70,104 @@ const (
 	PostgresPasswordTypeASCII        = "ASCII"
 )
 
+// PasswordType values of PostgresUserSpec and PostgresAdditionalConfig.
+const (
+	PasswordTypeMD5   = "MD5"
+	PasswordTypeSCRAM = "SCRAM-SHA-256"
+)
+
+// PostgresHBARule is one rule of pg_hba.conf. Set either rule or method.
+type PostgresHBARule struct {
+
+	// A complete line of pg_hba.conf. When set, the other fields are ignored.
+	// Either this or method is required.
+	// +kubebuilder:validation:Pattern=`^[^\r\n#]+$`
+	// +optional
+	Rule string `json:"rule,omitempty"`
+
+	// The kind of connection this rule matches. Defaults to "hostssl".
+	// +kubebuilder:validation:Enum={local,host,hostssl,hostnossl}
+	// +optional
+	Connection string `json:"connection,omitempty"`
+
+	// Databases this rule matches. Defaults to all databases. The keywords
+	// "all", "sameuser", "samerole", and "replication" keep their meaning.
+	// +optional
+	Databases []PostgresIdentifier `json:"databases,omitempty"`
+
+	// Users this rule matches. Defaults to all users. The keyword "all" keeps
+	// its meaning.
+	// +optional
+	Users []PostgresIdentifier `json:"users,omitempty"`
+
+	// The client addresses this rule matches as an IP address range in CIDR
+	// notation, a host name, or a keyword such as "samenet". Defaults to all
+	// addresses. Ignored for "local" connections.
+	// +kubebuilder:validation:Pattern=`^[0-9A-Za-z.:/_-]+$`
+	// +optional
+	Address string `json:"address,omitempty"`
+
+	// The authentication method to use when a connection matches this rule.
+	// +kubebuilder:validation:Enum={trust,reject,scram-sha-256,md5,password,gss,sspi,ident,peer,ldap,radius,cert,pam,bsd}
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Options of the authentication method, e.g. "ldapserver". Names contain
+	// only lowercase letters and underscores.
+	// +kubebuilder:validation:MaxProperties=20
+	// +optional
+	Options map[string]string `json:"options,omitempty"`
+}
+
+type PostgresDatabaseSpec struct {
+
+	// The name of this PostgreSQL database.
+	Name PostgresIdentifier `json:"name"`
+
+	// The role that owns this database and its schemas. The role is created
+	// when it does not exist. Changing this value changes the owner.
+	// +optional
+	Owner PostgresIdentifier `json:"owner,omitempty"`
+
+	// Extensions to create in this database. Removing an extension from this
+	// list does NOT drop it.
+	// +listType=set
+	// +optional
+	Extensions []PostgresIdentifier `json:"extensions,omitempty"`
+
+	// Schemas to create in this database. Removing a schema from this list
+	// does NOT drop it.
+	// +listType=set
+	// +optional
+	Schemas []PostgresIdentifier `json:"schemas,omitempty"`
+
+	// The locale and collation of this database when it is created. Changing
+	// this afterward has no effect. Defaults to that of the cluster.
+	// +optional
+	Locale *PostgresLocaleSpec `json:"locale,omitempty"`
+}
+
+type PostgresExtensionSpec struct {
+
+	// The name of this PostgreSQL extension.
+	Name PostgresIdentifier `json:"name"`
+
+	// Databases in which to create this extension. When empty, it is created
+	// in every database that allows connections, including "template1" so
+	// that new databases have it too. Defaults to "postgres" for pg_cron,
+	// which can only be created in one database.
+	// +listType=set
+	// +optional
+	Databases []PostgresIdentifier `json:"databases,omitempty"`
+
+	// The shared library to load when PostgreSQL starts. Defaults to the
+	// library of well-known extensions that require one, such as pg_cron,
+	// pg_stat_statements, and timescaledb. Changing this value causes
+	// PostgreSQL to restart.
+	// +optional
+	Library string `json:"library,omitempty"`
+}
+
 type PostgresUserSpec struct {
 
 	// This value goes into the name of a corev1.Secret and a label value, so
@@ -67,4 +197,19 @@ type PostgresUserSpec struct {
 	// Properties of the password generated for this user.
 	// +optional
 	Password *PostgresPasswordSpec `json:"password,omitempty"`
+
+	// How PostgreSQL stores and verifies the password of this user. Defaults
+	// to the passwordType of the cluster. Some older drivers support only MD5.
+	// More info: https://www.postgresql.org/docs/current/auth-password.html
+	// +kubebuilder:validation:Enum={SCRAM-SHA-256,MD5}
+	// +optional
+	PasswordType string `json:"passwordType,omitempty"`
+
+	// The name of an existing Secret, in the namespace of the cluster, from
+	// which to read the "password" and optional SCRAM "verifier" of this user.
+	// The operator does not generate a password when this is set. Changes to
+	// that Secret are applied to PostgreSQL.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
 }
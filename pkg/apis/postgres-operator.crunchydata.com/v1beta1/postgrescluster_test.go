@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 	"sigs.k8s.io/yaml"
 )
@@ -66,6 +67,7 @@ status:
   patroni: {}
   postgresVersion: 0
   proxy:
+    haProxy: {}
     pgBouncer: {}
 		`)+"\n")
 	})
@@ -104,6 +106,7 @@ status:
   patroni: {}
   postgresVersion: 0
   proxy:
+    haProxy: {}
     pgBouncer: {}
 		`)+"\n")
 	})
@@ -115,7 +118,7 @@ status:
 
 		b, err := yaml.Marshal(cluster.Spec.Proxy)
 		assert.NilError(t, err)
-		assert.DeepEqual(t, string(b), "pgBouncer: null\n")
+		assert.DeepEqual(t, string(b), "{}\n")
 	})
 
 	t.Run("PgBouncer proxy", func(t *testing.T) {
@@ -133,6 +136,40 @@ pgBouncer:
   resources: {}
 		`)+"\n")
 	})
+
+	t.Run("HAProxy proxy", func(t *testing.T) {
+		var cluster PostgresCluster
+		cluster.Spec.Proxy = &PostgresProxySpec{HAProxy: &HAProxyPodSpec{}}
+		cluster.Default()
+
+		b, err := yaml.Marshal(cluster.Spec.Proxy)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, string(b), strings.TrimSpace(`
+haProxy:
+  port: 5432
+  replicaPort: 5433
+  replicas: 1
+  resources: {}
+		`)+"\n")
+	})
+}
+
+func TestPostgresAdditionalConfigParameters(t *testing.T) {
+	var config PostgresAdditionalConfig
+	assert.NilError(t, yaml.Unmarshal([]byte(`{
+		parameters: { max_connections: 200, work_mem: 2MB, checkpoint_completion_target: "0.9" },
+	}`), &config))
+
+	assert.DeepEqual(t, config.Parameters, map[string]intstr.IntOrString{
+		"max_connections":              intstr.FromInt(200),
+		"work_mem":                     intstr.FromString("2MB"),
+		"checkpoint_completion_target": intstr.FromString("0.9"),
+	})
+
+	// Other numbers must be written as strings.
+	assert.Assert(t, yaml.Unmarshal([]byte(`{
+		parameters: { checkpoint_completion_target: 0.9 },
+	}`), &config) != nil)
 }
 
 func TestPostgresInstanceSetSpecDefault(t *testing.T) {
@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
@@ -36,6 +37,20 @@ type PostgresClusterSpec struct {
 	// +kubebuilder:validation:Required
 	Backups Backups `json:"backups"`
 
+	// Audit logging by pgAudit. Changing this value causes PostgreSQL to reload.
+	// +optional
+	Audit *PGAuditSpec `json:"audit,omitempty"`
+
+	// Partition maintenance by pg_partman.
+	// +optional
+	PGPartman *PGPartmanSpec `json:"pgPartman,omitempty"`
+
+	// The specification of a distributed Citus cluster. Each instance set
+	// becomes a Citus group. This value cannot change after the cluster is
+	// created.
+	// +optional
+	Citus *CitusSpec `json:"citus,omitempty"`
+
 	// The secret containing the Certificates and Keys to encrypt PostgreSQL
 	// traffic will need to contain the server TLS certificate, TLS key and the
 	// Certificate Authority certificate with the data keys set to tls.crt,
@@ -62,6 +77,12 @@ type PostgresClusterSpec struct {
 	// namespace as the cluster.
 	// +optional
 	DatabaseInitSQL *DatabaseInitSQL `json:"databaseInitSQL,omitempty"`
+
+	// A container that migrates the schema of databases after the cluster is
+	// initialized. The "MigrationSucceeded" condition reports its result.
+	// +optional
+	Migration *MigrationSpec `json:"migration,omitempty"`
+
 	// Whether or not the PostgreSQL cluster should use the defined default
 	// scheduling constraints. If the field is unset or false, the default
 	// scheduling constraints will be used in addition to any custom constraints
@@ -92,11 +113,24 @@ type PostgresClusterSpec struct {
 	// +optional
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 
+	// How PostgreSQL pods are updated when spec.image or another pod setting
+	// changes. Replicas are always updated before the primary.
+	// - Switchover: switch to an updated replica before updating the primary.
+	// - Restart: checkpoint and recreate the primary without a switchover.
+	// Patroni may promote another instance while the primary is down.
+	// - Manual: when only images change, update pods only when they are deleted.
+	// Changes to other pod settings still roll out as with Switchover.
+	// Defaults to Switchover.
+	// +kubebuilder:validation:Enum={Switchover,Restart,Manual}
+	// +optional
+	ImageUpdatePolicy ImageUpdatePolicy `json:"imageUpdatePolicy,omitempty"`
+
 	// Specifies one or more sets of PostgreSQL pods that replicate data for
 	// this cluster.
 	// +listType=map
 	// +listMapKey=name
 	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=16
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=2
 	InstanceSets []PostgresInstanceSetSpec `json:"instances"`
 
@@ -144,10 +178,32 @@ type PostgresClusterSpec struct {
 	// +optional
 	Monitoring *MonitoringSpec `json:"monitoring,omitempty"`
 
+	// The specification of routine maintenance that runs on a schedule.
+	// +optional
+	Maintenance *MaintenanceSpec `json:"maintenance,omitempty"`
+
 	// Specification of the service that exposes the PostgreSQL primary instance.
 	// +optional
 	Service *ServiceSpec `json:"service,omitempty"`
 
+	// Specification of the service that exposes PostgreSQL replica instances.
+	// +optional
+	ReplicaService *ServiceSpec `json:"replicaService,omitempty"`
+
+	// Replication slots that Patroni keeps on the primary and carries over to
+	// a new primary after failover or switchover. Specifying any slots makes
+	// Patroni also manage physical slots for every replica. Logical slots that
+	// are not specified here are left alone.
+	// More info: https://patroni.readthedocs.io/en/latest/SETTINGS.html#dynamic-configuration-settings
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ReplicationSlots []PostgresReplicationSlotSpec `json:"replicationSlots,omitempty"`
+
+	// Logical replication publications and subscriptions.
+	// +optional
+	Replication *PostgresReplicationSpec `json:"replication,omitempty"`
+
 	// Whether or not the PostgreSQL cluster should be stopped.
 	// When this is true, workloads are scaled to zero and CronJobs
 	// are suspended.
@@ -155,6 +211,11 @@ type PostgresClusterSpec struct {
 	// +optional
 	Shutdown *bool `json:"shutdown,omitempty"`
 
+	// Upgrade the data of the cluster to spec.postgresVersion with pg_upgrade.
+	// The cluster is shut down while the upgrade runs.
+	// +optional
+	Upgrade *PGUpgradeSpec `json:"upgrade,omitempty"`
+
 	// Run this cluster as a read-only copy of an existing cluster or archive.
 	// +optional
 	Standby *PostgresStandbySpec `json:"standby,omitempty"`
@@ -174,6 +235,22 @@ type PostgresClusterSpec struct {
 	// +optional
 	Users []PostgresUserSpec `json:"users,omitempty"`
 
+	// Databases to create inside PostgreSQL along with their owners,
+	// extensions, and schemas. Removing a database from this list does NOT
+	// drop it.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Databases []PostgresDatabaseSpec `json:"databases,omitempty"`
+
+	// Extensions to load and create inside PostgreSQL. Their shared libraries
+	// are loaded before they are created, and PostgreSQL restarts when these
+	// change. Removing an extension from this list does NOT drop it.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Extensions []PostgresExtensionSpec `json:"extensions,omitempty"`
+
 	Config PostgresAdditionalConfig `json:"config,omitempty"`
 }
 
@@ -226,6 +303,16 @@ type DataSourceVolume struct {
 	// associated volume.
 	// +optional
 	Directory string `json:"directory,omitempty"`
+
+	// The name of a VolumeSnapshot in the namespace of the PostgresCluster.
+	// When set, the PVC does not already exist and is created from this
+	// snapshot. On PostgreSQL 12 and later, any WAL archived in pgBackRest
+	// after the snapshot is replayed before a restored pgData volume is promoted.
+	// When a PostgresCluster or pgBackRest data source is also set, its restore
+	// runs on this volume and replays WAL from that repository instead.
+	// More info: https://kubernetes.io/docs/concepts/storage/volume-snapshots/
+	// +optional
+	VolumeSnapshotName string `json:"volumeSnapshotName,omitempty"`
 }
 
 // DatabaseInitSQL defines a ConfigMap containing custom SQL that will
@@ -236,9 +323,29 @@ type DatabaseInitSQL struct {
 	// +required
 	Name string `json:"name"`
 
-	// Key is the ConfigMap data key that points to a SQL string
-	// +required
-	Key string `json:"key"`
+	// Key is the ConfigMap data key that points to a SQL string. When empty,
+	// every key that ends in ".sql" runs in order of their names.
+	// +optional
+	Key string `json:"key,omitempty"`
+
+	// The database in which to run the SQL. Defaults to the maintenance
+	// database, "postgres".
+	// +optional
+	Database PostgresIdentifier `json:"database,omitempty"`
+
+	// Variables to set before running the SQL. Reference them as :name,
+	// :'name', or :"name" to interpolate them safely.
+	// More info: https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-INTERPOLATION
+	// +optional
+	Variables map[string]string `json:"variables,omitempty"`
+
+	// Whether or not psql should stop at the first command that fails. When
+	// true, a failure leaves the status unset so that the SQL runs again.
+	// Defaults to true when key or database is empty, and to false when only
+	// key is set.
+	// More info: https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-VARIABLES
+	// +optional
+	StopOnError *bool `json:"stopOnError,omitempty"`
 }
 
 // PostgresClusterDataSource defines a data source for bootstrapping PostgreSQL clusters using a
@@ -267,6 +374,27 @@ type PostgresClusterDataSource struct {
 	// +optional
 	Options []string `json:"options,omitempty"`
 
+	// The kind of recovery target at which to stop replaying WAL: "immediate"
+	// stops as soon as the backup is consistent, "lsn" at the WAL location in
+	// target, "name" at the restore point in target, "time" at targetTime,
+	// and "xid" at the transaction ID in target. Defaults to "time" when
+	// targetTime is set. The recovered cluster is promoted at the target.
+	// More info: https://pgbackrest.org/command.html#command-restore/category-command/option-type
+	// +kubebuilder:validation:Enum={immediate,lsn,name,time,xid}
+	// +optional
+	TargetType string `json:"targetType,omitempty"`
+
+	// The point in time at which to stop replaying WAL, in RFC 3339 format,
+	// e.g. "2022-03-15T10:30:00Z".
+	// +optional
+	TargetTime *metav1.Time `json:"targetTime,omitempty"`
+
+	// The WAL location, restore point name, or transaction ID at which to
+	// stop replaying WAL when targetType is "lsn", "name", or "xid".
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	Target string `json:"target,omitempty"`
+
 	// Resource requirements for the pgBackRest restore Job.
 	// +optional
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
@@ -319,6 +447,16 @@ type Backups struct {
 	// pgBackRest archive configuration
 	// +kubebuilder:validation:Required
 	PGBackRest PGBackRestArchive `json:"pgbackrest"`
+
+	// Logical backups of databases using pg_dump
+	// +optional
+	Logical *LogicalBackups `json:"logical,omitempty"`
+
+	// Archive WAL using WAL-G rather than pgBackRest. pgBackRest repositories
+	// are still configured, but no repository host, repository volumes,
+	// stanzas, nor backups are created for them.
+	// +optional
+	WALG *WALGArchive `json:"walg,omitempty"`
 }
 
 // PostgresClusterStatus defines the observed state of PostgresCluster
@@ -349,6 +487,16 @@ type PostgresClusterStatus struct {
 	// +optional
 	Proxy PostgresProxyStatus `json:"proxy,omitempty"`
 
+	// Current state of replay when the cluster is a standby.
+	// +optional
+	Standby *PostgresStandbyStatus `json:"standby,omitempty"`
+
+	// Replication slots on the primary when logical decoding is enabled.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ReplicationSlots []ReplicationSlotStatus `json:"replicationSlots,omitempty"`
+
 	// The instance that should be started first when bootstrapping and/or starting a
 	// PostgresCluster.
 	// +optional
@@ -373,14 +521,30 @@ type PostgresClusterStatus struct {
 	// +optional
 	DatabaseInitSQL *string `json:"databaseInitSQL,omitempty"`
 
+	// Identifies the migration container and restore that last migrated
+	// databases successfully.
+	// +optional
+	MigrationRevision string `json:"migrationRevision,omitempty"`
+
+	// Current state of scheduled maintenance.
+	// +optional
+	Maintenance *MaintenanceStatus `json:"maintenance,omitempty"`
+
+	// The latest Job of scheduled logical backups.
+	// +optional
+	LogicalBackup *LogicalBackupStatus `json:"logicalBackup,omitempty"`
+
 	// observedGeneration represents the .metadata.generation on which the status was based.
 	// +optional
 	// +kubebuilder:validation:Minimum=0
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
 	// conditions represent the observations of postgrescluster's current state.
-	// Known .status.conditions.type are: "PersistentVolumeResizing",
-	// "Progressing", "ProxyAvailable"
+	// Known .status.conditions.type are: "ClusterReady", "LogicalBackupSucceeded",
+	// "MaintenanceSucceeded", "MigrationSucceeded", "PatroniPaused",
+	// "PersistentVolumeResizing", "PostgresRestartPending",
+	// "PostgresUpgradeProgressing", "PrimaryAvailable", "Progressing",
+	// "ProxyAvailable", "ReplicationHealthy", "Standby"
 	// +optional
 	// +listType=map
 	// +listMapKey=type
@@ -388,11 +552,30 @@ type PostgresClusterStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// ImageUpdatePolicy is how PostgreSQL pods are updated.
+type ImageUpdatePolicy string
+
+const (
+	ImageUpdateManual     ImageUpdatePolicy = "Manual"
+	ImageUpdateRestart    ImageUpdatePolicy = "Restart"
+	ImageUpdateSwitchover ImageUpdatePolicy = "Switchover"
+)
+
 // PostgresClusterStatus condition types.
 const (
+	ClusterReady               = "ClusterReady"
+	LogicalBackupSucceeded     = "LogicalBackupSucceeded"
+	MaintenanceSucceeded       = "MaintenanceSucceeded"
+	MigrationSucceeded         = "MigrationSucceeded"
+	PatroniPaused              = "PatroniPaused"
 	PersistentVolumeResizing   = "PersistentVolumeResizing"
 	PostgresClusterProgressing = "Progressing"
+	PostgresRestartPending     = "PostgresRestartPending"
+	PostgresUpgradeProgressing = "PostgresUpgradeProgressing"
+	PrimaryAvailable           = "PrimaryAvailable"
 	ProxyAvailable             = "ProxyAvailable"
+	ReplicationHealthy         = "ReplicationHealthy"
+	PostgresClusterStandby     = "Standby"
 )
 
 type PostgresInstanceSetSpec struct {
@@ -422,6 +605,13 @@ type PostgresInstanceSetSpec struct {
 	// +kubebuilder:validation:Pattern=`^([a-z0-9]([-a-z0-9]*[a-z0-9])?)?$`
 	Name string `json:"name"`
 
+	// The Citus group of this instance set. Group 0 is the coordinator and
+	// other groups are workers. Required when citus is enabled and ignored
+	// otherwise. This value cannot change after the instance set is created.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CitusGroup *int32 `json:"citusGroup,omitempty"`
+
 	// Scheduling constraints of a PostgreSQL pod. Changing this value causes
 	// PostgreSQL to restart.
 	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/assign-pod-node
@@ -478,6 +668,30 @@ type PostgresInstanceSetSpec struct {
 	// More info: https://www.postgresql.org/docs/current/wal.html
 	// +optional
 	WALVolumeClaimSpec *corev1.PersistentVolumeClaimSpec `json:"walVolumeClaimSpec,omitempty"`
+
+	// Tablespaces stored on their own PersistentVolumeClaims. The operator
+	// creates each tablespace once PostgreSQL is running. Replicas require
+	// every tablespace of the primary, so every instance set must declare the
+	// same tablespaces.
+	// More info: https://www.postgresql.org/docs/current/manage-ag-tablespaces.html
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	TablespaceVolumes []TablespaceVolume `json:"tablespaceVolumes,omitempty"`
+}
+
+// TablespaceVolume defines a PostgreSQL tablespace and the volume that stores it.
+type TablespaceVolume struct {
+	// The name of the tablespace. It is also used in the name of its volume.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=32
+	// +kubebuilder:validation:Pattern=`^[a-z][a-z0-9]*$`
+	Name string `json:"name"`
+
+	// Defines a PersistentVolumeClaim for the tablespace.
+	// +kubebuilder:validation:Required
+	DataVolumeClaimSpec corev1.PersistentVolumeClaimSpec `json:"dataVolumeClaimSpec"`
 }
 
 // InstanceSidecars defines the configuration for instance sidecar containers
@@ -513,13 +727,66 @@ type PostgresInstanceSetStatus struct {
 	// Total number of pods that have the desired specification.
 	// +optional
 	UpdatedReplicas int32 `json:"updatedReplicas,omitempty"`
+
+	// How far each replica in this set is behind the primary, as observed
+	// on the primary.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	ReplicaLag []ReplicaLagStatus `json:"replicaLag,omitempty"`
+
+	// Each instance in this set, as last observed.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Members []PostgresInstanceStatus `json:"members,omitempty"`
+}
+
+// PostgresInstanceStatus describes a single PostgreSQL instance and its Pod.
+type PostgresInstanceStatus struct {
+
+	// The name of the instance.
+	Name string `json:"name"`
+
+	// The name of the Pod running this instance, if any.
+	// +optional
+	Pod string `json:"pod,omitempty"`
+
+	// The role Patroni assigned to this instance: "primary", "replica", or
+	// "standby-leader". Empty when Patroni has not reported a role.
+	// +optional
+	Role string `json:"role,omitempty"`
+
+	// Whether or not the Pod is ready to receive PostgreSQL connections.
+	Ready bool `json:"ready"`
+
+	// The names of the PersistentVolumeClaims mounted by the Pod.
+	// +listType=atomic
+	// +optional
+	PersistentVolumeClaims []string `json:"persistentVolumeClaims,omitempty"`
+
+	// The major version of PostgreSQL that the Pod runs.
+	// +optional
+	PostgresVersion int32 `json:"postgresVersion,omitempty"`
+
+	// The image of the database container.
+	// +optional
+	Image string `json:"image,omitempty"`
 }
 
 // PostgresProxySpec is a union of the supported PostgreSQL proxies.
+// +kubebuilder:validation:MinProperties=1
+// +kubebuilder:validation:MaxProperties=1
 type PostgresProxySpec struct {
 
 	// Defines a PgBouncer proxy and connection pooler.
-	PGBouncer *PGBouncerPodSpec `json:"pgBouncer"`
+	// +optional
+	PGBouncer *PGBouncerPodSpec `json:"pgBouncer,omitempty"`
+
+	// Defines an HAProxy that routes connections to the primary or replicas
+	// without pooling them.
+	// +optional
+	HAProxy *HAProxyPodSpec `json:"haProxy,omitempty"`
 }
 
 // Default sets the defaults for any proxies that are set.
@@ -527,17 +794,47 @@ func (s *PostgresProxySpec) Default() {
 	if s.PGBouncer != nil {
 		s.PGBouncer.Default()
 	}
+	if s.HAProxy != nil {
+		s.HAProxy.Default()
+	}
 }
 
 type PostgresProxyStatus struct {
 	PGBouncer PGBouncerPodStatus `json:"pgBouncer,omitempty"`
+	HAProxy   HAProxyPodStatus   `json:"haProxy,omitempty"`
+}
+
+// PostgresReplicationSlotSpec describes a permanent replication slot.
+type PostgresReplicationSlotSpec struct {
+	// The name of the replication slot.
+	// +required
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9_]+$`
+	Name string `json:"name"`
+
+	// The kind of replication slot. Logical slots also require database and plugin.
+	// +optional
+	// +kubebuilder:default=logical
+	// +kubebuilder:validation:Enum={logical,physical}
+	Type string `json:"type,omitempty"`
+
+	// The database of a logical replication slot.
+	// +optional
+	Database string `json:"database,omitempty"`
+
+	// The output plugin of a logical replication slot, e.g. "pgoutput".
+	// +optional
+	Plugin string `json:"plugin,omitempty"`
 }
 
 // PostgresStandbySpec defines if/how the cluster should be a hot standby.
 type PostgresStandbySpec struct {
 	// Whether or not the PostgreSQL cluster should be read-only. When this is
 	// true, WAL files are applied from a pgBackRest repository or another
-	// PostgreSQL server.
+	// PostgreSQL server. Changing this to false promotes the cluster once it
+	// has replayed all the WAL it received and is no longer streaming from
+	// its primary. Changing this to true demotes a primary to follow the
+	// repository or server below.
 	// +optional
 	// +kubebuilder:default=true
 	Enabled bool `json:"enabled"`
@@ -548,6 +845,10 @@ type PostgresStandbySpec struct {
 	RepoName string `json:"repoName,omitempty"`
 
 	// Network address of the PostgreSQL server to follow via streaming replication.
+	// That server can be in another namespace, another Kubernetes cluster, or
+	// outside Kubernetes. It must accept replication connections from the
+	// "_crunchyrepl" user using the certificate in customReplicationTLSSecret
+	// and present a certificate signed by the same authority.
 	// +optional
 	Host string `json:"host,omitempty"`
 
@@ -555,6 +856,47 @@ type PostgresStandbySpec struct {
 	// +optional
 	// +kubebuilder:validation:Minimum=1024
 	Port *int32 `json:"port,omitempty"`
+
+	// Whether or not to promote without checking that the standby leader is
+	// disconnected from its primary and has replayed all the WAL it received
+	// or found in its repository.
+	// Set this only when the former primary is fenced by other means, e.g.
+	// when it is lost. WAL that was not replayed is discarded.
+	// +optional
+	ForcePromotion *bool `json:"forcePromotion,omitempty"`
+}
+
+// PostgresStandbyStatus describes how far a standby cluster is behind the
+// cluster it follows, as observed on its standby leader.
+type PostgresStandbyStatus struct {
+	// The time at which these values were observed.
+	// +optional
+	ObservedAt *metav1.Time `json:"observedAt,omitempty"`
+
+	// The last WAL location received from the primary by streaming
+	// replication. This is empty when WAL comes only from a repository.
+	// +optional
+	ReceivedLSN string `json:"receivedLSN,omitempty"`
+
+	// The last WAL location replayed during recovery.
+	// +optional
+	ReplayedLSN string `json:"replayedLSN,omitempty"`
+
+	// The commit time of the last transaction replayed during recovery.
+	// +optional
+	LastReplayedTransaction *metav1.Time `json:"lastReplayedTransaction,omitempty"`
+
+	// Seconds between the last replayed transaction and observedAt. This
+	// grows while the primary is idle, so it is an upper bound of the lag.
+	// +optional
+	ReplayLagSeconds *int64 `json:"replayLagSeconds,omitempty"`
+
+	// The name of the WAL file containing the replay location, when WAL comes
+	// from the pgBackRest repository. Its timeline is that of the last
+	// checkpoint, which can be older than the timeline being replayed. This is
+	// empty when WAL is received by streaming replication.
+	// +optional
+	LastRestoredWAL string `json:"lastRestoredWAL,omitempty"`
 }
 
 // UserInterfaceSpec is a union of the supported PostgreSQL user interfaces.
@@ -580,7 +922,62 @@ type PostgresUserInterfaceStatus struct {
 }
 
 type PostgresAdditionalConfig struct {
+	// Files to mount under "/etc/postgres" in PostgreSQL containers. Files
+	// projected into "conf.d" with names ending in ".conf" are included in
+	// postgresql.conf, in order of their names. Parameters from Patroni and
+	// the operator take precedence over them.
+	// More info: https://www.postgresql.org/docs/current/config-setting.html#CONFIG-INCLUDES
+	// +optional
 	Files []corev1.VolumeProjection `json:"files,omitempty"`
+
+	// The locale and default collation of the cluster. These are applied when
+	// the cluster is initialized and become the defaults of every database;
+	// changing them afterward has no effect.
+	// +optional
+	Locale *PostgresLocaleSpec `json:"locale,omitempty"`
+
+	// The IANA time zone name, e.g. "America/New_York", of the cluster. When
+	// set, PostgreSQL uses it for "timezone" and "log_timezone", and scheduled
+	// backups are evaluated in it. When empty, PostgreSQL and backup schedules
+	// use UTC.
+	// More info: https://www.postgresql.org/docs/current/datatype-datetime.html#DATATYPE-TIMEZONES
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	Timezone string `json:"timezone,omitempty"`
+
+	// How PostgreSQL stores and verifies passwords. When SCRAM-SHA-256, every
+	// password connection must use SCRAM except for users with a passwordType
+	// of MD5. When MD5, passwords are stored as MD5. When empty, passwords are
+	// stored as SCRAM-SHA-256 and connections may use either method.
+	// More info: https://www.postgresql.org/docs/current/auth-password.html
+	// +kubebuilder:validation:Enum={SCRAM-SHA-256,MD5}
+	// +optional
+	PasswordType string `json:"passwordType,omitempty"`
+
+	// Rules of pg_hba.conf, checked in order after those the operator requires
+	// and before any from the Patroni dynamic configuration or the defaults.
+	// More info: https://www.postgresql.org/docs/current/auth-pg-hba-conf.html
+	// +kubebuilder:validation:MaxItems=64
+	// +optional
+	HBA []PostgresHBARule `json:"pg_hba,omitempty"`
+
+	// PostgreSQL parameters that override those in the Patroni dynamic
+	// configuration. Parameters the operator requires cannot be changed.
+	// PostgreSQL reloads when these change; when a parameter needs a restart,
+	// the operator restarts replicas then the primary and reports it in the
+	// "PostgresRestartPending" condition. Values are integers or strings;
+	// write other numbers as strings, e.g. "0.9".
+	// More info: https://www.postgresql.org/docs/current/runtime-config.html
+	// +mapType=granular
+	// +optional
+	Parameters map[string]intstr.IntOrString `json:"parameters,omitempty"`
+
+	// Whether or not to derive shared_buffers, effective_cache_size, work_mem,
+	// and maintenance_work_mem from the memory of the smallest instance set.
+	// The memory limit is used when set, otherwise the memory request. These
+	// are defaults; parameters set elsewhere take precedence.
+	// +optional
+	AutoTune *bool `json:"autoTune,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -660,6 +1057,10 @@ func (meta *Metadata) GetAnnotationsOrNil() map[string]string {
 type MonitoringSpec struct {
 	// +optional
 	PGMonitor *PGMonitorSpec `json:"pgmonitor,omitempty"`
+
+	// Forwards PostgreSQL and pgBackRest logs to a log pipeline.
+	// +optional
+	LogShipping *LogShippingSpec `json:"logShipping,omitempty"`
 }
 
 // MonitoringStatus is the current state of PostgreSQL cluster monitoring tool
@@ -673,6 +1074,38 @@ type MonitoringStatus struct {
 type PGMonitorSpec struct {
 	// +optional
 	Exporter *ExporterSpec `json:"exporter,omitempty"`
+
+	// Alerts about metrics of the exporter in a PrometheusRule. Requires the
+	// Prometheus Operator.
+	// More info: https://prometheus-operator.dev/docs/operator/api/#monitoring.coreos.com/v1.PrometheusRule
+	// +optional
+	Alerts *PGMonitorAlertsSpec `json:"alerts,omitempty"`
+}
+
+// PGMonitorAlertsSpec describes a PrometheusRule of alerts about one cluster.
+type PGMonitorAlertsSpec struct {
+
+	// Labels to add to the PrometheusRule so that Prometheus selects it.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Alert when a replica is more than this amount of WAL behind the
+	// primary. Defaults to 50Mi.
+	// +optional
+	ReplicationLag *resource.Quantity `json:"replicationLag,omitempty"`
+
+	// Alert when the latest backup is older than this number of hours.
+	// Defaults to 28.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	BackupAgeHours *int32 `json:"backupAgeHours,omitempty"`
+
+	// Alert when connections exceed this percent of max_connections.
+	// Defaults to 90.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	ConnectionsPercent *int32 `json:"connectionsPercent,omitempty"`
 }
 
 type ExporterSpec struct {
@@ -0,0 +1,193 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PostgresReplicationSpec declares logical replication in PostgreSQL and
+// limits on streaming replication between instances.
+// More info: https://www.postgresql.org/docs/current/logical-replication.html
+type PostgresReplicationSpec struct {
+
+	// The most WAL that a replica can be behind the primary before the
+	// ReplicationHealthy condition becomes False. Defaults to 50Mi.
+	// +optional
+	MaxReplicaLag *resource.Quantity `json:"maxReplicaLag,omitempty"`
+
+	// Publications to create on the primary. Publications removed from this
+	// list are not dropped.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Publications []PostgresPublicationSpec `json:"publications,omitempty"`
+
+	// Subscriptions to create on the primary. Subscriptions removed from this
+	// list are not dropped.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Subscriptions []PostgresSubscriptionSpec `json:"subscriptions,omitempty"`
+
+	// Prepares PostgreSQL for change data capture by clients outside the
+	// cluster, such as Debezium.
+	// +optional
+	LogicalDecoding *LogicalDecodingSpec `json:"logicalDecoding,omitempty"`
+}
+
+// LogicalDecodingSpec reserves replication connections and slots for logical
+// decoding and protects the primary from WAL retained by those slots.
+// More info: https://www.postgresql.org/docs/current/logicaldecoding.html
+type LogicalDecodingSpec struct {
+
+	// Logical replication slots that Patroni keeps on the primary and carries
+	// over to a new primary after failover or switchover. Clients resume
+	// decoding from these slots without missing changes.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Slots []LogicalDecodingSlotSpec `json:"slots,omitempty"`
+
+	// The number of replication connections and slots to reserve for clients
+	// in addition to those used by declared slots and the 10 reserved for
+	// replicas. Changes take effect after PostgreSQL restarts.
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Senders *int32 `json:"senders,omitempty"`
+
+	// The most WAL that any one replication slot can retain, e.g. "10Gi". A
+	// slot that falls further behind is invalidated, and its client must
+	// start over from a new snapshot. Requires PostgreSQL 13 or later. When
+	// empty, slots retain WAL until their clients consume it.
+	// More info: https://www.postgresql.org/docs/current/runtime-config-replication.html#GUC-MAX-SLOT-WAL-KEEP-SIZE
+	// +optional
+	MaxRetainedWAL *resource.Quantity `json:"maxRetainedWAL,omitempty"`
+}
+
+// LogicalDecodingSlotSpec describes a permanent logical replication slot.
+type LogicalDecodingSlotSpec struct {
+
+	// The name of the replication slot.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9_]+$`
+	Name string `json:"name"`
+
+	// The database from which the slot decodes changes.
+	// +kubebuilder:validation:Required
+	Database PostgresIdentifier `json:"database"`
+
+	// The output plugin that formats changes, e.g. "wal2json".
+	// +kubebuilder:default=pgoutput
+	// +optional
+	Plugin string `json:"plugin,omitempty"`
+}
+
+// ReplicationSlotStatus describes a replication slot on the primary.
+type ReplicationSlotStatus struct {
+
+	// The name of the replication slot.
+	Name string `json:"name"`
+
+	// The kind of replication slot, either "logical" or "physical".
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Whether or not a client is consuming from the slot.
+	// +optional
+	Active bool `json:"active,omitempty"`
+
+	// The amount of WAL that the slot keeps on the primary.
+	// +optional
+	RetainedWAL *resource.Quantity `json:"retainedWAL,omitempty"`
+
+	// Whether or not the WAL needed by the slot is still available, e.g.
+	// "reserved", "extended", "unreserved", or "lost". Requires PostgreSQL 13
+	// or later.
+	// More info: https://www.postgresql.org/docs/current/view-pg-replication-slots.html
+	// +optional
+	WALStatus string `json:"walStatus,omitempty"`
+}
+
+// ReplicaLagStatus describes how far a replica is behind the primary.
+type ReplicaLagStatus struct {
+
+	// The name of the instance.
+	Name string `json:"name"`
+
+	// The amount of WAL that the replica has yet to replay.
+	// +optional
+	Lag *resource.Quantity `json:"lag,omitempty"`
+
+	// How long ago the primary wrote the WAL that the replica last replayed.
+	// More info: https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-REPLICATION-VIEW
+	// +optional
+	LagTime *metav1.Duration `json:"lagTime,omitempty"`
+}
+
+// PostgresPublicationSpec describes a publication of changes in one database.
+// More info: https://www.postgresql.org/docs/current/sql-createpublication.html
+type PostgresPublicationSpec struct {
+
+	// The name of the publication.
+	// +kubebuilder:validation:Required
+	Name PostgresIdentifier `json:"name"`
+
+	// The database in which to create the publication.
+	// +kubebuilder:validation:Required
+	Database PostgresIdentifier `json:"database"`
+
+	// Tables to publish, optionally qualified by their schema, e.g.
+	// "sales.orders". When empty, every table in the database is published.
+	// A publication cannot change between a list of tables and every table.
+	// +listType=set
+	// +optional
+	Tables []string `json:"tables,omitempty"`
+}
+
+// PostgresSubscriptionSpec describes a subscription to publications of
+// another PostgreSQL server.
+// More info: https://www.postgresql.org/docs/current/sql-createsubscription.html
+type PostgresSubscriptionSpec struct {
+
+	// The name of the subscription.
+	// +kubebuilder:validation:Required
+	Name PostgresIdentifier `json:"name"`
+
+	// The database in which to create the subscription. Its tables receive
+	// the changes.
+	// +kubebuilder:validation:Required
+	Database PostgresIdentifier `json:"database"`
+
+	// The names of publications on the publisher.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	Publications []PostgresIdentifier `json:"publications"`
+
+	// A Secret key that contains the libpq connection string to the
+	// publisher, e.g. "host=hippo-primary.ns dbname=app user=repl password=…".
+	// More info: https://www.postgresql.org/docs/current/libpq-connect.html#LIBPQ-CONNSTRING
+	// +kubebuilder:validation:Required
+	Connection corev1.SecretKeySelector `json:"connection"`
+
+	// Whether or not the subscription receives changes. Defaults to true.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+}
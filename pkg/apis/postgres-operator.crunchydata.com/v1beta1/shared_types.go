@@ -42,6 +42,56 @@ type ServiceSpec struct {
 	// +optional
 	Metadata *Metadata `json:"metadata,omitempty"`
 
+	// Whether or not traffic from outside the Kubernetes cluster is routed
+	// only to endpoints on the node that received it. "Local" preserves the
+	// client source IP address. Applies when type is NodePort or LoadBalancer.
+	// More info: https://kubernetes.io/docs/tasks/access-application-cluster/create-external-load-balancer/#preserving-the-client-source-ip
+	// +optional
+	// +kubebuilder:validation:Enum={Cluster,Local}
+	ExternalTrafficPolicy *corev1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+
+	// IP families assigned to this Service, in order of preference. The first
+	// family is also used for pgBackRest TLS servers when this is the
+	// PostgresCluster Service. When omitted, Kubernetes uses the cluster default.
+	// More info: https://kubernetes.io/docs/concepts/services-networking/dual-stack/#services
+	// +optional
+	// +listType=atomic
+	// +kubebuilder:validation:MaxItems=2
+	// +kubebuilder:validation:items:Enum={IPv4,IPv6}
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
+
+	// Whether this Service should have one or both IP families.
+	// More info: https://kubernetes.io/docs/concepts/services-networking/dual-stack/#services
+	// +optional
+	// +kubebuilder:validation:Enum={SingleStack,PreferDualStack,RequireDualStack}
+	IPFamilyPolicy *corev1.IPFamilyPolicyType `json:"ipFamilyPolicy,omitempty"`
+
+	// The class of load balancer implementation this Service belongs to.
+	// This cannot be changed after the Service is created. Applies when type
+	// is LoadBalancer.
+	// More info: https://kubernetes.io/docs/concepts/services-networking/service/#load-balancer-class
+	// +optional
+	LoadBalancerClass *string `json:"loadBalancerClass,omitempty"`
+
+	// Client IP ranges, in CIDR notation, allowed to reach the load balancer
+	// when the platform supports it. Applies when type is LoadBalancer.
+	// More info: https://kubernetes.io/docs/tasks/access-application-cluster/configure-cloud-provider-firewall/
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// Whether or not connections from one client address are sent to the same
+	// endpoint each time. Defaults to None.
+	// More info: https://kubernetes.io/docs/concepts/services-networking/service/#session-affinity
+	// +optional
+	// +kubebuilder:validation:Enum={None,ClientIP}
+	SessionAffinity *corev1.ServiceAffinity `json:"sessionAffinity,omitempty"`
+
+	// Whether or not traffic should prefer endpoints in the same zone as the
+	// client. This requires the TopologyAwareHints feature of Kubernetes.
+	// More info: https://kubernetes.io/docs/concepts/services-networking/topology-aware-hints/
+	// +optional
+	TopologyAwareHints bool `json:"topologyAwareHints,omitempty"`
+
 	// The port on which this service is exposed when type is NodePort or
 	// LoadBalancer. Value must be in-range and not in use or the operation will
 	// fail. If unspecified, a port will be allocated if this Service requires one.
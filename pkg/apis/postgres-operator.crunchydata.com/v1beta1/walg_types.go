@@ -0,0 +1,33 @@
+/*
+ Copyright 2021 - 2022 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// WALGArchive selects WAL-G rather than pgBackRest to archive WAL, create
+// replicas, and restore. The PostgreSQL image must provide the wal-g executable.
+// More info: https://github.com/wal-g/wal-g/blob/master/docs/PostgreSQL.md
+type WALGArchive struct {
+
+	// Environment variables that configure WAL-G, such as WALG_S3_PREFIX and
+	// the credentials of that storage. They are set in PostgreSQL instances and
+	// restore Jobs.
+	// More info: https://github.com/wal-g/wal-g/blob/master/docs/STORAGES.md
+	// +kubebuilder:validation:MinItems=1
+	Env []corev1.EnvVar `json:"env"`
+}
@@ -22,6 +22,7 @@ package v1beta1
 
 import (
 	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -48,6 +49,11 @@ func (in *BackupJobs) DeepCopyInto(out *BackupJobs) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupJobs.
@@ -64,6 +70,16 @@ func (in *BackupJobs) DeepCopy() *BackupJobs {
 func (in *Backups) DeepCopyInto(out *Backups) {
 	*out = *in
 	in.PGBackRest.DeepCopyInto(&out.PGBackRest)
+	if in.Logical != nil {
+		in, out := &in.Logical, &out.Logical
+		*out = new(LogicalBackups)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WALG != nil {
+		in, out := &in.WALG, &out.WALG
+		*out = new(WALGArchive)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Backups.
@@ -76,6 +92,21 @@ func (in *Backups) DeepCopy() *Backups {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CitusSpec) DeepCopyInto(out *CitusSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CitusSpec.
+func (in *CitusSpec) DeepCopy() *CitusSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CitusSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataSource) DeepCopyInto(out *DataSource) {
 	*out = *in
@@ -154,6 +185,18 @@ func (in *DataSourceVolumes) DeepCopy() *DataSourceVolumes {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DatabaseInitSQL) DeepCopyInto(out *DatabaseInitSQL) {
 	*out = *in
+	if in.Variables != nil {
+		in, out := &in.Variables, &out.Variables
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.StopOnError != nil {
+		in, out := &in.StopOnError, &out.StopOnError
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseInitSQL.
@@ -194,6 +237,86 @@ func (in *ExporterSpec) DeepCopy() *ExporterSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HAProxyPodSpec) DeepCopyInto(out *HAProxyPodSpec) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReplicaPort != nil {
+		in, out := &in.ReplicaPort, &out.ReplicaPort
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PriorityClassName != nil {
+		in, out := &in.PriorityClassName, &out.PriorityClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HAProxyPodSpec.
+func (in *HAProxyPodSpec) DeepCopy() *HAProxyPodSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HAProxyPodSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HAProxyPodStatus) DeepCopyInto(out *HAProxyPodStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HAProxyPodStatus.
+func (in *HAProxyPodStatus) DeepCopy() *HAProxyPodStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HAProxyPodStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstanceSidecars) DeepCopyInto(out *InstanceSidecars) {
 	*out = *in
@@ -215,191 +338,535 @@ func (in *InstanceSidecars) DeepCopy() *InstanceSidecars {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Metadata) DeepCopyInto(out *Metadata) {
+func (in *LogShippingSpec) DeepCopyInto(out *LogShippingSpec) {
 	*out = *in
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	if in.Configuration != nil {
+		in, out := &in.Configuration, &out.Configuration
+		*out = make([]v1.VolumeProjection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	in.Resources.DeepCopyInto(&out.Resources)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metadata.
-func (in *Metadata) DeepCopy() *Metadata {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogShippingSpec.
+func (in *LogShippingSpec) DeepCopy() *LogShippingSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Metadata)
+	out := new(LogShippingSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+func (in *LogicalBackupStatus) DeepCopyInto(out *LogicalBackupStatus) {
 	*out = *in
-	if in.PGMonitor != nil {
-		in, out := &in.PGMonitor, &out.PGMonitor
-		*out = new(PGMonitorSpec)
-		(*in).DeepCopyInto(*out)
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
-func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalBackupStatus.
+func (in *LogicalBackupStatus) DeepCopy() *LogicalBackupStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(MonitoringSpec)
+	out := new(LogicalBackupStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonitoringStatus) DeepCopyInto(out *MonitoringStatus) {
+func (in *LogicalBackupUpload) DeepCopyInto(out *LogicalBackupUpload) {
 	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringStatus.
-func (in *MonitoringStatus) DeepCopy() *MonitoringStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalBackupUpload.
+func (in *LogicalBackupUpload) DeepCopy() *LogicalBackupUpload {
 	if in == nil {
 		return nil
 	}
-	out := new(MonitoringStatus)
+	out := new(LogicalBackupUpload)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGAdminConfiguration) DeepCopyInto(out *PGAdminConfiguration) {
+func (in *LogicalBackups) DeepCopyInto(out *LogicalBackups) {
 	*out = *in
-	if in.Files != nil {
-		in, out := &in.Files, &out.Files
-		*out = make([]v1.VolumeProjection, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
 	}
-	if in.LDAPBindPassword != nil {
-		in, out := &in.LDAPBindPassword, &out.LDAPBindPassword
-		*out = new(v1.SecretKeySelector)
+	if in.VolumeClaimSpec != nil {
+		in, out := &in.VolumeClaimSpec, &out.VolumeClaimSpec
+		*out = new(v1.PersistentVolumeClaimSpec)
 		(*in).DeepCopyInto(*out)
 	}
-	in.Settings.DeepCopyInto(&out.Settings)
+	if in.Retain != nil {
+		in, out := &in.Retain, &out.Retain
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Upload != nil {
+		in, out := &in.Upload, &out.Upload
+		*out = new(LogicalBackupUpload)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminConfiguration.
-func (in *PGAdminConfiguration) DeepCopy() *PGAdminConfiguration {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalBackups.
+func (in *LogicalBackups) DeepCopy() *LogicalBackups {
 	if in == nil {
 		return nil
 	}
-	out := new(PGAdminConfiguration)
+	out := new(LogicalBackups)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGAdminPodSpec) DeepCopyInto(out *PGAdminPodSpec) {
+func (in *LogicalDecodingSlotSpec) DeepCopyInto(out *LogicalDecodingSlotSpec) {
 	*out = *in
-	if in.Metadata != nil {
-		in, out := &in.Metadata, &out.Metadata
-		*out = new(Metadata)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(v1.Affinity)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalDecodingSlotSpec.
+func (in *LogicalDecodingSlotSpec) DeepCopy() *LogicalDecodingSlotSpec {
+	if in == nil {
+		return nil
 	}
-	in.Config.DeepCopyInto(&out.Config)
-	in.DataVolumeClaimSpec.DeepCopyInto(&out.DataVolumeClaimSpec)
-	if in.PriorityClassName != nil {
-		in, out := &in.PriorityClassName, &out.PriorityClassName
-		*out = new(string)
-		**out = **in
+	out := new(LogicalDecodingSlotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogicalDecodingSpec) DeepCopyInto(out *LogicalDecodingSpec) {
+	*out = *in
+	if in.Slots != nil {
+		in, out := &in.Slots, &out.Slots
+		*out = make([]LogicalDecodingSlotSpec, len(*in))
+		copy(*out, *in)
 	}
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
+	if in.Senders != nil {
+		in, out := &in.Senders, &out.Senders
 		*out = new(int32)
 		**out = **in
 	}
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.Service != nil {
-		in, out := &in.Service, &out.Service
-		*out = new(ServiceSpec)
-		(*in).DeepCopyInto(*out)
+	if in.MaxRetainedWAL != nil {
+		in, out := &in.MaxRetainedWAL, &out.MaxRetainedWAL
+		x := (*in).DeepCopy()
+		*out = &x
 	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalDecodingSpec.
+func (in *LogicalDecodingSpec) DeepCopy() *LogicalDecodingSpec {
+	if in == nil {
+		return nil
 	}
-	if in.TopologySpreadConstraints != nil {
-		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
-		*out = make([]v1.TopologySpreadConstraint, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	out := new(LogicalDecodingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceJobSpec) DeepCopyInto(out *MaintenanceJobSpec) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
+	in.Resources.DeepCopyInto(&out.Resources)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminPodSpec.
-func (in *PGAdminPodSpec) DeepCopy() *PGAdminPodSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceJobSpec.
+func (in *MaintenanceJobSpec) DeepCopy() *MaintenanceJobSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PGAdminPodSpec)
+	out := new(MaintenanceJobSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGAdminPodStatus) DeepCopyInto(out *PGAdminPodStatus) {
+func (in *MaintenanceJobStatus) DeepCopyInto(out *MaintenanceJobStatus) {
 	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminPodStatus.
-func (in *PGAdminPodStatus) DeepCopy() *PGAdminPodStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceJobStatus.
+func (in *MaintenanceJobStatus) DeepCopy() *MaintenanceJobStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PGAdminPodStatus)
+	out := new(MaintenanceJobStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBackRestArchive) DeepCopyInto(out *PGBackRestArchive) {
+func (in *MaintenanceSpec) DeepCopyInto(out *MaintenanceSpec) {
 	*out = *in
-	if in.Metadata != nil {
-		in, out := &in.Metadata, &out.Metadata
-		*out = new(Metadata)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Configuration != nil {
-		in, out := &in.Configuration, &out.Configuration
-		*out = make([]v1.VolumeProjection, len(*in))
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = make([]MaintenanceJobSpec, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Global != nil {
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceSpec.
+func (in *MaintenanceSpec) DeepCopy() *MaintenanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceStatus) DeepCopyInto(out *MaintenanceStatus) {
+	*out = *in
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = make([]MaintenanceJobStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceStatus.
+func (in *MaintenanceStatus) DeepCopy() *MaintenanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metadata) DeepCopyInto(out *Metadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metadata.
+func (in *Metadata) DeepCopy() *Metadata {
+	if in == nil {
+		return nil
+	}
+	out := new(Metadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationSpec) DeepCopyInto(out *MigrationSpec) {
+	*out = *in
+	in.Container.DeepCopyInto(&out.Container)
+	if in.RunAfterRestore != nil {
+		in, out := &in.RunAfterRestore, &out.RunAfterRestore
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationSpec.
+func (in *MigrationSpec) DeepCopy() *MigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+	*out = *in
+	if in.PGMonitor != nil {
+		in, out := &in.PGMonitor, &out.PGMonitor
+		*out = new(PGMonitorSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LogShipping != nil {
+		in, out := &in.LogShipping, &out.LogShipping
+		*out = new(LogShippingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
+func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MonitoringStatus) DeepCopyInto(out *MonitoringStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringStatus.
+func (in *MonitoringStatus) DeepCopy() *MonitoringStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MonitoringStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGAdminConfiguration) DeepCopyInto(out *PGAdminConfiguration) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]v1.VolumeProjection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LDAPBindPassword != nil {
+		in, out := &in.LDAPBindPassword, &out.LDAPBindPassword
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Settings.DeepCopyInto(&out.Settings)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminConfiguration.
+func (in *PGAdminConfiguration) DeepCopy() *PGAdminConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(PGAdminConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGAdminIngressSpec) DeepCopyInto(out *PGAdminIngressSpec) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminIngressSpec.
+func (in *PGAdminIngressSpec) DeepCopy() *PGAdminIngressSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGAdminIngressSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGAdminPodSpec) DeepCopyInto(out *PGAdminPodSpec) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Config.DeepCopyInto(&out.Config)
+	in.DataVolumeClaimSpec.DeepCopyInto(&out.DataVolumeClaimSpec)
+	if in.PriorityClassName != nil {
+		in, out := &in.PriorityClassName, &out.PriorityClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(PGAdminIngressSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminPodSpec.
+func (in *PGAdminPodSpec) DeepCopy() *PGAdminPodSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGAdminPodSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGAdminPodStatus) DeepCopyInto(out *PGAdminPodStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminPodStatus.
+func (in *PGAdminPodStatus) DeepCopy() *PGAdminPodStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGAdminPodStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGAuditSpec) DeepCopyInto(out *PGAuditSpec) {
+	*out = *in
+	if in.Log != nil {
+		in, out := &in.Log, &out.Log
+		*out = make([]PGAuditLogClass, len(*in))
+		copy(*out, *in)
+	}
+	if in.LogParameter != nil {
+		in, out := &in.LogParameter, &out.LogParameter
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LogRelation != nil {
+		in, out := &in.LogRelation, &out.LogRelation
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LogCatalog != nil {
+		in, out := &in.LogCatalog, &out.LogCatalog
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAuditSpec.
+func (in *PGAuditSpec) DeepCopy() *PGAuditSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGAuditSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestArchive) DeepCopyInto(out *PGBackRestArchive) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Configuration != nil {
+		in, out := &in.Configuration, &out.Configuration
+		*out = make([]v1.VolumeProjection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Global != nil {
 		in, out := &in.Global, &out.Global
 		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
 			(*out)[key] = val
 		}
 	}
+	if in.ArchiveAsync != nil {
+		in, out := &in.ArchiveAsync, &out.ArchiveAsync
+		*out = new(PGBackRestArchiveAsync)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Jobs != nil {
 		in, out := &in.Jobs, &out.Jobs
 		*out = new(BackupJobs)
@@ -427,6 +894,11 @@ func (in *PGBackRestArchive) DeepCopyInto(out *PGBackRestArchive) {
 		*out = new(PGBackRestRestore)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RestoreDrill != nil {
+		in, out := &in.RestoreDrill, &out.RestoreDrill
+		*out = new(PGBackRestRestoreDrill)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Sidecars != nil {
 		in, out := &in.Sidecars, &out.Sidecars
 		*out = new(PGBackRestSidecars)
@@ -444,6 +916,36 @@ func (in *PGBackRestArchive) DeepCopy() *PGBackRestArchive {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestArchiveAsync) DeepCopyInto(out *PGBackRestArchiveAsync) {
+	*out = *in
+	if in.ProcessMax != nil {
+		in, out := &in.ProcessMax, &out.ProcessMax
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PushQueueMax != nil {
+		in, out := &in.PushQueueMax, &out.PushQueueMax
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.GetQueueMax != nil {
+		in, out := &in.GetQueueMax, &out.GetQueueMax
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestArchiveAsync.
+func (in *PGBackRestArchiveAsync) DeepCopy() *PGBackRestArchiveAsync {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBackRestArchiveAsync)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PGBackRestBackupSchedules) DeepCopyInto(out *PGBackRestBackupSchedules) {
 	*out = *in
@@ -462,6 +964,11 @@ func (in *PGBackRestBackupSchedules) DeepCopyInto(out *PGBackRestBackupSchedules
 		*out = new(string)
 		**out = **in
 	}
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = new(string)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestBackupSchedules.
@@ -578,6 +1085,18 @@ func (in *PGBackRestRepo) DeepCopyInto(out *PGBackRestRepo) {
 		*out = new(PGBackRestBackupSchedules)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(PGBackRestRepoEncryption)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Azure != nil {
 		in, out := &in.Azure, &out.Azure
 		*out = new(RepoAzure)
@@ -610,6 +1129,22 @@ func (in *PGBackRestRepo) DeepCopy() *PGBackRestRepo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestRepoEncryption) DeepCopyInto(out *PGBackRestRepoEncryption) {
+	*out = *in
+	in.Passphrase.DeepCopyInto(&out.Passphrase)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestRepoEncryption.
+func (in *PGBackRestRepoEncryption) DeepCopy() *PGBackRestRepoEncryption {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBackRestRepoEncryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PGBackRestRepoHost) DeepCopyInto(out *PGBackRestRepoHost) {
 	*out = *in
@@ -675,12 +1210,29 @@ func (in *PGBackRestRestore) DeepCopyInto(out *PGBackRestRestore) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestRestore.
-func (in *PGBackRestRestore) DeepCopy() *PGBackRestRestore {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestRestore.
+func (in *PGBackRestRestore) DeepCopy() *PGBackRestRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBackRestRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestRestoreDrill) DeepCopyInto(out *PGBackRestRestoreDrill) {
+	*out = *in
+	in.DataVolumeClaimSpec.DeepCopyInto(&out.DataVolumeClaimSpec)
+	in.Resources.DeepCopyInto(&out.Resources)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestRestoreDrill.
+func (in *PGBackRestRestoreDrill) DeepCopy() *PGBackRestRestoreDrill {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBackRestRestore)
+	out := new(PGBackRestRestoreDrill)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -756,13 +1308,25 @@ func (in *PGBackRestStatus) DeepCopyInto(out *PGBackRestStatus) {
 	if in.Repos != nil {
 		in, out := &in.Repos, &out.Repos
 		*out = make([]RepoStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Restore != nil {
 		in, out := &in.Restore, &out.Restore
 		*out = new(PGBackRestJobStatus)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RestoreDrill != nil {
+		in, out := &in.RestoreDrill, &out.RestoreDrill
+		*out = new(PGBackRestJobStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastBackup != nil {
+		in, out := &in.LastBackup, &out.LastBackup
+		*out = new(PGBackRestJobStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestStatus.
@@ -818,6 +1382,31 @@ func (in *PGBouncerConfiguration) DeepCopy() *PGBouncerConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBouncerDatabasePoolSpec) DeepCopyInto(out *PGBouncerDatabasePoolSpec) {
+	*out = *in
+	if in.PoolSize != nil {
+		in, out := &in.PoolSize, &out.PoolSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinPoolSize != nil {
+		in, out := &in.MinPoolSize, &out.MinPoolSize
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerDatabasePoolSpec.
+func (in *PGBouncerDatabasePoolSpec) DeepCopy() *PGBouncerDatabasePoolSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBouncerDatabasePoolSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PGBouncerPodSpec) DeepCopyInto(out *PGBouncerPodSpec) {
 	*out = *in
@@ -832,6 +1421,11 @@ func (in *PGBouncerPodSpec) DeepCopyInto(out *PGBouncerPodSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.Config.DeepCopyInto(&out.Config)
+	if in.Pooling != nil {
+		in, out := &in.Pooling, &out.Pooling
+		*out = new(PGBouncerPoolingSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Containers != nil {
 		in, out := &in.Containers, &out.Containers
 		*out = make([]v1.Container, len(*in))
@@ -839,6 +1433,13 @@ func (in *PGBouncerPodSpec) DeepCopyInto(out *PGBouncerPodSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.CustomTLSSecret != nil {
 		in, out := &in.CustomTLSSecret, &out.CustomTLSSecret
 		*out = new(v1.SecretProjection)
@@ -875,83 +1476,404 @@ func (in *PGBouncerPodSpec) DeepCopyInto(out *PGBouncerPodSpec) {
 		*out = new(PGBouncerSidecars)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]v1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerPodSpec.
+func (in *PGBouncerPodSpec) DeepCopy() *PGBouncerPodSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBouncerPodSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBouncerPodStatus) DeepCopyInto(out *PGBouncerPodStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerPodStatus.
+func (in *PGBouncerPodStatus) DeepCopy() *PGBouncerPodStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBouncerPodStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBouncerPoolingSpec) DeepCopyInto(out *PGBouncerPoolingSpec) {
+	*out = *in
+	if in.DefaultPoolSize != nil {
+		in, out := &in.DefaultPoolSize, &out.DefaultPoolSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinPoolSize != nil {
+		in, out := &in.MinPoolSize, &out.MinPoolSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]PGBouncerDatabasePoolSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerPoolingSpec.
+func (in *PGBouncerPoolingSpec) DeepCopy() *PGBouncerPoolingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBouncerPoolingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBouncerSidecars) DeepCopyInto(out *PGBouncerSidecars) {
+	*out = *in
+	if in.PGBouncerConfig != nil {
+		in, out := &in.PGBouncerConfig, &out.PGBouncerConfig
+		*out = new(Sidecar)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerSidecars.
+func (in *PGBouncerSidecars) DeepCopy() *PGBouncerSidecars {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBouncerSidecars)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGMonitorAlertsSpec) DeepCopyInto(out *PGMonitorAlertsSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ReplicationLag != nil {
+		in, out := &in.ReplicationLag, &out.ReplicationLag
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.BackupAgeHours != nil {
+		in, out := &in.BackupAgeHours, &out.BackupAgeHours
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ConnectionsPercent != nil {
+		in, out := &in.ConnectionsPercent, &out.ConnectionsPercent
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGMonitorAlertsSpec.
+func (in *PGMonitorAlertsSpec) DeepCopy() *PGMonitorAlertsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGMonitorAlertsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGMonitorSpec) DeepCopyInto(out *PGMonitorSpec) {
+	*out = *in
+	if in.Exporter != nil {
+		in, out := &in.Exporter, &out.Exporter
+		*out = new(ExporterSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Alerts != nil {
+		in, out := &in.Alerts, &out.Alerts
+		*out = new(PGMonitorAlertsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGMonitorSpec.
+func (in *PGMonitorSpec) DeepCopy() *PGMonitorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGMonitorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGPartmanSpec) DeepCopyInto(out *PGPartmanSpec) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+	if in.IntervalSeconds != nil {
+		in, out := &in.IntervalSeconds, &out.IntervalSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Analyze != nil {
+		in, out := &in.Analyze, &out.Analyze
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGPartmanSpec.
+func (in *PGPartmanSpec) DeepCopy() *PGPartmanSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGPartmanSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGUpgradeSpec) DeepCopyInto(out *PGUpgradeSpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradeSpec.
+func (in *PGUpgradeSpec) DeepCopy() *PGUpgradeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGUpgradeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniAPIAuthentication) DeepCopyInto(out *PatroniAPIAuthentication) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniAPIAuthentication.
+func (in *PatroniAPIAuthentication) DeepCopy() *PatroniAPIAuthentication {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniAPIAuthentication)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniAPIServiceSpec) DeepCopyInto(out *PatroniAPIServiceSpec) {
+	*out = *in
+	in.ServiceSpec.DeepCopyInto(&out.ServiceSpec)
+	if in.AllowedSources != nil {
+		in, out := &in.AllowedSources, &out.AllowedSources
+		*out = make([]networkingv1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniAPIServiceSpec.
+func (in *PatroniAPIServiceSpec) DeepCopy() *PatroniAPIServiceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniAPIServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniCallbacks) DeepCopyInto(out *PatroniCallbacks) {
+	*out = *in
+	if in.OnRoleChange != nil {
+		in, out := &in.OnRoleChange, &out.OnRoleChange
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OnStart != nil {
+		in, out := &in.OnStart, &out.OnStart
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OnStop != nil {
+		in, out := &in.OnStop, &out.OnStop
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniCallbacks.
+func (in *PatroniCallbacks) DeepCopy() *PatroniCallbacks {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniCallbacks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniCreateReplica) DeepCopyInto(out *PatroniCreateReplica) {
+	*out = *in
+	if in.Methods != nil {
+		in, out := &in.Methods, &out.Methods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BasebackupOptions != nil {
+		in, out := &in.BasebackupOptions, &out.BasebackupOptions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.TopologySpreadConstraints != nil {
-		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
-		*out = make([]v1.TopologySpreadConstraint, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniCreateReplica.
+func (in *PatroniCreateReplica) DeepCopy() *PatroniCreateReplica {
+	if in == nil {
+		return nil
 	}
+	out := new(PatroniCreateReplica)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerPodSpec.
-func (in *PGBouncerPodSpec) DeepCopy() *PGBouncerPodSpec {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniHistoryStatus) DeepCopyInto(out *PatroniHistoryStatus) {
+	*out = *in
+	if in.Time != nil {
+		in, out := &in.Time, &out.Time
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniHistoryStatus.
+func (in *PatroniHistoryStatus) DeepCopy() *PatroniHistoryStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBouncerPodSpec)
+	out := new(PatroniHistoryStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBouncerPodStatus) DeepCopyInto(out *PGBouncerPodStatus) {
+func (in *PatroniLogConfig) DeepCopyInto(out *PatroniLogConfig) {
 	*out = *in
+	if in.StorageLimit != nil {
+		in, out := &in.StorageLimit, &out.StorageLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Level != nil {
+		in, out := &in.Level, &out.Level
+		*out = new(string)
+		**out = **in
+	}
+	if in.Format != nil {
+		in, out := &in.Format, &out.Format
+		*out = new(string)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerPodStatus.
-func (in *PGBouncerPodStatus) DeepCopy() *PGBouncerPodStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniLogConfig.
+func (in *PatroniLogConfig) DeepCopy() *PatroniLogConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBouncerPodStatus)
+	out := new(PatroniLogConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBouncerSidecars) DeepCopyInto(out *PGBouncerSidecars) {
+func (in *PatroniMemberStatus) DeepCopyInto(out *PatroniMemberStatus) {
 	*out = *in
-	if in.PGBouncerConfig != nil {
-		in, out := &in.PGBouncerConfig, &out.PGBouncerConfig
-		*out = new(Sidecar)
-		(*in).DeepCopyInto(*out)
+	if in.Timeline != nil {
+		in, out := &in.Timeline, &out.Timeline
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LagMegabytes != nil {
+		in, out := &in.LagMegabytes, &out.LagMegabytes
+		*out = new(int64)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerSidecars.
-func (in *PGBouncerSidecars) DeepCopy() *PGBouncerSidecars {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniMemberStatus.
+func (in *PatroniMemberStatus) DeepCopy() *PatroniMemberStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBouncerSidecars)
+	out := new(PatroniMemberStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGMonitorSpec) DeepCopyInto(out *PGMonitorSpec) {
+func (in *PatroniRewind) DeepCopyInto(out *PatroniRewind) {
 	*out = *in
-	if in.Exporter != nil {
-		in, out := &in.Exporter, &out.Exporter
-		*out = new(ExporterSpec)
-		(*in).DeepCopyInto(*out)
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RemoveDataDirectoryOnFailure != nil {
+		in, out := &in.RemoveDataDirectoryOnFailure, &out.RemoveDataDirectoryOnFailure
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RemoveDataDirectoryOnDivergedTimelines != nil {
+		in, out := &in.RemoveDataDirectoryOnDivergedTimelines, &out.RemoveDataDirectoryOnDivergedTimelines
+		*out = new(bool)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGMonitorSpec.
-func (in *PGMonitorSpec) DeepCopy() *PGMonitorSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniRewind.
+func (in *PatroniRewind) DeepCopy() *PatroniRewind {
 	if in == nil {
 		return nil
 	}
-	out := new(PGMonitorSpec)
+	out := new(PatroniRewind)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -959,6 +1881,16 @@ func (in *PGMonitorSpec) DeepCopy() *PGMonitorSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PatroniSpec) DeepCopyInto(out *PatroniSpec) {
 	*out = *in
+	if in.APIService != nil {
+		in, out := &in.APIService, &out.APIService
+		*out = new(PatroniAPIServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.APIAuthentication != nil {
+		in, out := &in.APIAuthentication, &out.APIAuthentication
+		*out = new(PatroniAPIAuthentication)
+		**out = **in
+	}
 	in.DynamicConfiguration.DeepCopyInto(&out.DynamicConfiguration)
 	if in.LeaderLeaseDurationSeconds != nil {
 		in, out := &in.LeaderLeaseDurationSeconds, &out.LeaderLeaseDurationSeconds
@@ -975,11 +1907,71 @@ func (in *PatroniSpec) DeepCopyInto(out *PatroniSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.Rewind != nil {
+		in, out := &in.Rewind, &out.Rewind
+		*out = new(PatroniRewind)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetryTimeoutSeconds != nil {
+		in, out := &in.RetryTimeoutSeconds, &out.RetryTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SynchronousMode != nil {
+		in, out := &in.SynchronousMode, &out.SynchronousMode
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SynchronousModeStrict != nil {
+		in, out := &in.SynchronousModeStrict, &out.SynchronousModeStrict
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SynchronousNodeCount != nil {
+		in, out := &in.SynchronousNodeCount, &out.SynchronousNodeCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Callbacks != nil {
+		in, out := &in.Callbacks, &out.Callbacks
+		*out = new(PatroniCallbacks)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CreateReplica != nil {
+		in, out := &in.CreateReplica, &out.CreateReplica
+		*out = new(PatroniCreateReplica)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaximumLagOnFailover != nil {
+		in, out := &in.MaximumLagOnFailover, &out.MaximumLagOnFailover
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Logging != nil {
+		in, out := &in.Logging, &out.Logging
+		*out = new(PatroniLogConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrimaryStartTimeoutSeconds != nil {
+		in, out := &in.PrimaryStartTimeoutSeconds, &out.PrimaryStartTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Switchover != nil {
 		in, out := &in.Switchover, &out.Switchover
 		*out = new(PatroniSwitchover)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.UseConfigMaps != nil {
+		in, out := &in.UseConfigMaps, &out.UseConfigMaps
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSpec.
@@ -1005,6 +1997,25 @@ func (in *PatroniStatus) DeepCopyInto(out *PatroniStatus) {
 		*out = new(int64)
 		**out = **in
 	}
+	if in.SwitchoverQueued != nil {
+		in, out := &in.SwitchoverQueued, &out.SwitchoverQueued
+		*out = new(string)
+		**out = **in
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]PatroniMemberStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]PatroniHistoryStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniStatus.
@@ -1025,6 +2036,11 @@ func (in *PatroniSwitchover) DeepCopyInto(out *PatroniSwitchover) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Window != nil {
+		in, out := &in.Window, &out.Window
+		*out = new(PatroniSwitchoverWindow)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSwitchover.
@@ -1037,6 +2053,21 @@ func (in *PatroniSwitchover) DeepCopy() *PatroniSwitchover {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniSwitchoverWindow) DeepCopyInto(out *PatroniSwitchoverWindow) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSwitchoverWindow.
+func (in *PatroniSwitchoverWindow) DeepCopy() *PatroniSwitchoverWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniSwitchoverWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresAdditionalConfig) DeepCopyInto(out *PostgresAdditionalConfig) {
 	*out = *in
@@ -1047,6 +2078,30 @@ func (in *PostgresAdditionalConfig) DeepCopyInto(out *PostgresAdditionalConfig)
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Locale != nil {
+		in, out := &in.Locale, &out.Locale
+		*out = new(PostgresLocaleSpec)
+		**out = **in
+	}
+	if in.HBA != nil {
+		in, out := &in.HBA, &out.HBA
+		*out = make([]PostgresHBARule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]intstr.IntOrString, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AutoTune != nil {
+		in, out := &in.AutoTune, &out.AutoTune
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresAdditionalConfig.
@@ -1094,6 +2149,10 @@ func (in *PostgresClusterDataSource) DeepCopyInto(out *PostgresClusterDataSource
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.TargetTime != nil {
+		in, out := &in.TargetTime, &out.TargetTime
+		*out = (*in).DeepCopy()
+	}
 	in.Resources.DeepCopyInto(&out.Resources)
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
@@ -1170,6 +2229,21 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.Backups.DeepCopyInto(&out.Backups)
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(PGAuditSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PGPartman != nil {
+		in, out := &in.PGPartman, &out.PGPartman
+		*out = new(PGPartmanSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Citus != nil {
+		in, out := &in.Citus, &out.Citus
+		*out = new(CitusSpec)
+		**out = **in
+	}
 	if in.CustomTLSSecret != nil {
 		in, out := &in.CustomTLSSecret, &out.CustomTLSSecret
 		*out = new(v1.SecretProjection)
@@ -1183,7 +2257,12 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 	if in.DatabaseInitSQL != nil {
 		in, out := &in.DatabaseInitSQL, &out.DatabaseInitSQL
 		*out = new(DatabaseInitSQL)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Migration != nil {
+		in, out := &in.Migration, &out.Migration
+		*out = new(MigrationSpec)
+		(*in).DeepCopyInto(*out)
 	}
 	if in.DisableDefaultPodScheduling != nil {
 		in, out := &in.DisableDefaultPodScheduling, &out.DisableDefaultPodScheduling
@@ -1237,16 +2316,41 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 		*out = new(MonitoringSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(MaintenanceSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Service != nil {
 		in, out := &in.Service, &out.Service
 		*out = new(ServiceSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ReplicaService != nil {
+		in, out := &in.ReplicaService, &out.ReplicaService
+		*out = new(ServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicationSlots != nil {
+		in, out := &in.ReplicationSlots, &out.ReplicationSlots
+		*out = make([]PostgresReplicationSlotSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.Replication != nil {
+		in, out := &in.Replication, &out.Replication
+		*out = new(PostgresReplicationSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Shutdown != nil {
 		in, out := &in.Shutdown, &out.Shutdown
 		*out = new(bool)
 		**out = **in
 	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		*out = new(PGUpgradeSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Standby != nil {
 		in, out := &in.Standby, &out.Standby
 		*out = new(PostgresStandbySpec)
@@ -1264,6 +2368,20 @@ func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]PostgresDatabaseSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]PostgresExtensionSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.Config.DeepCopyInto(&out.Config)
 }
 
@@ -1283,7 +2401,9 @@ func (in *PostgresClusterStatus) DeepCopyInto(out *PostgresClusterStatus) {
 	if in.InstanceSets != nil {
 		in, out := &in.InstanceSets, &out.InstanceSets
 		*out = make([]PostgresInstanceSetStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	in.Patroni.DeepCopyInto(&out.Patroni)
 	if in.PGBackRest != nil {
@@ -1292,6 +2412,18 @@ func (in *PostgresClusterStatus) DeepCopyInto(out *PostgresClusterStatus) {
 		(*in).DeepCopyInto(*out)
 	}
 	out.Proxy = in.Proxy
+	if in.Standby != nil {
+		in, out := &in.Standby, &out.Standby
+		*out = new(PostgresStandbyStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicationSlots != nil {
+		in, out := &in.ReplicationSlots, &out.ReplicationSlots
+		*out = make([]ReplicationSlotStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.UserInterface != nil {
 		in, out := &in.UserInterface, &out.UserInterface
 		*out = new(PostgresUserInterfaceStatus)
@@ -1303,21 +2435,113 @@ func (in *PostgresClusterStatus) DeepCopyInto(out *PostgresClusterStatus) {
 		*out = new(string)
 		**out = **in
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(MaintenanceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LogicalBackup != nil {
+		in, out := &in.LogicalBackup, &out.LogicalBackup
+		*out = new(LogicalBackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterStatus.
+func (in *PostgresClusterStatus) DeepCopy() *PostgresClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresDatabaseSpec) DeepCopyInto(out *PostgresDatabaseSpec) {
+	*out = *in
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schemas != nil {
+		in, out := &in.Schemas, &out.Schemas
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+	if in.Locale != nil {
+		in, out := &in.Locale, &out.Locale
+		*out = new(PostgresLocaleSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDatabaseSpec.
+func (in *PostgresDatabaseSpec) DeepCopy() *PostgresDatabaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresDatabaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresExtensionSpec) DeepCopyInto(out *PostgresExtensionSpec) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresExtensionSpec.
+func (in *PostgresExtensionSpec) DeepCopy() *PostgresExtensionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresExtensionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresHBARule) DeepCopyInto(out *PostgresHBARule) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterStatus.
-func (in *PostgresClusterStatus) DeepCopy() *PostgresClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresHBARule.
+func (in *PostgresHBARule) DeepCopy() *PostgresHBARule {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresClusterStatus)
+	out := new(PostgresHBARule)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1330,6 +2554,11 @@ func (in *PostgresInstanceSetSpec) DeepCopyInto(out *PostgresInstanceSetSpec) {
 		*out = new(Metadata)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CitusGroup != nil {
+		in, out := &in.CitusGroup, &out.CitusGroup
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
 		*out = new(v1.Affinity)
@@ -1383,6 +2612,13 @@ func (in *PostgresInstanceSetSpec) DeepCopyInto(out *PostgresInstanceSetSpec) {
 		*out = new(v1.PersistentVolumeClaimSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TablespaceVolumes != nil {
+		in, out := &in.TablespaceVolumes, &out.TablespaceVolumes
+		*out = make([]TablespaceVolume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresInstanceSetSpec.
@@ -1398,6 +2634,20 @@ func (in *PostgresInstanceSetSpec) DeepCopy() *PostgresInstanceSetSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresInstanceSetStatus) DeepCopyInto(out *PostgresInstanceSetStatus) {
 	*out = *in
+	if in.ReplicaLag != nil {
+		in, out := &in.ReplicaLag, &out.ReplicaLag
+		*out = make([]ReplicaLagStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]PostgresInstanceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresInstanceSetStatus.
@@ -1410,6 +2660,41 @@ func (in *PostgresInstanceSetStatus) DeepCopy() *PostgresInstanceSetStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresInstanceStatus) DeepCopyInto(out *PostgresInstanceStatus) {
+	*out = *in
+	if in.PersistentVolumeClaims != nil {
+		in, out := &in.PersistentVolumeClaims, &out.PersistentVolumeClaims
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresInstanceStatus.
+func (in *PostgresInstanceStatus) DeepCopy() *PostgresInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresLocaleSpec) DeepCopyInto(out *PostgresLocaleSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresLocaleSpec.
+func (in *PostgresLocaleSpec) DeepCopy() *PostgresLocaleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresLocaleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresPasswordSpec) DeepCopyInto(out *PostgresPasswordSpec) {
 	*out = *in
@@ -1433,6 +2718,11 @@ func (in *PostgresProxySpec) DeepCopyInto(out *PostgresProxySpec) {
 		*out = new(PGBouncerPodSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.HAProxy != nil {
+		in, out := &in.HAProxy, &out.HAProxy
+		*out = new(HAProxyPodSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresProxySpec.
@@ -1449,6 +2739,7 @@ func (in *PostgresProxySpec) DeepCopy() *PostgresProxySpec {
 func (in *PostgresProxyStatus) DeepCopyInto(out *PostgresProxyStatus) {
 	*out = *in
 	out.PGBouncer = in.PGBouncer
+	out.HAProxy = in.HAProxy
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresProxyStatus.
@@ -1461,6 +2752,80 @@ func (in *PostgresProxyStatus) DeepCopy() *PostgresProxyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPublicationSpec) DeepCopyInto(out *PostgresPublicationSpec) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPublicationSpec.
+func (in *PostgresPublicationSpec) DeepCopy() *PostgresPublicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPublicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresReplicationSlotSpec) DeepCopyInto(out *PostgresReplicationSlotSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresReplicationSlotSpec.
+func (in *PostgresReplicationSlotSpec) DeepCopy() *PostgresReplicationSlotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresReplicationSlotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresReplicationSpec) DeepCopyInto(out *PostgresReplicationSpec) {
+	*out = *in
+	if in.MaxReplicaLag != nil {
+		in, out := &in.MaxReplicaLag, &out.MaxReplicaLag
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Publications != nil {
+		in, out := &in.Publications, &out.Publications
+		*out = make([]PostgresPublicationSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Subscriptions != nil {
+		in, out := &in.Subscriptions, &out.Subscriptions
+		*out = make([]PostgresSubscriptionSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LogicalDecoding != nil {
+		in, out := &in.LogicalDecoding, &out.LogicalDecoding
+		*out = new(LogicalDecodingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresReplicationSpec.
+func (in *PostgresReplicationSpec) DeepCopy() *PostgresReplicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresReplicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresStandbySpec) DeepCopyInto(out *PostgresStandbySpec) {
 	*out = *in
@@ -1469,6 +2834,11 @@ func (in *PostgresStandbySpec) DeepCopyInto(out *PostgresStandbySpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ForcePromotion != nil {
+		in, out := &in.ForcePromotion, &out.ForcePromotion
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresStandbySpec.
@@ -1481,6 +2851,60 @@ func (in *PostgresStandbySpec) DeepCopy() *PostgresStandbySpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresStandbyStatus) DeepCopyInto(out *PostgresStandbyStatus) {
+	*out = *in
+	if in.ObservedAt != nil {
+		in, out := &in.ObservedAt, &out.ObservedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.LastReplayedTransaction != nil {
+		in, out := &in.LastReplayedTransaction, &out.LastReplayedTransaction
+		*out = (*in).DeepCopy()
+	}
+	if in.ReplayLagSeconds != nil {
+		in, out := &in.ReplayLagSeconds, &out.ReplayLagSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresStandbyStatus.
+func (in *PostgresStandbyStatus) DeepCopy() *PostgresStandbyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresStandbyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresSubscriptionSpec) DeepCopyInto(out *PostgresSubscriptionSpec) {
+	*out = *in
+	if in.Publications != nil {
+		in, out := &in.Publications, &out.Publications
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+	in.Connection.DeepCopyInto(&out.Connection)
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresSubscriptionSpec.
+func (in *PostgresSubscriptionSpec) DeepCopy() *PostgresSubscriptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresSubscriptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PostgresUserInterfaceStatus) DeepCopyInto(out *PostgresUserInterfaceStatus) {
 	*out = *in
@@ -1522,6 +2946,51 @@ func (in *PostgresUserSpec) DeepCopy() *PostgresUserSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicaLagStatus) DeepCopyInto(out *ReplicaLagStatus) {
+	*out = *in
+	if in.Lag != nil {
+		in, out := &in.Lag, &out.Lag
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.LagTime != nil {
+		in, out := &in.LagTime, &out.LagTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicaLagStatus.
+func (in *ReplicaLagStatus) DeepCopy() *ReplicaLagStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicaLagStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationSlotStatus) DeepCopyInto(out *ReplicationSlotStatus) {
+	*out = *in
+	if in.RetainedWAL != nil {
+		in, out := &in.RetainedWAL, &out.RetainedWAL
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSlotStatus.
+func (in *ReplicationSlotStatus) DeepCopy() *ReplicationSlotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationSlotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RepoAzure) DeepCopyInto(out *RepoAzure) {
 	*out = *in
@@ -1537,6 +3006,69 @@ func (in *RepoAzure) DeepCopy() *RepoAzure {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepoBackupStatus) DeepCopyInto(out *RepoBackupStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StopTime != nil {
+		in, out := &in.StopTime, &out.StopTime
+		*out = (*in).DeepCopy()
+	}
+	if in.DatabaseSize != nil {
+		in, out := &in.DatabaseSize, &out.DatabaseSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.RepositorySize != nil {
+		in, out := &in.RepositorySize, &out.RepositorySize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoBackupStatus.
+func (in *RepoBackupStatus) DeepCopy() *RepoBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RepoBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepoBackupsStatus) DeepCopyInto(out *RepoBackupsStatus) {
+	*out = *in
+	if in.Full != nil {
+		in, out := &in.Full, &out.Full
+		*out = new(RepoBackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Differential != nil {
+		in, out := &in.Differential, &out.Differential
+		*out = new(RepoBackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Incremental != nil {
+		in, out := &in.Incremental, &out.Incremental
+		*out = new(RepoBackupStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoBackupsStatus.
+func (in *RepoBackupsStatus) DeepCopy() *RepoBackupsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RepoBackupsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RepoGCS) DeepCopyInto(out *RepoGCS) {
 	*out = *in
@@ -1602,6 +3134,15 @@ func (in *RepoS3) DeepCopy() *RepoS3 {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RepoStatus) DeepCopyInto(out *RepoStatus) {
 	*out = *in
+	if in.LastVerified != nil {
+		in, out := &in.LastVerified, &out.LastVerified
+		*out = (*in).DeepCopy()
+	}
+	if in.Backups != nil {
+		in, out := &in.Backups, &out.Backups
+		*out = new(RepoBackupsStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoStatus.
@@ -1631,6 +3172,36 @@ func (in *ServiceSpec) DeepCopyInto(out *ServiceSpec) {
 		*out = new(Metadata)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ExternalTrafficPolicy != nil {
+		in, out := &in.ExternalTrafficPolicy, &out.ExternalTrafficPolicy
+		*out = new(v1.ServiceExternalTrafficPolicyType)
+		**out = **in
+	}
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]v1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.IPFamilyPolicy != nil {
+		in, out := &in.IPFamilyPolicy, &out.IPFamilyPolicy
+		*out = new(v1.IPFamilyPolicyType)
+		**out = **in
+	}
+	if in.LoadBalancerClass != nil {
+		in, out := &in.LoadBalancerClass, &out.LoadBalancerClass
+		*out = new(string)
+		**out = **in
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SessionAffinity != nil {
+		in, out := &in.SessionAffinity, &out.SessionAffinity
+		*out = new(v1.ServiceAffinity)
+		**out = **in
+	}
 	if in.NodePort != nil {
 		in, out := &in.NodePort, &out.NodePort
 		*out = new(int32)
@@ -1668,6 +3239,22 @@ func (in *Sidecar) DeepCopy() *Sidecar {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TablespaceVolume) DeepCopyInto(out *TablespaceVolume) {
+	*out = *in
+	in.DataVolumeClaimSpec.DeepCopyInto(&out.DataVolumeClaimSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TablespaceVolume.
+func (in *TablespaceVolume) DeepCopy() *TablespaceVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(TablespaceVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserInterfaceSpec) DeepCopyInto(out *UserInterfaceSpec) {
 	*out = *in
@@ -1687,3 +3274,25 @@ func (in *UserInterfaceSpec) DeepCopy() *UserInterfaceSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WALGArchive) DeepCopyInto(out *WALGArchive) {
+	*out = *in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WALGArchive.
+func (in *WALGArchive) DeepCopy() *WALGArchive {
+	if in == nil {
+		return nil
+	}
+	out := new(WALGArchive)
+	in.DeepCopyInto(out)
+	return out
+}